@@ -0,0 +1,193 @@
+// cmd/loadgen/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "messaging-microservice/proto"
+)
+
+// loadgen drives SendTemplateMessage traffic at a fixed rate against a running service, ideally
+// a local stack with WHATSAPP_PROVIDER=mock, and reports throughput, queue latency and database
+// latency so runs before and after a change can be compared
+func main() {
+	var opts options
+	flag.StringVar(&opts.server, "server", "localhost:9090", "gRPC address of the service")
+	flag.StringVar(&opts.metricsURL, "metrics", "http://localhost:8080/metrics", "Prometheus endpoint of the service, for database latency (empty skips it)")
+	flag.StringVar(&opts.tenant, "tenant", "", "tenant ID sent as x-tenant-id")
+	flag.Float64Var(&opts.rps, "rps", 50, "sends per second")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to send")
+	flag.IntVar(&opts.concurrency, "concurrency", 32, "sends in flight at most")
+	flag.StringVar(&opts.template, "template", "order_confirmation", "template to send")
+	flag.StringVar(&opts.phonePrefix, "phone-prefix", "+1555", "prefix of the random recipient numbers")
+	flag.DurationVar(&opts.drain, "drain", 30*time.Second, "how long to wait for queued messages to be sent after the last send")
+	flag.DurationVar(&opts.poll, "poll", 250*time.Millisecond, "how often to look up the status of queued messages")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of each RPC")
+	flag.Parse()
+
+	if opts.rps <= 0 || opts.concurrency <= 0 || opts.duration <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -rps, -concurrency and -duration must be positive")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// options are the settings of a run
+type options struct {
+	server      string
+	metricsURL  string
+	tenant      string
+	rps         float64
+	duration    time.Duration
+	concurrency int
+	template    string
+	phonePrefix string
+	drain       time.Duration
+	poll        time.Duration
+	timeout     time.Duration
+}
+
+// run sends for the configured duration, waits for the queue to drain and prints the report
+func run(ctx context.Context, opts options) error {
+	conn, err := grpc.NewClient(opts.server, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", opts.server, err)
+	}
+	defer conn.Close()
+	client := pb.NewWhatsAppServiceClient(conn)
+	if opts.tenant != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", opts.tenant)
+	}
+
+	var before map[string]histogram
+	if opts.metricsURL != "" {
+		if before, err = scrapeQueryDurations(ctx, opts.metricsURL); err != nil {
+			return fmt.Errorf("scrape %s: %w", opts.metricsURL, err)
+		}
+	}
+
+	tracker := newQueueTracker(client, opts.timeout)
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	pollDone := make(chan struct{})
+	go func() {
+		defer close(pollDone)
+		tracker.run(pollCtx, opts.poll)
+	}()
+
+	fmt.Printf("Sending %s at %.0f/s for %s to %s\n", opts.template, opts.rps, opts.duration, opts.server)
+	sends := send(ctx, client, opts, tracker)
+
+	// Let the consumer catch up, then stop looking
+	drainCtx, cancelDrain := context.WithTimeout(ctx, opts.drain)
+	tracker.wait(drainCtx)
+	cancelDrain()
+	stopPolling()
+	<-pollDone
+
+	var after map[string]histogram
+	if opts.metricsURL != "" {
+		if after, err = scrapeQueryDurations(context.Background(), opts.metricsURL); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not scrape database latency:", err)
+		}
+	}
+
+	report(os.Stdout, sends, tracker.result(), before, after)
+	return nil
+}
+
+// sendResult summarizes the send RPCs of a run
+type sendResult struct {
+	elapsed   time.Duration
+	latencies latencies
+	// errors counts the failed sends by gRPC code
+	errors map[string]int
+}
+
+// send issues sends at opts.rps until the duration passes or ctx is done. A send that finds
+// every worker busy is skipped and counted, so an overloaded service shows up as lost
+// throughput instead of a slower schedule.
+func send(ctx context.Context, client pb.WhatsAppServiceClient, opts options, tracker *queueTracker) sendResult {
+	result := sendResult{errors: make(map[string]int)}
+	var mu sync.Mutex
+
+	ticks := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func(rng *rand.Rand) {
+			defer wg.Done()
+			for range ticks {
+				req := &pb.SendTemplateMessageRequest{
+					PhoneNumber: fmt.Sprintf("%s%07d", opts.phonePrefix, rng.Intn(10000000)),
+					TemplateId:  opts.template,
+					Parameters:  map[string]string{"order_id": fmt.Sprintf("LOAD-%d", rng.Int63())},
+					OrderId:     "loadgen",
+				}
+				callCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+				start := time.Now()
+				resp, err := client.SendTemplateMessage(callCtx, req)
+				took := time.Since(start)
+				cancel()
+
+				mu.Lock()
+				if err != nil {
+					result.errors[status.Code(err).String()]++
+				} else {
+					result.latencies = append(result.latencies, took)
+				}
+				mu.Unlock()
+				if err == nil {
+					tracker.add(resp.MessageId, start.Add(took))
+				}
+			}
+		}(rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))))
+	}
+
+	start := time.Now()
+	interval := time.Duration(float64(time.Second) / opts.rps)
+	ticker := time.NewTicker(interval)
+	deadline := time.NewTimer(opts.duration)
+	for sending := true; sending; {
+		select {
+		case <-ctx.Done():
+			sending = false
+		case <-deadline.C:
+			sending = false
+		case <-ticker.C:
+			select {
+			case ticks <- struct{}{}:
+			default:
+				mu.Lock()
+				result.errors["Skipped"]++
+				mu.Unlock()
+			}
+		}
+	}
+	ticker.Stop()
+	deadline.Stop()
+	close(ticks)
+	wg.Wait()
+
+	result.elapsed = time.Since(start)
+	return result
+}
@@ -0,0 +1,147 @@
+// cmd/loadgen/queue.go
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	pb "messaging-microservice/proto"
+)
+
+// lookupBatch is how many messages one status lookup asks for, the GetMessages maximum
+const lookupBatch = 100
+
+// queuedStatuses are the statuses of messages the consumer has not sent yet
+var queuedStatuses = map[string]bool{
+	"queued":     true,
+	"processing": true,
+	"retrying":   true,
+}
+
+// queueResult summarizes how long accepted messages waited to be sent
+type queueResult struct {
+	latencies latencies
+	// statuses counts the messages that left the queue by the status they were found in
+	statuses map[string]int
+	// pending is how many were still queued when the run ended
+	pending int
+}
+
+// queueTracker looks up accepted messages until they leave the queue
+type queueTracker struct {
+	client  pb.WhatsAppServiceClient
+	timeout time.Duration
+
+	mu       sync.Mutex
+	pending  map[int64]time.Time
+	settled  queueResult
+	drained  chan struct{}
+	draining bool
+}
+
+func newQueueTracker(client pb.WhatsAppServiceClient, timeout time.Duration) *queueTracker {
+	return &queueTracker{
+		client:  client,
+		timeout: timeout,
+		pending: make(map[int64]time.Time),
+		settled: queueResult{statuses: make(map[string]int)},
+		drained: make(chan struct{}),
+	}
+}
+
+// add tracks a message accepted at acceptedAt
+func (t *queueTracker) add(id int64, acceptedAt time.Time) {
+	t.mu.Lock()
+	t.pending[id] = acceptedAt
+	t.mu.Unlock()
+}
+
+// wait returns once every tracked message left the queue, or ctx is done
+func (t *queueTracker) wait(ctx context.Context) {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+	select {
+	case <-ctx.Done():
+	case <-t.drained:
+	}
+}
+
+// run looks up the pending messages every interval until ctx is done
+func (t *queueTracker) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		t.poll(ctx)
+	}
+}
+
+// poll looks up the oldest pending messages and records those that left the queue
+func (t *queueTracker) poll(ctx context.Context) {
+	t.mu.Lock()
+	ids := make([]int64, 0, len(t.pending))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for start := 0; start < len(ids); start += lookupBatch {
+		batch := ids[start:min(start+lookupBatch, len(ids))]
+		callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+		resp, err := t.client.GetMessages(callCtx, &pb.GetMessagesRequest{MessageIds: batch})
+		cancel()
+		if err != nil {
+			return
+		}
+		t.record(resp.Messages, time.Now())
+	}
+
+	t.mu.Lock()
+	if t.draining && len(t.pending) == 0 {
+		select {
+		case <-t.drained:
+		default:
+			close(t.drained)
+		}
+	}
+	t.mu.Unlock()
+}
+
+// record settles the messages no longer queued. For a message found sent, the latency is from
+// its creation to its last update; later statuses have moved that on, so those count until
+// they were seen.
+func (t *queueTracker) record(msgs []*pb.MessageResponse, seenAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, msg := range msgs {
+		acceptedAt, ok := t.pending[msg.Id]
+		if !ok || queuedStatuses[msg.Status] {
+			continue
+		}
+		delete(t.pending, msg.Id)
+		t.settled.statuses[msg.Status]++
+
+		latency := seenAt.Sub(acceptedAt)
+		if msg.Status == "sent" && msg.CreatedAtTs != nil && msg.UpdatedAtTs != nil {
+			latency = msg.UpdatedAtTs.AsTime().Sub(msg.CreatedAtTs.AsTime())
+		}
+		t.settled.latencies = append(t.settled.latencies, latency)
+	}
+}
+
+// result returns what was recorded
+func (t *queueTracker) result() queueResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := t.settled
+	result.pending = len(t.pending)
+	return result
+}
@@ -0,0 +1,174 @@
+// cmd/loadgen/report.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// queryDurationMetric is the service's histogram of repository query durations
+const queryDurationMetric = "whatsapp_db_query_duration_seconds"
+
+// latencies are observed durations
+type latencies []time.Duration
+
+// percentile returns the duration below which p (0 to 1) of the observations fall
+func (l latencies) percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	sorted := make(latencies, len(l))
+	copy(sorted, l)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
+
+func (l latencies) String() string {
+	if len(l) == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("p50 %s  p90 %s  p99 %s  max %s",
+		l.percentile(0.5).Round(time.Microsecond*100), l.percentile(0.9).Round(time.Microsecond*100),
+		l.percentile(0.99).Round(time.Microsecond*100), l.percentile(1).Round(time.Microsecond*100))
+}
+
+// histogram is the cumulative bucket counts of one query's durations, by upper bound
+type histogram struct {
+	count   uint64
+	buckets map[float64]uint64
+}
+
+// scrapeQueryDurations reads the successful query duration histograms from a metrics endpoint
+func scrapeQueryDurations(ctx context.Context, url string) (map[string]histogram, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	histograms := make(map[string]histogram)
+	family, ok := families[queryDurationMetric]
+	if !ok {
+		return histograms, nil
+	}
+	for _, metric := range family.Metric {
+		if label(metric, "result") != "success" || metric.Histogram == nil {
+			continue
+		}
+		h := histogram{count: metric.Histogram.GetSampleCount(), buckets: make(map[float64]uint64)}
+		for _, bucket := range metric.Histogram.Bucket {
+			h.buckets[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+		}
+		histograms[label(metric, "query")] = h
+	}
+	return histograms, nil
+}
+
+func label(metric *dto.Metric, name string) string {
+	for _, pair := range metric.Label {
+		if pair.GetName() == name {
+			return pair.GetValue()
+		}
+	}
+	return ""
+}
+
+// quantile estimates the q quantile of the observations made between before and h, as the
+// upper bound of the bucket it falls in
+func (h histogram) quantile(before histogram, q float64) float64 {
+	count := h.count - before.count
+	if count == 0 {
+		return 0
+	}
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	rank := uint64(math.Ceil(q * float64(count)))
+	for _, bound := range bounds {
+		if h.buckets[bound]-before.buckets[bound] >= rank {
+			return bound
+		}
+	}
+	return math.Inf(1)
+}
+
+// report prints the results of a run
+func report(w io.Writer, sends sendResult, queue queueResult, before, after map[string]histogram) {
+	ok := len(sends.latencies)
+	failed := 0
+	for _, count := range sends.errors {
+		failed += count
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Sends:          %d accepted, %d not (%.1f/s accepted over %s)\n",
+		ok, failed, float64(ok)/sends.elapsed.Seconds(), sends.elapsed.Round(time.Millisecond))
+	if failed > 0 {
+		codes := make([]string, 0, len(sends.errors))
+		for code, count := range sends.errors {
+			codes = append(codes, fmt.Sprintf("%s %d", code, count))
+		}
+		sort.Strings(codes)
+		fmt.Fprintf(w, "  not accepted: %s\n", strings.Join(codes, ", "))
+	}
+	fmt.Fprintf(w, "Send latency:   %s\n", sends.latencies)
+
+	left := len(queue.latencies)
+	fmt.Fprintf(w, "Queue latency:  %s\n", queue.latencies)
+	statuses := make([]string, 0, len(queue.statuses))
+	for status, count := range queue.statuses {
+		statuses = append(statuses, fmt.Sprintf("%s %d", status, count))
+	}
+	sort.Strings(statuses)
+	fmt.Fprintf(w, "  left queue:   %d (%s), still queued %d\n", left, strings.Join(statuses, ", "), queue.pending)
+
+	if after == nil {
+		return
+	}
+	fmt.Fprintln(w, "Database queries (bucket upper bounds):")
+	queries := make([]string, 0, len(after))
+	for query, h := range after {
+		if h.count > before[query].count {
+			queries = append(queries, query)
+		}
+	}
+	sort.Strings(queries)
+	for _, query := range queries {
+		h := after[query]
+		fmt.Fprintf(w, "  %-32s %7d  p50 <= %s  p99 <= %s\n", query, h.count-before[query].count,
+			seconds(h.quantile(before[query], 0.5)), seconds(h.quantile(before[query], 0.99)))
+	}
+}
+
+// seconds formats a bucket bound
+func seconds(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return time.Duration(bound * float64(time.Second)).String()
+}
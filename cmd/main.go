@@ -3,18 +3,27 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
-	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	"messaging-microservice/config"
@@ -22,67 +31,599 @@ import (
 	"messaging-microservice/internal/queue"
 	"messaging-microservice/internal/repository"
 	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/app"
 	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/mockprovider"
+	"messaging-microservice/pkg/objectstore"
+	"messaging-microservice/pkg/providerrouter"
+	"messaging-microservice/pkg/render"
+	"messaging-microservice/pkg/schemaregistry"
+	"messaging-microservice/pkg/twilio"
 	"messaging-microservice/pkg/utils"
 	pb "messaging-microservice/proto"
 )
 
 func main() {
 	// Initialize logger
-	logger := utils.NewLogger()
+	startedAt := time.Now()
+	logger := utils.NewLogger(utils.LogOptions{})
 	logger.Info("Starting WhatsApp Microservice")
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(os.Args[1:]...)
 	if err != nil {
 		logger.Fatal("Failed to load configuration", "error", err)
 	}
+	if cfg.ValidateOnly {
+		fmt.Print(cfg.Redacted())
+		return
+	}
 
-	// Connect to database
-	db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
-	if err != nil {
-		logger.Fatal("Failed to connect to database", "error", err)
+	// Log as configured from here on
+	logLevels := utils.NewLogLevels(cfg.LogLevel)
+	logger = utils.NewLogger(utils.LogOptions{
+		Levels:           logLevels,
+		Encoding:         cfg.LogEncoding,
+		File:             cfg.LogFile,
+		FileMaxSizeMB:    cfg.LogFileMaxSizeMB,
+		FileMaxBackups:   cfg.LogFileMaxBackups,
+		FileMaxAgeDays:   cfg.LogFileMaxAgeDays,
+		FileCompress:     cfg.LogFileCompress,
+		SampleInitial:    cfg.LogSampleInitial,
+		SampleThereafter: cfg.LogSampleThereafter,
+	})
+
+	// Components register start and stop hooks with the app, which runs them in order
+	application := app.New(logger)
+
+	// Dependencies may come up after us; wait for them with bounded retries before giving up
+	startupBackoff := utils.Backoff{
+		Attempts: cfg.StartupRetries,
+		Initial:  cfg.StartupRetryBackoff,
+		Max:      cfg.StartupRetryMaxBackoff,
 	}
-	defer db.Close()
 
-	// Initialize repository
+	// Connections shared by many components are built on first use
+	provideConnections(application, cfg, startupBackoff, logger)
+	db := resolve[*sqlx.DB](application, logger)
+
+	// Singleton background workers run on the replica holding their lock
+	elector := resolve[*service.LeaderElector](application, logger)
+
+	// Initialize repository, sending read paths to the replica when one is configured
 	messageRepo := repository.NewMessageRepository(db, logger)
+	if cfg.DatabaseReadURL != "" {
+		var replica *sqlx.DB
+		err := utils.Retry(context.Background(), startupBackoff, logger, "read replica", func(ctx context.Context) error {
+			var err error
+			replica, err = repository.Connect(ctx, cfg.DatabaseReadDSN)
+			return err
+		})
+		if err != nil {
+			logger.Fatal("Failed to connect to read replica", "error", err)
+		}
+		application.Closer("read_replica", replica)
+
+		replica.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+		replica.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+		replica.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+		replica.SetConnMaxIdleTime(cfg.DatabaseConnMaxIdleTime)
+		prometheus.MustRegister(collectors.NewDBStatsCollector(replica.DB, "whatsapp_replica"))
+
+		reads := repository.NewReadRouter(db, replica, cfg.DatabaseReplicaMaxLag, logger)
+		application.Go("replica_lag_check", func(ctx context.Context) { reads.Run(ctx, cfg.DatabaseReplicaCheckInterval) })
+		messageRepo = repository.NewReplicatedMessageRepository(db, reads, logger)
+	}
+
+	// The per-message hot paths can run on pgx with cached prepared statements
+	if cfg.DatabaseDriver == "pgx" {
+		var pool *pgxpool.Pool
+		err := utils.Retry(context.Background(), startupBackoff, logger, "database (pgx)", func(ctx context.Context) error {
+			var err error
+			pool, err = repository.ConnectPgx(ctx, cfg.DatabaseDSN, repository.PgxPoolConfig{
+				MaxConns:           int32(cfg.DatabaseMaxOpenConns),
+				MaxConnLifetime:    cfg.DatabaseConnMaxLifetime,
+				MaxConnIdleTime:    cfg.DatabaseConnMaxIdleTime,
+				StatementCacheSize: cfg.DatabaseStatementCacheSize,
+			})
+			return err
+		})
+		if err != nil {
+			logger.Fatal("Failed to connect to database with pgx", "error", err)
+		}
+		application.Append(app.Hook{Name: "database_pgx", OnStop: func(context.Context) error {
+			pool.Close()
+			return nil
+		}})
+		prometheus.MustRegister(repository.NewPgxPoolCollector(pool, "whatsapp_pgx"))
+		messageRepo = repository.NewPgxMessageRepository(messageRepo, pool, logger)
+	}
+	messageRepo = repository.NewInstrumentedMessageRepository(messageRepo)
+
+	// Webhook lookups by external ID are served from a cache filled at send time
+	redisClient := resolve[redis.UniversalClient](application, logger)
+	switch cfg.ExternalIDCache {
+	case "memory":
+		messageRepo = repository.NewCachedMessageRepository(messageRepo, repository.NewLRUExternalIDCache(cfg.ExternalIDCacheSize, cfg.ExternalIDCacheTTL))
+	case "redis":
+		messageRepo = repository.NewCachedMessageRepository(messageRepo, repository.NewRedisExternalIDCache(redisClient, cfg.ExternalIDCacheTTL, logger))
+	}
+
+	// Archived messages are read back from object storage transparently
+	var archiveStore objectstore.Store
+	if cfg.ArchiveAfterDays > 0 {
+		archiveStore = newArchiveStore(cfg, logger)
+		messageRepo = repository.NewArchivedMessageRepository(messageRepo, archiveStore, logger)
+	}
+
+	// Status hooks see every status transition; deployments register their own here (custom
+	// metrics, CRM sync, cache invalidation) instead of changing the message service
+	statusHooks := service.NewStatusHooks(logger)
+	if cfg.StatusTransitionMetrics {
+		statusHooks.Register(service.StatusTransitionMetrics)
+	}
+	// The template failure monitor is registered below, once the kill switch exists
+	if statusHooks.Len() > 0 || cfg.TemplateMaxFailureRate > 0 {
+		messageRepo = service.NewStatusHookedRepository(messageRepo, statusHooks, logger)
+	}
+
+	// Keep secrets from Vault or AWS Secrets Manager fresh
+	if cfg.Secrets != nil {
+		application.Go("secrets_refresh", func(ctx context.Context) { cfg.Secrets.Run(ctx, logger) })
+	}
+
+	// Readiness checks served at /ready
+	readinessChecks := map[string]handler.ReadinessCheck{
+		"database":   db.PingContext,
+		"components": application.Ready,
+	}
+
+	// Initialize WhatsApp client (Meta, or the mock provider for local development). Each
+	// provider is built once and counted in the per-provider send metrics.
+	providerTransport, err := utils.NewTransport(cfg.ProviderHTTPSProxy, cfg.ProviderCABundle)
+	if err != nil {
+		logger.Fatal("Failed to configure provider HTTP transport", "error", err)
+	}
+	var capturingTransport http.RoundTripper = providerTransport
+	if cfg.ProviderCapture {
+		capturingTransport = utils.NewCapturingTransport(providerTransport, cfg.ProviderCaptureMaxBody)
+		logger.Warn("Provider capture enabled; provider requests and responses of sends are stored", "retention", cfg.ProviderCaptureRetention)
+	}
+	twilioCatalog := twilio.NewCatalog(cfg.TwilioDefaultLocale, render.NewEngine(render.Options{Safe: cfg.TemplateSafeMode}))
+	providerClients := make(map[string]meta.Client)
+	typingIndicators := make(map[string]meta.TypingIndicator)
+	templateSources := make(map[string]meta.TemplateSource)
+	textTemplateSources := make(map[string]meta.TextTemplateSource)
+	providerClient := func(provider string) meta.Client {
+		if client, ok := providerClients[provider]; ok {
+			return client
+		}
+		client := newWhatsAppClient(application, provider, cfg, capturingTransport, twilioCatalog, readinessChecks, utils.WithComponent(logger, "provider_"+provider))
+		if typing, ok := client.(meta.TypingIndicator); ok {
+			typingIndicators[provider] = typing
+		}
+		if source, ok := client.(meta.TemplateSource); ok {
+			templateSources[provider] = source
+		}
+		if texts, ok := client.(meta.TextTemplateSource); ok {
+			textTemplateSources[provider] = texts
+		}
+		providerClients[provider] = providerrouter.NewInstrumentedClient(provider, client)
+		return providerClients[provider]
+	}
 
-	// Initialize WhatsApp client (now using Meta)
-	whatsappClient := meta.NewClient(cfg.MetaPhoneNumberID, cfg.MetaAccessToken, cfg.MetaAppSecret, logger)
+	whatsappClient := providerClient(cfg.WhatsAppProvider)
+	if cfg.FailoverProvider != "" {
+		whatsappClient = providerrouter.NewFailoverClient(whatsappClient, providerClient(cfg.FailoverProvider), providerrouter.FailoverConfig{
+			PrimaryName:    cfg.WhatsAppProvider,
+			SecondaryName:  cfg.FailoverProvider,
+			MaxErrorRate:   cfg.FailoverMaxErrorRate,
+			MaxLatency:     cfg.FailoverMaxLatency,
+			Window:         cfg.FailoverWindow,
+			MinSamples:     cfg.FailoverMinSamples,
+			ProbeInterval:  cfg.FailoverProbeInterval,
+			RecoveryProbes: cfg.FailoverRecoveryProbes,
+			OnSwitch:       newProviderEventPublisher(cfg, logger),
+		}, logger)
+		logger.Info("Provider failover enabled", "primary", cfg.WhatsAppProvider, "secondary", cfg.FailoverProvider)
+	}
+	if cfg.CanaryProvider != "" {
+		whatsappClient = providerrouter.NewCanaryClient(whatsappClient, providerClient(cfg.CanaryProvider), providerrouter.CanaryConfig{
+			CanaryName:     cfg.CanaryProvider,
+			Percent:        cfg.CanaryPercent,
+			MaxFailureRate: cfg.CanaryMaxFailureRate,
+			Window:         cfg.CanaryWindow,
+			MinSamples:     cfg.CanaryMinSamples,
+			Cooldown:       cfg.CanaryCooldown,
+		}, logger)
+		logger.Info("Routing canary share of sends", "provider", cfg.CanaryProvider, "percent", cfg.CanaryPercent)
+	}
+
+	// Pace sends and stop them while the provider keeps failing; the consumer stops reading
+	// while either holds sends back, so queued messages wait in the topic
+	var sendGates []queue.Gate
+	var sendPacer service.RateScaler
+	if cfg.ProviderSendRate != "" {
+		limit, err := utils.ParseRateLimit(cfg.ProviderSendRate)
+		if err != nil {
+			logger.Fatal("Invalid provider send rate", "error", err)
+		}
+		paced := providerrouter.NewPacedClient(whatsappClient, newRateLimiter(redisClient, logger), limit, logger)
+		whatsappClient = paced
+		sendGates = append(sendGates, paced)
+		sendPacer = paced
+	}
+	if cfg.ProviderBreakerFailures > 0 {
+		breaker := providerrouter.NewBreakerClient(whatsappClient, providerrouter.BreakerConfig{
+			FailureThreshold: cfg.ProviderBreakerFailures,
+			Cooldown:         cfg.ProviderBreakerCooldown,
+			IsHealthError:    providerrouter.IsDegradedError,
+		}, logger)
+		whatsappClient = breaker
+		sendGates = append(sendGates, breaker)
+	}
+
+	// Wait for Kafka and the topics this service reads and writes, creating them if enabled
+	err = utils.Retry(context.Background(), startupBackoff, logger, "kafka", func(ctx context.Context) error {
+		created, err := queue.EnsureTopics(ctx, cfg.KafkaBrokers, topicSpecs(cfg), cfg.KafkaAutoCreateTopics)
+		if len(created) > 0 {
+			logger.Info("Created Kafka topics", "topics", created)
+		}
+		return err
+	})
+	if err != nil {
+		logger.Fatal("Kafka is not available", "error", err)
+	}
 
 	// Initialize message queue
-	messageProducer, err := queue.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic, logger)
+	producerConfig := queue.ProducerConfig{
+		BatchSize:    cfg.KafkaBatchSize,
+		BatchBytes:   int64(cfg.KafkaBatchBytes),
+		BatchTimeout: cfg.KafkaBatchTimeout,
+		Compression:  cfg.KafkaCompression,
+		WriteTimeout: utils.CallTimeout{Floor: cfg.KafkaProduceTimeoutFloor, Ceiling: cfg.KafkaProduceTimeoutCeiling},
+	}
+	sendProducerConfig := producerConfig
+	sendProducerConfig.Async = cfg.KafkaProducerAsync
+	sendProducerConfig.OnDelivery = service.NewEnqueueReporter(messageRepo, logger)
+	messageProducer, err := queue.NewProducerWithConfig(cfg.KafkaBrokers, cfg.KafkaTopic, sendProducerConfig, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize Kafka producer", "error", err)
 	}
-	defer messageProducer.Close()
+	application.Closer("message_producer", messageProducer)
+
+	// Initialize status event producer. Only the send topic can be async, since its delivery
+	// reports have a message row to mark failed.
+	statusProducer, err := queue.NewProducerWithConfig(cfg.KafkaBrokers, cfg.KafkaStatusTopic, producerConfig, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize Kafka status producer", "error", err)
+	}
+	application.Closer("status_producer", statusProducer)
 
 	// Initialize consumer
-	messageConsumer, err := queue.NewConsumer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID, logger)
+	messageConsumer, err := queue.NewGatedConsumer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID, queue.AllGates(sendGates...), utils.WithComponent(logger, "message_consumer"))
 	if err != nil {
 		logger.Fatal("Failed to initialize Kafka consumer", "error", err)
 	}
 
-	// Initialize services
-	messageService := service.NewMessageService(messageRepo, whatsappClient, messageProducer, logger)
-	webhookService := service.NewWebhookService(messageRepo, messageProducer, logger, cfg.MetaVerifyToken)
+	// Write Avro with registered schemas instead of JSON when a schema registry is configured
+	consumeHandler := func(handler queue.MessageHandler) queue.MessageHandler { return handler }
+	sendTopicProducer := func(producer queue.Producer) queue.Producer { return producer }
+	if cfg.SchemaRegistryURL != "" {
+		registry := schemaregistry.NewClient(schemaregistry.Config{
+			URL:      cfg.SchemaRegistryURL,
+			Username: cfg.SchemaRegistryUsername,
+			Password: cfg.SchemaRegistryPassword,
+		})
+		messageSerializer, err := schemaregistry.NewSerializer(context.Background(), registry, schemaregistry.SubjectForTopic(cfg.KafkaTopic), service.QueueMessageAvroSchema)
+		if err != nil {
+			logger.Fatal("Failed to register queue message schema", "error", err)
+		}
+		statusSerializer, err := schemaregistry.NewSerializer(context.Background(), registry, schemaregistry.SubjectForTopic(cfg.KafkaStatusTopic), service.WebhookEventAvroSchema)
+		if err != nil {
+			logger.Fatal("Failed to register status event schema", "error", err)
+		}
+		sendTopicProducer = func(producer queue.Producer) queue.Producer {
+			return queue.NewRegistryProducer(producer, messageSerializer)
+		}
+		messageProducer = sendTopicProducer(messageProducer)
+		statusProducer = queue.NewRegistryProducer(statusProducer, statusSerializer)
+		deserializer := schemaregistry.NewDeserializer(registry)
+		consumeHandler = func(handler queue.MessageHandler) queue.MessageHandler {
+			return queue.RegistryHandler(handler, deserializer)
+		}
+		logger.Info("Producing Avro payloads with registered schemas")
+	}
 
-	// Start consumer
-	go func() {
-		logger.Info("Starting message consumer")
-		messageConsumer.Consume(context.Background(), messageService.ProcessQueueMessage)
-	}()
+	// Phone numbers leaving the OLTP store (events, exports) are pseudonymized when a key is set
+	phoneHasher := utils.NewPlainPhoneNumberHasher()
+	if cfg.PhoneHashKey != "" {
+		phoneHasher = utils.NewHMACPhoneNumberHasher(cfg.PhoneHashKey)
+	}
 
-	// Start gRPC server
-	go func() {
-		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+	// Initialize services
+	messageService := service.NewMessageService(messageRepo, whatsappClient, messageProducer, utils.WithComponent(logger, "message_service"))
+	outboxRepo := repository.NewOutboxRepository(db, logger)
+	historyRepo := repository.NewStatusHistoryRepository(db, logger)
+	if cfg.MessageOutbox {
+		messageService = service.NewMessageServiceWithOutbox(messageRepo, whatsappClient, messageProducer, service.MessageOutbox{
+			Work:    repository.NewUnitOfWork(db),
+			Outbox:  outboxRepo,
+			History: historyRepo,
+		}, logger)
+		outboxRelay := service.NewOutboxRelay(outboxRepo, messageProducer, cfg.OutboxRelayGrace, logger)
+		runSingleton(application, elector, "outbox_relay", func(ctx context.Context) { outboxRelay.Run(ctx, cfg.OutboxRelayInterval) })
+	}
+	captureRepo := repository.NewProviderCaptureRepository(db, logger)
+	if cfg.ProviderCapture {
+		messageService = service.NewCapturingMessageService(messageService, captureRepo, logger)
+	}
+	providerCaptures := service.NewProviderCaptureService(captureRepo, cfg.ProviderCapture, logger)
+	quotas := quotaPolicy(cfg, logger)
+	quotaService := service.NewQuotaService(repository.NewQuotaRepository(db, logger), quotas, logger)
+	if quotas.Enabled() {
+		messageService = service.NewQuotaEnforcingMessageService(messageService, quotaService, messageRepo, cfg.QuotaExceededAction == "record", logger)
+	}
+	// Admin changes and data subject requests are recorded in the audit log, and published to
+	// AUDIT_TOPIC when it is set
+	var auditProducer queue.Producer
+	if cfg.AuditTopic != "" {
+		auditProducer, err = queue.NewProducerWithConfig(cfg.KafkaBrokers, cfg.AuditTopic, producerConfig, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize Kafka audit producer", "error", err)
+		}
+		application.Closer("audit_producer", auditProducer)
+	}
+	auditLog := service.NewAuditLog(repository.NewAuditRepository(db, logger), auditProducer, logger)
+	pauseService := service.NewAuditedPauseService(service.NewPauseService(repository.NewPauseRepository(db, logger), messageRepo, messageProducer, cfg.MaintenanceMode, logger), auditLog, cfg.AdminActors, logger)
+	if _, err := pauseService.Refresh(context.Background()); err != nil {
+		logger.Error("Failed to load send pauses", "error", err)
+	}
+	if cfg.MaintenanceMode {
+		logger.Warn("Maintenance mode: outbound sends are paused")
+	}
+	messageService = service.NewPausableMessageService(messageService, pauseService, messageRepo, logger)
+	quietHours, err := service.NewQuietHoursScheduler(service.QuietHoursConfig{
+		Default:            cfg.QuietHours,
+		Tenants:            cfg.QuietHoursTenants,
+		MarketingTemplates: cfg.MarketingTemplates,
+	}, messageRepo, messageProducer, logger)
+	if err != nil {
+		logger.Fatal("Invalid quiet hours", "error", err)
+	}
+	messageService = service.NewQuietHoursMessageService(messageService, quietHours, messageRepo, logger)
+	templateSwitch := service.NewAuditedTemplateSwitch(service.NewTemplateSwitch(repository.NewTemplateRepository(db, logger), logger), auditLog, cfg.AdminActors, logger)
+	if err := templateSwitch.Refresh(context.Background()); err != nil {
+		logger.Error("Failed to load disabled templates", "error", err)
+	}
+	messageService = service.NewTemplateGuardedMessageService(messageService, templateSwitch, messageRepo, logger)
+	if cfg.TemplateMaxFailureRate > 0 {
+		statusHooks.Register(service.NewTemplateFailureMonitor(templateSwitch, service.TemplateFailurePolicy{
+			MaxFailureRate: cfg.TemplateMaxFailureRate,
+			Window:         cfg.TemplateFailureWindow,
+			MinMessages:    cfg.TemplateFailureMinMessages,
+		}, alerts.NewMultiNotifier(nonNilNotifiers(alertNotifier(cfg), templateAlertNotifier(cfg))...), logger))
+	}
+	countryPolicy := service.NewAuditedCountryPolicy(service.NewCountryPolicy(repository.NewCountryRuleRepository(db, logger), cfg.CountryAllowlist, cfg.CountryBlocklist, logger), auditLog, cfg.AdminActors, logger)
+	if err := countryPolicy.Refresh(context.Background()); err != nil {
+		logger.Error("Failed to load country rules", "error", err)
+	}
+	messageService = service.NewCountryRestrictedMessageService(messageService, countryPolicy, messageRepo, logger)
+	optInRepo := repository.NewOptInRepository(db, logger)
+	optIns := service.NewOptInService(optInRepo, cfg.MarketingTemplates, logger)
+	if cfg.OptInRequired {
+		messageService = service.NewOptInRequiredMessageService(messageService, optIns, messageRepo, logger)
+	}
+	if cfg.DuplicateWindow > 0 {
+		messageService = service.NewDuplicateSuppressingMessageService(messageService, messageRepo, cfg.DuplicateWindow, logger)
+	}
+	messageService = service.NewAuditedMessageService(messageService, auditLog, cfg.AdminActors, logger)
+	// Outermost, so synchronous sends go through every check a queued message does
+	messageService = service.NewSynchronousMessageService(messageService, messageRepo, logger)
+	segmentRepo := repository.NewSegmentRepository(db, logger)
+	campaigns := service.NewCampaignService(segmentRepo, repository.NewCampaignRepository(db, logger), messageService, cfg.CampaignDispatchBatch, logger)
+	// Templates are previewed from the primary provider's definitions
+	templatePreviews := service.NewTemplatePreviewService(templateSources[cfg.WhatsAppProvider], textTemplateSources[cfg.WhatsAppProvider], templateAccounts(cfg), logger)
+	conversationRepo := repository.NewConversationRepository(db, logger)
+	webhookFailureRepo := repository.NewWebhookFailureRepository(db, logger)
+	privacyService := service.NewPrivacyServiceWithStores(messageRepo, auditLog, service.PrivacyStores{
+		Captures:        captureRepo,
+		Conversations:   conversationRepo,
+		OptIns:          optInRepo,
+		Contacts:        segmentRepo,
+		WebhookFailures: webhookFailureRepo,
+	}, phoneHasher, logger)
+	var handoffChannel service.HandoffChannel
+	switch cfg.HandoffChannel {
+	case "kafka":
+		handoffProducer, err := queue.NewProducerWithConfig(cfg.KafkaBrokers, cfg.HandoffTopic, producerConfig, logger)
 		if err != nil {
-			logger.Fatal("Failed to listen for gRPC", "error", err)
+			logger.Fatal("Failed to initialize Kafka handoff producer", "error", err)
 		}
+		application.Closer("handoff_producer", handoffProducer)
+		handoffChannel = service.NewKafkaHandoffChannel(handoffProducer)
+	case "webhook":
+		handoffChannel = service.NewWebhookHandoffChannel(cfg.HandoffWebhookURL, cfg.HandoffWebhookToken)
+	}
+	// Bots answering inbound messages are registered here; messages none of them handles go to an agent
+	// Typing indicators go out through the primary provider, which inbound messages arrive on
+	typingIndicator := typingIndicators[cfg.WhatsAppProvider]
+	inboundPipeline := service.NewInboundPipeline(logger,
+		service.RecoverInbound(),
+		service.WithTypingIndicator(typingIndicator, cfg.InboundTypingDelay, logger),
+		service.WithInboundTimeout(cfg.InboundHandlerTimeout),
+	)
+	logger.Info("Inbound handlers registered", "count", inboundPipeline.Len())
+	inboundService := service.NewInboundServiceWithHandler(conversationRepo, inboundPipeline, typingIndicator, handoffChannel, cfg.HandoffContextMessages, logger)
+	accountQuality := service.NewAccountQualityService(repository.NewAccountQualityRepository(db, logger), sendPacer, cfg.QualityRedRateFactor, logger)
+	templateEvents := service.NewTemplateEventService(templateSwitch, templateAlertNotifier(cfg), logger)
+	webhookService := service.NewWebhookServiceWithHandlers(messageRepo, statusProducer, service.NewStaticTenantResolver(webhookTenants(cfg)), phoneHasher, service.WebhookHandlers{
+		Inbound:    inboundService,
+		Accounts:   accountQuality,
+		Templates:  templateEvents,
+		Signatures: metaWebhookSignatures(providerClients),
+		Replay:     webhookReplayGuard(cfg, redisClient, logger),
+
+		Failures:          webhookFailureRepo,
+		MaxReplayAttempts: cfg.WebhookFailureMaxAttempts,
+		Quarantine:        statusQuarantine(cfg, db, logger),
+		QuarantineWindow:  cfg.WebhookQuarantineWindow,
+		LookupWait:        cfg.WebhookLookupWait,
+	}, utils.WithComponent(logger, "webhook"), cfg.MetaVerifyToken)
+
+	// Keep send pauses, disabled templates, Twilio template bodies and country rules in sync with
+	// the other replicas
+	application.Go("pause_refresh", func(ctx context.Context) { pauseService.Run(ctx, cfg.PauseRefreshInterval) })
+	application.Go("template_switch_refresh", func(ctx context.Context) { templateSwitch.Run(ctx, cfg.TemplateRefreshInterval) })
+	if _, ok := providerClients["twilio"]; ok {
+		templateCatalog := service.NewTemplateCatalog(repository.NewTemplateTranslationRepository(db, logger), cfg.TwilioCatalogFile, twilioCatalog, logger)
+		application.Go("template_catalog_refresh", func(ctx context.Context) { templateCatalog.Run(ctx, cfg.TemplateRefreshInterval) })
+	}
+	application.Go("country_policy_refresh", func(ctx context.Context) { countryPolicy.Run(ctx, cfg.CountryRefreshInterval) })
+
+	// Slow sends down while a phone number's quality rating is low
+	application.Go("account_quality_refresh", func(ctx context.Context) { accountQuality.Run(ctx, cfg.QualityRefreshInterval) })
+
+	// Re-enqueue marketing messages deferred by quiet hours once their window ends
+	runSingleton(application, elector, "quiet_hours_release", func(ctx context.Context) { quietHours.Run(ctx, cfg.QuietHoursReleaseInterval) })
+
+	// Send started campaigns to their audiences
+	runSingleton(application, elector, "campaign_dispatch", func(ctx context.Context) { campaigns.Run(ctx, cfg.CampaignDispatchInterval) })
+
+	// Replay the changes of Meta webhooks that failed
+	runSingleton(application, elector, "webhook_replay", func(ctx context.Context) { webhookService.Run(ctx, cfg.WebhookFailureReplayInterval) })
+
+	// Apply statuses that arrived before their message's external ID was stored
+	runSingleton(application, elector, "webhook_quarantine", func(ctx context.Context) { webhookService.RunQuarantine(ctx, cfg.WebhookQuarantineInterval) })
 
-		grpcServer := grpc.NewServer()
-		grpcHandler := handler.NewGrpcMessageHandler(messageService, logger)
+	// Transient send failures move through the delayed retry topics and finally the DLQ; each
+	// stage's failures are produced to the next stage's topic
+	sendHandler := consumeHandler(messageService.ProcessQueueMessage)
+	var retryConsumers []queue.Consumer
+	if len(cfg.KafkaRetryDelays) > 0 {
+		retryConfig := service.NewSendRetryConfig(cfg.KafkaRetryDelays, messageRepo, logger)
+		stageTopics := append([]string{cfg.KafkaTopic}, queue.RetryChainTopics(cfg.KafkaTopic, cfg.KafkaRetryDelays)...)
+
+		stageHandlers := make([]queue.MessageHandler, len(cfg.KafkaRetryDelays)+1)
+		for stage := range stageHandlers {
+			next, err := queue.NewProducerWithConfig(cfg.KafkaBrokers, stageTopics[stage+1], producerConfig, logger)
+			if err != nil {
+				logger.Fatal("Failed to initialize Kafka retry producer", "error", err, "topic", stageTopics[stage+1])
+			}
+			application.Closer("retry_producer_"+stageTopics[stage+1], next)
+			stageHandlers[stage] = consumeHandler(queue.RetryHandler(messageService.ProcessQueueMessage, stage, sendTopicProducer(next), retryConfig, logger))
+		}
+		sendHandler = stageHandlers[0]
+
+		for i, delay := range cfg.KafkaRetryDelays {
+			retryConsumer, err := queue.NewDelayedConsumer(cfg.KafkaBrokers, stageTopics[i+1], cfg.KafkaGroupID, delay, queue.AllGates(sendGates...), utils.WithComponent(logger, "retry_consumer_"+stageTopics[i+1]))
+			if err != nil {
+				logger.Fatal("Failed to initialize Kafka retry consumer", "error", err, "topic", stageTopics[i+1])
+			}
+			retryConsumers = append(retryConsumers, retryConsumer)
+			runConsumer(application, cfg, "retry_consumer_"+stageTopics[i+1], retryConsumer, stageHandlers[i+1])
+		}
+		logger.Info("Retrying transient send failures", "delays", cfg.KafkaRetryDelays)
+	}
+
+	// Start consumer
+	runConsumer(application, cfg, "message_consumer", messageConsumer, sendHandler)
+
+	// Keep the lag gauge current and alert on lag or handler failures
+	consumerMonitor := queue.NewConsumerMonitor(messageConsumer, queue.MonitorConfig{
+		MaxLag:         int64(cfg.ConsumerMaxLag),
+		MaxFailureRate: cfg.ConsumerMaxFailureRate,
+		MinMessages:    int64(cfg.ConsumerAlertMinMessages),
+	}, alertNotifier(cfg), logger)
+	application.Go("consumer_monitor", func(ctx context.Context) { consumerMonitor.Run(ctx, cfg.ConsumerMonitorInterval) })
+
+	// Alert on send failures, dead letters, webhook signature failures and token expiry. Each
+	// replica judges its own counters; the shared dead-letter topic and token are watched by
+	// one replica.
+	replicaRules, sharedRules := alertRules(cfg)
+	if len(replicaRules) > 0 {
+		replicaAlerts := alerts.NewEngine(replicaRules, alertNotifier(cfg), logger)
+		application.Go("alert_rules", func(ctx context.Context) { replicaAlerts.Run(ctx, cfg.AlertEvaluationInterval) })
+	}
+	if len(sharedRules) > 0 {
+		sharedAlerts := alerts.NewEngine(sharedRules, alertNotifier(cfg), logger)
+		runSingleton(application, elector, "shared_alert_rules", func(ctx context.Context) { sharedAlerts.Run(ctx, cfg.AlertEvaluationInterval) })
+	}
+
+	// Start maintenance job: partition rotation and retention purge. It always runs since
+	// provider captures and outbox payloads outlive PROVIDER_CAPTURE and MESSAGE_OUTBOX being
+	// switched off until they are purged, and webhook failures are kept for
+	// WEBHOOK_FAILURE_RETENTION whatever else is configured.
+	var partitionRepo repository.PartitionRepository
+	if cfg.MessagePartitionsAhead > 0 {
+		partitionRepo = repository.NewPartitionRepository(db, logger)
+	}
+	retentionService := service.NewRetentionServiceWithStores(messageRepo, partitionRepo, service.RetentionStores{
+		Captures:        captureRepo,
+		WebhookFailures: webhookFailureRepo,
+		Inbound:         conversationRepo,
+		Outbox:          outboxRepo,
+		History:         historyRepo,
+	}, service.RetentionPolicy{
+		MessageMaxAge:         time.Duration(cfg.RetentionMessageDays) * 24 * time.Hour,
+		WebhookEventMaxAge:    time.Duration(cfg.RetentionWebhookEventDays) * 24 * time.Hour,
+		OutboxMaxAge:          cfg.OutboxRetention,
+		ProviderCaptureMaxAge: cfg.ProviderCaptureRetention,
+		WebhookFailureMaxAge:  cfg.WebhookFailureRetention,
+		BatchSize:             cfg.RetentionBatchSize,
+		PartitionMonthsAhead:  cfg.MessagePartitionsAhead,
+	}, logger)
+	runSingleton(application, elector, "retention", func(ctx context.Context) { retentionService.Run(ctx, cfg.RetentionInterval) })
+	logger.Info("Started maintenance job", "message_days", cfg.RetentionMessageDays, "webhook_event_days", cfg.RetentionWebhookEventDays, "outbox_retention", cfg.OutboxRetention, "provider_capture_retention", cfg.ProviderCaptureRetention, "webhook_failure_retention", cfg.WebhookFailureRetention, "partitions_ahead", cfg.MessagePartitionsAhead, "interval", cfg.RetentionInterval)
+
+	// Start archive job
+	if archiveStore != nil {
+		archiveService := service.NewArchiveService(messageRepo, archiveStore, service.ArchivePolicy{
+			MaxHotAge: time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour,
+			BatchSize: cfg.ArchiveBatchSize,
+		}, logger)
+		runSingleton(application, elector, "archive", func(ctx context.Context) { archiveService.Run(ctx, cfg.ArchiveInterval) })
+		logger.Info("Started message archive job", "after_days", cfg.ArchiveAfterDays, "store", cfg.ArchiveStore, "interval", cfg.ArchiveInterval)
+	}
+
+	// gRPC, export and audience upload callers authenticate with the same API keys
+	credentials := grpcCredentials(cfg)
+	if len(credentials) == 0 {
+		logger.Warn("GRPC_API_KEYS is empty: gRPC and HTTP API callers are not authenticated and pick their tenant with x-tenant-id")
+	}
+
+	// gRPC server
+	{
+		sendLimiter := handler.NewSendLimiter(cfg.SendMaxInFlight, cfg.SendMaxQueued, cfg.SendQueueTimeout, cfg.SendRetryAfter)
+		grpcServer := grpc.NewServer(append(grpcServerOptions(cfg),
+			grpc.ChainUnaryInterceptor(
+				handler.TimeoutInterceptor(cfg.WriteTimeout),
+				handler.TenantInterceptor(credentials),
+				handler.ValidationInterceptor(),
+				handler.CallerLimitInterceptor(newRateLimiter(redisClient, logger), newWindowCounter(redisClient, logger), callerLimitPolicy(cfg, logger), logger),
+				handler.SendLimitInterceptor(sendLimiter, logger),
+			),
+			grpc.ChainStreamInterceptor(
+				handler.TenantStreamInterceptor(credentials),
+				handler.ValidationStreamInterceptor(),
+			),
+		)...)
+		serviceInfo := handler.ServiceInfo{
+			Provider:         cfg.WhatsAppProvider,
+			MaxInFlightSends: cfg.SendMaxInFlight,
+			MaxQueuedSends:   cfg.SendMaxQueued,
+			CatalogID:        cfg.MetaCatalogID,
+			Instance: handler.InstanceInfo{
+				ID:        cfg.InstanceID,
+				StartedAt: startedAt,
+				Consumers: append([]queue.Consumer{messageConsumer}, retryConsumers...),
+				Sends:     sendLimiter,
+				Leader:    elector,
+				Logs:      logLevels,
+			},
+		}
+		grpcHandler := handler.NewGrpcMessageHandler(messageService, privacyService, quotaService, pauseService, templateSwitch, countryPolicy, inboundService, accountQuality, auditLog, providerCaptures, optIns, campaigns, templatePreviews, serviceInfo, phoneHasher, logger)
 		pb.RegisterWhatsAppServiceServer(grpcServer, grpcHandler)
 
 		// Register reflection service on gRPC server (for debugging)
@@ -90,11 +631,11 @@ func main() {
 			reflection.Register(grpcServer)
 		}
 
-		logger.Info("Starting gRPC server", "port", cfg.GRPCPort)
-		if err := grpcServer.Serve(lis); err != nil {
-			logger.Fatal("Failed to serve gRPC", "error", err)
-		}
-	}()
+		logger.Info("Starting gRPC server", "port", cfg.GRPCPort, "api_version", handler.APIVersion)
+		application.Serve("grpc_server", ":"+cfg.GRPCPort, grpcServer.Serve, func(ctx context.Context) error {
+			return stopGRPCServer(ctx, grpcServer)
+		})
+	}
 
 	// Initialize HTTP server for webhooks
 	router := gin.Default()
@@ -102,51 +643,612 @@ func main() {
 	// Register middleware
 	router.Use(gin.Recovery())
 	router.Use(utils.RequestLogger(logger))
+	router.Use(utils.RateLimiterMiddleware(newRateLimiter(redisClient, logger), rateLimitPolicy(cfg, logger), logger))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "up"})
 	})
+	router.GET("/ready", handler.NewReadinessHandler(readinessChecks, logger).HandleReady)
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Webhook handler
 	webhookHandler := handler.NewWebhookHandler(webhookService, logger)
 	router.POST("/webhook", webhookHandler.HandleWebhook)
+	if twilioClient, ok := providerClients["twilio"]; ok {
+		twilioHandler := handler.NewTwilioWebhookHandler(webhookService, twilioClient.ValidateWebhookSignature, cfg.TwilioStatusCallbackURL, logger)
+		router.POST("/webhook/twilio", twilioHandler.HandleStatusCallback)
+	}
+
+	// Message export endpoint
+	exportHandler := handler.NewExportHandler(messageService, phoneHasher, logger)
+	router.GET("/export/messages", handler.TenantMiddleware(credentials), exportHandler.HandleExport)
+
+	// Campaign audience upload endpoint
+	audienceImportHandler := handler.NewAudienceImportHandler(campaigns, logger)
+	router.POST("/campaigns/:campaign_id/audience", handler.TenantMiddleware(credentials), audienceImportHandler.HandleImport)
+
+	// REST/JSON gateway for the gRPC API
+	gatewayHandler, err := handler.NewGatewayHandler(application.Context(), "localhost:"+cfg.GRPCPort,
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(cfg.GRPCMaxRecvMsgSize), grpc.MaxCallRecvMsgSize(cfg.GRPCMaxSendMsgSize)),
+	)
+	if err != nil {
+		logger.Fatal("Failed to initialize REST gateway", "error", err)
+	}
+	router.Any("/v1/*path", gin.WrapH(gatewayHandler))
+	router.GET("/openapi.json", handler.HandleOpenAPISpec)
 
 	// Start HTTP server
 	srv := &http.Server{
-		Addr:    ":" + cfg.HTTPPort,
-		Handler: router,
+		Addr:         ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
 	}
 
-	// Graceful shutdown
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start HTTP server", "error", err)
+	application.Serve("http_server", srv.Addr, func(lis net.Listener) error {
+		if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}, srv.Shutdown)
+
+	// Start everything, then stop it in reverse order on SIGINT or SIGTERM
+	if err := application.Run(cfg.ShutdownTimeout, syscall.SIGINT, syscall.SIGTERM); err != nil {
+		logger.Fatal("Server stopped with errors", "error", err)
+	}
+	logger.Info("Server exited gracefully")
+}
+
+// grpcServerOptions applies the configured message size, stream and keepalive limits
+func grpcServerOptions(cfg *config.Config) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ConnectionTimeout(cfg.ReadTimeout),
+		grpc.MaxRecvMsgSize(cfg.GRPCMaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.GRPCMaxSendMsgSize),
+		grpc.MaxConcurrentStreams(uint32(cfg.GRPCMaxConcurrentStreams)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.GRPCMaxConnectionIdle,
+			MaxConnectionAge:      cfg.GRPCMaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.GRPCMaxConnectionAgeGrace,
+			Time:                  cfg.GRPCKeepaliveTime,
+			Timeout:               cfg.GRPCKeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPCKeepaliveMinTime,
+			PermitWithoutStream: cfg.GRPCKeepalivePermitWithoutStream,
+		}),
+	}
+}
+
+// topicSpecs lists the topics the service needs: sends, status events, the send retry tiers
+// and DLQ, and provider events, handoffs and audit entries when enabled, laid out as configured
+func topicSpecs(cfg *config.Config) []queue.TopicSpec {
+	names := append([]string{cfg.KafkaTopic, cfg.KafkaStatusTopic}, queue.RetryChainTopics(cfg.KafkaTopic, cfg.KafkaRetryDelays)...)
+	if cfg.KafkaProviderEventsTopic != "" {
+		names = append(names, cfg.KafkaProviderEventsTopic)
+	}
+	if cfg.HandoffChannel == "kafka" {
+		names = append(names, cfg.HandoffTopic)
+	}
+	if cfg.AuditTopic != "" {
+		names = append(names, cfg.AuditTopic)
+	}
+
+	specs := make([]queue.TopicSpec, 0, len(names))
+	for _, name := range names {
+		spec := queue.TopicSpec{
+			Name:              name,
+			Partitions:        cfg.KafkaTopicPartitions,
+			ReplicationFactor: cfg.KafkaTopicReplicationFactor,
+		}
+		if layout, ok := cfg.KafkaTopicLayouts[name]; ok {
+			// Validated with the rest of the configuration
+			partitions, replicationFactor, _ := queue.ParseTopicLayout(layout)
+			spec.Partitions = partitions
+			if replicationFactor > 0 {
+				spec.ReplicationFactor = replicationFactor
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// newWhatsAppClient creates the client of a provider ("meta", "twilio" or "mock"); the Meta
+// client's token health is registered as a metric and readiness check
+func newWhatsAppClient(application *app.App, provider string, cfg *config.Config, transport http.RoundTripper, catalog *twilio.Catalog, readinessChecks map[string]handler.ReadinessCheck, logger utils.Logger) meta.Client {
+	if provider == "twilio" {
+		return twilio.NewClient(twilio.Config{
+			AccountSID:          cfg.TwilioAccountSID,
+			AuthToken:           cfg.TwilioAuthToken,
+			From:                cfg.TwilioFrom,
+			MessagingServiceSID: cfg.TwilioMessagingServiceSID,
+			ContentSIDs:         cfg.TwilioContentSIDs,
+			StatusCallbackURL:   cfg.TwilioStatusCallbackURL,
+			Catalog:             catalog,
+			CallTimeout:         providerTimeout(cfg),
+			HTTP:                providerHTTPClient(cfg, transport),
+		}, logger)
+	}
+
+	if provider == "mock" {
+		client := mockprovider.NewClient(mockprovider.Config{
+			PhoneNumberID:  cfg.MetaPhoneNumberID,
+			AppSecret:      cfg.MetaAppSecret,
+			WebhookURL:     cfg.MockWebhookURL,
+			DeliveredDelay: cfg.MockDeliveredDelay,
+			ReadDelay:      cfg.MockReadDelay,
+			FailureRate:    cfg.MockFailureRate,
+		}, logger)
+		logger.Warn("Using mock WhatsApp provider; no real messages will be sent", "webhook_url", cfg.MockWebhookURL)
+		return client
+	}
+
+	meta.CheckAPIVersion(cfg.MetaAPIVersion, time.Now(), cfg.MetaAPIVersionWarnBefore, logger)
+	tokenManager := meta.NewTokenManager(meta.TokenManagerConfig{
+		AccessToken:   cfg.MetaAccessToken,
+		AppID:         cfg.MetaAppID,
+		AppSecret:     cfg.MetaAppSecret,
+		RefreshBefore: cfg.MetaTokenRefreshBefore,
+		CheckInterval: cfg.MetaTokenCheckInterval,
+		HTTP:          utils.HTTPClientConfig{Transport: transport},
+		APIVersion:    cfg.MetaAPIVersion,
+	}, logger)
+	if err := tokenManager.Validate(context.Background()); err != nil {
+		if errors.Is(err, meta.ErrTokenInvalid) {
+			logger.Fatal("Meta access token is invalid", "error", err)
+		}
+		logger.Warn("Could not validate Meta access token at startup", "error", err)
+	}
+	application.Go("meta_token_manager", tokenManager.Run)
+
+	prometheus.MustRegister(meta.NewTokenHealthCollector(tokenManager))
+	readinessChecks["meta_token"] = func(context.Context) error {
+		if health := tokenManager.Health(); !health.Valid {
+			return fmt.Errorf("meta access token is not valid: %s", health.LastError)
+		}
+		return nil
+	}
+
+	if cfg.Secrets != nil {
+		cfg.Secrets.OnChange(func(key, value string) {
+			switch key {
+			case config.SecretMetaAccessToken:
+				tokenManager.UpdateCredentials(value, "")
+			case config.SecretMetaAppSecret:
+				tokenManager.UpdateCredentials("", value)
+			default:
+				return
+			}
+			logger.Info("Meta credentials rotated", "secret", key)
+			if err := tokenManager.Validate(context.Background()); err != nil {
+				logger.Error("Rotated Meta access token failed validation", "error", err)
+			}
+		})
+	}
+
+	return meta.NewClientWithTokenSource(cfg.MetaPhoneNumberID, tokenManager, cfg.MetaAppSecret, logger,
+		meta.WithAPIVersion(cfg.MetaAPIVersion), meta.WithCallTimeout(providerTimeout(cfg)), meta.WithHTTPClient(providerHTTPClient(cfg, transport)))
+}
+
+// providerTimeout bounds provider requests by the deadline of the call they serve
+func providerTimeout(cfg *config.Config) utils.CallTimeout {
+	return utils.CallTimeout{Floor: cfg.ProviderTimeoutFloor, Ceiling: cfg.ProviderTimeoutCeiling}
+}
+
+// providerHTTPClient sets the retries, per-attempt timeout and transport of provider requests
+func providerHTTPClient(cfg *config.Config, transport http.RoundTripper) utils.HTTPClientConfig {
+	return utils.HTTPClientConfig{
+		Transport:      transport,
+		AttemptTimeout: cfg.ProviderHTTPAttemptTimeout,
+		Retry: utils.Backoff{
+			Attempts: cfg.ProviderHTTPAttempts,
+			Initial:  cfg.ProviderHTTPRetryBackoff,
+			Max:      cfg.ProviderHTTPRetryMaxBackoff,
+			Jitter:   utils.DefaultHTTPRetry.Jitter,
+		},
+	}
+}
+
+// newProviderEventPublisher returns the provider switchover hook, which produces each event to
+// KAFKA_PROVIDER_EVENTS_TOPIC when set; switchovers are always logged by the router itself
+func newProviderEventPublisher(cfg *config.Config, logger utils.Logger) func(providerrouter.SwitchEvent) {
+	if cfg.KafkaProviderEventsTopic == "" {
+		return nil
+	}
+
+	producer, err := queue.NewProducer(cfg.KafkaBrokers, cfg.KafkaProviderEventsTopic, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize provider events producer", "error", err)
+	}
+
+	return func(event providerrouter.SwitchEvent) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			logger.Error("Failed to marshal provider switch event", "error", err)
+			return
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := producer.Produce(ctx, data); err != nil {
+				logger.Error("Failed to publish provider switch event", "error", err, "from", event.From, "to", event.To)
+			}
+		}()
+	}
+}
+
+// alertNotifier returns the notifier for operational alerts, or nil when none is configured
+func alertNotifier(cfg *config.Config) alerts.Notifier {
+	var notifiers []alerts.Notifier
+	if cfg.AlertSlackWebhookURL != "" {
+		notifiers = append(notifiers, alerts.NewSlackNotifier(cfg.AlertSlackWebhookURL))
+	}
+	if cfg.AlertPagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, alerts.NewPagerDutyNotifier(cfg.AlertPagerDutyRoutingKey, "whatsapp-microservice"))
+	}
+	if cfg.AlertEmailSMTPAddr != "" {
+		notifiers = append(notifiers, alerts.NewEmailNotifier(alerts.EmailConfig{
+			Addr:     cfg.AlertEmailSMTPAddr,
+			Username: cfg.AlertEmailUsername,
+			Password: cfg.AlertEmailPassword,
+			From:     cfg.AlertEmailFrom,
+			To:       cfg.AlertEmailTo,
+		}))
+	}
+	return alerts.NewMultiNotifier(notifiers...)
+}
+
+// alertRules returns the configured alerting rules on this replica's metrics, and those on
+// state all replicas share
+func alertRules(cfg *config.Config) (replica, shared []alerts.Rule) {
+	if cfg.AlertMaxSendFailureRate > 0 {
+		replica = append(replica, alerts.Rule{
+			Key:       "send_failure_rate:" + cfg.InstanceID,
+			Measure:   alerts.CounterShare(prometheus.DefaultGatherer, "whatsapp_provider_sends_total", map[string]string{"result": "failure"}, float64(cfg.AlertMinSends)),
+			Threshold: cfg.AlertMaxSendFailureRate,
+			Summary: func(rate float64) string {
+				return fmt.Sprintf("%.1f%% of provider sends on %s failed", rate*100, cfg.InstanceID)
+			},
+		})
+	}
+	if cfg.AlertMaxSignatureFailures > 0 {
+		replica = append(replica, alerts.Rule{
+			Key:       "webhook_signature_failures:" + cfg.InstanceID,
+			Measure:   alerts.CounterIncrease(prometheus.DefaultGatherer, "whatsapp_webhook_errors_total", map[string]string{"code": handler.WebhookErrorInvalidSignature}),
+			Threshold: float64(cfg.AlertMaxSignatureFailures),
+			Summary: func(failures float64) string {
+				return fmt.Sprintf("%.0f webhooks on %s failed signature validation in %s", failures, cfg.InstanceID, cfg.AlertEvaluationInterval)
+			},
+		})
+	}
+	if cfg.AlertMaxDLQDepth > 0 {
+		dlqTopic := queue.DeadLetterTopic(cfg.KafkaTopic)
+		shared = append(shared, alerts.Rule{
+			Key: "dlq_depth:" + dlqTopic,
+			Measure: func(ctx context.Context) (float64, bool, error) {
+				depth, err := queue.TopicDepth(ctx, cfg.KafkaBrokers, dlqTopic, cfg.AlertDLQGroup)
+				return float64(depth), err == nil, err
+			},
+			Threshold: float64(cfg.AlertMaxDLQDepth),
+			Summary: func(depth float64) string {
+				return fmt.Sprintf("%.0f messages wait in %s", depth, dlqTopic)
+			},
+		})
+	}
+	if cfg.AlertTokenExpiryWithin > 0 {
+		shared = append(shared, alerts.Rule{
+			Key:       "meta_token_expiry",
+			Measure:   alerts.TimeUntil(prometheus.DefaultGatherer, "whatsapp_meta_token_expiry_timestamp_seconds"),
+			Threshold: cfg.AlertTokenExpiryWithin.Seconds(),
+			Below:     true,
+			Summary: func(seconds float64) string {
+				if seconds <= 0 {
+					return "The Meta access token has expired"
+				}
+				return fmt.Sprintf("The Meta access token expires in %s", (time.Duration(seconds) * time.Second).Round(time.Minute))
+			},
+		})
+	}
+	return replica, shared
+}
+
+// templateAlertNotifier returns the notifier telling template owners Meta paused or disabled a
+// template, or nil when none is configured
+func templateAlertNotifier(cfg *config.Config) alerts.Notifier {
+	if cfg.TemplateAlertSlackWebhookURL == "" {
+		return nil
+	}
+	return alerts.NewSlackNotifier(cfg.TemplateAlertSlackWebhookURL)
+}
+
+// nonNilNotifiers drops the notifiers that are not configured
+func nonNilNotifiers(notifiers ...alerts.Notifier) []alerts.Notifier {
+	configured := notifiers[:0]
+	for _, notifier := range notifiers {
+		if notifier != nil {
+			configured = append(configured, notifier)
 		}
+	}
+	return configured
+}
+
+// webhookTenants maps the Meta phone number IDs, Twilio senders and Meta business account IDs
+// that webhooks arrive for to their tenants
+func webhookTenants(cfg *config.Config) map[string]string {
+	tenants := make(map[string]string, len(cfg.PhoneNumberTenants)+len(cfg.TwilioSenderTenants)+len(cfg.WABATenants))
+	for phoneNumberID, tenantID := range cfg.PhoneNumberTenants {
+		tenants[phoneNumberID] = tenantID
+	}
+	for sender, tenantID := range cfg.TwilioSenderTenants {
+		tenants[sender] = tenantID
+	}
+	for accountID, tenantID := range cfg.WABATenants {
+		tenants[accountID] = tenantID
+	}
+	return tenants
+}
+
+// templateAccounts maps tenants to the Meta business account their templates belong to
+func templateAccounts(cfg *config.Config) map[string]string {
+	accounts := make(map[string]string, len(cfg.WABATenants))
+	for accountID, tenantID := range cfg.WABATenants {
+		accounts[tenantID] = accountID
+	}
+	return accounts
+}
+
+// newRedisClient connects to REDIS_URL, returning nil when it is not set
+func newRedisClient(cfg *config.Config, logger utils.Logger) redis.UniversalClient {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		logger.Fatal("Invalid REDIS_URL", "error", err)
+	}
+	return redis.NewClient(opts)
+}
+
+// metaWebhookSignatures checks Meta webhooks against the app secret of the Meta client, or of
+// the mock provider standing in for it; without either, no Meta webhook is accepted
+func metaWebhookSignatures(providerClients map[string]meta.Client) func(signature, url string, body []byte) bool {
+	for _, provider := range []string{"meta", "mock"} {
+		if client, ok := providerClients[provider]; ok {
+			return client.ValidateWebhookSignature
+		}
+	}
+	return nil
+}
+
+// webhookReplayGuard builds the replay protection of status webhooks from configuration
+func webhookReplayGuard(cfg *config.Config, client redis.UniversalClient, logger utils.Logger) *service.ReplayGuard {
+	var nonces repository.WebhookNonceStore
+	switch cfg.WebhookNonceStore {
+	case "memory":
+		nonces = repository.NewMemoryWebhookNonceStore(cfg.WebhookNonceCacheSize, cfg.WebhookMaxEventAge)
+	case "redis":
+		nonces = repository.NewRedisWebhookNonceStore(client, cfg.WebhookMaxEventAge, logger)
+	}
+	return service.NewReplayGuard(nonces, service.ReplayPolicy{
+		MaxAge: cfg.WebhookMaxEventAge,
+		Action: cfg.WebhookReplayAction,
+	}, logger)
+}
+
+// runSingleton runs a background worker on this replica, or only while it leads the worker
+// when elector is set
+func runSingleton(application *app.App, elector *service.LeaderElector, worker string, run func(ctx context.Context)) {
+	if elector == nil {
+		application.Go(worker, run)
+		return
+	}
+	application.Go(worker, func(ctx context.Context) { elector.Run(ctx, worker, run) })
+}
+
+// runConsumer consumes with handler while the app runs, restarting the consumer when it stops
+// and closing it when the app stops
+func runConsumer(application *app.App, cfg *config.Config, name string, consumer queue.Consumer, handler queue.MessageHandler) {
+	application.Closer(name, consumer)
+	application.Supervise(name, app.RestartPolicy{
+		Backoff: utils.Backoff{
+			Attempts: cfg.ComponentMaxFailures,
+			Initial:  cfg.ComponentRestartBackoff,
+			Max:      cfg.ComponentRestartMaxBackoff,
+			Jitter:   app.DefaultRestartPolicy.Backoff.Jitter,
+		},
+		StableAfter: cfg.ComponentStableAfter,
+	}, func(ctx context.Context) error { return consumer.Consume(ctx, handler) })
+}
+
+// stopGRPCServer lets in-flight calls finish, cutting them off when ctx is done
+func stopGRPCServer(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
 	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// resolve returns the component of type T, exiting when it cannot be built
+func resolve[T any](application *app.App, logger utils.Logger) T {
+	component, err := app.Resolve[T](application)
+	if err != nil {
+		logger.Fatal("Failed to build component", "error", err)
+	}
+	return component
+}
+
+// provideConnections registers the constructors of the connections shared by many components:
+// the database, Redis and the leader elector using the database's locks
+func provideConnections(application *app.App, cfg *config.Config, startupBackoff utils.Backoff, logger utils.Logger) {
+	app.Provide(application, func(a *app.App) (*sqlx.DB, error) {
+		var db *sqlx.DB
+		err := utils.Retry(context.Background(), startupBackoff, logger, "database", func(ctx context.Context) error {
+			var err error
+			db, err = repository.Connect(ctx, cfg.DatabaseDSN)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		a.Closer("database", db)
 
-	logger.Info("Shutting down server...")
+		db.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+		db.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+		db.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+		db.SetConnMaxIdleTime(cfg.DatabaseConnMaxIdleTime)
+		prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, "whatsapp"))
+		return db, nil
+	})
 
-	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	app.Provide(application, func(a *app.App) (redis.UniversalClient, error) {
+		client := newRedisClient(cfg, logger)
+		if client != nil {
+			a.Closer("redis", client)
+		}
+		return client, nil
+	})
 
-	// Shutdown HTTP server
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("HTTP server forced to shutdown", "error", err)
+	app.Provide(application, func(a *app.App) (*service.LeaderElector, error) {
+		if !cfg.LeaderElection {
+			return nil, nil
+		}
+		db, err := app.Resolve[*sqlx.DB](a)
+		if err != nil {
+			return nil, err
+		}
+		return service.NewLeaderElector(repository.NewAdvisoryLocker(db), cfg.InstanceID, cfg.LeaderCheckInterval, logger), nil
+	})
+}
+
+// statusQuarantine keeps statuses for unknown external IDs unless the window is zero
+func statusQuarantine(cfg *config.Config, db *sqlx.DB, logger utils.Logger) repository.StatusQuarantineRepository {
+	if cfg.WebhookQuarantineWindow <= 0 {
+		return nil
 	}
+	return repository.NewStatusQuarantineRepository(db, logger)
+}
 
-	// Close consumer
-	if err := messageConsumer.Close(); err != nil {
-		logger.Error("Failed to close consumer", "error", err)
+// newRateLimiter shares rate limits through Redis when configured
+func newRateLimiter(client redis.UniversalClient, logger utils.Logger) utils.RateLimiter {
+	fallback := utils.NewMemoryRateLimiter()
+	if client == nil {
+		return fallback
 	}
+	return utils.NewRedisRateLimiter(client, fallback, logger)
+}
 
-	logger.Info("Server exited gracefully")
+// newWindowCounter shares counts through Redis when configured
+func newWindowCounter(client redis.UniversalClient, logger utils.Logger) utils.WindowCounter {
+	fallback := utils.NewMemoryWindowCounter()
+	if client == nil {
+		return fallback
+	}
+	return utils.NewRedisWindowCounter(client, fallback, logger)
+}
 
+// grpcCredentials maps the gRPC API keys to the tenant and caller each authenticates
+func grpcCredentials(cfg *config.Config) map[string]handler.GRPCCredential {
+	credentials := make(map[string]handler.GRPCCredential, len(cfg.GRPCAPIKeys))
+	for key, credential := range cfg.GRPCAPIKeys {
+		tenant, caller, _ := strings.Cut(credential, ":")
+		credentials[key] = handler.GRPCCredential{Caller: caller, Tenant: tenant}
+	}
+	return credentials
+}
+
+// callerLimitPolicy builds the gRPC per-caller limit policy from configuration
+func callerLimitPolicy(cfg *config.Config, logger utils.Logger) handler.CallerLimitPolicy {
+	policy := handler.CallerLimitPolicy{
+		DailyMessages:      int64(cfg.GRPCDailyMessagesDefault),
+		DailyMessageLimits: make(map[string]int64, len(cfg.GRPCDailyMessages)),
+	}
+	var err error
 
-}
\ No newline at end of file
+	if cfg.GRPCRateLimitDefault != "" {
+		if policy.Rate, err = utils.ParseRateLimit(cfg.GRPCRateLimitDefault); err != nil {
+			logger.Fatal("Invalid GRPC_RATE_LIMIT_DEFAULT", "error", err)
+		}
+	}
+	if policy.Rates, err = utils.ParseRateLimits(cfg.GRPCRateLimits); err != nil {
+		logger.Fatal("Invalid GRPC_RATE_LIMITS", "error", err)
+	}
+	for caller, limit := range cfg.GRPCDailyMessages {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			logger.Fatal("Invalid GRPC_DAILY_MESSAGES", "error", err)
+		}
+		policy.DailyMessageLimits[caller] = n
+	}
+	return policy
+}
+
+// rateLimitPolicy builds the HTTP rate limit policy from configuration
+func rateLimitPolicy(cfg *config.Config, logger utils.Logger) utils.RateLimitPolicy {
+	var policy utils.RateLimitPolicy
+	var err error
+
+	if cfg.RateLimitDefault != "" {
+		if policy.Default, err = utils.ParseRateLimit(cfg.RateLimitDefault); err != nil {
+			logger.Fatal("Invalid RATE_LIMIT_DEFAULT", "error", err)
+		}
+	}
+	if policy.Routes, err = utils.ParseRateLimits(cfg.RateLimitRoutes); err != nil {
+		logger.Fatal("Invalid RATE_LIMIT_ROUTES", "error", err)
+	}
+	if policy.APIKeys, err = utils.ParseRateLimits(cfg.RateLimitAPIKeys); err != nil {
+		logger.Fatal("Invalid RATE_LIMIT_API_KEYS", "error", err)
+	}
+	return policy
+}
+
+// quotaPolicy builds the monthly message quota policy from configuration
+func quotaPolicy(cfg *config.Config, logger utils.Logger) service.QuotaPolicy {
+	policy := service.QuotaPolicy{
+		TenantDefault:   int64(cfg.QuotaTenantMonthly),
+		CustomerDefault: int64(cfg.QuotaCustomerMonthly),
+		Tenants:         make(map[string]int64, len(cfg.QuotaTenants)),
+		Customers:       make(map[string]int64, len(cfg.QuotaCustomers)),
+	}
+
+	for id, limit := range cfg.QuotaTenants {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			logger.Fatal("Invalid QUOTA_TENANTS", "tenant", id, "error", err)
+		}
+		policy.Tenants[id] = n
+	}
+	for id, limit := range cfg.QuotaCustomers {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			logger.Fatal("Invalid QUOTA_CUSTOMERS", "customer", id, "error", err)
+		}
+		policy.Customers[id] = n
+	}
+	return policy
+}
+
+// newArchiveStore opens the object store that holds archived messages
+func newArchiveStore(cfg *config.Config, logger utils.Logger) objectstore.Store {
+	if cfg.ArchiveStore == "file" {
+		return objectstore.NewFileStore(cfg.ArchiveDir)
+	}
+
+	store, err := objectstore.NewS3Store(context.Background(), cfg.ArchiveRegion, cfg.ArchiveS3Endpoint, cfg.ArchiveBucket)
+	if err != nil {
+		logger.Fatal("Failed to create archive store", "error", err)
+	}
+	return store
+}
@@ -0,0 +1,43 @@
+// cmd/whatsappctl/account.go
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	pb "messaging-microservice/proto"
+)
+
+func newAccountCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "Inspect the WhatsApp Business Account",
+	}
+
+	var events int32
+	quality := &cobra.Command{
+		Use:     "quality",
+		Short:   "Show the phone numbers' quality rating, messaging limit and recent account events",
+		Example: "  whatsappctl account quality --events 50",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.GetAccountQuality(ctx, &pb.GetAccountQualityRequest{EventLimit: events})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+	quality.Flags().Int32Var(&events, "events", 20, "most recent account events to show (at most 100)")
+
+	cmd.AddCommand(quality)
+	return cmd
+}
@@ -0,0 +1,76 @@
+// cmd/whatsappctl/audit.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "messaging-microservice/proto"
+)
+
+// newAuditCommand lists recent audit log entries
+func newAuditCommand() *cobra.Command {
+	var (
+		since       time.Duration
+		limit       int32
+		action      string
+		actor       string
+		subjectType string
+		subject     string
+		full        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   "List recent admin changes and data subject requests from the audit log",
+		Example: "  whatsappctl audit --since 168h --action disable_template",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{
+				Action:         action,
+				Actor:          actor,
+				SubjectType:    subjectType,
+				Subject:        subject,
+				CreatedAfterTs: timestamppb.New(time.Now().Add(-since)),
+				Limit:          limit,
+			})
+			if err != nil {
+				return err
+			}
+			if full {
+				return printProto(resp)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tAT\tACTOR\tACTION\tSUBJECT\tREASON")
+			for _, entry := range resp.Entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s:%s\t%s\n",
+					entry.Id, entry.CreatedAt.AsTime().Format(time.RFC3339), entry.Actor, entry.Action, entry.SubjectType, entry.Subject, entry.Reason)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "how far back to look")
+	cmd.Flags().Int32Var(&limit, "limit", 100, "maximum number of entries to show")
+	cmd.Flags().StringVar(&action, "action", "", "only show this action, e.g. pause_sending")
+	cmd.Flags().StringVar(&actor, "actor", "", "only show entries of this operator")
+	cmd.Flags().StringVar(&subjectType, "subject-type", "", "only show this subject type, e.g. template")
+	cmd.Flags().StringVar(&subject, "subject", "", "only show this subject, e.g. a template ID")
+	cmd.Flags().BoolVar(&full, "full", false, "print the entries as JSON, with their before and after state")
+	return cmd
+}
@@ -0,0 +1,112 @@
+// cmd/whatsappctl/conversations.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	pb "messaging-microservice/proto"
+)
+
+func newConversationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conversation",
+		Short: "Inspect customer conversations and their agent handoff",
+	}
+
+	get := &cobra.Command{
+		Use:     "get <phone_number>",
+		Short:   "Show a customer's conversation and handoff state",
+		Example: "  whatsappctl conversation get +15551234567",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.GetConversation(ctx, &pb.GetConversationRequest{PhoneNumber: args[0]})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	var agent string
+	setHandoff := func(use, short, example string, status pb.HandoffStatus) *cobra.Command {
+		c := &cobra.Command{
+			Use:     use + " <conversation_id>",
+			Short:   short,
+			Example: example,
+			Args:    cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				conversationID, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid conversation ID %q", args[0])
+				}
+
+				client, closeConn, err := dial()
+				if err != nil {
+					return err
+				}
+				defer closeConn()
+
+				ctx, cancel := callContext(cmd.Context())
+				defer cancel()
+
+				resp, err := client.UpdateHandoff(ctx, &pb.UpdateHandoffRequest{
+					ConversationId: conversationID,
+					Status:         status,
+					Agent:          agent,
+				})
+				if err != nil {
+					return err
+				}
+				return printProto(resp)
+			},
+		}
+		c.Flags().StringVar(&agent, "agent", os.Getenv("USER"), "agent taking or finishing the conversation")
+		return c
+	}
+
+	handoff := setHandoff("handoff", "Hand a conversation to the agents",
+		"  whatsappctl conversation handoff 42", pb.HandoffStatus_HANDOFF_STATUS_PENDING)
+	assign := setHandoff("assign", "Record an agent taking a conversation",
+		"  whatsappctl conversation assign 42 --agent alice", pb.HandoffStatus_HANDOFF_STATUS_ASSIGNED)
+	resolve := setHandoff("resolve", "Return a conversation to automation",
+		"  whatsappctl conversation resolve 42", pb.HandoffStatus_HANDOFF_STATUS_RESOLVED)
+
+	typing := &cobra.Command{
+		Use:     "typing <message_id>",
+		Short:   "Show the customer a typing indicator for their message",
+		Example: "  whatsappctl conversation typing wamid.XXX",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.SendTypingIndicator(ctx, &pb.SendTypingIndicatorRequest{MessageId: args[0]})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.AddCommand(get, handoff, assign, resolve, typing)
+	return cmd
+}
@@ -0,0 +1,163 @@
+// cmd/whatsappctl/events.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/spf13/cobra"
+)
+
+// Kafka flags for commands that read topics directly
+var kafkaBrokers string
+
+func addKafkaFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&kafkaBrokers, "brokers", envOrDefault("KAFKA_BROKERS", "localhost:9092"), "comma-separated Kafka brokers")
+}
+
+// newEventsCommand groups status event commands
+func newEventsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Inspect status events",
+	}
+	addKafkaFlags(cmd)
+	cmd.AddCommand(newEventsTailCommand())
+	return cmd
+}
+
+// newEventsTailCommand prints status events as they are published
+func newEventsTailCommand() *cobra.Command {
+	var (
+		topic     string
+		fromStart bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Print status events as they arrive (Ctrl+C to stop)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			// No group ID: tailing must not commit offsets or steal partitions from the service
+			startOffset := kafka.LastOffset
+			if fromStart {
+				startOffset = kafka.FirstOffset
+			}
+			reader := kafka.NewReader(kafka.ReaderConfig{
+				Brokers:     strings.Split(kafkaBrokers, ","),
+				Topic:       topic,
+				StartOffset: startOffset,
+			})
+			defer reader.Close()
+
+			for {
+				msg, err := reader.ReadMessage(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+				fmt.Printf("%s key=%s %s\n", msg.Time.Format("15:04:05.000"), string(msg.Key), string(msg.Value))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&topic, "topic", envOrDefault("KAFKA_STATUS_TOPIC", "whatsapp-status-events"), "status events topic")
+	cmd.Flags().BoolVar(&fromStart, "from-start", false, "read the topic from the earliest offset (partition 0)")
+	return cmd
+}
+
+// newDLQCommand groups dead-letter queue commands
+func newDLQCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dlq",
+		Short: "Manage dead-lettered queue messages",
+	}
+	addKafkaFlags(cmd)
+	cmd.AddCommand(newDLQReplayCommand())
+	return cmd
+}
+
+// newDLQReplayCommand republishes dead-lettered messages to the send topic
+func newDLQReplayCommand() *cobra.Command {
+	var (
+		dlqTopic    string
+		targetTopic string
+		groupID     string
+		maxMessages int
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Republish dead-lettered messages to the send topic",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if maxMessages <= 0 {
+				return errors.New("--max must be positive")
+			}
+
+			brokers := strings.Split(kafkaBrokers, ",")
+
+			// A consumer group remembers how far replay got, so re-running continues where it stopped
+			reader := kafka.NewReader(kafka.ReaderConfig{
+				Brokers: brokers,
+				Topic:   dlqTopic,
+				GroupID: groupID,
+			})
+			defer reader.Close()
+
+			writer := &kafka.Writer{
+				Addr:         kafka.TCP(brokers...),
+				Topic:        targetTopic,
+				Balancer:     &kafka.Hash{},
+				RequiredAcks: kafka.RequireAll,
+			}
+			defer writer.Close()
+
+			replayed := 0
+			for replayed < maxMessages {
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				msg, err := reader.FetchMessage(ctx)
+				cancel()
+				if err != nil {
+					if errors.Is(err, context.DeadlineExceeded) {
+						break // DLQ drained
+					}
+					return err
+				}
+
+				if dryRun {
+					fmt.Printf("would replay offset=%d key=%s %s\n", msg.Offset, string(msg.Key), string(msg.Value))
+					replayed++
+					continue
+				}
+
+				if err := writer.WriteMessages(cmd.Context(), kafka.Message{Key: msg.Key, Value: msg.Value}); err != nil {
+					return fmt.Errorf("republish offset %d: %w", msg.Offset, err)
+				}
+				if err := reader.CommitMessages(cmd.Context(), msg); err != nil {
+					return fmt.Errorf("commit offset %d: %w", msg.Offset, err)
+				}
+				replayed++
+			}
+
+			fmt.Printf("replayed %d message(s) from %s to %s\n", replayed, dlqTopic, targetTopic)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dlqTopic, "dlq-topic", envOrDefault("KAFKA_TOPIC", "whatsapp-messages")+".dlq", "dead-letter topic to read")
+	cmd.Flags().StringVar(&targetTopic, "target-topic", envOrDefault("KAFKA_TOPIC", "whatsapp-messages"), "topic to republish to")
+	cmd.Flags().StringVar(&groupID, "group", "whatsappctl-dlq-replay", "consumer group used to track replay progress")
+	cmd.Flags().IntVar(&maxMessages, "max", 100, "maximum number of messages to replay")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print messages without republishing or committing")
+	return cmd
+}
@@ -0,0 +1,33 @@
+// cmd/whatsappctl/instance.go
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	pb "messaging-microservice/proto"
+)
+
+func newInstanceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "instance",
+		Short:   "Show the partitions, in-flight work and singleton workers of the replica answering",
+		Example: "  whatsappctl instance --server whatsapp-1.whatsapp:9090",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.GetInstanceStatus(ctx, &pb.GetInstanceStatusRequest{})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+}
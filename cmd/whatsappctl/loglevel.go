@@ -0,0 +1,77 @@
+// cmd/whatsappctl/loglevel.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "messaging-microservice/proto"
+)
+
+// newLogLevelCommand shows or changes the log level of the replica answering
+func newLogLevelCommand() *cobra.Command {
+	var component, phoneNumber, requestedBy string
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "log-level [debug | info | warn | error | off]",
+		Short: "Show or change the log level of the replica answering, or the debug logs of a component or phone number",
+		Example: "  whatsappctl log-level --server whatsapp-1.whatsapp:9090\n" +
+			"  whatsappctl log-level debug --for 10m --server whatsapp-1.whatsapp:9090\n" +
+			"  whatsappctl log-level debug --component message_consumer --by ops-oncall\n" +
+			"  whatsappctl log-level off --phone +15551234567 --by ops-oncall",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			if len(args) == 0 {
+				resp, err := client.GetLogLevel(ctx, &pb.GetLogLevelRequest{})
+				if err != nil {
+					return err
+				}
+				return printProto(resp)
+			}
+
+			// "off" disables the debug logs of a target, which the API takes as an empty level
+			level := args[0]
+			if level == "off" {
+				if component == "" && phoneNumber == "" {
+					return fmt.Errorf("off needs --component or --phone")
+				}
+				level = ""
+			}
+			req := &pb.SetLogLevelRequest{
+				Level:       level,
+				Component:   component,
+				PhoneNumber: phoneNumber,
+				RequestedBy: requestedBy,
+			}
+			if duration > 0 {
+				req.Duration = durationpb.New(duration)
+			}
+			resp, err := client.SetLogLevel(ctx, req)
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.Flags().StringVar(&component, "component", "", "only change the debug logs of this component")
+	cmd.Flags().StringVar(&phoneNumber, "phone", "", "only change the debug logs about this phone number")
+	cmd.Flags().DurationVar(&duration, "for", 0, "revert after this long (debug targets default to 15m)")
+	cmd.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible for the change")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+// cmd/whatsappctl/main.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "messaging-microservice/proto"
+)
+
+// Global flags shared by all commands
+var (
+	serverAddr string
+	tenantID   string
+	timeout    time.Duration
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:           "whatsappctl",
+		Short:         "Operator CLI for the WhatsApp messaging service",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&serverAddr, "server", envOrDefault("WHATSAPPCTL_SERVER", "localhost:9090"), "gRPC address of the service")
+	root.PersistentFlags().StringVar(&tenantID, "tenant", os.Getenv("WHATSAPPCTL_TENANT"), "tenant ID sent as x-tenant-id")
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 10*time.Second, "timeout for unary calls")
+
+	root.AddCommand(
+		newSendCommand(),
+		newGetCommand(),
+		newRetryCommand(),
+		newDeleteCommand(),
+		newFailuresCommand(),
+		newSearchCommand(),
+		newEventsCommand(),
+		newDLQCommand(),
+		newPauseCommand(),
+		newResumeCommand(),
+		newPausesCommand(),
+		newTemplateCommand(),
+		newCountryCommand(),
+		newConversationCommand(),
+		newAccountCommand(),
+		newInstanceCommand(),
+		newLogLevelCommand(),
+		newAuditCommand(),
+	)
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// dial connects to the service and returns a client along with a cleanup function
+func dial() (pb.WhatsAppServiceClient, func(), error) {
+	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to %s: %w", serverAddr, err)
+	}
+	return pb.NewWhatsAppServiceClient(conn), func() { conn.Close() }, nil
+}
+
+// callContext returns a context carrying the call timeout and tenant metadata
+func callContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	if tenantID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", tenantID)
+	}
+	return ctx, cancel
+}
+
+// printProto writes a proto message as indented JSON
+func printProto(msg proto.Message) error {
+	out, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,342 @@
+// cmd/whatsappctl/messages.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "messaging-microservice/proto"
+)
+
+// newSendCommand sends a test template message
+func newSendCommand() *cobra.Command {
+	var (
+		phoneNumber string
+		templateID  string
+		params      []string
+		buttonURLs  []string
+		orderID     string
+		customerID  string
+		synchronous bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "send",
+		Short:   "Send a template message",
+		Example: "  whatsappctl send --to +1234567890 --template order_confirmation --param order_id=ORD-1 --order ORD-1",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parameters, err := parseParams(params)
+			if err != nil {
+				return err
+			}
+			buttons := make(map[int32]string, len(buttonURLs))
+			for _, button := range buttonURLs {
+				index, suffix, ok := strings.Cut(button, "=")
+				n, err := strconv.ParseInt(index, 10, 32)
+				if !ok || err != nil {
+					return fmt.Errorf("invalid --button-url %q, expected index=suffix", button)
+				}
+				buttons[int32(n)] = suffix
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.SendTemplateMessage(ctx, &pb.SendTemplateMessageRequest{
+				PhoneNumber: phoneNumber,
+				TemplateId:  templateID,
+				Parameters:  parameters,
+				ButtonUrls:  buttons,
+				OrderId:     orderID,
+				CustomerId:  customerID,
+				Synchronous: synchronous,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.Flags().StringVar(&phoneNumber, "to", "", "recipient phone number")
+	cmd.Flags().StringVar(&templateID, "template", "", "template ID")
+	cmd.Flags().StringArrayVar(&params, "param", nil, "template parameter as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&buttonURLs, "button-url", nil, "dynamic URL suffix of a URL button as index=suffix (repeatable)")
+	cmd.Flags().StringVar(&orderID, "order", "", "order ID")
+	cmd.Flags().StringVar(&customerID, "customer", "", "customer ID")
+	cmd.Flags().BoolVar(&synchronous, "sync", false, "send to the provider before returning instead of queueing")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagRequired("template")
+
+	return cmd
+}
+
+// parseParams parses repeated key=value --param flags
+func parseParams(params []string) (map[string]string, error) {
+	parameters := make(map[string]string, len(params))
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --param %q, expected key=value", param)
+		}
+		parameters[key] = value
+	}
+	return parameters, nil
+}
+
+// newRetryCommand retries a failed message, optionally with corrections
+func newRetryCommand() *cobra.Command {
+	var (
+		phoneNumber string
+		params      []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "retry <message-id>",
+		Short:   "Send a failed message again as a new attempt",
+		Example: "  whatsappctl retry 42 --to +1234567891 --param order_id=ORD-2",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid message ID %q", args[0])
+			}
+			parameters, err := parseParams(params)
+			if err != nil {
+				return err
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			msg, err := client.RetryMessage(ctx, &pb.RetryMessageRequest{
+				MessageId:   id,
+				PhoneNumber: phoneNumber,
+				Parameters:  parameters,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(msg)
+		},
+	}
+
+	cmd.Flags().StringVar(&phoneNumber, "to", "", "corrected recipient phone number")
+	cmd.Flags().StringArrayVar(&params, "param", nil, "corrected template parameter as key=value (repeatable)")
+	return cmd
+}
+
+// newDeleteCommand soft deletes a message, or hard deletes it for admins
+func newDeleteCommand() *cobra.Command {
+	var (
+		hard        bool
+		reason      string
+		requestedBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "delete <message-id>",
+		Short: "Hide a message from reads, listings and stats, or remove it with --hard",
+		Example: "  whatsappctl delete 42 --reason \"test send\" --by ops-oncall\n" +
+			"  whatsappctl delete 42 --hard --reason \"mistaken entry\" --by ops-admin",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid message ID %q", args[0])
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.DeleteMessage(ctx, &pb.DeleteMessageRequest{
+				MessageId:   id,
+				HardDelete:  hard,
+				RequestedBy: requestedBy,
+				Reason:      reason,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.Flags().BoolVar(&hard, "hard", false, "remove the row instead of hiding it (admins only)")
+	cmd.Flags().StringVar(&reason, "reason", "", "why the message is deleted")
+	cmd.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible")
+
+	return cmd
+}
+
+// newGetCommand fetches a message by internal or external ID
+func newGetCommand() *cobra.Command {
+	var externalID string
+
+	cmd := &cobra.Command{
+		Use:   "get [message-id]",
+		Short: "Fetch a message by ID or --external-id",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (externalID == "") {
+				return errors.New("provide either a message ID or --external-id")
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			var msg *pb.MessageResponse
+			if externalID != "" {
+				msg, err = client.GetMessageByExternalID(ctx, &pb.GetMessageByExternalIDRequest{ExternalId: externalID})
+			} else {
+				id, parseErr := strconv.ParseInt(args[0], 10, 64)
+				if parseErr != nil {
+					return fmt.Errorf("invalid message ID %q", args[0])
+				}
+				msg, err = client.GetMessage(ctx, &pb.GetMessageRequest{MessageId: id})
+			}
+			if err != nil {
+				return err
+			}
+			return printProto(msg)
+		},
+	}
+
+	cmd.Flags().StringVar(&externalID, "external-id", "", "provider external ID")
+	return cmd
+}
+
+// newSearchCommand searches messages by parameter values and text
+func newSearchCommand() *cobra.Command {
+	var (
+		since time.Duration
+		limit int32
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search messages by parameter values and text in their content",
+		Example: "  whatsappctl search 'parameters.tracking_id = TRK-1'\n" +
+			"  whatsappctl search '\"out for delivery\"' --since 72h",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			req := &pb.SearchMessagesRequest{Query: args[0], Limit: limit}
+			if since > 0 {
+				req.CreatedAfterTs = timestamppb.New(time.Now().Add(-since))
+			}
+			resp, err := client.SearchMessages(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCREATED\tPHONE\tTEMPLATE\tSTATUS\tORDER")
+			for _, msg := range resp.Messages {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					msg.Id, msg.CreatedAtTs.AsTime().Format(time.RFC3339), msg.PhoneNumber, msg.TemplateId, msg.Status, msg.OrderId)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 0, "only search messages created this recently (0 searches all)")
+	cmd.Flags().Int32Var(&limit, "limit", 50, "maximum number of messages to show")
+	return cmd
+}
+
+// newFailuresCommand lists recently failed messages
+func newFailuresCommand() *cobra.Command {
+	var (
+		since      time.Duration
+		limit      int32
+		templateID string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "failures",
+		Short: "List recently failed messages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.ListMessages(ctx, &pb.ListMessagesRequest{
+				Status:         "failed",
+				TemplateId:     templateID,
+				CreatedAfterTs: timestamppb.New(time.Now().Add(-since)),
+				Limit:          limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCREATED\tPHONE\tTEMPLATE\tCODE\tERROR")
+			for _, msg := range resp.Messages {
+				var code string
+				if msg.ErrorDetail != nil {
+					code = msg.ErrorDetail.ProviderCode
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					msg.Id, msg.CreatedAtTs.AsTime().Format(time.RFC3339), msg.PhoneNumber, msg.TemplateId, code, msg.ErrorMessage)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%d of %d failed messages in the last %s\n", len(resp.Messages), resp.TotalCount, since)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "how far back to look")
+	cmd.Flags().Int32Var(&limit, "limit", 50, "maximum number of messages to show")
+	cmd.Flags().StringVar(&templateID, "template", "", "only show failures for this template")
+	return cmd
+}
@@ -0,0 +1,234 @@
+// cmd/whatsappctl/pauses.go
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	pb "messaging-microservice/proto"
+)
+
+// pauseScopes maps the scope argument to the proto enum
+var pauseScopes = map[string]pb.PauseScope{
+	"all":      pb.PauseScope_PAUSE_SCOPE_ALL,
+	"tenant":   pb.PauseScope_PAUSE_SCOPE_TENANT,
+	"template": pb.PauseScope_PAUSE_SCOPE_TEMPLATE,
+}
+
+// parsePauseScope reads "all", "tenant <id>" or "template <id>" from the arguments
+func parsePauseScope(args []string) (pb.PauseScope, string, error) {
+	scope, ok := pauseScopes[args[0]]
+	if !ok {
+		return 0, "", fmt.Errorf("unknown scope %q, expected all, tenant or template", args[0])
+	}
+	if scope == pb.PauseScope_PAUSE_SCOPE_ALL {
+		if len(args) > 1 {
+			return 0, "", fmt.Errorf("pausing all sends takes no ID")
+		}
+		return scope, "", nil
+	}
+	if len(args) != 2 {
+		return 0, "", fmt.Errorf("%s scope needs an ID", args[0])
+	}
+	return scope, args[1], nil
+}
+
+// newPauseCommand pauses outbound sends
+func newPauseCommand() *cobra.Command {
+	var reason, requestedBy string
+
+	cmd := &cobra.Command{
+		Use:     "pause all | tenant <id> | template <id>",
+		Short:   "Pause outbound sends; paused messages stay queued",
+		Example: "  whatsappctl pause template promo_spring --reason \"wrong price\" --by ops-oncall",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, subject, err := parsePauseScope(args)
+			if err != nil {
+				return err
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.PauseSending(ctx, &pb.PauseSendingRequest{
+				Scope:       scope,
+				Subject:     subject,
+				Reason:      reason,
+				RequestedBy: requestedBy,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.Flags().StringVar(&reason, "reason", "", "why sending is paused")
+	cmd.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible for the pause")
+
+	return cmd
+}
+
+// newResumeCommand lifts a pause
+func newResumeCommand() *cobra.Command {
+	var requestedBy string
+
+	cmd := &cobra.Command{
+		Use:     "resume all | tenant <id> | template <id>",
+		Short:   "Resume paused sends and re-enqueue the messages held meanwhile",
+		Example: "  whatsappctl resume template promo_spring --by ops-oncall",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, subject, err := parsePauseScope(args)
+			if err != nil {
+				return err
+			}
+
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.ResumeSending(ctx, &pb.ResumeSendingRequest{
+				Scope:       scope,
+				Subject:     subject,
+				RequestedBy: requestedBy,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible for resuming")
+
+	return cmd
+}
+
+// newPausesCommand lists the pauses in force
+func newPausesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pauses",
+		Short: "List the send pauses in force",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.ListSendPauses(ctx, &pb.ListSendPausesRequest{})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+}
+
+// newTemplateCommand disables, enables and lists templates switched off by the kill switch
+func newTemplateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Switch templates off and on at runtime",
+	}
+
+	var reason, requestedBy string
+	disable := &cobra.Command{
+		Use:     "disable <template-id>",
+		Short:   "Disable a template; its sends fail until it is enabled again",
+		Example: "  whatsappctl template disable promo_spring --reason \"wrong price\" --by ops-oncall",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.DisableTemplate(ctx, &pb.DisableTemplateRequest{
+				TemplateId:  args[0],
+				Reason:      reason,
+				RequestedBy: requestedBy,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+	disable.Flags().StringVar(&reason, "reason", "", "why the template is disabled")
+	disable.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible")
+
+	enable := &cobra.Command{
+		Use:   "enable <template-id>",
+		Short: "Enable a disabled template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.EnableTemplate(ctx, &pb.EnableTemplateRequest{
+				TemplateId:  args[0],
+				RequestedBy: requestedBy,
+			})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+	enable.Flags().StringVar(&requestedBy, "by", os.Getenv("USER"), "operator or ticket responsible")
+
+	list := &cobra.Command{
+		Use:   "disabled",
+		Short: "List disabled templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeConn, err := dial()
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			ctx, cancel := callContext(cmd.Context())
+			defer cancel()
+
+			resp, err := client.ListDisabledTemplates(ctx, &pb.ListDisabledTemplatesRequest{})
+			if err != nil {
+				return err
+			}
+			return printProto(resp)
+		},
+	}
+
+	cmd.AddCommand(disable, enable, list)
+	return cmd
+}
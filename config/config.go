@@ -2,17 +2,22 @@
 package config
 
 import (
-	"errors"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/secrets"
 )
 
 // Config holds all configuration for the service
 type Config struct {
+	// ValidateOnly is set by --validate-config: print the effective configuration and exit
+	ValidateOnly bool
+
 	// Server configuration
 	HTTPPort        string
 	GRPCPort        string
@@ -20,25 +25,438 @@ type Config struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	// Startup waits for Postgres and Kafka: up to StartupRetries attempts each, backing off
+	// from StartupRetryBackoff to at most StartupRetryMaxBackoff between them
+	StartupRetries         int
+	StartupRetryBackoff    time.Duration
+	StartupRetryMaxBackoff time.Duration
+	// Consumers that stop are restarted after ComponentRestartBackoff, doubling up to
+	// ComponentRestartMaxBackoff; after ComponentMaxFailures failures in a row readiness fails
+	// until a run lasts ComponentStableAfter
+	ComponentMaxFailures       int
+	ComponentRestartBackoff    time.Duration
+	ComponentRestartMaxBackoff time.Duration
+	ComponentStableAfter       time.Duration
+	// Logging starts at LogLevel, encoded as LogEncoding ("json" or "console"). With LogFile the
+	// logs go to that file instead of stdout, rotated at LogFileMaxSizeMB and keeping
+	// LogFileMaxBackups old files (0: all) for LogFileMaxAgeDays (0: forever). LogSampleInitial
+	// info entries with the same message are written per second, then every
+	// LogSampleThereafter-th; 0 disables sampling
+	LogLevel            string
+	LogEncoding         string
+	LogFile             string
+	LogFileMaxSizeMB    int
+	LogFileMaxBackups   int
+	LogFileMaxAgeDays   int
+	LogFileCompress     bool
+	LogSampleInitial    int
+	LogSampleThereafter int
+	// InstanceID names this replica in logs and worker leadership; it defaults to the hostname
+	InstanceID string
+	// LeaderElection runs the singleton background workers (campaign dispatch, quiet hours
+	// release, webhook replay, maintenance jobs) on one replica at a time, holding a Postgres
+	// advisory lock per worker checked every LeaderCheckInterval
+	LeaderElection      bool
+	LeaderCheckInterval time.Duration
+
+	// gRPC server limits. Message sizes are in bytes; GRPCMaxConcurrentStreams bounds the streams
+	// per connection (0 leaves it to grpc-go). The server pings idle connections every
+	// GRPCKeepaliveTime and drops them without an ack within GRPCKeepaliveTimeout, and closes
+	// connections of clients pinging more often than GRPCKeepaliveMinTime. GRPCMaxConnectionAge
+	// (0 = unlimited) recycles connections so load balancers can rebalance them, letting calls
+	// finish for GRPCMaxConnectionAgeGrace.
+	GRPCMaxRecvMsgSize               int
+	GRPCMaxSendMsgSize               int
+	GRPCMaxConcurrentStreams         int
+	GRPCKeepaliveTime                time.Duration
+	GRPCKeepaliveTimeout             time.Duration
+	GRPCKeepaliveMinTime             time.Duration
+	GRPCKeepalivePermitWithoutStream bool
+	GRPCMaxConnectionIdle            time.Duration
+	GRPCMaxConnectionAge             time.Duration
+	GRPCMaxConnectionAgeGrace        time.Duration
+
+	// Send path load shedding
+	SendMaxInFlight  int
+	SendMaxQueued    int
+	SendQueueTimeout time.Duration
+	SendRetryAfter   time.Duration
 
 	// Database configuration
-	DatabaseURL          string
+	DatabaseURL          string `secret:"url"`
 	DatabaseMaxOpenConns int
 	DatabaseMaxIdleConns int
+	// Connections are recycled after this lifetime or idle time
+	DatabaseConnMaxLifetime time.Duration
+	DatabaseConnMaxIdleTime time.Duration
+	// Optional read replica for lookups, listings and counts; reads fall back to the primary
+	// while its replication lag exceeds DatabaseReplicaMaxLag
+	DatabaseReadURL              string `secret:"url"`
+	DatabaseReplicaMaxLag        time.Duration
+	DatabaseReplicaCheckInterval time.Duration
+	// DatabaseDriver "pgx" runs inserts, status updates and per-send lookups on a pgx pool
+	// (sized like the lib/pq one) with DatabaseStatementCacheSize prepared statements per
+	// connection; "pq" (default) keeps everything on lib/pq
+	DatabaseDriver             string
+	DatabaseStatementCacheSize int
 
 	// Meta WhatsApp configuration
 	MetaPhoneNumberID string
-	MetaAccessToken   string
-	MetaAppSecret     string
-	MetaVerifyToken   string
+	MetaAccessToken   string `secret:"true"`
+	MetaAppSecret     string `secret:"true"`
+	MetaVerifyToken   string `secret:"true"`
+	MetaAppID         string
+	// MetaCatalogID is the WhatsApp Business catalog product messages use unless they name one
+	MetaCatalogID string
+
+	// Access token lifecycle: how often it is revalidated and how long before expiry it is refreshed
+	MetaTokenCheckInterval time.Duration
+	MetaTokenRefreshBefore time.Duration
+
+	// MetaAPIVersion is the Graph API version requests go to; startup warns when it is within
+	// MetaAPIVersionWarnBefore of the end of Meta's support for it
+	MetaAPIVersion           string
+	MetaAPIVersionWarnBefore time.Duration
+
+	// Twilio configuration (used when a provider is "twilio"). Templates are sent through the
+	// Content API: TwilioContentSIDs maps template names to content SIDs (HX...)
+	TwilioAccountSID          string
+	TwilioAuthToken           string `secret:"true"`
+	TwilioFrom                string
+	TwilioMessagingServiceSID string
+	TwilioContentSIDs         map[string]string
+	TwilioStatusCallbackURL   string
+	// TwilioCatalogFile holds localized bodies of templates without a content SID, sent as
+	// plain text in the recipient's locale or TwilioDefaultLocale; the template_translations
+	// table adds to and overrides it
+	TwilioCatalogFile   string
+	TwilioDefaultLocale string
+	// TwilioSenderTenants maps senders ("whatsapp:+14155238886") or messaging service SIDs to
+	// the tenant that owns them, for routing status callbacks
+	TwilioSenderTenants map[string]string
+
+	// WhatsApp provider: "meta", "twilio" or "mock"
+	WhatsAppProvider string
+
+	// Canary rollout: CanaryPercent of phone numbers (sticky) are sent through CanaryProvider.
+	// When more than CanaryMaxFailureRate of its last CanaryWindow sends fail (once at least
+	// CanaryMinSamples were made) the canary is rolled back for CanaryCooldown
+	CanaryProvider       string
+	CanaryPercent        float64
+	CanaryMaxFailureRate float64
+	CanaryWindow         int
+	CanaryMinSamples     int
+	CanaryCooldown       time.Duration
+
+	// Circuit breaker: after ProviderBreakerFailures consecutive provider errors (rate limits,
+	// server errors, timeouts; 0 disables) sends are rejected for ProviderBreakerCooldown, then
+	// one probe decides whether to close it
+	ProviderBreakerFailures int
+	ProviderBreakerCooldown time.Duration
+	// ProviderSendRate paces sends to the provider as "rps:burst", shared between replicas
+	// through Redis when configured; empty disables pacing
+	ProviderSendRate string
+	// Provider requests get the time left of the gRPC deadline they serve, at most
+	// ProviderTimeoutCeiling (also the bound of queued sends), and are not started with less
+	// than ProviderTimeoutFloor left
+	ProviderTimeoutFloor   time.Duration
+	ProviderTimeoutCeiling time.Duration
+	// Provider requests are tried up to ProviderHTTPAttempts times, each bounded by
+	// ProviderHTTPAttemptTimeout (0 = only the call's deadline), backing off from
+	// ProviderHTTPRetryBackoff to ProviderHTTPRetryMaxBackoff with jitter. Sends are only
+	// retried when they could not connect.
+	ProviderHTTPAttempts        int
+	ProviderHTTPAttemptTimeout  time.Duration
+	ProviderHTTPRetryBackoff    time.Duration
+	ProviderHTTPRetryMaxBackoff time.Duration
+	// ProviderHTTPSProxy routes provider requests through a proxy (empty: HTTPS_PROXY and
+	// NO_PROXY apply) and ProviderCABundle is a PEM file of CAs trusted besides the system roots
+	ProviderHTTPSProxy string `secret:"url"`
+	ProviderCABundle   string
+	// ProviderCapture stores the provider requests and responses of every send, credentials
+	// redacted and bodies cut at ProviderCaptureMaxBody bytes, for ProviderCaptureRetention
+	ProviderCapture          bool
+	ProviderCaptureMaxBody   int
+	ProviderCaptureRetention time.Duration
+
+	// Provider failover: new sends move to FailoverProvider while more than FailoverMaxErrorRate
+	// of the primary's last FailoverWindow sends failed or their p95 latency exceeds
+	// FailoverMaxLatency (0 disables the latency check). While failed over one send per
+	// FailoverProbeInterval still goes to the primary, and FailoverRecoveryProbes healthy
+	// probes in a row fail back.
+	FailoverProvider       string
+	FailoverMaxErrorRate   float64
+	FailoverMaxLatency     time.Duration
+	FailoverWindow         int
+	FailoverMinSamples     int
+	FailoverProbeInterval  time.Duration
+	FailoverRecoveryProbes int
+
+	// Mock provider configuration (used when WhatsAppProvider is "mock")
+	MockWebhookURL     string
+	MockDeliveredDelay time.Duration
+	MockReadDelay      time.Duration
+	MockFailureRate    float64
+
+	// Data retention: messages (with their status history) older than RetentionMessageDays and
+	// inbound messages older than RetentionWebhookEventDays are purged every RetentionInterval
+	// in batches of RetentionBatchSize (0 days keeps them forever)
+	RetentionMessageDays      int
+	RetentionWebhookEventDays int
+	RetentionInterval         time.Duration
+	RetentionBatchSize        int
+
+	// MessagePartitionsAhead is how many months of messages partitions the maintenance job
+	// creates beyond the current one; 0 leaves partitions to an external tool
+	MessagePartitionsAhead int
+
+	// Message archive: messages older than ArchiveAfterDays are moved to object storage every
+	// ArchiveInterval, leaving stub rows (0 days disables archiving). ArchiveStore is "s3"
+	// (ArchiveBucket, with ArchiveS3Endpoint for GCS or MinIO) or "file" (ArchiveDir)
+	ArchiveAfterDays  int
+	ArchiveInterval   time.Duration
+	ArchiveBatchSize  int
+	ArchiveStore      string
+	ArchiveBucket     string
+	ArchiveS3Endpoint string
+	ArchiveRegion     string
+	ArchiveDir        string
+
+	// Tenants keyed by the Meta phone number ID they send from
+	PhoneNumberTenants map[string]string
+	// MetaWABAID is the WhatsApp Business Account whose account and quality webhooks belong to
+	// the "default" tenant; WABATenants maps further account IDs to tenants
+	MetaWABAID  string
+	WABATenants map[string]string
 
 	// Kafka configuration
-	KafkaBrokers []string
-	KafkaTopic   string
-	KafkaGroupID string
+	KafkaBrokers     []string
+	KafkaTopic       string
+	KafkaStatusTopic string
+	KafkaGroupID     string
+	// KafkaProviderEventsTopic receives provider switchover events; empty only logs them
+	KafkaProviderEventsTopic string
+	// Producer batching: a batch is written once it holds KafkaBatchSize messages or
+	// KafkaBatchBytes bytes, or after lingering KafkaBatchTimeout
+	KafkaBatchSize    int
+	KafkaBatchBytes   int
+	KafkaBatchTimeout time.Duration
+	// KafkaCompression is none, gzip, snappy, lz4 or zstd
+	KafkaCompression string
+	// Kafka writes are bounded like provider requests, by KafkaProduceTimeoutFloor and
+	// KafkaProduceTimeoutCeiling
+	KafkaProduceTimeoutFloor   time.Duration
+	KafkaProduceTimeoutCeiling time.Duration
+	// KafkaProducerAsync returns from sends once the message is buffered; write failures then
+	// mark the message failed when the broker reports them
+	KafkaProducerAsync bool
+	// MessageOutbox stores each queue payload in the transaction creating its message; payloads
+	// still unpublished after OutboxRelayGrace are produced every OutboxRelayInterval, and
+	// published ones are purged by the retention job after OutboxRetention
+	MessageOutbox       bool
+	OutboxRelayInterval time.Duration
+	OutboxRelayGrace    time.Duration
+	OutboxRetention     time.Duration
+	// KafkaRetryDelays are the delayed retry tiers of transient send failures, e.g. 1m,10m,1h;
+	// a message failing every tier lands in the <KafkaTopic>.dlq topic. Empty disables retries.
+	KafkaRetryDelays []time.Duration
+	// KafkaAutoCreateTopics creates missing topics at startup instead of waiting for them, with
+	// KafkaTopicPartitions partitions and KafkaTopicReplicationFactor replicas (0 leaves either
+	// to the broker default); KafkaTopicLayouts overrides both per topic as topic=partitions[:replication]
+	KafkaAutoCreateTopics       bool
+	KafkaTopicPartitions        int
+	KafkaTopicReplicationFactor int
+	KafkaTopicLayouts           map[string]string
+	// Consumer alerts fire when the group's lag exceeds ConsumerMaxLag or more than
+	// ConsumerMaxFailureRate of an interval's messages (once it has ConsumerAlertMinMessages)
+	// fail; 0 disables either. They are checked every ConsumerMonitorInterval.
+	ConsumerMaxLag           int
+	ConsumerMaxFailureRate   float64
+	ConsumerAlertMinMessages int
+	ConsumerMonitorInterval  time.Duration
+	// Alerting rules are evaluated every AlertEvaluationInterval and fire when more than
+	// AlertMaxSendFailureRate of an interval's provider sends (once it has AlertMinSends) fail,
+	// more than AlertMaxDLQDepth dead-letter messages wait to be replayed by AlertDLQGroup, more
+	// than AlertMaxSignatureFailures webhooks in an interval fail signature validation, or the
+	// Meta access token expires within AlertTokenExpiryWithin; 0 disables each.
+	AlertEvaluationInterval   time.Duration
+	AlertMaxSendFailureRate   float64
+	AlertMinSends             int
+	AlertMaxDLQDepth          int
+	AlertDLQGroup             string
+	AlertMaxSignatureFailures int
+	AlertTokenExpiryWithin    time.Duration
+	// Alerts are posted to a Slack incoming webhook and/or a PagerDuty Events v2 integration
+	AlertSlackWebhookURL     string `secret:"true"`
+	AlertPagerDutyRoutingKey string `secret:"true"`
+	// and/or mailed to AlertEmailTo through the SMTP server at AlertEmailSMTPAddr (host:port),
+	// authenticating when AlertEmailUsername is set
+	AlertEmailSMTPAddr string
+	AlertEmailUsername string
+	AlertEmailPassword string `secret:"true"`
+	AlertEmailFrom     string
+	AlertEmailTo       []string
+
+	// TemplateAlertSlackWebhookURL tells template owners when Meta pauses or disables a template
+	TemplateAlertSlackWebhookURL string `secret:"true"`
+
+	// AuditTopic receives every audit log entry as JSON as well; empty only stores them
+	AuditTopic string
+
+	// AdminActors are the callers, as named in GRPCAPIKeys, allowed to hard delete messages and
+	// to change settings reaching every tenant, such as pausing all sends, disabling a template
+	// or changing country rules; when empty, messages can only be soft deleted and callers only
+	// pause their own tenant
+	AdminActors []string
+
+	// SchemaRegistryURL enables Avro payloads on the send and status topics with schemas
+	// registered in a Confluent Schema Registry; empty keeps plain JSON
+	SchemaRegistryURL      string `secret:"url"`
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string `secret:"true"`
+
+	// Secrets provider: "env" (default), "vault" or "aws"; refreshed every SecretsRefreshInterval
+	SecretsProvider        string
+	SecretsRefreshInterval time.Duration
+	VaultAddr              string
+	VaultToken             string `secret:"true"`
+	VaultSecretPath        string
+	AWSRegion              string
+	AWSSecretID            string
+
+	// Secrets is the loaded secret store, nil when secrets come from the environment
+	Secrets *secrets.Store
+
+	// Redis used for shared state such as rate limits (in-memory fallback when empty)
+	RedisURL string `secret:"url"`
+
+	// Cache of external ID to message ID for webhook lookups: "memory" (an LRU of
+	// ExternalIDCacheSize entries), "redis" (REDIS_URL) or "none"
+	ExternalIDCache     string
+	ExternalIDCacheSize int
+	ExternalIDCacheTTL  time.Duration
+
+	// Webhook replay protection: status events dated more than WebhookMaxEventAge ago, and
+	// events already processed within it, are handled per WebhookReplayAction ("reject" or
+	// "flag"). Processed events are remembered in WebhookNonceStore: "memory" (an LRU of
+	// WebhookNonceCacheSize events), "redis" (REDIS_URL) or "none"
+	WebhookMaxEventAge    time.Duration
+	WebhookReplayAction   string
+	WebhookNonceStore     string
+	WebhookNonceCacheSize int
+
+	// Changes of Meta webhooks that fail are stored and replayed every
+	// WebhookFailureReplayInterval, at most WebhookFailureMaxAttempts times, and purged by the
+	// retention job WebhookFailureRetention after they failed
+	WebhookFailureReplayInterval time.Duration
+	WebhookFailureMaxAttempts    int
+	WebhookFailureRetention      time.Duration
+
+	// Statuses for external IDs no message has are quarantined and matched again every
+	// WebhookQuarantineInterval for WebhookQuarantineWindow (0 drops them)
+	WebhookQuarantineWindow   time.Duration
+	WebhookQuarantineInterval time.Duration
+	// WebhookLookupWait is how long a webhook waits for unknown external IDs to be stored
+	// before quarantining their statuses (0 doesn't wait)
+	WebhookLookupWait time.Duration
+
+	// MaintenanceMode pauses all outbound sends (messages stay queued) until it is unset.
+	// Pauses set through the admin API reach every replica within PauseRefreshInterval
+	MaintenanceMode      bool
+	PauseRefreshInterval time.Duration
+
+	// Templates disabled through the admin API reach every replica within TemplateRefreshInterval
+	TemplateRefreshInterval time.Duration
+	// A template is disabled, and alerted on, when more than TemplateMaxFailureRate of its sends
+	// in the last TemplateFailureWindow fail, once there were TemplateFailureMinMessages; 0
+	// disables it
+	TemplateMaxFailureRate     float64
+	TemplateFailureWindow      time.Duration
+	TemplateFailureMinMessages int
+	// TemplateSafeMode sandboxes the message bodies this service renders, see render.Options
+	TemplateSafeMode bool
+
+	// StatusTransitionMetrics counts every message status transition by previous and new status
+	StatusTransitionMetrics bool
+
+	// DuplicateWindow collapses a send of the same template and parameters to the same
+	// recipient within it into the earlier message; 0 disables the check
+	DuplicateWindow time.Duration
+
+	// While a phone number is rated red the provider send rate is scaled by
+	// QualityRedRateFactor; other replicas pick ratings up within QualityRefreshInterval
+	QualityRedRateFactor   float64
+	QualityRefreshInterval time.Duration
+
+	// Destination country rules: dialing prefixes ("44", "1876") sends are allowed to or blocked
+	// from. Once any prefix is allowed, numbers matching no rule are blocked. Rules set through
+	// the admin API override these and reach every replica within CountryRefreshInterval
+	CountryAllowlist       []string
+	CountryBlocklist       []string
+	CountryRefreshInterval time.Duration
+
+	// Quiet hours ("21:00-09:00", recipient local time) in which sends of MarketingTemplates are
+	// deferred to the end of the window; empty disables them. QuietHoursTenants overrides the
+	// window per tenant, with "off" exempting a tenant. Deferred messages are re-enqueued every
+	// QuietHoursReleaseInterval
+	QuietHours                string
+	QuietHoursTenants         map[string]string
+	MarketingTemplates        []string
+	QuietHoursReleaseInterval time.Duration
+
+	// OptInRequired refuses sends of MarketingTemplates to contacts whose latest recorded
+	// opt-in event isn't an opt-in
+	OptInRequired bool
+
+	// Campaigns send to their materialized audience every CampaignDispatchInterval, up to
+	// CampaignDispatchBatch recipients at a time
+	CampaignDispatchInterval time.Duration
+	CampaignDispatchBatch    int
+
+	// Inbound messages are handed to agents through HandoffChannel: "kafka" publishes to
+	// HandoffTopic, "webhook" posts to HandoffWebhookURL (with HandoffWebhookToken as a bearer
+	// token), empty only records them. Requests carry the last HandoffContextMessages messages.
+	HandoffChannel         string
+	HandoffTopic           string
+	HandoffWebhookURL      string `secret:"url"`
+	HandoffWebhookToken    string `secret:"true"`
+	HandoffContextMessages int
+	// InboundHandlerTimeout bounds each bot handler looking at an inbound message (0 is unbounded)
+	InboundHandlerTimeout time.Duration
+	// InboundTypingDelay is how long a bot handler works on a message before the customer is
+	// shown a typing indicator
+	InboundTypingDelay time.Duration
+
+	// Monthly message quotas (0 is unlimited); QuotaTenants and QuotaCustomers override the defaults
+	// per ID. Sends over quota are rejected, or with QuotaExceededAction "record" stored unsent
+	QuotaTenantMonthly   int
+	QuotaCustomerMonthly int
+	QuotaTenants         map[string]string
+	QuotaCustomers       map[string]string
+	QuotaExceededAction  string
+
+	// HTTP rate limits written as "rps:burst"; RateLimitDefault applies when nothing more specific does
+	RateLimitDefault string
+	RateLimitRoutes  map[string]string
+	RateLimitAPIKeys map[string]string `secret:"true"`
+
+	// GRPCAPIKeys authenticate gRPC callers by their x-api-key metadata, written
+	// key=tenant:caller; a key's requests only reach its tenant. When empty, any caller is
+	// accepted and picks its tenant with x-tenant-id.
+	GRPCAPIKeys map[string]string `secret:"true"`
+
+	// gRPC limits per caller (x-api-key metadata, or tenant ID): request rates written as
+	// "rps:burst" and messages sent per UTC day (0 is unlimited); empty defaults disable them
+	GRPCRateLimitDefault     string
+	GRPCRateLimits           map[string]string `secret:"true"`
+	GRPCDailyMessagesDefault int
+	GRPCDailyMessages        map[string]string `secret:"true"`
+
+	// Key used to pseudonymize phone numbers in events and exports (disabled when empty)
+	PhoneHashKey string `secret:"true"`
 
 	// JWT configuration
-	JWTSecret     string
+	JWTSecret     string `secret:"true"`
 	JWTExpiration time.Duration
 
 	// Template IDs for WhatsApp
@@ -49,76 +467,448 @@ type Config struct {
 	DelayNotificationTemplateID    string
 }
 
-// Load reads configuration from environment variables
-func Load() (*Config, error) {
+// Load reads configuration from command-line flags, environment variables and an optional
+// YAML file, in that order of precedence. Flags are written --http-port=8080 for HTTP_PORT;
+// --config selects the YAML file (also CONFIG_FILE) and --validate-config sets ValidateOnly.
+func Load(args ...string) (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
+	l, err := newLoader(args)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		HTTPPort:        getEnv("HTTP_PORT", "8080"),
-		GRPCPort:        getEnv("GRPC_PORT", "9090"),
-		Environment:     getEnv("ENVIRONMENT", "development"),
-		ReadTimeout:     getEnvAsDuration("READ_TIMEOUT", 5*time.Second),
-		WriteTimeout:    getEnvAsDuration("WRITE_TIMEOUT", 10*time.Second),
-		ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		ValidateOnly: l.validateOnly,
+
+		HTTPPort:        l.getEnv("HTTP_PORT", "8080"),
+		GRPCPort:        l.getEnv("GRPC_PORT", "9090"),
+		Environment:     l.getEnv("ENVIRONMENT", "development"),
+		ReadTimeout:     l.getEnvAsDuration("READ_TIMEOUT", 5*time.Second),
+		WriteTimeout:    l.getEnvAsDuration("WRITE_TIMEOUT", 10*time.Second),
+		ShutdownTimeout: l.getEnvAsDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+
+		StartupRetries:         l.getEnvAsInt("STARTUP_RETRIES", 10),
+		StartupRetryBackoff:    l.getEnvAsDuration("STARTUP_RETRY_BACKOFF", time.Second),
+		StartupRetryMaxBackoff: l.getEnvAsDuration("STARTUP_RETRY_MAX_BACKOFF", 30*time.Second),
+		InstanceID:             l.getEnv("INSTANCE_ID", ""),
+		LeaderElection:         l.getEnvAsBool("LEADER_ELECTION", true),
+		LeaderCheckInterval:    l.getEnvAsDuration("LEADER_CHECK_INTERVAL", 5*time.Second),
+
+		ComponentMaxFailures:       l.getEnvAsInt("COMPONENT_MAX_FAILURES", 5),
+		ComponentRestartBackoff:    l.getEnvAsDuration("COMPONENT_RESTART_BACKOFF", time.Second),
+		ComponentRestartMaxBackoff: l.getEnvAsDuration("COMPONENT_RESTART_MAX_BACKOFF", time.Minute),
+		ComponentStableAfter:       l.getEnvAsDuration("COMPONENT_STABLE_AFTER", time.Minute),
+
+		LogLevel:            l.getEnv("LOG_LEVEL", "info"),
+		LogEncoding:         l.getEnv("LOG_ENCODING", "json"),
+		LogFile:             l.getEnv("LOG_FILE", ""),
+		LogFileMaxSizeMB:    l.getEnvAsInt("LOG_FILE_MAX_SIZE_MB", 100),
+		LogFileMaxBackups:   l.getEnvAsInt("LOG_FILE_MAX_BACKUPS", 5),
+		LogFileMaxAgeDays:   l.getEnvAsInt("LOG_FILE_MAX_AGE_DAYS", 30),
+		LogFileCompress:     l.getEnvAsBool("LOG_FILE_COMPRESS", true),
+		LogSampleInitial:    l.getEnvAsInt("LOG_SAMPLE_INITIAL", 0),
+		LogSampleThereafter: l.getEnvAsInt("LOG_SAMPLE_THEREAFTER", 100),
+
+		GRPCMaxRecvMsgSize:               l.getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 4<<20),
+		GRPCMaxSendMsgSize:               l.getEnvAsInt("GRPC_MAX_SEND_MSG_SIZE", 16<<20),
+		GRPCMaxConcurrentStreams:         l.getEnvAsInt("GRPC_MAX_CONCURRENT_STREAMS", 0),
+		GRPCKeepaliveTime:                l.getEnvAsDuration("GRPC_KEEPALIVE_TIME", 2*time.Minute),
+		GRPCKeepaliveTimeout:             l.getEnvAsDuration("GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+		GRPCKeepaliveMinTime:             l.getEnvAsDuration("GRPC_KEEPALIVE_MIN_TIME", 30*time.Second),
+		GRPCKeepalivePermitWithoutStream: l.getEnvAsBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true),
+		GRPCMaxConnectionIdle:            l.getEnvAsDuration("GRPC_MAX_CONNECTION_IDLE", 0),
+		GRPCMaxConnectionAge:             l.getEnvAsDuration("GRPC_MAX_CONNECTION_AGE", 0),
+		GRPCMaxConnectionAgeGrace:        l.getEnvAsDuration("GRPC_MAX_CONNECTION_AGE_GRACE", 30*time.Second),
+
+		SendMaxInFlight:  l.getEnvAsInt("SEND_MAX_IN_FLIGHT", 100),
+		SendMaxQueued:    l.getEnvAsInt("SEND_MAX_QUEUED", 200),
+		SendQueueTimeout: l.getEnvAsDuration("SEND_QUEUE_TIMEOUT", 500*time.Millisecond),
+		SendRetryAfter:   l.getEnvAsDuration("SEND_RETRY_AFTER", time.Second),
+
+		DatabaseURL:             l.getEnv("DATABASE_URL", ""),
+		DatabaseMaxOpenConns:    l.getEnvAsInt("DATABASE_MAX_OPEN_CONNS", 20),
+		DatabaseMaxIdleConns:    l.getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5),
+		DatabaseConnMaxLifetime: l.getEnvAsDuration("DATABASE_CONN_MAX_LIFETIME", 30*time.Minute),
+		DatabaseConnMaxIdleTime: l.getEnvAsDuration("DATABASE_CONN_MAX_IDLE_TIME", 5*time.Minute),
+
+		DatabaseReadURL:              l.getEnv("DATABASE_READ_URL", ""),
+		DatabaseReplicaMaxLag:        l.getEnvAsDuration("DATABASE_REPLICA_MAX_LAG", 5*time.Second),
+		DatabaseReplicaCheckInterval: l.getEnvAsDuration("DATABASE_REPLICA_CHECK_INTERVAL", 5*time.Second),
+
+		DatabaseDriver:             l.getEnv("DATABASE_DRIVER", "pq"),
+		DatabaseStatementCacheSize: l.getEnvAsInt("DATABASE_STATEMENT_CACHE_SIZE", 512),
+
+		MetaPhoneNumberID: l.getEnv("META_PHONE_NUMBER_ID", ""),
+		MetaAccessToken:   l.getEnv("META_ACCESS_TOKEN", ""),
+		MetaAppSecret:     l.getEnv("META_APP_SECRET", ""),
+		MetaVerifyToken:   l.getEnv("META_VERIFY_TOKEN", ""),
+		MetaAppID:         l.getEnv("META_APP_ID", ""),
+		MetaCatalogID:     l.getEnv("META_CATALOG_ID", ""),
+
+		PhoneNumberTenants: l.getEnvAsMap("META_PHONE_NUMBER_TENANTS"),
+
+		MetaWABAID:  l.getEnv("META_WABA_ID", ""),
+		WABATenants: l.getEnvAsMap("META_WABA_TENANTS"),
+
+		TwilioAccountSID:          l.getEnv("TWILIO_ACCOUNT_SID", ""),
+		TwilioAuthToken:           l.getEnv("TWILIO_AUTH_TOKEN", ""),
+		TwilioFrom:                l.getEnv("TWILIO_FROM", ""),
+		TwilioMessagingServiceSID: l.getEnv("TWILIO_MESSAGING_SERVICE_SID", ""),
+		TwilioContentSIDs:         l.getEnvAsMap("TWILIO_CONTENT_SIDS"),
+		TwilioStatusCallbackURL:   l.getEnv("TWILIO_STATUS_CALLBACK_URL", ""),
+		TwilioSenderTenants:       l.getEnvAsMap("TWILIO_SENDER_TENANTS"),
+		TwilioCatalogFile:         l.getEnv("TWILIO_CATALOG_FILE", ""),
+		TwilioDefaultLocale:       l.getEnv("TWILIO_DEFAULT_LOCALE", "en"),
+
+		RetentionMessageDays:      l.getEnvAsInt("RETENTION_MESSAGE_DAYS", 0),
+		RetentionWebhookEventDays: l.getEnvAsInt("RETENTION_WEBHOOK_EVENT_DAYS", 0),
+		RetentionInterval:         l.getEnvAsDuration("RETENTION_INTERVAL", time.Hour),
+		RetentionBatchSize:        l.getEnvAsInt("RETENTION_BATCH_SIZE", 1000),
+
+		MessagePartitionsAhead: l.getEnvAsInt("MESSAGE_PARTITIONS_AHEAD", 3),
+
+		ArchiveAfterDays:  l.getEnvAsInt("ARCHIVE_AFTER_DAYS", 0),
+		ArchiveInterval:   l.getEnvAsDuration("ARCHIVE_INTERVAL", time.Hour),
+		ArchiveBatchSize:  l.getEnvAsInt("ARCHIVE_BATCH_SIZE", 1000),
+		ArchiveStore:      l.getEnv("ARCHIVE_STORE", "s3"),
+		ArchiveBucket:     l.getEnv("ARCHIVE_BUCKET", ""),
+		ArchiveS3Endpoint: l.getEnv("ARCHIVE_S3_ENDPOINT", ""),
+		ArchiveRegion:     l.getEnv("ARCHIVE_REGION", ""),
+		ArchiveDir:        l.getEnv("ARCHIVE_DIR", "./archive"),
+
+		MetaTokenCheckInterval: l.getEnvAsDuration("META_TOKEN_CHECK_INTERVAL", time.Hour),
+		MetaTokenRefreshBefore: l.getEnvAsDuration("META_TOKEN_REFRESH_BEFORE", 7*24*time.Hour),
+
+		MetaAPIVersion:           l.getEnv("META_API_VERSION", meta.DefaultAPIVersion),
+		MetaAPIVersionWarnBefore: l.getEnvAsDuration("META_API_VERSION_WARN_BEFORE", 90*24*time.Hour),
+
+		WhatsAppProvider:     l.getEnv("WHATSAPP_PROVIDER", "meta"),
+		CanaryProvider:       l.getEnv("CANARY_PROVIDER", ""),
+		CanaryPercent:        l.getEnvAsFloat("CANARY_PERCENT", 0),
+		CanaryMaxFailureRate: l.getEnvAsFloat("CANARY_MAX_FAILURE_RATE", 0.2),
+		CanaryWindow:         l.getEnvAsInt("CANARY_WINDOW", 200),
+		CanaryMinSamples:     l.getEnvAsInt("CANARY_MIN_SAMPLES", 50),
+		CanaryCooldown:       l.getEnvAsDuration("CANARY_COOLDOWN", 15*time.Minute),
+
+		ProviderBreakerFailures:     l.getEnvAsInt("PROVIDER_BREAKER_FAILURES", 0),
+		ProviderBreakerCooldown:     l.getEnvAsDuration("PROVIDER_BREAKER_COOLDOWN", 30*time.Second),
+		ProviderTimeoutFloor:        l.getEnvAsDuration("PROVIDER_TIMEOUT_FLOOR", 100*time.Millisecond),
+		ProviderTimeoutCeiling:      l.getEnvAsDuration("PROVIDER_TIMEOUT_CEILING", 10*time.Second),
+		ProviderHTTPAttempts:        l.getEnvAsInt("PROVIDER_HTTP_ATTEMPTS", 3),
+		ProviderHTTPAttemptTimeout:  l.getEnvAsDuration("PROVIDER_HTTP_ATTEMPT_TIMEOUT", 5*time.Second),
+		ProviderHTTPRetryBackoff:    l.getEnvAsDuration("PROVIDER_HTTP_RETRY_BACKOFF", 200*time.Millisecond),
+		ProviderHTTPRetryMaxBackoff: l.getEnvAsDuration("PROVIDER_HTTP_RETRY_MAX_BACKOFF", 2*time.Second),
+		ProviderHTTPSProxy:          l.getEnv("PROVIDER_HTTPS_PROXY", ""),
+		ProviderCABundle:            l.getEnv("PROVIDER_CA_BUNDLE", ""),
+		ProviderCapture:             l.getEnvAsBool("PROVIDER_CAPTURE", false),
+		ProviderCaptureMaxBody:      l.getEnvAsInt("PROVIDER_CAPTURE_MAX_BODY", 64<<10),
+		ProviderCaptureRetention:    l.getEnvAsDuration("PROVIDER_CAPTURE_RETENTION", 72*time.Hour),
+		ProviderSendRate:            l.getEnv("PROVIDER_SEND_RATE", ""),
+
+		FailoverProvider:       l.getEnv("FAILOVER_PROVIDER", ""),
+		FailoverMaxErrorRate:   l.getEnvAsFloat("FAILOVER_MAX_ERROR_RATE", 0.5),
+		FailoverMaxLatency:     l.getEnvAsDuration("FAILOVER_MAX_LATENCY", 5*time.Second),
+		FailoverWindow:         l.getEnvAsInt("FAILOVER_WINDOW", 100),
+		FailoverMinSamples:     l.getEnvAsInt("FAILOVER_MIN_SAMPLES", 20),
+		FailoverProbeInterval:  l.getEnvAsDuration("FAILOVER_PROBE_INTERVAL", 30*time.Second),
+		FailoverRecoveryProbes: l.getEnvAsInt("FAILOVER_RECOVERY_PROBES", 3),
 
-		DatabaseURL:          getEnv("DATABASE_URL", ""),
-		DatabaseMaxOpenConns: getEnvAsInt("DATABASE_MAX_OPEN_CONNS", 20),
-		DatabaseMaxIdleConns: getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5),
+		MockWebhookURL:     l.getEnv("MOCK_WEBHOOK_URL", ""),
+		MockDeliveredDelay: l.getEnvAsDuration("MOCK_DELIVERED_DELAY", 2*time.Second),
+		MockReadDelay:      l.getEnvAsDuration("MOCK_READ_DELAY", 5*time.Second),
+		MockFailureRate:    l.getEnvAsFloat("MOCK_FAILURE_RATE", 0),
 
-		MetaPhoneNumberID: getEnv("META_PHONE_NUMBER_ID", ""),
-		MetaAccessToken:   getEnv("META_ACCESS_TOKEN", ""),
-		MetaAppSecret:     getEnv("META_APP_SECRET", ""),
-		MetaVerifyToken:   getEnv("META_VERIFY_TOKEN", ""),
+		KafkaBrokers:               strings.Split(l.getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
+		KafkaTopic:                 l.getEnv("KAFKA_TOPIC", "whatsapp-messages"),
+		KafkaStatusTopic:           l.getEnv("KAFKA_STATUS_TOPIC", "whatsapp-status-events"),
+		KafkaGroupID:               l.getEnv("KAFKA_GROUP_ID", "whatsapp-microservice"),
+		KafkaProviderEventsTopic:   l.getEnv("KAFKA_PROVIDER_EVENTS_TOPIC", ""),
+		KafkaBatchSize:             l.getEnvAsInt("KAFKA_BATCH_SIZE", 100),
+		KafkaBatchBytes:            l.getEnvAsInt("KAFKA_BATCH_BYTES", 1048576),
+		KafkaBatchTimeout:          l.getEnvAsDuration("KAFKA_BATCH_TIMEOUT", time.Second),
+		KafkaCompression:           l.getEnv("KAFKA_COMPRESSION", "none"),
+		KafkaProduceTimeoutFloor:   l.getEnvAsDuration("KAFKA_PRODUCE_TIMEOUT_FLOOR", 100*time.Millisecond),
+		KafkaProduceTimeoutCeiling: l.getEnvAsDuration("KAFKA_PRODUCE_TIMEOUT_CEILING", 10*time.Second),
+		KafkaProducerAsync:         l.getEnvAsBool("KAFKA_PRODUCER_ASYNC", false),
+		MessageOutbox:              l.getEnvAsBool("MESSAGE_OUTBOX", false),
+		OutboxRelayInterval:        l.getEnvAsDuration("OUTBOX_RELAY_INTERVAL", 5*time.Second),
+		OutboxRelayGrace:           l.getEnvAsDuration("OUTBOX_RELAY_GRACE", 30*time.Second),
+		OutboxRetention:            l.getEnvAsDuration("OUTBOX_RETENTION", 24*time.Hour),
+		KafkaRetryDelays:           l.getEnvAsDurationList("KAFKA_RETRY_DELAYS"),
+		KafkaAutoCreateTopics:      l.getEnvAsBool("KAFKA_AUTO_CREATE_TOPICS", false),
+		ConsumerMaxLag:             l.getEnvAsInt("CONSUMER_MAX_LAG", 0),
+		ConsumerMaxFailureRate:     l.getEnvAsFloat("CONSUMER_MAX_FAILURE_RATE", 0),
+		ConsumerAlertMinMessages:   l.getEnvAsInt("CONSUMER_ALERT_MIN_MESSAGES", 20),
+		ConsumerMonitorInterval:    l.getEnvAsDuration("CONSUMER_MONITOR_INTERVAL", 30*time.Second),
+		AlertEvaluationInterval:    l.getEnvAsDuration("ALERT_EVALUATION_INTERVAL", time.Minute),
+		AlertMaxSendFailureRate:    l.getEnvAsFloat("ALERT_MAX_SEND_FAILURE_RATE", 0),
+		AlertMinSends:              l.getEnvAsInt("ALERT_MIN_SENDS", 20),
+		AlertMaxDLQDepth:           l.getEnvAsInt("ALERT_MAX_DLQ_DEPTH", 0),
+		AlertDLQGroup:              l.getEnv("ALERT_DLQ_GROUP", "whatsappctl-dlq-replay"),
+		AlertMaxSignatureFailures:  l.getEnvAsInt("ALERT_MAX_SIGNATURE_FAILURES", 0),
+		AlertTokenExpiryWithin:     l.getEnvAsDuration("ALERT_TOKEN_EXPIRY_WITHIN", 0),
+		AlertSlackWebhookURL:       l.getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+		AlertPagerDutyRoutingKey:   l.getEnv("ALERT_PAGERDUTY_ROUTING_KEY", ""),
+		AlertEmailSMTPAddr:         l.getEnv("ALERT_EMAIL_SMTP_ADDR", ""),
+		AlertEmailUsername:         l.getEnv("ALERT_EMAIL_USERNAME", ""),
+		AlertEmailPassword:         l.getEnv("ALERT_EMAIL_PASSWORD", ""),
+		AlertEmailFrom:             l.getEnv("ALERT_EMAIL_FROM", ""),
+		AlertEmailTo:               l.getEnvAsList("ALERT_EMAIL_TO"),
+		SchemaRegistryURL:          l.getEnv("SCHEMA_REGISTRY_URL", ""),
+		SchemaRegistryUsername:     l.getEnv("SCHEMA_REGISTRY_USERNAME", ""),
+		SchemaRegistryPassword:     l.getEnv("SCHEMA_REGISTRY_PASSWORD", ""),
 
-		KafkaBrokers: strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
-		KafkaTopic:   getEnv("KAFKA_TOPIC", "whatsapp-messages"),
-		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "whatsapp-microservice"),
+		TemplateAlertSlackWebhookURL: l.getEnv("TEMPLATE_ALERT_SLACK_WEBHOOK_URL", ""),
 
-		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
-		JWTExpiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+		AuditTopic:  l.getEnv("AUDIT_TOPIC", ""),
+		AdminActors: l.getEnvAsList("ADMIN_ACTORS"),
 
-		OrderConfirmationTemplateID:    getEnv("ORDER_CONFIRMATION_TEMPLATE_ID", ""),
-		ShipmentDispatchedTemplateID:   getEnv("SHIPMENT_DISPATCHED_TEMPLATE_ID", ""),
-		DeliveryETATemplateID:          getEnv("DELIVERY_ETA_TEMPLATE_ID", ""),
-		DeliveryConfirmationTemplateID: getEnv("DELIVERY_CONFIRMATION_TEMPLATE_ID", ""),
-		DelayNotificationTemplateID:    getEnv("DELAY_NOTIFICATION_TEMPLATE_ID", ""),
+		KafkaTopicPartitions:        l.getEnvAsInt("KAFKA_TOPIC_PARTITIONS", 0),
+		KafkaTopicReplicationFactor: l.getEnvAsInt("KAFKA_TOPIC_REPLICATION_FACTOR", 0),
+		KafkaTopicLayouts:           l.getEnvAsMap("KAFKA_TOPIC_LAYOUTS"),
+
+		SecretsProvider:        l.getEnv("SECRETS_PROVIDER", "env"),
+		SecretsRefreshInterval: l.getEnvAsDuration("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+		VaultAddr:              l.getEnv("VAULT_ADDR", ""),
+		VaultToken:             l.getEnv("VAULT_TOKEN", ""),
+		VaultSecretPath:        l.getEnv("VAULT_SECRET_PATH", ""),
+		AWSRegion:              l.getEnv("AWS_REGION", ""),
+		AWSSecretID:            l.getEnv("AWS_SECRET_ID", ""),
+
+		RedisURL: l.getEnv("REDIS_URL", ""),
+
+		ExternalIDCache:     l.getEnv("EXTERNAL_ID_CACHE", "memory"),
+		ExternalIDCacheSize: l.getEnvAsInt("EXTERNAL_ID_CACHE_SIZE", 100000),
+		ExternalIDCacheTTL:  l.getEnvAsDuration("EXTERNAL_ID_CACHE_TTL", 72*time.Hour),
+
+		WebhookMaxEventAge:    l.getEnvAsDuration("WEBHOOK_MAX_EVENT_AGE", 7*24*time.Hour),
+		WebhookReplayAction:   l.getEnv("WEBHOOK_REPLAY_ACTION", "reject"),
+		WebhookNonceStore:     l.getEnv("WEBHOOK_NONCE_STORE", "memory"),
+		WebhookNonceCacheSize: l.getEnvAsInt("WEBHOOK_NONCE_CACHE_SIZE", 100000),
+
+		WebhookFailureReplayInterval: l.getEnvAsDuration("WEBHOOK_FAILURE_REPLAY_INTERVAL", time.Minute),
+		WebhookFailureMaxAttempts:    l.getEnvAsInt("WEBHOOK_FAILURE_MAX_ATTEMPTS", 10),
+		WebhookFailureRetention:      l.getEnvAsDuration("WEBHOOK_FAILURE_RETENTION", 30*24*time.Hour),
+
+		WebhookQuarantineWindow:   l.getEnvAsDuration("WEBHOOK_QUARANTINE_WINDOW", 15*time.Minute),
+		WebhookQuarantineInterval: l.getEnvAsDuration("WEBHOOK_QUARANTINE_INTERVAL", 5*time.Second),
+		WebhookLookupWait:         l.getEnvAsDuration("WEBHOOK_LOOKUP_WAIT", 200*time.Millisecond),
+
+		MaintenanceMode:      l.getEnvAsBool("MAINTENANCE_MODE", false),
+		PauseRefreshInterval: l.getEnvAsDuration("PAUSE_REFRESH_INTERVAL", 5*time.Second),
+
+		TemplateRefreshInterval:    l.getEnvAsDuration("TEMPLATE_REFRESH_INTERVAL", 5*time.Second),
+		TemplateMaxFailureRate:     l.getEnvAsFloat("TEMPLATE_MAX_FAILURE_RATE", 0),
+		TemplateFailureWindow:      l.getEnvAsDuration("TEMPLATE_FAILURE_WINDOW", 5*time.Minute),
+		TemplateFailureMinMessages: l.getEnvAsInt("TEMPLATE_FAILURE_MIN_MESSAGES", 20),
+		TemplateSafeMode:           l.getEnvAsBool("TEMPLATE_SAFE_MODE", true),
+
+		StatusTransitionMetrics: l.getEnvAsBool("STATUS_TRANSITION_METRICS", false),
+
+		DuplicateWindow: l.getEnvAsDuration("DUPLICATE_SUPPRESSION_WINDOW", 0),
+
+		QualityRedRateFactor:   l.getEnvAsFloat("QUALITY_RED_RATE_FACTOR", 0.5),
+		QualityRefreshInterval: l.getEnvAsDuration("QUALITY_REFRESH_INTERVAL", time.Minute),
+
+		CountryAllowlist:       l.getEnvAsList("COUNTRY_ALLOWLIST"),
+		CountryBlocklist:       l.getEnvAsList("COUNTRY_BLOCKLIST"),
+		CountryRefreshInterval: l.getEnvAsDuration("COUNTRY_REFRESH_INTERVAL", 5*time.Second),
+
+		QuietHours:                l.getEnv("QUIET_HOURS", ""),
+		QuietHoursTenants:         l.getEnvAsMap("QUIET_HOURS_TENANTS"),
+		MarketingTemplates:        l.getEnvAsList("MARKETING_TEMPLATES"),
+		QuietHoursReleaseInterval: l.getEnvAsDuration("QUIET_HOURS_RELEASE_INTERVAL", time.Minute),
+
+		OptInRequired: l.getEnvAsBool("OPT_IN_REQUIRED", false),
+
+		CampaignDispatchInterval: l.getEnvAsDuration("CAMPAIGN_DISPATCH_INTERVAL", 5*time.Second),
+		CampaignDispatchBatch:    l.getEnvAsInt("CAMPAIGN_DISPATCH_BATCH", 100),
+
+		HandoffChannel:         l.getEnv("HANDOFF_CHANNEL", ""),
+		HandoffTopic:           l.getEnv("HANDOFF_TOPIC", "whatsapp-handoffs"),
+		HandoffWebhookURL:      l.getEnv("HANDOFF_WEBHOOK_URL", ""),
+		HandoffWebhookToken:    l.getEnv("HANDOFF_WEBHOOK_TOKEN", ""),
+		HandoffContextMessages: l.getEnvAsInt("HANDOFF_CONTEXT_MESSAGES", 10),
+
+		InboundHandlerTimeout: l.getEnvAsDuration("INBOUND_HANDLER_TIMEOUT", 5*time.Second),
+		InboundTypingDelay:    l.getEnvAsDuration("INBOUND_TYPING_DELAY", time.Second),
+
+		QuotaTenantMonthly:   l.getEnvAsInt("QUOTA_TENANT_MONTHLY", 0),
+		QuotaCustomerMonthly: l.getEnvAsInt("QUOTA_CUSTOMER_MONTHLY", 0),
+		QuotaTenants:         l.getEnvAsMap("QUOTA_TENANTS"),
+		QuotaCustomers:       l.getEnvAsMap("QUOTA_CUSTOMERS"),
+		QuotaExceededAction:  l.getEnv("QUOTA_EXCEEDED_ACTION", "reject"),
+
+		RateLimitDefault: l.getEnv("RATE_LIMIT_DEFAULT", "50:100"),
+		RateLimitRoutes:  l.getEnvAsMap("RATE_LIMIT_ROUTES"),
+		RateLimitAPIKeys: l.getEnvAsMap("RATE_LIMIT_API_KEYS"),
+
+		GRPCAPIKeys: l.getEnvAsMap("GRPC_API_KEYS"),
+
+		GRPCRateLimitDefault:     l.getEnv("GRPC_RATE_LIMIT_DEFAULT", ""),
+		GRPCRateLimits:           l.getEnvAsMap("GRPC_RATE_LIMITS"),
+		GRPCDailyMessagesDefault: l.getEnvAsInt("GRPC_DAILY_MESSAGES_DEFAULT", 0),
+		GRPCDailyMessages:        l.getEnvAsMap("GRPC_DAILY_MESSAGES"),
+
+		PhoneHashKey: l.getEnv("PHONE_HASH_KEY", ""),
+
+		JWTSecret:     l.getEnv("JWT_SECRET", "your-secret-key"),
+		JWTExpiration: l.getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+
+		OrderConfirmationTemplateID:    l.getEnv("ORDER_CONFIRMATION_TEMPLATE_ID", ""),
+		ShipmentDispatchedTemplateID:   l.getEnv("SHIPMENT_DISPATCHED_TEMPLATE_ID", ""),
+		DeliveryETATemplateID:          l.getEnv("DELIVERY_ETA_TEMPLATE_ID", ""),
+		DeliveryConfirmationTemplateID: l.getEnv("DELIVERY_CONFIRMATION_TEMPLATE_ID", ""),
+		DelayNotificationTemplateID:    l.getEnv("DELAY_NOTIFICATION_TEMPLATE_ID", ""),
+	}
+
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadSecrets(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = hostname()
 	}
 
-	// Validate required configuration
-	if cfg.DatabaseURL == "" {
-		return nil, errors.New("DATABASE_URL is required")
+	if cfg.WhatsAppProvider == "mock" || cfg.CanaryProvider == "mock" || cfg.FailoverProvider == "mock" {
+		if cfg.MetaPhoneNumberID == "" {
+			cfg.MetaPhoneNumberID = "mock-phone-number-id"
+		}
+		if cfg.MockWebhookURL == "" {
+			cfg.MockWebhookURL = "http://localhost:" + cfg.HTTPPort + "/webhook"
+		}
 	}
 
-	if cfg.MetaPhoneNumberID == "" || cfg.MetaAccessToken == "" {
-		return nil, errors.New("META_PHONE_NUMBER_ID and META_ACCESS_TOKEN are required")
+	if _, ok := cfg.PhoneNumberTenants[cfg.MetaPhoneNumberID]; !ok && cfg.MetaPhoneNumberID != "" {
+		cfg.PhoneNumberTenants[cfg.MetaPhoneNumberID] = "default"
+	}
+	if _, ok := cfg.WABATenants[cfg.MetaWABAID]; !ok && cfg.MetaWABAID != "" {
+		cfg.WABATenants[cfg.MetaWABAID] = "default"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
-// Helper functions to read environment variables
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
+// Helper functions to read settings through the loader's sources
+// hostname is the default instance ID
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
+
+func (l *loader) getEnv(key, defaultValue string) string {
+	if value, exists := l.lookup(key); exists {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value, exists := os.LookupEnv(key); exists {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+func (l *loader) getEnvAsInt(key string, defaultValue int) int {
+	if value, exists := l.lookup(key); exists {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			l.invalid(key, value, "an integer")
+			return defaultValue
+		}
+		return intValue
+	}
+	return defaultValue
+}
+
+func (l *loader) getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := l.lookup(key); exists {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			l.invalid(key, value, "true or false")
+			return defaultValue
+		}
+		return boolValue
+	}
+	return defaultValue
+}
+
+func (l *loader) getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := l.lookup(key); exists {
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.invalid(key, value, "a number")
+			return defaultValue
 		}
+		return floatValue
 	}
 	return defaultValue
 }
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value, exists := os.LookupEnv(key); exists {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// getEnvAsMap parses a comma-separated list of key=value pairs
+func (l *loader) getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	value, exists := l.lookup(key)
+	if !exists || value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || k == "" {
+			l.invalid(key, pair, "a key=value pair")
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// getEnvAsList parses a comma-separated list, dropping empty items
+func (l *loader) getEnvAsList(key string) []string {
+	value, exists := l.lookup(key)
+	if !exists {
+		return nil
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvAsDurationList parses a comma-separated list of durations
+func (l *loader) getEnvAsDurationList(key string) []time.Duration {
+	var result []time.Duration
+	for _, item := range l.getEnvAsList(key) {
+		duration, err := time.ParseDuration(item)
+		if err != nil {
+			l.invalid(key, item, "a duration such as 500ms or 5m")
+			continue
+		}
+		result = append(result, duration)
+	}
+	return result
+}
+
+func (l *loader) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := l.lookup(key); exists {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			l.invalid(key, value, "a duration such as 500ms or 5m")
+			return defaultValue
 		}
+		return duration
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
@@ -0,0 +1,97 @@
+// config/secrets.go
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"messaging-microservice/pkg/secrets"
+)
+
+// Keys looked up in the external secret
+const (
+	SecretMetaAccessToken  = "META_ACCESS_TOKEN"
+	SecretMetaAppSecret    = "META_APP_SECRET"
+	SecretTwilioAuthToken  = "TWILIO_AUTH_TOKEN"
+	SecretJWTSecret        = "JWT_SECRET"
+	SecretDatabasePassword = "DATABASE_PASSWORD"
+)
+
+// loadSecrets fetches secrets from the configured provider and overrides the matching fields
+func loadSecrets(cfg *Config) error {
+	var provider secrets.Provider
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	switch cfg.SecretsProvider {
+	case "", "env":
+		return nil
+	case "vault":
+		if cfg.VaultAddr == "" || cfg.VaultSecretPath == "" {
+			return fmt.Errorf("VAULT_ADDR and VAULT_SECRET_PATH are required when SECRETS_PROVIDER is vault")
+		}
+		provider = secrets.NewVaultProvider(cfg.VaultAddr, cfg.VaultToken, cfg.VaultSecretPath)
+	case "aws":
+		if cfg.AWSSecretID == "" {
+			return fmt.Errorf("AWS_SECRET_ID is required when SECRETS_PROVIDER is aws")
+		}
+		var err error
+		if provider, err = secrets.NewAWSProvider(ctx, cfg.AWSRegion, cfg.AWSSecretID); err != nil {
+			return fmt.Errorf("failed to configure AWS Secrets Manager: %w", err)
+		}
+	default:
+		return fmt.Errorf("SECRETS_PROVIDER must be one of: env, vault, aws")
+	}
+
+	store := secrets.NewStore(provider, cfg.SecretsRefreshInterval)
+	if err := store.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load secrets from %s: %w", cfg.SecretsProvider, err)
+	}
+	cfg.Secrets = store
+
+	if value, ok := store.Get(SecretMetaAccessToken); ok {
+		cfg.MetaAccessToken = value
+	}
+	if value, ok := store.Get(SecretMetaAppSecret); ok {
+		cfg.MetaAppSecret = value
+	}
+	if value, ok := store.Get(SecretTwilioAuthToken); ok {
+		cfg.TwilioAuthToken = value
+	}
+	if value, ok := store.Get(SecretJWTSecret); ok {
+		cfg.JWTSecret = value
+	}
+	return nil
+}
+
+// DatabaseDSN returns DatabaseURL with the current database password from the secret store,
+// if it holds one. It is evaluated per connection so rotated passwords are picked up.
+func (c *Config) DatabaseDSN() string {
+	return c.withDatabasePassword(c.DatabaseURL)
+}
+
+// DatabaseReadDSN returns DatabaseReadURL with the current database password, like DatabaseDSN
+func (c *Config) DatabaseReadDSN() string {
+	return c.withDatabasePassword(c.DatabaseReadURL)
+}
+
+// withDatabasePassword replaces the password in a Postgres URL with the secret store's
+func (c *Config) withDatabasePassword(rawURL string) string {
+	if c.Secrets == nil {
+		return rawURL
+	}
+	password, ok := c.Secrets.Get(SecretDatabasePassword)
+	if !ok {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+	return u.String()
+}
@@ -0,0 +1,159 @@
+// config/sources.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loader resolves each setting from command-line flags, then environment variables,
+// then the YAML config file, and collects every malformed or unknown setting
+type loader struct {
+	flags        map[string]string
+	file         map[string]string
+	fileName     string
+	validateOnly bool
+	used         map[string]bool
+	errs         []error
+}
+
+// newLoader parses args and reads the config file they (or CONFIG_FILE) point to
+func newLoader(args []string) (*loader, error) {
+	l := &loader{
+		flags: make(map[string]string),
+		file:  make(map[string]string),
+		used:  make(map[string]bool),
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return nil, fmt.Errorf("unexpected argument %q", arg)
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "validate-config" {
+			l.validateOnly = !hasValue || value == "true"
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag --%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if name == "config" {
+			l.fileName = value
+			continue
+		}
+		l.flags[settingKey(name)] = value
+	}
+
+	if l.fileName == "" {
+		l.fileName = os.Getenv("CONFIG_FILE")
+	}
+	if l.fileName != "" {
+		if err := l.readFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// settingKey converts a flag or YAML key such as http-port to its variable name HTTP_PORT
+func settingKey(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// readFile loads a flat YAML mapping of settings. Keys may be written as env names
+// (HTTP_PORT) or in lower case (http_port); lists are joined with commas and nested
+// mappings become key=value pairs.
+func (l *loader) readFile() error {
+	data, err := os.ReadFile(l.fileName)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", l.fileName, err)
+	}
+
+	for key, value := range raw {
+		switch v := value.(type) {
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			l.file[settingKey(key)] = strings.Join(items, ",")
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			pairs := make([]string, 0, len(v))
+			for _, k := range keys {
+				pairs = append(pairs, k+"="+fmt.Sprint(v[k]))
+			}
+			l.file[settingKey(key)] = strings.Join(pairs, ",")
+		case nil:
+			l.file[settingKey(key)] = ""
+		default:
+			l.file[settingKey(key)] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// lookup returns the highest-precedence value of a setting
+func (l *loader) lookup(key string) (string, bool) {
+	l.used[key] = true
+	if value, ok := l.flags[key]; ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	value, ok := l.file[key]
+	return value, ok
+}
+
+// invalid records a setting that could not be parsed
+func (l *loader) invalid(key, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %q is not %s", key, value, want))
+}
+
+// err reports malformed settings and flags or file keys that match no setting
+func (l *loader) err() error {
+	errs := l.errs
+	for _, key := range sortedKeys(l.flags) {
+		if !l.used[key] {
+			errs = append(errs, fmt.Errorf("unknown flag --%s", strings.ToLower(strings.ReplaceAll(key, "_", "-"))))
+		}
+	}
+	for _, key := range sortedKeys(l.file) {
+		if !l.used[key] {
+			errs = append(errs, fmt.Errorf("unknown setting %s in %s", key, l.fileName))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
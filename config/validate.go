@@ -0,0 +1,444 @@
+// config/validate.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// Validate checks the whole configuration and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.HTTPPort), "HTTP_PORT must be a port number, got %q", c.HTTPPort)
+	check(validPort(c.GRPCPort), "GRPC_PORT must be a port number, got %q", c.GRPCPort)
+	check(c.HTTPPort != c.GRPCPort, "HTTP_PORT and GRPC_PORT must differ")
+	check(c.ReadTimeout > 0, "READ_TIMEOUT must be positive")
+	check(c.WriteTimeout > 0, "WRITE_TIMEOUT must be positive")
+	check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check(c.StartupRetries > 0, "STARTUP_RETRIES must be positive")
+	check(c.StartupRetryBackoff > 0, "STARTUP_RETRY_BACKOFF must be positive")
+	check(c.StartupRetryMaxBackoff >= c.StartupRetryBackoff, "STARTUP_RETRY_MAX_BACKOFF must not be below STARTUP_RETRY_BACKOFF")
+	check(c.ComponentMaxFailures > 0, "COMPONENT_MAX_FAILURES must be positive")
+	check(c.ComponentRestartBackoff > 0, "COMPONENT_RESTART_BACKOFF must be positive")
+	check(c.ComponentRestartMaxBackoff >= c.ComponentRestartBackoff, "COMPONENT_RESTART_MAX_BACKOFF must not be below COMPONENT_RESTART_BACKOFF")
+	check(c.ComponentStableAfter > 0, "COMPONENT_STABLE_AFTER must be positive")
+	check(utils.IsValidLogLevel(c.LogLevel), "LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	check(c.LogEncoding == "json" || c.LogEncoding == "console", "LOG_ENCODING must be json or console, got %q", c.LogEncoding)
+	if c.LogFile != "" {
+		check(c.LogFileMaxSizeMB > 0, "LOG_FILE_MAX_SIZE_MB must be positive")
+		check(c.LogFileMaxBackups >= 0, "LOG_FILE_MAX_BACKUPS must not be negative")
+		check(c.LogFileMaxAgeDays >= 0, "LOG_FILE_MAX_AGE_DAYS must not be negative")
+	}
+	check(c.LogSampleInitial >= 0, "LOG_SAMPLE_INITIAL must not be negative")
+	check(c.LogSampleThereafter >= 0, "LOG_SAMPLE_THEREAFTER must not be negative")
+	if c.LeaderElection {
+		check(c.LeaderCheckInterval > 0, "LEADER_CHECK_INTERVAL must be positive")
+	}
+
+	check(c.GRPCMaxRecvMsgSize > 0, "GRPC_MAX_RECV_MSG_SIZE must be positive")
+	check(c.GRPCMaxSendMsgSize > 0, "GRPC_MAX_SEND_MSG_SIZE must be positive")
+	check(c.GRPCMaxConcurrentStreams >= 0, "GRPC_MAX_CONCURRENT_STREAMS must not be negative")
+	check(c.GRPCKeepaliveTime > 0, "GRPC_KEEPALIVE_TIME must be positive")
+	check(c.GRPCKeepaliveTimeout > 0, "GRPC_KEEPALIVE_TIMEOUT must be positive")
+	check(c.GRPCKeepaliveMinTime >= 0, "GRPC_KEEPALIVE_MIN_TIME must not be negative")
+	check(c.GRPCMaxConnectionIdle >= 0, "GRPC_MAX_CONNECTION_IDLE must not be negative")
+	check(c.GRPCMaxConnectionAge >= 0, "GRPC_MAX_CONNECTION_AGE must not be negative")
+	check(c.GRPCMaxConnectionAgeGrace >= 0, "GRPC_MAX_CONNECTION_AGE_GRACE must not be negative")
+
+	check(c.SendMaxInFlight > 0, "SEND_MAX_IN_FLIGHT must be positive")
+	check(c.SendMaxQueued >= 0, "SEND_MAX_QUEUED must not be negative")
+	check(c.SendQueueTimeout >= 0, "SEND_QUEUE_TIMEOUT must not be negative")
+
+	check(c.DatabaseURL != "", "DATABASE_URL is required")
+	check(c.DatabaseMaxOpenConns > 0, "DATABASE_MAX_OPEN_CONNS must be positive")
+	check(c.DatabaseMaxIdleConns >= 0 && c.DatabaseMaxIdleConns <= c.DatabaseMaxOpenConns,
+		"DATABASE_MAX_IDLE_CONNS must be between 0 and DATABASE_MAX_OPEN_CONNS")
+	check(c.DatabaseConnMaxLifetime >= 0, "DATABASE_CONN_MAX_LIFETIME must not be negative")
+	check(c.DatabaseConnMaxIdleTime >= 0, "DATABASE_CONN_MAX_IDLE_TIME must not be negative")
+	if c.DatabaseReadURL != "" {
+		check(c.DatabaseReplicaMaxLag > 0, "DATABASE_REPLICA_MAX_LAG must be positive")
+		check(c.DatabaseReplicaCheckInterval > 0, "DATABASE_REPLICA_CHECK_INTERVAL must be positive")
+	}
+	check(c.DatabaseDriver == "pq" || c.DatabaseDriver == "pgx", "DATABASE_DRIVER must be one of: pq, pgx")
+	if c.DatabaseDriver == "pgx" {
+		check(c.DatabaseStatementCacheSize > 0, "DATABASE_STATEMENT_CACHE_SIZE must be positive")
+	}
+
+	check(c.RetentionMessageDays >= 0, "RETENTION_MESSAGE_DAYS must not be negative")
+	check(c.RetentionWebhookEventDays >= 0, "RETENTION_WEBHOOK_EVENT_DAYS must not be negative")
+	check(c.RetentionInterval > 0, "RETENTION_INTERVAL must be positive")
+	check(c.RetentionBatchSize > 0, "RETENTION_BATCH_SIZE must be positive")
+	check(c.MessagePartitionsAhead >= 0, "MESSAGE_PARTITIONS_AHEAD must not be negative")
+
+	check(c.ArchiveAfterDays >= 0, "ARCHIVE_AFTER_DAYS must not be negative")
+	check(c.RetentionMessageDays == 0 || c.ArchiveAfterDays < c.RetentionMessageDays,
+		"ARCHIVE_AFTER_DAYS must be less than RETENTION_MESSAGE_DAYS")
+	if c.ArchiveAfterDays > 0 {
+		check(c.ArchiveInterval > 0, "ARCHIVE_INTERVAL must be positive")
+		check(c.ArchiveBatchSize > 0, "ARCHIVE_BATCH_SIZE must be positive")
+		switch c.ArchiveStore {
+		case "s3":
+			check(c.ArchiveBucket != "", "ARCHIVE_BUCKET is required when ARCHIVE_STORE is s3")
+		case "file":
+			check(c.ArchiveDir != "", "ARCHIVE_DIR is required when ARCHIVE_STORE is file")
+		default:
+			errs = append(errs, errors.New("ARCHIVE_STORE must be one of: s3, file"))
+		}
+	}
+
+	switch c.ExternalIDCache {
+	case "none":
+	case "memory":
+		check(c.ExternalIDCacheSize > 0, "EXTERNAL_ID_CACHE_SIZE must be positive")
+		check(c.ExternalIDCacheTTL > 0, "EXTERNAL_ID_CACHE_TTL must be positive")
+	case "redis":
+		check(c.RedisURL != "", "REDIS_URL is required when EXTERNAL_ID_CACHE is redis")
+		check(c.ExternalIDCacheTTL > 0, "EXTERNAL_ID_CACHE_TTL must be positive")
+	default:
+		errs = append(errs, errors.New("EXTERNAL_ID_CACHE must be one of: memory, redis, none"))
+	}
+
+	check(c.WebhookMaxEventAge > 0, "WEBHOOK_MAX_EVENT_AGE must be positive")
+	check(c.WebhookReplayAction == "reject" || c.WebhookReplayAction == "flag", "WEBHOOK_REPLAY_ACTION must be one of: reject, flag")
+	switch c.WebhookNonceStore {
+	case "none":
+	case "memory":
+		check(c.WebhookNonceCacheSize > 0, "WEBHOOK_NONCE_CACHE_SIZE must be positive")
+	case "redis":
+		check(c.RedisURL != "", "REDIS_URL is required when WEBHOOK_NONCE_STORE is redis")
+	default:
+		errs = append(errs, errors.New("WEBHOOK_NONCE_STORE must be one of: memory, redis, none"))
+	}
+	check(c.WebhookFailureReplayInterval > 0, "WEBHOOK_FAILURE_REPLAY_INTERVAL must be positive")
+	check(c.WebhookFailureMaxAttempts > 0, "WEBHOOK_FAILURE_MAX_ATTEMPTS must be positive")
+	check(c.WebhookFailureRetention > 0, "WEBHOOK_FAILURE_RETENTION must be positive")
+	check(c.WebhookQuarantineWindow >= 0, "WEBHOOK_QUARANTINE_WINDOW must not be negative")
+	if c.WebhookQuarantineWindow > 0 {
+		check(c.WebhookQuarantineInterval > 0, "WEBHOOK_QUARANTINE_INTERVAL must be positive")
+	}
+	check(c.WebhookLookupWait >= 0 && c.WebhookLookupWait < c.WriteTimeout, "WEBHOOK_LOOKUP_WAIT must not be negative and must be shorter than WRITE_TIMEOUT")
+
+	if c.MessageOutbox {
+		check(c.OutboxRelayInterval > 0, "OUTBOX_RELAY_INTERVAL must be positive")
+		check(c.OutboxRelayGrace >= 0, "OUTBOX_RELAY_GRACE must not be negative")
+	}
+	check(c.OutboxRetention > 0, "OUTBOX_RETENTION must be positive")
+
+	check(c.PauseRefreshInterval > 0, "PAUSE_REFRESH_INTERVAL must be positive")
+	check(c.TemplateRefreshInterval > 0, "TEMPLATE_REFRESH_INTERVAL must be positive")
+	check(c.TemplateMaxFailureRate >= 0 && c.TemplateMaxFailureRate < 1, "TEMPLATE_MAX_FAILURE_RATE must be at least 0 and below 1")
+	check(c.TemplateFailureWindow >= 10*time.Second, "TEMPLATE_FAILURE_WINDOW must be at least 10s")
+	check(c.TemplateFailureMinMessages > 0, "TEMPLATE_FAILURE_MIN_MESSAGES must be positive")
+	check(c.DuplicateWindow >= 0, "DUPLICATE_SUPPRESSION_WINDOW must not be negative")
+	check(c.QualityRedRateFactor > 0 && c.QualityRedRateFactor <= 1, "QUALITY_RED_RATE_FACTOR must be in (0, 1]")
+	check(c.QualityRefreshInterval > 0, "QUALITY_REFRESH_INTERVAL must be positive")
+	check(c.CountryRefreshInterval > 0, "COUNTRY_REFRESH_INTERVAL must be positive")
+	countryLists := map[string][]string{"COUNTRY_ALLOWLIST": c.CountryAllowlist, "COUNTRY_BLOCKLIST": c.CountryBlocklist}
+	seenPrefixes := make(map[string]bool)
+	for _, key := range []string{"COUNTRY_ALLOWLIST", "COUNTRY_BLOCKLIST"} {
+		for _, prefix := range countryLists[key] {
+			check(validCountryPrefix(prefix), "%s: %q is not a dialing prefix of 1 to 6 digits", key, prefix)
+			check(!seenPrefixes[prefix], "%s: prefix %s is listed more than once", key, prefix)
+			seenPrefixes[prefix] = true
+		}
+	}
+
+	check(c.QuietHoursReleaseInterval > 0, "QUIET_HOURS_RELEASE_INTERVAL must be positive")
+	if c.QuietHours != "" {
+		_, err := domain.ParseQuietHours(c.QuietHours)
+		check(err == nil, "QUIET_HOURS: %v", err)
+	}
+	for _, tenant := range sortedKeys(c.QuietHoursTenants) {
+		if window := c.QuietHoursTenants[tenant]; window != "off" {
+			_, err := domain.ParseQuietHours(window)
+			check(err == nil, "QUIET_HOURS_TENANTS: tenant %s: %v", tenant, err)
+		}
+	}
+
+	check(!c.OptInRequired || len(c.MarketingTemplates) > 0, "MARKETING_TEMPLATES is required when OPT_IN_REQUIRED is on")
+	check(c.CampaignDispatchInterval > 0, "CAMPAIGN_DISPATCH_INTERVAL must be positive")
+	check(c.CampaignDispatchBatch > 0, "CAMPAIGN_DISPATCH_BATCH must be positive")
+
+	switch c.HandoffChannel {
+	case "":
+	case "kafka":
+		check(c.HandoffTopic != "", "HANDOFF_TOPIC is required when HANDOFF_CHANNEL is kafka")
+	case "webhook":
+		u, err := url.Parse(c.HandoffWebhookURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "HANDOFF_WEBHOOK_URL must be an http or https URL when HANDOFF_CHANNEL is webhook")
+	default:
+		errs = append(errs, fmt.Errorf("HANDOFF_CHANNEL must be one of: kafka, webhook"))
+	}
+	check(c.HandoffContextMessages >= 0, "HANDOFF_CONTEXT_MESSAGES must not be negative")
+	check(c.InboundHandlerTimeout >= 0, "INBOUND_HANDLER_TIMEOUT must not be negative")
+	check(c.InboundTypingDelay >= 0, "INBOUND_TYPING_DELAY must not be negative")
+
+	check(c.QuotaTenantMonthly >= 0, "QUOTA_TENANT_MONTHLY must not be negative")
+	check(c.QuotaCustomerMonthly >= 0, "QUOTA_CUSTOMER_MONTHLY must not be negative")
+	for key, limit := range c.QuotaTenants {
+		check(validQuota(limit), "QUOTA_TENANTS: tenant %s has invalid limit %q", key, limit)
+	}
+	for key, limit := range c.QuotaCustomers {
+		check(validQuota(limit), "QUOTA_CUSTOMERS: customer %s has invalid limit %q", key, limit)
+	}
+	check(c.QuotaExceededAction == "reject" || c.QuotaExceededAction == "record",
+		"QUOTA_EXCEEDED_ACTION must be one of: reject, record")
+
+	for _, credential := range c.GRPCAPIKeys {
+		tenant, caller, ok := strings.Cut(credential, ":")
+		check(ok && tenant != "" && caller != "", "GRPC_API_KEYS: invalid credential %q, must be written as tenant:caller", credential)
+	}
+	if c.GRPCRateLimitDefault != "" {
+		_, err := utils.ParseRateLimit(c.GRPCRateLimitDefault)
+		check(err == nil, "GRPC_RATE_LIMIT_DEFAULT must be written as rps:burst")
+	}
+	for _, limit := range c.GRPCRateLimits {
+		_, err := utils.ParseRateLimit(limit)
+		check(err == nil, "GRPC_RATE_LIMITS: invalid limit %q, must be written as rps:burst", limit)
+	}
+	check(c.GRPCDailyMessagesDefault >= 0, "GRPC_DAILY_MESSAGES_DEFAULT must not be negative")
+	for key, limit := range c.GRPCDailyMessages {
+		check(validQuota(limit), "GRPC_DAILY_MESSAGES: caller %s has invalid limit %q", key, limit)
+	}
+
+	providers := map[string]string{"WHATSAPP_PROVIDER": c.WhatsAppProvider}
+	if c.CanaryProvider != "" {
+		providers["CANARY_PROVIDER"] = c.CanaryProvider
+		check(c.CanaryProvider != c.WhatsAppProvider, "CANARY_PROVIDER must differ from WHATSAPP_PROVIDER")
+		check(c.CanaryPercent >= 0 && c.CanaryPercent <= 100, "CANARY_PERCENT must be between 0 and 100")
+		check(c.CanaryMaxFailureRate >= 0 && c.CanaryMaxFailureRate <= 1, "CANARY_MAX_FAILURE_RATE must be between 0 and 1")
+		check(c.CanaryWindow > 0, "CANARY_WINDOW must be positive")
+		check(c.CanaryMinSamples > 0 && c.CanaryMinSamples <= c.CanaryWindow, "CANARY_MIN_SAMPLES must be between 1 and CANARY_WINDOW")
+		check(c.CanaryCooldown > 0, "CANARY_COOLDOWN must be positive")
+	}
+	check(c.ProviderBreakerFailures >= 0, "PROVIDER_BREAKER_FAILURES must not be negative")
+	check(c.ProviderBreakerCooldown > 0, "PROVIDER_BREAKER_COOLDOWN must be positive")
+	check(c.ProviderTimeoutCeiling > 0, "PROVIDER_TIMEOUT_CEILING must be positive")
+	check(c.ProviderTimeoutFloor >= 0 && c.ProviderTimeoutFloor <= c.ProviderTimeoutCeiling,
+		"PROVIDER_TIMEOUT_FLOOR must be between 0 and PROVIDER_TIMEOUT_CEILING")
+	check(c.ProviderHTTPAttempts > 0, "PROVIDER_HTTP_ATTEMPTS must be positive")
+	check(c.ProviderHTTPAttemptTimeout >= 0, "PROVIDER_HTTP_ATTEMPT_TIMEOUT must not be negative")
+	check(c.ProviderHTTPRetryBackoff > 0, "PROVIDER_HTTP_RETRY_BACKOFF must be positive")
+	check(c.ProviderHTTPRetryMaxBackoff >= c.ProviderHTTPRetryBackoff, "PROVIDER_HTTP_RETRY_MAX_BACKOFF must not be below PROVIDER_HTTP_RETRY_BACKOFF")
+	if c.ProviderHTTPSProxy != "" {
+		u, err := url.Parse(c.ProviderHTTPSProxy)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "socks5") && u.Host != "", "PROVIDER_HTTPS_PROXY must be an http, https or socks5 URL")
+	}
+	if c.ProviderCABundle != "" {
+		_, err := utils.LoadCertPool(c.ProviderCABundle)
+		check(err == nil, "PROVIDER_CA_BUNDLE: %v", err)
+	}
+	check(c.ProviderCaptureMaxBody > 0, "PROVIDER_CAPTURE_MAX_BODY must be positive")
+	check(c.ProviderCaptureRetention > 0, "PROVIDER_CAPTURE_RETENTION must be positive")
+	if c.ProviderSendRate != "" {
+		_, err := utils.ParseRateLimit(c.ProviderSendRate)
+		check(err == nil, "PROVIDER_SEND_RATE must be written as rps:burst")
+	}
+	if c.FailoverProvider != "" {
+		providers["FAILOVER_PROVIDER"] = c.FailoverProvider
+		check(c.FailoverProvider != c.WhatsAppProvider, "FAILOVER_PROVIDER must differ from WHATSAPP_PROVIDER")
+		check(c.FailoverMaxErrorRate >= 0 && c.FailoverMaxErrorRate <= 1, "FAILOVER_MAX_ERROR_RATE must be between 0 and 1")
+		check(c.FailoverMaxLatency >= 0, "FAILOVER_MAX_LATENCY must not be negative")
+		check(c.FailoverMaxErrorRate > 0 || c.FailoverMaxLatency > 0, "one of FAILOVER_MAX_ERROR_RATE and FAILOVER_MAX_LATENCY must be set")
+		check(c.FailoverWindow > 0, "FAILOVER_WINDOW must be positive")
+		check(c.FailoverMinSamples > 0 && c.FailoverMinSamples <= c.FailoverWindow, "FAILOVER_MIN_SAMPLES must be between 1 and FAILOVER_WINDOW")
+		check(c.FailoverProbeInterval > 0, "FAILOVER_PROBE_INTERVAL must be positive")
+		check(c.FailoverRecoveryProbes > 0, "FAILOVER_RECOVERY_PROBES must be positive")
+	}
+	for _, key := range sortedKeys(providers) {
+		switch providers[key] {
+		case "meta":
+			check(c.MetaPhoneNumberID != "" && c.MetaAccessToken != "", "META_PHONE_NUMBER_ID and META_ACCESS_TOKEN are required")
+			check(c.MetaTokenCheckInterval > 0, "META_TOKEN_CHECK_INTERVAL must be positive")
+			check(meta.ValidAPIVersion(c.MetaAPIVersion), "META_API_VERSION must be a Graph API version such as %s, got %q", meta.DefaultAPIVersion, c.MetaAPIVersion)
+			check(c.MetaAPIVersionWarnBefore >= 0, "META_API_VERSION_WARN_BEFORE must not be negative")
+		case "twilio":
+			check(c.TwilioAccountSID != "" && c.TwilioAuthToken != "", "TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN are required")
+			check(c.TwilioFrom != "" || c.TwilioMessagingServiceSID != "", "one of TWILIO_FROM and TWILIO_MESSAGING_SERVICE_SID is required")
+			for _, template := range sortedKeys(c.TwilioContentSIDs) {
+				check(strings.HasPrefix(c.TwilioContentSIDs[template], "HX"), "TWILIO_CONTENT_SIDS: template %s has invalid content SID %q", template, c.TwilioContentSIDs[template])
+			}
+			check(c.TwilioDefaultLocale != "", "TWILIO_DEFAULT_LOCALE is required")
+			if c.TwilioCatalogFile != "" {
+				entries, err := twilio.LoadCatalogFile(c.TwilioCatalogFile)
+				if err == nil {
+					err = twilio.NewCatalog(c.TwilioDefaultLocale, render.NewEngine(render.Options{Safe: c.TemplateSafeMode})).Replace(entries)
+				}
+				check(err == nil, "TWILIO_CATALOG_FILE is invalid: %v", err)
+			}
+		case "mock":
+			check(c.MockFailureRate >= 0 && c.MockFailureRate <= 1, "MOCK_FAILURE_RATE must be between 0 and 1")
+		default:
+			errs = append(errs, fmt.Errorf("%s must be one of: meta, twilio, mock", key))
+		}
+	}
+
+	check(len(c.KafkaBrokers) > 0 && c.KafkaBrokers[0] != "", "KAFKA_BROKERS is required")
+	check(c.KafkaTopic != "", "KAFKA_TOPIC is required")
+	check(c.KafkaStatusTopic != "", "KAFKA_STATUS_TOPIC is required")
+	check(c.KafkaTopic != c.KafkaStatusTopic, "KAFKA_TOPIC and KAFKA_STATUS_TOPIC must differ")
+	check(c.KafkaGroupID != "", "KAFKA_GROUP_ID is required")
+	check(c.KafkaBatchSize > 0, "KAFKA_BATCH_SIZE must be positive")
+	check(c.KafkaBatchBytes > 0, "KAFKA_BATCH_BYTES must be positive")
+	check(c.KafkaBatchTimeout > 0, "KAFKA_BATCH_TIMEOUT must be positive")
+	check(queue.ValidCompression(c.KafkaCompression), "KAFKA_COMPRESSION must be one of: none, gzip, snappy, lz4, zstd")
+	check(c.KafkaProduceTimeoutCeiling > 0, "KAFKA_PRODUCE_TIMEOUT_CEILING must be positive")
+	check(c.KafkaProduceTimeoutFloor >= 0 && c.KafkaProduceTimeoutFloor <= c.KafkaProduceTimeoutCeiling,
+		"KAFKA_PRODUCE_TIMEOUT_FLOOR must be between 0 and KAFKA_PRODUCE_TIMEOUT_CEILING")
+	retryDelaysOK := true
+	for i, delay := range c.KafkaRetryDelays {
+		if delay < time.Second || (i > 0 && delay <= c.KafkaRetryDelays[i-1]) {
+			retryDelaysOK = false
+		}
+	}
+	check(c.KafkaTopicPartitions >= 0, "KAFKA_TOPIC_PARTITIONS must not be negative")
+	check(c.KafkaTopicReplicationFactor >= 0, "KAFKA_TOPIC_REPLICATION_FACTOR must not be negative")
+	for _, topic := range sortedKeys(c.KafkaTopicLayouts) {
+		_, _, err := queue.ParseTopicLayout(c.KafkaTopicLayouts[topic])
+		check(err == nil, "KAFKA_TOPIC_LAYOUTS: %s: %v", topic, err)
+	}
+	check(retryDelaysOK, "KAFKA_RETRY_DELAYS must be increasing delays of at least 1s")
+	check(c.ConsumerMaxLag >= 0, "CONSUMER_MAX_LAG must not be negative")
+	check(c.ConsumerMaxFailureRate >= 0 && c.ConsumerMaxFailureRate <= 1, "CONSUMER_MAX_FAILURE_RATE must be between 0 and 1")
+	check(c.ConsumerAlertMinMessages >= 0, "CONSUMER_ALERT_MIN_MESSAGES must not be negative")
+	check(c.ConsumerMonitorInterval > 0, "CONSUMER_MONITOR_INTERVAL must be positive")
+	check(c.AlertEvaluationInterval > 0, "ALERT_EVALUATION_INTERVAL must be positive")
+	check(c.AlertMaxSendFailureRate >= 0 && c.AlertMaxSendFailureRate <= 1, "ALERT_MAX_SEND_FAILURE_RATE must be between 0 and 1")
+	check(c.AlertMinSends >= 0, "ALERT_MIN_SENDS must not be negative")
+	check(c.AlertMaxDLQDepth >= 0, "ALERT_MAX_DLQ_DEPTH must not be negative")
+	check(c.AlertMaxDLQDepth == 0 || c.AlertDLQGroup != "", "ALERT_MAX_DLQ_DEPTH requires ALERT_DLQ_GROUP")
+	check(c.AlertMaxDLQDepth == 0 || len(c.KafkaRetryDelays) > 0, "ALERT_MAX_DLQ_DEPTH requires KAFKA_RETRY_DELAYS, without which there is no dead-letter topic")
+	check(c.AlertMaxSignatureFailures >= 0, "ALERT_MAX_SIGNATURE_FAILURES must not be negative")
+	check(c.AlertTokenExpiryWithin >= 0, "ALERT_TOKEN_EXPIRY_WITHIN must not be negative")
+	if c.AlertSlackWebhookURL != "" {
+		u, err := url.Parse(c.AlertSlackWebhookURL)
+		check(err == nil && u.Scheme == "https" && u.Host != "", "ALERT_SLACK_WEBHOOK_URL must be an https URL")
+	}
+	if c.AlertEmailSMTPAddr != "" {
+		_, port, err := net.SplitHostPort(c.AlertEmailSMTPAddr)
+		check(err == nil && port != "", "ALERT_EMAIL_SMTP_ADDR must be host:port")
+		check(c.AlertEmailFrom != "", "ALERT_EMAIL_SMTP_ADDR requires ALERT_EMAIL_FROM")
+		check(len(c.AlertEmailTo) > 0, "ALERT_EMAIL_SMTP_ADDR requires ALERT_EMAIL_TO")
+	}
+	check(c.AlertEmailPassword == "" || c.AlertEmailUsername != "", "ALERT_EMAIL_PASSWORD requires ALERT_EMAIL_USERNAME")
+	if c.TemplateAlertSlackWebhookURL != "" {
+		u, err := url.Parse(c.TemplateAlertSlackWebhookURL)
+		check(err == nil && u.Scheme == "https" && u.Host != "", "TEMPLATE_ALERT_SLACK_WEBHOOK_URL must be an https URL")
+	}
+	if c.SchemaRegistryURL != "" {
+		u, err := url.Parse(c.SchemaRegistryURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "SCHEMA_REGISTRY_URL must be an http or https URL")
+	}
+	check(c.SchemaRegistryPassword == "" || c.SchemaRegistryUsername != "", "SCHEMA_REGISTRY_PASSWORD requires SCHEMA_REGISTRY_USERNAME")
+
+	for key, tenant := range c.PhoneNumberTenants {
+		check(tenant != "", "META_PHONE_NUMBER_TENANTS: phone number ID %s has no tenant", key)
+	}
+	for key, tenant := range c.WABATenants {
+		check(tenant != "", "META_WABA_TENANTS: business account ID %s has no tenant", key)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validPort reports whether port is a TCP port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+// validQuota reports whether s is a monthly message quota (0 is unlimited)
+func validQuota(s string) bool {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return err == nil && n >= 0
+}
+
+// Redacted renders the effective configuration, one field per line, with secrets masked
+func (c *Config) Redacted() string {
+	var b strings.Builder
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "Secrets" || field.Name == "ValidateOnly" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", field.Name, redactValue(v.Field(i), field.Tag.Get("secret")))
+	}
+	return b.String()
+}
+
+// redactValue formats a field value, masking it according to its secret tag
+func redactValue(v reflect.Value, secret string) string {
+	switch secret {
+	case "true":
+		if v.Len() == 0 {
+			return `""`
+		}
+		return `"[REDACTED]"`
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil {
+			return `"[REDACTED]"`
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "REDACTED")
+		}
+		return strconv.Quote(u.String())
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case string:
+		return strconv.Quote(value)
+	case []string:
+		return "[" + strings.Join(value, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+": "+strconv.Quote(value[key]))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// validCountryPrefix reports whether prefix is 1 to 6 digits without a leading zero
+func validCountryPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 6 || prefix[0] == '0' {
+		return false
+	}
+	for _, r := range prefix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,3 @@
+-- Per-message counter stamped on every published status event so consumers
+-- can order events and discard stale redeliveries
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS status_sequence BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_tenant_external_id;
+ALTER TABLE messages DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,4 @@
+-- Tenant (sender) that owns the message, resolved from the sending phone number ID
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(50) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_external_id ON messages(tenant_id, external_id);
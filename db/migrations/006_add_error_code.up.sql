@@ -0,0 +1,2 @@
+-- Provider error code of the last failure, used to derive the typed error detail
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS error_code VARCHAR(20);
@@ -0,0 +1,2 @@
+-- Exact provider payload (template name, language and rendered components) sent for the message
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS content_snapshot TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE messages DROP COLUMN IF EXISTS erased_at;
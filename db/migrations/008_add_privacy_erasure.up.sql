@@ -0,0 +1,18 @@
+-- Set when a message was anonymized by a data subject erasure request
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP;
+
+-- Append-only record of sensitive operations such as data subject erasures and exports
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    tenant_id VARCHAR(50) NOT NULL,
+    subject_type VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT,
+    affected_rows BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_subject ON audit_log(subject_type, subject);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_unarchived_created_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS archive_key;
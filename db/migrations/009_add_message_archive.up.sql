@@ -0,0 +1,5 @@
+-- Object storage key of the archive holding the full message; set rows are stubs
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS archive_key TEXT;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_unarchived_created_at ON messages(created_at) WHERE archive_key IS NULL;
@@ -0,0 +1,71 @@
+-- Range-partition messages by month on created_at so list queries prune old months and
+-- retention can drop whole partitions. Primary keys on partitioned tables must include the
+-- partition key, so the key becomes (id, created_at); ids still come from messages_id_seq.
+-- Partitions are named messages_pYYYY_MM; rows outside every monthly partition land in
+-- messages_default. The service creates upcoming months (MESSAGE_PARTITIONS_AHEAD).
+ALTER TABLE messages RENAME TO messages_unpartitioned;
+ALTER SEQUENCE messages_id_seq OWNED BY NONE;
+
+CREATE TABLE messages (
+    id BIGINT NOT NULL DEFAULT nextval('messages_id_seq'),
+    phone_number VARCHAR(50) NOT NULL,
+    template_id VARCHAR(50) NOT NULL,
+    parameters TEXT NOT NULL,
+    order_id VARCHAR(50),
+    customer_id VARCHAR(50),
+    status VARCHAR(20) NOT NULL,
+    error_code VARCHAR(20),
+    error_message TEXT,
+    external_id VARCHAR(100),
+    tenant_id VARCHAR(50) NOT NULL DEFAULT 'default',
+    status_sequence BIGINT NOT NULL DEFAULT 0,
+    content_snapshot TEXT,
+    erased_at TIMESTAMP,
+    archive_key TEXT,
+    archived_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE messages_default PARTITION OF messages DEFAULT;
+
+-- One partition per month from the oldest message through next month
+DO $$
+DECLARE
+    month_start DATE := date_trunc('month', COALESCE((SELECT MIN(created_at) FROM messages_unpartitioned), NOW()));
+BEGIN
+    WHILE month_start <= date_trunc('month', NOW() + INTERVAL '1 month') LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF messages FOR VALUES FROM (%L) TO (%L)',
+            'messages_p' || to_char(month_start, 'YYYY_MM'),
+            month_start,
+            month_start + INTERVAL '1 month'
+        );
+        month_start := month_start + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+INSERT INTO messages (
+    id, phone_number, template_id, parameters, order_id, customer_id, status,
+    error_code, error_message, external_id, tenant_id, status_sequence, content_snapshot,
+    erased_at, archive_key, archived_at, created_at, updated_at
+)
+SELECT
+    id, phone_number, template_id, parameters, order_id, customer_id, status,
+    error_code, error_message, external_id, tenant_id, status_sequence, content_snapshot,
+    erased_at, archive_key, archived_at, created_at, updated_at
+FROM messages_unpartitioned;
+
+DROP TABLE messages_unpartitioned;
+ALTER SEQUENCE messages_id_seq OWNED BY messages.id;
+
+CREATE INDEX IF NOT EXISTS idx_messages_id ON messages(id);
+CREATE INDEX IF NOT EXISTS idx_messages_phone_number ON messages(phone_number);
+CREATE INDEX IF NOT EXISTS idx_messages_order_id ON messages(order_id);
+CREATE INDEX IF NOT EXISTS idx_messages_customer_id ON messages(customer_id);
+CREATE INDEX IF NOT EXISTS idx_messages_external_id ON messages(external_id);
+CREATE INDEX IF NOT EXISTS idx_messages_status ON messages(status);
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_external_id ON messages(tenant_id, external_id);
+CREATE INDEX IF NOT EXISTS idx_messages_unarchived_created_at ON messages(created_at) WHERE archive_key IS NULL;
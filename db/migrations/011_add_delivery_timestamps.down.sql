@@ -0,0 +1,3 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS read_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS delivered_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS sent_at;
@@ -0,0 +1,5 @@
+-- When each delivery stage was first reached (created_at is when the message was queued),
+-- used for delivery latency reporting
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS sent_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS read_at TIMESTAMP;
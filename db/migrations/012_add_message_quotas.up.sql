@@ -0,0 +1,9 @@
+-- Messages accepted per tenant or customer and calendar month, for quota enforcement
+CREATE TABLE IF NOT EXISTS quota_usage (
+    scope VARCHAR(20) NOT NULL,
+    subject VARCHAR(150) NOT NULL,
+    period_start DATE NOT NULL,
+    used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject, period_start)
+);
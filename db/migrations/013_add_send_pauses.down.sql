@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_held;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS held_at;
+
+DROP TABLE IF EXISTS send_pauses;
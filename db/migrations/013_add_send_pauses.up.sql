@@ -0,0 +1,14 @@
+-- Active pauses of outbound sending, shared by all replicas
+CREATE TABLE IF NOT EXISTS send_pauses (
+    scope VARCHAR(20) NOT NULL,
+    subject VARCHAR(100) NOT NULL DEFAULT '',
+    reason TEXT,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject)
+);
+
+-- Set while a queued message is held by a pause; cleared when it is re-enqueued on resume
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS held_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_held ON messages(id) WHERE held_at IS NOT NULL;
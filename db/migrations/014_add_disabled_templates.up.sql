@@ -0,0 +1,7 @@
+-- Templates switched off at runtime (kill switch); sends of these are refused
+CREATE TABLE IF NOT EXISTS disabled_templates (
+    template_id VARCHAR(100) PRIMARY KEY,
+    reason TEXT,
+    actor VARCHAR(100) NOT NULL,
+    disabled_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
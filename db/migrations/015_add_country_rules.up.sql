@@ -0,0 +1,8 @@
+-- Destination country rules set at runtime; they add to and override the configured lists
+CREATE TABLE IF NOT EXISTS country_rules (
+    prefix VARCHAR(15) PRIMARY KEY,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('allow', 'block')),
+    reason TEXT,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
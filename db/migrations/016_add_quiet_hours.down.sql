@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_deferred;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS deferred_until;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS recipient_timezone;
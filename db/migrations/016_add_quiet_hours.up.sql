@@ -0,0 +1,8 @@
+-- Explicit recipient timezone of a message; NULL infers it from the phone number
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS recipient_timezone VARCHAR(64);
+
+-- Set while a queued marketing message is deferred out of quiet hours; cleared when the
+-- scheduler re-enqueues it
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS deferred_until TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_deferred ON messages(deferred_until) WHERE deferred_until IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Time after which a queued message is expired instead of sent; NULL never expires
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_retry_of;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS retry_of;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS attempt;
@@ -0,0 +1,6 @@
+-- Retries are new messages linked to the message they retry; attempt counts sends of the
+-- same content starting at 1
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS retry_of BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_messages_retry_of ON messages(retry_of) WHERE retry_of IS NOT NULL;
@@ -0,0 +1,2 @@
+-- enqueued_at is when the broker acknowledged the message's write to the send topic
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS enqueued_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS inbound_messages;
+
+DROP TABLE IF EXISTS conversations;
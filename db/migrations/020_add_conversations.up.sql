@@ -0,0 +1,33 @@
+-- One conversation per customer of a tenant, carrying its agent handoff state
+CREATE TABLE IF NOT EXISTS conversations (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(50) NOT NULL,
+    profile_name VARCHAR(255),
+    handoff_status VARCHAR(20) NOT NULL DEFAULT 'none',
+    handoff_reason TEXT,
+    handoff_agent VARCHAR(100),
+    handoff_at TIMESTAMP,
+    last_inbound_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, phone_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_handoff ON conversations(tenant_id, handoff_status) WHERE handoff_status IN ('pending', 'assigned');
+
+-- Messages customers sent in; the provider's ID dedupes redelivered webhooks
+CREATE TABLE IF NOT EXISTS inbound_messages (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    message_type VARCHAR(30) NOT NULL,
+    text TEXT,
+    received_at TIMESTAMP NOT NULL,
+    handled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbound_messages_conversation ON inbound_messages(conversation_id, received_at DESC);
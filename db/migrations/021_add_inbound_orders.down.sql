@@ -0,0 +1 @@
+ALTER TABLE inbound_messages DROP COLUMN IF EXISTS order_payload;
@@ -0,0 +1,2 @@
+-- order_payload is the cart of an order message, as JSON (catalog_id and product_items)
+ALTER TABLE inbound_messages ADD COLUMN IF NOT EXISTS order_payload JSONB;
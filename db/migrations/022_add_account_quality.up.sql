@@ -0,0 +1,25 @@
+-- Latest quality rating and messaging limit tier of each business phone number
+CREATE TABLE IF NOT EXISTS account_quality (
+    tenant_id VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(50) NOT NULL,
+    quality_rating VARCHAR(20) NOT NULL DEFAULT 'UNKNOWN',
+    messaging_limit_tier VARCHAR(30),
+    last_event VARCHAR(50) NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, phone_number)
+);
+
+-- History of account_update and phone_number_quality_update webhook events
+CREATE TABLE IF NOT EXISTS account_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(50) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    quality_rating VARCHAR(20),
+    messaging_limit_tier VARCHAR(30),
+    detail TEXT,
+    received_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_events_tenant_received ON account_events (tenant_id, received_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_audit_log_actor;
+DROP INDEX IF EXISTS idx_audit_log_tenant_created;
+
+ALTER TABLE audit_log DROP COLUMN IF EXISTS after_state;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS before_state;
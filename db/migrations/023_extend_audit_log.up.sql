@@ -0,0 +1,6 @@
+-- State of the changed object before and after an audited admin operation
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_state JSONB;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_state JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
@@ -0,0 +1,2 @@
+-- Serves the duplicate send lookup: a recipient's recent messages within a tenant
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_phone_created_at ON messages(tenant_id, phone_number, created_at);
@@ -0,0 +1,2 @@
+-- Set when a message was soft deleted, e.g. a test send; reads and stats skip such rows
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
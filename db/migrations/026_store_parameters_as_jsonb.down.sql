@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_messages_search_text;
+DROP INDEX IF EXISTS idx_messages_parameters;
+
+ALTER TABLE messages ALTER COLUMN parameters TYPE TEXT USING parameters::text;
@@ -0,0 +1,9 @@
+-- Store parameters as JSONB so support can search them: the GIN index serves containment
+-- lookups such as parameters @> '{"tracking_id": "TRK-1"}', and the full-text index covers
+-- parameter values and the rendered content that was sent
+ALTER TABLE messages ALTER COLUMN parameters TYPE JSONB USING parameters::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_messages_parameters ON messages USING GIN (parameters jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_messages_search_text ON messages USING GIN (
+    (jsonb_to_tsvector('simple', parameters, '["string", "numeric"]') || to_tsvector('simple', COALESCE(content_snapshot, '')))
+);
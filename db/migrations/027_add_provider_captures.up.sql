@@ -0,0 +1,19 @@
+-- Provider requests and responses of message sends, kept while PROVIDER_CAPTURE is on
+CREATE TABLE IF NOT EXISTS provider_captures (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    tenant_id VARCHAR(50) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    url TEXT NOT NULL,
+    request_headers JSONB,
+    request_body TEXT,
+    status_code INTEGER,
+    response_headers JSONB,
+    response_body TEXT,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_captures_message ON provider_captures (message_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_provider_captures_created ON provider_captures (created_at);
@@ -0,0 +1,15 @@
+-- Opt-in and opt-out events of contacts, kept as proof of consent to marketing messages
+CREATE TABLE IF NOT EXISTS contact_opt_ins (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    evidence TEXT,
+    actor VARCHAR(100) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    recorded_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_opt_ins_contact ON contact_opt_ins (tenant_id, phone_number, occurred_at DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS campaigns;
+DROP TABLE IF EXISTS segment_members;
+DROP TABLE IF EXISTS segments;
+DROP TABLE IF EXISTS contacts;
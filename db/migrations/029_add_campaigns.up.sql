@@ -0,0 +1,62 @@
+-- Contacts of each tenant, with the attributes and tags segments filter on
+CREATE TABLE IF NOT EXISTS contacts (
+    tenant_id VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    last_activity_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, phone_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_attributes ON contacts USING GIN (attributes jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_tags ON contacts USING GIN (tags);
+
+-- Campaign audiences: stored filters over contacts, or uploaded lists in segment_members
+CREATE TABLE IF NOT EXISTS segments (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    filter JSONB,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_segments_tenant ON segments (tenant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS segment_members (
+    segment_id BIGINT NOT NULL REFERENCES segments(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    PRIMARY KEY (segment_id, phone_number)
+);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    segment_id BIGINT NOT NULL REFERENCES segments(id),
+    template_id VARCHAR(50) NOT NULL,
+    parameters JSONB,
+    status VARCHAR(20) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_tenant ON campaigns (tenant_id, created_at DESC);
+
+-- The audience of a campaign, materialized when it starts; status is pending, sending, sent or failed
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    message_id BIGINT,
+    error TEXT,
+    claimed_at TIMESTAMP,
+    PRIMARY KEY (campaign_id, phone_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_pending ON campaign_recipients (campaign_id) WHERE status IN ('pending', 'sending');
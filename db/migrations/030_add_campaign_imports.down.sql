@@ -0,0 +1,3 @@
+DELETE FROM campaigns WHERE segment_id IS NULL;
+ALTER TABLE campaigns ALTER COLUMN segment_id SET NOT NULL;
+ALTER TABLE campaign_recipients DROP COLUMN IF EXISTS parameters;
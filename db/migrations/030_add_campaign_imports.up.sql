@@ -0,0 +1,4 @@
+-- Recipients imported from a CSV carry their own template parameters, and campaigns may send
+-- to an imported audience only
+ALTER TABLE campaign_recipients ADD COLUMN IF NOT EXISTS parameters JSONB;
+ALTER TABLE campaigns ALTER COLUMN segment_id DROP NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE campaign_recipients DROP COLUMN IF EXISTS variant;
+DROP TABLE IF EXISTS campaign_variants;
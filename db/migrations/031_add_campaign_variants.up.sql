@@ -0,0 +1,13 @@
+-- Templates an A/B tested campaign splits its audience between, in the order they were given
+CREATE TABLE IF NOT EXISTS campaign_variants (
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    position INT NOT NULL,
+    template_id VARCHAR(50) NOT NULL,
+    parameters JSONB,
+    weight INT NOT NULL,
+    PRIMARY KEY (campaign_id, name)
+);
+
+-- The variant each recipient was sent, set with its outcome
+ALTER TABLE campaign_recipients ADD COLUMN IF NOT EXISTS variant VARCHAR(50);
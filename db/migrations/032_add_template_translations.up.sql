@@ -0,0 +1,9 @@
+-- Localized bodies of templates sent through Twilio without a content template. Bodies are Go
+-- text/template executed with the message parameters, e.g. 'Tu pedido {{.order_id}} va en camino'
+CREATE TABLE IF NOT EXISTS template_translations (
+    template_id VARCHAR(50) NOT NULL,
+    locale VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (template_id, locale)
+);
@@ -0,0 +1,15 @@
+-- Changes of provider webhooks that failed to process, replayed until they go through or run
+-- out of attempts. The payload holds the webhook with only the failed change.
+CREATE TABLE IF NOT EXISTS webhook_failures (
+    id BIGSERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    entry_id VARCHAR(100) NOT NULL,
+    field VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    error TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_failures_attempts ON webhook_failures(attempts, id);
@@ -0,0 +1,19 @@
+-- Statuses for external IDs no message had when they arrived, matched again until they expire.
+-- Redelivered statuses are quarantined once.
+CREATE TABLE IF NOT EXISTS quarantined_statuses (
+    id BIGSERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    tenant_id VARCHAR(50) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_code VARCHAR(50),
+    error_message TEXT,
+    phone_number VARCHAR(100),
+    timestamp VARCHAR(20),
+    status_at TIMESTAMP,
+    dedupe_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, tenant_id, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_statuses_created_at ON quarantined_statuses(created_at);
@@ -0,0 +1,24 @@
+-- Queue payloads written in the transaction storing their message, published by the relay
+-- when the inline produce after commit did not go through
+CREATE TABLE IF NOT EXISTS message_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    tenant_id VARCHAR(50) NOT NULL DEFAULT 'default',
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_outbox_pending ON message_outbox(created_at) WHERE published_at IS NULL;
+
+-- Status transitions of messages, written with the change they record
+CREATE TABLE IF NOT EXISTS message_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_code VARCHAR(50),
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_status_history_message_id ON message_status_history(message_id);
@@ -0,0 +1,2 @@
+-- Lets the retention job find expired webhook failures without scanning them all
+CREATE INDEX IF NOT EXISTS idx_webhook_failures_created ON webhook_failures (created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_inbound_messages_created;
+DROP INDEX IF EXISTS idx_message_outbox_published;
+DROP INDEX IF EXISTS idx_message_status_history_created;
@@ -0,0 +1,5 @@
+-- Lets the retention job find expired status history, published outbox payloads and inbound
+-- messages without scanning them all
+CREATE INDEX IF NOT EXISTS idx_message_status_history_created ON message_status_history (created_at);
+CREATE INDEX IF NOT EXISTS idx_message_outbox_published ON message_outbox (published_at) WHERE published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_inbound_messages_created ON inbound_messages (created_at);
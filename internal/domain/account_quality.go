@@ -0,0 +1,61 @@
+// internal/domain/account_quality.go
+package domain
+
+import "time"
+
+// Quality ratings of a business phone number. Meta reports a number FLAGGED when its rating
+// drops to low (red) and UNFLAGGED once it recovered, which is recorded as green.
+const (
+	QualityUnknown = "UNKNOWN"
+	QualityGreen   = "GREEN"
+	QualityRed     = "RED"
+)
+
+// Webhook fields account events arrive in
+const (
+	AccountFieldAccountUpdate = "account_update"
+	AccountFieldQualityUpdate = "phone_number_quality_update"
+)
+
+// AccountQuality is the latest quality rating and messaging limit Meta reported for one of a
+// tenant's business phone numbers
+type AccountQuality struct {
+	TenantID    string
+	PhoneNumber string
+	// QualityRating is one of the Quality* constants
+	QualityRating string
+	// MessagingLimitTier is Meta's tier, e.g. TIER_1K or TIER_UNLIMITED; empty until reported
+	MessagingLimitTier string
+	LastEvent          string
+	UpdatedAt          time.Time
+}
+
+// AccountEvent is one account_update or phone_number_quality_update webhook event
+type AccountEvent struct {
+	ID          int64
+	TenantID    string
+	PhoneNumber string
+	// Field is AccountFieldAccountUpdate or AccountFieldQualityUpdate
+	Field string
+	// Event is Meta's event, e.g. FLAGGED, DOWNGRADE or ACCOUNT_VIOLATION
+	Event string
+	// QualityRating and MessagingLimitTier are set when the event changed them
+	QualityRating      string
+	MessagingLimitTier string
+	// Detail describes violations and bans of account updates
+	Detail     string
+	ReceivedAt time.Time
+}
+
+// QualityForEvent returns the quality rating a phone_number_quality_update event leaves a number
+// with, or "" for events that only change its messaging limit
+func QualityForEvent(event string) string {
+	switch event {
+	case "FLAGGED":
+		return QualityRed
+	case "UNFLAGGED":
+		return QualityGreen
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,72 @@
+// internal/domain/audit.go
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionEraseCustomerData  = "erase_customer_data"
+	AuditActionExportCustomerData = "export_customer_data"
+	AuditActionPauseSending       = "pause_sending"
+	AuditActionResumeSending      = "resume_sending"
+	AuditActionDisableTemplate    = "disable_template"
+	AuditActionEnableTemplate     = "enable_template"
+	AuditActionSetCountryRule     = "set_country_rule"
+	AuditActionDeleteCountryRule  = "delete_country_rule"
+	AuditActionDeleteMessage      = "delete_message"
+	AuditActionHardDeleteMessage  = "hard_delete_message"
+	AuditActionSetLogLevel        = "set_log_level"
+)
+
+// AuditEntry records who performed a sensitive operation on which subject
+type AuditEntry struct {
+	ID           int64
+	Action       string
+	TenantID     string
+	SubjectType  string
+	Subject      string
+	Actor        string
+	Reason       string
+	AffectedRows int64
+	// Before and After are JSON snapshots of the subject around a change; nil when it didn't
+	// exist or for operations that change no single object
+	Before    json.RawMessage
+	After     json.RawMessage
+	CreatedAt time.Time
+}
+
+// AuditFilter selects audit log entries; zero fields match everything
+type AuditFilter struct {
+	TenantID    string
+	Action      string
+	Actor       string
+	SubjectType string
+	Subject     string
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+}
+
+// Data subject identifier types
+const (
+	SubjectCustomerID  = "customer_id"
+	SubjectPhoneNumber = "phone_number"
+)
+
+// Subject types of audited admin operations
+const (
+	SubjectSendPause     = "send_pause"
+	SubjectTemplate      = "template"
+	SubjectCountryPrefix = "country_prefix"
+	SubjectMessage       = "message"
+	SubjectLogLevel      = "log_level"
+)
+
+// DataSubject identifies the person a privacy request is about
+type DataSubject struct {
+	CustomerID  string
+	PhoneNumber string
+}
@@ -0,0 +1,161 @@
+// internal/domain/campaign.go
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// Contact is a customer of a tenant that segments select from
+type Contact struct {
+	TenantID string
+	// PhoneNumber holds the E.164 digits of the contact's number
+	PhoneNumber string
+	Attributes  map[string]string
+	Tags        []string
+	// LastActivityAt is when the contact was last active as reported by the tenant; inbound
+	// messages count as activity too
+	LastActivityAt time.Time
+	UpdatedAt      time.Time
+}
+
+// Segment kinds
+const (
+	// SegmentKindFilter selects the contacts matching a stored filter when a campaign starts
+	SegmentKindFilter = "filter"
+	// SegmentKindList is a fixed list of uploaded phone numbers
+	SegmentKindList = "list"
+)
+
+// SegmentFilter selects contacts; unset fields match every contact
+type SegmentFilter struct {
+	// Attributes must all equal the contact's
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Tags must all be among the contact's
+	Tags []string `json:"tags,omitempty"`
+	// ActiveWithin matches contacts active this recently
+	ActiveWithin time.Duration `json:"active_within,omitempty"`
+	// InactiveFor matches contacts not active for this long, including never
+	InactiveFor time.Duration `json:"inactive_for,omitempty"`
+}
+
+// Segment is a campaign audience: a stored filter over contacts or an uploaded list
+type Segment struct {
+	ID       int64
+	TenantID string
+	Name     string
+	// Kind is SegmentKindFilter or SegmentKindList
+	Kind   string
+	Filter SegmentFilter
+	// ListSize is the number of phone numbers of a list segment
+	ListSize  int64
+	Actor     string
+	CreatedAt time.Time
+}
+
+// Campaign statuses
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusRunning   = "running"
+	CampaignStatusCompleted = "completed"
+)
+
+// Campaign sends a template to the audience of a segment and to recipients imported into it.
+// The segment's audience is materialized when the campaign starts, so contacts changing later
+// don't affect it.
+type Campaign struct {
+	ID       int64
+	TenantID string
+	Name     string
+	// SegmentID is 0 for campaigns sending to imported recipients only
+	SegmentID int64
+	// TemplateID is sent to every recipient, unless the campaign tests Variants
+	TemplateID string
+	Parameters map[string]string
+	Variants   []CampaignVariant
+	Status     string
+	// AudienceSize is the number of recipients imported, and materialized at start
+	AudienceSize int64
+	// Sent and Failed count the recipients whose send was accepted or refused so far
+	Sent        int64
+	Failed      int64
+	Actor       string
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// CampaignVariant is one of the templates an A/B tested campaign splits its audience between
+type CampaignVariant struct {
+	Name       string
+	TemplateID string
+	// Parameters override the campaign's
+	Parameters map[string]string
+	// Weight is the variant's share of the audience, relative to the other variants' weights
+	Weight int
+}
+
+// PickVariant assigns a recipient to one of variants in proportion to their weights. The
+// assignment depends only on the campaign and phone number, so it is the same on every replica
+// and every retry.
+func PickVariant(variants []CampaignVariant, campaignID int64, phoneNumber string) *CampaignVariant {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%d:%s", campaignID, phoneNumber)
+	point := int(hash.Sum32() % uint32(total))
+	for i := range variants {
+		if point < variants[i].Weight {
+			return &variants[i]
+		}
+		point -= variants[i].Weight
+	}
+	return nil
+}
+
+// CampaignRecipient is a member of a campaign's audience. Parameters are the recipient's own,
+// from an import; they override the variant's, which override the campaign's.
+type CampaignRecipient struct {
+	CampaignID  int64
+	TenantID    string
+	PhoneNumber string
+	TemplateID  string
+	// CampaignParameters are the campaign's parameters, set on claimed recipients
+	CampaignParameters map[string]string
+	Parameters         map[string]string
+}
+
+// VariantReport compares how the messages of one variant fared. Stats counts the recipients
+// sent to or refused by their message's current status, "failed" for refusals.
+type VariantReport struct {
+	Variant CampaignVariant
+	Stats   StatsBucket
+}
+
+// AudienceImport summarizes an import of recipients into a campaign
+type AudienceImport struct {
+	// Rows counts the data rows read, each of them imported, a duplicate, suppressed or invalid
+	Rows       int64
+	Imported   int64
+	Duplicates int64
+	// Suppressed counts the rows of contacts who opted out
+	Suppressed int64
+	Invalid    int64
+	// Errors holds the first invalid rows
+	Errors []ImportRowError
+}
+
+// ImportRowError is an imported row that was refused
+type ImportRowError struct {
+	// Row is the row's line, counting the header as line 1
+	Row         int64
+	PhoneNumber string
+	Message     string
+}
@@ -0,0 +1,80 @@
+// internal/domain/conversation.go
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Handoff states of a conversation: automation owns it (none), it was handed to the agent
+// channel and waits for an agent (pending), an agent took it (assigned), or the agent is done
+// and it went back to automation (resolved)
+const (
+	HandoffNone     = "none"
+	HandoffPending  = "pending"
+	HandoffAssigned = "assigned"
+	HandoffResolved = "resolved"
+)
+
+// Conversation is the thread of inbound messages from one customer of a tenant
+type Conversation struct {
+	ID          int64
+	TenantID    string
+	PhoneNumber string
+	ProfileName string
+
+	HandoffStatus string
+	HandoffReason string
+	HandoffAgent  string
+	HandoffAt     time.Time
+
+	LastInboundAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// WithAgent reports whether an agent rather than automation is answering the conversation
+func (c Conversation) WithAgent() bool {
+	return c.HandoffStatus == HandoffPending || c.HandoffStatus == HandoffAssigned
+}
+
+// InboundMessage is a message a customer sent to one of the tenant's numbers
+type InboundMessage struct {
+	ID             int64
+	ConversationID int64
+	TenantID       string
+	// ExternalID is the provider's message ID, unique per tenant
+	ExternalID  string
+	PhoneNumber string
+	ProfileName string
+	// Type is the provider's message type, e.g. text, button, interactive or image
+	Type string
+	// Text is the message body, or the title of the button or list item picked
+	Text string
+	// Order is the cart of an order message, sent from the catalog
+	Order      *InboundOrder
+	ReceivedAt time.Time
+	// HandledAt is set once the message was dealt with, so redelivered webhooks skip it
+	HandledAt time.Time
+}
+
+// InboundOrder is a cart a customer sent from the tenant's catalog
+type InboundOrder struct {
+	CatalogID string      `json:"catalog_id"`
+	Items     []OrderItem `json:"product_items"`
+}
+
+// OrderItem is one product of an InboundOrder
+type OrderItem struct {
+	ProductRetailerID string  `json:"product_retailer_id"`
+	Quantity          int     `json:"quantity"`
+	ItemPrice         float64 `json:"item_price"`
+	Currency          string  `json:"currency"`
+}
+
+// NormalizeCustomerPhone reduces a phone number to the digits-only WhatsApp ID form
+// inbound messages arrive with
+func NormalizeCustomerPhone(phoneNumber string) string {
+	phoneNumber = strings.TrimPrefix(strings.TrimSpace(phoneNumber), "whatsapp:")
+	return strings.TrimPrefix(phoneNumber, "+")
+}
@@ -0,0 +1,63 @@
+// internal/domain/country.go
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Country rule actions
+const (
+	CountryActionAllow = "allow"
+	CountryActionBlock = "block"
+)
+
+// CountryRule allows or blocks sends to phone numbers starting with a dialing prefix: a
+// country calling code ("44"), or a longer prefix for part of a shared code ("1876")
+type CountryRule struct {
+	Prefix    string
+	Action    string
+	Reason    string
+	Actor     string
+	CreatedAt time.Time
+}
+
+// twoDigitCallingCodes are the two-digit country calling codes; codes starting with 1 or 7 have
+// one digit and all others three. Calling codes are prefix-free, so this determines the code.
+var twoDigitCallingCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true, "36": true,
+	"39": true, "40": true, "41": true, "43": true, "44": true, "45": true, "46": true, "47": true,
+	"48": true, "49": true, "51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true, "64": true, "65": true,
+	"66": true, "81": true, "82": true, "84": true, "86": true, "90": true, "91": true, "92": true,
+	"93": true, "94": true, "95": true, "98": true,
+}
+
+// PhoneDigits returns the E.164 digits of a phone number, without the "whatsapp:" prefix, the
+// leading "+" or any formatting
+func PhoneDigits(phoneNumber string) string {
+	phoneNumber = strings.TrimPrefix(phoneNumber, "whatsapp:")
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phoneNumber)
+}
+
+// CountryCallingCode returns the country calling code of an E.164 phone number
+func CountryCallingCode(phoneNumber string) (string, bool) {
+	digits := PhoneDigits(phoneNumber)
+	if len(digits) < 4 || digits[0] == '0' {
+		return "", false
+	}
+
+	switch {
+	case digits[0] == '1' || digits[0] == '7':
+		return digits[:1], true
+	case twoDigitCallingCodes[digits[:2]]:
+		return digits[:2], true
+	default:
+		return digits[:3], true
+	}
+}
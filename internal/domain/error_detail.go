@@ -0,0 +1,123 @@
+// internal/domain/error_detail.go
+package domain
+
+// Providers that can report error codes
+const (
+	ProviderMeta   = "meta"
+	ProviderTwilio = "twilio"
+)
+
+// ErrorCategory groups provider error codes into stable values clients can branch on
+type ErrorCategory string
+
+const (
+	ErrorCategoryUnknown             ErrorCategory = "unknown"
+	ErrorCategoryInvalidRequest      ErrorCategory = "invalid_request"
+	ErrorCategoryRecipient           ErrorCategory = "recipient"
+	ErrorCategoryTemplate            ErrorCategory = "template"
+	ErrorCategoryRateLimited         ErrorCategory = "rate_limited"
+	ErrorCategoryAuthentication      ErrorCategory = "authentication"
+	ErrorCategoryProviderUnavailable ErrorCategory = "provider_unavailable"
+	ErrorCategoryPolicy              ErrorCategory = "policy"
+	ErrorCategoryInternal            ErrorCategory = "internal"
+)
+
+// ErrorDetail is the typed description of a message failure
+type ErrorDetail struct {
+	ProviderCode string
+	Category     ErrorCategory
+	Retryable    bool
+	Message      string
+}
+
+type errorClass struct {
+	category  ErrorCategory
+	retryable bool
+}
+
+// metaErrorClasses maps WhatsApp Cloud API error codes to categories
+var metaErrorClasses = map[string]errorClass{
+	"0":      {ErrorCategoryAuthentication, false},
+	"10":     {ErrorCategoryAuthentication, false},
+	"190":    {ErrorCategoryAuthentication, false},
+	"1":      {ErrorCategoryProviderUnavailable, true},
+	"2":      {ErrorCategoryProviderUnavailable, true},
+	"131000": {ErrorCategoryProviderUnavailable, true},
+	"131016": {ErrorCategoryProviderUnavailable, true},
+	"133004": {ErrorCategoryProviderUnavailable, true},
+	"4":      {ErrorCategoryRateLimited, true},
+	"80007":  {ErrorCategoryRateLimited, true},
+	"130429": {ErrorCategoryRateLimited, true},
+	"131048": {ErrorCategoryRateLimited, true},
+	"131056": {ErrorCategoryRateLimited, true},
+	"100":    {ErrorCategoryInvalidRequest, false},
+	"131008": {ErrorCategoryInvalidRequest, false},
+	"131009": {ErrorCategoryInvalidRequest, false},
+	"131021": {ErrorCategoryInvalidRequest, false},
+	"131051": {ErrorCategoryInvalidRequest, false},
+	"131026": {ErrorCategoryRecipient, false},
+	"131047": {ErrorCategoryRecipient, false},
+	"131050": {ErrorCategoryRecipient, false},
+	"132000": {ErrorCategoryTemplate, false},
+	"132001": {ErrorCategoryTemplate, false},
+	"132005": {ErrorCategoryTemplate, false},
+	"132007": {ErrorCategoryTemplate, false},
+	"132012": {ErrorCategoryTemplate, false},
+	"132015": {ErrorCategoryTemplate, false},
+	"132016": {ErrorCategoryTemplate, false},
+	"368":    {ErrorCategoryPolicy, false},
+	"131031": {ErrorCategoryPolicy, false},
+	"131049": {ErrorCategoryPolicy, false},
+}
+
+// twilioErrorClasses maps Twilio error codes to categories
+var twilioErrorClasses = map[string]errorClass{
+	"20003": {ErrorCategoryAuthentication, false},
+	"20429": {ErrorCategoryRateLimited, true},
+	"63018": {ErrorCategoryRateLimited, true},
+	"30001": {ErrorCategoryProviderUnavailable, true},
+	"21211": {ErrorCategoryInvalidRequest, false},
+	"63024": {ErrorCategoryInvalidRequest, false},
+	"30003": {ErrorCategoryRecipient, false},
+	"63003": {ErrorCategoryRecipient, false},
+	"63016": {ErrorCategoryRecipient, false},
+	"63027": {ErrorCategoryTemplate, false},
+	"30007": {ErrorCategoryPolicy, false},
+	"63005": {ErrorCategoryPolicy, false},
+	"63013": {ErrorCategoryPolicy, false},
+}
+
+// ClassifyProviderError returns the category of a provider error code and whether
+// retrying the send may succeed. Unknown codes are reported as non-retryable.
+func ClassifyProviderError(provider, code string) (ErrorCategory, bool) {
+	var classes map[string]errorClass
+	switch provider {
+	case ProviderMeta:
+		classes = metaErrorClasses
+	case ProviderTwilio:
+		classes = twilioErrorClasses
+	}
+
+	if class, ok := classes[code]; ok {
+		return class.category, class.retryable
+	}
+	return ErrorCategoryUnknown, false
+}
+
+// NewErrorDetail builds the error detail of a failed message. Failures without a
+// provider code happened inside this service and are classified as internal.
+func NewErrorDetail(provider, code, message string) *ErrorDetail {
+	if code == "" && message == "" {
+		return nil
+	}
+
+	detail := &ErrorDetail{
+		ProviderCode: code,
+		Category:     ErrorCategoryInternal,
+		Message:      message,
+	}
+	if code != "" {
+		detail.Category, detail.Retryable = ClassifyProviderError(provider, code)
+	}
+	return detail
+}
@@ -0,0 +1,63 @@
+// internal/domain/errors.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors callers can test for with errors.Is
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrValidation          = errors.New("validation failed")
+	ErrConflict            = errors.New("conflict")
+	ErrUnauthenticated     = errors.New("unauthenticated")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrProviderRateLimited = errors.New("provider rate limited")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	ErrQuotaExceeded       = errors.New("quota exceeded")
+	ErrFailedPrecondition  = errors.New("failed precondition")
+)
+
+// Error is a domain error of a given kind with a message safe to return to clients
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Is matches the error's kind so errors.Is(err, ErrNotFound) works
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Unwrap returns the underlying cause, if any
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError creates a domain error of the given kind
+func NewError(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// WrapError creates a domain error of the given kind caused by err
+func WrapError(kind error, err error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// ErrorMessage returns the client-facing message of a domain error, or fallback for any other error
+func ErrorMessage(err error, fallback string) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+	return fallback
+}
@@ -0,0 +1,23 @@
+// internal/domain/expiry.go
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StatusExpired marks a message whose TTL elapsed while it was queued, so it was never sent
+const StatusExpired = "expired"
+
+type expiresAtContextKey struct{}
+
+// WithExpiresAt returns a copy of ctx carrying the time after which a new message must not be sent
+func WithExpiresAt(ctx context.Context, expiresAt time.Time) context.Context {
+	return context.WithValue(ctx, expiresAtContextKey{}, expiresAt)
+}
+
+// ExpiresAtFromContext returns the expiry stored in ctx, or the zero time for none
+func ExpiresAtFromContext(ctx context.Context) time.Time {
+	expiresAt, _ := ctx.Value(expiresAtContextKey{}).(time.Time)
+	return expiresAt
+}
@@ -4,15 +4,93 @@ package domain
 import "time"
 
 type Message struct {
-    ID           int64                  `json:"id"`
-    PhoneNumber  string                 `json:"phone_number"`
-    TemplateID   string                 `json:"template_id"`
-    Parameters   map[string]interface{} `json:"parameters"`
-    OrderID      string                 `json:"order_id"`
-    CustomerID   string                 `json:"customer_id"`
-    Status       string                 `json:"status"`
-    ErrorMessage string                 `json:"error_message,omitempty"`
-    ExternalID   string                 `json:"external_id,omitempty"`
-    CreatedAt    time.Time              `json:"created_at"`
-    UpdatedAt    time.Time              `json:"updated_at"`
-}
\ No newline at end of file
+    ID              int64                  `json:"id"`
+    PhoneNumber     string                 `json:"phone_number"`
+    TemplateID      string                 `json:"template_id"`
+    Parameters      map[string]interface{} `json:"parameters"`
+    OrderID         string                 `json:"order_id"`
+    CustomerID      string                 `json:"customer_id"`
+    Status          string                 `json:"status"`
+    ErrorCode       string                 `json:"error_code,omitempty"`
+    ErrorMessage    string                 `json:"error_message,omitempty"`
+    ExternalID      string                 `json:"external_id,omitempty"`
+    TenantID        string                 `json:"tenant_id"`
+    // RecipientTimezone is the IANA timezone quiet hours are applied in; empty infers it
+    // from the phone number's country code
+    RecipientTimezone string               `json:"recipient_timezone,omitempty"`
+    // ExpiresAt, when set, is the time after which a queued message is expired unsent
+    ExpiresAt       time.Time              `json:"expires_at,omitempty"`
+    // Attempt counts sends of the same content: 1 for a new message, and one more than the
+    // retried message for a retry, which links back to it through RetryOfID
+    Attempt         int                    `json:"attempt"`
+    RetryOfID       int64                  `json:"retry_of_id,omitempty"`
+    // ContentSnapshot is the exact payload sent to the provider, kept so support
+    // can see what the customer received even after the template changes
+    ContentSnapshot string                 `json:"content_snapshot,omitempty"`
+    // ArchiveKey is set once the full message has been moved to object storage and
+    // only a stub row remains in the database
+    ArchiveKey      string                 `json:"archive_key,omitempty"`
+    // DeletedAt is set once the message was soft deleted; it is then hidden from reads
+    // and stats but keeps its place in order and retry history
+    DeletedAt       time.Time              `json:"deleted_at,omitempty"`
+    // ErasedAt is set once the message's personal data was erased at the subject's request
+    ErasedAt        time.Time              `json:"erased_at,omitempty"`
+    CreatedAt       time.Time              `json:"created_at"`
+    UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// StatusUpdate is one provider status change applied to a message. Empty error and
+// external ID fields leave the stored values unchanged.
+type StatusUpdate struct {
+    MessageID    int64
+    Status       string
+    ErrorCode    string
+    ErrorMessage string
+    ExternalID   string
+    // At is when the provider reports the status was reached; zero means now
+    At           time.Time
+}
+
+// StatusUpdateResult is the stored state of a message after UpdateMessageStatuses
+type StatusUpdateResult struct {
+    // Sequence is the message's final status sequence
+    Sequence    int64
+    // PreviousStatus is the status before the update, Status the one it left
+    PreviousStatus string
+    Status         string
+    CreatedAt   time.Time
+    // Stage times recorded by this update; zero when not reached or already recorded earlier
+    SentAt      time.Time
+    DeliveredAt time.Time
+    ReadAt      time.Time
+}
+
+// MessageFilter holds the optional criteria used to list and count messages.
+// Empty strings and zero times are ignored.
+type MessageFilter struct {
+    TenantID      string
+    OrderID       string
+    CustomerID    string
+    PhoneNumber   string
+    Status        string
+    TemplateID    string
+    CreatedAfter  time.Time
+    CreatedBefore time.Time
+    // IncludeDeleted also matches soft deleted messages
+    IncludeDeleted bool
+}
+
+// MessageSort orders a message listing by By ("created_at", "updated_at" or "status") in
+// Order ("asc" or "desc"); empty fields list newest first
+type MessageSort struct {
+    By    string
+    Order string
+}
+
+// MessageSearch selects the messages matching a filter whose parameters hold every value in
+// Parameters and whose parameter values or rendered content contain every word of Text
+type MessageSearch struct {
+    Filter     MessageFilter
+    Parameters map[string]string
+    Text       string
+}
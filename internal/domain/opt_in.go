@@ -0,0 +1,31 @@
+// internal/domain/opt_in.go
+package domain
+
+import "time"
+
+// Opt-in actions
+const (
+	OptInActionOptIn  = "opt_in"
+	OptInActionOptOut = "opt_out"
+)
+
+// OptInEvent records a contact agreeing to, or withdrawing from, marketing messages, with the
+// proof kept for WhatsApp Business policy audits. A contact is opted in while their latest
+// event is an opt-in.
+type OptInEvent struct {
+	ID       int64
+	TenantID string
+	// PhoneNumber holds the E.164 digits of the contact's number
+	PhoneNumber string
+	// Action is OptInActionOptIn or OptInActionOptOut
+	Action string
+	// Source is where consent was given or withdrawn, e.g. "checkout" or "stop_keyword"
+	Source string
+	// Channel is the medium it was given through, e.g. "web", "whatsapp" or "sms"
+	Channel string
+	// Evidence is the consent text the contact agreed to, or their message
+	Evidence   string
+	Actor      string
+	OccurredAt time.Time
+	RecordedAt time.Time
+}
@@ -0,0 +1,25 @@
+// internal/domain/outbox.go
+package domain
+
+import "time"
+
+// OutboxMessage is the queue payload of a message, stored with it and published after
+type OutboxMessage struct {
+	ID        int64
+	MessageID int64
+	TenantID  string
+	Payload   []byte
+	CreatedAt time.Time
+	// PublishedAt is zero until the payload reaches the queue
+	PublishedAt time.Time
+}
+
+// StatusHistoryEntry is one status a message went through
+type StatusHistoryEntry struct {
+	ID           int64
+	MessageID    int64
+	Status       string
+	ErrorCode    string
+	ErrorMessage string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,49 @@
+// internal/domain/pause.go
+package domain
+
+import "time"
+
+// Pause scopes: all outbound sends, one tenant's, or one template's
+const (
+	PauseScopeAll      = "all"
+	PauseScopeTenant   = "tenant"
+	PauseScopeTemplate = "template"
+)
+
+// SendPause stops outbound sends in its scope; paused messages stay queued until resumed
+type SendPause struct {
+	Scope string
+	// Subject is the tenant or template ID; empty for PauseScopeAll
+	Subject   string
+	Reason    string
+	Actor     string
+	CreatedAt time.Time
+	// Maintenance is set on the pause imposed by maintenance mode, which the API cannot lift
+	Maintenance bool
+}
+
+// Covers reports whether the pause applies to a message of the given tenant and template
+func (p SendPause) Covers(tenantID, templateID string) bool {
+	switch p.Scope {
+	case PauseScopeAll:
+		return true
+	case PauseScopeTenant:
+		return p.Subject == tenantID
+	case PauseScopeTemplate:
+		return p.Subject == templateID
+	default:
+		return false
+	}
+}
+
+// Filter returns the criteria selecting the messages the pause applies to
+func (p SendPause) Filter() MessageFilter {
+	switch p.Scope {
+	case PauseScopeTenant:
+		return MessageFilter{TenantID: p.Subject}
+	case PauseScopeTemplate:
+		return MessageFilter{TemplateID: p.Subject}
+	default:
+		return MessageFilter{}
+	}
+}
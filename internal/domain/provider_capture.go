@@ -0,0 +1,24 @@
+// internal/domain/provider_capture.go
+package domain
+
+import "time"
+
+// ProviderCapture is one provider HTTP request made while sending a message and the response
+// to it, kept in debug mode with credentials redacted
+type ProviderCapture struct {
+	ID        int64
+	MessageID int64
+	TenantID  string
+	Method    string
+	URL       string
+	// Headers are flattened, multiple values joined by ", "
+	RequestHeaders  map[string]string
+	RequestBody     string
+	StatusCode      int
+	ResponseHeaders map[string]string
+	ResponseBody    string
+	// Error is set when the request got no response
+	Error     string
+	Duration  time.Duration
+	CreatedAt time.Time
+}
@@ -0,0 +1,170 @@
+// internal/domain/quiet_hours.go
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily window of recipient local time in which marketing messages are not
+// sent. A window whose end is before its start spans midnight, e.g. 21:00-09:00.
+type QuietHours struct {
+	// Start and End are minutes after local midnight
+	Start int
+	End   int
+}
+
+// ParseQuietHours parses a window written as "HH:MM-HH:MM"
+func ParseQuietHours(value string) (QuietHours, error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("quiet hours %q must be written as HH:MM-HH:MM", value)
+	}
+
+	startMinute, err := parseClock(start)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	endMinute, err := parseClock(end)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if startMinute == endMinute {
+		return QuietHours{}, fmt.Errorf("quiet hours %q must not start and end at the same time", value)
+	}
+	return QuietHours{Start: startMinute, End: endMinute}, nil
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: want HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// String formats the window as HH:MM-HH:MM
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// Contains reports whether t falls inside the window in t's location
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// NextAllowed returns t if it is outside the window, and otherwise the end of the window
+// in t's location
+func (q QuietHours) NextAllowed(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End/60, q.End%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = time.Date(t.Year(), t.Month(), t.Day()+1, q.End/60, q.End%60, 0, 0, t.Location())
+	}
+	return end
+}
+
+// countryTimezones maps calling codes to the timezone most of the country's population
+// lives in. Countries spanning several zones get their most populous one.
+var countryTimezones = map[string]string{
+	"1":   "America/New_York",
+	"7":   "Europe/Moscow",
+	"20":  "Africa/Cairo",
+	"27":  "Africa/Johannesburg",
+	"30":  "Europe/Athens",
+	"31":  "Europe/Amsterdam",
+	"32":  "Europe/Brussels",
+	"33":  "Europe/Paris",
+	"34":  "Europe/Madrid",
+	"36":  "Europe/Budapest",
+	"39":  "Europe/Rome",
+	"40":  "Europe/Bucharest",
+	"41":  "Europe/Zurich",
+	"43":  "Europe/Vienna",
+	"44":  "Europe/London",
+	"45":  "Europe/Copenhagen",
+	"46":  "Europe/Stockholm",
+	"47":  "Europe/Oslo",
+	"48":  "Europe/Warsaw",
+	"49":  "Europe/Berlin",
+	"51":  "America/Lima",
+	"52":  "America/Mexico_City",
+	"54":  "America/Argentina/Buenos_Aires",
+	"55":  "America/Sao_Paulo",
+	"56":  "America/Santiago",
+	"57":  "America/Bogota",
+	"58":  "America/Caracas",
+	"60":  "Asia/Kuala_Lumpur",
+	"61":  "Australia/Sydney",
+	"62":  "Asia/Jakarta",
+	"63":  "Asia/Manila",
+	"64":  "Pacific/Auckland",
+	"65":  "Asia/Singapore",
+	"66":  "Asia/Bangkok",
+	"81":  "Asia/Tokyo",
+	"82":  "Asia/Seoul",
+	"84":  "Asia/Ho_Chi_Minh",
+	"86":  "Asia/Shanghai",
+	"90":  "Europe/Istanbul",
+	"91":  "Asia/Kolkata",
+	"92":  "Asia/Karachi",
+	"93":  "Asia/Kabul",
+	"94":  "Asia/Colombo",
+	"95":  "Asia/Yangon",
+	"98":  "Asia/Tehran",
+	"212": "Africa/Casablanca",
+	"213": "Africa/Algiers",
+	"216": "Africa/Tunis",
+	"234": "Africa/Lagos",
+	"254": "Africa/Nairobi",
+	"351": "Europe/Lisbon",
+	"353": "Europe/Dublin",
+	"358": "Europe/Helsinki",
+	"852": "Asia/Hong_Kong",
+	"880": "Asia/Dhaka",
+	"886": "Asia/Taipei",
+	"961": "Asia/Beirut",
+	"962": "Asia/Amman",
+	"964": "Asia/Baghdad",
+	"965": "Asia/Kuwait",
+	"966": "Asia/Riyadh",
+	"968": "Asia/Muscat",
+	"971": "Asia/Dubai",
+	"972": "Asia/Jerusalem",
+	"973": "Asia/Bahrain",
+	"974": "Asia/Qatar",
+	"977": "Asia/Kathmandu",
+}
+
+// TimezoneForPhone infers the recipient's timezone from the phone number's calling code
+func TimezoneForPhone(phoneNumber string) (string, bool) {
+	code, ok := CountryCallingCode(phoneNumber)
+	if !ok {
+		return "", false
+	}
+	tz, ok := countryTimezones[code]
+	return tz, ok
+}
+
+type recipientTimezoneContextKey struct{}
+
+// WithRecipientTimezone returns a copy of ctx carrying the recipient's IANA timezone
+func WithRecipientTimezone(ctx context.Context, tz string) context.Context {
+	return context.WithValue(ctx, recipientTimezoneContextKey{}, tz)
+}
+
+// RecipientTimezoneFromContext returns the recipient timezone stored in ctx, if any
+func RecipientTimezoneFromContext(ctx context.Context) string {
+	tz, _ := ctx.Value(recipientTimezoneContextKey{}).(string)
+	return tz
+}
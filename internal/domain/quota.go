@@ -0,0 +1,38 @@
+// internal/domain/quota.go
+package domain
+
+import "time"
+
+// Quota scopes; customer quotas are counted per tenant
+const (
+	QuotaScopeTenant   = "tenant"
+	QuotaScopeCustomer = "customer"
+)
+
+// StatusQuotaExceeded marks a message recorded but not sent because a quota was used up
+const StatusQuotaExceeded = "quota_exceeded"
+
+// QuotaUsage is the state of one monthly quota
+type QuotaUsage struct {
+	Scope       string
+	Subject     string
+	Limit       int64
+	Used        int64
+	PeriodStart time.Time
+	ResetsAt    time.Time
+}
+
+// Remaining is how many more messages the quota allows this period
+func (q QuotaUsage) Remaining() int64 {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// QuotaPeriod returns the UTC calendar month containing t
+func QuotaPeriod(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
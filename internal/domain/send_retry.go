@@ -0,0 +1,6 @@
+// internal/domain/send_retry.go
+package domain
+
+// StatusRetrying marks a message whose send failed transiently and that waits in a delayed
+// retry topic for another attempt
+const StatusRetrying = "retrying"
@@ -0,0 +1,75 @@
+// internal/domain/stats.go
+package domain
+
+import "time"
+
+// StatusCount is the number of messages with one status created on one UTC day for one template
+type StatusCount struct {
+	Day        time.Time
+	TemplateID string
+	Status     string
+	Count      int64
+}
+
+// StatsBucket aggregates the messages created on one day for one template; the summary
+// bucket of MessageStats spans every day and template, leaving both unset
+type StatsBucket struct {
+	Day          time.Time
+	TemplateID   string
+	StatusCounts map[string]int64
+	Total        int64
+}
+
+// Add counts n more messages with the given status
+func (b *StatsBucket) Add(status string, n int64) {
+	if b.StatusCounts == nil {
+		b.StatusCounts = make(map[string]int64)
+	}
+	b.StatusCounts[status] += n
+	b.Total += n
+}
+
+// DeliveredRate is the share of messages that reached the recipient; read messages were delivered too
+func (b StatsBucket) DeliveredRate() float64 {
+	return b.rate(b.StatusCounts["delivered"] + b.StatusCounts["read"])
+}
+
+// ReadRate is the share of messages the recipient read
+func (b StatsBucket) ReadRate() float64 {
+	return b.rate(b.StatusCounts["read"])
+}
+
+// FailureRate is the share of messages that failed
+func (b StatsBucket) FailureRate() float64 {
+	return b.rate(b.StatusCounts["failed"])
+}
+
+func (b StatsBucket) rate(n int64) float64 {
+	if b.Total == 0 {
+		return 0
+	}
+	return float64(n) / float64(b.Total)
+}
+
+// MessageStats summarizes the messages created in a time range
+type MessageStats struct {
+	Summary StatsBucket
+	// Buckets are ordered by day, then template
+	Buckets []StatsBucket
+}
+
+// Delivery stages measured from when a message was queued
+const (
+	StageSent      = "sent"
+	StageDelivered = "delivered"
+	StageRead      = "read"
+)
+
+// StageLatency summarizes how long messages took from being queued to reaching a stage
+type StageLatency struct {
+	Stage string
+	// Count is the number of messages that reached the stage
+	Count int64
+	P50   time.Duration
+	P95   time.Duration
+}
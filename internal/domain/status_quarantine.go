@@ -0,0 +1,25 @@
+// internal/domain/status_quarantine.go
+package domain
+
+import "time"
+
+// QuarantinedStatus is a provider status for an external ID no message has (yet). A webhook can
+// arrive before the send that got the ID is committed, so quarantined statuses are matched
+// again until they are too old, when they are taken to belong elsewhere (e.g. another
+// environment sharing the account).
+type QuarantinedStatus struct {
+	ID           int64
+	Provider     string
+	TenantID     string
+	ExternalID   string
+	Status       string
+	ErrorCode    string
+	ErrorMessage string
+	// PhoneNumber is the recipient, hashed like the status events it is published in
+	PhoneNumber string
+	// Timestamp is the provider's, and At the time it stands for (zero for Twilio)
+	Timestamp string
+	At        time.Time
+	DedupeKey string
+	CreatedAt time.Time
+}
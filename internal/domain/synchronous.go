@@ -0,0 +1,18 @@
+// internal/domain/synchronous.go
+package domain
+
+import "context"
+
+type synchronousContextKey struct{}
+
+// WithSynchronous returns a copy of ctx asking for a new message to be sent to the provider
+// before the send returns, instead of being queued
+func WithSynchronous(ctx context.Context) context.Context {
+	return context.WithValue(ctx, synchronousContextKey{}, true)
+}
+
+// SynchronousFromContext reports whether ctx asks for a synchronous send
+func SynchronousFromContext(ctx context.Context) bool {
+	synchronous, _ := ctx.Value(synchronousContextKey{}).(bool)
+	return synchronous
+}
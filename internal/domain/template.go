@@ -0,0 +1,68 @@
+// internal/domain/template.go
+package domain
+
+import "time"
+
+// DisabledTemplate is a template switched off at runtime; sends of it are refused
+type DisabledTemplate struct {
+	TemplateID string
+	Reason     string
+	Actor      string
+	DisabledAt time.Time
+}
+
+// Webhook fields template events arrive in
+const (
+	TemplateFieldStatusUpdate  = "message_template_status_update"
+	TemplateFieldQualityUpdate = "message_template_quality_update"
+)
+
+// TemplateEvent is a template status or quality change Meta reported
+type TemplateEvent struct {
+	// TemplateID is the template's name, which sends refer to it by
+	TemplateID string
+	Language   string
+	// Field is TemplateFieldStatusUpdate or TemplateFieldQualityUpdate
+	Field string
+	// Event is the new status, e.g. PAUSED, DISABLED or REINSTATED; empty for quality updates
+	Event  string
+	Reason string
+	// PreviousQuality and Quality are the scores of quality updates, e.g. GREEN or RED
+	PreviousQuality string
+	Quality         string
+}
+
+// TemplatePreview is a template rendered with the parameters of a send, without sending it
+type TemplatePreview struct {
+	TemplateID string
+	Language   string
+	Status     string
+	Category   string
+	Header     string
+	Body       string
+	Footer     string
+	Buttons    []TemplateButtonPreview
+	// Payload is the JSON request a send to the preview's recipient would make
+	Payload []byte
+	// MissingParameters lists the placeholders no parameter fills, as "body {{2}}"
+	MissingParameters []string
+	// UnusedParameters lists the parameters no placeholder takes
+	UnusedParameters []string
+}
+
+// TemplateButtonPreview is a button of a rendered template
+type TemplateButtonPreview struct {
+	Type        string
+	Text        string
+	URL         string
+	PhoneNumber string
+}
+
+// TemplateTranslation is the body of a template in one locale, for providers sending templates
+// as plain text
+type TemplateTranslation struct {
+	TemplateID string
+	Locale     string
+	// Body is a Go text/template executed with the message parameters
+	Body string
+}
@@ -0,0 +1,60 @@
+// internal/domain/tenant.go
+package domain
+
+import "context"
+
+// DefaultTenantID is used when a request does not identify a tenant
+const DefaultTenantID = "default"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the given tenant ID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID stored in ctx, or DefaultTenantID
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+type tenantScopeContextKey struct{}
+
+// WithTenantScope returns a copy of ctx carrying tenantID and confining the messages read and
+// changed with it to that tenant, as for every gRPC request
+func WithTenantScope(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(WithTenant(ctx, tenantID), tenantScopeContextKey{}, tenantID)
+}
+
+// TenantScope returns the tenant ctx is confined to. Internal work, such as consumers, jobs
+// and webhooks, is not confined and sees every tenant's messages.
+func TenantScope(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantScopeContextKey{}).(string)
+	return tenantID, ok
+}
+
+type callerContextKey struct{}
+
+// WithCaller returns a copy of ctx carrying the name of the authenticated caller
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFromContext returns the name of the authenticated caller, or "" when the request was
+// not authenticated
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerContextKey{}).(string)
+	return caller
+}
+
+// ActorFromContext returns who audit entries name for a request: the authenticated caller, or
+// claimed, such as a request's requested_by, when the request was not authenticated
+func ActorFromContext(ctx context.Context, claimed string) string {
+	if caller := CallerFromContext(ctx); caller != "" {
+		return caller
+	}
+	return claimed
+}
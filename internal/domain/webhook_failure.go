@@ -0,0 +1,20 @@
+// internal/domain/webhook_failure.go
+package domain
+
+import "time"
+
+// WebhookFailure is a change of a provider webhook that failed to process, kept to be replayed
+type WebhookFailure struct {
+	ID       int64
+	Provider string
+	// EntryID is the business account the change was for, and Field the kind of change
+	EntryID string
+	Field   string
+	// Payload is the webhook as the provider sent it, with only the failed change
+	Payload []byte
+	Error   string
+	// Attempts counts the replays that failed
+	Attempts  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,42 @@
+// internal/handler/account_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "messaging-microservice/proto"
+)
+
+// GetAccountQuality returns the caller tenant's phone number quality and recent account events
+func (h *GrpcMessageHandler) GetAccountQuality(ctx context.Context, req *pb.GetAccountQualityRequest) (*pb.GetAccountQualityResponse, error) {
+	qualities, events, err := h.accounts.GetAccountQuality(ctx, int(req.EventLimit))
+	if err != nil {
+		return nil, GRPCError(err, "failed to get account quality")
+	}
+
+	resp := &pb.GetAccountQualityResponse{SendRateFactor: h.accounts.RateFactor()}
+	for _, quality := range qualities {
+		resp.PhoneNumbers = append(resp.PhoneNumbers, &pb.PhoneNumberQuality{
+			PhoneNumber:        quality.PhoneNumber,
+			QualityRating:      quality.QualityRating,
+			MessagingLimitTier: quality.MessagingLimitTier,
+			LastEvent:          quality.LastEvent,
+			UpdatedAt:          timestamppb.New(quality.UpdatedAt),
+		})
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, &pb.AccountEvent{
+			Id:                 event.ID,
+			PhoneNumber:        event.PhoneNumber,
+			Field:              event.Field,
+			Event:              event.Event,
+			QualityRating:      event.QualityRating,
+			MessagingLimitTier: event.MessagingLimitTier,
+			Detail:             event.Detail,
+			ReceivedAt:         timestamppb.New(event.ReceivedAt),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,161 @@
+// internal/handler/admin_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// PauseSending stops outbound sends in the requested scope
+func (h *GrpcMessageHandler) PauseSending(ctx context.Context, req *pb.PauseSendingRequest) (*pb.SendPause, error) {
+	pause, err := h.pauseService.Pause(ctx, domain.SendPause{
+		Scope:   pauseScopeFromProto(req.Scope),
+		Subject: req.Subject,
+		Reason:  req.Reason,
+		Actor:   req.RequestedBy,
+	})
+	if err != nil {
+		h.logger.Error("Failed to pause sending", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to pause sending")
+	}
+
+	return convertPauseToProto(*pause), nil
+}
+
+// ResumeSending lifts a pause and reports how many held messages were re-enqueued
+func (h *GrpcMessageHandler) ResumeSending(ctx context.Context, req *pb.ResumeSendingRequest) (*pb.ResumeSendingResponse, error) {
+	released, err := h.pauseService.Resume(ctx, pauseScopeFromProto(req.Scope), req.Subject, req.RequestedBy)
+	if err != nil {
+		h.logger.Error("Failed to resume sending", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to resume sending")
+	}
+
+	return &pb.ResumeSendingResponse{ReleasedMessages: released}, nil
+}
+
+// ListSendPauses returns the pauses in force on this replica
+func (h *GrpcMessageHandler) ListSendPauses(ctx context.Context, req *pb.ListSendPausesRequest) (*pb.ListSendPausesResponse, error) {
+	pauses := h.pauseService.ListPauses()
+
+	resp := &pb.ListSendPausesResponse{Pauses: make([]*pb.SendPause, 0, len(pauses))}
+	for _, pause := range pauses {
+		resp.Pauses = append(resp.Pauses, convertPauseToProto(pause))
+	}
+	return resp, nil
+}
+
+// convertPauseToProto converts a domain.SendPause
+func convertPauseToProto(pause domain.SendPause) *pb.SendPause {
+	resp := &pb.SendPause{
+		Scope:       pauseScopeToProto(pause.Scope),
+		Subject:     pause.Subject,
+		Reason:      pause.Reason,
+		RequestedBy: pause.Actor,
+		Maintenance: pause.Maintenance,
+	}
+	if !pause.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(pause.CreatedAt)
+	}
+	return resp
+}
+
+// DisableTemplate switches a template off
+func (h *GrpcMessageHandler) DisableTemplate(ctx context.Context, req *pb.DisableTemplateRequest) (*pb.DisabledTemplate, error) {
+	template, err := h.templates.Disable(ctx, domain.DisabledTemplate{
+		TemplateID: req.TemplateId,
+		Reason:     req.Reason,
+		Actor:      req.RequestedBy,
+	})
+	if err != nil {
+		h.logger.Error("Failed to disable template", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to disable template")
+	}
+
+	return convertDisabledTemplateToProto(*template), nil
+}
+
+// EnableTemplate switches a disabled template back on
+func (h *GrpcMessageHandler) EnableTemplate(ctx context.Context, req *pb.EnableTemplateRequest) (*pb.EnableTemplateResponse, error) {
+	if err := h.templates.Enable(ctx, req.TemplateId, req.RequestedBy); err != nil {
+		h.logger.Error("Failed to enable template", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to enable template")
+	}
+
+	return &pb.EnableTemplateResponse{}, nil
+}
+
+// ListDisabledTemplates returns the templates switched off, as cached on this replica
+func (h *GrpcMessageHandler) ListDisabledTemplates(ctx context.Context, req *pb.ListDisabledTemplatesRequest) (*pb.ListDisabledTemplatesResponse, error) {
+	disabled := h.templates.ListDisabled()
+
+	resp := &pb.ListDisabledTemplatesResponse{Templates: make([]*pb.DisabledTemplate, 0, len(disabled))}
+	for _, template := range disabled {
+		resp.Templates = append(resp.Templates, convertDisabledTemplateToProto(template))
+	}
+	return resp, nil
+}
+
+// convertDisabledTemplateToProto converts a domain.DisabledTemplate
+func convertDisabledTemplateToProto(template domain.DisabledTemplate) *pb.DisabledTemplate {
+	return &pb.DisabledTemplate{
+		TemplateId:  template.TemplateID,
+		Reason:      template.Reason,
+		RequestedBy: template.Actor,
+		DisabledAt:  timestamppb.New(template.DisabledAt),
+	}
+}
+
+// SetCountryRule allows or blocks sends to a dialing prefix
+func (h *GrpcMessageHandler) SetCountryRule(ctx context.Context, req *pb.SetCountryRuleRequest) (*pb.CountryRule, error) {
+	rule, err := h.countries.SetRule(ctx, domain.CountryRule{
+		Prefix: req.Prefix,
+		Action: countryActionFromProto(req.Action),
+		Reason: req.Reason,
+		Actor:  req.RequestedBy,
+	})
+	if err != nil {
+		h.logger.Error("Failed to set country rule", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to set country rule")
+	}
+
+	return convertCountryRuleToProto(*rule), nil
+}
+
+// DeleteCountryRule removes a runtime country rule
+func (h *GrpcMessageHandler) DeleteCountryRule(ctx context.Context, req *pb.DeleteCountryRuleRequest) (*pb.DeleteCountryRuleResponse, error) {
+	if err := h.countries.DeleteRule(ctx, req.Prefix, req.RequestedBy); err != nil {
+		h.logger.Error("Failed to delete country rule", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to delete country rule")
+	}
+
+	return &pb.DeleteCountryRuleResponse{}, nil
+}
+
+// ListCountryRules returns the country rules in force on this replica
+func (h *GrpcMessageHandler) ListCountryRules(ctx context.Context, req *pb.ListCountryRulesRequest) (*pb.ListCountryRulesResponse, error) {
+	rules := h.countries.ListRules()
+
+	resp := &pb.ListCountryRulesResponse{Rules: make([]*pb.CountryRule, 0, len(rules))}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, convertCountryRuleToProto(rule))
+	}
+	return resp, nil
+}
+
+// convertCountryRuleToProto converts a domain.CountryRule
+func convertCountryRuleToProto(rule domain.CountryRule) *pb.CountryRule {
+	resp := &pb.CountryRule{
+		Prefix:      rule.Prefix,
+		Action:      countryActionToProto(rule.Action),
+		Reason:      rule.Reason,
+		RequestedBy: rule.Actor,
+	}
+	if !rule.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(rule.CreatedAt)
+	}
+	return resp
+}
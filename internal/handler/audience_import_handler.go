@@ -0,0 +1,56 @@
+// internal/handler/audience_import_handler.go
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// AudienceImportHandler takes campaign audience CSVs as plain HTTP uploads, for clients that
+// can't stream them over gRPC
+type AudienceImportHandler struct {
+	campaigns service.CampaignService
+	logger    utils.Logger
+}
+
+// NewAudienceImportHandler creates a new audience import handler
+func NewAudienceImportHandler(campaigns service.CampaignService, logger utils.Logger) *AudienceImportHandler {
+	return &AudienceImportHandler{
+		campaigns: campaigns,
+		logger:    logger,
+	}
+}
+
+// HandleImport reads the request body as a CSV into the campaign's audience and responds with
+// the import summary, shaped as ImportCampaignAudience's response. It is served behind
+// TenantMiddleware, which scopes the request to the tenant of the caller's API key.
+func (h *AudienceImportHandler) HandleImport(c *gin.Context) {
+	campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+	if err != nil || campaignID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaign_id must be a positive integer"})
+		return
+	}
+
+	summary, err := h.campaigns.ImportAudience(c.Request.Context(), campaignID, c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to import campaign audience", "error", err, "campaign_id", campaignID)
+		err = GRPCError(err, "failed to import campaign audience")
+		c.JSON(HTTPStatus(err), gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(convertAudienceImportToProto(summary))
+	if err != nil {
+		h.logger.Error("Failed to encode audience import summary", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode response"})
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+}
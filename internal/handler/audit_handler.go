@@ -0,0 +1,49 @@
+// internal/handler/audit_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// ListAuditEntries returns the caller tenant's audit log entries matching the request
+func (h *GrpcMessageHandler) ListAuditEntries(ctx context.Context, req *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	var messageFilter domain.MessageFilter
+	if err := applyTimestampRange(&messageFilter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return nil, err
+	}
+
+	entries, err := h.audit.ListAuditEntries(ctx, domain.AuditFilter{
+		Action:      req.Action,
+		Actor:       req.Actor,
+		SubjectType: req.SubjectType,
+		Subject:     req.Subject,
+		Since:       messageFilter.CreatedAfter,
+		Until:       messageFilter.CreatedBefore,
+		Limit:       int(req.Limit),
+	})
+	if err != nil {
+		return nil, GRPCError(err, "failed to list audit entries")
+	}
+
+	resp := &pb.ListAuditEntriesResponse{Entries: make([]*pb.AuditEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, &pb.AuditEntry{
+			Id:           entry.ID,
+			Action:       entry.Action,
+			SubjectType:  entry.SubjectType,
+			Subject:      entry.Subject,
+			Actor:        entry.Actor,
+			Reason:       entry.Reason,
+			AffectedRows: entry.AffectedRows,
+			BeforeJson:   string(entry.Before),
+			AfterJson:    string(entry.After),
+			CreatedAt:    timestamppb.New(entry.CreatedAt),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,141 @@
+// internal/handler/caller_limits.go
+package handler
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// apiKeyMetadataKey is the gRPC metadata key callers use to identify their API key
+const apiKeyMetadataKey = "x-api-key"
+
+// sendMethods are the RPCs counted against daily message limits, one message per request.
+// StartCampaign counts its audience instead.
+var sendMethods = map[string]bool{
+	pb.WhatsAppService_SendTemplateMessage_FullMethodName:    true,
+	pb.WhatsAppService_SendCTAURLMessage_FullMethodName:      true,
+	pb.WhatsAppService_SendProductMessage_FullMethodName:     true,
+	pb.WhatsAppService_SendProductListMessage_FullMethodName: true,
+	pb.WhatsAppService_RetryMessage_FullMethodName:           true,
+}
+
+var callerLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_grpc_caller_limited_total",
+	Help: "gRPC requests rejected by per-caller limits, by limit (rate, daily_messages).",
+}, []string{"limit"})
+
+// CallerLimitPolicy selects the limits applied to a gRPC caller. Callers are identified by
+// the API key TenantInterceptor authenticated them with, or by tenant when requests are not
+// authenticated; per-caller entries are keyed by the API key or tenant ID and take precedence
+// over the defaults.
+type CallerLimitPolicy struct {
+	// Rate limits requests of any RPC; the zero value is unlimited
+	Rate  utils.RateLimit
+	Rates map[string]utils.RateLimit
+	// DailyMessages caps the sends per UTC day; 0 is unlimited
+	DailyMessages      int64
+	DailyMessageLimits map[string]int64
+}
+
+// rate returns the request rate of caller
+func (p CallerLimitPolicy) rate(caller string) (utils.RateLimit, bool) {
+	if limit, ok := p.Rates[caller]; ok {
+		return limit, true
+	}
+	return p.Rate, p.Rate.RPS > 0
+}
+
+// dailyMessages returns the daily send limit of caller
+func (p CallerLimitPolicy) dailyMessages(caller string) int64 {
+	if limit, ok := p.DailyMessageLimits[caller]; ok {
+		return limit
+	}
+	return p.DailyMessages
+}
+
+// CallerLimitInterceptor enforces per-caller request rates and daily message limits, sharing
+// them between replicas through limiter and counter. Rejected requests get ResourceExhausted
+// and a retry-after header (in seconds). It must run after TenantInterceptor.
+func CallerLimitInterceptor(limiter utils.RateLimiter, counter utils.WindowCounter, policy CallerLimitPolicy, logger utils.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Only a key TenantInterceptor authenticated identifies the caller, so made-up keys
+		// share their tenant's limits. Keys are hashed before they go into limiter keys.
+		caller, identity := domain.TenantFromContext(ctx), "tenant:"+domain.TenantFromContext(ctx)
+		if domain.CallerFromContext(ctx) != "" {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if apiKey := firstMetadata(md, apiKeyMetadataKey); apiKey != "" {
+				caller, identity = apiKey, "key:"+utils.HashAPIKey(apiKey)
+			}
+		}
+
+		if limit, ok := policy.rate(caller); ok {
+			allowed, retryAfter, err := limiter.Allow(ctx, "grpc:"+identity, limit)
+			if err != nil {
+				// Fail open: rate limiting must not take the service down
+				logger.Error("Caller rate limiter failed", "error", err, "method", info.FullMethod)
+			} else if !allowed {
+				callerLimitedTotal.WithLabelValues("rate").Inc()
+				logger.Warn("Caller rate limit exceeded", "method", info.FullMethod, "tenant", domain.TenantFromContext(ctx))
+				return nil, callerLimited(ctx, retryAfter, "rate limit exceeded, retry later")
+			}
+		}
+
+		limit := policy.dailyMessages(caller)
+		campaign := info.FullMethod == pb.WhatsAppService_StartCampaign_FullMethodName
+		if limit <= 0 || !sendMethods[info.FullMethod] && !campaign {
+			return handler(ctx, req)
+		}
+
+		now := time.Now().UTC()
+		day := now.Truncate(24 * time.Hour)
+		reset := day.Add(24 * time.Hour)
+		key := "grpc-daily:" + identity + ":" + day.Format("2006-01-02")
+
+		// A send counts one message up front. A campaign's audience is only known once it
+		// starts, so it may start while the caller is under the limit and its recipients count
+		// then, holding back the caller's sends for the rest of the day.
+		var increment, allowed int64 = 1, limit
+		if campaign {
+			increment, allowed = 0, limit-1
+		}
+		count, err := counter.Incr(ctx, key, increment, reset.Add(time.Hour))
+		if err != nil {
+			logger.Error("Caller message counter failed", "error", err, "method", info.FullMethod)
+		} else if count > allowed {
+			callerLimitedTotal.WithLabelValues("daily_messages").Inc()
+			logger.Warn("Caller daily message limit exceeded", "method", info.FullMethod, "tenant", domain.TenantFromContext(ctx), "limit", limit)
+			return nil, callerLimited(ctx, reset.Sub(now), "daily message limit exceeded")
+		}
+
+		resp, err := handler(ctx, req)
+		if started, ok := resp.(*pb.Campaign); ok && err == nil && campaign {
+			if _, err := counter.Incr(ctx, key, started.AudienceSize, reset.Add(time.Hour)); err != nil {
+				logger.Error("Caller message counter failed", "error", err, "method", info.FullMethod)
+			}
+		}
+		return resp, err
+	}
+}
+
+// callerLimited sets the retry-after header and returns a ResourceExhausted error
+func callerLimited(ctx context.Context, retryAfter time.Duration, msg string) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	return status.Error(codes.ResourceExhausted, msg)
+}
@@ -0,0 +1,289 @@
+// internal/handler/campaign_handler.go
+package handler
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// UpsertContact creates or replaces a contact of the caller tenant
+func (h *GrpcMessageHandler) UpsertContact(ctx context.Context, req *pb.UpsertContactRequest) (*pb.Contact, error) {
+	contact := domain.Contact{
+		PhoneNumber: req.PhoneNumber,
+		Attributes:  req.Attributes,
+		Tags:        req.Tags,
+	}
+	if req.LastActivityAt != nil {
+		contact.LastActivityAt = req.LastActivityAt.AsTime()
+	}
+
+	stored, err := h.campaigns.UpsertContact(ctx, contact)
+	if err != nil {
+		h.logger.Error("Failed to upsert contact", "error", err)
+		return nil, GRPCError(err, "failed to upsert contact")
+	}
+	return &pb.Contact{
+		PhoneNumber:    stored.PhoneNumber,
+		Attributes:     stored.Attributes,
+		Tags:           stored.Tags,
+		LastActivityAt: optionalTimestamp(stored.LastActivityAt),
+		UpdatedAt:      timestamppb.New(stored.UpdatedAt),
+	}, nil
+}
+
+// CreateSegment stores a filter segment, or a list segment when phone numbers are given
+func (h *GrpcMessageHandler) CreateSegment(ctx context.Context, req *pb.CreateSegmentRequest) (*pb.Segment, error) {
+	segment := domain.Segment{
+		Name:   req.Name,
+		Kind:   domain.SegmentKindFilter,
+		Filter: segmentFilterFromProto(req.Filter),
+		Actor:  req.RequestedBy,
+	}
+	if len(req.PhoneNumbers) > 0 {
+		segment.Kind = domain.SegmentKindList
+	}
+
+	created, err := h.campaigns.CreateSegment(ctx, segment, req.PhoneNumbers)
+	if err != nil {
+		h.logger.Error("Failed to create segment", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to create segment")
+	}
+	return convertSegmentToProto(*created), nil
+}
+
+// ListSegments returns the caller tenant's segments
+func (h *GrpcMessageHandler) ListSegments(ctx context.Context, req *pb.ListSegmentsRequest) (*pb.ListSegmentsResponse, error) {
+	segments, err := h.campaigns.ListSegments(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list segments", "error", err)
+		return nil, GRPCError(err, "failed to list segments")
+	}
+
+	resp := &pb.ListSegmentsResponse{Segments: make([]*pb.Segment, 0, len(segments))}
+	for _, segment := range segments {
+		resp.Segments = append(resp.Segments, convertSegmentToProto(segment))
+	}
+	return resp, nil
+}
+
+// PreviewSegment sizes a stored segment or an unsaved filter
+func (h *GrpcMessageHandler) PreviewSegment(ctx context.Context, req *pb.PreviewSegmentRequest) (*pb.PreviewSegmentResponse, error) {
+	size, sample, err := h.campaigns.PreviewSegment(ctx, req.SegmentId, segmentFilterFromProto(req.Filter), int(req.SampleSize))
+	if err != nil {
+		h.logger.Error("Failed to preview segment", "error", err, "segment_id", req.SegmentId)
+		return nil, GRPCError(err, "failed to preview segment")
+	}
+	return &pb.PreviewSegmentResponse{Size: size, Sample: sample}, nil
+}
+
+// CreateCampaign stores a draft campaign
+func (h *GrpcMessageHandler) CreateCampaign(ctx context.Context, req *pb.CreateCampaignRequest) (*pb.Campaign, error) {
+	variants := make([]domain.CampaignVariant, 0, len(req.Variants))
+	for _, variant := range req.Variants {
+		variants = append(variants, domain.CampaignVariant{
+			Name:       variant.Name,
+			TemplateID: variant.TemplateId,
+			Parameters: variant.Parameters,
+			Weight:     int(variant.Weight),
+		})
+	}
+
+	campaign, err := h.campaigns.CreateCampaign(ctx, domain.Campaign{
+		Name:       req.Name,
+		SegmentID:  req.SegmentId,
+		TemplateID: req.TemplateId,
+		Parameters: req.Parameters,
+		Variants:   variants,
+		Actor:      req.RequestedBy,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create campaign", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to create campaign")
+	}
+	return convertCampaignToProto(*campaign), nil
+}
+
+// StartCampaign materializes a draft campaign's audience and starts sending
+func (h *GrpcMessageHandler) StartCampaign(ctx context.Context, req *pb.StartCampaignRequest) (*pb.Campaign, error) {
+	campaign, err := h.campaigns.StartCampaign(ctx, req.CampaignId, req.RequestedBy)
+	if err != nil {
+		h.logger.Error("Failed to start campaign", "error", err, "campaign_id", req.CampaignId, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to start campaign")
+	}
+	return convertCampaignToProto(*campaign), nil
+}
+
+// GetCampaignReport compares the delivery and read rates of a campaign's variants
+func (h *GrpcMessageHandler) GetCampaignReport(ctx context.Context, req *pb.GetCampaignReportRequest) (*pb.CampaignReport, error) {
+	reports, err := h.campaigns.GetCampaignReport(ctx, req.CampaignId)
+	if err != nil {
+		h.logger.Error("Failed to get campaign report", "error", err, "campaign_id", req.CampaignId)
+		return nil, GRPCError(err, "failed to get campaign report")
+	}
+
+	resp := &pb.CampaignReport{CampaignId: req.CampaignId, Variants: make([]*pb.VariantReport, 0, len(reports))}
+	for _, report := range reports {
+		resp.Variants = append(resp.Variants, &pb.VariantReport{
+			Variant: convertCampaignVariantToProto(report.Variant),
+			Stats:   convertStatsBucketToProto(report.Stats),
+		})
+	}
+	return resp, nil
+}
+
+// ImportCampaignAudience reads a CSV streamed in chunks into a draft campaign's audience. The
+// campaign is named by the first chunk.
+func (h *GrpcMessageHandler) ImportCampaignAudience(stream pb.WhatsAppService_ImportCampaignAudienceServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF || (err == nil && first.CampaignId == 0) {
+		return status.Error(codes.InvalidArgument, "campaign_id is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.campaigns.ImportAudience(stream.Context(), first.CampaignId, &importStreamReader{stream: stream, data: first.Data})
+	if err != nil {
+		h.logger.Error("Failed to import campaign audience", "error", err, "campaign_id", first.CampaignId)
+		return GRPCError(err, "failed to import campaign audience")
+	}
+	return stream.SendAndClose(convertAudienceImportToProto(summary))
+}
+
+// importStreamReader reads the data of an import stream's chunks
+type importStreamReader struct {
+	stream pb.WhatsAppService_ImportCampaignAudienceServer
+	data   []byte
+}
+
+func (r *importStreamReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = chunk.Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// GetCampaign returns a campaign of the caller tenant
+func (h *GrpcMessageHandler) GetCampaign(ctx context.Context, req *pb.GetCampaignRequest) (*pb.Campaign, error) {
+	campaign, err := h.campaigns.GetCampaign(ctx, req.CampaignId)
+	if err != nil {
+		h.logger.Error("Failed to get campaign", "error", err, "campaign_id", req.CampaignId)
+		return nil, GRPCError(err, "failed to get campaign")
+	}
+	return convertCampaignToProto(*campaign), nil
+}
+
+// optionalTimestamp converts t, leaving the zero time unset
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// segmentFilterFromProto converts a proto segment filter; nil matches every contact
+func segmentFilterFromProto(filter *pb.SegmentFilter) domain.SegmentFilter {
+	if filter == nil {
+		return domain.SegmentFilter{}
+	}
+	return domain.SegmentFilter{
+		Attributes:   filter.Attributes,
+		Tags:         filter.Tags,
+		ActiveWithin: filter.ActiveWithin.AsDuration(),
+		InactiveFor:  filter.InactiveFor.AsDuration(),
+	}
+}
+
+// convertSegmentToProto converts a domain segment to its proto form
+func convertSegmentToProto(segment domain.Segment) *pb.Segment {
+	resp := &pb.Segment{
+		Id:          segment.ID,
+		Name:        segment.Name,
+		Kind:        segmentKindToProto(segment.Kind),
+		ListSize:    segment.ListSize,
+		RequestedBy: segment.Actor,
+		CreatedAt:   timestamppb.New(segment.CreatedAt),
+	}
+	if segment.Kind == domain.SegmentKindFilter {
+		resp.Filter = &pb.SegmentFilter{
+			Attributes: segment.Filter.Attributes,
+			Tags:       segment.Filter.Tags,
+		}
+		if segment.Filter.ActiveWithin > 0 {
+			resp.Filter.ActiveWithin = durationpb.New(segment.Filter.ActiveWithin)
+		}
+		if segment.Filter.InactiveFor > 0 {
+			resp.Filter.InactiveFor = durationpb.New(segment.Filter.InactiveFor)
+		}
+	}
+	return resp
+}
+
+// convertCampaignToProto converts a domain campaign to its proto form
+func convertCampaignToProto(campaign domain.Campaign) *pb.Campaign {
+	return &pb.Campaign{
+		Id:           campaign.ID,
+		Name:         campaign.Name,
+		SegmentId:    campaign.SegmentID,
+		TemplateId:   campaign.TemplateID,
+		Parameters:   campaign.Parameters,
+		Status:       campaignStatusToProto(campaign.Status),
+		AudienceSize: campaign.AudienceSize,
+		Sent:         campaign.Sent,
+		Failed:       campaign.Failed,
+		RequestedBy:  campaign.Actor,
+		CreatedAt:    timestamppb.New(campaign.CreatedAt),
+		StartedAt:    optionalTimestamp(campaign.StartedAt),
+		CompletedAt:  optionalTimestamp(campaign.CompletedAt),
+		Variants:     convertCampaignVariantsToProto(campaign.Variants),
+	}
+}
+
+// convertCampaignVariantsToProto converts campaign variants to their protobuf form
+func convertCampaignVariantsToProto(variants []domain.CampaignVariant) []*pb.CampaignVariant {
+	converted := make([]*pb.CampaignVariant, 0, len(variants))
+	for _, variant := range variants {
+		converted = append(converted, convertCampaignVariantToProto(variant))
+	}
+	return converted
+}
+
+func convertCampaignVariantToProto(variant domain.CampaignVariant) *pb.CampaignVariant {
+	return &pb.CampaignVariant{
+		Name:       variant.Name,
+		TemplateId: variant.TemplateID,
+		Parameters: variant.Parameters,
+		Weight:     int32(variant.Weight),
+	}
+}
+
+// convertAudienceImportToProto converts an import summary to its protobuf form
+func convertAudienceImportToProto(summary *domain.AudienceImport) *pb.ImportCampaignAudienceResponse {
+	resp := &pb.ImportCampaignAudienceResponse{
+		Rows:       summary.Rows,
+		Imported:   summary.Imported,
+		Duplicates: summary.Duplicates,
+		Suppressed: summary.Suppressed,
+		Invalid:    summary.Invalid,
+		Errors:     make([]*pb.ImportRowError, 0, len(summary.Errors)),
+	}
+	for _, rowErr := range summary.Errors {
+		resp.Errors = append(resp.Errors, &pb.ImportRowError{Row: rowErr.Row, PhoneNumber: rowErr.PhoneNumber, Error: rowErr.Message})
+	}
+	return resp
+}
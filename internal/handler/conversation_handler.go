@@ -0,0 +1,60 @@
+// internal/handler/conversation_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// GetConversation returns the caller tenant's conversation with a customer
+func (h *GrpcMessageHandler) GetConversation(ctx context.Context, req *pb.GetConversationRequest) (*pb.Conversation, error) {
+	conversation, err := h.inbound.GetConversation(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, GRPCError(err, "failed to get conversation")
+	}
+	return convertConversationToProto(*conversation), nil
+}
+
+// UpdateHandoff moves a conversation to a new handoff state
+func (h *GrpcMessageHandler) UpdateHandoff(ctx context.Context, req *pb.UpdateHandoffRequest) (*pb.Conversation, error) {
+	conversation, err := h.inbound.UpdateHandoff(ctx, req.ConversationId, handoffStatusFromProto(req.Status), req.Agent)
+	if err != nil {
+		h.logger.Error("Failed to update handoff", "error", err, "conversation_id", req.ConversationId)
+		return nil, GRPCError(err, "failed to update handoff")
+	}
+	return convertConversationToProto(*conversation), nil
+}
+
+// SendTypingIndicator shows the customer a reply to their message is being written
+func (h *GrpcMessageHandler) SendTypingIndicator(ctx context.Context, req *pb.SendTypingIndicatorRequest) (*pb.SendTypingIndicatorResponse, error) {
+	if err := h.inbound.SendTypingIndicator(ctx, req.MessageId); err != nil {
+		return nil, GRPCError(err, "failed to send typing indicator")
+	}
+	return &pb.SendTypingIndicatorResponse{}, nil
+}
+
+// convertConversationToProto converts a domain.Conversation
+func convertConversationToProto(conversation domain.Conversation) *pb.Conversation {
+	resp := &pb.Conversation{
+		ConversationId: conversation.ID,
+		PhoneNumber:    conversation.PhoneNumber,
+		ProfileName:    conversation.ProfileName,
+		HandoffStatus:  handoffStatusToProto(conversation.HandoffStatus),
+		HandoffReason:  conversation.HandoffReason,
+		HandoffAgent:   conversation.HandoffAgent,
+	}
+	if !conversation.HandoffAt.IsZero() {
+		resp.HandoffAt = timestamppb.New(conversation.HandoffAt)
+	}
+	if !conversation.LastInboundAt.IsZero() {
+		resp.LastInboundAt = timestamppb.New(conversation.LastInboundAt)
+	}
+	if !conversation.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(conversation.CreatedAt)
+	}
+	return resp
+}
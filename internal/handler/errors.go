@@ -0,0 +1,72 @@
+// internal/handler/errors.go
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+)
+
+// errorMapping ties a domain error kind to its gRPC code and HTTP status
+type errorMapping struct {
+	kind       error
+	code       codes.Code
+	httpStatus int
+}
+
+var errorMappings = []errorMapping{
+	{domain.ErrNotFound, codes.NotFound, http.StatusNotFound},
+	{domain.ErrValidation, codes.InvalidArgument, http.StatusBadRequest},
+	{domain.ErrConflict, codes.AlreadyExists, http.StatusConflict},
+	{domain.ErrUnauthenticated, codes.Unauthenticated, http.StatusUnauthorized},
+	{domain.ErrPermissionDenied, codes.PermissionDenied, http.StatusForbidden},
+	{domain.ErrProviderRateLimited, codes.ResourceExhausted, http.StatusTooManyRequests},
+	{domain.ErrProviderUnavailable, codes.Unavailable, http.StatusServiceUnavailable},
+	{domain.ErrQuotaExceeded, codes.ResourceExhausted, http.StatusTooManyRequests},
+	{domain.ErrFailedPrecondition, codes.FailedPrecondition, http.StatusBadRequest},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, http.StatusGatewayTimeout},
+	{context.Canceled, codes.Canceled, 499},
+}
+
+// GRPCError converts an error into a gRPC status error. Domain errors keep their message;
+// anything unrecognised becomes Internal with fallback as the message.
+func GRPCError(err error, fallback string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.kind) {
+			return status.Error(m.code, domain.ErrorMessage(err, m.kind.Error()))
+		}
+	}
+	return status.Error(codes.Internal, fallback)
+}
+
+// HTTPStatus returns the HTTP status code for an error
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if s, ok := status.FromError(err); ok {
+		for _, m := range errorMappings {
+			if m.code == s.Code() {
+				return m.httpStatus
+			}
+		}
+		return http.StatusInternalServerError
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.kind) {
+			return m.httpStatus
+		}
+	}
+	return http.StatusInternalServerError
+}
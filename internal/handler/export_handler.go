@@ -0,0 +1,157 @@
+// internal/handler/export_handler.go
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// exportColumns is the CSV header written by the export endpoint
+var exportColumns = []string{
+	"id", "phone_number", "template_id", "parameters", "order_id", "customer_id",
+	"status", "error_message", "external_id", "created_at", "updated_at",
+}
+
+// ExportHandler streams message exports over HTTP
+type ExportHandler struct {
+	messageService service.MessageService
+	hasher         utils.PhoneNumberHasher
+	logger         utils.Logger
+}
+
+// NewExportHandler creates a new export handler. Phone numbers in the export are
+// passed through hasher so downstream systems only see pseudonyms when enabled, and
+// content snapshots are left out.
+func NewExportHandler(messageService service.MessageService, hasher utils.PhoneNumberHasher, logger utils.Logger) *ExportHandler {
+	return &ExportHandler{
+		messageService: messageService,
+		hasher:         hasher,
+		logger:         logger,
+	}
+}
+
+// HandleExport streams messages matching the query filters as CSV (default) or JSONL
+func (h *ExportHandler) HandleExport(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+
+	filter, err := parseMessageFilter(
+		c.Query("order_id"), c.Query("customer_id"), c.Query("phone_number"),
+		c.Query("status"), c.Query("template_id"), c.Query("created_after"), c.Query("created_before"),
+	)
+	if err != nil {
+		c.JSON(HTTPStatus(err), gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+
+	chunkSize, _ := strconv.Atoi(c.Query("chunk_size"))
+
+	// Headers are committed with the first chunk, so later failures can only abort the stream
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=messages.csv")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", "attachment; filename=messages.jsonl")
+	}
+	c.Status(http.StatusOK)
+
+	// Exports can outlive the server's write timeout, so lift the deadline for this response
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+	if format == "csv" {
+		if err := csvWriter.Write(exportColumns); err != nil {
+			h.logger.Error("Failed to write export header", "error", err)
+			return
+		}
+	}
+
+	err = h.messageService.ExportMessages(c.Request.Context(), filter, exportChunkSize(chunkSize), func(messages []*domain.Message) error {
+		for _, msg := range messages {
+			pseudonymizeExport(msg, h.hasher)
+			if format == "csv" {
+				if err := csvWriter.Write(messageToCSVRecord(msg)); err != nil {
+					return err
+				}
+			} else if err := encoder.Encode(msg); err != nil {
+				return err
+			}
+		}
+
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Failed to export messages", "error", err, "format", format)
+		c.Abort()
+	}
+}
+
+// pseudonymizeExport removes what an exported message must not carry in cleartext: the phone
+// number is passed through hasher, as are string parameters repeating it, and the content
+// snapshot, which holds the recipient and every rendered value, is dropped
+func pseudonymizeExport(msg *domain.Message, hasher utils.PhoneNumberHasher) {
+	digits := phoneDigits(msg.PhoneNumber)
+	msg.PhoneNumber = hasher.Hash(msg.PhoneNumber)
+	msg.ContentSnapshot = ""
+	if digits == "" {
+		return
+	}
+	for key, value := range msg.Parameters {
+		if text, ok := value.(string); ok && strings.Contains(phoneDigits(text), digits) {
+			msg.Parameters[key] = hasher.Hash(text)
+		}
+	}
+}
+
+// phoneDigits keeps the digits of a phone number, however it is formatted
+func phoneDigits(phoneNumber string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phoneNumber)
+}
+
+// messageToCSVRecord converts a message to a CSV row matching exportColumns
+func messageToCSVRecord(msg *domain.Message) []string {
+	parameters, err := json.Marshal(msg.Parameters)
+	if err != nil {
+		parameters = []byte("{}")
+	}
+
+	return []string{
+		strconv.FormatInt(msg.ID, 10),
+		msg.PhoneNumber,
+		msg.TemplateID,
+		string(parameters),
+		msg.OrderID,
+		msg.CustomerID,
+		msg.Status,
+		msg.ErrorMessage,
+		msg.ExternalID,
+		msg.CreatedAt.Format(time.RFC3339),
+		msg.UpdatedAt.Format(time.RFC3339),
+	}
+}
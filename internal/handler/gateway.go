@@ -0,0 +1,57 @@
+// internal/handler/gateway.go
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "messaging-microservice/proto"
+)
+
+// gatewayExportPath is the gateway route of ExportMessages
+const gatewayExportPath = "/v1/messages:export"
+
+// NewGatewayHandler creates the REST/JSON facade for the gRPC API. Requests are
+// proxied to grpcEndpoint so they pass through the same interceptors as native
+// gRPC calls; dialOpts are added to the connection, e.g. to match the server's
+// message size limits.
+func NewGatewayHandler(ctx context.Context, grpcEndpoint string, dialOpts ...grpc.DialOption) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+	)
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOpts...)
+	if err := pb.RegisterWhatsAppServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Exports stream for as long as they take, as with the HTTP export endpoint
+		if r.URL.Path == gatewayExportPath {
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+// gatewayHeaderMatcher forwards the API key and tenant headers in addition to the default set
+func gatewayHeaderMatcher(key string) (string, bool) {
+	for _, forwarded := range []string{apiKeyMetadataKey, tenantMetadataKey} {
+		if strings.EqualFold(key, forwarded) {
+			return forwarded, true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// HandleOpenAPISpec serves the OpenAPI document of the REST gateway
+func HandleOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", pb.OpenAPISpec)
+}
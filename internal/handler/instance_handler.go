@@ -0,0 +1,68 @@
+// internal/handler/instance_handler.go
+package handler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// InstanceInfo is what GetInstanceStatus reports about the replica. Sends and Leader are nil
+// when sends are not limited or every replica runs every worker. Logs are the replica's log
+// levels changed by SetLogLevel; nil when they can't be changed.
+type InstanceInfo struct {
+	ID        string
+	StartedAt time.Time
+	Consumers []queue.Consumer
+	Sends     *SendLimiter
+	Leader    *service.LeaderElector
+	Logs      *utils.LogLevels
+}
+
+// GetInstanceStatus returns the partitions, in-flight work and utilization of the replica's
+// consumers, its send slots and the singleton workers it runs
+func (h *GrpcMessageHandler) GetInstanceStatus(ctx context.Context, req *pb.GetInstanceStatusRequest) (*pb.InstanceStatus, error) {
+	instance := h.info.Instance
+	resp := &pb.InstanceStatus{
+		InstanceId: instance.ID,
+		StartedAt:  timestamppb.New(instance.StartedAt),
+		Consumers:  make([]*pb.ConsumerStatus, 0, len(instance.Consumers)),
+	}
+
+	for _, consumer := range instance.Consumers {
+		stats := consumer.Stats()
+		status := &pb.ConsumerStatus{
+			Topic:       stats.Topic,
+			Lag:         stats.Lag,
+			InFlight:    stats.InFlight,
+			Utilization: stats.Utilization,
+			Handled:     stats.Handled,
+			Failed:      stats.Failed,
+			Partitions:  make([]*pb.PartitionAssignment, 0, len(stats.Partitions)),
+		}
+		for _, partition := range stats.Partitions {
+			status.Partitions = append(status.Partitions, &pb.PartitionAssignment{
+				Partition:  int32(partition.Partition),
+				Offset:     partition.Offset,
+				LastReadAt: timestamppb.New(partition.LastReadAt),
+			})
+		}
+		resp.Consumers = append(resp.Consumers, status)
+	}
+
+	if instance.Sends != nil {
+		resp.SendsInFlight = int32(instance.Sends.InFlight())
+		resp.SendsQueued = int32(instance.Sends.Queued())
+		resp.MaxInFlightSends = int32(h.info.MaxInFlightSends)
+	}
+	if instance.Leader != nil {
+		resp.LeadingWorkers = instance.Leader.Leading()
+	}
+	return resp, nil
+}
@@ -0,0 +1,121 @@
+// internal/handler/load_shedding.go
+package handler
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// ErrOverloaded is returned when the send path is saturated
+var ErrOverloaded = status.Error(codes.ResourceExhausted, "service is overloaded, retry later")
+
+// SendLimiter bounds the number of concurrent sends. Up to maxInFlight requests
+// run at once and up to maxQueued more may wait for a slot; anything beyond
+// that watermark, or waiting longer than queueTimeout, is shed.
+type SendLimiter struct {
+	slots        chan struct{}
+	queued       int64
+	maxQueued    int64
+	queueTimeout time.Duration
+	retryAfter   time.Duration
+}
+
+// NewSendLimiter creates a new send limiter
+func NewSendLimiter(maxInFlight, maxQueued int, queueTimeout, retryAfter time.Duration) *SendLimiter {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	if maxQueued < 0 {
+		maxQueued = 0
+	}
+
+	return &SendLimiter{
+		slots:        make(chan struct{}, maxInFlight),
+		maxQueued:    int64(maxQueued),
+		queueTimeout: queueTimeout,
+		retryAfter:   retryAfter,
+	}
+}
+
+// Acquire reserves a slot, returning false when the request should be shed.
+// Callers must call Release after a successful Acquire.
+func (l *SendLimiter) Acquire(ctx context.Context) bool {
+	// Fast path: a slot is free
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	// Shed immediately once the queue watermark is reached
+	if atomic.AddInt64(&l.queued, 1) > l.maxQueued {
+		atomic.AddInt64(&l.queued, -1)
+		return false
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release frees a slot acquired with Acquire
+func (l *SendLimiter) Release() {
+	<-l.slots
+}
+
+// InFlight returns the number of requests currently holding a slot
+func (l *SendLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Queued returns the number of requests waiting for a slot
+func (l *SendLimiter) Queued() int {
+	return int(atomic.LoadInt64(&l.queued))
+}
+
+// RetryAfter returns the back-off hint sent to shed clients
+func (l *SendLimiter) RetryAfter() time.Duration {
+	return l.retryAfter
+}
+
+// SendLimitInterceptor applies the limiter to the send RPCs and rejects excess
+// requests with ResourceExhausted and a retry-after header (in seconds)
+func SendLimitInterceptor(limiter *SendLimiter, logger utils.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod != pb.WhatsAppService_SendTemplateMessage_FullMethodName {
+			return handler(ctx, req)
+		}
+
+		if !limiter.Acquire(ctx) {
+			logger.Warn("Shedding send request", "in_flight", limiter.InFlight(), "queued", limiter.Queued())
+			retryAfter := int(limiter.RetryAfter().Round(time.Second) / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+			return nil, ErrOverloaded
+		}
+		defer limiter.Release()
+
+		return handler(ctx, req)
+	}
+}
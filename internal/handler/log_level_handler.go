@@ -0,0 +1,113 @@
+// internal/handler/log_level_handler.go
+package handler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// defaultDebugTargetDuration is how long a debug target lasts when the request gives no
+// duration, so a forgotten one doesn't keep logging a customer's traffic
+const defaultDebugTargetDuration = 15 * time.Minute
+
+// GetLogLevel returns the log level and debug targets of this replica
+func (h *GrpcMessageHandler) GetLogLevel(ctx context.Context, req *pb.GetLogLevelRequest) (*pb.LogLevel, error) {
+	levels := h.info.Instance.Logs
+	if levels == nil {
+		return nil, status.Error(codes.Unimplemented, "log levels can't be changed on this replica")
+	}
+	return h.convertLogLevelToProto(levels.State()), nil
+}
+
+// SetLogLevel changes the log level of this replica, or enables or disables debug logs of a
+// component or phone number on it. Changes last until the replica restarts.
+func (h *GrpcMessageHandler) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.LogLevel, error) {
+	levels := h.info.Instance.Logs
+	if levels == nil {
+		return nil, status.Error(codes.Unimplemented, "log levels can't be changed on this replica")
+	}
+	if req.Component != "" && req.PhoneNumber != "" {
+		return nil, status.Error(codes.InvalidArgument, "set the debug logs of a component or a phone number, not both")
+	}
+	duration := req.Duration.AsDuration()
+	if duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration must not be negative")
+	}
+
+	field, value, subject := "", "", h.info.Instance.ID
+	switch {
+	case req.Component != "":
+		field, value = utils.LogFieldComponent, req.Component
+		subject += "/" + field + ":" + value
+	case req.PhoneNumber != "":
+		field, value = utils.LogFieldPhoneNumber, req.PhoneNumber
+		subject += "/" + field + ":" + h.hasher.Hash(value)
+	}
+
+	switch {
+	case field == "":
+		if err := levels.SetLevel(req.Level, duration); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	case req.Level == "debug":
+		if duration == 0 {
+			duration = defaultDebugTargetDuration
+		}
+		if err := levels.EnableDebug(field, value, duration); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	case req.Level == "":
+		levels.DisableDebug(field, value)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "the level of a component or phone number is debug, or empty to disable its debug logs")
+	}
+	h.logger.Warn("Changed log level", "level", req.Level, "subject", subject, "duration", duration, "requested_by", req.RequestedBy)
+
+	// The change already happened; a failure to audit it is returned so the caller retries,
+	// which sets the same level again
+	if _, err := h.audit.RecordAuditEntry(ctx, &domain.AuditEntry{
+		Action:      domain.AuditActionSetLogLevel,
+		SubjectType: domain.SubjectLogLevel,
+		Subject:     subject,
+		Actor:       domain.ActorFromContext(ctx, req.RequestedBy),
+		Reason:      req.Level,
+	}); err != nil {
+		h.logger.Error("Failed to audit log level change", "error", err, "subject", subject)
+		return nil, GRPCError(err, "failed to audit log level change")
+	}
+
+	return h.convertLogLevelToProto(levels.State()), nil
+}
+
+// convertLogLevelToProto converts the replica's utils.LogLevelState
+func (h *GrpcMessageHandler) convertLogLevelToProto(state utils.LogLevelState) *pb.LogLevel {
+	resp := &pb.LogLevel{
+		InstanceId:   h.info.Instance.ID,
+		Level:        state.Level,
+		DebugTargets: make([]*pb.DebugLogTarget, 0, len(state.Targets)),
+	}
+	if !state.Until.IsZero() {
+		resp.RevertsAt = timestamppb.New(state.Until)
+	}
+	for _, target := range state.Targets {
+		debugTarget := &pb.DebugLogTarget{}
+		if target.Field == utils.LogFieldComponent {
+			debugTarget.Component = target.Value
+		} else {
+			debugTarget.PhoneNumber = target.Value
+		}
+		if !target.Until.IsZero() {
+			debugTarget.ExpiresAt = timestamppb.New(target.Until)
+		}
+		resp.DebugTargets = append(resp.DebugTargets, debugTarget)
+	}
+	return resp
+}
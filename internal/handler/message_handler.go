@@ -7,9 +7,11 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"messaging-microservice/internal/domain"
 	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
 	"messaging-microservice/pkg/utils"
 	pb "messaging-microservice/proto"
 )
@@ -18,25 +20,50 @@ import (
 type GrpcMessageHandler struct {
 	pb.UnimplementedWhatsAppServiceServer
 	messageService service.MessageService
+	privacyService service.PrivacyService
+	quotaService   service.QuotaService
+	pauseService   service.PauseService
+	templates      service.TemplateSwitch
+	countries      service.CountryPolicy
+	inbound        service.InboundService
+	accounts       service.AccountQualityService
+	audit          service.AuditLog
+	captures       service.ProviderCaptureService
+	optIns         service.OptInService
+	campaigns      service.CampaignService
+	previews       service.TemplatePreviewService
+	info           ServiceInfo
+	hasher         utils.PhoneNumberHasher
 	logger         utils.Logger
 }
 
-// NewGrpcMessageHandler creates a new gRPC message handler
-func NewGrpcMessageHandler(messageService service.MessageService, logger utils.Logger) *GrpcMessageHandler {
+// NewGrpcMessageHandler creates a new gRPC message handler. The hasher is applied
+// to phone numbers in exports, which feed analytics rather than operational lookups.
+func NewGrpcMessageHandler(messageService service.MessageService, privacyService service.PrivacyService, quotaService service.QuotaService, pauseService service.PauseService, templates service.TemplateSwitch, countries service.CountryPolicy, inbound service.InboundService, accounts service.AccountQualityService, audit service.AuditLog, captures service.ProviderCaptureService, optIns service.OptInService, campaigns service.CampaignService, previews service.TemplatePreviewService, info ServiceInfo, hasher utils.PhoneNumberHasher, logger utils.Logger) *GrpcMessageHandler {
 	return &GrpcMessageHandler{
 		messageService: messageService,
+		privacyService: privacyService,
+		quotaService:   quotaService,
+		pauseService:   pauseService,
+		templates:      templates,
+		countries:      countries,
+		inbound:        inbound,
+		accounts:       accounts,
+		audit:          audit,
+		captures:       captures,
+		optIns:         optIns,
+		campaigns:      campaigns,
+		previews:       previews,
+		info:           info,
+		hasher:         hasher,
 		logger:         logger,
 	}
 }
 
 // SendTemplateMessage sends a WhatsApp template message
 func (h *GrpcMessageHandler) SendTemplateMessage(ctx context.Context, req *pb.SendTemplateMessageRequest) (*pb.SendTemplateMessageResponse, error) {
-	// Validate request
-	if req.PhoneNumber == "" {
-		return nil, status.Error(codes.InvalidArgument, "phone_number is required")
-	}
-	if req.TemplateId == "" {
-		return nil, status.Error(codes.InvalidArgument, "template_id is required")
+	if req.TemplateId == meta.CTAURLTemplate || meta.IsProductTemplate(req.TemplateId) {
+		return nil, status.Error(codes.InvalidArgument, "use SendCTAURLMessage, SendProductMessage or SendProductListMessage to send interactive messages")
 	}
 
 	// Convert parameters from proto map to regular map
@@ -44,22 +71,74 @@ func (h *GrpcMessageHandler) SendTemplateMessage(ctx context.Context, req *pb.Se
 	for key, value := range req.Parameters {
 		parameters[key] = value
 	}
+	for index, suffix := range req.ButtonUrls {
+		parameters[meta.ButtonURLParameter(int(index))] = suffix
+	}
+	if err := meta.ValidateButtonURLParameters(parameters); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.RecipientTimezone != "" {
+		ctx = domain.WithRecipientTimezone(ctx, req.RecipientTimezone)
+	}
+	if req.ExpiresAt != nil {
+		if err := req.ExpiresAt.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "expires_at is invalid: "+err.Error())
+		}
+		if !req.ExpiresAt.AsTime().After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expires_at must be in the future")
+		}
+		ctx = domain.WithExpiresAt(ctx, req.ExpiresAt.AsTime())
+	}
+	if req.Synchronous {
+		ctx = domain.WithSynchronous(ctx)
+	}
 
 	// Call service
 	msg, err := h.messageService.SendTemplateMessage(ctx, req.PhoneNumber, req.TemplateId, parameters, req.OrderId, req.CustomerId)
 	if err != nil {
 		h.logger.Error("Failed to send template message", "error", err)
-		return nil, status.Error(codes.Internal, "failed to send message: "+err.Error())
+		return nil, GRPCError(err, "failed to send message")
 	}
 
-	// Create response
-	resp := &pb.SendTemplateMessageResponse{
+	return newSendResponse(msg), nil
+}
+
+// newSendResponse reports a message accepted by one of the send RPCs
+func newSendResponse(msg *domain.Message) *pb.SendTemplateMessageResponse {
+	return &pb.SendTemplateMessageResponse{
 		MessageId:  msg.ID,
 		Status:     msg.Status,
 		ExternalId: msg.ExternalID,
+		StatusCode: statusToProto(msg.Status),
 	}
+}
 
-	return resp, nil
+// SendCTAURLMessage sends an interactive message with a URL button. It is stored and queued as
+// a message of the meta.CTAURLTemplate pseudo-template.
+func (h *GrpcMessageHandler) SendCTAURLMessage(ctx context.Context, req *pb.SendCTAURLMessageRequest) (*pb.SendTemplateMessageResponse, error) {
+	parameters := map[string]interface{}{
+		meta.CTAURLBody:        req.Body,
+		meta.CTAURLDisplayText: req.DisplayText,
+		meta.CTAURLLink:        req.Url,
+	}
+	if req.Header != "" {
+		parameters[meta.CTAURLHeader] = req.Header
+	}
+	if req.Footer != "" {
+		parameters[meta.CTAURLFooter] = req.Footer
+	}
+	if err := meta.ValidateCTAURL(parameters); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	msg, err := h.messageService.SendTemplateMessage(ctx, req.PhoneNumber, meta.CTAURLTemplate, parameters, req.OrderId, req.CustomerId)
+	if err != nil {
+		h.logger.Error("Failed to send CTA URL message", "error", err)
+		return nil, GRPCError(err, "failed to send message")
+	}
+
+	return newSendResponse(msg), nil
 }
 
 // GetMessage retrieves a message by ID
@@ -68,36 +147,138 @@ func (h *GrpcMessageHandler) GetMessage(ctx context.Context, req *pb.GetMessageR
 	msg, err := h.messageService.GetMessageByID(ctx, req.MessageId)
 	if err != nil {
 		h.logger.Error("Failed to get message", "error", err, "message_id", req.MessageId)
-		return nil, status.Error(codes.NotFound, "message not found")
+		return nil, GRPCError(err, "failed to get message")
 	}
 
 	// Convert to proto response
-	resp := convertMessageToProto(msg)
+	resp := convertMessageToProto(msg, h.info.Provider)
 	return resp, nil
 }
 
+// RetryMessage sends a failed message again as a new attempt
+func (h *GrpcMessageHandler) RetryMessage(ctx context.Context, req *pb.RetryMessageRequest) (*pb.MessageResponse, error) {
+	var parameters map[string]interface{}
+	if len(req.Parameters) > 0 {
+		parameters = make(map[string]interface{}, len(req.Parameters))
+		for key, value := range req.Parameters {
+			parameters[key] = value
+		}
+	}
+
+	msg, err := h.messageService.RetryMessage(ctx, req.MessageId, req.PhoneNumber, parameters)
+	if err != nil {
+		h.logger.Error("Failed to retry message", "error", err, "message_id", req.MessageId)
+		return nil, GRPCError(err, "failed to retry message")
+	}
+
+	return convertMessageToProto(msg, h.info.Provider), nil
+}
+
+// DeleteMessage soft deletes a message, or hard deletes it for admins
+func (h *GrpcMessageHandler) DeleteMessage(ctx context.Context, req *pb.DeleteMessageRequest) (*pb.DeleteMessageResponse, error) {
+	if err := h.messageService.DeleteMessage(ctx, req.MessageId, req.HardDelete, req.RequestedBy, req.Reason); err != nil {
+		h.logger.Error("Failed to delete message", "error", err, "message_id", req.MessageId, "hard_delete", req.HardDelete)
+		return nil, GRPCError(err, "failed to delete message")
+	}
+
+	return &pb.DeleteMessageResponse{}, nil
+}
+
+// GetMessageByExternalID retrieves a message by its provider external ID
+func (h *GrpcMessageHandler) GetMessageByExternalID(ctx context.Context, req *pb.GetMessageByExternalIDRequest) (*pb.MessageResponse, error) {
+	// Call service
+	msg, err := h.messageService.GetMessageByExternalID(ctx, req.ExternalId)
+	if err != nil {
+		h.logger.Error("Failed to get message by external ID", "error", err, "external_id", req.ExternalId)
+		return nil, GRPCError(err, "failed to get message")
+	}
+
+	// Convert to proto response
+	return convertMessageToProto(msg, h.info.Provider), nil
+}
+
+// GetMessages retrieves many messages by ID in one call
+func (h *GrpcMessageHandler) GetMessages(ctx context.Context, req *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error) {
+	messages, err := h.messageService.GetMessages(ctx, req.MessageIds)
+	if err != nil {
+		h.logger.Error("Failed to get messages", "error", err, "count", len(req.MessageIds))
+		return nil, GRPCError(err, "failed to get messages")
+	}
+
+	return h.newGetMessagesResponse(messages), nil
+}
+
+// GetMessagesByExternalIDs retrieves many messages by provider external ID in one call
+func (h *GrpcMessageHandler) GetMessagesByExternalIDs(ctx context.Context, req *pb.GetMessagesByExternalIDsRequest) (*pb.GetMessagesResponse, error) {
+	messages, err := h.messageService.GetMessagesByExternalIDs(ctx, req.ExternalIds)
+	if err != nil {
+		h.logger.Error("Failed to get messages by external ID", "error", err, "count", len(req.ExternalIds))
+		return nil, GRPCError(err, "failed to get messages")
+	}
+
+	return h.newGetMessagesResponse(messages), nil
+}
+
+// newGetMessagesResponse converts the messages of a batch lookup
+func (h *GrpcMessageHandler) newGetMessagesResponse(messages []*domain.Message) *pb.GetMessagesResponse {
+	protoMessages := make([]*pb.MessageResponse, 0, len(messages))
+	for _, msg := range messages {
+		protoMessages = append(protoMessages, convertMessageToProto(msg, h.info.Provider))
+	}
+	return &pb.GetMessagesResponse{Messages: protoMessages}
+}
+
+// GetMessagesByOrderID retrieves all messages sent for an order
+func (h *GrpcMessageHandler) GetMessagesByOrderID(ctx context.Context, req *pb.GetMessagesByOrderIDRequest) (*pb.ListMessagesResponse, error) {
+	// Call service
+	messages, err := h.messageService.GetMessagesByOrderID(ctx, req.OrderId)
+	if err != nil {
+		h.logger.Error("Failed to get messages by order ID", "error", err, "order_id", req.OrderId)
+		return nil, GRPCError(err, "failed to get messages")
+	}
+
+	// Convert to proto response
+	protoMessages := make([]*pb.MessageResponse, 0, len(messages))
+	for _, msg := range messages {
+		protoMessages = append(protoMessages, convertMessageToProto(msg, h.info.Provider))
+	}
+
+	return &pb.ListMessagesResponse{
+		Messages:   protoMessages,
+		TotalCount: int32(len(protoMessages)),
+	}, nil
+}
+
 // ListMessages retrieves a list of messages
 func (h *GrpcMessageHandler) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) (*pb.ListMessagesResponse, error) {
 	// Set default limit if not provided
 	limit := int(req.Limit)
 	if limit <= 0 {
-		limit = 10
+		limit = defaultPageSize
+	}
+
+	// Build filter
+	filter, err := parseMessageFilter(req.OrderId, req.CustomerId, req.PhoneNumber, req.Status, req.TemplateId, req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTimestampRange(&filter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return nil, err
 	}
+	filter.IncludeDeleted = req.IncludeDeleted
 
 	// Call service
-	messages, err := h.messageService.ListMessages(ctx, req.OrderId, req.CustomerId, req.PhoneNumber, limit, int(req.Offset))
+	sort := domain.MessageSort{By: req.SortBy, Order: req.SortOrder}
+	messages, totalCount, err := h.messageService.ListMessages(ctx, filter, sort, limit, int(req.Offset))
 	if err != nil {
 		h.logger.Error("Failed to list messages", "error", err)
-		return nil, status.Error(codes.Internal, "failed to list messages: "+err.Error())
+		return nil, GRPCError(err, "failed to list messages")
 	}
 
-	// Count total (in a real implementation, this would be a separate query)
-	totalCount := len(messages)
-
 	// Convert to proto response
 	protoMessages := make([]*pb.MessageResponse, 0, len(messages))
 	for _, msg := range messages {
-		protoMessages = append(protoMessages, convertMessageToProto(msg))
+		protoMessages = append(protoMessages, convertMessageToProto(msg, h.info.Provider))
 	}
 
 	// Create response
@@ -109,8 +290,104 @@ func (h *GrpcMessageHandler) ListMessages(ctx context.Context, req *pb.ListMessa
 	return resp, nil
 }
 
+// ExportMessages streams all messages matching the filters
+func (h *GrpcMessageHandler) ExportMessages(req *pb.ExportMessagesRequest, stream pb.WhatsAppService_ExportMessagesServer) error {
+	filter, err := parseMessageFilter(req.OrderId, req.CustomerId, req.PhoneNumber, req.Status, req.TemplateId, req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return err
+	}
+	if err := applyTimestampRange(&filter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return err
+	}
+
+	err = h.messageService.ExportMessages(stream.Context(), filter, exportChunkSize(int(req.ChunkSize)), func(messages []*domain.Message) error {
+		for _, msg := range messages {
+			pseudonymizeExport(msg, h.hasher)
+			if err := stream.Send(convertMessageToProto(msg, h.info.Provider)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Failed to export messages", "error", err)
+		return GRPCError(err, "failed to export messages")
+	}
+
+	return nil
+}
+
+// SearchMessages finds messages by parameter values and text, newest first
+func (h *GrpcMessageHandler) SearchMessages(ctx context.Context, req *pb.SearchMessagesRequest) (*pb.SearchMessagesResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var filter domain.MessageFilter
+	if err := applyTimestampRange(&filter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return nil, err
+	}
+
+	messages, err := h.messageService.SearchMessages(ctx, req.Query, filter, limit, int(req.Offset))
+	if err != nil {
+		h.logger.Error("Failed to search messages", "error", err)
+		return nil, GRPCError(err, "failed to search messages")
+	}
+
+	protoMessages := make([]*pb.MessageResponse, 0, len(messages))
+	for _, msg := range messages {
+		protoMessages = append(protoMessages, convertMessageToProto(msg, h.info.Provider))
+	}
+	return &pb.SearchMessagesResponse{Messages: protoMessages}, nil
+}
+
+// parseMessageFilter builds a domain.MessageFilter from request fields, validating timestamps
+func parseMessageFilter(orderID, customerID, phoneNumber, msgStatus, templateID, createdAfter, createdBefore string) (domain.MessageFilter, error) {
+	filter := domain.MessageFilter{
+		OrderID:     orderID,
+		CustomerID:  customerID,
+		PhoneNumber: phoneNumber,
+		Status:      msgStatus,
+		TemplateID:  templateID,
+	}
+	if createdAfter != "" {
+		t, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "created_after must be an RFC3339 timestamp")
+		}
+		filter.CreatedAfter = t
+	}
+	if createdBefore != "" {
+		t, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "created_before must be an RFC3339 timestamp")
+		}
+		filter.CreatedBefore = t
+	}
+	return filter, nil
+}
+
+// applyTimestampRange sets the creation range from typed timestamps, which take
+// precedence over the deprecated RFC3339 string fields
+func applyTimestampRange(filter *domain.MessageFilter, createdAfter, createdBefore *timestamppb.Timestamp) error {
+	if createdAfter != nil {
+		if err := createdAfter.CheckValid(); err != nil {
+			return status.Error(codes.InvalidArgument, "created_after_ts is invalid: "+err.Error())
+		}
+		filter.CreatedAfter = createdAfter.AsTime()
+	}
+	if createdBefore != nil {
+		if err := createdBefore.CheckValid(); err != nil {
+			return status.Error(codes.InvalidArgument, "created_before_ts is invalid: "+err.Error())
+		}
+		filter.CreatedBefore = createdBefore.AsTime()
+	}
+	return nil
+}
+
 // Helper function to convert a domain.Message to pb.MessageResponse
-func convertMessageToProto(msg *domain.Message) *pb.MessageResponse {
+func convertMessageToProto(msg *domain.Message, provider string) *pb.MessageResponse {
 	// Convert parameters from map[string]interface{} to map[string]string
 	parameters := make(map[string]string)
 	for key, value := range msg.Parameters {
@@ -122,17 +399,31 @@ func convertMessageToProto(msg *domain.Message) *pb.MessageResponse {
 		}
 	}
 
-	return &pb.MessageResponse{
-		Id:           msg.ID,
-		PhoneNumber:  msg.PhoneNumber,
-		TemplateId:   msg.TemplateID,
-		Parameters:   parameters,
-		OrderId:      msg.OrderID,
-		CustomerId:   msg.CustomerID,
-		Status:       msg.Status,
-		ErrorMessage: msg.ErrorMessage,
-		ExternalId:   msg.ExternalID,
-		CreatedAt:    msg.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:    msg.UpdatedAt.Format(time.RFC3339),
-	}
-}
\ No newline at end of file
+	resp := &pb.MessageResponse{
+		Id:              msg.ID,
+		PhoneNumber:     msg.PhoneNumber,
+		TemplateId:      msg.TemplateID,
+		Parameters:      parameters,
+		OrderId:         msg.OrderID,
+		CustomerId:      msg.CustomerID,
+		Status:          msg.Status,
+		ErrorMessage:    msg.ErrorMessage,
+		ExternalId:      msg.ExternalID,
+		TenantId:        msg.TenantID,
+		CreatedAt:       msg.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       msg.UpdatedAt.Format(time.RFC3339),
+		StatusCode:      statusToProto(msg.Status),
+		ErrorDetail:     errorDetailToProto(provider, msg),
+		ContentSnapshot: msg.ContentSnapshot,
+		CreatedAtTs:     timestamppb.New(msg.CreatedAt),
+		UpdatedAtTs:     timestamppb.New(msg.UpdatedAt),
+	}
+	resp.Attempt, resp.RetryOfId = int32(msg.Attempt), msg.RetryOfID
+	if !msg.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(msg.ExpiresAt)
+	}
+	if !msg.DeletedAt.IsZero() {
+		resp.DeletedAt = timestamppb.New(msg.DeletedAt)
+	}
+	return resp
+}
@@ -0,0 +1,66 @@
+// internal/handler/opt_in_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// RecordOptIn records an opt-in or opt-out of a contact of the caller tenant
+func (h *GrpcMessageHandler) RecordOptIn(ctx context.Context, req *pb.RecordOptInRequest) (*pb.OptInEvent, error) {
+	event := domain.OptInEvent{
+		PhoneNumber: req.PhoneNumber,
+		Action:      optInActionFromProto(req.Action),
+		Source:      req.Source,
+		Channel:     req.Channel,
+		Evidence:    req.Evidence,
+		Actor:       req.RequestedBy,
+	}
+	if req.OccurredAt != nil {
+		event.OccurredAt = req.OccurredAt.AsTime()
+	}
+
+	recorded, err := h.optIns.Record(ctx, event)
+	if err != nil {
+		h.logger.Error("Failed to record opt-in", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to record opt-in")
+	}
+	return convertOptInEventToProto(*recorded), nil
+}
+
+// ListOptIns returns the opt-in history of a contact of the caller tenant
+func (h *GrpcMessageHandler) ListOptIns(ctx context.Context, req *pb.ListOptInsRequest) (*pb.ListOptInsResponse, error) {
+	events, err := h.optIns.List(ctx, req.PhoneNumber)
+	if err != nil {
+		h.logger.Error("Failed to list opt-ins", "error", err)
+		return nil, GRPCError(err, "failed to list opt-ins")
+	}
+
+	resp := &pb.ListOptInsResponse{
+		Events:  make([]*pb.OptInEvent, 0, len(events)),
+		OptedIn: len(events) > 0 && events[0].Action == domain.OptInActionOptIn,
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, convertOptInEventToProto(event))
+	}
+	return resp, nil
+}
+
+// convertOptInEventToProto converts a domain opt-in event to its proto form
+func convertOptInEventToProto(event domain.OptInEvent) *pb.OptInEvent {
+	return &pb.OptInEvent{
+		Id:          event.ID,
+		PhoneNumber: event.PhoneNumber,
+		Action:      optInActionToProto(event.Action),
+		Source:      event.Source,
+		Channel:     event.Channel,
+		Evidence:    event.Evidence,
+		OccurredAt:  timestamppb.New(event.OccurredAt),
+		RequestedBy: event.Actor,
+		RecordedAt:  timestamppb.New(event.RecordedAt),
+	}
+}
@@ -0,0 +1,46 @@
+// internal/handler/privacy_handler.go
+package handler
+
+import (
+	"context"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// EraseCustomerData anonymizes or deletes all messages of a data subject
+func (h *GrpcMessageHandler) EraseCustomerData(ctx context.Context, req *pb.EraseCustomerDataRequest) (*pb.EraseCustomerDataResponse, error) {
+	subject := domain.DataSubject{CustomerID: req.CustomerId, PhoneNumber: req.PhoneNumber}
+
+	entry, err := h.privacyService.EraseCustomerData(ctx, subject, req.HardDelete, req.RequestedBy, req.Reason)
+	if err != nil {
+		h.logger.Error("Failed to erase customer data", "error", err, "requested_by", req.RequestedBy)
+		return nil, GRPCError(err, "failed to erase customer data")
+	}
+
+	return &pb.EraseCustomerDataResponse{
+		AffectedMessages: entry.AffectedRows,
+		AuditId:          entry.ID,
+	}, nil
+}
+
+// ExportCustomerData streams all messages of a data subject. Phone numbers are not
+// pseudonymized here: the export is the subject's own data.
+func (h *GrpcMessageHandler) ExportCustomerData(req *pb.ExportCustomerDataRequest, stream pb.WhatsAppService_ExportCustomerDataServer) error {
+	subject := domain.DataSubject{CustomerID: req.CustomerId, PhoneNumber: req.PhoneNumber}
+
+	err := h.privacyService.ExportCustomerData(stream.Context(), subject, req.RequestedBy, req.Reason, func(messages []*domain.Message) error {
+		for _, msg := range messages {
+			if err := stream.Send(convertMessageToProto(msg, h.info.Provider)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Failed to export customer data", "error", err, "requested_by", req.RequestedBy)
+		return GRPCError(err, "failed to export customer data")
+	}
+
+	return nil
+}
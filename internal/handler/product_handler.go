@@ -0,0 +1,61 @@
+// internal/handler/product_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/pkg/meta"
+	pb "messaging-microservice/proto"
+)
+
+// SendProductMessage sends one catalog product, stored and queued as a message of the
+// meta.ProductTemplate pseudo-template
+func (h *GrpcMessageHandler) SendProductMessage(ctx context.Context, req *pb.SendProductMessageRequest) (*pb.SendTemplateMessageResponse, error) {
+	parameters := map[string]interface{}{
+		meta.ProductCatalogID:  h.catalogID(req.CatalogId),
+		meta.ProductRetailerID: req.ProductRetailerId,
+	}
+	if req.Body != "" {
+		parameters[meta.ProductBody] = req.Body
+	}
+	if req.Footer != "" {
+		parameters[meta.ProductFooter] = req.Footer
+	}
+	return h.sendProduct(ctx, req.PhoneNumber, meta.ProductTemplate, parameters, req.OrderId, req.CustomerId)
+}
+
+// SendProductListMessage sends catalog products in sections, stored and queued as a message of
+// the meta.ProductListTemplate pseudo-template
+func (h *GrpcMessageHandler) SendProductListMessage(ctx context.Context, req *pb.SendProductListMessageRequest) (*pb.SendTemplateMessageResponse, error) {
+	sections := make([]meta.ProductSection, len(req.Sections))
+	for i, section := range req.Sections {
+		sections[i] = meta.ProductSection{Title: section.Title, ProductRetailerIDs: section.ProductRetailerIds}
+	}
+	parameters := meta.ProductListParameters(h.catalogID(req.CatalogId), req.Header, req.Body, req.Footer, sections)
+	return h.sendProduct(ctx, req.PhoneNumber, meta.ProductListTemplate, parameters, req.OrderId, req.CustomerId)
+}
+
+// sendProduct validates and sends a product message
+func (h *GrpcMessageHandler) sendProduct(ctx context.Context, phoneNumber, templateName string, parameters map[string]interface{}, orderID, customerID string) (*pb.SendTemplateMessageResponse, error) {
+	if err := meta.ValidateProductMessage(templateName, parameters); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	msg, err := h.messageService.SendTemplateMessage(ctx, phoneNumber, templateName, parameters, orderID, customerID)
+	if err != nil {
+		h.logger.Error("Failed to send product message", "error", err, "template_id", templateName)
+		return nil, GRPCError(err, "failed to send message")
+	}
+	return newSendResponse(msg), nil
+}
+
+// catalogID returns the requested catalog, or the configured one
+func (h *GrpcMessageHandler) catalogID(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return h.info.CatalogID
+}
@@ -0,0 +1,208 @@
+// internal/handler/proto_mapping.go
+package handler
+
+import (
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// statusToProto maps a stored message status to the proto enum
+func statusToProto(status string) pb.MessageStatus {
+	switch status {
+	case "queued":
+		return pb.MessageStatus_MESSAGE_STATUS_QUEUED
+	case "processing":
+		return pb.MessageStatus_MESSAGE_STATUS_PROCESSING
+	case "sent":
+		return pb.MessageStatus_MESSAGE_STATUS_SENT
+	case "delivered":
+		return pb.MessageStatus_MESSAGE_STATUS_DELIVERED
+	case "read":
+		return pb.MessageStatus_MESSAGE_STATUS_READ
+	case "failed":
+		return pb.MessageStatus_MESSAGE_STATUS_FAILED
+	case domain.StatusQuotaExceeded:
+		return pb.MessageStatus_MESSAGE_STATUS_QUOTA_EXCEEDED
+	case domain.StatusExpired:
+		return pb.MessageStatus_MESSAGE_STATUS_EXPIRED
+	case domain.StatusRetrying:
+		return pb.MessageStatus_MESSAGE_STATUS_RETRYING
+	default:
+		return pb.MessageStatus_MESSAGE_STATUS_UNSPECIFIED
+	}
+}
+
+// errorCategoryToProto maps a domain error category to the proto enum
+func errorCategoryToProto(category domain.ErrorCategory) pb.ErrorCategory {
+	switch category {
+	case domain.ErrorCategoryUnknown:
+		return pb.ErrorCategory_ERROR_CATEGORY_UNKNOWN
+	case domain.ErrorCategoryInvalidRequest:
+		return pb.ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST
+	case domain.ErrorCategoryRecipient:
+		return pb.ErrorCategory_ERROR_CATEGORY_RECIPIENT
+	case domain.ErrorCategoryTemplate:
+		return pb.ErrorCategory_ERROR_CATEGORY_TEMPLATE
+	case domain.ErrorCategoryRateLimited:
+		return pb.ErrorCategory_ERROR_CATEGORY_RATE_LIMITED
+	case domain.ErrorCategoryAuthentication:
+		return pb.ErrorCategory_ERROR_CATEGORY_AUTHENTICATION
+	case domain.ErrorCategoryProviderUnavailable:
+		return pb.ErrorCategory_ERROR_CATEGORY_PROVIDER_UNAVAILABLE
+	case domain.ErrorCategoryPolicy:
+		return pb.ErrorCategory_ERROR_CATEGORY_POLICY
+	case domain.ErrorCategoryInternal:
+		return pb.ErrorCategory_ERROR_CATEGORY_INTERNAL
+	default:
+		return pb.ErrorCategory_ERROR_CATEGORY_UNSPECIFIED
+	}
+}
+
+// errorDetailToProto builds the typed error detail of a message, or nil if it has no error
+func errorDetailToProto(provider string, msg *domain.Message) *pb.ErrorDetail {
+	detail := domain.NewErrorDetail(provider, msg.ErrorCode, msg.ErrorMessage)
+	if detail == nil {
+		return nil
+	}
+
+	return &pb.ErrorDetail{
+		ProviderCode: detail.ProviderCode,
+		Category:     errorCategoryToProto(detail.Category),
+		Retryable:    detail.Retryable,
+		Message:      detail.Message,
+	}
+}
+
+// pauseScopeToProto maps a domain pause scope to the proto enum
+func pauseScopeToProto(scope string) pb.PauseScope {
+	switch scope {
+	case domain.PauseScopeAll:
+		return pb.PauseScope_PAUSE_SCOPE_ALL
+	case domain.PauseScopeTenant:
+		return pb.PauseScope_PAUSE_SCOPE_TENANT
+	case domain.PauseScopeTemplate:
+		return pb.PauseScope_PAUSE_SCOPE_TEMPLATE
+	default:
+		return pb.PauseScope_PAUSE_SCOPE_UNSPECIFIED
+	}
+}
+
+// pauseScopeFromProto maps the proto enum to a domain pause scope; unspecified maps to ""
+func pauseScopeFromProto(scope pb.PauseScope) string {
+	switch scope {
+	case pb.PauseScope_PAUSE_SCOPE_ALL:
+		return domain.PauseScopeAll
+	case pb.PauseScope_PAUSE_SCOPE_TENANT:
+		return domain.PauseScopeTenant
+	case pb.PauseScope_PAUSE_SCOPE_TEMPLATE:
+		return domain.PauseScopeTemplate
+	default:
+		return ""
+	}
+}
+
+// handoffStatusToProto maps a domain handoff state to the proto enum
+func handoffStatusToProto(status string) pb.HandoffStatus {
+	switch status {
+	case domain.HandoffNone:
+		return pb.HandoffStatus_HANDOFF_STATUS_NONE
+	case domain.HandoffPending:
+		return pb.HandoffStatus_HANDOFF_STATUS_PENDING
+	case domain.HandoffAssigned:
+		return pb.HandoffStatus_HANDOFF_STATUS_ASSIGNED
+	case domain.HandoffResolved:
+		return pb.HandoffStatus_HANDOFF_STATUS_RESOLVED
+	default:
+		return pb.HandoffStatus_HANDOFF_STATUS_UNSPECIFIED
+	}
+}
+
+// handoffStatusFromProto maps the proto enum to a domain handoff state; unspecified maps to ""
+func handoffStatusFromProto(status pb.HandoffStatus) string {
+	switch status {
+	case pb.HandoffStatus_HANDOFF_STATUS_NONE:
+		return domain.HandoffNone
+	case pb.HandoffStatus_HANDOFF_STATUS_PENDING:
+		return domain.HandoffPending
+	case pb.HandoffStatus_HANDOFF_STATUS_ASSIGNED:
+		return domain.HandoffAssigned
+	case pb.HandoffStatus_HANDOFF_STATUS_RESOLVED:
+		return domain.HandoffResolved
+	default:
+		return ""
+	}
+}
+
+// countryActionToProto maps a domain country rule action to the proto enum
+func countryActionToProto(action string) pb.CountryAction {
+	switch action {
+	case domain.CountryActionAllow:
+		return pb.CountryAction_COUNTRY_ACTION_ALLOW
+	case domain.CountryActionBlock:
+		return pb.CountryAction_COUNTRY_ACTION_BLOCK
+	default:
+		return pb.CountryAction_COUNTRY_ACTION_UNSPECIFIED
+	}
+}
+
+// countryActionFromProto maps the proto enum to a domain country rule action; unspecified maps to ""
+func countryActionFromProto(action pb.CountryAction) string {
+	switch action {
+	case pb.CountryAction_COUNTRY_ACTION_ALLOW:
+		return domain.CountryActionAllow
+	case pb.CountryAction_COUNTRY_ACTION_BLOCK:
+		return domain.CountryActionBlock
+	default:
+		return ""
+	}
+}
+
+// optInActionToProto maps a domain opt-in action to the proto enum
+func optInActionToProto(action string) pb.OptInAction {
+	switch action {
+	case domain.OptInActionOptIn:
+		return pb.OptInAction_OPT_IN_ACTION_OPT_IN
+	case domain.OptInActionOptOut:
+		return pb.OptInAction_OPT_IN_ACTION_OPT_OUT
+	default:
+		return pb.OptInAction_OPT_IN_ACTION_UNSPECIFIED
+	}
+}
+
+// optInActionFromProto maps the proto enum to a domain opt-in action; unspecified maps to ""
+func optInActionFromProto(action pb.OptInAction) string {
+	switch action {
+	case pb.OptInAction_OPT_IN_ACTION_OPT_IN:
+		return domain.OptInActionOptIn
+	case pb.OptInAction_OPT_IN_ACTION_OPT_OUT:
+		return domain.OptInActionOptOut
+	default:
+		return ""
+	}
+}
+
+// segmentKindToProto maps a domain segment kind to the proto enum
+func segmentKindToProto(kind string) pb.SegmentKind {
+	switch kind {
+	case domain.SegmentKindFilter:
+		return pb.SegmentKind_SEGMENT_KIND_FILTER
+	case domain.SegmentKindList:
+		return pb.SegmentKind_SEGMENT_KIND_LIST
+	default:
+		return pb.SegmentKind_SEGMENT_KIND_UNSPECIFIED
+	}
+}
+
+// campaignStatusToProto maps a domain campaign status to the proto enum
+func campaignStatusToProto(status string) pb.CampaignStatus {
+	switch status {
+	case domain.CampaignStatusDraft:
+		return pb.CampaignStatus_CAMPAIGN_STATUS_DRAFT
+	case domain.CampaignStatusRunning:
+		return pb.CampaignStatus_CAMPAIGN_STATUS_RUNNING
+	case domain.CampaignStatusCompleted:
+		return pb.CampaignStatus_CAMPAIGN_STATUS_COMPLETED
+	default:
+		return pb.CampaignStatus_CAMPAIGN_STATUS_UNSPECIFIED
+	}
+}
@@ -0,0 +1,40 @@
+// internal/handler/provider_capture_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "messaging-microservice/proto"
+)
+
+// GetProviderCaptures returns the provider calls captured for one of the caller tenant's messages
+func (h *GrpcMessageHandler) GetProviderCaptures(ctx context.Context, req *pb.GetProviderCapturesRequest) (*pb.GetProviderCapturesResponse, error) {
+	captures, err := h.captures.ListProviderCaptures(ctx, req.MessageId)
+	if err != nil {
+		h.logger.Error("Failed to list provider captures", "error", err, "message_id", req.MessageId)
+		return nil, GRPCError(err, "failed to list provider captures")
+	}
+
+	resp := &pb.GetProviderCapturesResponse{
+		Captures:       make([]*pb.ProviderCapture, 0, len(captures)),
+		CaptureEnabled: h.captures.Enabled(),
+	}
+	for _, capture := range captures {
+		resp.Captures = append(resp.Captures, &pb.ProviderCapture{
+			Id:              capture.ID,
+			Method:          capture.Method,
+			Url:             capture.URL,
+			RequestHeaders:  capture.RequestHeaders,
+			RequestBody:     capture.RequestBody,
+			StatusCode:      int32(capture.StatusCode),
+			ResponseHeaders: capture.ResponseHeaders,
+			ResponseBody:    capture.ResponseBody,
+			Error:           capture.Error,
+			DurationMs:      capture.Duration.Milliseconds(),
+			CreatedAt:       timestamppb.New(capture.CreatedAt),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,35 @@
+// internal/handler/quota_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// GetQuota returns the caller's monthly quotas and how much of each remains
+func (h *GrpcMessageHandler) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	usage, err := h.quotaService.Usage(ctx, domain.TenantFromContext(ctx), req.CustomerId)
+	if err != nil {
+		h.logger.Error("Failed to get quota usage", "error", err)
+		return nil, GRPCError(err, "failed to get quota")
+	}
+
+	quotas := make([]*pb.QuotaUsage, 0, len(usage))
+	for _, q := range usage {
+		quotas = append(quotas, &pb.QuotaUsage{
+			Scope:       q.Scope,
+			Subject:     q.Subject,
+			Limit:       q.Limit,
+			Used:        q.Used,
+			Remaining:   q.Remaining(),
+			PeriodStart: timestamppb.New(q.PeriodStart),
+			ResetsAt:    timestamppb.New(q.ResetsAt),
+		})
+	}
+
+	return &pb.GetQuotaResponse{Quotas: quotas}, nil
+}
@@ -0,0 +1,56 @@
+// internal/handler/readiness.go
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// ReadinessCheck reports whether a dependency is ready to serve traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// ReadinessHandler serves the readiness probe from a set of named checks
+type ReadinessHandler struct {
+	checks map[string]ReadinessCheck
+	logger utils.Logger
+}
+
+// NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(checks map[string]ReadinessCheck, logger utils.Logger) *ReadinessHandler {
+	return &ReadinessHandler{
+		checks: checks,
+		logger: logger,
+	}
+}
+
+// HandleReady runs every check and responds 503 if any of them fails
+func (h *ReadinessHandler) HandleReady(c *gin.Context) {
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ready := true
+	results := make(map[string]string, len(names))
+	for _, name := range names {
+		if err := h.checks[name](c.Request.Context()); err != nil {
+			ready = false
+			results[name] = err.Error()
+			h.logger.Warn("Readiness check failed", "check", name, "error", err)
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": results})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
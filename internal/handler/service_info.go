@@ -0,0 +1,107 @@
+// internal/handler/service_info.go
+package handler
+
+import (
+	"context"
+
+	pb "messaging-microservice/proto"
+)
+
+// APIVersion is the version of the gRPC API served by this build.
+// Bump the minor version whenever RPCs or request fields are added.
+const APIVersion = "1.27.0"
+
+// defaultPageSize is used by list RPCs when the caller does not set a limit
+const defaultPageSize = 10
+
+// Export chunk sizes bound how many rows an export fetches per database round trip
+const (
+	defaultExportChunkSize = 500
+	maxExportChunkSize     = 5000
+)
+
+// exportChunkSize applies the default and upper bound to a requested chunk size
+func exportChunkSize(requested int) int {
+	if requested <= 0 {
+		return defaultExportChunkSize
+	}
+	if requested > maxExportChunkSize {
+		return maxExportChunkSize
+	}
+	return requested
+}
+
+// Features lists the optional capabilities clients can probe for via GetServiceInfo
+var Features = []string{
+	"list_filters",
+	"list_total_count",
+	"external_id_lookup",
+	"order_lookup",
+	"status_events",
+	"load_shedding",
+	"export_messages",
+	"tenants",
+	"typed_status",
+	"error_detail",
+	"typed_timestamps",
+	"privacy_requests",
+	"message_stats",
+	"delivery_latency",
+	"quotas",
+	"send_pauses",
+	"template_kill_switch",
+	"country_rules",
+	"quiet_hours",
+	"message_expiry",
+	"retry_message",
+	"retry_tiers",
+	"agent_handoff",
+	"typing_indicator",
+	"cta_url_buttons",
+	"product_messages",
+	"account_quality",
+	"audit_log",
+	"soft_delete",
+	"message_search",
+	"list_sorting",
+	"batch_lookup",
+	"request_validation",
+	"provider_capture",
+	"caller_limits",
+	"opt_ins",
+	"campaigns",
+	"audience_import",
+	"campaign_variants",
+	"template_preview",
+	"instance_status",
+	"log_levels",
+	"synchronous_send",
+}
+
+// ServiceInfo describes the deployment-specific values reported by GetServiceInfo
+type ServiceInfo struct {
+	Provider         string
+	MaxInFlightSends int
+	MaxQueuedSends   int
+	// CatalogID is the catalog product messages use unless they name one; it is not reported
+	CatalogID string
+	// Instance is the replica reported by GetInstanceStatus
+	Instance InstanceInfo
+}
+
+// GetServiceInfo returns the API version, features, provider and limits
+func (h *GrpcMessageHandler) GetServiceInfo(ctx context.Context, req *pb.GetServiceInfoRequest) (*pb.ServiceInfoResponse, error) {
+	features := make([]string, len(Features))
+	copy(features, Features)
+
+	return &pb.ServiceInfoResponse{
+		ApiVersion: APIVersion,
+		Features:   features,
+		Provider:   h.info.Provider,
+		Limits: &pb.ServiceLimits{
+			MaxInFlightSends: int32(h.info.MaxInFlightSends),
+			MaxQueuedSends:   int32(h.info.MaxQueuedSends),
+			DefaultPageSize:  defaultPageSize,
+		},
+	}, nil
+}
@@ -0,0 +1,79 @@
+// internal/handler/stats_handler.go
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"messaging-microservice/internal/domain"
+	pb "messaging-microservice/proto"
+)
+
+// GetMessageStats returns message counts and rates by day and template for a time range
+func (h *GrpcMessageHandler) GetMessageStats(ctx context.Context, req *pb.GetMessageStatsRequest) (*pb.GetMessageStatsResponse, error) {
+	filter := domain.MessageFilter{TemplateID: req.TemplateId}
+	if err := applyTimestampRange(&filter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return nil, err
+	}
+
+	stats, err := h.messageService.GetMessageStats(ctx, filter)
+	if err != nil {
+		h.logger.Error("Failed to get message stats", "error", err)
+		return nil, GRPCError(err, "failed to get message stats")
+	}
+
+	buckets := make([]*pb.MessageStatsBucket, 0, len(stats.Buckets))
+	for _, bucket := range stats.Buckets {
+		buckets = append(buckets, convertStatsBucketToProto(bucket))
+	}
+
+	return &pb.GetMessageStatsResponse{
+		Summary: convertStatsBucketToProto(stats.Summary),
+		Buckets: buckets,
+	}, nil
+}
+
+// convertStatsBucketToProto converts a domain.StatsBucket; the zero day of the summary stays empty
+func convertStatsBucketToProto(bucket domain.StatsBucket) *pb.MessageStatsBucket {
+	var day string
+	if !bucket.Day.IsZero() {
+		day = bucket.Day.Format("2006-01-02")
+	}
+
+	return &pb.MessageStatsBucket{
+		Day:           day,
+		TemplateId:    bucket.TemplateID,
+		StatusCounts:  bucket.StatusCounts,
+		Total:         bucket.Total,
+		DeliveredRate: bucket.DeliveredRate(),
+		ReadRate:      bucket.ReadRate(),
+		FailureRate:   bucket.FailureRate(),
+	}
+}
+
+// GetDeliveryLatency returns p50/p95 time from queueing to each delivery stage for a time range
+func (h *GrpcMessageHandler) GetDeliveryLatency(ctx context.Context, req *pb.GetDeliveryLatencyRequest) (*pb.GetDeliveryLatencyResponse, error) {
+	filter := domain.MessageFilter{TemplateID: req.TemplateId}
+	if err := applyTimestampRange(&filter, req.CreatedAfterTs, req.CreatedBeforeTs); err != nil {
+		return nil, err
+	}
+
+	latencies, err := h.messageService.GetDeliveryLatency(ctx, filter)
+	if err != nil {
+		h.logger.Error("Failed to get delivery latency", "error", err)
+		return nil, GRPCError(err, "failed to get delivery latency")
+	}
+
+	stages := make([]*pb.StageLatency, 0, len(latencies))
+	for _, latency := range latencies {
+		stages = append(stages, &pb.StageLatency{
+			Stage: latency.Stage,
+			Count: latency.Count,
+			P50:   durationpb.New(latency.P50),
+			P95:   durationpb.New(latency.P95),
+		})
+	}
+
+	return &pb.GetDeliveryLatencyResponse{Stages: stages}, nil
+}
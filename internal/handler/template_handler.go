@@ -0,0 +1,50 @@
+// internal/handler/template_handler.go
+package handler
+
+import (
+	"context"
+
+	"messaging-microservice/pkg/meta"
+	pb "messaging-microservice/proto"
+)
+
+// PreviewTemplateMessage renders a template of the caller tenant as SendTemplateMessage would
+// send it
+func (h *GrpcMessageHandler) PreviewTemplateMessage(ctx context.Context, req *pb.PreviewTemplateMessageRequest) (*pb.TemplatePreview, error) {
+	parameters := make(map[string]interface{}, len(req.Parameters)+len(req.ButtonUrls))
+	for key, value := range req.Parameters {
+		parameters[key] = value
+	}
+	for index, suffix := range req.ButtonUrls {
+		parameters[meta.ButtonURLParameter(int(index))] = suffix
+	}
+
+	preview, err := h.previews.Preview(ctx, req.TemplateId, req.Language, req.PhoneNumber, parameters)
+	if err != nil {
+		h.logger.Error("Failed to preview template", "error", err, "template_id", req.TemplateId)
+		return nil, GRPCError(err, "failed to preview template")
+	}
+
+	resp := &pb.TemplatePreview{
+		TemplateId:        preview.TemplateID,
+		Language:          preview.Language,
+		Status:            preview.Status,
+		Category:          preview.Category,
+		Header:            preview.Header,
+		Body:              preview.Body,
+		Footer:            preview.Footer,
+		Buttons:           make([]*pb.TemplateButtonPreview, 0, len(preview.Buttons)),
+		Payload:           string(preview.Payload),
+		MissingParameters: preview.MissingParameters,
+		UnusedParameters:  preview.UnusedParameters,
+	}
+	for _, button := range preview.Buttons {
+		resp.Buttons = append(resp.Buttons, &pb.TemplateButtonPreview{
+			Type:        button.Type,
+			Text:        button.Text,
+			Url:         button.URL,
+			PhoneNumber: button.PhoneNumber,
+		})
+	}
+	return resp, nil
+}
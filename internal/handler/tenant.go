@@ -0,0 +1,108 @@
+// internal/handler/tenant.go
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+)
+
+// tenantMetadataKey is the gRPC metadata key callers use to identify their tenant
+const tenantMetadataKey = "x-tenant-id"
+
+// GRPCCredential is what a gRPC API key authenticates: the caller, named in admin lists and
+// audit entries, and the one tenant its requests reach
+type GRPCCredential struct {
+	Caller string
+	Tenant string
+}
+
+// TenantInterceptor authenticates the caller's x-api-key against credentials and scopes the
+// request to the key's tenant, so it only reads and changes that tenant's messages. An
+// x-tenant-id naming another tenant is refused. With no credentials, as in development, any
+// caller is accepted and x-tenant-id is trusted as is.
+func TenantInterceptor(credentials map[string]GRPCCredential) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateTenant(ctx, credentials)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// TenantStreamInterceptor is TenantInterceptor for streaming calls
+func TenantStreamInterceptor(credentials map[string]GRPCCredential) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateTenant(stream.Context(), credentials)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// TenantMiddleware is TenantInterceptor for HTTP routes served outside the gateway, reading the
+// X-API-Key and X-Tenant-ID headers. Refused requests are aborted with the status the gateway
+// would return.
+func TenantMiddleware(credentials map[string]GRPCCredential) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := authenticate(c.Request.Context(), credentials, c.GetHeader(apiKeyMetadataKey), c.GetHeader(tenantMetadataKey))
+		if err != nil {
+			c.AbortWithStatusJSON(HTTPStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// authenticateTenant returns ctx scoped to the caller's tenant and carrying the caller's name
+func authenticateTenant(ctx context.Context, credentials map[string]GRPCCredential) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return authenticate(ctx, credentials, firstMetadata(md, apiKeyMetadataKey), firstMetadata(md, tenantMetadataKey))
+}
+
+// authenticate checks apiKey against credentials and scopes ctx to its tenant, refusing a
+// tenantID naming another tenant
+func authenticate(ctx context.Context, credentials map[string]GRPCCredential, apiKey, tenantID string) (context.Context, error) {
+	if len(credentials) == 0 {
+		if tenantID == "" {
+			tenantID = domain.DefaultTenantID
+		}
+		return domain.WithTenantScope(ctx, tenantID), nil
+	}
+
+	credential, ok := credentials[apiKey]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing or unknown "+apiKeyMetadataKey)
+	}
+	if tenantID != "" && tenantID != credential.Tenant {
+		return nil, status.Errorf(codes.PermissionDenied, "API key is not valid for tenant %q", tenantID)
+	}
+	return domain.WithCaller(domain.WithTenantScope(ctx, credential.Tenant), credential.Caller), nil
+}
+
+// firstMetadata returns the first value of a metadata key, or ""
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// tenantStream is a server stream whose context carries the caller's tenant
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
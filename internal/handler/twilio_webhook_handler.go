@@ -0,0 +1,96 @@
+// internal/handler/twilio_webhook_handler.go
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// SignatureValidator checks a provider webhook signature against the URL it was posted to
+type SignatureValidator func(signature, url string, body []byte) bool
+
+// TwilioWebhookHandler handles Twilio message status callbacks
+type TwilioWebhookHandler struct {
+	webhookService service.WebhookService
+	validate       SignatureValidator
+	publicURL      string
+	logger         utils.Logger
+}
+
+// NewTwilioWebhookHandler creates a Twilio status callback handler. publicURL is the callback
+// URL as Twilio sees it, which is what it signs; when empty it is rebuilt from the request.
+func NewTwilioWebhookHandler(webhookService service.WebhookService, validate SignatureValidator, publicURL string, logger utils.Logger) *TwilioWebhookHandler {
+	return &TwilioWebhookHandler{
+		webhookService: webhookService,
+		validate:       validate,
+		publicURL:      publicURL,
+		logger:         logger,
+	}
+}
+
+// HandleStatusCallback validates and applies a form-encoded status callback
+func (h *TwilioWebhookHandler) HandleStatusCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to read Twilio callback body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !h.validate(c.GetHeader("X-Twilio-Signature"), h.callbackURL(c), body) {
+		h.logger.Warn("Rejected Twilio callback with invalid signature", "remote_addr", c.ClientIP())
+		writeWebhookError(c, h.logger, domain.ProviderTwilio, domain.NewError(domain.ErrUnauthenticated, "invalid webhook signature"))
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeWebhookError(c, h.logger, domain.ProviderTwilio, domain.WrapError(domain.ErrValidation, err, "invalid form body"))
+		return
+	}
+	callback, err := twilio.ParseStatusCallback(form)
+	if err != nil {
+		writeWebhookError(c, h.logger, domain.ProviderTwilio, domain.WrapError(domain.ErrValidation, err, "%s", err))
+		return
+	}
+
+	if err := h.webhookService.ProcessTwilioStatus(c.Request.Context(), callback); err != nil {
+		writeWebhookError(c, h.logger, domain.ProviderTwilio, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// callbackURL returns the URL Twilio signed: the configured public URL with the request's
+// query string, or the request URL as seen through any proxy in front of the service
+func (h *TwilioWebhookHandler) callbackURL(c *gin.Context) string {
+	query := ""
+	if c.Request.URL.RawQuery != "" {
+		query = "?" + c.Request.URL.RawQuery
+	}
+	if h.publicURL != "" {
+		return h.publicURL + query
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host + c.Request.URL.Path + query
+}
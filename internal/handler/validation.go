@@ -0,0 +1,363 @@
+// internal/handler/validation.go
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+	"messaging-microservice/proto/validate"
+)
+
+// fieldRules pairs a field with its validation rules. Rules are nil for a message field
+// without annotations, which is only descended into.
+type fieldRules struct {
+	fd    protoreflect.FieldDescriptor
+	rules *validate.FieldRules
+}
+
+// messageRules caches rulesOf by message full name
+var messageRules sync.Map
+
+// rulesOf returns the fields of md to validate: those with rules and those holding messages
+func rulesOf(md protoreflect.MessageDescriptor) []fieldRules {
+	if cached, ok := messageRules.Load(md.FullName()); ok {
+		return cached.([]fieldRules)
+	}
+	var fields []fieldRules
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		var rules *validate.FieldRules
+		if proto.HasExtension(fd.Options(), validate.E_Field) {
+			rules = proto.GetExtension(fd.Options(), validate.E_Field).(*validate.FieldRules)
+		}
+		holdsMessages := fd.Message() != nil && (!fd.IsMap() || fd.MapValue().Message() != nil)
+		if rules != nil || holdsMessages {
+			fields = append(fields, fieldRules{fd: fd, rules: rules})
+		}
+	}
+	messageRules.Store(md.FullName(), fields)
+	return fields
+}
+
+// ValidationInterceptor rejects unary requests breaking the validation rules annotated on
+// their fields in proto/whatapp.proto, e.g. [(whatsapp.validate.field).string.max_len = 50], with
+// InvalidArgument. It runs before any handler, so handlers and services only check what
+// depends on more than one field or on state.
+func ValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor rejects streaming requests breaking their field rules with
+// InvalidArgument as they are received
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: stream})
+	}
+}
+
+// validatingStream validates every message received on a stream
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(m)
+}
+
+// CheckRequestRules reports a rule in the API the interceptors cannot enforce: one for another
+// type of value, or one proto/validate does not declare, so an annotation cannot silently go
+// unchecked
+func CheckRequestRules() error {
+	return checkMessageRules(pb.File_proto_whatapp_proto.Messages())
+}
+
+// checkMessageRules applies CheckRequestRules to messages and the messages nested in them
+func checkMessageRules(messages protoreflect.MessageDescriptors) error {
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		for _, f := range rulesOf(md) {
+			if f.rules == nil {
+				continue
+			}
+			if err := checkRules(f.fd, f.rules, true); err != nil {
+				return fmt.Errorf("validation rules of %s: %w", f.fd.FullName(), err)
+			}
+		}
+		if err := checkMessageRules(md.Messages()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRules reports rules not applying to the values of fd. whole is whether the rules are
+// on the field itself rather than on the items, keys or values of a repeated field or map.
+func checkRules(fd protoreflect.FieldDescriptor, rules *validate.FieldRules, whole bool) error {
+	if len(rules.ProtoReflect().GetUnknown()) > 0 {
+		return errors.New("unsupported rules")
+	}
+	valueType := fd.Kind().String()
+	switch {
+	case whole && fd.IsList():
+		valueType = "repeated"
+	case whole && fd.IsMap():
+		valueType = "map"
+	}
+	reflected := rules.ProtoReflect()
+	if typed := reflected.WhichOneof(reflected.Descriptor().Oneofs().ByName("type")); typed != nil {
+		if string(typed.Name()) != valueType {
+			return fmt.Errorf("%s rules on a %s value", typed.Name(), valueType)
+		}
+		if len(reflected.Get(typed).Message().GetUnknown()) > 0 {
+			return fmt.Errorf("unsupported %s rules", typed.Name())
+		}
+	}
+	if items := rules.GetRepeated().GetItems(); items != nil {
+		return checkRules(fd, items, false)
+	}
+	if keys := rules.GetMap().GetKeys(); keys != nil {
+		if err := checkRules(fd.MapKey(), keys, false); err != nil {
+			return fmt.Errorf("keys: %w", err)
+		}
+	}
+	if values := rules.GetMap().GetValues(); values != nil {
+		return checkRules(fd.MapValue(), values, false)
+	}
+	return nil
+}
+
+// validateRequest checks req against the rules of its fields, returning the first violation
+func validateRequest(req interface{}) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+	return validateMessage(msg.ProtoReflect(), "")
+}
+
+// validateMessage checks the fields of msg and of the messages it holds. prefix names msg
+// within the request, e.g. "variants[0]."
+func validateMessage(msg protoreflect.Message, prefix string) error {
+	for _, f := range rulesOf(msg.Descriptor()) {
+		path := prefix + string(f.fd.Name())
+		if f.rules != nil {
+			if err := checkField(msg, f.fd, f.rules, path); err != nil {
+				return err
+			}
+		}
+		if err := validateNested(msg, f.fd, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested checks the messages held by field fd of msg
+func validateNested(msg protoreflect.Message, fd protoreflect.FieldDescriptor, path string) error {
+	if fd.Message() == nil || !msg.Has(fd) {
+		return nil
+	}
+	value := msg.Get(fd)
+	switch {
+	case fd.IsList():
+		list := value.List()
+		for i := 0; i < list.Len(); i++ {
+			if err := validateMessage(list.Get(i).Message(), fmt.Sprintf("%s[%d].", path, i)); err != nil {
+				return err
+			}
+		}
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return nil
+		}
+		var err error
+		value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			err = validateMessage(value.Message(), fmt.Sprintf("%s[%v].", path, key.Interface()))
+			return err == nil
+		})
+		return err
+	default:
+		return validateMessage(value.Message(), path+".")
+	}
+	return nil
+}
+
+// checkField applies rules to field fd of msg
+func checkField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, rules *validate.FieldRules, path string) error {
+	if rules.GetIgnore() == validate.Ignore_IGNORE_ALWAYS {
+		return nil
+	}
+	if !msg.Has(fd) {
+		if rules.GetRequired() {
+			return violation(path, "is required")
+		}
+		if rules.GetIgnore() == validate.Ignore_IGNORE_IF_ZERO_VALUE || (fd.Message() != nil && !fd.IsList() && !fd.IsMap()) {
+			return nil
+		}
+	}
+
+	value := msg.Get(fd)
+	switch {
+	case fd.IsList():
+		list := value.List()
+		repeated := rules.GetRepeated()
+		if err := checkCount(path, list.Len(), repeated.MinItems, repeated.MaxItems); err != nil {
+			return err
+		}
+		for i := 0; i < list.Len(); i++ {
+			if err := checkValue(repeated.GetItems(), list.Get(i), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fd.IsMap():
+		entries := value.Map()
+		rules := rules.GetMap()
+		if err := checkCount(path, entries.Len(), rules.MinPairs, rules.MaxPairs); err != nil {
+			return err
+		}
+		var err error
+		entries.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			if err = checkValue(rules.GetKeys(), key.Value(), path+" keys"); err == nil {
+				err = checkValue(rules.GetValues(), value, path)
+			}
+			return err == nil
+		})
+		return err
+	default:
+		return checkValue(rules, value, path)
+	}
+}
+
+// checkCount applies entry count bounds to a repeated field or map
+func checkCount(path string, n int, min, max *uint64) error {
+	switch {
+	case min != nil && uint64(n) < *min:
+		return violation(path, fmt.Sprintf("must have at least %d entries", *min))
+	case max != nil && uint64(n) > *max:
+		return violation(path, fmt.Sprintf("must have at most %d entries", *max))
+	}
+	return nil
+}
+
+// checkValue applies rules to a single value: a singular field, an item, a map key or value
+func checkValue(rules *validate.FieldRules, value protoreflect.Value, path string) error {
+	switch rules.GetIgnore() {
+	case validate.Ignore_IGNORE_ALWAYS:
+		return nil
+	case validate.Ignore_IGNORE_IF_ZERO_VALUE:
+		if value.Equal(protoreflect.ValueOf(zeroOf(value.Interface()))) {
+			return nil
+		}
+	}
+
+	switch v := value.Interface().(type) {
+	case string:
+		if r := rules.GetString_(); r != nil {
+			n := uint64(utf8.RuneCountInString(v))
+			if r.MinLen != nil && n < *r.MinLen {
+				return violation(path, fmt.Sprintf("must be at least %d characters", *r.MinLen))
+			}
+			if r.MaxLen != nil && n > *r.MaxLen {
+				return violation(path, fmt.Sprintf("must be at most %d characters", *r.MaxLen))
+			}
+			if r.GetPhoneNumber() && !utils.IsValidPhoneNumber(v) {
+				return violation(path, "must be a phone number")
+			}
+		}
+	case int32:
+		if r := rules.GetInt32(); r != nil {
+			return boundsOf(r).check(int64(v), path)
+		}
+	case int64:
+		if r := rules.GetInt64(); r != nil {
+			return boundsOf(r).check(v, path)
+		}
+	}
+	return nil
+}
+
+// zeroOf returns the zero value of v's type
+func zeroOf(v interface{}) interface{} {
+	switch v.(type) {
+	case string:
+		return ""
+	case int32:
+		return int32(0)
+	case int64:
+		return int64(0)
+	case bool:
+		return false
+	}
+	return v
+}
+
+// intBounds are the bounds set in Int32Rules or Int64Rules
+type intBounds struct {
+	lt, lte, gt, gte *int64
+}
+
+// boundsOf reads the bounds set in rules
+func boundsOf(rules proto.Message) intBounds {
+	var b intBounds
+	rules.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		n := value.Int()
+		switch fd.Name() {
+		case "lt":
+			b.lt = &n
+		case "lte":
+			b.lte = &n
+		case "gt":
+			b.gt = &n
+		case "gte":
+			b.gte = &n
+		}
+		return true
+	})
+	return b
+}
+
+// check reports v outside the bounds
+func (b intBounds) check(v int64, path string) error {
+	switch {
+	case b.gte != nil && b.lte != nil && (v < *b.gte || v > *b.lte):
+		return violation(path, fmt.Sprintf("must be between %d and %d", *b.gte, *b.lte))
+	case b.gt != nil && v <= *b.gt && *b.gt == 0:
+		return violation(path, "must be positive")
+	case b.gt != nil && v <= *b.gt:
+		return violation(path, fmt.Sprintf("must be greater than %d", *b.gt))
+	case b.gte != nil && v < *b.gte && *b.gte == 0:
+		return violation(path, "must not be negative")
+	case b.gte != nil && v < *b.gte:
+		return violation(path, fmt.Sprintf("must be at least %d", *b.gte))
+	case b.lt != nil && v >= *b.lt:
+		return violation(path, fmt.Sprintf("must be less than %d", *b.lt))
+	case b.lte != nil && v > *b.lte:
+		return violation(path, fmt.Sprintf("must be at most %d", *b.lte))
+	}
+	return nil
+}
+
+// violation reports the field at path as invalid
+func violation(path, message string) error {
+	return status.Error(codes.InvalidArgument, path+" "+message)
+}
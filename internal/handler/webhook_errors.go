@@ -0,0 +1,75 @@
+// internal/handler/webhook_errors.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// Codes of WebhookError
+const (
+	WebhookErrorMalformed        = "malformed_payload"
+	WebhookErrorInvalidSignature = "invalid_signature"
+	WebhookErrorProcessing       = "processing_failed"
+)
+
+// webhookErrorsTotal counts the callbacks that were not processed
+var webhookErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_webhook_errors_total",
+	Help: "Webhook callbacks not processed, by provider and error code (malformed_payload, invalid_signature, processing_failed).",
+}, []string{"provider", "code"})
+
+// WebhookError is the machine-readable body of a response to a callback that was not processed
+type WebhookError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Retryable reports whether redelivering the callback may succeed
+	Retryable bool `json:"retryable"`
+}
+
+// writeWebhookError answers a callback that failed. Callbacks that can never be processed are
+// acknowledged with 200 and logged, since providers redeliver anything else for days; bad
+// signatures get 401 and failures that may pass on redelivery 500.
+func writeWebhookError(c *gin.Context, logger utils.Logger, provider string, err error) {
+	status, body := http.StatusInternalServerError, WebhookError{
+		Code:      WebhookErrorProcessing,
+		Message:   domain.ErrorMessage(err, "failed to process webhook"),
+		Retryable: true,
+	}
+	switch {
+	case errors.Is(err, domain.ErrUnauthenticated):
+		status, body.Code, body.Retryable = http.StatusUnauthorized, WebhookErrorInvalidSignature, false
+	case permanentWebhookError(err):
+		status, body.Code, body.Retryable = http.StatusOK, WebhookErrorMalformed, false
+	}
+
+	webhookErrorsTotal.WithLabelValues(provider, body.Code).Inc()
+	if body.Retryable {
+		logger.Error("Failed to process webhook", "error", err, "provider", provider)
+	} else {
+		logger.Warn("Dropped webhook that cannot be processed", "error", err, "provider", provider, "code", body.Code)
+	}
+	c.JSON(status, gin.H{"error": body})
+}
+
+// permanentWebhookError reports whether redelivering a callback would fail the same way: every
+// error joined in err is a validation error
+func permanentWebhookError(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, err := range errs {
+			if !permanentWebhookError(err) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	return errors.Is(err, domain.ErrValidation)
+}
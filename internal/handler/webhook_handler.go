@@ -7,6 +7,7 @@ import (
 	"context"
 
 	"github.com/gin-gonic/gin"
+	"messaging-microservice/internal/domain"
 	"messaging-microservice/internal/service"
 	"messaging-microservice/pkg/utils"
 	pb "messaging-microservice/proto"
@@ -48,8 +49,7 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 	
 	// Process the webhook
 	if err := h.webhookService.ProcessWebhook(c.Request.Context(), body, signature, c.Request.URL.String()); err != nil {
-		h.logger.Error("Failed to process webhook", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		writeWebhookError(c, h.logger, domain.ProviderMeta, err)
 		return
 	}
 
@@ -85,7 +85,7 @@ func (h *WebhookHandler) handleVerification(c *gin.Context) {
 // HandleGrpcWebhook handles webhook events coming through gRPC
 func (h *WebhookHandler) HandleGrpcWebhook(ctx context.Context, req *pb.WebhookRequest) (*pb.WebhookResponse, error) {
 	// Process the webhook
-	err := h.webhookService.UpdateMessageStatus(ctx, req.ExternalId, req.Status, req.ErrorMessage)
+	err := h.webhookService.UpdateMessageStatus(ctx, req.ExternalId, req.Status, req.ErrorCode, req.ErrorMessage)
 	if err != nil {
 		h.logger.Error("Failed to process gRPC webhook", "error", err)
 		return &pb.WebhookResponse{
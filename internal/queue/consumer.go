@@ -3,6 +3,13 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -15,17 +22,115 @@ type MessageHandler func(context.Context, []byte) error
 // Consumer defines the interface for message consumers
 type Consumer interface {
 	Consume(ctx context.Context, handler MessageHandler) error
+	Stats() ConsumerStats
 	Close() error
 }
 
+// ConsumerStats is a snapshot of a consumer's progress. Handled and Failed are running totals.
+type ConsumerStats struct {
+	Topic string
+	// Lag is how many messages the group is behind the end of the partitions being read
+	Lag int64
+	// Handled counts messages passed to the handler, Failed those it returned an error for
+	Handled int64
+	Failed  int64
+	// InFlight is how many messages the handler is working on; Utilization the share of the
+	// last measured minute (or more, when idle) it was busy, from 0 to 1
+	InFlight    int64
+	Utilization float64
+	// Partitions are those this replica read from within AssignmentWindow, by partition
+	Partitions []PartitionStats
+}
+
+// PartitionStats is a partition of the topic this replica reads
+type PartitionStats struct {
+	Partition int
+	// Offset is the last offset read and LastReadAt when
+	Offset     int64
+	LastReadAt time.Time
+}
+
+// AssignmentWindow is how long after its last read a partition is still reported as assigned.
+// The group's assignment is not exposed by the reader, so it is observed from the reads; a
+// partition without traffic for longer drops out.
+const AssignmentWindow = 5 * time.Minute
+
+// utilizationWindow is the period utilization is measured over
+const utilizationWindow = time.Minute
+
+// Gate tells the consumer whether downstream can take work. While it is closed the consumer
+// stops reading, so messages wait in the topic instead of being pulled and failed.
+type Gate interface {
+	// Ready reports whether to read now and, if not, roughly how long to wait
+	Ready() (bool, time.Duration)
+}
+
+// gates is a Gate that is ready when all of its gates are
+type gates []Gate
+
+// AllGates combines gates; it returns nil when there are none
+func AllGates(all ...Gate) Gate {
+	if len(all) == 0 {
+		return nil
+	}
+	return gates(all)
+}
+
+// Ready waits for the slowest closed gate
+func (g gates) Ready() (bool, time.Duration) {
+	ready, longest := true, time.Duration(0)
+	for _, gate := range g {
+		if ok, wait := gate.Ready(); !ok {
+			ready = false
+			if wait > longest {
+				longest = wait
+			}
+		}
+	}
+	return ready, longest
+}
+
+// maxReadFailures is how many reads in a row may fail before Consume returns; the wait after
+// each failure grows by readFailureWait
+const (
+	maxReadFailures = 5
+	readFailureWait = time.Second
+)
+
+// maxGateWait caps how long the consumer sleeps before asking a closed gate again
+const maxGateWait = 5 * time.Second
+
 // kafkaConsumer implements Consumer using Kafka
 type kafkaConsumer struct {
-	reader *kafka.Reader
-	logger utils.Logger
+	reader  *kafka.Reader
+	topic   string
+	gate    Gate
+	delay   time.Duration
+	handled atomic.Int64
+	failed  atomic.Int64
+	// inFlight, partitions and busy track what the handler is doing, for Stats
+	inFlight   atomic.Int64
+	mu         sync.Mutex
+	partitions map[int]PartitionStats
+	busy       busyTracker
+	logger     utils.Logger
 }
 
 // NewConsumer creates a new Kafka consumer
 func NewConsumer(brokers []string, topic, groupID string, logger utils.Logger) (Consumer, error) {
+	return NewGatedConsumer(brokers, topic, groupID, nil, logger)
+}
+
+// NewGatedConsumer creates a Kafka consumer that only reads while gate is ready; a nil gate is
+// always ready
+func NewGatedConsumer(brokers []string, topic, groupID string, gate Gate, logger utils.Logger) (Consumer, error) {
+	return NewDelayedConsumer(brokers, topic, groupID, 0, gate, logger)
+}
+
+// NewDelayedConsumer creates a gated Kafka consumer that handles each message only once delay
+// has passed since it was produced. Delayed consumers commit a message after handling it, so a
+// restart while waiting does not lose it.
+func NewDelayedConsumer(brokers []string, topic, groupID string, delay time.Duration, gate Gate, logger utils.Logger) (Consumer, error) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        brokers,
 		Topic:          topic,
@@ -38,34 +143,223 @@ func NewConsumer(brokers []string, topic, groupID string, logger utils.Logger) (
 	})
 
 	return &kafkaConsumer{
-		reader: reader,
-		logger: logger,
+		reader:     reader,
+		topic:      topic,
+		gate:       gate,
+		delay:      delay,
+		partitions: make(map[int]PartitionStats),
+		busy:       busyTracker{windowStart: time.Now()},
+		logger:     logger,
 	}, nil
 }
 
-// Consume consumes messages from Kafka
+// waitForGate blocks while the gate is closed
+func (c *kafkaConsumer) waitForGate(ctx context.Context) error {
+	if c.gate == nil {
+		return nil
+	}
+
+	paused := false
+	for {
+		ready, wait := c.gate.Ready()
+		if ready {
+			if paused {
+				consumerPaused.WithLabelValues(c.topic).Set(0)
+				c.logger.Debug("Resuming consumption", "topic", c.topic)
+			}
+			return nil
+		}
+		if !paused {
+			paused = true
+			consumerPaused.WithLabelValues(c.topic).Set(1)
+			c.logger.Debug("Pausing consumption until the provider can take sends", "topic", c.topic, "wait", wait)
+		}
+
+		if wait <= 0 || wait > maxGateWait {
+			wait = maxGateWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Consume consumes messages from Kafka until ctx is done, or until the reader is closed or
+// fails maxReadFailures times in a row
 func (c *kafkaConsumer) Consume(ctx context.Context, handler MessageHandler) error {
+	readFailures := 0
 	for {
-		msg, err := c.reader.ReadMessage(ctx)
+		if err := c.waitForGate(ctx); err != nil {
+			return err
+		}
+
+		msg, err := c.read(ctx)
 		if err != nil {
 			// Check if context was canceled
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
 
-			c.logger.Error("Failed to read message from Kafka", "error", err)
+			// A closed reader or one that keeps failing is given up on, so whatever runs the
+			// consumer sees it stopped
+			readFailures++
+			if errors.Is(err, io.EOF) || readFailures >= maxReadFailures {
+				return fmt.Errorf("read %s: %d failures in a row: %w", c.topic, readFailures, err)
+			}
+			c.logger.Error("Failed to read message from Kafka", "error", err, "failures", readFailures)
+			timer := time.NewTimer(time.Duration(readFailures) * readFailureWait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
 			continue
 		}
+		readFailures = 0
 
 		c.logger.Info("Received message from Kafka", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
 
 		// Handle message
-		if err := handler(ctx, msg.Value); err != nil {
+		start := c.begin(msg)
+		err = handler(ctx, msg.Value)
+		c.end(msg, start)
+		c.handled.Add(1)
+		if c.delay > 0 {
+			if commitErr := c.reader.CommitMessages(ctx, msg); commitErr != nil {
+				c.logger.Error("Failed to commit message", "error", commitErr, "topic", msg.Topic, "offset", msg.Offset)
+			}
+		}
+		if err != nil {
+			c.failed.Add(1)
+			consumerMessages.WithLabelValues(msg.Topic, "error").Inc()
 			c.logger.Error("Failed to handle message", "error", err)
-			// Continue processing other messages even if one fails
-			// In a production system, you might want to handle retries, DLQ, etc.
+			// Continue processing other messages even if one fails; RetryHandler moves
+			// transient failures on to the retry topics
+			continue
+		}
+		consumerMessages.WithLabelValues(msg.Topic, "success").Inc()
+	}
+}
+
+// begin records that the handler is taking msg, returning when
+func (c *kafkaConsumer) begin(msg kafka.Message) time.Time {
+	start := time.Now()
+	c.mu.Lock()
+	if _, ok := c.partitions[msg.Partition]; !ok {
+		c.logger.Info("Reading partition", "topic", c.topic, "partition", msg.Partition)
+	}
+	c.partitions[msg.Partition] = PartitionStats{Partition: msg.Partition, Offset: msg.Offset, LastReadAt: start}
+	c.mu.Unlock()
+
+	consumerInFlight.WithLabelValues(c.topic).Set(float64(c.inFlight.Add(1)))
+	consumerPartitionOffset.WithLabelValues(c.topic, strconv.Itoa(msg.Partition)).Set(float64(msg.Offset))
+	return start
+}
+
+// end records that the handler is done with msg
+func (c *kafkaConsumer) end(msg kafka.Message, start time.Time) {
+	now := time.Now()
+	elapsed := now.Sub(start)
+	consumerHandlerDuration.WithLabelValues(msg.Topic).Observe(elapsed.Seconds())
+	consumerBusySeconds.WithLabelValues(c.topic).Add(elapsed.Seconds())
+	consumerInFlight.WithLabelValues(c.topic).Set(float64(c.inFlight.Add(-1)))
+
+	c.mu.Lock()
+	c.busy.add(start, now)
+	c.mu.Unlock()
+}
+
+// busyTracker measures the share of each period of at least utilizationWindow the handler was
+// busy. Periods end on the first message or Stats call after the window.
+type busyTracker struct {
+	windowStart time.Time
+	busy        time.Duration
+	last        float64
+}
+
+// add counts the handler as busy from start to end
+func (b *busyTracker) add(start, end time.Time) {
+	if start.Before(b.windowStart) {
+		start = b.windowStart
+	}
+	b.busy += end.Sub(start)
+	b.roll(end)
+}
+
+// roll closes the windows that ended by now
+func (b *busyTracker) roll(now time.Time) {
+	elapsed := now.Sub(b.windowStart)
+	if elapsed < utilizationWindow {
+		return
+	}
+	b.last = min(b.busy.Seconds()/elapsed.Seconds(), 1)
+	b.windowStart, b.busy = now, 0
+}
+
+// read returns the next message. Delayed consumers wait until the message is due, and commit
+// it once the handler returns.
+func (c *kafkaConsumer) read(ctx context.Context) (kafka.Message, error) {
+	if c.delay <= 0 {
+		return c.reader.ReadMessage(ctx)
+	}
+
+	msg, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return msg, err
+	}
+	// Messages of a topic share its delay, so waiting for each in turn keeps them in order
+	if wait := time.Until(msg.Time.Add(c.delay)); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return msg, ctx.Err()
+		case <-timer.C:
 		}
 	}
+	return msg, nil
+}
+
+// Stats returns the consumer's lag, handler totals and assignment, and updates their gauges. The lag is
+// the one observed on the latest fetch of any partition assigned to this replica.
+func (c *kafkaConsumer) Stats() ConsumerStats {
+	lag := c.reader.Stats().Lag
+	consumerLag.WithLabelValues(c.topic).Set(float64(lag))
+
+	now := time.Now()
+	c.mu.Lock()
+	c.busy.roll(now)
+	utilization := c.busy.last
+	partitions := make([]PartitionStats, 0, len(c.partitions))
+	for id, partition := range c.partitions {
+		if now.Sub(partition.LastReadAt) > AssignmentWindow {
+			delete(c.partitions, id)
+			consumerPartitionOffset.DeleteLabelValues(c.topic, strconv.Itoa(id))
+			c.logger.Info("Stopped reading partition", "topic", c.topic, "partition", id)
+			continue
+		}
+		partitions = append(partitions, partition)
+	}
+	c.mu.Unlock()
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Partition < partitions[j].Partition })
+
+	consumerUtilization.WithLabelValues(c.topic).Set(utilization)
+	consumerAssignedPartitions.WithLabelValues(c.topic).Set(float64(len(partitions)))
+
+	return ConsumerStats{
+		Topic:       c.topic,
+		Lag:         lag,
+		Handled:     c.handled.Load(),
+		Failed:      c.failed.Load(),
+		InFlight:    c.inFlight.Load(),
+		Utilization: utilization,
+		Partitions:  partitions,
+	}
 }
 
 // Close closes the Kafka reader
@@ -0,0 +1,66 @@
+// internal/queue/metrics.go
+package queue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// consumerMessages counts consumed messages by handler result; rate() over it gives the
+// processing and error rates
+var consumerMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_consumer_messages_total",
+	Help: "Messages consumed, by topic and handler result (success or error).",
+}, []string{"topic", "result"})
+
+// consumerHandlerDuration observes how long the handler takes per message
+var consumerHandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "whatsapp_consumer_handler_duration_seconds",
+	Help:    "Time the consumer's handler took per message.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"topic"})
+
+// consumerLag is the consumer group's lag, i.e. the depth of the queue still to be read
+var consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "whatsapp_consumer_lag",
+	Help: "Messages the consumer group is behind the end of the topic.",
+}, []string{"topic"})
+
+// consumerPaused is 1 while the consumer holds off reading because its gate is closed
+var consumerPaused = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "whatsapp_consumer_paused",
+	Help: "Whether the consumer has stopped reading because the provider cannot take sends.",
+}, []string{"topic"})
+
+// consumerInFlight, consumerUtilization and consumerBusySeconds show how busy the handler of
+// this replica is; utilization near 1 means adding replicas (and partitions) would help
+var (
+	consumerInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_consumer_in_flight",
+		Help: "Messages the consumer's handler is working on.",
+	}, []string{"topic"})
+
+	consumerUtilization = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_consumer_utilization",
+		Help: "Share of the last full minute the consumer's handler was busy, from 0 to 1.",
+	}, []string{"topic"})
+
+	consumerBusySeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_consumer_busy_seconds_total",
+		Help: "Time the consumer's handler spent on messages.",
+	}, []string{"topic"})
+)
+
+// consumerAssignedPartitions and consumerPartitionOffset show which partitions this replica
+// reads, as observed from its reads within queue.AssignmentWindow
+var (
+	consumerAssignedPartitions = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_consumer_assigned_partitions",
+		Help: "Partitions this replica read from recently.",
+	}, []string{"topic"})
+
+	consumerPartitionOffset = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_consumer_partition_offset",
+		Help: "Last offset this replica read, by partition.",
+	}, []string{"topic", "partition"})
+)
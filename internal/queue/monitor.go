@@ -0,0 +1,119 @@
+// internal/queue/monitor.go
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/utils"
+)
+
+// MonitorConfig holds the thresholds a consumer monitor alerts on
+type MonitorConfig struct {
+	// MaxLag is the highest acceptable consumer lag; 0 disables the lag alert
+	MaxLag int64
+	// MaxFailureRate is the highest acceptable share of handler errors per interval, between 0
+	// and 1; 0 disables the failure rate alert
+	MaxFailureRate float64
+	// MinMessages is how many messages an interval needs before its failure rate is judged
+	MinMessages int64
+}
+
+// ConsumerMonitor watches a consumer's lag and handler failure rate and alerts when either
+// crosses its threshold
+type ConsumerMonitor interface {
+	// Check compares the consumer's stats since the previous check against the thresholds
+	Check(ctx context.Context)
+	// Run checks every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// consumerMonitor implements ConsumerMonitor
+type consumerMonitor struct {
+	consumer Consumer
+	cfg      MonitorConfig
+	notifier alerts.Notifier
+	logger   utils.Logger
+
+	last   ConsumerStats
+	firing map[string]bool
+}
+
+// NewConsumerMonitor creates a consumer monitor. Alerts go to notifier, or are only logged
+// when it is nil.
+func NewConsumerMonitor(consumer Consumer, cfg MonitorConfig, notifier alerts.Notifier, logger utils.Logger) ConsumerMonitor {
+	return &consumerMonitor{
+		consumer: consumer,
+		cfg:      cfg,
+		notifier: notifier,
+		logger:   logger,
+		firing:   make(map[string]bool),
+	}
+}
+
+// Check evaluates both thresholds
+func (m *consumerMonitor) Check(ctx context.Context) {
+	stats := m.consumer.Stats()
+	handled, failed := stats.Handled-m.last.Handled, stats.Failed-m.last.Failed
+	m.last = stats
+
+	if m.cfg.MaxLag > 0 {
+		m.evaluate(ctx, alerts.Alert{
+			Key:       "consumer_lag:" + stats.Topic,
+			Summary:   fmt.Sprintf("Consumer lag on %s is %d messages", stats.Topic, stats.Lag),
+			Value:     float64(stats.Lag),
+			Threshold: float64(m.cfg.MaxLag),
+		}, stats.Lag > m.cfg.MaxLag)
+	}
+
+	// Quiet intervals say nothing about the failure rate, so the alert keeps its state
+	if m.cfg.MaxFailureRate > 0 && handled > 0 && handled >= m.cfg.MinMessages {
+		rate := float64(failed) / float64(handled)
+		m.evaluate(ctx, alerts.Alert{
+			Key:       "consumer_failure_rate:" + stats.Topic,
+			Summary:   fmt.Sprintf("%.1f%% of messages on %s failed (%d of %d)", rate*100, stats.Topic, failed, handled),
+			Value:     rate,
+			Threshold: m.cfg.MaxFailureRate,
+		}, rate > m.cfg.MaxFailureRate)
+	}
+}
+
+// evaluate notifies when a condition starts or stops firing
+func (m *consumerMonitor) evaluate(ctx context.Context, alert alerts.Alert, firing bool) {
+	if firing == m.firing[alert.Key] {
+		return
+	}
+	m.firing[alert.Key] = firing
+	alert.Resolved = !firing
+
+	if firing {
+		m.logger.Warn("Consumer alert firing", "alert", alert.Key, "value", alert.Value, "threshold", alert.Threshold)
+	} else {
+		m.logger.Info("Consumer alert resolved", "alert", alert.Key, "value", alert.Value, "threshold", alert.Threshold)
+	}
+	if m.notifier == nil {
+		return
+	}
+	if err := m.notifier.Notify(ctx, alert); err != nil {
+		m.logger.Error("Failed to send consumer alert", "error", err, "alert", alert.Key)
+	}
+}
+
+// Run checks every interval, starting one interval after the call so the first failure rate
+// covers a full interval
+func (m *consumerMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.last = m.consumer.Stats()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package queue
 
 import (
     "context"
+    "errors"
+    "fmt"
     "time"
 
     "github.com/segmentio/kafka-go"
@@ -12,32 +14,120 @@ import (
 // Producer defines the interface for message producers
 type Producer interface {
     Produce(ctx context.Context, value []byte) error
+    ProduceWithKey(ctx context.Context, key, value []byte) error
+    Close() error
+}
+
+// kafkaWriter is the subset of kafka.Writer used by the producer
+type kafkaWriter interface {
+    WriteMessages(ctx context.Context, msgs ...kafka.Message) error
     Close() error
 }
 
 // kafkaProducer implements Producer using Kafka
 type kafkaProducer struct {
-    writer *kafka.Writer
-    logger utils.Logger
+    writer  kafkaWriter
+    timeout utils.CallTimeout
+    logger  utils.Logger
 }
 
 // WriterCreator is a function type for creating Kafka writers
 // Used for testing to inject mocks
 type WriterCreator func(brokers []string, topic string, logger utils.Logger) (interface{}, error)
 
+// DeliveryFunc is called once the broker has acknowledged, or finally refused, a message
+// produced with a context carrying its message ID (see WithMessageID)
+type DeliveryFunc func(messageID int64, err error)
+
+// ProducerConfig tunes how a producer batches and writes messages. Zero values keep the
+// kafka-go defaults: batches of 100 messages or 1MB, flushed at least every second.
+type ProducerConfig struct {
+    // BatchSize is the most messages written in one request
+    BatchSize int
+    // BatchBytes is the largest request size in bytes
+    BatchBytes int64
+    // BatchTimeout is how long a partial batch lingers before it is written
+    BatchTimeout time.Duration
+    // Compression is the codec for batches: "none", "gzip", "snappy", "lz4" or "zstd"
+    Compression string
+    // Async makes Produce return once the message is buffered instead of written; write
+    // errors are then only reported to OnDelivery
+    Async bool
+    // OnDelivery receives the outcome of every write of a message tagged with its ID
+    OnDelivery DeliveryFunc
+    // WriteTimeout bounds each Produce call by its caller's deadline; zero uses
+    // utils.DefaultCallTimeout. A write that times out may still reach the broker.
+    WriteTimeout utils.CallTimeout
+}
+
+// compressionCodecs maps the configurable codec names to kafka-go's
+var compressionCodecs = map[string]kafka.Compression{
+    "gzip":   kafka.Gzip,
+    "snappy": kafka.Snappy,
+    "lz4":    kafka.Lz4,
+    "zstd":   kafka.Zstd,
+}
+
+// ValidCompression reports whether name is a supported compression codec
+func ValidCompression(name string) bool {
+    _, ok := compressionCodecs[name]
+    return ok || name == "" || name == "none"
+}
+
+type messageIDContextKey struct{}
+
+// WithMessageID returns a copy of ctx that tags produced messages with a message ID, so the
+// producer's OnDelivery hook can report their outcome
+func WithMessageID(ctx context.Context, id int64) context.Context {
+    return context.WithValue(ctx, messageIDContextKey{}, id)
+}
+
 // NewProducer creates a new Kafka producer
 func NewProducer(brokers []string, topic string, logger utils.Logger) (Producer, error) {
+    return NewProducerWithConfig(brokers, topic, ProducerConfig{}, logger)
+}
+
+// NewProducerWithConfig creates a Kafka producer with batching, compression and delivery
+// reporting settings
+func NewProducerWithConfig(brokers []string, topic string, cfg ProducerConfig, logger utils.Logger) (Producer, error) {
+    if !ValidCompression(cfg.Compression) {
+        return nil, fmt.Errorf("unknown compression codec %q", cfg.Compression)
+    }
+
     writer := &kafka.Writer{
         Addr:         kafka.TCP(brokers...),
         Topic:        topic,
-        Balancer:     &kafka.LeastBytes{},
+        Balancer:     &kafka.Hash{}, // keyed messages keep per-key ordering, unkeyed ones are spread round-robin
         RequiredAcks: kafka.RequireOne,
-        Async:        false,
+        BatchSize:    cfg.BatchSize,
+        BatchBytes:   cfg.BatchBytes,
+        BatchTimeout: cfg.BatchTimeout,
+        Compression:  compressionCodecs[cfg.Compression],
+        Async:        cfg.Async,
+    }
+    if cfg.OnDelivery != nil {
+        // Completion runs after every batch in both modes
+        writer.Completion = func(messages []kafka.Message, err error) {
+            for _, msg := range messages {
+                if id, ok := msg.WriterData.(int64); ok {
+                    cfg.OnDelivery(id, err)
+                }
+            }
+            if err != nil && cfg.Async {
+                logger.Error("Failed to write messages to Kafka", "error", err, "topic", topic, "count", len(messages))
+            }
+        }
+    }
+
+    timeout := cfg.WriteTimeout
+    if timeout == (utils.CallTimeout{}) {
+        timeout = utils.DefaultCallTimeout
     }
 
     return &kafkaProducer{
-        writer: writer,
-        logger: logger,
+        writer:  writer,
+        timeout: timeout,
+        logger:  logger,
     }, nil
 }
 
@@ -49,18 +139,40 @@ func NewProducerWithWriter(brokers []string, topic string, logger utils.Logger,
         return nil, err
     }
 
+    kw, ok := writer.(kafkaWriter)
+    if !ok {
+        return nil, errors.New("writer does not implement WriteMessages and Close")
+    }
+
     return &kafkaProducer{
-        writer: writer.(*kafka.Writer),
-        logger: logger,
+        writer:  kw,
+        timeout: utils.DefaultCallTimeout,
+        logger:  logger,
     }, nil
 }
 
 // Produce sends a message to Kafka
 func (p *kafkaProducer) Produce(ctx context.Context, value []byte) error {
+    return p.ProduceWithKey(ctx, nil, value)
+}
+
+// ProduceWithKey sends a keyed message to Kafka. Messages sharing a key are
+// routed to the same partition, which preserves their relative order.
+func (p *kafkaProducer) ProduceWithKey(ctx context.Context, key, value []byte) error {
     msg := kafka.Message{
+        Key:   key,
         Value: value,
         Time:  time.Now(),
     }
+    if id, ok := ctx.Value(messageIDContextKey{}).(int64); ok {
+        msg.WriterData = id
+    }
+
+    ctx, cancel, err := p.timeout.Context(ctx)
+    if err != nil {
+        return err
+    }
+    defer cancel()
 
     if err := p.writer.WriteMessages(ctx, msg); err != nil {
         p.logger.Error("Failed to write message to Kafka", "error", err)
@@ -0,0 +1,50 @@
+// internal/queue/registry.go
+package queue
+
+import (
+	"context"
+
+	"messaging-microservice/pkg/schemaregistry"
+)
+
+// registryProducer serializes JSON payloads with a registered schema before producing them
+type registryProducer struct {
+	Producer
+	serializer schemaregistry.Serializer
+}
+
+// NewRegistryProducer wraps a producer so every payload is written in the schema registry's
+// wire format
+func NewRegistryProducer(inner Producer, serializer schemaregistry.Serializer) Producer {
+	return &registryProducer{Producer: inner, serializer: serializer}
+}
+
+// Produce serializes and produces a payload
+func (p *registryProducer) Produce(ctx context.Context, value []byte) error {
+	data, err := p.serializer.Serialize(ctx, value)
+	if err != nil {
+		return err
+	}
+	return p.Producer.Produce(ctx, data)
+}
+
+// ProduceWithKey serializes and produces a keyed payload
+func (p *registryProducer) ProduceWithKey(ctx context.Context, key, value []byte) error {
+	data, err := p.serializer.Serialize(ctx, value)
+	if err != nil {
+		return err
+	}
+	return p.Producer.ProduceWithKey(ctx, key, data)
+}
+
+// RegistryHandler wraps a handler so registry-framed payloads reach it as JSON. Plain JSON
+// payloads pass through, so the consumer keeps working while producers switch over.
+func RegistryHandler(handler MessageHandler, deserializer schemaregistry.Deserializer) MessageHandler {
+	return func(ctx context.Context, data []byte) error {
+		decoded, err := deserializer.Deserialize(ctx, data)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, decoded)
+	}
+}
@@ -0,0 +1,89 @@
+// internal/queue/retry.go
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// retriedMessages counts messages moved to a retry topic or the dead-letter topic
+var retriedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_queue_retries_total",
+	Help: "Failed messages moved on to a delayed retry tier or the dead-letter topic, by tier (delay or dlq).",
+}, []string{"tier"})
+
+// RetryConfig controls how failed messages move through the delayed retry topics
+type RetryConfig struct {
+	// Delays are the retry tiers: a message failing on the main topic is retried from the
+	// first tier's topic once Delays[0] has passed, and so on; after the last it is dead-lettered
+	Delays []time.Duration
+	// IsRetryable reports whether a handler error is transient; other failures are final and
+	// the message is neither retried nor dead-lettered
+	IsRetryable func(error) bool
+	// OnRetry is called after a message was moved to the retry tier with the given delay
+	OnRetry func(ctx context.Context, data []byte, delay time.Duration, err error)
+	// OnDeadLetter is called after a message was moved to the dead-letter topic
+	OnDeadLetter func(ctx context.Context, data []byte, err error)
+}
+
+// RetryTopic names the retry topic of a tier, e.g. whatsapp-messages.retry.10m
+func RetryTopic(topic string, delay time.Duration) string {
+	return topic + ".retry." + formatDelay(delay)
+}
+
+// DeadLetterTopic names the topic messages land in once every retry failed
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// formatDelay writes a delay in its largest whole unit, e.g. 1h, 10m or 90s
+func formatDelay(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
+
+// RetryHandler wraps the handler of one stage of the retry chain: stage 0 is the main topic
+// and stage i the retry tier with Delays[i-1]. Transient failures are produced to next, which
+// writes to the following tier, or to the dead-letter topic after the last one.
+func RetryHandler(handler MessageHandler, stage int, next Producer, cfg RetryConfig, logger utils.Logger) MessageHandler {
+	return func(ctx context.Context, data []byte) error {
+		err := handler(ctx, data)
+		if err == nil || cfg.IsRetryable == nil || !cfg.IsRetryable(err) {
+			return err
+		}
+
+		if produceErr := next.Produce(ctx, data); produceErr != nil {
+			logger.Error("Failed to move message along the retry chain", "error", produceErr, "stage", stage)
+			return errors.Join(err, produceErr)
+		}
+
+		if stage < len(cfg.Delays) {
+			delay := cfg.Delays[stage]
+			retriedMessages.WithLabelValues(formatDelay(delay)).Inc()
+			logger.Info("Scheduled message for retry", "stage", stage, "delay", delay, "error", err)
+			if cfg.OnRetry != nil {
+				cfg.OnRetry(ctx, data, delay, err)
+			}
+		} else {
+			retriedMessages.WithLabelValues("dlq").Inc()
+			logger.Warn("Dead-lettered message after its last retry", "stage", stage, "error", err)
+			if cfg.OnDeadLetter != nil {
+				cfg.OnDeadLetter(ctx, data, err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,211 @@
+// internal/queue/topics.go
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// adminTimeout bounds each metadata or topic creation request
+const adminTimeout = 10 * time.Second
+
+// RetryChainTopics returns the retry tier topics of topic followed by its dead-letter topic,
+// or nothing when there are no retry tiers
+func RetryChainTopics(topic string, delays []time.Duration) []string {
+	if len(delays) == 0 {
+		return nil
+	}
+	topics := make([]string, 0, len(delays)+1)
+	for _, delay := range delays {
+		topics = append(topics, RetryTopic(topic, delay))
+	}
+	return append(topics, DeadLetterTopic(topic))
+}
+
+// TopicSpec describes a topic the service needs. Zero Partitions or ReplicationFactor leave
+// the choice to the broker's defaults when the topic is created.
+type TopicSpec struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+}
+
+// EnsureTopics checks that a broker is reachable and that topics exist. Missing topics are
+// created as specified when create is set, and reported as an error otherwise. Existing
+// topics are left as they are. It returns the names of the topics it created.
+func EnsureTopics(ctx context.Context, brokers []string, topics []TopicSpec, create bool) ([]string, error) {
+	conn, err := dialAny(ctx, brokers)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	missing, err := missingTopics(conn, topics)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("kafka topics do not exist: %s", strings.Join(topicNames(missing), ", "))
+	}
+
+	// Topics can only be created through the controller
+	controller, err := conn.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find kafka controller: %w", err)
+	}
+	controllerConn, err := kafka.DialContext(ctx, "tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka controller: %w", err)
+	}
+	defer controllerConn.Close()
+
+	configs := make([]kafka.TopicConfig, 0, len(missing))
+	for _, topic := range missing {
+		configs = append(configs, kafka.TopicConfig{
+			Topic:             topic.Name,
+			NumPartitions:     orBrokerDefault(topic.Partitions),
+			ReplicationFactor: orBrokerDefault(topic.ReplicationFactor),
+		})
+	}
+	controllerConn.SetDeadline(time.Now().Add(adminTimeout))
+	if err := controllerConn.CreateTopics(configs...); err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return nil, fmt.Errorf("failed to create kafka topics %s: %w", strings.Join(topicNames(missing), ", "), err)
+	}
+	return topicNames(missing), nil
+}
+
+// orBrokerDefault maps an unset count to -1, which asks the broker for its default
+func orBrokerDefault(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+// topicNames lists the names of topics
+func topicNames(topics []TopicSpec) []string {
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	return names
+}
+
+// TopicDepth returns how many messages of topic the consumer group has not consumed yet, e.g.
+// the dead-letter messages waiting to be replayed. Without a committed offset on a partition
+// all of its retained messages count.
+func TopicDepth(ctx context.Context, brokers []string, topic, groupID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+	defer cancel()
+
+	conn, err := dialAny(ctx, brokers)
+	if err != nil {
+		return 0, err
+	}
+	conn.SetDeadline(time.Now().Add(adminTimeout))
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read partitions of %s: %w", topic, err)
+	}
+
+	ids := make([]int, 0, len(partitions))
+	requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+	for _, partition := range partitions {
+		ids = append(ids, partition.ID)
+		requests = append(requests, kafka.FirstOffsetOf(partition.ID), kafka.LastOffsetOf(partition.ID))
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: adminTimeout}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list offsets of %s: %w", topic, err)
+	}
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{topic: ids}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch offsets of group %s: %w", groupID, err)
+	}
+	if committed.Error != nil {
+		return 0, fmt.Errorf("failed to fetch offsets of group %s: %w", groupID, committed.Error)
+	}
+
+	commits := make(map[int]int64, len(ids))
+	for _, partition := range committed.Topics[topic] {
+		commits[partition.Partition] = partition.CommittedOffset
+	}
+	var depth int64
+	for _, partition := range offsets.Topics[topic] {
+		if partition.Error != nil {
+			return 0, fmt.Errorf("failed to list offsets of %s partition %d: %w", topic, partition.Partition, partition.Error)
+		}
+		// A commit older than the retained messages, or none (-1), counts from the first
+		start := partition.FirstOffset
+		if commit, ok := commits[partition.Partition]; ok && commit > start {
+			start = commit
+		}
+		if partition.LastOffset > start {
+			depth += partition.LastOffset - start
+		}
+	}
+	return depth, nil
+}
+
+// dialAny connects to the first broker that answers
+func dialAny(ctx context.Context, brokers []string) (*kafka.Conn, error) {
+	var errs []error
+	for _, broker := range brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("no kafka broker reachable: %w", errors.Join(errs...))
+}
+
+// missingTopics returns the topics the cluster does not know about
+func missingTopics(conn *kafka.Conn, topics []TopicSpec) ([]TopicSpec, error) {
+	conn.SetDeadline(time.Now().Add(adminTimeout))
+	partitions, err := conn.ReadPartitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kafka metadata: %w", err)
+	}
+
+	existing := make(map[string]bool, len(partitions))
+	for _, partition := range partitions {
+		existing[partition.Topic] = true
+	}
+	var missing []TopicSpec
+	for _, topic := range topics {
+		if !existing[topic.Name] {
+			missing = append(missing, topic)
+		}
+	}
+	return missing, nil
+}
+
+// ParseTopicLayout parses a topic's layout written as "partitions[:replication]"
+func ParseTopicLayout(value string) (partitions, replicationFactor int, err error) {
+	partitionsPart, replicationPart, hasReplication := strings.Cut(strings.TrimSpace(value), ":")
+	partitions, err = strconv.Atoi(partitionsPart)
+	if err != nil || partitions <= 0 {
+		return 0, 0, fmt.Errorf("invalid topic layout %q: partitions must be a positive integer", value)
+	}
+	if hasReplication {
+		replicationFactor, err = strconv.Atoi(replicationPart)
+		if err != nil || replicationFactor <= 0 {
+			return 0, 0, fmt.Errorf("invalid topic layout %q: replication factor must be a positive integer", value)
+		}
+	}
+	return partitions, replicationFactor, nil
+}
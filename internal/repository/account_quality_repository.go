@@ -0,0 +1,154 @@
+// internal/repository/account_quality_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// AccountQualityRepository stores the account events Meta reports and the quality rating and
+// messaging limit they leave each business phone number with
+type AccountQualityRepository interface {
+	// RecordEvent stores an event and applies its rating and tier to the number's current state
+	RecordEvent(ctx context.Context, event *domain.AccountEvent) error
+	// ListQuality returns the current state of a tenant's numbers; an empty tenant lists all
+	ListQuality(ctx context.Context, tenantID string) ([]domain.AccountQuality, error)
+	// ListEvents returns a tenant's most recent events, newest first
+	ListEvents(ctx context.Context, tenantID string, limit int) ([]domain.AccountEvent, error)
+}
+
+// accountQualityModel represents a number's current quality in the database
+type accountQualityModel struct {
+	TenantID           string         `db:"tenant_id"`
+	PhoneNumber        string         `db:"phone_number"`
+	QualityRating      string         `db:"quality_rating"`
+	MessagingLimitTier sql.NullString `db:"messaging_limit_tier"`
+	LastEvent          string         `db:"last_event"`
+	UpdatedAt          time.Time      `db:"updated_at"`
+}
+
+// accountEventModel represents an account event in the database
+type accountEventModel struct {
+	ID                 int64          `db:"id"`
+	TenantID           string         `db:"tenant_id"`
+	PhoneNumber        string         `db:"phone_number"`
+	Field              string         `db:"field"`
+	Event              string         `db:"event"`
+	QualityRating      sql.NullString `db:"quality_rating"`
+	MessagingLimitTier sql.NullString `db:"messaging_limit_tier"`
+	Detail             sql.NullString `db:"detail"`
+	ReceivedAt         time.Time      `db:"received_at"`
+}
+
+// accountQualityRepository implements AccountQualityRepository
+type accountQualityRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewAccountQualityRepository creates a new account quality repository
+func NewAccountQualityRepository(db *sqlx.DB, logger utils.Logger) AccountQualityRepository {
+	return &accountQualityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordEvent inserts the event and upserts the number's state in one statement. An event that
+// doesn't report a rating or tier keeps the number's previous one.
+func (r *accountQualityRepository) RecordEvent(ctx context.Context, event *domain.AccountEvent) error {
+	query := `
+		WITH event AS (
+			INSERT INTO account_events (tenant_id, phone_number, field, event, quality_rating, messaging_limit_tier, detail, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		), quality AS (
+			INSERT INTO account_quality (tenant_id, phone_number, quality_rating, messaging_limit_tier, last_event, updated_at)
+			VALUES ($1, $2, COALESCE($5, $9), $6, $4, $8)
+			ON CONFLICT (tenant_id, phone_number)
+			DO UPDATE SET quality_rating = COALESCE($5, account_quality.quality_rating),
+				messaging_limit_tier = COALESCE($6, account_quality.messaging_limit_tier),
+				last_event = EXCLUDED.last_event,
+				updated_at = EXCLUDED.updated_at
+		)
+		SELECT id FROM event
+	`
+
+	nullable := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	return r.db.GetContext(ctx, &event.ID, query,
+		event.TenantID,
+		event.PhoneNumber,
+		event.Field,
+		event.Event,
+		nullable(event.QualityRating),
+		nullable(event.MessagingLimitTier),
+		nullable(event.Detail),
+		event.ReceivedAt,
+		domain.QualityUnknown,
+	)
+}
+
+// ListQuality returns the numbers' current state ordered by tenant and number
+func (r *accountQualityRepository) ListQuality(ctx context.Context, tenantID string) ([]domain.AccountQuality, error) {
+	query := `
+		SELECT tenant_id, phone_number, quality_rating, messaging_limit_tier, last_event, updated_at
+		FROM account_quality
+		WHERE $1 = '' OR tenant_id = $1
+		ORDER BY tenant_id, phone_number
+	`
+
+	var models []accountQualityModel
+	if err := r.db.SelectContext(ctx, &models, query, tenantID); err != nil {
+		return nil, err
+	}
+
+	qualities := make([]domain.AccountQuality, 0, len(models))
+	for _, model := range models {
+		qualities = append(qualities, domain.AccountQuality{
+			TenantID:           model.TenantID,
+			PhoneNumber:        model.PhoneNumber,
+			QualityRating:      model.QualityRating,
+			MessagingLimitTier: model.MessagingLimitTier.String,
+			LastEvent:          model.LastEvent,
+			UpdatedAt:          model.UpdatedAt,
+		})
+	}
+	return qualities, nil
+}
+
+// ListEvents returns up to limit of a tenant's events, newest first
+func (r *accountQualityRepository) ListEvents(ctx context.Context, tenantID string, limit int) ([]domain.AccountEvent, error) {
+	query := `
+		SELECT id, tenant_id, phone_number, field, event, quality_rating, messaging_limit_tier, detail, received_at
+		FROM account_events
+		WHERE tenant_id = $1
+		ORDER BY received_at DESC, id DESC
+		LIMIT $2
+	`
+
+	var models []accountEventModel
+	if err := r.db.SelectContext(ctx, &models, query, tenantID, limit); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.AccountEvent, 0, len(models))
+	for _, model := range models {
+		events = append(events, domain.AccountEvent{
+			ID:                 model.ID,
+			TenantID:           model.TenantID,
+			PhoneNumber:        model.PhoneNumber,
+			Field:              model.Field,
+			Event:              model.Event,
+			QualityRating:      model.QualityRating.String,
+			MessagingLimitTier: model.MessagingLimitTier.String,
+			Detail:             model.Detail.String,
+			ReceivedAt:         model.ReceivedAt,
+		})
+	}
+	return events, nil
+}
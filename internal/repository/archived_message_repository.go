@@ -0,0 +1,203 @@
+// internal/repository/archived_message_repository.go
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/objectstore"
+	"messaging-microservice/pkg/utils"
+)
+
+// archiveErasePageSize is the page size used when finding the archived messages an erasure covers
+const archiveErasePageSize = 500
+
+// archivedMessageRepository resolves stub rows left by the archiver back into full
+// messages on single-message lookups. List queries return the stubs as they are.
+type archivedMessageRepository struct {
+	MessageRepository
+	store  objectstore.Store
+	logger utils.Logger
+}
+
+// NewArchivedMessageRepository wraps repo so GetMessage* calls transparently read archived
+// messages from store, and erasures remove them from it
+func NewArchivedMessageRepository(repo MessageRepository, store objectstore.Store, logger utils.Logger) MessageRepository {
+	return &archivedMessageRepository{
+		MessageRepository: repo,
+		store:             store,
+		logger:            logger,
+	}
+}
+
+// GetMessageByID retrieves a message by ID, reading it from the archive if needed
+func (r *archivedMessageRepository) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
+	msg, err := r.MessageRepository.GetMessageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, msg)
+}
+
+// GetMessageByExternalID retrieves a message by external ID, reading it from the archive if needed
+func (r *archivedMessageRepository) GetMessageByExternalID(ctx context.Context, externalID string) (*domain.Message, error) {
+	msg, err := r.MessageRepository.GetMessageByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, msg)
+}
+
+// GetTenantMessageByExternalID retrieves a tenant's message by external ID, reading it from the archive if needed
+func (r *archivedMessageRepository) GetTenantMessageByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Message, error) {
+	msg, err := r.MessageRepository.GetTenantMessageByExternalID(ctx, tenantID, externalID)
+	if err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, msg)
+}
+
+// EraseMessages removes the messages matching the filter from their archive objects, then
+// erases their rows. The archives go first so a failed erasure can be retried: the rows keep
+// matching the filter until they are erased.
+func (r *archivedMessageRepository) EraseMessages(ctx context.Context, filter domain.MessageFilter, hardDelete bool) (int64, error) {
+	filter.IncludeDeleted = true
+	if filter == (domain.MessageFilter{IncludeDeleted: true}) {
+		return 0, errors.New("refusing to erase messages without a filter")
+	}
+
+	archived := make(map[string]map[int64]bool)
+	var afterID int64
+	for {
+		messages, err := r.MessageRepository.ListMessagesAfterID(ctx, filter, afterID, archiveErasePageSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, msg := range messages {
+			if msg.ArchiveKey == "" || !msg.ErasedAt.IsZero() {
+				continue
+			}
+			if archived[msg.ArchiveKey] == nil {
+				archived[msg.ArchiveKey] = make(map[int64]bool)
+			}
+			archived[msg.ArchiveKey][msg.ID] = true
+		}
+		if len(messages) < archiveErasePageSize {
+			break
+		}
+		afterID = messages[len(messages)-1].ID
+	}
+
+	for key, ids := range archived {
+		if err := r.removeArchived(ctx, key, ids); err != nil {
+			r.logger.Error("Failed to erase archived messages", "error", err, "archive_key", key, "count", len(ids))
+			return 0, err
+		}
+	}
+	return r.MessageRepository.EraseMessages(ctx, filter, hardDelete)
+}
+
+// restore replaces a stub with the archived message. The stub's status fields are newer
+// than the archive (webhooks keep updating them), so they win. Erased messages are no
+// longer in the archive and are returned as their stub.
+func (r *archivedMessageRepository) restore(ctx context.Context, stub *domain.Message) (*domain.Message, error) {
+	if stub.ArchiveKey == "" || !stub.ErasedAt.IsZero() {
+		return stub, nil
+	}
+
+	archived, err := r.readArchived(ctx, stub.ArchiveKey, stub.ID)
+	if err != nil {
+		r.logger.Error("Failed to read archived message", "error", err, "message_id", stub.ID, "archive_key", stub.ArchiveKey)
+		return nil, err
+	}
+
+	archived.Status = stub.Status
+	archived.ErrorCode = stub.ErrorCode
+	archived.ExternalID = stub.ExternalID
+	archived.UpdatedAt = stub.UpdatedAt
+	archived.ArchiveKey = stub.ArchiveKey
+	archived.DeletedAt = stub.DeletedAt
+	return archived, nil
+}
+
+// readArchived finds one message in a gzip-compressed JSONL archive
+func (r *archivedMessageRepository) readArchived(ctx context.Context, key string, id int64) (*domain.Message, error) {
+	data, err := r.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *domain.Message
+	err = scanArchive(data, func(line []byte, msg *domain.Message) error {
+		if msg.ID == id {
+			found = msg
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("message %d missing from archive %s", id, key)
+	}
+	return found, nil
+}
+
+// removeArchived rewrites an archive without the messages with the given IDs
+func (r *archivedMessageRepository) removeArchived(ctx context.Context, key string, ids map[int64]bool) error {
+	data, err := r.store.Get(ctx, key)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	err = scanArchive(data, func(line []byte, msg *domain.Message) error {
+		if ids[msg.ID] {
+			return nil
+		}
+		if _, err := gz.Write(line); err != nil {
+			return err
+		}
+		_, err := gz.Write([]byte("\n"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return r.store.Put(ctx, key, buf.Bytes(), "application/gzip")
+}
+
+// scanArchive passes each line of a gzip-compressed JSONL archive to fn with its message
+func scanArchive(data []byte, fn func(line []byte, msg *domain.Message) error) error {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg domain.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return err
+		}
+		if err := fn(scanner.Bytes(), &msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,127 @@
+// internal/repository/audit_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+	RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) (int64, error)
+	// ListAuditEntries returns the entries matching filter, newest first
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// auditEntryModel represents an audit log entry in the database
+type auditEntryModel struct {
+	ID           int64          `db:"id"`
+	Action       string         `db:"action"`
+	TenantID     string         `db:"tenant_id"`
+	SubjectType  string         `db:"subject_type"`
+	Subject      string         `db:"subject"`
+	Actor        string         `db:"actor"`
+	Reason       sql.NullString `db:"reason"`
+	AffectedRows int64          `db:"affected_rows"`
+	Before       []byte         `db:"before_state"`
+	After        []byte         `db:"after_state"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+// auditRepository implements AuditRepository
+type auditRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sqlx.DB, logger utils.Logger) AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordAuditEntry appends an entry to the audit log
+func (r *auditRepository) RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) (int64, error) {
+	query := `
+		INSERT INTO audit_log (
+			action, tenant_id, subject_type, subject, actor, reason, affected_rows, before_state, after_state, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING id
+	`
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	var id int64
+	if err := r.db.GetContext(ctx, &id, query,
+		entry.Action, entry.TenantID, entry.SubjectType, entry.Subject,
+		entry.Actor, entry.Reason, entry.AffectedRows,
+		auditState(entry.Before), auditState(entry.After), entry.CreatedAt,
+	); err != nil {
+		return 0, err
+	}
+
+	entry.ID = id
+	return id, nil
+}
+
+// ListAuditEntries returns up to filter.Limit matching entries, newest first. A tenant's
+// entries include those of changes reaching every tenant, which have an empty tenant_id.
+func (r *auditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	q := newQuery(`
+		SELECT id, action, tenant_id, subject_type, subject, actor, reason, affected_rows, before_state, after_state, created_at
+		FROM audit_log
+	`)
+
+	if filter.TenantID != "" {
+		q.Where("tenant_id IN (" + q.Arg(filter.TenantID) + ", '')")
+	}
+	q.WhereEq("action", filter.Action)
+	q.WhereEq("actor", filter.Actor)
+	q.WhereEq("subject_type", filter.SubjectType)
+	q.WhereEq("subject", filter.Subject)
+	q.WhereTime("created_at", ">=", filter.Since)
+	q.WhereTime("created_at", "<", filter.Until)
+	q.Append(" ORDER BY created_at DESC, id DESC LIMIT " + q.Arg(filter.Limit))
+
+	var models []auditEntryModel
+	if err := r.db.SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.AuditEntry, 0, len(models))
+	for _, model := range models {
+		entries = append(entries, domain.AuditEntry{
+			ID:           model.ID,
+			Action:       model.Action,
+			TenantID:     model.TenantID,
+			SubjectType:  model.SubjectType,
+			Subject:      model.Subject,
+			Actor:        model.Actor,
+			Reason:       model.Reason.String,
+			AffectedRows: model.AffectedRows,
+			Before:       json.RawMessage(model.Before),
+			After:        json.RawMessage(model.After),
+			CreatedAt:    model.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// auditState converts a state snapshot to a JSONB parameter, NULL when there is none
+func auditState(state json.RawMessage) interface{} {
+	if len(state) == 0 {
+		return nil
+	}
+	return string(state)
+}
@@ -0,0 +1,438 @@
+// internal/repository/campaign_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// Final statuses of campaign recipients; they are "pending" until claimed and "sending" while claimed
+const (
+	recipientSent   = "sent"
+	recipientFailed = "failed"
+)
+
+// CampaignRepository stores campaigns and the audiences materialized when they start
+type CampaignRepository interface {
+	// CreateCampaign stores a campaign along with its variants
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) error
+	// GetCampaign returns a campaign of the tenant with its variants and recipient counts
+	GetCampaign(ctx context.Context, tenantID string, id int64) (*domain.Campaign, error)
+	// ListCampaignVariants returns a campaign's variants in the order they were given
+	ListCampaignVariants(ctx context.Context, campaignID int64) ([]domain.CampaignVariant, error)
+	// StartCampaign moves a draft campaign to running and materializes the segment's audience
+	// at now into its recipients, in one statement; a nil segment adds none. It reports whether
+	// the campaign was a draft.
+	StartCampaign(ctx context.Context, campaign *domain.Campaign, segment *domain.Segment, now time.Time) (bool, error)
+	// ImportCampaignRecipients adds recipients to a draft campaign, replacing the parameters of
+	// those already in it. Contacts whose latest opt-in event is an opt-out are left out, and
+	// removed if imported before; their phone numbers are returned.
+	ImportCampaignRecipients(ctx context.Context, campaign *domain.Campaign, recipients []domain.CampaignRecipient) (int64, []string, error)
+	// ClaimCampaignRecipients claims up to limit recipients of running campaigns for sending.
+	// Recipients claimed before staleBefore and never completed are claimed again.
+	ClaimCampaignRecipients(ctx context.Context, limit int, staleBefore time.Time) ([]domain.CampaignRecipient, error)
+	// CompleteCampaignRecipient records the outcome of a recipient's send and the variant it was
+	// sent; an empty errMsg means sent
+	CompleteCampaignRecipient(ctx context.Context, campaignID int64, phoneNumber, variant string, messageID int64, errMsg string) error
+	// CampaignVariantStats counts a campaign's completed recipients by variant ("" for campaigns
+	// without variants) and their message's current status
+	CampaignVariantStats(ctx context.Context, campaignID int64) (map[string]*domain.StatsBucket, error)
+	// CompleteCampaigns marks running campaigns without recipients left to send completed
+	CompleteCampaigns(ctx context.Context, now time.Time) (int64, error)
+}
+
+// campaignModel represents a campaign in the database
+type campaignModel struct {
+	ID           int64          `db:"id"`
+	TenantID     string         `db:"tenant_id"`
+	Name         string         `db:"name"`
+	SegmentID    int64          `db:"segment_id"`
+	TemplateID   string         `db:"template_id"`
+	Parameters   sql.NullString `db:"parameters"`
+	Status       string         `db:"status"`
+	AudienceSize int64          `db:"audience_size"`
+	Sent         int64          `db:"sent"`
+	Failed       int64          `db:"failed"`
+	Actor        string         `db:"actor"`
+	CreatedAt    time.Time      `db:"created_at"`
+	StartedAt    sql.NullTime   `db:"started_at"`
+	CompletedAt  sql.NullTime   `db:"completed_at"`
+}
+
+// campaignRepository implements CampaignRepository
+type campaignRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewCampaignRepository creates a new campaign repository
+func NewCampaignRepository(db *sqlx.DB, logger utils.Logger) CampaignRepository {
+	return &campaignRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// encodeParameters stores template parameters as JSON, or NULL when there are none
+func encodeParameters(parameters map[string]string) (sql.NullString, error) {
+	if len(parameters) == 0 {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(parameters)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// CreateCampaign inserts a draft campaign and its variants in one statement
+func (r *campaignRepository) CreateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	query := `
+		WITH campaign AS (
+			INSERT INTO campaigns (tenant_id, name, segment_id, template_id, parameters, status, actor, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		), variants AS (
+			INSERT INTO campaign_variants (campaign_id, name, position, template_id, parameters, weight)
+			SELECT campaign.id, v.name, v.position, v.template_id, NULLIF(v.parameters, '{}'), v.weight
+			FROM campaign CROSS JOIN unnest($9::text[], $10::text[], $11::jsonb[], $12::int[])
+				WITH ORDINALITY AS v(name, template_id, parameters, weight, position)
+		)
+		SELECT id FROM campaign
+	`
+
+	parameters, err := encodeParameters(campaign.Parameters)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(campaign.Variants))
+	templateIDs := make([]string, 0, len(campaign.Variants))
+	variantParameters := make([]string, 0, len(campaign.Variants))
+	weights := make([]int64, 0, len(campaign.Variants))
+	for _, variant := range campaign.Variants {
+		encoded, err := encodeParameters(variant.Parameters)
+		if err != nil {
+			return err
+		}
+		if !encoded.Valid {
+			encoded.String = "{}"
+		}
+		names = append(names, variant.Name)
+		templateIDs = append(templateIDs, variant.TemplateID)
+		variantParameters = append(variantParameters, encoded.String)
+		weights = append(weights, int64(variant.Weight))
+	}
+
+	campaign.Status = domain.CampaignStatusDraft
+	campaign.CreatedAt = time.Now()
+	segmentID := sql.NullInt64{Int64: campaign.SegmentID, Valid: campaign.SegmentID != 0}
+	return r.db.GetContext(ctx, &campaign.ID, query, campaign.TenantID, campaign.Name, segmentID,
+		campaign.TemplateID, parameters, campaign.Status, campaign.Actor, campaign.CreatedAt,
+		pq.Array(names), pq.Array(templateIDs), pq.Array(variantParameters), pq.Array(weights))
+}
+
+// GetCampaign returns a campaign with its recipients counted by status
+func (r *campaignRepository) GetCampaign(ctx context.Context, tenantID string, id int64) (*domain.Campaign, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.name, COALESCE(c.segment_id, 0) AS segment_id, c.template_id, c.parameters, c.status, c.actor,
+			c.created_at, c.started_at, c.completed_at,
+			count(r.phone_number) AS audience_size,
+			count(*) FILTER (WHERE r.status = 'sent') AS sent,
+			count(*) FILTER (WHERE r.status = 'failed') AS failed
+		FROM campaigns c
+		LEFT JOIN campaign_recipients r ON r.campaign_id = c.id
+		WHERE c.id = $1 AND c.tenant_id = $2
+		GROUP BY c.id
+	`
+
+	var model campaignModel
+	if err := r.db.GetContext(ctx, &model, query, id, tenantID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "campaign not found")
+		}
+		return nil, err
+	}
+
+	campaign := &domain.Campaign{
+		ID:           model.ID,
+		TenantID:     model.TenantID,
+		Name:         model.Name,
+		SegmentID:    model.SegmentID,
+		TemplateID:   model.TemplateID,
+		Status:       model.Status,
+		AudienceSize: model.AudienceSize,
+		Sent:         model.Sent,
+		Failed:       model.Failed,
+		Actor:        model.Actor,
+		CreatedAt:    model.CreatedAt,
+		StartedAt:    model.StartedAt.Time,
+		CompletedAt:  model.CompletedAt.Time,
+	}
+	if model.Parameters.Valid {
+		if err := json.Unmarshal([]byte(model.Parameters.String), &campaign.Parameters); err != nil {
+			r.logger.Error("Failed to unmarshal campaign parameters", "error", err, "campaign_id", model.ID)
+		}
+	}
+
+	variants, err := r.ListCampaignVariants(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Variants = variants
+	return campaign, nil
+}
+
+// ListCampaignVariants returns the variants of a campaign by position
+func (r *campaignRepository) ListCampaignVariants(ctx context.Context, campaignID int64) ([]domain.CampaignVariant, error) {
+	query := `
+		SELECT name, template_id, parameters, weight
+		FROM campaign_variants
+		WHERE campaign_id = $1
+		ORDER BY position
+	`
+
+	var rows []struct {
+		Name       string         `db:"name"`
+		TemplateID string         `db:"template_id"`
+		Parameters sql.NullString `db:"parameters"`
+		Weight     int            `db:"weight"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, campaignID); err != nil {
+		return nil, err
+	}
+
+	variants := make([]domain.CampaignVariant, 0, len(rows))
+	for _, row := range rows {
+		variant := domain.CampaignVariant{Name: row.Name, TemplateID: row.TemplateID, Weight: row.Weight}
+		if row.Parameters.Valid {
+			if err := json.Unmarshal([]byte(row.Parameters.String), &variant.Parameters); err != nil {
+				r.logger.Error("Failed to unmarshal variant parameters", "error", err, "campaign_id", campaignID, "variant", row.Name)
+			}
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// StartCampaign starts the campaign and inserts its audience atomically. Imported recipients
+// keep their parameters; the audience size counts them along with those inserted.
+func (r *campaignRepository) StartCampaign(ctx context.Context, campaign *domain.Campaign, segment *domain.Segment, now time.Time) (bool, error) {
+	q := newQuery("")
+	campaignID := q.Arg(campaign.ID)
+	q.Append(`
+		WITH started AS (
+			UPDATE campaigns SET status = 'running', started_at = ` + q.Arg(now) + `
+			WHERE id = ` + campaignID + ` AND tenant_id = ` + q.Arg(campaign.TenantID) + ` AND status = 'draft'
+			RETURNING id
+		), audience AS (`)
+	if segment != nil {
+		q.Append(`
+			INSERT INTO campaign_recipients (campaign_id, phone_number)
+			SELECT started.id, audience.phone_number FROM started CROSS JOIN (`)
+		appendSegmentAudience(q, *segment, now)
+		q.Append(`) audience
+			ON CONFLICT DO NOTHING
+			RETURNING 1`)
+	} else {
+		q.Append(`SELECT 1 WHERE false`)
+	}
+	q.Append(`
+		)
+		SELECT (SELECT count(*) FROM started) AS started,
+			(SELECT count(*) FROM audience) + (SELECT count(*) FROM campaign_recipients WHERE campaign_id = ` + campaignID + `) AS audience_size
+	`)
+
+	var result struct {
+		Started      int64 `db:"started"`
+		AudienceSize int64 `db:"audience_size"`
+	}
+	if err := r.db.GetContext(ctx, &result, q.SQL(), q.Args()...); err != nil {
+		return false, err
+	}
+	if result.Started == 0 {
+		return false, nil
+	}
+	campaign.Status = domain.CampaignStatusRunning
+	campaign.StartedAt = now
+	campaign.AudienceSize = result.AudienceSize
+	return true, nil
+}
+
+// ImportCampaignRecipients upserts the recipients unless the campaign has left draft since,
+// filtering out and deleting suppressed contacts in the same statement
+func (r *campaignRepository) ImportCampaignRecipients(ctx context.Context, campaign *domain.Campaign, recipients []domain.CampaignRecipient) (int64, []string, error) {
+	query := `
+		WITH rows AS (
+			SELECT * FROM unnest($3::text[], $4::jsonb[]) AS t(phone_number, parameters)
+		), suppressed AS (
+			SELECT rows.phone_number FROM rows
+			WHERE (
+				SELECT o.action FROM contact_opt_ins o
+				WHERE o.tenant_id = $2 AND o.phone_number = rows.phone_number
+				ORDER BY o.occurred_at DESC, o.id DESC
+				LIMIT 1
+			) = 'opt_out'
+		), removed AS (
+			DELETE FROM campaign_recipients
+			WHERE campaign_id = $1 AND phone_number IN (SELECT phone_number FROM suppressed)
+				AND EXISTS (SELECT 1 FROM campaigns WHERE id = $1 AND tenant_id = $2 AND status = 'draft')
+		), imported AS (
+			INSERT INTO campaign_recipients (campaign_id, phone_number, parameters)
+			SELECT c.id, rows.phone_number, NULLIF(rows.parameters, '{}')
+			FROM rows JOIN campaigns c ON c.id = $1 AND c.tenant_id = $2 AND c.status = 'draft'
+			WHERE rows.phone_number NOT IN (SELECT phone_number FROM suppressed)
+			ON CONFLICT (campaign_id, phone_number) DO UPDATE SET parameters = EXCLUDED.parameters
+			RETURNING 1
+		)
+		SELECT (SELECT count(*) FROM imported) AS imported, ARRAY(SELECT phone_number FROM suppressed) AS suppressed
+	`
+
+	phoneNumbers := make([]string, 0, len(recipients))
+	parameters := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		encoded, err := json.Marshal(recipient.Parameters)
+		if err != nil {
+			return 0, nil, err
+		}
+		if recipient.Parameters == nil {
+			encoded = []byte("{}")
+		}
+		phoneNumbers = append(phoneNumbers, recipient.PhoneNumber)
+		parameters = append(parameters, string(encoded))
+	}
+
+	var result struct {
+		Imported   int64          `db:"imported"`
+		Suppressed pq.StringArray `db:"suppressed"`
+	}
+	if err := r.db.GetContext(ctx, &result, query, campaign.ID, campaign.TenantID, pq.Array(phoneNumbers), pq.Array(parameters)); err != nil {
+		return 0, nil, err
+	}
+	return result.Imported, result.Suppressed, nil
+}
+
+// ClaimCampaignRecipients marks recipients as sending, skipping rows other replicas are claiming
+func (r *campaignRepository) ClaimCampaignRecipients(ctx context.Context, limit int, staleBefore time.Time) ([]domain.CampaignRecipient, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE campaign_recipients SET status = 'sending', claimed_at = NOW()
+			WHERE (campaign_id, phone_number) IN (
+				SELECT r.campaign_id, r.phone_number
+				FROM campaign_recipients r
+				JOIN campaigns c ON c.id = r.campaign_id AND c.status = 'running'
+				WHERE r.status = 'pending' OR (r.status = 'sending' AND r.claimed_at < $2)
+				ORDER BY r.campaign_id
+				LIMIT $1
+				FOR UPDATE OF r SKIP LOCKED
+			)
+			RETURNING campaign_id, phone_number, parameters
+		)
+		SELECT claimed.campaign_id, claimed.phone_number, c.tenant_id, c.template_id,
+			c.parameters AS campaign_parameters, claimed.parameters
+		FROM claimed JOIN campaigns c ON c.id = claimed.campaign_id
+	`
+
+	var rows []struct {
+		CampaignID         int64          `db:"campaign_id"`
+		PhoneNumber        string         `db:"phone_number"`
+		TenantID           string         `db:"tenant_id"`
+		TemplateID         string         `db:"template_id"`
+		CampaignParameters sql.NullString `db:"campaign_parameters"`
+		Parameters         sql.NullString `db:"parameters"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, limit, staleBefore); err != nil {
+		return nil, err
+	}
+
+	recipients := make([]domain.CampaignRecipient, 0, len(rows))
+	for _, row := range rows {
+		recipient := domain.CampaignRecipient{
+			CampaignID:  row.CampaignID,
+			TenantID:    row.TenantID,
+			PhoneNumber: row.PhoneNumber,
+			TemplateID:  row.TemplateID,
+		}
+		if row.CampaignParameters.Valid {
+			if err := json.Unmarshal([]byte(row.CampaignParameters.String), &recipient.CampaignParameters); err != nil {
+				r.logger.Error("Failed to unmarshal campaign parameters", "error", err, "campaign_id", row.CampaignID)
+			}
+		}
+		if row.Parameters.Valid {
+			if err := json.Unmarshal([]byte(row.Parameters.String), &recipient.Parameters); err != nil {
+				r.logger.Error("Failed to unmarshal recipient parameters", "error", err, "campaign_id", row.CampaignID)
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// CompleteCampaignRecipient stores the message sent to a recipient, or why it failed
+func (r *campaignRepository) CompleteCampaignRecipient(ctx context.Context, campaignID int64, phoneNumber, variant string, messageID int64, errMsg string) error {
+	query := `
+		UPDATE campaign_recipients SET status = $3, variant = $4, message_id = $5, error = $6
+		WHERE campaign_id = $1 AND phone_number = $2
+	`
+
+	status := recipientSent
+	if errMsg != "" {
+		status = recipientFailed
+	}
+	_, err := r.db.ExecContext(ctx, query, campaignID, phoneNumber, status, sql.NullString{String: variant, Valid: variant != ""},
+		sql.NullInt64{Int64: messageID, Valid: messageID != 0}, sql.NullString{String: errMsg, Valid: errMsg != ""})
+	return err
+}
+
+// CampaignVariantStats counts completed recipients by variant and the current status of their
+// message; refused recipients, and those whose message is gone, count by their own status
+func (r *campaignRepository) CampaignVariantStats(ctx context.Context, campaignID int64) (map[string]*domain.StatsBucket, error) {
+	query := `
+		SELECT COALESCE(r.variant, '') AS variant, COALESCE(m.status, r.status) AS status, count(*) AS count
+		FROM campaign_recipients r
+		LEFT JOIN messages m ON m.id = r.message_id
+		WHERE r.campaign_id = $1 AND r.status IN ('sent', 'failed')
+		GROUP BY 1, 2
+	`
+
+	var rows []struct {
+		Variant string `db:"variant"`
+		Status  string `db:"status"`
+		Count   int64  `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, campaignID); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*domain.StatsBucket)
+	for _, row := range rows {
+		if stats[row.Variant] == nil {
+			stats[row.Variant] = &domain.StatsBucket{}
+		}
+		stats[row.Variant].Add(row.Status, row.Count)
+	}
+	return stats, nil
+}
+
+// CompleteCampaigns stamps running campaigns whose recipients are all sent or failed
+func (r *campaignRepository) CompleteCampaigns(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE campaigns c SET status = 'completed', completed_at = $1
+		WHERE c.status = 'running' AND NOT EXISTS (
+			SELECT 1 FROM campaign_recipients r
+			WHERE r.campaign_id = c.id AND r.status IN ('pending', 'sending')
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
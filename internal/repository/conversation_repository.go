@@ -0,0 +1,307 @@
+// internal/repository/conversation_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// ConversationRepository stores inbound messages and the conversations they belong to
+type ConversationRepository interface {
+	// RecordInbound stores an inbound message, starting its conversation with the first one. A
+	// redelivered message is not stored again; its ID and HandledAt are filled in from the stored copy.
+	RecordInbound(ctx context.Context, msg *domain.InboundMessage) (*domain.Conversation, error)
+	// MarkInboundHandled records that an inbound message was dealt with
+	MarkInboundHandled(ctx context.Context, id int64, at time.Time) error
+	// RecentInbound returns a conversation's latest inbound messages, oldest first
+	RecentInbound(ctx context.Context, conversationID int64, limit int) ([]domain.InboundMessage, error)
+	// GetInbound returns a tenant's inbound message by provider message ID
+	GetInbound(ctx context.Context, tenantID, externalID string) (*domain.InboundMessage, error)
+	GetConversation(ctx context.Context, tenantID, phoneNumber string) (*domain.Conversation, error)
+	GetConversationByID(ctx context.Context, tenantID string, id int64) (*domain.Conversation, error)
+	// UpdateHandoff sets a conversation's handoff state; empty reason or agent keep the current ones
+	UpdateHandoff(ctx context.Context, tenantID string, id int64, status, reason, agent string) (*domain.Conversation, error)
+	// EraseConversations deletes the conversations of the customer or phone number the filter
+	// names, with their inbound messages
+	EraseConversations(ctx context.Context, filter domain.MessageFilter) (int64, error)
+	// PurgeInboundBefore deletes up to limit inbound messages received before cutoff
+	PurgeInboundBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// conversationModel represents a conversation in the database
+type conversationModel struct {
+	ID            int64          `db:"id"`
+	TenantID      string         `db:"tenant_id"`
+	PhoneNumber   string         `db:"phone_number"`
+	ProfileName   sql.NullString `db:"profile_name"`
+	HandoffStatus string         `db:"handoff_status"`
+	HandoffReason sql.NullString `db:"handoff_reason"`
+	HandoffAgent  sql.NullString `db:"handoff_agent"`
+	HandoffAt     sql.NullTime   `db:"handoff_at"`
+	LastInboundAt time.Time      `db:"last_inbound_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+// inboundMessageModel represents an inbound message in the database
+type inboundMessageModel struct {
+	ID             int64          `db:"id"`
+	ConversationID int64          `db:"conversation_id"`
+	TenantID       string         `db:"tenant_id"`
+	ExternalID     string         `db:"external_id"`
+	MessageType    string         `db:"message_type"`
+	Text           sql.NullString `db:"text"`
+	OrderPayload   []byte         `db:"order_payload"`
+	ReceivedAt     time.Time      `db:"received_at"`
+	HandledAt      sql.NullTime   `db:"handled_at"`
+}
+
+// conversationColumns lists the columns conversationModel is scanned from
+const conversationColumns = `id, tenant_id, phone_number, profile_name, handoff_status, handoff_reason,
+	handoff_agent, handoff_at, last_inbound_at, created_at, updated_at`
+
+// inboundMessageColumns lists the columns inboundMessageModel is scanned from
+const inboundMessageColumns = `id, conversation_id, tenant_id, external_id, message_type, text, order_payload, received_at, handled_at`
+
+// conversationRepository implements ConversationRepository
+type conversationRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *sqlx.DB, logger utils.Logger) ConversationRepository {
+	return &conversationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordInbound upserts the conversation, then the message
+func (r *conversationRepository) RecordInbound(ctx context.Context, msg *domain.InboundMessage) (*domain.Conversation, error) {
+	query := `
+		INSERT INTO conversations (tenant_id, phone_number, profile_name, last_inbound_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (tenant_id, phone_number)
+		DO UPDATE SET
+			profile_name = COALESCE(EXCLUDED.profile_name, conversations.profile_name),
+			last_inbound_at = GREATEST(conversations.last_inbound_at, EXCLUDED.last_inbound_at),
+			updated_at = NOW()
+		RETURNING ` + conversationColumns
+
+	profileName := sql.NullString{String: msg.ProfileName, Valid: msg.ProfileName != ""}
+	var conversation conversationModel
+	if err := r.db.GetContext(ctx, &conversation, query, msg.TenantID, msg.PhoneNumber, profileName, msg.ReceivedAt); err != nil {
+		return nil, err
+	}
+
+	// The no-op update on conflict makes a redelivered message return the stored row
+	query = `
+		INSERT INTO inbound_messages (conversation_id, tenant_id, external_id, message_type, text, order_payload, received_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (tenant_id, external_id)
+		DO UPDATE SET external_id = EXCLUDED.external_id
+		RETURNING id, handled_at
+	`
+
+	text := sql.NullString{String: msg.Text, Valid: msg.Text != ""}
+	var order interface{} // NULL unless it is an order message
+	if msg.Order != nil {
+		payload, err := json.Marshal(msg.Order)
+		if err != nil {
+			return nil, err
+		}
+		order = string(payload)
+	}
+	var stored struct {
+		ID        int64        `db:"id"`
+		HandledAt sql.NullTime `db:"handled_at"`
+	}
+	if err := r.db.GetContext(ctx, &stored, query, conversation.ID, msg.TenantID, msg.ExternalID, msg.Type, text, order, msg.ReceivedAt); err != nil {
+		return nil, err
+	}
+
+	msg.ID = stored.ID
+	msg.ConversationID = conversation.ID
+	msg.HandledAt = stored.HandledAt.Time
+	return conversation.toDomain(), nil
+}
+
+// MarkInboundHandled stamps an inbound message as handled
+func (r *conversationRepository) MarkInboundHandled(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE inbound_messages SET handled_at = $1 WHERE id = $2 AND handled_at IS NULL`, at, id)
+	return err
+}
+
+// RecentInbound returns up to limit of a conversation's latest inbound messages
+func (r *conversationRepository) RecentInbound(ctx context.Context, conversationID int64, limit int) ([]domain.InboundMessage, error) {
+	query := `
+		SELECT ` + inboundMessageColumns + `
+		FROM inbound_messages
+		WHERE conversation_id = $1
+		ORDER BY received_at DESC, id DESC
+		LIMIT $2
+	`
+
+	var models []inboundMessageModel
+	if err := r.db.SelectContext(ctx, &models, query, conversationID, limit); err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.InboundMessage, len(models))
+	for i, model := range models {
+		messages[len(models)-1-i] = model.toDomain()
+	}
+	return messages, nil
+}
+
+// GetInbound looks an inbound message up by its provider ID
+func (r *conversationRepository) GetInbound(ctx context.Context, tenantID, externalID string) (*domain.InboundMessage, error) {
+	query := `
+		SELECT ` + inboundMessageColumns + `
+		FROM inbound_messages
+		WHERE tenant_id = $1 AND external_id = $2
+	`
+
+	var model inboundMessageModel
+	if err := r.db.GetContext(ctx, &model, query, tenantID, externalID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "inbound message not found")
+		}
+		return nil, err
+	}
+	msg := model.toDomain()
+	return &msg, nil
+}
+
+// GetConversation returns the conversation with a customer
+func (r *conversationRepository) GetConversation(ctx context.Context, tenantID, phoneNumber string) (*domain.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations WHERE tenant_id = $1 AND phone_number = $2`
+	return r.getConversation(ctx, query, tenantID, phoneNumber)
+}
+
+// GetConversationByID returns a conversation of the tenant by ID
+func (r *conversationRepository) GetConversationByID(ctx context.Context, tenantID string, id int64) (*domain.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations WHERE tenant_id = $1 AND id = $2`
+	return r.getConversation(ctx, query, tenantID, id)
+}
+
+// UpdateHandoff moves a conversation to a handoff state
+func (r *conversationRepository) UpdateHandoff(ctx context.Context, tenantID string, id int64, status, reason, agent string) (*domain.Conversation, error) {
+	query := `
+		UPDATE conversations
+		SET handoff_status = $3,
+			handoff_reason = COALESCE($4, handoff_reason),
+			handoff_agent = COALESCE($5, handoff_agent),
+			handoff_at = NOW(),
+			updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING ` + conversationColumns
+
+	reasonArg := sql.NullString{String: reason, Valid: reason != ""}
+	agentArg := sql.NullString{String: agent, Valid: agent != ""}
+	return r.getConversation(ctx, query, tenantID, id, status, reasonArg, agentArg)
+}
+
+// customerPhoneSQL is domain.NormalizeCustomerPhone of the phone_number column of messages
+const customerPhoneSQL = `regexp_replace(trim(phone_number), '^(whatsapp:)?\+?', '')`
+
+// EraseConversations deletes the conversations of the subject the filter names; their inbound
+// messages go with them. A customer ID names the conversations with the phone numbers its
+// messages were sent to, so run it before those messages are anonymized.
+func (r *conversationRepository) EraseConversations(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	filter = scopeFilter(ctx, filter)
+	q := newQuery("DELETE FROM conversations")
+	q.WhereEq("tenant_id", filter.TenantID)
+	switch {
+	case filter.PhoneNumber != "":
+		q.Where("phone_number = " + q.Arg(domain.NormalizeCustomerPhone(filter.PhoneNumber)))
+	case filter.CustomerID != "" && filter.TenantID != "":
+		q.Where("phone_number IN (SELECT " + customerPhoneSQL + " FROM messages WHERE tenant_id = " +
+			q.Arg(filter.TenantID) + " AND customer_id = " + q.Arg(filter.CustomerID) + ")")
+	default:
+		return 0, errors.New("refusing to erase conversations without a customer")
+	}
+
+	result, err := r.db.ExecContext(ctx, q.SQL(), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// getConversation runs a query returning one conversation row
+func (r *conversationRepository) getConversation(ctx context.Context, query string, args ...interface{}) (*domain.Conversation, error) {
+	var model conversationModel
+	if err := r.db.GetContext(ctx, &model, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "conversation not found")
+		}
+		return nil, err
+	}
+	return model.toDomain(), nil
+}
+
+// toDomain converts a conversationModel
+func (m conversationModel) toDomain() *domain.Conversation {
+	return &domain.Conversation{
+		ID:            m.ID,
+		TenantID:      m.TenantID,
+		PhoneNumber:   m.PhoneNumber,
+		ProfileName:   m.ProfileName.String,
+		HandoffStatus: m.HandoffStatus,
+		HandoffReason: m.HandoffReason.String,
+		HandoffAgent:  m.HandoffAgent.String,
+		HandoffAt:     m.HandoffAt.Time,
+		LastInboundAt: m.LastInboundAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// toDomain converts an inboundMessageModel
+func (m inboundMessageModel) toDomain() domain.InboundMessage {
+	msg := domain.InboundMessage{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		TenantID:       m.TenantID,
+		ExternalID:     m.ExternalID,
+		Type:           m.MessageType,
+		Text:           m.Text.String,
+		ReceivedAt:     m.ReceivedAt,
+		HandledAt:      m.HandledAt.Time,
+	}
+	if len(m.OrderPayload) > 0 {
+		var order domain.InboundOrder
+		if err := json.Unmarshal(m.OrderPayload, &order); err == nil {
+			msg.Order = &order
+		}
+	}
+	return msg
+}
+
+// PurgeInboundBefore deletes up to limit inbound messages stored before cutoff, oldest first
+func (r *conversationRepository) PurgeInboundBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM inbound_messages
+		WHERE id IN (
+			SELECT id FROM inbound_messages
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,94 @@
+// internal/repository/country_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// CountryRuleRepository stores the destination country rules set at runtime
+type CountryRuleRepository interface {
+	// SetCountryRule stores a rule, replacing any rule for the same prefix
+	SetCountryRule(ctx context.Context, rule *domain.CountryRule) error
+	// DeleteCountryRule removes the rule for a prefix and reports whether there was one
+	DeleteCountryRule(ctx context.Context, prefix string) (bool, error)
+	ListCountryRules(ctx context.Context) ([]domain.CountryRule, error)
+}
+
+// countryRuleModel represents a country rule in the database
+type countryRuleModel struct {
+	Prefix    string         `db:"prefix"`
+	Action    string         `db:"action"`
+	Reason    sql.NullString `db:"reason"`
+	Actor     string         `db:"actor"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// countryRuleRepository implements CountryRuleRepository
+type countryRuleRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewCountryRuleRepository creates a new country rule repository
+func NewCountryRuleRepository(db *sqlx.DB, logger utils.Logger) CountryRuleRepository {
+	return &countryRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetCountryRule inserts or replaces the rule of a prefix
+func (r *countryRuleRepository) SetCountryRule(ctx context.Context, rule *domain.CountryRule) error {
+	query := `
+		INSERT INTO country_rules (prefix, action, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (prefix)
+		DO UPDATE SET action = EXCLUDED.action, reason = EXCLUDED.reason, actor = EXCLUDED.actor, created_at = EXCLUDED.created_at
+		RETURNING created_at
+	`
+
+	reason := sql.NullString{String: rule.Reason, Valid: rule.Reason != ""}
+	return r.db.GetContext(ctx, &rule.CreatedAt, query, rule.Prefix, rule.Action, reason, rule.Actor, time.Now())
+}
+
+// DeleteCountryRule removes the rule of a prefix
+func (r *countryRuleRepository) DeleteCountryRule(ctx context.Context, prefix string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM country_rules WHERE prefix = $1`, prefix)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ListCountryRules returns every runtime rule ordered by prefix
+func (r *countryRuleRepository) ListCountryRules(ctx context.Context) ([]domain.CountryRule, error) {
+	query := `SELECT prefix, action, reason, actor, created_at FROM country_rules ORDER BY prefix`
+
+	var models []countryRuleModel
+	if err := r.db.SelectContext(ctx, &models, query); err != nil {
+		return nil, err
+	}
+
+	rules := make([]domain.CountryRule, 0, len(models))
+	for _, model := range models {
+		rules = append(rules, domain.CountryRule{
+			Prefix:    model.Prefix,
+			Action:    model.Action,
+			Reason:    model.Reason.String,
+			Actor:     model.Actor,
+			CreatedAt: model.CreatedAt,
+		})
+	}
+	return rules, nil
+}
@@ -0,0 +1,39 @@
+// internal/repository/db.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// dsnConnector opens each connection with the DSN current at dial time, so credentials
+// rotated in a secret store are used by new connections without a restart
+type dsnConnector struct {
+	dsn func() string
+}
+
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	connector, err := pq.NewConnector(c.dsn())
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// Connect opens a Postgres pool whose connections use the DSN returned by dsn and verifies it with a ping
+func Connect(ctx context.Context, dsn func() string) (*sqlx.DB, error) {
+	db := sqlx.NewDb(sql.OpenDB(&dsnConnector{dsn: dsn}), "postgres")
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,170 @@
+// internal/repository/external_id_cache.go
+package repository
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// ExternalIDCache maps a tenant's provider external IDs to message IDs
+type ExternalIDCache interface {
+	Get(ctx context.Context, tenantID, externalID string) (int64, bool)
+	Set(ctx context.Context, tenantID, externalID string, id int64)
+}
+
+// lruEntry is one cached mapping
+type lruEntry struct {
+	key       string
+	id        int64
+	expiresAt time.Time
+}
+
+// lruExternalIDCache is an in-process LRU cache with a TTL per entry
+type lruExternalIDCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewLRUExternalIDCache creates an in-process cache holding at most size entries for ttl each
+func NewLRUExternalIDCache(size int, ttl time.Duration) ExternalIDCache {
+	return &lruExternalIDCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruExternalIDCache) Get(_ context.Context, tenantID, externalID string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[externalIDKey(tenantID, externalID)]
+	if !ok {
+		return 0, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, entry.key)
+		return 0, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.id, true
+}
+
+func (c *lruExternalIDCache) Set(_ context.Context, tenantID, externalID string, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := externalIDKey(tenantID, externalID)
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.id, entry.expiresAt = id, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, id: id, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// redisExternalIDCache shares the mapping between replicas through Redis
+type redisExternalIDCache struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+	logger utils.Logger
+}
+
+// NewRedisExternalIDCache creates a cache stored in Redis with the given TTL per entry
+func NewRedisExternalIDCache(client redis.UniversalClient, ttl time.Duration, logger utils.Logger) ExternalIDCache {
+	return &redisExternalIDCache{
+		client: client,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Get treats Redis errors as misses so webhooks fall through to the database
+func (c *redisExternalIDCache) Get(ctx context.Context, tenantID, externalID string) (int64, bool) {
+	value, err := c.client.Get(ctx, "extid:"+externalIDKey(tenantID, externalID)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			c.logger.Warn("External ID cache read failed", "error", err)
+		}
+		return 0, false
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func (c *redisExternalIDCache) Set(ctx context.Context, tenantID, externalID string, id int64) {
+	if err := c.client.Set(ctx, "extid:"+externalIDKey(tenantID, externalID), id, c.ttl).Err(); err != nil {
+		c.logger.Warn("External ID cache write failed", "error", err)
+	}
+}
+
+// externalIDKey scopes an external ID to its tenant
+func externalIDKey(tenantID, externalID string) string {
+	return tenantID + ":" + externalID
+}
+
+// cachedMessageRepository serves external ID lookups from an ExternalIDCache, filled when
+// a send records the provider's external ID
+type cachedMessageRepository struct {
+	MessageRepository
+	cache ExternalIDCache
+}
+
+// NewCachedMessageRepository wraps repo so webhook lookups by external ID skip the database
+// for messages this service sent. The tenant is taken from the context of the status update.
+func NewCachedMessageRepository(repo MessageRepository, cache ExternalIDCache) MessageRepository {
+	return &cachedMessageRepository{
+		MessageRepository: repo,
+		cache:             cache,
+	}
+}
+
+// GetMessageIDByExternalID returns the cached message ID, loading and caching it on a miss
+func (r *cachedMessageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error) {
+	if id, ok := r.cache.Get(ctx, tenantID, externalID); ok {
+		return id, nil
+	}
+
+	id, err := r.MessageRepository.GetMessageIDByExternalID(ctx, tenantID, externalID)
+	if err != nil {
+		return 0, err
+	}
+	r.cache.Set(ctx, tenantID, externalID, id)
+	return id, nil
+}
+
+// UpdateMessageStatus caches the external ID assigned by the provider
+func (r *cachedMessageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error {
+	if err := r.MessageRepository.UpdateMessageStatus(ctx, id, status, errorCode, errorMessage, externalID); err != nil {
+		return err
+	}
+	if externalID != "" {
+		AfterCommit(ctx, func(ctx context.Context) { r.cache.Set(ctx, domain.TenantFromContext(ctx), externalID, id) })
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// internal/repository/leader_lock.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// LeaderLocker hands out named locks that at most one instance holds at a time
+type LeaderLocker interface {
+	// TryLock takes the lock named name if no instance holds it; ok is false when one does
+	TryLock(ctx context.Context, name string) (lock HeldLock, ok bool, err error)
+}
+
+// HeldLock is a lock taken with TryLock
+type HeldLock interface {
+	// Check returns an error once the lock may have been lost
+	Check(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+// advisoryLocker implements LeaderLocker with Postgres session advisory locks
+type advisoryLocker struct {
+	db *sqlx.DB
+}
+
+// NewAdvisoryLocker creates a locker on db's primary. Each held lock pins a connection of the
+// pool, and is released by Postgres when that connection drops.
+func NewAdvisoryLocker(db *sqlx.DB) LeaderLocker {
+	return &advisoryLocker{db: db}
+}
+
+// advisoryLockKey maps a lock name to the key of its advisory lock
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("whatsapp:" + name))
+	return int64(h.Sum64())
+}
+
+// TryLock takes the advisory lock of name on a connection kept for as long as it is held
+func (l *advisoryLocker) TryLock(ctx context.Context, name string) (HeldLock, bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := advisoryLockKey(name)
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	return &advisoryLock{conn: conn, key: key}, true, nil
+}
+
+// advisoryLock is an advisory lock held on conn
+type advisoryLock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// Check fails once the connection holding the lock is gone
+func (l *advisoryLock) Check(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, `SELECT 1`)
+	return err
+}
+
+// Release unlocks and returns the connection to the pool. A connection that could not unlock
+// is closed instead, which releases the lock all the same.
+func (l *advisoryLock) Release(ctx context.Context) error {
+	defer l.conn.Close()
+	if _, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		l.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		return err
+	}
+	return nil
+}
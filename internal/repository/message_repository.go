@@ -6,9 +6,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"messaging-microservice/internal/domain"
 	"messaging-microservice/pkg/utils"
 )
@@ -22,24 +24,70 @@ type MessageModel struct {
 	OrderID      sql.NullString `db:"order_id"`
 	CustomerID   sql.NullString `db:"customer_id"`
 	Status       string         `db:"status"`
+	ErrorCode    sql.NullString `db:"error_code"`
 	ErrorMessage sql.NullString `db:"error_message"`
 	ExternalID   sql.NullString `db:"external_id"`
-	CreatedAt    time.Time      `db:"created_at"`
-	UpdatedAt    time.Time      `db:"updated_at"`
+	TenantID     string         `db:"tenant_id"`
+	// RecipientTimezone is only stored when given explicitly; quiet hours otherwise infer it
+	RecipientTimezone sql.NullString `db:"recipient_timezone"`
+	ExpiresAt         sql.NullTime   `db:"expires_at"`
+	Attempt           int            `db:"attempt"`
+	RetryOf           sql.NullInt64  `db:"retry_of"`
+	ContentSnapshot   sql.NullString `db:"content_snapshot"`
+	ArchiveKey        sql.NullString `db:"archive_key"`
+	DeletedAt         sql.NullTime   `db:"deleted_at"`
+	ErasedAt          sql.NullTime   `db:"erased_at"`
+	CreatedAt         time.Time      `db:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at"`
 }
 
-// MessageRepository defines the interface for database operations
+// MessageRepository defines the interface for database operations. With a context scoped by
+// domain.WithTenantScope, lookups, listings and changes of particular messages only reach
+// the scoped tenant's messages.
 type MessageRepository interface {
 	CreateMessage(ctx context.Context, message *domain.Message) (int64, error)
+	CreateMessages(ctx context.Context, messages []*domain.Message) ([]int64, error)
 	GetMessageByID(ctx context.Context, id int64) (*domain.Message, error)
 	GetMessageByExternalID(ctx context.Context, externalID string) (*domain.Message, error)
-	ListMessages(ctx context.Context, orderID, customerID, phoneNumber string, limit, offset int) ([]*domain.Message, error)
-	UpdateMessageStatus(ctx context.Context, id int64, status, errorMessage, externalID string) error
+	GetTenantMessageByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Message, error)
+	GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error)
+	// GetMessagesByIDs and GetMessagesByExternalIDs return the messages found among many, in
+	// no particular order; archived messages come back as their stubs
+	GetMessagesByIDs(ctx context.Context, ids []int64) ([]*domain.Message, error)
+	GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) ([]*domain.Message, error)
+	// GetMessagesByOrderID returns the order's messages that are not soft deleted
+	GetMessagesByOrderID(ctx context.Context, orderID string) ([]*domain.Message, error)
+	// FindRecentDuplicate returns the newest message created since since to the same recipient
+	// with the same template and parameters, unless it failed to go out
+	FindRecentDuplicate(ctx context.Context, message *domain.Message, since time.Time) (*domain.Message, error)
+	ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) ([]*domain.Message, error)
+	ListMessagesAfterID(ctx context.Context, filter domain.MessageFilter, afterID int64, limit int) ([]*domain.Message, error)
+	// SearchMessages returns a page of the messages matching search, newest first
+	SearchMessages(ctx context.Context, search domain.MessageSearch, limit, offset int) ([]*domain.Message, error)
+	CountMessages(ctx context.Context, filter domain.MessageFilter) (int, error)
+	CountMessagesByDay(ctx context.Context, filter domain.MessageFilter) ([]domain.StatusCount, error)
+	GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) ([]domain.StageLatency, error)
+	UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error
+	SaveContentSnapshot(ctx context.Context, id int64, snapshot string) error
+	NextStatusSequence(ctx context.Context, id int64) (int64, error)
+	UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (map[int64]domain.StatusUpdateResult, error)
+	EraseMessages(ctx context.Context, filter domain.MessageFilter, hardDelete bool) (int64, error)
+	PurgeMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	ListMessagesToArchive(ctx context.Context, before time.Time, limit int) ([]*domain.Message, error)
+	MarkMessagesArchived(ctx context.Context, ids []int64, archiveKey string) error
+	HoldMessage(ctx context.Context, id int64) error
+	ReleaseHeldMessages(ctx context.Context, filter domain.MessageFilter, limit int) ([]*domain.Message, error)
+	DeferMessage(ctx context.Context, id int64, until time.Time) error
+	ReleaseDueMessages(ctx context.Context, now time.Time, limit int) ([]*domain.Message, error)
+	MarkMessageEnqueued(ctx context.Context, id int64, at time.Time) error
+	// DeleteMessage marks a message deleted, or removes its row when hardDelete is set
+	DeleteMessage(ctx context.Context, id int64, hardDelete bool) error
 }
 
 // messageRepository implements MessageRepository
 type messageRepository struct {
 	db     *sqlx.DB
+	reads  *ReadRouter
 	logger utils.Logger
 }
 
@@ -51,36 +99,37 @@ func NewMessageRepository(db *sqlx.DB, logger utils.Logger) MessageRepository {
 	}
 }
 
-// CreateMessage creates a new message
-func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.Message) (int64, error) {
-	// Convert parameters to JSON
-	paramsJSON, err := json.Marshal(message.Parameters)
-	if err != nil {
-		return 0, err
+// NewReplicatedMessageRepository creates a message repository that writes to db and sends
+// lookups, listings and counts to the pool chosen by reads
+func NewReplicatedMessageRepository(db *sqlx.DB, reads *ReadRouter, logger utils.Logger) MessageRepository {
+	return &messageRepository{
+		db:     db,
+		reads:  reads,
+		logger: logger,
 	}
+}
 
-	// Create model
-	model := MessageModel{
-		PhoneNumber:  message.PhoneNumber,
-		TemplateID:   message.TemplateID,
-		Parameters:   string(paramsJSON),
-		Status:       message.Status,
-		CreatedAt:    message.CreatedAt,
-		UpdatedAt:    message.UpdatedAt,
-	}
+// conn returns the unit of work's transaction, or the primary pool
+func (r *messageRepository) conn(ctx context.Context) dbConn {
+	return conn(ctx, r.db)
+}
 
-	// Set nullable fields
-	if message.OrderID != "" {
-		model.OrderID = sql.NullString{String: message.OrderID, Valid: true}
+// reader returns the pool for a read-only query; reads made in a unit of work see its writes
+func (r *messageRepository) reader(ctx context.Context) dbConn {
+	if InTx(ctx) {
+		return r.conn(ctx)
 	}
-	if message.CustomerID != "" {
-		model.CustomerID = sql.NullString{String: message.CustomerID, Valid: true}
+	if db := r.reads.Reader(ctx); db != nil {
+		return db
 	}
-	if message.ErrorMessage != "" {
-		model.ErrorMessage = sql.NullString{String: message.ErrorMessage, Valid: true}
-	}
-	if message.ExternalID != "" {
-		model.ExternalID = sql.NullString{String: message.ExternalID, Valid: true}
+	return r.db
+}
+
+// CreateMessage creates a new message
+func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.Message) (int64, error) {
+	model, err := domainToModel(message)
+	if err != nil {
+		return 0, err
 	}
 
 	// Insert into database
@@ -88,15 +137,15 @@ func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.M
 		INSERT INTO messages (
 			phone_number, template_id, parameters, 
 			order_id, customer_id, status, 
-			error_message, external_id, created_at, updated_at
+			error_code, error_message, external_id, tenant_id, recipient_timezone, expires_at, attempt, retry_of, created_at, updated_at
 		) VALUES (
 			:phone_number, :template_id, :parameters, 
 			:order_id, :customer_id, :status, 
-			:error_message, :external_id, :created_at, :updated_at
+			:error_code, :error_message, :external_id, :tenant_id, :recipient_timezone, :expires_at, :attempt, :retry_of, :created_at, :updated_at
 		) RETURNING id
 	`
 
-	rows, err := r.db.NamedQueryContext(ctx, query, model)
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), query, model)
 	if err != nil {
 		return 0, err
 	}
@@ -114,20 +163,98 @@ func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.M
 	return id, nil
 }
 
-// GetMessageByID retrieves a message by ID
-func (r *messageRepository) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
+// timestampLayout formats times for TIMESTAMP array parameters
+const timestampLayout = "2006-01-02 15:04:05.999999"
+
+// CreateMessages inserts messages in one statement, whatever their number, and returns their
+// IDs in input order. IDs are reserved from the sequence first so each maps to its message
+// without relying on the order of RETURNING rows.
+func (r *messageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) ([]int64, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	var ids []int64
+	if err := r.conn(ctx).SelectContext(ctx, &ids, `SELECT nextval('messages_id_seq') FROM generate_series(1, $1)`, len(messages)); err != nil {
+		return nil, err
+	}
+
+	n := len(messages)
+	phoneNumbers, templateIDs, parameters := make([]string, n), make([]string, n), make([]string, n)
+	orderIDs, customerIDs, statuses := make([]string, n), make([]string, n), make([]string, n)
+	errorCodes, errorMessages, externalIDs := make([]string, n), make([]string, n), make([]string, n)
+	tenantIDs, timezones, expiresAt := make([]string, n), make([]string, n), make([]string, n)
+	attempts, retryOf := make([]int64, n), make([]int64, n)
+	// Timestamps go as wall-clock text, which is what TIMESTAMP keeps of a single-row insert
+	createdAt, updatedAt := make([]string, n), make([]string, n)
+	for i, message := range messages {
+		model, err := domainToModel(message)
+		if err != nil {
+			return nil, err
+		}
+		phoneNumbers[i], templateIDs[i], parameters[i] = model.PhoneNumber, model.TemplateID, model.Parameters
+		orderIDs[i], customerIDs[i], statuses[i] = model.OrderID.String, model.CustomerID.String, model.Status
+		errorCodes[i], errorMessages[i], externalIDs[i] = model.ErrorCode.String, model.ErrorMessage.String, model.ExternalID.String
+		tenantIDs[i], timezones[i] = model.TenantID, model.RecipientTimezone.String
+		if model.ExpiresAt.Valid {
+			expiresAt[i] = model.ExpiresAt.Time.Format(timestampLayout)
+		}
+		attempts[i], retryOf[i] = int64(model.Attempt), model.RetryOf.Int64
+		createdAt[i], updatedAt[i] = model.CreatedAt.Format(timestampLayout), model.UpdatedAt.Format(timestampLayout)
+	}
+
 	query := `
-		SELECT id, phone_number, template_id, parameters, 
+		INSERT INTO messages (
+			id, phone_number, template_id, parameters, 
 			order_id, customer_id, status, 
-			error_message, external_id, created_at, updated_at
-		FROM messages
-		WHERE id = $1
+			error_code, error_message, external_id, tenant_id, recipient_timezone, expires_at,
+			attempt, retry_of, created_at, updated_at
+		)
+		SELECT id, phone_number, template_id, parameters,
+			NULLIF(order_id, ''), NULLIF(customer_id, ''), status,
+			NULLIF(error_code, ''), NULLIF(error_message, ''), NULLIF(external_id, ''), tenant_id,
+			NULLIF(recipient_timezone, ''), NULLIF(expires_at, '')::timestamp,
+			attempt, NULLIF(retry_of, 0), created_at, updated_at
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::jsonb[], $5::text[], $6::text[], $7::text[],
+			$8::text[], $9::text[], $10::text[], $11::text[], $12::text[], $13::text[], $14::bigint[], $15::bigint[],
+			$16::timestamp[], $17::timestamp[])
+			AS m(id, phone_number, template_id, parameters, order_id, customer_id, status,
+				error_code, error_message, external_id, tenant_id, recipient_timezone, expires_at,
+				attempt, retry_of, created_at, updated_at)
 	`
 
+	_, err := r.conn(ctx).ExecContext(ctx, query, pq.Array(ids), pq.Array(phoneNumbers), pq.Array(templateIDs),
+		pq.Array(parameters), pq.Array(orderIDs), pq.Array(customerIDs), pq.Array(statuses),
+		pq.Array(errorCodes), pq.Array(errorMessages), pq.Array(externalIDs), pq.Array(tenantIDs),
+		pq.Array(timezones), pq.Array(expiresAt), pq.Array(attempts), pq.Array(retryOf), pq.Array(createdAt), pq.Array(updatedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// messageColumns are the columns of messages read into MessageModel, in its field order
+const messageColumns = "id, phone_number, template_id, parameters, order_id, customer_id, status, error_code, error_message, " +
+	"external_id, tenant_id, recipient_timezone, expires_at, attempt, retry_of, content_snapshot, archive_key, deleted_at, " +
+	"erased_at, created_at, updated_at"
+
+// GetMessageByID retrieves a message by ID
+func (r *messageRepository) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
+
+	// A message missing on the replica may just not have replicated yet
 	var model MessageModel
-	if err := r.db.GetContext(ctx, &model, query, id); err != nil {
+	db := r.reader(ctx)
+	err := db.GetContext(ctx, &model, q.SQL(), q.Args()...)
+	if err == sql.ErrNoRows && db != r.conn(ctx) {
+		err = r.conn(ctx).GetContext(ctx, &model, q.SQL(), q.Args()...)
+	}
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("message not found")
+			return nil, domain.NewError(domain.ErrNotFound, "message not found")
 		}
 		return nil, err
 	}
@@ -138,18 +265,14 @@ func (r *messageRepository) GetMessageByID(ctx context.Context, id int64) (*doma
 
 // GetMessageByExternalID retrieves a message by external ID
 func (r *messageRepository) GetMessageByExternalID(ctx context.Context, externalID string) (*domain.Message, error) {
-	query := `
-		SELECT id, phone_number, template_id, parameters, 
-			order_id, customer_id, status, 
-			error_message, external_id, created_at, updated_at
-		FROM messages
-		WHERE external_id = $1
-	`
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+	q.Where("external_id = " + q.Arg(externalID))
+	whereTenantScope(ctx, q)
 
 	var model MessageModel
-	if err := r.db.GetContext(ctx, &model, query, externalID); err != nil {
+	if err := r.conn(ctx).GetContext(ctx, &model, q.SQL(), q.Args()...); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("message not found")
+			return nil, domain.NewError(domain.ErrNotFound, "message not found")
 		}
 		return nil, err
 	}
@@ -158,50 +281,72 @@ func (r *messageRepository) GetMessageByExternalID(ctx context.Context, external
 	return modelToDomainMessage(&model)
 }
 
-// ListMessages retrieves a list of messages
-func (r *messageRepository) ListMessages(ctx context.Context, orderID, customerID, phoneNumber string, limit, offset int) ([]*domain.Message, error) {
-	// Build query
+// GetTenantMessageByExternalID retrieves a message by external ID within a single tenant
+func (r *messageRepository) GetTenantMessageByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Message, error) {
 	query := `
-		SELECT id, phone_number, template_id, parameters, 
-			order_id, customer_id, status, 
-			error_message, external_id, created_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE 1=1
+		WHERE tenant_id = $1 AND external_id = $2
 	`
-	
-	// Add filters
-	args := []interface{}{}
-	argIndex := 1
 
-	if orderID != "" {
-		query += " AND order_id = $" + utils.GetPlaceholderIndex(argIndex)
-		args = append(args, orderID)
-		argIndex++
+	var model MessageModel
+	if err := r.conn(ctx).GetContext(ctx, &model, query, tenantID, externalID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return nil, err
 	}
 
-	if customerID != "" {
-		query += " AND customer_id = $" + utils.GetPlaceholderIndex(argIndex)
-		args = append(args, customerID)
-		argIndex++
-	}
+	// Convert to domain.Message
+	return modelToDomainMessage(&model)
+}
+
+// GetMessagesByIDs retrieves the messages with the given IDs in one query. As with
+// GetMessageByID, the primary is asked again when the replica misses some of them.
+func (r *messageRepository) GetMessagesByIDs(ctx context.Context, ids []int64) ([]*domain.Message, error) {
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+	q.Where("id = ANY(" + q.Arg(pq.Array(ids)) + ")")
+	whereTenantScope(ctx, q)
 
-	if phoneNumber != "" {
-		query += " AND phone_number = $" + utils.GetPlaceholderIndex(argIndex)
-		args = append(args, phoneNumber)
-		argIndex++
+	var models []MessageModel
+	db := r.reader(ctx)
+	err := db.SelectContext(ctx, &models, q.SQL(), q.Args()...)
+	if err == nil && db != r.conn(ctx) && len(models) < len(uniqueIDs(ids)) {
+		models = nil
+		err = r.conn(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Add pagination
-	query += " ORDER BY created_at DESC LIMIT $" + utils.GetPlaceholderIndex(argIndex) + " OFFSET $" + utils.GetPlaceholderIndex(argIndex+1)
-	args = append(args, limit, offset)
+	return r.modelsToDomainMessages(models), nil
+}
+
+// GetMessagesByExternalIDs retrieves the messages with the given external IDs in one query
+func (r *messageRepository) GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) ([]*domain.Message, error) {
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+	q.Where("external_id = ANY(" + q.Arg(pq.Array(externalIDs)) + ")")
+	whereTenantScope(ctx, q)
 
-	// Execute query
 	var models []MessageModel
-	if err := r.db.SelectContext(ctx, &models, query, args...); err != nil {
+	if err := r.conn(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
 		return nil, err
 	}
 
-	// Convert to domain.Messages
+	return r.modelsToDomainMessages(models), nil
+}
+
+// uniqueIDs counts each ID once
+func uniqueIDs(ids []int64) map[int64]bool {
+	unique := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
+
+// modelsToDomainMessages converts query results, skipping rows that cannot be converted
+func (r *messageRepository) modelsToDomainMessages(models []MessageModel) []*domain.Message {
 	messages := make([]*domain.Message, 0, len(models))
 	for _, model := range models {
 		msg, err := modelToDomainMessage(&model)
@@ -211,42 +356,493 @@ func (r *messageRepository) ListMessages(ctx context.Context, orderID, customerI
 		}
 		messages = append(messages, msg)
 	}
+	return messages
+}
+
+// GetMessageIDByExternalID resolves a tenant's external ID to the message ID without loading the message
+func (r *messageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error) {
+	query := `SELECT id FROM messages WHERE tenant_id = $1 AND external_id = $2`
 
-	return messages, nil
+	var id int64
+	if err := r.conn(ctx).GetContext(ctx, &id, query, tenantID, externalID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return 0, err
+	}
+	return id, nil
 }
 
-// UpdateMessageStatus updates the status of a message
-func (r *messageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorMessage, externalID string) error {
+// FindRecentDuplicate retrieves the newest message matching message's tenant, recipient,
+// template and parameters created since since. Parameters are compared as JSON values, so
+// key order does not matter.
+func (r *messageRepository) FindRecentDuplicate(ctx context.Context, message *domain.Message, since time.Time) (*domain.Message, error) {
+	model, err := domainToModel(message)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		UPDATE messages
-		SET status = $1, updated_at = $2
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE tenant_id = $1 AND phone_number = $2 AND template_id = $3 AND parameters = $4
+			AND created_at >= $5 AND erased_at IS NULL AND deleted_at IS NULL
+			AND status NOT IN ('failed', $6, $7)
+		ORDER BY created_at DESC
+		LIMIT 1
 	`
-	args := []interface{}{status, time.Now()}
-	argIndex := 3
 
-	// Add error message if provided
-	if errorMessage != "" {
-		query += ", error_message = $" + utils.GetPlaceholderIndex(argIndex)
-		args = append(args, errorMessage)
-		argIndex++
+	// Always read the primary: the duplicate is typically only milliseconds old
+	var duplicate MessageModel
+	if err := r.conn(ctx).GetContext(ctx, &duplicate, query,
+		model.TenantID, model.PhoneNumber, model.TemplateID, model.Parameters,
+		since, domain.StatusQuotaExceeded, domain.StatusExpired,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return nil, err
+	}
+
+	return modelToDomainMessage(&duplicate)
+}
+
+// GetMessagesByOrderID retrieves all messages for an order in creation order
+func (r *messageRepository) GetMessagesByOrderID(ctx context.Context, orderID string) ([]*domain.Message, error) {
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+	q.Where("order_id = " + q.Arg(orderID)).Where("deleted_at IS NULL")
+	whereTenantScope(ctx, q)
+	q.Append(" ORDER BY created_at ASC, id ASC")
+
+	var models []MessageModel
+	if err := r.conn(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// sortableColumns whitelists what listings can be ordered by. Column names cannot be query
+// arguments, so only the values of this map ever reach the SQL.
+var sortableColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"status":     "status",
+}
+
+// orderBy renders the ORDER BY clause of sort, breaking ties by ID so pages are stable
+func orderBy(sort domain.MessageSort) (string, error) {
+	column := "created_at"
+	if sort.By != "" {
+		var ok bool
+		if column, ok = sortableColumns[sort.By]; !ok {
+			return "", domain.NewError(domain.ErrValidation, "cannot sort by %q; use created_at, updated_at or status", sort.By)
+		}
+	}
+
+	direction := "DESC"
+	switch strings.ToLower(sort.Order) {
+	case "", "desc":
+	case "asc":
+		direction = "ASC"
+	default:
+		return "", domain.NewError(domain.ErrValidation, "sort order must be asc or desc, not %q", sort.Order)
+	}
+	return " ORDER BY " + column + " " + direction + ", id " + direction, nil
+}
+
+// ListMessages retrieves a page of the messages matching the filter in sort order
+func (r *messageRepository) ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) ([]*domain.Message, error) {
+	filter = scopeFilter(ctx, filter)
+	order, err := orderBy(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build query
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+
+	// Add filters
+	whereMessageFilter(q, filter)
+
+	// Add ordering and pagination
+	q.Append(order + " LIMIT " + q.Arg(limit) + " OFFSET " + q.Arg(offset))
+
+	// Execute query
+	var models []MessageModel
+	if err := r.reader(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// ListMessagesAfterID retrieves up to limit messages with an ID greater than afterID in ID order.
+// It is used to page through large result sets with a stable keyset cursor.
+func (r *messageRepository) ListMessagesAfterID(ctx context.Context, filter domain.MessageFilter, afterID int64, limit int) ([]*domain.Message, error) {
+	filter = scopeFilter(ctx, filter)
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+
+	whereMessageFilter(q, filter)
+	q.Where("id > " + q.Arg(afterID))
+	q.Append(" ORDER BY id ASC LIMIT " + q.Arg(limit))
+
+	var models []MessageModel
+	if err := r.reader(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// searchTextExpression is the document free-text searches match; it must stay identical to
+// the expression of idx_messages_search_text for the index to be used
+const searchTextExpression = `(jsonb_to_tsvector('simple', parameters, '["string", "numeric"]') || to_tsvector('simple', COALESCE(content_snapshot, '')))`
+
+// SearchMessages retrieves a page of the messages matching search, newest first. Parameter
+// conditions become one JSONB containment check and the text a full-text match, both served
+// by GIN indexes.
+func (r *messageRepository) SearchMessages(ctx context.Context, search domain.MessageSearch, limit, offset int) ([]*domain.Message, error) {
+	q := newQuery("SELECT " + messageColumns + " FROM messages")
+
+	whereMessageFilter(q, scopeFilter(ctx, search.Filter))
+	if len(search.Parameters) > 0 {
+		contained, err := json.Marshal(search.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		q.Where("parameters @> " + q.Arg(string(contained)) + "::jsonb")
+	}
+	if search.Text != "" {
+		q.Where(searchTextExpression + " @@ plainto_tsquery('simple', " + q.Arg(search.Text) + ")")
+	}
+	q.Append(" ORDER BY created_at DESC, id DESC LIMIT " + q.Arg(limit) + " OFFSET " + q.Arg(offset))
+
+	var models []MessageModel
+	if err := r.reader(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// CountMessages returns the number of messages matching the filter
+func (r *messageRepository) CountMessages(ctx context.Context, filter domain.MessageFilter) (int, error) {
+	filter = scopeFilter(ctx, filter)
+	q := newQuery(`
+		SELECT COUNT(*)
+		FROM messages
+	`)
+
+	whereMessageFilter(q, filter)
+
+	var count int
+	if err := r.reader(ctx).GetContext(ctx, &count, q.SQL(), q.Args()...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountMessagesByDay counts messages matching the filter per UTC creation day, template and
+// status, ordered by day and template. The created_at range prunes partitions.
+func (r *messageRepository) CountMessagesByDay(ctx context.Context, filter domain.MessageFilter) ([]domain.StatusCount, error) {
+	filter = scopeFilter(ctx, filter)
+	q := newQuery(`
+		SELECT date_trunc('day', created_at) AS day, template_id, status, COUNT(*) AS count
+		FROM messages
+	`)
+
+	whereMessageFilter(q, filter)
+	q.Append(" GROUP BY 1, 2, 3 ORDER BY 1, 2, 3")
+
+	var rows []struct {
+		Day        time.Time `db:"day"`
+		TemplateID string    `db:"template_id"`
+		Status     string    `db:"status"`
+		Count      int64     `db:"count"`
+	}
+	if err := r.reader(ctx).SelectContext(ctx, &rows, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	counts := make([]domain.StatusCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, domain.StatusCount{Day: row.Day, TemplateID: row.TemplateID, Status: row.Status, Count: row.Count})
+	}
+	return counts, nil
+}
+
+// GetDeliveryLatency computes the median and 95th percentile time from creation to each
+// delivery stage for messages matching the filter
+func (r *messageRepository) GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) ([]domain.StageLatency, error) {
+	filter = scopeFilter(ctx, filter)
+	stages := []string{domain.StageSent, domain.StageDelivered, domain.StageRead}
+
+	var selects []string
+	for _, stage := range stages {
+		latency := "EXTRACT(EPOCH FROM " + stageColumns[stage] + " - created_at)"
+		selects = append(selects,
+			"COUNT("+stageColumns[stage]+")",
+			"percentile_cont(0.5) WITHIN GROUP (ORDER BY "+latency+")",
+			"percentile_cont(0.95) WITHIN GROUP (ORDER BY "+latency+")",
+		)
 	}
+	q := newQuery("SELECT " + strings.Join(selects, ", ") + " FROM messages")
+	whereMessageFilter(q, filter)
 
-	// Add external ID if provided
+	counts := make([]int64, len(stages))
+	p50s := make([]sql.NullFloat64, len(stages))
+	p95s := make([]sql.NullFloat64, len(stages))
+	dest := make([]interface{}, 0, 3*len(stages))
+	for i := range stages {
+		dest = append(dest, &counts[i], &p50s[i], &p95s[i])
+	}
+	if err := r.reader(ctx).QueryRowContext(ctx, q.SQL(), q.Args()...).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	latencies := make([]domain.StageLatency, len(stages))
+	for i, stage := range stages {
+		latencies[i] = domain.StageLatency{
+			Stage: stage,
+			Count: counts[i],
+			P50:   time.Duration(p50s[i].Float64 * float64(time.Second)),
+			P95:   time.Duration(p95s[i].Float64 * float64(time.Second)),
+		}
+	}
+	return latencies, nil
+}
+
+// UpdateMessageStatus updates the status of a message
+func (r *messageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error {
+	q := newQuery("UPDATE messages")
+	now := q.Arg(time.Now())
+	q.Append(" SET status = " + q.Arg(status) + ", updated_at = " + now)
+
+	// Record when the message first reached a delivery stage
+	if column, ok := stageColumns[status]; ok {
+		q.Append(", " + column + " = COALESCE(" + column + ", " + now + ")")
+	}
+
+	// Set error code, error message and external ID if provided
+	if errorCode != "" {
+		q.Append(", error_code = " + q.Arg(errorCode))
+	}
+	if errorMessage != "" {
+		q.Append(", error_message = " + q.Arg(errorMessage))
+	}
 	if externalID != "" {
-		query += ", external_id = $" + utils.GetPlaceholderIndex(argIndex)
-		args = append(args, externalID)
-		argIndex++
+		q.Append(", external_id = " + q.Arg(externalID))
 	}
 
-	// Add where clause
-	query += " WHERE id = $" + utils.GetPlaceholderIndex(argIndex)
-	args = append(args, id)
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
 
 	// Execute query
-	_, err := r.db.ExecContext(ctx, query, args...)
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
 	return err
 }
 
+// stageColumns maps delivery stages to the columns recording when they were first reached
+var stageColumns = map[string]string{
+	domain.StageSent:      "sent_at",
+	domain.StageDelivered: "delivered_at",
+	domain.StageRead:      "read_at",
+}
+
+// UpdateMessageStatuses applies status updates in order with one statement and returns each
+// updated message's resulting state. Updates are collapsed per message exactly as sequential
+// UpdateMessageStatus calls would leave the row, and the sequence advances once per update, so
+// the caller numbers a message's k-th of n updates final-n+k. Messages that no longer exist
+// are missing from the result.
+func (r *messageRepository) UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (map[int64]domain.StatusUpdateResult, error) {
+	if len(updates) == 0 {
+		return map[int64]domain.StatusUpdateResult{}, nil
+	}
+
+	type collapsed struct {
+		domain.StatusUpdate
+		count  int
+		stages map[string]time.Time
+	}
+	now := time.Now()
+	var order []int64
+	byID := make(map[int64]*collapsed)
+	for _, update := range updates {
+		c, ok := byID[update.MessageID]
+		if !ok {
+			c = &collapsed{StatusUpdate: domain.StatusUpdate{MessageID: update.MessageID}, stages: make(map[string]time.Time)}
+			byID[update.MessageID] = c
+			order = append(order, update.MessageID)
+		}
+		c.Status = update.Status
+		if update.ErrorCode != "" {
+			c.ErrorCode = update.ErrorCode
+		}
+		if update.ErrorMessage != "" {
+			c.ErrorMessage = update.ErrorMessage
+		}
+		if update.ExternalID != "" {
+			c.ExternalID = update.ExternalID
+		}
+		if _, isStage := stageColumns[update.Status]; isStage {
+			at := update.At
+			if at.IsZero() {
+				at = now
+			}
+			if first, seen := c.stages[update.Status]; !seen || at.Before(first) {
+				c.stages[update.Status] = at
+			}
+		}
+		c.count++
+	}
+
+	n := len(order)
+	ids, counts := make([]int64, n), make([]int64, n)
+	statuses, errorCodes, errorMessages, externalIDs := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+	sentAt, deliveredAt, readAt := make([]string, n), make([]string, n), make([]string, n)
+	stageTime := func(c *collapsed, stage string) string {
+		if at, ok := c.stages[stage]; ok {
+			return at.Format(timestampLayout)
+		}
+		return ""
+	}
+	for i, id := range order {
+		c := byID[id]
+		ids[i], statuses[i], errorCodes[i], errorMessages[i], externalIDs[i], counts[i] =
+			id, c.Status, c.ErrorCode, c.ErrorMessage, c.ExternalID, int64(c.count)
+		sentAt[i], deliveredAt[i], readAt[i] =
+			stageTime(c, domain.StageSent), stageTime(c, domain.StageDelivered), stageTime(c, domain.StageRead)
+	}
+
+	// The self-join reads each row as it was before this statement, so RETURNING can tell
+	// which stage times this update recorded first
+	query := `
+		UPDATE messages AS m
+		SET status = u.status,
+			error_code = COALESCE(NULLIF(u.error_code, ''), m.error_code),
+			error_message = COALESCE(NULLIF(u.error_message, ''), m.error_message),
+			external_id = COALESCE(NULLIF(u.external_id, ''), m.external_id),
+			status_sequence = m.status_sequence + u.events,
+			sent_at = COALESCE(m.sent_at, NULLIF(u.sent_at, '')::timestamp),
+			delivered_at = COALESCE(m.delivered_at, NULLIF(u.delivered_at, '')::timestamp),
+			read_at = COALESCE(m.read_at, NULLIF(u.read_at, '')::timestamp),
+			updated_at = $1
+		FROM unnest($2::bigint[], $3::text[], $4::text[], $5::text[], $6::text[], $7::bigint[],
+			$8::text[], $9::text[], $10::text[])
+			AS u(id, status, error_code, error_message, external_id, events, sent_at, delivered_at, read_at)
+		JOIN messages AS before ON before.id = u.id
+		WHERE m.id = u.id
+		RETURNING m.id, m.status_sequence, m.created_at, before.status, m.status,
+			CASE WHEN before.sent_at IS NULL THEN m.sent_at END,
+			CASE WHEN before.delivered_at IS NULL THEN m.delivered_at END,
+			CASE WHEN before.read_at IS NULL THEN m.read_at END
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, now, pq.Array(ids), pq.Array(statuses),
+		pq.Array(errorCodes), pq.Array(errorMessages), pq.Array(externalIDs), pq.Array(counts),
+		pq.Array(sentAt), pq.Array(deliveredAt), pq.Array(readAt))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make(map[int64]domain.StatusUpdateResult, n)
+	for rows.Next() {
+		var id int64
+		var result domain.StatusUpdateResult
+		var sent, delivered, read sql.NullTime
+		if err := rows.Scan(&id, &result.Sequence, &result.CreatedAt, &result.PreviousStatus, &result.Status, &sent, &delivered, &read); err != nil {
+			return nil, err
+		}
+		result.SentAt, result.DeliveredAt, result.ReadAt = sent.Time, delivered.Time, read.Time
+		results[id] = result
+	}
+	return results, rows.Err()
+}
+
+// whereMessageFilter adds the conditions of filter shared by list, count, export, erase and
+// release queries. Soft deleted messages are skipped unless the filter includes them.
+func whereMessageFilter(q *query, filter domain.MessageFilter) {
+	if !filter.IncludeDeleted {
+		q.Where("deleted_at IS NULL")
+	}
+	q.WhereEq("tenant_id", filter.TenantID)
+	q.WhereEq("order_id", filter.OrderID)
+	q.WhereEq("customer_id", filter.CustomerID)
+	q.WhereEq("phone_number", filter.PhoneNumber)
+	q.WhereEq("status", filter.Status)
+	q.WhereEq("template_id", filter.TemplateID)
+	q.WhereTime("created_at", ">=", filter.CreatedAfter)
+	q.WhereTime("created_at", "<", filter.CreatedBefore)
+}
+
+// scopeFilter confines filter to the tenant ctx is scoped to, whatever tenant it asked for
+func scopeFilter(ctx context.Context, filter domain.MessageFilter) domain.MessageFilter {
+	if tenantID, ok := domain.TenantScope(ctx); ok {
+		filter.TenantID = tenantID
+	}
+	return filter
+}
+
+// phoneDigitsSQL is domain.PhoneDigits of the phone_number column of messages
+const phoneDigitsSQL = `regexp_replace(phone_number, '[^0-9]', '', 'g')`
+
+// whereSubjectPhone adds the condition that column, holding phone numbers as domain.PhoneDigits
+// returns them, is the filter's phone number or one its customer's messages were sent to. It
+// adds nothing and reports false when the filter names neither.
+func whereSubjectPhone(q *query, column string, filter domain.MessageFilter) bool {
+	switch {
+	case filter.PhoneNumber != "":
+		q.Where(column + " = " + q.Arg(domain.PhoneDigits(filter.PhoneNumber)))
+	case filter.CustomerID != "" && filter.TenantID != "":
+		q.Where(column + " IN (SELECT " + phoneDigitsSQL + " FROM messages WHERE tenant_id = " +
+			q.Arg(filter.TenantID) + " AND customer_id = " + q.Arg(filter.CustomerID) + ")")
+	default:
+		return false
+	}
+	return true
+}
+
+// whereTenantScope adds the tenant ctx is scoped to, so lookups and changes by ID never reach
+// another tenant's messages
+func whereTenantScope(ctx context.Context, q *query) *query {
+	if tenantID, ok := domain.TenantScope(ctx); ok {
+		q.Where("tenant_id = " + q.Arg(tenantID))
+	}
+	return q
+}
+
+// SaveContentSnapshot stores the exact content sent to the provider for a message
+func (r *messageRepository) SaveContentSnapshot(ctx context.Context, id int64, snapshot string) error {
+	q := newQuery("UPDATE messages")
+	q.Append(" SET content_snapshot = " + q.Arg(snapshot) + ", updated_at = " + q.Arg(time.Now()))
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
+
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	return err
+}
+
+// NextStatusSequence atomically increments and returns the status event sequence of a message
+func (r *messageRepository) NextStatusSequence(ctx context.Context, id int64) (int64, error) {
+	q := newQuery("UPDATE messages SET status_sequence = status_sequence + 1")
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
+	q.Append(" RETURNING status_sequence")
+
+	var sequence int64
+	if err := r.conn(ctx).GetContext(ctx, &sequence, q.SQL(), q.Args()...); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
 // Helper function to convert model to domain message
 func modelToDomainMessage(model *MessageModel) (*domain.Message, error) {
 	// Parse parameters JSON
@@ -262,6 +858,8 @@ func modelToDomainMessage(model *MessageModel) (*domain.Message, error) {
 		TemplateID:  model.TemplateID,
 		Parameters:  parameters,
 		Status:      model.Status,
+		TenantID:    model.TenantID,
+		Attempt:     model.Attempt,
 		CreatedAt:   model.CreatedAt,
 		UpdatedAt:   model.UpdatedAt,
 	}
@@ -273,12 +871,277 @@ func modelToDomainMessage(model *MessageModel) (*domain.Message, error) {
 	if model.CustomerID.Valid {
 		message.CustomerID = model.CustomerID.String
 	}
+	if model.ErrorCode.Valid {
+		message.ErrorCode = model.ErrorCode.String
+	}
 	if model.ErrorMessage.Valid {
 		message.ErrorMessage = model.ErrorMessage.String
 	}
 	if model.ExternalID.Valid {
 		message.ExternalID = model.ExternalID.String
 	}
+	if model.RecipientTimezone.Valid {
+		message.RecipientTimezone = model.RecipientTimezone.String
+	}
+	if model.ExpiresAt.Valid {
+		message.ExpiresAt = model.ExpiresAt.Time
+	}
+	if model.RetryOf.Valid {
+		message.RetryOfID = model.RetryOf.Int64
+	}
+	if model.ContentSnapshot.Valid {
+		message.ContentSnapshot = model.ContentSnapshot.String
+	}
+	if model.ArchiveKey.Valid {
+		message.ArchiveKey = model.ArchiveKey.String
+	}
+	if model.DeletedAt.Valid {
+		message.DeletedAt = model.DeletedAt.Time
+	}
+	if model.ErasedAt.Valid {
+		message.ErasedAt = model.ErasedAt.Time
+	}
 
 	return message, nil
-}
\ No newline at end of file
+}
+
+// EraseMessages removes personal data from every message matching the filter. Rows are
+// anonymized in place, keeping order and delivery history, or deleted when hardDelete is set.
+func (r *messageRepository) EraseMessages(ctx context.Context, filter domain.MessageFilter, hardDelete bool) (int64, error) {
+	// Soft deleted messages still hold personal data
+	filter = scopeFilter(ctx, filter)
+	filter.IncludeDeleted = true
+
+	q := newQuery("DELETE FROM messages")
+	if !hardDelete {
+		q = newQuery("")
+		now := q.Arg(time.Now())
+		q.Append(`
+			UPDATE messages
+			SET phone_number = 'erased', customer_id = NULL, parameters = '{}',
+				error_message = NULL, content_snapshot = NULL,
+				erased_at = ` + now + `, updated_at = ` + now)
+	}
+	whereMessageFilter(q, filter)
+	if !q.Filtered() {
+		return 0, errors.New("refusing to erase messages without a filter")
+	}
+
+	result, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeMessagesBefore deletes up to limit messages created before cutoff, oldest first,
+// so large purges run as short transactions instead of one long lock-holding delete
+func (r *messageRepository) PurgeMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM messages
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListMessagesToArchive returns up to limit messages created before the cutoff that are
+// still stored in full, oldest first
+func (r *messageRepository) ListMessagesToArchive(ctx context.Context, before time.Time, limit int) ([]*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE created_at < $1 AND archive_key IS NULL
+		ORDER BY id ASC
+		LIMIT $2
+	`
+
+	var models []MessageModel
+	if err := r.conn(ctx).SelectContext(ctx, &models, query, before, limit); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// MarkMessagesArchived turns archived messages into stub rows: identifiers, status and
+// timestamps stay queryable while payloads live only in the archive. The phone number and
+// customer ID stay too, so erasure and access requests still find archived messages.
+func (r *messageRepository) MarkMessagesArchived(ctx context.Context, ids []int64, archiveKey string) error {
+	query := `
+		UPDATE messages
+		SET archive_key = $1, archived_at = $2,
+			parameters = '{}', error_message = NULL, content_snapshot = NULL
+		WHERE id = ANY($3) AND archive_key IS NULL
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, archiveKey, time.Now(), pq.Array(ids))
+	return err
+}
+
+// DeleteMessage marks a message deleted, or removes its row when hardDelete is set. Deleting
+// an already soft deleted message again keeps its original deletion time.
+func (r *messageRepository) DeleteMessage(ctx context.Context, id int64, hardDelete bool) error {
+	q := newQuery("DELETE FROM messages")
+	if !hardDelete {
+		q = newQuery("")
+		now := q.Arg(time.Now())
+		q.Append("UPDATE messages SET deleted_at = " + now + ", updated_at = " + now)
+		q.Where("deleted_at IS NULL")
+	}
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
+
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	return err
+}
+
+// HoldMessage marks a queued message as held by a send pause
+func (r *messageRepository) HoldMessage(ctx context.Context, id int64) error {
+	q := newQuery("UPDATE messages")
+	q.Append(" SET held_at = " + q.Arg(time.Now()))
+	q.Where("id = " + q.Arg(id)).Where("status = 'queued'")
+	whereTenantScope(ctx, q)
+
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	return err
+}
+
+// ReleaseHeldMessages clears the hold of up to limit held queued messages matching the filter
+// and returns them. Each message is released once even when several replicas release concurrently.
+func (r *messageRepository) ReleaseHeldMessages(ctx context.Context, filter domain.MessageFilter, limit int) ([]*domain.Message, error) {
+	// The conditions go to the subquery selecting the messages to release
+	q := newQuery(`
+		UPDATE messages
+		SET held_at = NULL
+		WHERE id IN (
+			SELECT id FROM messages`)
+	q.Where("held_at IS NOT NULL AND status = 'queued'")
+	whereMessageFilter(q, filter)
+	q.Append(`
+			ORDER BY id ASC
+			LIMIT ` + q.Arg(limit) + `
+			FOR UPDATE SKIP LOCKED
+		) AND held_at IS NOT NULL
+		RETURNING ` + messageColumns)
+
+	var models []MessageModel
+	if err := r.conn(ctx).SelectContext(ctx, &models, q.SQL(), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// domainToModel converts a new message to its database model
+func domainToModel(message *domain.Message) (*MessageModel, error) {
+	// Convert parameters to JSON
+	paramsJSON, err := json.Marshal(message.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create model
+	model := MessageModel{
+		PhoneNumber: message.PhoneNumber,
+		TemplateID:  message.TemplateID,
+		Parameters:  string(paramsJSON),
+		Status:      message.Status,
+		TenantID:    message.TenantID,
+		Attempt:     message.Attempt,
+		CreatedAt:   message.CreatedAt,
+		UpdatedAt:   message.UpdatedAt,
+	}
+
+	if model.TenantID == "" {
+		model.TenantID = domain.DefaultTenantID
+	}
+	if model.Attempt == 0 {
+		model.Attempt = 1
+	}
+
+	// Set nullable fields
+	if message.OrderID != "" {
+		model.OrderID = sql.NullString{String: message.OrderID, Valid: true}
+	}
+	if message.CustomerID != "" {
+		model.CustomerID = sql.NullString{String: message.CustomerID, Valid: true}
+	}
+	if message.ErrorCode != "" {
+		model.ErrorCode = sql.NullString{String: message.ErrorCode, Valid: true}
+	}
+	if message.ErrorMessage != "" {
+		model.ErrorMessage = sql.NullString{String: message.ErrorMessage, Valid: true}
+	}
+	if message.ExternalID != "" {
+		model.ExternalID = sql.NullString{String: message.ExternalID, Valid: true}
+	}
+	if message.RecipientTimezone != "" {
+		model.RecipientTimezone = sql.NullString{String: message.RecipientTimezone, Valid: true}
+	}
+	if !message.ExpiresAt.IsZero() {
+		model.ExpiresAt = sql.NullTime{Time: message.ExpiresAt, Valid: true}
+	}
+	if message.RetryOfID != 0 {
+		model.RetryOf = sql.NullInt64{Int64: message.RetryOfID, Valid: true}
+	}
+
+	return &model, nil
+}
+
+// DeferMessage marks a queued message as deferred until the given time
+func (r *messageRepository) DeferMessage(ctx context.Context, id int64, until time.Time) error {
+	// Deferrals come in the recipient's timezone; TIMESTAMP keeps only the wall clock
+	q := newQuery("UPDATE messages")
+	q.Append(" SET deferred_until = " + q.Arg(until.UTC()))
+	q.Where("id = " + q.Arg(id)).Where("status = 'queued'")
+	whereTenantScope(ctx, q)
+
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	return err
+}
+
+// ReleaseDueMessages clears the deferral of up to limit queued messages deferred until now or
+// earlier and returns them. Each message is released once even when several replicas release
+// concurrently.
+func (r *messageRepository) ReleaseDueMessages(ctx context.Context, now time.Time, limit int) ([]*domain.Message, error) {
+	query := `
+		UPDATE messages
+		SET deferred_until = NULL
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE deferred_until <= $1 AND status = 'queued'
+			ORDER BY deferred_until ASC, id ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) AND deferred_until IS NOT NULL
+		RETURNING ` + messageColumns
+
+	var models []MessageModel
+	if err := r.conn(ctx).SelectContext(ctx, &models, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return r.modelsToDomainMessages(models), nil
+}
+
+// MarkMessageEnqueued records when the broker acknowledged the message's write to the send
+// topic. Releases of held or deferred messages write it again, so it is the latest write.
+func (r *messageRepository) MarkMessageEnqueued(ctx context.Context, id int64, at time.Time) error {
+	q := newQuery("UPDATE messages")
+	q.Append(" SET enqueued_at = " + q.Arg(at.UTC()))
+	q.Where("id = " + q.Arg(id))
+	whereTenantScope(ctx, q)
+
+	_, err := r.conn(ctx).ExecContext(ctx, q.SQL(), q.Args()...)
+	return err
+}
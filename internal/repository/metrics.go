@@ -0,0 +1,249 @@
+// internal/repository/metrics.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+)
+
+// queryDuration observes how long each repository query takes, whichever pool serves it
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "whatsapp_db_query_duration_seconds",
+	Help:    "Time taken by message repository queries, by query name and result (success or error).",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"query", "result"})
+
+// observeQuery records a query that started at start and ended with err
+func observeQuery(name string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	queryDuration.WithLabelValues(name, result).Observe(time.Since(start).Seconds())
+}
+
+// pgxPoolCollector exports the statistics of a pgx pool, mirroring what the Go collectors
+// report for database/sql pools
+type pgxPoolCollector struct {
+	pool *pgxpool.Pool
+
+	maxConns         *prometheus.Desc
+	totalConns       *prometheus.Desc
+	inUse            *prometheus.Desc
+	idle             *prometheus.Desc
+	constructing     *prometheus.Desc
+	waitCount        *prometheus.Desc
+	waitDuration     *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+}
+
+// NewPgxPoolCollector creates a collector for pool whose metrics are named after namespace,
+// e.g. whatsapp_pgx_in_use_connections for "whatsapp_pgx"
+func NewPgxPoolCollector(pool *pgxpool.Pool, namespace string) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(namespace+"_"+name, help, nil, nil)
+	}
+	return &pgxPoolCollector{
+		pool:             pool,
+		maxConns:         desc("max_open_connections", "Maximum number of connections in the pool."),
+		totalConns:       desc("open_connections", "Connections in the pool, in use, idle or being opened."),
+		inUse:            desc("in_use_connections", "Connections currently acquired from the pool."),
+		idle:             desc("idle_connections", "Idle connections in the pool."),
+		constructing:     desc("constructing_connections", "Connections being opened."),
+		waitCount:        desc("wait_count_total", "Acquires that had to wait for a connection."),
+		waitDuration:     desc("wait_duration_seconds_total", "Total time spent acquiring connections, waits included."),
+		canceledAcquires: desc("canceled_acquires_total", "Acquires canceled by their context before getting a connection."),
+	}
+}
+
+func (c *pgxPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxConns
+	ch <- c.totalConns
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.constructing
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.canceledAcquires
+}
+
+func (c *pgxPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// instrumentedMessageRepository times every call to the wrapped repository
+type instrumentedMessageRepository struct {
+	repo MessageRepository
+}
+
+// NewInstrumentedMessageRepository wraps repo so the latency of each query is observed under
+// its name. Wrap the repository that talks to the database, inside any cache, so cache hits
+// do not hide slow queries.
+func NewInstrumentedMessageRepository(repo MessageRepository) MessageRepository {
+	return &instrumentedMessageRepository{repo: repo}
+}
+
+func (r *instrumentedMessageRepository) CreateMessage(ctx context.Context, message *domain.Message) (id int64, err error) {
+	defer func(start time.Time) { observeQuery("create_message", start, err) }(time.Now())
+	return r.repo.CreateMessage(ctx, message)
+}
+
+func (r *instrumentedMessageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) (ids []int64, err error) {
+	defer func(start time.Time) { observeQuery("create_messages", start, err) }(time.Now())
+	return r.repo.CreateMessages(ctx, messages)
+}
+
+func (r *instrumentedMessageRepository) GetMessageByID(ctx context.Context, id int64) (message *domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_message_by_id", start, err) }(time.Now())
+	return r.repo.GetMessageByID(ctx, id)
+}
+
+func (r *instrumentedMessageRepository) GetMessageByExternalID(ctx context.Context, externalID string) (message *domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_message_by_external_id", start, err) }(time.Now())
+	return r.repo.GetMessageByExternalID(ctx, externalID)
+}
+
+func (r *instrumentedMessageRepository) GetTenantMessageByExternalID(ctx context.Context, tenantID, externalID string) (message *domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_tenant_message_by_external_id", start, err) }(time.Now())
+	return r.repo.GetTenantMessageByExternalID(ctx, tenantID, externalID)
+}
+
+func (r *instrumentedMessageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (id int64, err error) {
+	defer func(start time.Time) { observeQuery("get_message_id_by_external_id", start, err) }(time.Now())
+	return r.repo.GetMessageIDByExternalID(ctx, tenantID, externalID)
+}
+
+func (r *instrumentedMessageRepository) GetMessagesByIDs(ctx context.Context, ids []int64) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_messages_by_ids", start, err) }(time.Now())
+	return r.repo.GetMessagesByIDs(ctx, ids)
+}
+
+func (r *instrumentedMessageRepository) GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_messages_by_external_ids", start, err) }(time.Now())
+	return r.repo.GetMessagesByExternalIDs(ctx, externalIDs)
+}
+
+func (r *instrumentedMessageRepository) GetMessagesByOrderID(ctx context.Context, orderID string) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("get_messages_by_order_id", start, err) }(time.Now())
+	return r.repo.GetMessagesByOrderID(ctx, orderID)
+}
+
+func (r *instrumentedMessageRepository) FindRecentDuplicate(ctx context.Context, message *domain.Message, since time.Time) (duplicate *domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("find_recent_duplicate", start, err) }(time.Now())
+	return r.repo.FindRecentDuplicate(ctx, message, since)
+}
+
+func (r *instrumentedMessageRepository) ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("list_messages", start, err) }(time.Now())
+	return r.repo.ListMessages(ctx, filter, sort, limit, offset)
+}
+
+func (r *instrumentedMessageRepository) ListMessagesAfterID(ctx context.Context, filter domain.MessageFilter, afterID int64, limit int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("list_messages_after_id", start, err) }(time.Now())
+	return r.repo.ListMessagesAfterID(ctx, filter, afterID, limit)
+}
+
+func (r *instrumentedMessageRepository) SearchMessages(ctx context.Context, search domain.MessageSearch, limit, offset int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("search_messages", start, err) }(time.Now())
+	return r.repo.SearchMessages(ctx, search, limit, offset)
+}
+
+func (r *instrumentedMessageRepository) CountMessages(ctx context.Context, filter domain.MessageFilter) (count int, err error) {
+	defer func(start time.Time) { observeQuery("count_messages", start, err) }(time.Now())
+	return r.repo.CountMessages(ctx, filter)
+}
+
+func (r *instrumentedMessageRepository) CountMessagesByDay(ctx context.Context, filter domain.MessageFilter) (counts []domain.StatusCount, err error) {
+	defer func(start time.Time) { observeQuery("count_messages_by_day", start, err) }(time.Now())
+	return r.repo.CountMessagesByDay(ctx, filter)
+}
+
+func (r *instrumentedMessageRepository) GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) (latencies []domain.StageLatency, err error) {
+	defer func(start time.Time) { observeQuery("get_delivery_latency", start, err) }(time.Now())
+	return r.repo.GetDeliveryLatency(ctx, filter)
+}
+
+func (r *instrumentedMessageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) (err error) {
+	defer func(start time.Time) { observeQuery("update_message_status", start, err) }(time.Now())
+	return r.repo.UpdateMessageStatus(ctx, id, status, errorCode, errorMessage, externalID)
+}
+
+func (r *instrumentedMessageRepository) SaveContentSnapshot(ctx context.Context, id int64, snapshot string) (err error) {
+	defer func(start time.Time) { observeQuery("save_content_snapshot", start, err) }(time.Now())
+	return r.repo.SaveContentSnapshot(ctx, id, snapshot)
+}
+
+func (r *instrumentedMessageRepository) NextStatusSequence(ctx context.Context, id int64) (seq int64, err error) {
+	defer func(start time.Time) { observeQuery("next_status_sequence", start, err) }(time.Now())
+	return r.repo.NextStatusSequence(ctx, id)
+}
+
+func (r *instrumentedMessageRepository) UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (results map[int64]domain.StatusUpdateResult, err error) {
+	defer func(start time.Time) { observeQuery("update_message_statuses", start, err) }(time.Now())
+	return r.repo.UpdateMessageStatuses(ctx, updates)
+}
+
+func (r *instrumentedMessageRepository) EraseMessages(ctx context.Context, filter domain.MessageFilter, hardDelete bool) (erased int64, err error) {
+	defer func(start time.Time) { observeQuery("erase_messages", start, err) }(time.Now())
+	return r.repo.EraseMessages(ctx, filter, hardDelete)
+}
+
+func (r *instrumentedMessageRepository) PurgeMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (purged int64, err error) {
+	defer func(start time.Time) { observeQuery("purge_messages_before", start, err) }(time.Now())
+	return r.repo.PurgeMessagesBefore(ctx, cutoff, limit)
+}
+
+func (r *instrumentedMessageRepository) ListMessagesToArchive(ctx context.Context, before time.Time, limit int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("list_messages_to_archive", start, err) }(time.Now())
+	return r.repo.ListMessagesToArchive(ctx, before, limit)
+}
+
+func (r *instrumentedMessageRepository) MarkMessagesArchived(ctx context.Context, ids []int64, archiveKey string) (err error) {
+	defer func(start time.Time) { observeQuery("mark_messages_archived", start, err) }(time.Now())
+	return r.repo.MarkMessagesArchived(ctx, ids, archiveKey)
+}
+
+func (r *instrumentedMessageRepository) HoldMessage(ctx context.Context, id int64) (err error) {
+	defer func(start time.Time) { observeQuery("hold_message", start, err) }(time.Now())
+	return r.repo.HoldMessage(ctx, id)
+}
+
+func (r *instrumentedMessageRepository) ReleaseHeldMessages(ctx context.Context, filter domain.MessageFilter, limit int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("release_held_messages", start, err) }(time.Now())
+	return r.repo.ReleaseHeldMessages(ctx, filter, limit)
+}
+
+func (r *instrumentedMessageRepository) DeferMessage(ctx context.Context, id int64, until time.Time) (err error) {
+	defer func(start time.Time) { observeQuery("defer_message", start, err) }(time.Now())
+	return r.repo.DeferMessage(ctx, id, until)
+}
+
+func (r *instrumentedMessageRepository) ReleaseDueMessages(ctx context.Context, now time.Time, limit int) (messages []*domain.Message, err error) {
+	defer func(start time.Time) { observeQuery("release_due_messages", start, err) }(time.Now())
+	return r.repo.ReleaseDueMessages(ctx, now, limit)
+}
+
+func (r *instrumentedMessageRepository) MarkMessageEnqueued(ctx context.Context, id int64, at time.Time) (err error) {
+	defer func(start time.Time) { observeQuery("mark_message_enqueued", start, err) }(time.Now())
+	return r.repo.MarkMessageEnqueued(ctx, id, at)
+}
+
+func (r *instrumentedMessageRepository) DeleteMessage(ctx context.Context, id int64, hardDelete bool) (err error) {
+	defer func(start time.Time) { observeQuery("delete_message", start, err) }(time.Now())
+	return r.repo.DeleteMessage(ctx, id, hardDelete)
+}
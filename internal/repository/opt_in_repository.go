@@ -0,0 +1,155 @@
+// internal/repository/opt_in_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// OptInRepository stores the opt-in and opt-out events of contacts. Events are only ever
+// added, so the history stays available as proof, until the contact's data is erased.
+type OptInRepository interface {
+	RecordOptIn(ctx context.Context, event *domain.OptInEvent) error
+	// ListOptIns returns a contact's events, most recent first
+	ListOptIns(ctx context.Context, tenantID, phoneNumber string) ([]domain.OptInEvent, error)
+	// LatestOptIn returns a contact's most recent event, or nil when there is none
+	LatestOptIn(ctx context.Context, tenantID, phoneNumber string) (*domain.OptInEvent, error)
+	// EraseOptIns deletes the events of the customer or phone number the filter names, keeping
+	// a latest opt-out without its evidence
+	EraseOptIns(ctx context.Context, filter domain.MessageFilter) (int64, error)
+}
+
+// optInModel represents an opt-in event in the database
+type optInModel struct {
+	ID          int64          `db:"id"`
+	TenantID    string         `db:"tenant_id"`
+	PhoneNumber string         `db:"phone_number"`
+	Action      string         `db:"action"`
+	Source      string         `db:"source"`
+	Channel     string         `db:"channel"`
+	Evidence    sql.NullString `db:"evidence"`
+	Actor       string         `db:"actor"`
+	OccurredAt  time.Time      `db:"occurred_at"`
+	RecordedAt  time.Time      `db:"recorded_at"`
+}
+
+// toDomain converts the model to a domain event
+func (m optInModel) toDomain() domain.OptInEvent {
+	return domain.OptInEvent{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		PhoneNumber: m.PhoneNumber,
+		Action:      m.Action,
+		Source:      m.Source,
+		Channel:     m.Channel,
+		Evidence:    m.Evidence.String,
+		Actor:       m.Actor,
+		OccurredAt:  m.OccurredAt,
+		RecordedAt:  m.RecordedAt,
+	}
+}
+
+// optInRepository implements OptInRepository
+type optInRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewOptInRepository creates a new opt-in repository
+func NewOptInRepository(db *sqlx.DB, logger utils.Logger) OptInRepository {
+	return &optInRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const optInColumns = `id, tenant_id, phone_number, action, source, channel, evidence, actor, occurred_at, recorded_at`
+
+// RecordOptIn inserts an event
+func (r *optInRepository) RecordOptIn(ctx context.Context, event *domain.OptInEvent) error {
+	query := `
+		INSERT INTO contact_opt_ins (tenant_id, phone_number, action, source, channel, evidence, actor, occurred_at, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	event.RecordedAt = time.Now()
+	evidence := sql.NullString{String: event.Evidence, Valid: event.Evidence != ""}
+	return r.db.GetContext(ctx, &event.ID, query, event.TenantID, event.PhoneNumber, event.Action,
+		event.Source, event.Channel, evidence, event.Actor, event.OccurredAt, event.RecordedAt)
+}
+
+// ListOptIns returns a contact's events, most recent first
+func (r *optInRepository) ListOptIns(ctx context.Context, tenantID, phoneNumber string) ([]domain.OptInEvent, error) {
+	query := `SELECT ` + optInColumns + ` FROM contact_opt_ins
+		WHERE tenant_id = $1 AND phone_number = $2
+		ORDER BY occurred_at DESC, id DESC`
+
+	var models []optInModel
+	if err := r.db.SelectContext(ctx, &models, query, tenantID, phoneNumber); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.OptInEvent, 0, len(models))
+	for _, model := range models {
+		events = append(events, model.toDomain())
+	}
+	return events, nil
+}
+
+// LatestOptIn returns a contact's most recent event
+func (r *optInRepository) LatestOptIn(ctx context.Context, tenantID, phoneNumber string) (*domain.OptInEvent, error) {
+	query := `SELECT ` + optInColumns + ` FROM contact_opt_ins
+		WHERE tenant_id = $1 AND phone_number = $2
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 1`
+
+	var model optInModel
+	if err := r.db.GetContext(ctx, &model, query, tenantID, phoneNumber); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	event := model.toDomain()
+	return &event, nil
+}
+
+// EraseOptIns deletes the subject's events. A contact whose latest event is an opt-out keeps
+// that event, with its evidence cleared, so it is still left out of marketing sends and
+// campaign imports after the erasure. Run it before the subject's messages are anonymized,
+// while they still tie a customer ID to its phone numbers.
+func (r *optInRepository) EraseOptIns(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	filter = scopeFilter(ctx, filter)
+	if filter.TenantID == "" {
+		return 0, errors.New("refusing to erase opt-ins without a tenant")
+	}
+
+	q := newQuery(`WITH events AS (
+		SELECT id, action, row_number() OVER (PARTITION BY phone_number ORDER BY occurred_at DESC, id DESC) AS n
+		FROM contact_opt_ins`)
+	q.WhereEq("tenant_id", filter.TenantID)
+	if !whereSubjectPhone(q, "phone_number", filter) {
+		return 0, errors.New("refusing to erase opt-ins without a customer")
+	}
+	optOut := q.Arg(domain.OptInActionOptOut)
+	q.Append(`
+	), suppressions AS (
+		UPDATE contact_opt_ins SET evidence = NULL
+		WHERE id IN (SELECT id FROM events WHERE n = 1 AND action = ` + optOut + `)
+	)
+	DELETE FROM contact_opt_ins
+	WHERE id IN (SELECT id FROM events WHERE n > 1 OR action <> ` + optOut + `)`)
+
+	result, err := r.db.ExecContext(ctx, q.SQL(), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
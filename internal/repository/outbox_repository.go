@@ -0,0 +1,131 @@
+// internal/repository/outbox_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// OutboxRepository stores the queue payloads of messages until they are published. Writes join
+// the unit of work in their context.
+type OutboxRepository interface {
+	AddOutboxMessage(ctx context.Context, msg *domain.OutboxMessage) (int64, error)
+	// ListPendingOutbox returns up to limit unpublished payloads stored before cutoff, oldest
+	// first
+	ListPendingOutbox(ctx context.Context, cutoff time.Time, limit int) ([]domain.OutboxMessage, error)
+	MarkOutboxPublished(ctx context.Context, ids []int64) error
+	// PurgePublishedOutboxBefore deletes up to limit payloads published before cutoff; pending
+	// payloads are kept whatever their age
+	PurgePublishedOutboxBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// outboxMessageModel represents an outbox row in the database
+type outboxMessageModel struct {
+	ID          int64        `db:"id"`
+	MessageID   int64        `db:"message_id"`
+	TenantID    string       `db:"tenant_id"`
+	Payload     []byte       `db:"payload"`
+	CreatedAt   time.Time    `db:"created_at"`
+	PublishedAt sql.NullTime `db:"published_at"`
+}
+
+// outboxRepository implements OutboxRepository
+type outboxRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sqlx.DB, logger utils.Logger) OutboxRepository {
+	return &outboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddOutboxMessage inserts a payload
+func (r *outboxRepository) AddOutboxMessage(ctx context.Context, msg *domain.OutboxMessage) (int64, error) {
+	query := `
+		INSERT INTO message_outbox (message_id, tenant_id, payload, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	if msg.TenantID == "" {
+		msg.TenantID = domain.DefaultTenantID
+	}
+
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, msg.MessageID, msg.TenantID, msg.Payload, msg.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	msg.ID = id
+	return id, nil
+}
+
+// ListPendingOutbox returns the oldest unpublished payloads
+func (r *outboxRepository) ListPendingOutbox(ctx context.Context, cutoff time.Time, limit int) ([]domain.OutboxMessage, error) {
+	query := `
+		SELECT id, message_id, tenant_id, payload, created_at, published_at
+		FROM message_outbox
+		WHERE published_at IS NULL AND created_at < $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	var models []outboxMessageModel
+	if err := conn(ctx, r.db).SelectContext(ctx, &models, query, cutoff, limit); err != nil {
+		return nil, err
+	}
+
+	msgs := make([]domain.OutboxMessage, 0, len(models))
+	for _, model := range models {
+		msgs = append(msgs, domain.OutboxMessage{
+			ID:          model.ID,
+			MessageID:   model.MessageID,
+			TenantID:    model.TenantID,
+			Payload:     model.Payload,
+			CreatedAt:   model.CreatedAt,
+			PublishedAt: model.PublishedAt.Time,
+		})
+	}
+	return msgs, nil
+}
+
+// MarkOutboxPublished records that payloads reached the queue
+func (r *outboxRepository) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE message_outbox SET published_at = $2 WHERE id = ANY($1) AND published_at IS NULL`, pq.Array(ids), time.Now())
+	return err
+}
+
+// PurgePublishedOutboxBefore deletes up to limit payloads published before cutoff, oldest first
+func (r *outboxRepository) PurgePublishedOutboxBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM message_outbox
+		WHERE id IN (
+			SELECT id FROM message_outbox
+			WHERE published_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,106 @@
+// internal/repository/partition_repository.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/pkg/utils"
+)
+
+// messagePartitionPrefix names monthly partitions messages_pYYYY_MM
+const messagePartitionPrefix = "messages_p"
+
+// PartitionRepository manages the monthly range partitions of the messages table
+type PartitionRepository interface {
+	// EnsureMessagePartitions creates the partitions for the month containing from and the following months
+	EnsureMessagePartitions(ctx context.Context, from time.Time, monthsAhead int) error
+	// DropMessagePartitionsBefore drops monthly partitions that end at or before cutoff and
+	// returns the rows they held
+	DropMessagePartitionsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// partitionRepository implements PartitionRepository
+type partitionRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewPartitionRepository creates a new partition repository
+func NewPartitionRepository(db *sqlx.DB, logger utils.Logger) PartitionRepository {
+	return &partitionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// MessagePartitionName returns the partition holding messages created in t's month
+func MessagePartitionName(t time.Time) string {
+	return messagePartitionPrefix + t.UTC().Format("2006_01")
+}
+
+// EnsureMessagePartitions creates missing monthly partitions. Creating a month fails if
+// messages_default already holds rows for it, so months are created ahead of time.
+func (r *partitionRepository) EnsureMessagePartitions(ctx context.Context, from time.Time, monthsAhead int) error {
+	start := monthStart(from)
+	for i := 0; i <= monthsAhead; i++ {
+		lower := start.AddDate(0, i, 0)
+		upper := lower.AddDate(0, 1, 0)
+		query := fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF messages FOR VALUES FROM ('%s') TO ('%s')`,
+			MessagePartitionName(lower), lower.Format("2006-01-02"), upper.Format("2006-01-02"),
+		)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", MessagePartitionName(lower), err)
+		}
+	}
+	return nil
+}
+
+// DropMessagePartitionsBefore drops whole monthly partitions older than the cutoff, which
+// is far cheaper than deleting their rows
+func (r *partitionRepository) DropMessagePartitionsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var partitions []string
+	query := `
+		SELECT child.relname
+		FROM pg_inherits
+		JOIN pg_class parent ON pg_inherits.inhparent = parent.oid
+		JOIN pg_class child ON pg_inherits.inhrelid = child.oid
+		WHERE parent.relname = 'messages' AND child.relname LIKE 'messages\_p%'
+		ORDER BY child.relname
+	`
+	if err := r.db.SelectContext(ctx, &partitions, query); err != nil {
+		return 0, err
+	}
+
+	var dropped int64
+	for _, name := range partitions {
+		month, err := time.Parse("2006_01", name[len(messagePartitionPrefix):])
+		if err != nil {
+			continue
+		}
+		if month.AddDate(0, 1, 0).After(cutoff) {
+			break
+		}
+
+		var rows int64
+		if err := r.db.GetContext(ctx, &rows, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, name)); err != nil {
+			return dropped, err
+		}
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, name)); err != nil {
+			return dropped, fmt.Errorf("failed to drop partition %s: %w", name, err)
+		}
+		r.logger.Info("Dropped message partition", "partition", name, "rows", rows)
+		dropped += rows
+	}
+
+	return dropped, nil
+}
+
+// monthStart returns midnight UTC on the first day of t's month
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
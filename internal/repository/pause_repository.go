@@ -0,0 +1,94 @@
+// internal/repository/pause_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// PauseRepository stores the active send pauses
+type PauseRepository interface {
+	// CreatePause adds a pause, replacing the reason and actor of an existing one in the same scope
+	CreatePause(ctx context.Context, pause *domain.SendPause) error
+	// DeletePause removes a pause and reports whether it existed
+	DeletePause(ctx context.Context, scope, subject string) (bool, error)
+	ListPauses(ctx context.Context) ([]domain.SendPause, error)
+}
+
+// pauseModel represents a send pause in the database
+type pauseModel struct {
+	Scope     string         `db:"scope"`
+	Subject   string         `db:"subject"`
+	Reason    sql.NullString `db:"reason"`
+	Actor     string         `db:"actor"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// pauseRepository implements PauseRepository
+type pauseRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewPauseRepository creates a new send pause repository
+func NewPauseRepository(db *sqlx.DB, logger utils.Logger) PauseRepository {
+	return &pauseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreatePause inserts or refreshes a pause
+func (r *pauseRepository) CreatePause(ctx context.Context, pause *domain.SendPause) error {
+	query := `
+		INSERT INTO send_pauses (scope, subject, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, subject)
+		DO UPDATE SET reason = EXCLUDED.reason, actor = EXCLUDED.actor
+		RETURNING created_at
+	`
+
+	reason := sql.NullString{String: pause.Reason, Valid: pause.Reason != ""}
+	return r.db.GetContext(ctx, &pause.CreatedAt, query, pause.Scope, pause.Subject, reason, pause.Actor, time.Now())
+}
+
+// DeletePause removes a pause
+func (r *pauseRepository) DeletePause(ctx context.Context, scope, subject string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM send_pauses WHERE scope = $1 AND subject = $2`, scope, subject)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ListPauses returns every active pause, oldest first
+func (r *pauseRepository) ListPauses(ctx context.Context) ([]domain.SendPause, error) {
+	query := `SELECT scope, subject, reason, actor, created_at FROM send_pauses ORDER BY created_at ASC`
+
+	var models []pauseModel
+	if err := r.db.SelectContext(ctx, &models, query); err != nil {
+		return nil, err
+	}
+
+	pauses := make([]domain.SendPause, 0, len(models))
+	for _, model := range models {
+		pauses = append(pauses, domain.SendPause{
+			Scope:     model.Scope,
+			Subject:   model.Subject,
+			Reason:    model.Reason.String,
+			Actor:     model.Actor,
+			CreatedAt: model.CreatedAt,
+		})
+	}
+	return pauses, nil
+}
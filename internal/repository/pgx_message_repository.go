@@ -0,0 +1,175 @@
+// internal/repository/pgx_message_repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// PgxPoolConfig sizes a pgx pool
+type PgxPoolConfig struct {
+	MaxConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	// StatementCacheSize is the number of prepared statements cached per connection
+	StatementCacheSize int
+}
+
+// ConnectPgx opens a pgx pool whose connections use the credentials in the DSN current at dial
+// time, so rotated secrets are picked up like Connect does, and verifies it with a ping.
+// Queries are prepared once per connection and use the binary protocol.
+func ConnectPgx(ctx context.Context, dsn func() string, cfg PgxPoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn())
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheSize
+	poolConfig.BeforeConnect = func(_ context.Context, connConfig *pgx.ConnConfig) error {
+		current, err := pgx.ParseConfig(dsn())
+		if err != nil {
+			return err
+		}
+		connConfig.User, connConfig.Password = current.User, current.Password
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// pgxMessageRepository runs the per-message hot paths on a pgx pool
+type pgxMessageRepository struct {
+	MessageRepository
+	pool   *pgxpool.Pool
+	logger utils.Logger
+}
+
+// NewPgxMessageRepository wraps repo so inserts, status updates and the lookups made for every
+// send and status callback run on pool with cached prepared statements. Everything else,
+// including reads that may go to the replica and calls made in a unit of work, is left to repo.
+func NewPgxMessageRepository(repo MessageRepository, pool *pgxpool.Pool, logger utils.Logger) MessageRepository {
+	return &pgxMessageRepository{
+		MessageRepository: repo,
+		pool:              pool,
+		logger:            logger,
+	}
+}
+
+// CreateMessage creates a new message
+func (r *pgxMessageRepository) CreateMessage(ctx context.Context, message *domain.Message) (int64, error) {
+	if InTx(ctx) {
+		return r.MessageRepository.CreateMessage(ctx, message)
+	}
+	model, err := domainToModel(message)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO messages (
+			phone_number, template_id, parameters,
+			order_id, customer_id, status,
+			error_code, error_message, external_id, tenant_id, recipient_timezone, expires_at, attempt, retry_of, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		) RETURNING id
+	`
+
+	var id int64
+	err = r.pool.QueryRow(ctx, query,
+		model.PhoneNumber, model.TemplateID, model.Parameters,
+		model.OrderID, model.CustomerID, model.Status,
+		model.ErrorCode, model.ErrorMessage, model.ExternalID, model.TenantID, model.RecipientTimezone, model.ExpiresAt,
+		model.Attempt, model.RetryOf, model.CreatedAt, model.UpdatedAt,
+	).Scan(&id)
+	return id, err
+}
+
+// GetMessageByID reads the message on the pool when ctx requires the primary, as the consumer
+// does for every send; other reads keep their replica routing
+func (r *pgxMessageRepository) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
+	if forced, _ := ctx.Value(primaryKey{}).(bool); !forced || InTx(ctx) {
+		return r.MessageRepository.GetMessageByID(ctx, id)
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = $1 AND ($2 = '' OR tenant_id = $2)
+	`
+
+	scope, _ := domain.TenantScope(ctx)
+	var model MessageModel
+	err := r.pool.QueryRow(ctx, query, id, scope).Scan(
+		&model.ID, &model.PhoneNumber, &model.TemplateID, &model.Parameters,
+		&model.OrderID, &model.CustomerID, &model.Status,
+		&model.ErrorCode, &model.ErrorMessage, &model.ExternalID, &model.TenantID, &model.RecipientTimezone, &model.ExpiresAt,
+		&model.Attempt, &model.RetryOf, &model.ContentSnapshot, &model.ArchiveKey, &model.DeletedAt, &model.ErasedAt, &model.CreatedAt, &model.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return nil, err
+	}
+	return modelToDomainMessage(&model)
+}
+
+// GetMessageIDByExternalID resolves a tenant's external ID to the message ID without loading the message
+func (r *pgxMessageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error) {
+	if InTx(ctx) {
+		return r.MessageRepository.GetMessageIDByExternalID(ctx, tenantID, externalID)
+	}
+	query := `SELECT id FROM messages WHERE tenant_id = $1 AND external_id = $2`
+
+	var id int64
+	if err := r.pool.QueryRow(ctx, query, tenantID, externalID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.NewError(domain.ErrNotFound, "message not found")
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateMessageStatus updates the status of a message. Unlike the sqlx version, which builds
+// the statement from the fields given, it is a single statement so one prepared plan serves
+// every update; empty fields keep their stored values and an unscoped context matches any tenant.
+func (r *pgxMessageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error {
+	if InTx(ctx) {
+		return r.MessageRepository.UpdateMessageStatus(ctx, id, status, errorCode, errorMessage, externalID)
+	}
+	query := `
+		UPDATE messages
+		SET status = $1, updated_at = $2,
+			sent_at = CASE WHEN $1 = 'sent' THEN COALESCE(sent_at, $2) ELSE sent_at END,
+			delivered_at = CASE WHEN $1 = 'delivered' THEN COALESCE(delivered_at, $2) ELSE delivered_at END,
+			read_at = CASE WHEN $1 = 'read' THEN COALESCE(read_at, $2) ELSE read_at END,
+			error_code = COALESCE(NULLIF($3, ''), error_code),
+			error_message = COALESCE(NULLIF($4, ''), error_message),
+			external_id = COALESCE(NULLIF($5, ''), external_id)
+		WHERE id = $6 AND ($7 = '' OR tenant_id = $7)
+	`
+
+	scope, _ := domain.TenantScope(ctx)
+	_, err := r.pool.Exec(ctx, query, status, time.Now(), errorCode, errorMessage, externalID, id, scope)
+	return err
+}
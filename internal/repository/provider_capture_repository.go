@@ -0,0 +1,179 @@
+// internal/repository/provider_capture_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// ProviderCaptureRepository stores the provider requests and responses captured in debug mode
+type ProviderCaptureRepository interface {
+	SaveProviderCapture(ctx context.Context, capture *domain.ProviderCapture) error
+	// ListProviderCaptures returns a tenant's captures of a message, oldest first
+	ListProviderCaptures(ctx context.Context, tenantID string, messageID int64) ([]domain.ProviderCapture, error)
+	// PurgeProviderCapturesBefore deletes up to limit captures made before cutoff
+	PurgeProviderCapturesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	// EraseProviderCaptures deletes the captures of the messages matching the filter
+	EraseProviderCaptures(ctx context.Context, filter domain.MessageFilter) (int64, error)
+}
+
+// providerCaptureModel represents a provider capture in the database
+type providerCaptureModel struct {
+	ID              int64          `db:"id"`
+	MessageID       int64          `db:"message_id"`
+	TenantID        string         `db:"tenant_id"`
+	Method          string         `db:"method"`
+	URL             string         `db:"url"`
+	RequestHeaders  []byte         `db:"request_headers"`
+	RequestBody     sql.NullString `db:"request_body"`
+	StatusCode      sql.NullInt64  `db:"status_code"`
+	ResponseHeaders []byte         `db:"response_headers"`
+	ResponseBody    sql.NullString `db:"response_body"`
+	Error           sql.NullString `db:"error"`
+	DurationMs      int64          `db:"duration_ms"`
+	CreatedAt       time.Time      `db:"created_at"`
+}
+
+// providerCaptureRepository implements ProviderCaptureRepository
+type providerCaptureRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewProviderCaptureRepository creates a new provider capture repository
+func NewProviderCaptureRepository(db *sqlx.DB, logger utils.Logger) ProviderCaptureRepository {
+	return &providerCaptureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveProviderCapture inserts a capture
+func (r *providerCaptureRepository) SaveProviderCapture(ctx context.Context, capture *domain.ProviderCapture) error {
+	query := `
+		INSERT INTO provider_captures (
+			message_id, tenant_id, method, url, request_headers, request_body,
+			status_code, response_headers, response_body, error, duration_ms, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		) RETURNING id
+	`
+
+	if capture.CreatedAt.IsZero() {
+		capture.CreatedAt = time.Now()
+	}
+
+	nullable := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	return r.db.GetContext(ctx, &capture.ID, query,
+		capture.MessageID,
+		capture.TenantID,
+		capture.Method,
+		capture.URL,
+		captureHeaders(capture.RequestHeaders),
+		nullable(capture.RequestBody),
+		sql.NullInt64{Int64: int64(capture.StatusCode), Valid: capture.StatusCode != 0},
+		captureHeaders(capture.ResponseHeaders),
+		nullable(capture.ResponseBody),
+		nullable(capture.Error),
+		capture.Duration.Milliseconds(),
+		capture.CreatedAt,
+	)
+}
+
+// ListProviderCaptures returns the captures of a message, oldest first
+func (r *providerCaptureRepository) ListProviderCaptures(ctx context.Context, tenantID string, messageID int64) ([]domain.ProviderCapture, error) {
+	query := `
+		SELECT id, message_id, tenant_id, method, url, request_headers, request_body,
+			status_code, response_headers, response_body, error, duration_ms, created_at
+		FROM provider_captures
+		WHERE message_id = $1 AND tenant_id = $2
+		ORDER BY created_at, id
+	`
+
+	var models []providerCaptureModel
+	if err := r.db.SelectContext(ctx, &models, query, messageID, tenantID); err != nil {
+		return nil, err
+	}
+
+	captures := make([]domain.ProviderCapture, 0, len(models))
+	for _, model := range models {
+		capture := domain.ProviderCapture{
+			ID:           model.ID,
+			MessageID:    model.MessageID,
+			TenantID:     model.TenantID,
+			Method:       model.Method,
+			URL:          model.URL,
+			RequestBody:  model.RequestBody.String,
+			StatusCode:   int(model.StatusCode.Int64),
+			ResponseBody: model.ResponseBody.String,
+			Error:        model.Error.String,
+			Duration:     time.Duration(model.DurationMs) * time.Millisecond,
+			CreatedAt:    model.CreatedAt,
+		}
+		if len(model.RequestHeaders) > 0 {
+			if err := json.Unmarshal(model.RequestHeaders, &capture.RequestHeaders); err != nil {
+				r.logger.Error("Failed to unmarshal captured request headers", "error", err, "capture_id", model.ID)
+			}
+		}
+		if len(model.ResponseHeaders) > 0 {
+			if err := json.Unmarshal(model.ResponseHeaders, &capture.ResponseHeaders); err != nil {
+				r.logger.Error("Failed to unmarshal captured response headers", "error", err, "capture_id", model.ID)
+			}
+		}
+		captures = append(captures, capture)
+	}
+	return captures, nil
+}
+
+// PurgeProviderCapturesBefore deletes up to limit captures made before cutoff, oldest first
+func (r *providerCaptureRepository) PurgeProviderCapturesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM provider_captures
+		WHERE id IN (
+			SELECT id FROM provider_captures
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// EraseProviderCaptures deletes the captures of the messages matching the filter. Captured
+// bodies hold the recipient's phone number and parameters, so they are deleted whether the
+// messages are anonymized or deleted; run it before the messages are anonymized, while the
+// filter still matches them.
+func (r *providerCaptureRepository) EraseProviderCaptures(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	q := newQuery("SELECT id FROM messages")
+	whereMessageFilter(q, scopeFilter(ctx, filter))
+	if !q.Filtered() {
+		return 0, errors.New("refusing to erase provider captures without a filter")
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM provider_captures WHERE message_id IN ("+q.SQL()+")", q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// captureHeaders converts captured headers to a JSONB parameter, NULL when there are none
+func captureHeaders(headers map[string]string) interface{} {
+	if len(headers) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(headers)
+	return string(data)
+}
@@ -0,0 +1,85 @@
+// internal/repository/query.go
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query builds a Postgres statement whose placeholders are numbered as values are bound, so
+// conditions can be added in any order without counting arguments by hand:
+//
+//	q := newQuery("SELECT id FROM messages")
+//	q.WhereEq("tenant_id", tenantID)
+//	q.Append(" LIMIT " + q.Arg(limit))
+//	db.SelectContext(ctx, &ids, q.SQL(), q.Args()...)
+type query struct {
+	sql        strings.Builder
+	args       []interface{}
+	conditions int
+}
+
+// newQuery starts a statement with sql, which may be empty
+func newQuery(sql string) *query {
+	q := &query{}
+	q.sql.WriteString(sql)
+	return q
+}
+
+// Arg binds value and returns its placeholder, e.g. "$3". Binding the same value twice gives
+// two placeholders; reuse the returned one instead.
+func (q *query) Arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Append adds SQL text as is
+func (q *query) Append(sql string) *query {
+	q.sql.WriteString(sql)
+	return q
+}
+
+// Where adds a condition, starting the WHERE clause on the first one and joining later ones
+// with AND. Values in condition must be bound with Arg.
+func (q *query) Where(condition string) *query {
+	if q.conditions == 0 {
+		q.sql.WriteString(" WHERE ")
+	} else {
+		q.sql.WriteString(" AND ")
+	}
+	q.sql.WriteString(condition)
+	q.conditions++
+	return q
+}
+
+// WhereEq adds "column = value" unless value is empty, the convention for an unset filter
+func (q *query) WhereEq(column, value string) *query {
+	if value == "" {
+		return q
+	}
+	return q.Where(column + " = " + q.Arg(value))
+}
+
+// WhereTime adds "column op at", e.g. op ">=" for a range start, unless at is zero
+func (q *query) WhereTime(column, op string, at time.Time) *query {
+	if at.IsZero() {
+		return q
+	}
+	return q.Where(column + " " + op + " " + q.Arg(at))
+}
+
+// Filtered reports whether any condition was added
+func (q *query) Filtered() bool {
+	return q.conditions > 0
+}
+
+// SQL returns the statement
+func (q *query) SQL() string {
+	return q.sql.String()
+}
+
+// Args returns the bound values in placeholder order
+func (q *query) Args() []interface{} {
+	return q.args
+}
@@ -0,0 +1,85 @@
+// internal/repository/quota_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/pkg/utils"
+)
+
+// QuotaRepository tracks how many messages each quota subject used per period
+type QuotaRepository interface {
+	// ConsumeQuota counts one message if fewer than limit were used in the period and reports
+	// whether it did, along with the usage afterwards
+	ConsumeQuota(ctx context.Context, scope, subject string, period time.Time, limit int64) (int64, bool, error)
+	// ReleaseQuota gives back one message counted by ConsumeQuota
+	ReleaseQuota(ctx context.Context, scope, subject string, period time.Time) error
+	// GetQuotaUsage returns the messages used in the period
+	GetQuotaUsage(ctx context.Context, scope, subject string, period time.Time) (int64, error)
+}
+
+// quotaRepository implements QuotaRepository
+type quotaRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *sqlx.DB, logger utils.Logger) QuotaRepository {
+	return &quotaRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ConsumeQuota increments usage in a single statement so concurrent sends can't overshoot the limit
+func (r *quotaRepository) ConsumeQuota(ctx context.Context, scope, subject string, period time.Time, limit int64) (int64, bool, error) {
+	query := `
+		INSERT INTO quota_usage (scope, subject, period_start, used, updated_at)
+		VALUES ($1, $2, $3, 1, $5)
+		ON CONFLICT (scope, subject, period_start)
+		DO UPDATE SET used = quota_usage.used + 1, updated_at = $5
+		WHERE quota_usage.used < $4
+		RETURNING used
+	`
+
+	var used int64
+	err := r.db.GetContext(ctx, &used, query, scope, subject, period, limit, time.Now())
+	if err == sql.ErrNoRows {
+		used, err = r.GetQuotaUsage(ctx, scope, subject, period)
+		return used, false, err
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return used, true, nil
+}
+
+// ReleaseQuota decrements usage, never below zero
+func (r *quotaRepository) ReleaseQuota(ctx context.Context, scope, subject string, period time.Time) error {
+	query := `
+		UPDATE quota_usage
+		SET used = used - 1, updated_at = $4
+		WHERE scope = $1 AND subject = $2 AND period_start = $3 AND used > 0
+	`
+
+	_, err := r.db.ExecContext(ctx, query, scope, subject, period, time.Now())
+	return err
+}
+
+// GetQuotaUsage returns the messages used in the period, zero if none were sent
+func (r *quotaRepository) GetQuotaUsage(ctx context.Context, scope, subject string, period time.Time) (int64, error) {
+	query := `SELECT used FROM quota_usage WHERE scope = $1 AND subject = $2 AND period_start = $3`
+
+	var used int64
+	if err := r.db.GetContext(ctx, &used, query, scope, subject, period); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return used, nil
+}
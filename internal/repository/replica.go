@@ -0,0 +1,121 @@
+// internal/repository/replica.go
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// replicaLagSeconds reports the replication lag measured by the read router
+var replicaLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "whatsapp_db_replica_lag_seconds",
+	Help: "Replication lag of the read replica when last checked.",
+})
+
+// primaryKey marks contexts whose reads must see the primary's latest writes
+type primaryKey struct{}
+
+// WithPrimary routes all reads made with the returned context to the primary, for
+// read-modify-write paths that cannot tolerate replica lag
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ReadRouter chooses the pool for read-only queries: the replica while its replication lag
+// is within bounds, otherwise the primary
+type ReadRouter struct {
+	primary *sqlx.DB
+	replica *sqlx.DB
+	maxLag  time.Duration
+	logger  utils.Logger
+
+	// healthy is false until the first lag check passes
+	healthy atomic.Bool
+}
+
+// NewReadRouter creates a router; a nil replica sends every read to the primary
+func NewReadRouter(primary, replica *sqlx.DB, maxLag time.Duration, logger utils.Logger) *ReadRouter {
+	return &ReadRouter{
+		primary: primary,
+		replica: replica,
+		maxLag:  maxLag,
+		logger:  logger,
+	}
+}
+
+// Reader returns the pool to read from for ctx
+func (r *ReadRouter) Reader(ctx context.Context) *sqlx.DB {
+	if r == nil {
+		return nil
+	}
+	if r.replica == nil || !r.healthy.Load() {
+		return r.primary
+	}
+	if forced, _ := ctx.Value(primaryKey{}).(bool); forced {
+		return r.primary
+	}
+	return r.replica
+}
+
+// CheckLag measures replication lag and updates whether the replica may serve reads. A replica
+// that has replayed everything it received reports zero lag even when the primary is idle.
+func (r *ReadRouter) CheckLag(ctx context.Context) (time.Duration, error) {
+	query := `
+		SELECT CASE
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0)
+		END
+	`
+	var seconds float64
+	if err := r.replica.GetContext(ctx, &seconds, query); err != nil {
+		r.setHealthy(false, "error", err)
+		return 0, err
+	}
+
+	lag := time.Duration(seconds * float64(time.Second))
+	replicaLagSeconds.Set(seconds)
+	if lag > r.maxLag {
+		r.setHealthy(false, "lag", lag, "max_lag", r.maxLag)
+	} else {
+		r.setHealthy(true, "lag", lag)
+	}
+	return lag, nil
+}
+
+// Run checks replication lag every interval until ctx is done
+func (r *ReadRouter) Run(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckLag(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setHealthy records a routing change, logging only transitions
+func (r *ReadRouter) setHealthy(healthy bool, keysAndValues ...interface{}) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		r.logger.Info("Routing reads to replica", keysAndValues...)
+	} else {
+		r.logger.Warn("Routing reads to primary", keysAndValues...)
+	}
+}
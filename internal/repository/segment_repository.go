@@ -0,0 +1,260 @@
+// internal/repository/segment_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// SegmentRepository stores contacts and the segments campaigns select their audience from
+type SegmentRepository interface {
+	// UpsertContact creates or replaces a contact's attributes, tags and last activity
+	UpsertContact(ctx context.Context, contact *domain.Contact) error
+	// CreateSegment stores a segment along with the phone numbers of a list segment
+	CreateSegment(ctx context.Context, segment *domain.Segment, phoneNumbers []string) error
+	GetSegment(ctx context.Context, tenantID string, id int64) (*domain.Segment, error)
+	// ListSegments returns a tenant's segments, newest first
+	ListSegments(ctx context.Context, tenantID string) ([]domain.Segment, error)
+	// PreviewSegment returns how many contacts the segment selects at now and up to sampleSize
+	// of their phone numbers
+	PreviewSegment(ctx context.Context, segment domain.Segment, now time.Time, sampleSize int) (int64, []string, error)
+	// EraseContacts deletes the contacts of the customer or phone number the filter names and
+	// removes them from list segments
+	EraseContacts(ctx context.Context, filter domain.MessageFilter) (int64, error)
+}
+
+// segmentModel represents a segment in the database
+type segmentModel struct {
+	ID        int64          `db:"id"`
+	TenantID  string         `db:"tenant_id"`
+	Name      string         `db:"name"`
+	Kind      string         `db:"kind"`
+	Filter    sql.NullString `db:"filter"`
+	ListSize  int64          `db:"list_size"`
+	Actor     string         `db:"actor"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// segmentColumns selects a segment with the size of its list
+const segmentColumns = `s.id, s.tenant_id, s.name, s.kind, s.filter, s.actor, s.created_at,
+	(SELECT count(*) FROM segment_members m WHERE m.segment_id = s.id) AS list_size`
+
+// segmentRepository implements SegmentRepository
+type segmentRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewSegmentRepository creates a new segment repository
+func NewSegmentRepository(db *sqlx.DB, logger utils.Logger) SegmentRepository {
+	return &segmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// UpsertContact inserts or replaces a contact
+func (r *segmentRepository) UpsertContact(ctx context.Context, contact *domain.Contact) error {
+	query := `
+		INSERT INTO contacts (tenant_id, phone_number, attributes, tags, last_activity_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (tenant_id, phone_number)
+		DO UPDATE SET
+			attributes = EXCLUDED.attributes,
+			tags = EXCLUDED.tags,
+			last_activity_at = GREATEST(contacts.last_activity_at, EXCLUDED.last_activity_at),
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	attributes, err := json.Marshal(contact.Attributes)
+	if err != nil {
+		return err
+	}
+	if contact.Attributes == nil {
+		attributes = []byte("{}")
+	}
+	lastActivity := sql.NullTime{Time: contact.LastActivityAt, Valid: !contact.LastActivityAt.IsZero()}
+	return r.db.GetContext(ctx, &contact.UpdatedAt, query, contact.TenantID, contact.PhoneNumber, string(attributes),
+		pq.Array(contact.Tags), lastActivity)
+}
+
+// CreateSegment inserts the segment and its list members in one statement
+func (r *segmentRepository) CreateSegment(ctx context.Context, segment *domain.Segment, phoneNumbers []string) error {
+	query := `
+		WITH segment AS (
+			INSERT INTO segments (tenant_id, name, kind, filter, actor, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		), members AS (
+			INSERT INTO segment_members (segment_id, phone_number)
+			SELECT segment.id, number FROM segment, unnest($7::text[]) AS number
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		SELECT segment.id, (SELECT count(*) FROM members) AS list_size FROM segment
+	`
+
+	var filter sql.NullString
+	if segment.Kind == domain.SegmentKindFilter {
+		encoded, err := json.Marshal(segment.Filter)
+		if err != nil {
+			return err
+		}
+		filter = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	segment.CreatedAt = time.Now()
+	var created struct {
+		ID       int64 `db:"id"`
+		ListSize int64 `db:"list_size"`
+	}
+	if err := r.db.GetContext(ctx, &created, query, segment.TenantID, segment.Name, segment.Kind, filter,
+		segment.Actor, segment.CreatedAt, pq.Array(phoneNumbers)); err != nil {
+		return err
+	}
+	segment.ID, segment.ListSize = created.ID, created.ListSize
+	return nil
+}
+
+// GetSegment returns a segment of the tenant
+func (r *segmentRepository) GetSegment(ctx context.Context, tenantID string, id int64) (*domain.Segment, error) {
+	query := `SELECT ` + segmentColumns + ` FROM segments s WHERE s.id = $1 AND s.tenant_id = $2`
+
+	var model segmentModel
+	if err := r.db.GetContext(ctx, &model, query, id, tenantID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewError(domain.ErrNotFound, "segment not found")
+		}
+		return nil, err
+	}
+	return r.toDomain(model), nil
+}
+
+// ListSegments returns a tenant's segments, newest first
+func (r *segmentRepository) ListSegments(ctx context.Context, tenantID string) ([]domain.Segment, error) {
+	query := `SELECT ` + segmentColumns + ` FROM segments s WHERE s.tenant_id = $1 ORDER BY s.created_at DESC, s.id DESC`
+
+	var models []segmentModel
+	if err := r.db.SelectContext(ctx, &models, query, tenantID); err != nil {
+		return nil, err
+	}
+
+	segments := make([]domain.Segment, 0, len(models))
+	for _, model := range models {
+		segments = append(segments, *r.toDomain(model))
+	}
+	return segments, nil
+}
+
+// PreviewSegment counts and samples the segment's audience
+func (r *segmentRepository) PreviewSegment(ctx context.Context, segment domain.Segment, now time.Time, sampleSize int) (int64, []string, error) {
+	q := newQuery("SELECT count(*) FROM (")
+	appendSegmentAudience(q, segment, now)
+	q.Append(") audience")
+
+	var size int64
+	if err := r.db.GetContext(ctx, &size, q.SQL(), q.Args()...); err != nil {
+		return 0, nil, err
+	}
+
+	sample := []string{}
+	if sampleSize > 0 && size > 0 {
+		q = newQuery("SELECT phone_number FROM (")
+		appendSegmentAudience(q, segment, now)
+		q.Append(") audience ORDER BY phone_number LIMIT " + q.Arg(sampleSize))
+		if err := r.db.SelectContext(ctx, &sample, q.SQL(), q.Args()...); err != nil {
+			return 0, nil, err
+		}
+	}
+	return size, sample, nil
+}
+
+// toDomain converts a segment model, decoding its filter
+func (r *segmentRepository) toDomain(model segmentModel) *domain.Segment {
+	segment := &domain.Segment{
+		ID:        model.ID,
+		TenantID:  model.TenantID,
+		Name:      model.Name,
+		Kind:      model.Kind,
+		ListSize:  model.ListSize,
+		Actor:     model.Actor,
+		CreatedAt: model.CreatedAt,
+	}
+	if model.Filter.Valid {
+		if err := json.Unmarshal([]byte(model.Filter.String), &segment.Filter); err != nil {
+			r.logger.Error("Failed to unmarshal segment filter", "error", err, "segment_id", model.ID)
+		}
+	}
+	return segment
+}
+
+// appendSegmentAudience appends a query selecting the phone_number of each contact in the
+// segment at now. It adds the first WHERE conditions of q.
+func appendSegmentAudience(q *query, segment domain.Segment, now time.Time) {
+	if segment.Kind == domain.SegmentKindList {
+		q.Append("SELECT phone_number FROM segment_members")
+		q.Where("segment_id = " + q.Arg(segment.ID))
+		return
+	}
+
+	// Inbound messages count as activity, along with the activity the tenant reports
+	const activity = "GREATEST(c.last_activity_at, cv.last_inbound_at)"
+	q.Append(`SELECT c.phone_number FROM contacts c
+		LEFT JOIN conversations cv ON cv.tenant_id = c.tenant_id AND cv.phone_number = c.phone_number`)
+	q.Where("c.tenant_id = " + q.Arg(segment.TenantID))
+
+	filter := segment.Filter
+	if len(filter.Attributes) > 0 {
+		attributes, _ := json.Marshal(filter.Attributes)
+		q.Where("c.attributes @> " + q.Arg(string(attributes)) + "::jsonb")
+	}
+	if len(filter.Tags) > 0 {
+		q.Where("c.tags @> " + q.Arg(pq.Array(filter.Tags)) + "::text[]")
+	}
+	if filter.ActiveWithin > 0 {
+		q.Where(activity + " >= " + q.Arg(now.Add(-filter.ActiveWithin)))
+	}
+	if filter.InactiveFor > 0 {
+		q.Where("(" + activity + " IS NULL OR " + activity + " < " + q.Arg(now.Add(-filter.InactiveFor)) + ")")
+	}
+}
+
+// EraseContacts deletes the subject's contacts and list segment memberships. Run it before
+// the subject's messages are anonymized, while they still tie a customer ID to its phone numbers.
+func (r *segmentRepository) EraseContacts(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	filter = scopeFilter(ctx, filter)
+	if filter.TenantID == "" {
+		return 0, errors.New("refusing to erase contacts without a tenant")
+	}
+
+	members := newQuery("DELETE FROM segment_members")
+	members.Where("segment_id IN (SELECT id FROM segments WHERE tenant_id = " + members.Arg(filter.TenantID) + ")")
+	contacts := newQuery("DELETE FROM contacts")
+	contacts.WhereEq("tenant_id", filter.TenantID)
+	if !whereSubjectPhone(members, "phone_number", filter) || !whereSubjectPhone(contacts, "phone_number", filter) {
+		return 0, errors.New("refusing to erase contacts without a customer")
+	}
+
+	var total int64
+	for _, q := range []*query{members, contacts} {
+		result, err := r.db.ExecContext(ctx, q.SQL(), q.Args()...)
+		if err != nil {
+			return total, err
+		}
+		erased, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += erased
+	}
+	return total, nil
+}
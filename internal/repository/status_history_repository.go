@@ -0,0 +1,115 @@
+// internal/repository/status_history_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// StatusHistoryRepository stores the statuses messages went through. Writes join the unit of
+// work in their context.
+type StatusHistoryRepository interface {
+	AddStatusHistory(ctx context.Context, entry *domain.StatusHistoryEntry) error
+	// ListStatusHistory returns a message's statuses, oldest first
+	ListStatusHistory(ctx context.Context, messageID int64) ([]domain.StatusHistoryEntry, error)
+	// PurgeStatusHistoryBefore deletes up to limit entries recorded before cutoff
+	PurgeStatusHistoryBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// statusHistoryModel represents a status history entry in the database
+type statusHistoryModel struct {
+	ID           int64          `db:"id"`
+	MessageID    int64          `db:"message_id"`
+	Status       string         `db:"status"`
+	ErrorCode    sql.NullString `db:"error_code"`
+	ErrorMessage sql.NullString `db:"error_message"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+// statusHistoryRepository implements StatusHistoryRepository
+type statusHistoryRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewStatusHistoryRepository creates a new status history repository
+func NewStatusHistoryRepository(db *sqlx.DB, logger utils.Logger) StatusHistoryRepository {
+	return &statusHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddStatusHistory inserts an entry
+func (r *statusHistoryRepository) AddStatusHistory(ctx context.Context, entry *domain.StatusHistoryEntry) error {
+	query := `
+		INSERT INTO message_status_history (message_id, status, error_code, error_message, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	nullable := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.MessageID,
+		entry.Status,
+		nullable(entry.ErrorCode),
+		nullable(entry.ErrorMessage),
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// ListStatusHistory returns a message's statuses
+func (r *statusHistoryRepository) ListStatusHistory(ctx context.Context, messageID int64) ([]domain.StatusHistoryEntry, error) {
+	query := `
+		SELECT id, message_id, status, error_code, error_message, created_at
+		FROM message_status_history
+		WHERE message_id = $1
+		ORDER BY id
+	`
+
+	var models []statusHistoryModel
+	if err := conn(ctx, r.db).SelectContext(ctx, &models, query, messageID); err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.StatusHistoryEntry, 0, len(models))
+	for _, model := range models {
+		entries = append(entries, domain.StatusHistoryEntry{
+			ID:           model.ID,
+			MessageID:    model.MessageID,
+			Status:       model.Status,
+			ErrorCode:    model.ErrorCode.String,
+			ErrorMessage: model.ErrorMessage.String,
+			CreatedAt:    model.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// PurgeStatusHistoryBefore deletes up to limit entries recorded before cutoff, oldest first
+func (r *statusHistoryRepository) PurgeStatusHistoryBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM message_status_history
+		WHERE id IN (
+			SELECT id FROM message_status_history
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
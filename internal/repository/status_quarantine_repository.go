@@ -0,0 +1,156 @@
+// internal/repository/status_quarantine_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// StatusQuarantineRepository stores the statuses for external IDs no message had
+type StatusQuarantineRepository interface {
+	// QuarantineStatus stores a status, ignoring one already quarantined with its dedupe key
+	QuarantineStatus(ctx context.Context, status *domain.QuarantinedStatus) error
+	// ListQuarantinedStatuses returns up to limit statuses, oldest first
+	ListQuarantinedStatuses(ctx context.Context, limit int) ([]domain.QuarantinedStatus, error)
+	DeleteQuarantinedStatuses(ctx context.Context, ids []int64) error
+	// ExpireQuarantinedStatuses deletes the statuses quarantined before cutoff, returning how
+	// many there were by provider
+	ExpireQuarantinedStatuses(ctx context.Context, cutoff time.Time) (map[string]int64, error)
+}
+
+// quarantinedStatusModel represents a quarantined status in the database
+type quarantinedStatusModel struct {
+	ID           int64          `db:"id"`
+	Provider     string         `db:"provider"`
+	TenantID     string         `db:"tenant_id"`
+	ExternalID   string         `db:"external_id"`
+	Status       string         `db:"status"`
+	ErrorCode    sql.NullString `db:"error_code"`
+	ErrorMessage sql.NullString `db:"error_message"`
+	PhoneNumber  sql.NullString `db:"phone_number"`
+	Timestamp    sql.NullString `db:"timestamp"`
+	StatusAt     sql.NullTime   `db:"status_at"`
+	DedupeKey    string         `db:"dedupe_key"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+// statusQuarantineRepository implements StatusQuarantineRepository
+type statusQuarantineRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewStatusQuarantineRepository creates a new status quarantine repository
+func NewStatusQuarantineRepository(db *sqlx.DB, logger utils.Logger) StatusQuarantineRepository {
+	return &statusQuarantineRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// QuarantineStatus inserts a status unless it is already quarantined
+func (r *statusQuarantineRepository) QuarantineStatus(ctx context.Context, status *domain.QuarantinedStatus) error {
+	query := `
+		INSERT INTO quarantined_statuses (
+			provider, tenant_id, external_id, status, error_code, error_message,
+			phone_number, timestamp, status_at, dedupe_key, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+		ON CONFLICT (provider, tenant_id, dedupe_key) DO NOTHING
+	`
+
+	if status.CreatedAt.IsZero() {
+		status.CreatedAt = time.Now()
+	}
+
+	nullable := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	_, err := r.db.ExecContext(ctx, query,
+		status.Provider,
+		status.TenantID,
+		status.ExternalID,
+		status.Status,
+		nullable(status.ErrorCode),
+		nullable(status.ErrorMessage),
+		nullable(status.PhoneNumber),
+		nullable(status.Timestamp),
+		sql.NullTime{Time: status.At, Valid: !status.At.IsZero()},
+		status.DedupeKey,
+		status.CreatedAt,
+	)
+	return err
+}
+
+// ListQuarantinedStatuses returns the oldest quarantined statuses
+func (r *statusQuarantineRepository) ListQuarantinedStatuses(ctx context.Context, limit int) ([]domain.QuarantinedStatus, error) {
+	query := `
+		SELECT id, provider, tenant_id, external_id, status, error_code, error_message,
+			phone_number, timestamp, status_at, dedupe_key, created_at
+		FROM quarantined_statuses
+		ORDER BY id
+		LIMIT $1
+	`
+
+	var models []quarantinedStatusModel
+	if err := r.db.SelectContext(ctx, &models, query, limit); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]domain.QuarantinedStatus, 0, len(models))
+	for _, model := range models {
+		statuses = append(statuses, domain.QuarantinedStatus{
+			ID:           model.ID,
+			Provider:     model.Provider,
+			TenantID:     model.TenantID,
+			ExternalID:   model.ExternalID,
+			Status:       model.Status,
+			ErrorCode:    model.ErrorCode.String,
+			ErrorMessage: model.ErrorMessage.String,
+			PhoneNumber:  model.PhoneNumber.String,
+			Timestamp:    model.Timestamp.String,
+			At:           model.StatusAt.Time,
+			DedupeKey:    model.DedupeKey,
+			CreatedAt:    model.CreatedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// DeleteQuarantinedStatuses removes statuses once they are matched
+func (r *statusQuarantineRepository) DeleteQuarantinedStatuses(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM quarantined_statuses WHERE id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+// ExpireQuarantinedStatuses deletes the statuses quarantined before cutoff
+func (r *statusQuarantineRepository) ExpireQuarantinedStatuses(ctx context.Context, cutoff time.Time) (map[string]int64, error) {
+	query := `
+		WITH expired AS (
+			DELETE FROM quarantined_statuses WHERE created_at < $1 RETURNING provider
+		)
+		SELECT provider, count(*) AS count FROM expired GROUP BY provider
+	`
+
+	var rows []struct {
+		Provider string `db:"provider"`
+		Count    int64  `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, cutoff); err != nil {
+		return nil, err
+	}
+
+	expired := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		expired[row.Provider] = row.Count
+	}
+	return expired, nil
+}
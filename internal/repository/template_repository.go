@@ -0,0 +1,92 @@
+// internal/repository/template_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// TemplateRepository stores the templates disabled by the kill switch
+type TemplateRepository interface {
+	// DisableTemplate switches a template off, replacing the reason and actor if it already is
+	DisableTemplate(ctx context.Context, template *domain.DisabledTemplate) error
+	// EnableTemplate switches a template back on and reports whether it was disabled
+	EnableTemplate(ctx context.Context, templateID string) (bool, error)
+	ListDisabledTemplates(ctx context.Context) ([]domain.DisabledTemplate, error)
+}
+
+// disabledTemplateModel represents a disabled template in the database
+type disabledTemplateModel struct {
+	TemplateID string         `db:"template_id"`
+	Reason     sql.NullString `db:"reason"`
+	Actor      string         `db:"actor"`
+	DisabledAt time.Time      `db:"disabled_at"`
+}
+
+// templateRepository implements TemplateRepository
+type templateRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewTemplateRepository creates a new template repository
+func NewTemplateRepository(db *sqlx.DB, logger utils.Logger) TemplateRepository {
+	return &templateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// DisableTemplate inserts or refreshes the kill switch of a template
+func (r *templateRepository) DisableTemplate(ctx context.Context, template *domain.DisabledTemplate) error {
+	query := `
+		INSERT INTO disabled_templates (template_id, reason, actor, disabled_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (template_id)
+		DO UPDATE SET reason = EXCLUDED.reason, actor = EXCLUDED.actor
+		RETURNING disabled_at
+	`
+
+	reason := sql.NullString{String: template.Reason, Valid: template.Reason != ""}
+	return r.db.GetContext(ctx, &template.DisabledAt, query, template.TemplateID, reason, template.Actor, time.Now())
+}
+
+// EnableTemplate removes the kill switch of a template
+func (r *templateRepository) EnableTemplate(ctx context.Context, templateID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM disabled_templates WHERE template_id = $1`, templateID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ListDisabledTemplates returns every disabled template, most recently disabled first
+func (r *templateRepository) ListDisabledTemplates(ctx context.Context) ([]domain.DisabledTemplate, error) {
+	query := `SELECT template_id, reason, actor, disabled_at FROM disabled_templates ORDER BY disabled_at DESC`
+
+	var models []disabledTemplateModel
+	if err := r.db.SelectContext(ctx, &models, query); err != nil {
+		return nil, err
+	}
+
+	templates := make([]domain.DisabledTemplate, 0, len(models))
+	for _, model := range models {
+		templates = append(templates, domain.DisabledTemplate{
+			TemplateID: model.TemplateID,
+			Reason:     model.Reason.String,
+			Actor:      model.Actor,
+			DisabledAt: model.DisabledAt,
+		})
+	}
+	return templates, nil
+}
@@ -0,0 +1,54 @@
+// internal/repository/template_translation_repository.go
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// TemplateTranslationRepository stores the localized bodies of templates
+type TemplateTranslationRepository interface {
+	ListTemplateTranslations(ctx context.Context) ([]domain.TemplateTranslation, error)
+}
+
+// templateTranslationModel represents a template translation in the database
+type templateTranslationModel struct {
+	TemplateID string `db:"template_id"`
+	Locale     string `db:"locale"`
+	Body       string `db:"body"`
+}
+
+// templateTranslationRepository implements TemplateTranslationRepository
+type templateTranslationRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewTemplateTranslationRepository creates a new template translation repository
+func NewTemplateTranslationRepository(db *sqlx.DB, logger utils.Logger) TemplateTranslationRepository {
+	return &templateTranslationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListTemplateTranslations returns every translation
+func (r *templateTranslationRepository) ListTemplateTranslations(ctx context.Context) ([]domain.TemplateTranslation, error) {
+	var models []templateTranslationModel
+	if err := r.db.SelectContext(ctx, &models, `SELECT template_id, locale, body FROM template_translations`); err != nil {
+		return nil, err
+	}
+
+	translations := make([]domain.TemplateTranslation, 0, len(models))
+	for _, model := range models {
+		translations = append(translations, domain.TemplateTranslation{
+			TemplateID: model.TemplateID,
+			Locale:     model.Locale,
+			Body:       model.Body,
+		})
+	}
+	return translations, nil
+}
@@ -0,0 +1,119 @@
+// internal/repository/unit_of_work.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// dbConn is what repositories query through: the pool, or the transaction of a unit of work
+type dbConn interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txKey is the context key of the transaction of a unit of work
+type txKey struct{}
+
+// unitTx is a transaction begun by a unit of work and the callbacks to run once it commits
+type unitTx struct {
+	tx          *sqlx.Tx
+	afterCommit []func(ctx context.Context)
+}
+
+// WithTx returns a context whose repository reads and writes run in tx, for callers managing
+// the transaction themselves
+func WithTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &unitTx{tx: tx})
+}
+
+// InTx reports whether ctx carries a transaction
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*unitTx)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction ctx carries commits, with the context the unit of
+// work was started with, or right away with ctx when it carries none. Side effects of writes,
+// like cache entries and hooks, go through it so a rolled back write leaves none.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if unit, ok := ctx.Value(txKey{}).(*unitTx); ok {
+		unit.afterCommit = append(unit.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// conn returns the transaction ctx carries, or db
+func conn(ctx context.Context, db *sqlx.DB) dbConn {
+	if unit, ok := ctx.Value(txKey{}).(*unitTx); ok {
+		return unit.tx
+	}
+	return db
+}
+
+// UnitOfWork groups repository writes in one transaction
+type UnitOfWork interface {
+	// Begin starts a transaction and returns the context repositories join it with
+	Begin(ctx context.Context) (context.Context, *sqlx.Tx, error)
+	// Do runs fn in a transaction that the repositories called with fn's context join. It
+	// commits when fn returns nil and rolls back otherwise; a transaction already in ctx is
+	// joined instead.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// unitOfWork implements UnitOfWork
+type unitOfWork struct {
+	db *sqlx.DB
+}
+
+// NewUnitOfWork creates a unit of work on db, the pool the repositories write to
+func NewUnitOfWork(db *sqlx.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Begin starts a transaction
+func (u *unitOfWork) Begin(ctx context.Context) (context.Context, *sqlx.Tx, error) {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return WithTx(ctx, tx), tx, nil
+}
+
+// Do runs fn in a transaction, then the callbacks registered with AfterCommit
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	txCtx, tx, err := u.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(txCtx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range txCtx.Value(txKey{}).(*unitTx).afterCommit {
+		fn(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// internal/repository/webhook_failure_repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// WebhookFailureRepository stores the webhook changes that failed to process
+type WebhookFailureRepository interface {
+	// CreateWebhookFailures inserts failures in one statement
+	CreateWebhookFailures(ctx context.Context, failures []domain.WebhookFailure) error
+	// ListWebhookFailures returns up to limit failures replayed fewer than maxAttempts times,
+	// oldest first
+	ListWebhookFailures(ctx context.Context, maxAttempts, limit int) ([]domain.WebhookFailure, error)
+	// RecordWebhookFailureAttempt counts a failed replay and keeps its error
+	RecordWebhookFailureAttempt(ctx context.Context, id int64, errorMessage string) error
+	DeleteWebhookFailure(ctx context.Context, id int64) error
+	// PurgeWebhookFailuresBefore deletes up to limit failures stored before cutoff
+	PurgeWebhookFailuresBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	// EraseWebhookFailures deletes the failures whose payload holds the phone number or the
+	// phone numbers of the customer the filter names
+	EraseWebhookFailures(ctx context.Context, filter domain.MessageFilter) (int64, error)
+}
+
+// webhookFailureModel represents a webhook failure in the database
+type webhookFailureModel struct {
+	ID        int64     `db:"id"`
+	Provider  string    `db:"provider"`
+	EntryID   string    `db:"entry_id"`
+	Field     string    `db:"field"`
+	Payload   []byte    `db:"payload"`
+	Error     string    `db:"error"`
+	Attempts  int       `db:"attempts"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// webhookFailureRepository implements WebhookFailureRepository
+type webhookFailureRepository struct {
+	db     *sqlx.DB
+	logger utils.Logger
+}
+
+// NewWebhookFailureRepository creates a new webhook failure repository
+func NewWebhookFailureRepository(db *sqlx.DB, logger utils.Logger) WebhookFailureRepository {
+	return &webhookFailureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateWebhookFailures inserts failures
+func (r *webhookFailureRepository) CreateWebhookFailures(ctx context.Context, failures []domain.WebhookFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	providers := make([]string, 0, len(failures))
+	entryIDs := make([]string, 0, len(failures))
+	fields := make([]string, 0, len(failures))
+	payloads := make([]string, 0, len(failures))
+	errors := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		providers = append(providers, failure.Provider)
+		entryIDs = append(entryIDs, failure.EntryID)
+		fields = append(fields, failure.Field)
+		payloads = append(payloads, string(failure.Payload))
+		errors = append(errors, failure.Error)
+	}
+
+	query := `
+		INSERT INTO webhook_failures (provider, entry_id, field, payload, error, created_at, updated_at)
+		SELECT provider, entry_id, field, payload, error, $6, $6
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::jsonb[], $5::text[])
+			AS t(provider, entry_id, field, payload, error)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		pq.Array(providers), pq.Array(entryIDs), pq.Array(fields), pq.Array(payloads), pq.Array(errors), time.Now())
+	return err
+}
+
+// ListWebhookFailures returns the failures still to be replayed, oldest first
+func (r *webhookFailureRepository) ListWebhookFailures(ctx context.Context, maxAttempts, limit int) ([]domain.WebhookFailure, error) {
+	query := `
+		SELECT id, provider, entry_id, field, payload, error, attempts, created_at, updated_at
+		FROM webhook_failures
+		WHERE attempts < $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	var models []webhookFailureModel
+	if err := r.db.SelectContext(ctx, &models, query, maxAttempts, limit); err != nil {
+		return nil, err
+	}
+
+	failures := make([]domain.WebhookFailure, 0, len(models))
+	for _, model := range models {
+		failures = append(failures, domain.WebhookFailure{
+			ID:        model.ID,
+			Provider:  model.Provider,
+			EntryID:   model.EntryID,
+			Field:     model.Field,
+			Payload:   model.Payload,
+			Error:     model.Error,
+			Attempts:  model.Attempts,
+			CreatedAt: model.CreatedAt,
+			UpdatedAt: model.UpdatedAt,
+		})
+	}
+	return failures, nil
+}
+
+// RecordWebhookFailureAttempt counts a failed replay
+func (r *webhookFailureRepository) RecordWebhookFailureAttempt(ctx context.Context, id int64, errorMessage string) error {
+	query := `UPDATE webhook_failures SET attempts = attempts + 1, error = $2, updated_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, errorMessage, time.Now())
+	return err
+}
+
+// DeleteWebhookFailure removes a failure once it has been replayed
+func (r *webhookFailureRepository) DeleteWebhookFailure(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhook_failures WHERE id = $1`, id)
+	return err
+}
+
+// PurgeWebhookFailuresBefore deletes up to limit failures stored before cutoff, oldest first,
+// whether or not they ran out of replays
+func (r *webhookFailureRepository) PurgeWebhookFailuresBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM webhook_failures
+		WHERE id IN (
+			SELECT id FROM webhook_failures
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// webhookFailurePhonesSQL lists the phone numbers in the payload of each failure: the
+// recipients of statuses, the contacts and the senders of inbound messages, all digits only
+const webhookFailurePhonesSQL = `
+	SELECT id, jsonb_path_query(payload, '$.entry[*].changes[*].value.statuses[*].recipient_id') #>> '{}' AS phone_number FROM webhook_failures
+	UNION ALL
+	SELECT id, jsonb_path_query(payload, '$.entry[*].changes[*].value.contacts[*].wa_id') #>> '{}' FROM webhook_failures
+	UNION ALL
+	SELECT id, jsonb_path_query(payload, '$.entry[*].changes[*].value.messages[*].from') #>> '{}' FROM webhook_failures`
+
+// EraseWebhookFailures deletes the failures whose payload names the subject's phone number.
+// Failures are not stored per tenant, so a phone number's are deleted whichever business
+// account they came in for. Run it before the subject's messages are anonymized, while they
+// still tie a customer ID to its phone numbers.
+func (r *webhookFailureRepository) EraseWebhookFailures(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	q := newQuery("SELECT id FROM (" + webhookFailurePhonesSQL + ") AS failure_phones")
+	if !whereSubjectPhone(q, "phone_number", scopeFilter(ctx, filter)) {
+		return 0, errors.New("refusing to erase webhook failures without a customer")
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_failures WHERE id IN ("+q.SQL()+")", q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,79 @@
+// internal/repository/webhook_nonce_store.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// WebhookNonceStore remembers the keys of processed webhook events for a TTL, so replays of
+// them can be recognized
+type WebhookNonceStore interface {
+	// Seen reports whether key was recorded within the TTL
+	Seen(ctx context.Context, key string) (bool, error)
+	// Record remembers keys for the TTL
+	Record(ctx context.Context, keys ...string) error
+}
+
+// memoryWebhookNonceStore keeps the keys in an in-process LRU
+type memoryWebhookNonceStore struct {
+	keys ExternalIDCache
+}
+
+// NewMemoryWebhookNonceStore creates an in-process store of at most size keys. Keys evicted
+// early, or recorded by other replicas, are not recognized.
+func NewMemoryWebhookNonceStore(size int, ttl time.Duration) WebhookNonceStore {
+	return &memoryWebhookNonceStore{keys: NewLRUExternalIDCache(size, ttl)}
+}
+
+func (s *memoryWebhookNonceStore) Seen(ctx context.Context, key string) (bool, error) {
+	_, seen := s.keys.Get(ctx, "", key)
+	return seen, nil
+}
+
+func (s *memoryWebhookNonceStore) Record(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		s.keys.Set(ctx, "", key, 1)
+	}
+	return nil
+}
+
+// redisWebhookNonceStore shares the keys between replicas through Redis
+type redisWebhookNonceStore struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+	logger utils.Logger
+}
+
+// NewRedisWebhookNonceStore creates a store keeping each key in Redis for ttl
+func NewRedisWebhookNonceStore(client redis.UniversalClient, ttl time.Duration, logger utils.Logger) WebhookNonceStore {
+	return &redisWebhookNonceStore{
+		client: client,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+func (s *redisWebhookNonceStore) Seen(ctx context.Context, key string) (bool, error) {
+	count, err := s.client.Exists(ctx, "whnonce:"+key).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *redisWebhookNonceStore) Record(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := s.client.Pipeline()
+	for _, key := range keys {
+		pipe.Set(ctx, "whnonce:"+key, 1, s.ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,201 @@
+// internal/service/account_quality_service.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// maxAccountEvents bounds how many events GetAccountQuality returns
+const maxAccountEvents = 100
+
+var (
+	accountEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_account_events_total",
+		Help: "Account update and quality webhook events received, by event.",
+	}, []string{"event"})
+
+	sendRateFactor = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "whatsapp_send_rate_factor",
+		Help: "Factor the provider send rate is scaled by because of the numbers' quality ratings.",
+	})
+)
+
+// RateScaler is a send pacer whose rate can be scaled down
+type RateScaler interface {
+	SetRateFactor(factor float64)
+}
+
+// AccountQualityService records the quality ratings and messaging limits Meta reports for the
+// business phone numbers and slows sending down while a number is rated low. Like pauses, the
+// ratings are refreshed from the database periodically so every replica slows down.
+type AccountQualityService interface {
+	// RecordEvent stores an account event and applies it to the send rate right away
+	RecordEvent(ctx context.Context, event domain.AccountEvent) error
+	// GetAccountQuality returns the caller tenant's numbers and up to eventLimit recent events
+	GetAccountQuality(ctx context.Context, eventLimit int) ([]domain.AccountQuality, []domain.AccountEvent, error)
+	// RateFactor returns the factor the send rate is currently scaled by
+	RateFactor() float64
+	// Refresh reloads every number's rating and rescales the send rate
+	Refresh(ctx context.Context) error
+	// Run refreshes the ratings every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// accountQualityService implements AccountQualityService
+type accountQualityService struct {
+	repo      repository.AccountQualityRepository
+	pacer     RateScaler
+	redFactor float64
+	logger    utils.Logger
+
+	mu     sync.RWMutex
+	red    map[string]bool
+	factor float64
+}
+
+// NewAccountQualityService creates a new account quality service. While any number is rated
+// red the pacer's rate is scaled by redFactor; a nil pacer only records the events.
+func NewAccountQualityService(repo repository.AccountQualityRepository, pacer RateScaler, redFactor float64, logger utils.Logger) AccountQualityService {
+	return &accountQualityService{
+		repo:      repo,
+		pacer:     pacer,
+		redFactor: redFactor,
+		logger:    logger,
+		red:       make(map[string]bool),
+		factor:    1,
+	}
+}
+
+// RecordEvent stores the event and marks or clears the number's low rating
+func (s *accountQualityService) RecordEvent(ctx context.Context, event domain.AccountEvent) error {
+	if event.ReceivedAt.IsZero() {
+		event.ReceivedAt = time.Now()
+	}
+	if err := s.repo.RecordEvent(ctx, &event); err != nil {
+		s.logger.Error("Failed to record account event", "error", err, "tenant_id", event.TenantID, "event", event.Event)
+		return err
+	}
+	accountEventsTotal.WithLabelValues(event.Event).Inc()
+	s.logger.Info("Recorded account event",
+		"tenant_id", event.TenantID,
+		"phone_number", event.PhoneNumber,
+		"field", event.Field,
+		"event", event.Event,
+		"quality_rating", event.QualityRating,
+		"messaging_limit_tier", event.MessagingLimitTier)
+
+	if event.QualityRating == "" {
+		return nil
+	}
+	s.mu.Lock()
+	key := event.TenantID + "/" + event.PhoneNumber
+	if event.QualityRating == domain.QualityRed {
+		s.red[key] = true
+	} else {
+		delete(s.red, key)
+	}
+	s.mu.Unlock()
+	s.applyFactor()
+	return nil
+}
+
+// GetAccountQuality returns the caller tenant's numbers and recent events
+func (s *accountQualityService) GetAccountQuality(ctx context.Context, eventLimit int) ([]domain.AccountQuality, []domain.AccountEvent, error) {
+	if eventLimit < 0 || eventLimit > maxAccountEvents {
+		return nil, nil, domain.NewError(domain.ErrValidation, "event_limit must be between 0 and 100")
+	}
+	if eventLimit == 0 {
+		eventLimit = 20
+	}
+
+	tenantID := domain.TenantFromContext(ctx)
+	qualities, err := s.repo.ListQuality(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.repo.ListEvents(ctx, tenantID, eventLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return qualities, events, nil
+}
+
+// RateFactor returns the current send rate factor
+func (s *accountQualityService) RateFactor() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.factor
+}
+
+// Refresh reloads which numbers are rated red
+func (s *accountQualityService) Refresh(ctx context.Context) error {
+	qualities, err := s.repo.ListQuality(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	red := make(map[string]bool)
+	for _, quality := range qualities {
+		if quality.QualityRating == domain.QualityRed {
+			red[quality.TenantID+"/"+quality.PhoneNumber] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.red = red
+	s.mu.Unlock()
+	s.applyFactor()
+	return nil
+}
+
+// applyFactor scales the pacer down while any number is rated red
+func (s *accountQualityService) applyFactor() {
+	s.mu.Lock()
+	factor := 1.0
+	if len(s.red) > 0 {
+		factor = s.redFactor
+	}
+	changed := factor != s.factor
+	s.factor = factor
+	s.mu.Unlock()
+
+	sendRateFactor.Set(factor)
+	if !changed {
+		return
+	}
+	if s.pacer != nil {
+		s.pacer.SetRateFactor(factor)
+	}
+	if factor < 1 {
+		s.logger.Warn("Slowing sends down because a phone number's quality is low", "rate_factor", factor)
+	} else {
+		s.logger.Info("Restored the send rate as phone number quality recovered")
+	}
+}
+
+// Run refreshes the ratings every interval until ctx is done
+func (s *accountQualityService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Error("Failed to refresh account quality", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
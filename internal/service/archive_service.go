@@ -0,0 +1,139 @@
+// internal/service/archive_service.go
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/objectstore"
+	"messaging-microservice/pkg/utils"
+)
+
+// archivedMessages counts messages moved to object storage
+var archivedMessages = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "whatsapp_archived_messages_total",
+	Help: "Messages moved to object storage by the archive job.",
+})
+
+// ArchivePolicy says when messages leave the hot database
+type ArchivePolicy struct {
+	// MaxHotAge is how long full messages stay in the database; zero disables archiving
+	MaxHotAge time.Duration
+	// BatchSize bounds the messages written per archive object
+	BatchSize int
+}
+
+// ArchiveService moves old messages to object storage, leaving stub rows behind
+type ArchiveService interface {
+	// ArchiveExpired archives all messages past the hot window and returns how many were moved
+	ArchiveExpired(ctx context.Context) (int64, error)
+	// Run archives expired messages every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// archiveService implements ArchiveService
+type archiveService struct {
+	repo   repository.MessageRepository
+	store  objectstore.Store
+	policy ArchivePolicy
+	now    func() time.Time
+	logger utils.Logger
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService(repo repository.MessageRepository, store objectstore.Store, policy ArchivePolicy, logger utils.Logger) ArchiveService {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 1000
+	}
+	return &archiveService{
+		repo:   repo,
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// ArchiveExpired writes expired messages as gzip-compressed JSONL objects, one per batch,
+// and only stubs the rows once the object is stored
+func (s *archiveService) ArchiveExpired(ctx context.Context) (int64, error) {
+	if s.policy.MaxHotAge <= 0 {
+		return 0, nil
+	}
+
+	cutoff := s.now().Add(-s.policy.MaxHotAge)
+	var total int64
+	for {
+		messages, err := s.repo.ListMessagesToArchive(ctx, cutoff, s.policy.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		encoder := json.NewEncoder(gz)
+		ids := make([]int64, 0, len(messages))
+		for _, msg := range messages {
+			if err := encoder.Encode(msg); err != nil {
+				return total, err
+			}
+			ids = append(ids, msg.ID)
+		}
+		if err := gz.Close(); err != nil {
+			return total, err
+		}
+
+		first := messages[0]
+		key := fmt.Sprintf("messages/%s/%d-%d.jsonl.gz", first.CreatedAt.UTC().Format("2006/01/02"), first.ID, messages[len(messages)-1].ID)
+		if err := s.store.Put(ctx, key, buf.Bytes(), "application/gzip"); err != nil {
+			return total, fmt.Errorf("failed to upload archive %s: %w", key, err)
+		}
+		if err := s.repo.MarkMessagesArchived(ctx, ids, key); err != nil {
+			return total, err
+		}
+
+		total += int64(len(messages))
+		archivedMessages.Add(float64(len(messages)))
+
+		if len(messages) < s.policy.BatchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Archived messages", "count", total, "cutoff", cutoff)
+	}
+	return total, nil
+}
+
+// Run archives expired messages every interval until ctx is done
+func (s *archiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ArchiveExpired(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Message archiving failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// internal/service/audit_log.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// Bounds on the audit entries ListAuditEntries returns
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditEntriesTotal counts audit log entries by action and whether they reached the audit topic
+var auditEntriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_audit_entries_total",
+	Help: "Audit log entries recorded, by action and publish result.",
+}, []string{"action", "published"})
+
+// AuditLog records admin and data-changing operations for compliance review. It satisfies
+// repository.AuditRepository, so services auditing their own operations can write through it.
+type AuditLog interface {
+	// RecordAuditEntry stores an entry and publishes it to the audit topic, if any
+	RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) (int64, error)
+	// ListAuditEntries returns the caller tenant's entries matching filter, newest first
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditEvent is an audit log entry as published to the audit topic
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	Action       string          `json:"action"`
+	TenantID     string          `json:"tenant_id"`
+	SubjectType  string          `json:"subject_type"`
+	Subject      string          `json:"subject"`
+	Actor        string          `json:"actor"`
+	Reason       string          `json:"reason,omitempty"`
+	AffectedRows int64           `json:"affected_rows"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// auditLog implements AuditLog
+type auditLog struct {
+	repo     repository.AuditRepository
+	producer queue.Producer
+	logger   utils.Logger
+}
+
+// NewAuditLog creates a new audit log. Entries are also produced to producer's topic, keyed
+// by subject; a nil producer only stores them.
+func NewAuditLog(repo repository.AuditRepository, producer queue.Producer, logger utils.Logger) AuditLog {
+	return &auditLog{
+		repo:     repo,
+		producer: producer,
+		logger:   logger,
+	}
+}
+
+// RecordAuditEntry stores the entry first; the database is the record of truth, so a failed
+// publish is logged rather than failing the operation
+func (a *auditLog) RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) (int64, error) {
+	id, err := a.repo.RecordAuditEntry(ctx, entry)
+	if err != nil {
+		return 0, err
+	}
+
+	published := "none"
+	if a.producer != nil {
+		published = "true"
+		if err := a.publish(ctx, entry); err != nil {
+			published = "false"
+			a.logger.Error("Failed to publish audit entry", "error", err, "audit_id", id, "action", entry.Action)
+		}
+	}
+	auditEntriesTotal.WithLabelValues(entry.Action, published).Inc()
+	return id, nil
+}
+
+// publish produces the entry as JSON
+func (a *auditLog) publish(ctx context.Context, entry *domain.AuditEntry) error {
+	data, err := json.Marshal(AuditEvent{
+		ID:           entry.ID,
+		Action:       entry.Action,
+		TenantID:     entry.TenantID,
+		SubjectType:  entry.SubjectType,
+		Subject:      entry.Subject,
+		Actor:        entry.Actor,
+		Reason:       entry.Reason,
+		AffectedRows: entry.AffectedRows,
+		Before:       entry.Before,
+		After:        entry.After,
+		CreatedAt:    entry.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return a.producer.ProduceWithKey(ctx, []byte(entry.SubjectType+":"+entry.Subject), data)
+}
+
+// ListAuditEntries scopes the filter to the caller's tenant and bounds its limit
+func (a *auditLog) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit < 0 || filter.Limit > maxAuditLimit {
+		return nil, domain.NewError(domain.ErrValidation, "limit must be between 0 and %d", maxAuditLimit)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return nil, domain.NewError(domain.ErrValidation, "since must be before until")
+	}
+
+	filter.TenantID = domain.TenantFromContext(ctx)
+	return a.repo.ListAuditEntries(ctx, filter)
+}
+
+// auditSnapshot marshals the state of an audited object, or returns nil for nil
+func auditSnapshot(state interface{}) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,343 @@
+// internal/service/audited_admin.go
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/utils"
+)
+
+// recordAudit writes an entry for a change that already happened. A failure is returned so
+// the caller retries, which is safe as every audited admin operation is idempotent. Entries of
+// changes reaching every tenant have no TenantID.
+func recordAudit(ctx context.Context, audit AuditLog, logger utils.Logger, entry domain.AuditEntry) error {
+	if _, err := audit.RecordAuditEntry(ctx, &entry); err != nil {
+		logger.Error("Failed to audit admin operation", "error", err, "action", entry.Action, "subject", entry.Subject)
+		return err
+	}
+	return nil
+}
+
+// adminSet holds the callers, as authenticated, allowed to make changes reaching every tenant
+type adminSet map[string]bool
+
+// newAdminSet builds an adminSet from caller names
+func newAdminSet(admins []string) adminSet {
+	set := make(adminSet, len(admins))
+	for _, admin := range admins {
+		set[admin] = true
+	}
+	return set
+}
+
+// allows reports whether ctx may change settings shared by every tenant. API requests, which
+// are scoped to a tenant, may when their authenticated caller is an admin; the requested_by a
+// request claims is never trusted. Internal work, such as Meta's template events, always may.
+func (a adminSet) allows(ctx context.Context) bool {
+	if _, scoped := domain.TenantScope(ctx); !scoped {
+		return true
+	}
+	caller := domain.CallerFromContext(ctx)
+	return caller != "" && a[caller]
+}
+
+// auditedPauseService records pauses and resumes in the audit log and keeps callers other
+// than admins to their own tenant
+type auditedPauseService struct {
+	PauseService
+	audit  AuditLog
+	admins adminSet
+	logger utils.Logger
+}
+
+// NewAuditedPauseService wraps a pause service so every pause and resume is audited. Only the
+// authenticated callers in admins may pause or resume all sends, a template or another tenant.
+func NewAuditedPauseService(inner PauseService, audit AuditLog, admins []string, logger utils.Logger) PauseService {
+	return &auditedPauseService{
+		PauseService: inner,
+		audit:        audit,
+		admins:       newAdminSet(admins),
+		logger:       logger,
+	}
+}
+
+// Pause audits the pause replacing the one in the same scope, if any
+func (s *auditedPauseService) Pause(ctx context.Context, pause domain.SendPause) (*domain.SendPause, error) {
+	if err := s.authorize(ctx, pause.Scope, &pause.Subject); err != nil {
+		return nil, err
+	}
+	pause.Actor = domain.ActorFromContext(ctx, pause.Actor)
+	before := s.current(pause.Scope, pause.Subject)
+	created, err := s.PauseService.Pause(ctx, pause)
+	if err != nil {
+		return nil, err
+	}
+	return created, recordAudit(ctx, s.audit, s.logger, domain.AuditEntry{
+		Action:      domain.AuditActionPauseSending,
+		TenantID:    pauseTenant(pause.Scope, pause.Subject),
+		SubjectType: domain.SubjectSendPause,
+		Subject:     pauseSubject(pause.Scope, pause.Subject),
+		Actor:       pause.Actor,
+		Reason:      pause.Reason,
+		Before:      before,
+		After:       auditSnapshot(created),
+	})
+}
+
+// Resume audits the lifted pause and how many messages it released
+func (s *auditedPauseService) Resume(ctx context.Context, scope, subject, actor string) (int64, error) {
+	if err := s.authorize(ctx, scope, &subject); err != nil {
+		return 0, err
+	}
+	actor = domain.ActorFromContext(ctx, actor)
+	before := s.current(scope, subject)
+	released, err := s.PauseService.Resume(ctx, scope, subject, actor)
+	if err != nil {
+		return released, err
+	}
+	return released, recordAudit(ctx, s.audit, s.logger, domain.AuditEntry{
+		Action:       domain.AuditActionResumeSending,
+		TenantID:     pauseTenant(scope, subject),
+		SubjectType:  domain.SubjectSendPause,
+		Subject:      pauseSubject(scope, subject),
+		Actor:        actor,
+		AffectedRows: released,
+		Before:       before,
+	})
+}
+
+// authorize lets admins change any pause. Other callers may only change their own tenant's:
+// the subject of a tenant pause is forced to the caller's tenant, and pauses of all sends or
+// of a template, which reach every tenant, are refused.
+func (s *auditedPauseService) authorize(ctx context.Context, scope string, subject *string) error {
+	if s.admins.allows(ctx) {
+		return nil
+	}
+	if scope != domain.PauseScopeTenant {
+		return domain.NewError(domain.ErrPermissionDenied, "only admins can pause or resume sends beyond their tenant")
+	}
+	*subject, _ = domain.TenantScope(ctx)
+	return nil
+}
+
+// current snapshots the pause in force in a scope, or returns nil
+func (s *auditedPauseService) current(scope, subject string) []byte {
+	for _, pause := range s.ListPauses() {
+		if pause.Scope == scope && pause.Subject == subject && !pause.Maintenance {
+			return auditSnapshot(pause)
+		}
+	}
+	return nil
+}
+
+// pauseTenant is the tenant a pause's audit entries are filed under: the paused tenant, or
+// none for pauses reaching every tenant
+func pauseTenant(scope, subject string) string {
+	if scope == domain.PauseScopeTenant {
+		return subject
+	}
+	return ""
+}
+
+// pauseSubject names a pause's scope in the audit log, e.g. "template:promo_spring"
+func pauseSubject(scope, subject string) string {
+	if subject == "" {
+		return scope
+	}
+	return scope + ":" + subject
+}
+
+// auditedTemplateSwitch records template kill switch changes in the audit log and keeps them
+// to admins
+type auditedTemplateSwitch struct {
+	TemplateSwitch
+	audit  AuditLog
+	admins adminSet
+	logger utils.Logger
+}
+
+// NewAuditedTemplateSwitch wraps a template kill switch so every change, including those made
+// because of Meta's template events, is audited. A kill switch stops the template for every
+// tenant, so only the authenticated callers in admins may flip it through the API.
+func NewAuditedTemplateSwitch(inner TemplateSwitch, audit AuditLog, admins []string, logger utils.Logger) TemplateSwitch {
+	return &auditedTemplateSwitch{
+		TemplateSwitch: inner,
+		audit:          audit,
+		admins:         newAdminSet(admins),
+		logger:         logger,
+	}
+}
+
+// Disable audits the template's previous kill switch, if any, and the new one
+func (s *auditedTemplateSwitch) Disable(ctx context.Context, template domain.DisabledTemplate) (*domain.DisabledTemplate, error) {
+	if !s.admins.allows(ctx) {
+		return nil, domain.NewError(domain.ErrPermissionDenied, "only admins can disable templates")
+	}
+	template.Actor = domain.ActorFromContext(ctx, template.Actor)
+	before := s.current(template.TemplateID)
+	disabled, err := s.TemplateSwitch.Disable(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	return disabled, recordAudit(ctx, s.audit, s.logger, domain.AuditEntry{
+		Action:      domain.AuditActionDisableTemplate,
+		SubjectType: domain.SubjectTemplate,
+		Subject:     template.TemplateID,
+		Actor:       template.Actor,
+		Reason:      template.Reason,
+		Before:      before,
+		After:       auditSnapshot(disabled),
+	})
+}
+
+// Enable audits the lifted kill switch
+func (s *auditedTemplateSwitch) Enable(ctx context.Context, templateID, actor string) error {
+	if !s.admins.allows(ctx) {
+		return domain.NewError(domain.ErrPermissionDenied, "only admins can enable templates")
+	}
+	actor = domain.ActorFromContext(ctx, actor)
+	before := s.current(templateID)
+	if err := s.TemplateSwitch.Enable(ctx, templateID, actor); err != nil {
+		return err
+	}
+	return recordAudit(ctx, s.audit, s.logger, domain.AuditEntry{
+		Action:      domain.AuditActionEnableTemplate,
+		SubjectType: domain.SubjectTemplate,
+		Subject:     templateID,
+		Actor:       actor,
+		Before:      before,
+	})
+}
+
+// current snapshots a template's kill switch, or returns nil
+func (s *auditedTemplateSwitch) current(templateID string) []byte {
+	if template, disabled := s.Disabled(templateID); disabled {
+		return auditSnapshot(template)
+	}
+	return nil
+}
+
+// auditedCountryPolicy records runtime country rule changes in the audit log and keeps them to
+// admins
+type auditedCountryPolicy struct {
+	CountryPolicy
+	audit  AuditLog
+	admins adminSet
+	logger utils.Logger
+}
+
+// NewAuditedCountryPolicy wraps a country policy so every runtime rule change is audited.
+// Country rules apply to every tenant, so only the authenticated callers in admins may change
+// them through the API.
+func NewAuditedCountryPolicy(inner CountryPolicy, audit AuditLog, admins []string, logger utils.Logger) CountryPolicy {
+	return &auditedCountryPolicy{
+		CountryPolicy: inner,
+		audit:         audit,
+		admins:        newAdminSet(admins),
+		logger:        logger,
+	}
+}
+
+// SetRule audits the rule replaced, if any, and the new one
+func (p *auditedCountryPolicy) SetRule(ctx context.Context, rule domain.CountryRule) (*domain.CountryRule, error) {
+	if !p.admins.allows(ctx) {
+		return nil, domain.NewError(domain.ErrPermissionDenied, "only admins can set country rules")
+	}
+	rule.Actor = domain.ActorFromContext(ctx, rule.Actor)
+	before := p.current(rule.Prefix)
+	set, err := p.CountryPolicy.SetRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return set, recordAudit(ctx, p.audit, p.logger, domain.AuditEntry{
+		Action:      domain.AuditActionSetCountryRule,
+		SubjectType: domain.SubjectCountryPrefix,
+		Subject:     set.Prefix,
+		Actor:       rule.Actor,
+		Reason:      rule.Reason,
+		Before:      before,
+		After:       auditSnapshot(set),
+	})
+}
+
+// DeleteRule audits the removed rule
+func (p *auditedCountryPolicy) DeleteRule(ctx context.Context, prefix, actor string) error {
+	if !p.admins.allows(ctx) {
+		return domain.NewError(domain.ErrPermissionDenied, "only admins can delete country rules")
+	}
+	actor = domain.ActorFromContext(ctx, actor)
+	before := p.current(prefix)
+	if err := p.CountryPolicy.DeleteRule(ctx, prefix, actor); err != nil {
+		return err
+	}
+	return recordAudit(ctx, p.audit, p.logger, domain.AuditEntry{
+		Action:      domain.AuditActionDeleteCountryRule,
+		SubjectType: domain.SubjectCountryPrefix,
+		Subject:     strings.TrimPrefix(prefix, "+"),
+		Actor:       actor,
+		Before:      before,
+	})
+}
+
+// current snapshots the rule for a prefix, or returns nil
+func (p *auditedCountryPolicy) current(prefix string) []byte {
+	prefix = strings.TrimPrefix(prefix, "+")
+	for _, rule := range p.ListRules() {
+		if rule.Prefix == prefix {
+			return auditSnapshot(rule)
+		}
+	}
+	return nil
+}
+
+// auditedMessageService records message deletions in the audit log and keeps hard deletes to
+// admins
+type auditedMessageService struct {
+	MessageService
+	audit  AuditLog
+	admins adminSet
+	logger utils.Logger
+}
+
+// NewAuditedMessageService wraps a message service so every deletion is audited. Only the
+// authenticated callers in admins may hard delete messages; with none, messages can only be
+// soft deleted.
+func NewAuditedMessageService(inner MessageService, audit AuditLog, admins []string, logger utils.Logger) MessageService {
+	return &auditedMessageService{
+		MessageService: inner,
+		audit:          audit,
+		admins:         newAdminSet(admins),
+		logger:         logger,
+	}
+}
+
+// DeleteMessage refuses hard deletes by anyone but an admin and audits the deletion. Admins
+// are told by the caller the request authenticated as, never by the requested_by it claims,
+// which only names the actor of unauthenticated requests. The message's content is not
+// snapshotted, so the audit log never holds data a deletion or an erasure was meant to remove.
+func (s *auditedMessageService) DeleteMessage(ctx context.Context, id int64, hardDelete bool, actor, reason string) error {
+	caller := domain.CallerFromContext(ctx)
+	actor = domain.ActorFromContext(ctx, actor)
+	action := domain.AuditActionDeleteMessage
+	if hardDelete {
+		if caller == "" || !s.admins[caller] {
+			return domain.NewError(domain.ErrPermissionDenied, "only admins can hard delete messages")
+		}
+		action = domain.AuditActionHardDeleteMessage
+	}
+	if err := s.MessageService.DeleteMessage(ctx, id, hardDelete, actor, reason); err != nil {
+		return err
+	}
+	return recordAudit(ctx, s.audit, s.logger, domain.AuditEntry{
+		Action:       action,
+		TenantID:     domain.TenantFromContext(ctx),
+		SubjectType:  domain.SubjectMessage,
+		Subject:      strconv.FormatInt(id, 10),
+		Actor:        actor,
+		Reason:       reason,
+		AffectedRows: 1,
+	})
+}
@@ -0,0 +1,570 @@
+// internal/service/campaign_service.go
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// Bounds of segments and campaigns
+const (
+	// MaxSegmentListSize bounds the phone numbers of a list segment
+	MaxSegmentListSize = 10000
+	// maxSegmentSample bounds the phone numbers a preview returns
+	maxSegmentSample = 100
+	// MaxCampaignVariants bounds the variants of an A/B tested campaign
+	MaxCampaignVariants = 10
+	// MaxAudienceImportRows bounds the data rows of an audience import
+	MaxAudienceImportRows = 100000
+	// maxImportParameters and maxImportParameterLength bound the parameter columns of an
+	// import and their values, as for a single send
+	maxImportParameters      = 50
+	maxImportParameterLength = 1024
+	// maxCampaignKeyLength bounds import column names, variant names and their template IDs
+	maxCampaignKeyLength = 50
+	// maxImportErrors bounds the row errors an import reports
+	maxImportErrors = 100
+	// importBatchSize is how many imported rows are stored at a time
+	importBatchSize = 1000
+	// campaignClaimTimeout is how long a recipient claimed by a replica may go without an outcome
+	// before another replica claims it again
+	campaignClaimTimeout = 10 * time.Minute
+)
+
+// campaignSendsTotal counts campaign sends by outcome
+var campaignSendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_campaign_sends_total",
+	Help: "Campaign recipients sent to, by outcome (sent, failed).",
+}, []string{"outcome"})
+
+// CampaignService manages contacts, the segments built from them, and campaigns sending a
+// template to a segment. A campaign's audience is materialized when it starts; its recipients
+// are then sent to in batches through the message service, so the same policies apply as to
+// any other send.
+type CampaignService interface {
+	// UpsertContact creates or replaces a contact of the caller tenant
+	UpsertContact(ctx context.Context, contact domain.Contact) (*domain.Contact, error)
+	// CreateSegment stores a filter segment, or a list segment of phoneNumbers
+	CreateSegment(ctx context.Context, segment domain.Segment, phoneNumbers []string) (*domain.Segment, error)
+	// ListSegments returns the caller tenant's segments, newest first
+	ListSegments(ctx context.Context) ([]domain.Segment, error)
+	// PreviewSegment returns the size of the stored segment with segmentID, or else of an
+	// unsaved filter segment, and up to sampleSize phone numbers of it
+	PreviewSegment(ctx context.Context, segmentID int64, filter domain.SegmentFilter, sampleSize int) (int64, []string, error)
+	// CreateCampaign stores a draft campaign sending one template, or splitting its audience
+	// between variants
+	CreateCampaign(ctx context.Context, campaign domain.Campaign) (*domain.Campaign, error)
+	// ImportAudience adds the rows of a CSV with a phone_number column, and a template parameter
+	// in each other column, to a draft campaign's audience
+	ImportAudience(ctx context.Context, campaignID int64, data io.Reader) (*domain.AudienceImport, error)
+	// StartCampaign materializes a draft campaign's audience and starts sending to it
+	StartCampaign(ctx context.Context, id int64, actor string) (*domain.Campaign, error)
+	GetCampaign(ctx context.Context, id int64) (*domain.Campaign, error)
+	// GetCampaignReport compares the delivery and read rates of a campaign's variants; campaigns
+	// without variants report a single unnamed one
+	GetCampaignReport(ctx context.Context, id int64) ([]domain.VariantReport, error)
+	// Dispatch sends to one batch of recipients of running campaigns and returns how many
+	Dispatch(ctx context.Context) (int, error)
+	// Run dispatches every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// campaignService implements CampaignService
+type campaignService struct {
+	segments  repository.SegmentRepository
+	campaigns repository.CampaignRepository
+	messages  MessageService
+	batchSize int
+	now       func() time.Time
+	logger    utils.Logger
+}
+
+// NewCampaignService creates a campaign service sending batchSize recipients per dispatch
+// through messages
+func NewCampaignService(segments repository.SegmentRepository, campaigns repository.CampaignRepository, messages MessageService, batchSize int, logger utils.Logger) CampaignService {
+	return &campaignService{
+		segments:  segments,
+		campaigns: campaigns,
+		messages:  messages,
+		batchSize: batchSize,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// UpsertContact normalizes the phone number and stores the contact
+func (s *campaignService) UpsertContact(ctx context.Context, contact domain.Contact) (*domain.Contact, error) {
+	contact.TenantID = domain.TenantFromContext(ctx)
+	contact.PhoneNumber = domain.PhoneDigits(contact.PhoneNumber)
+	if err := s.segments.UpsertContact(ctx, &contact); err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// CreateSegment validates the segment and stores it for the caller tenant
+func (s *campaignService) CreateSegment(ctx context.Context, segment domain.Segment, phoneNumbers []string) (*domain.Segment, error) {
+	if segment.Name == "" {
+		return nil, domain.NewError(domain.ErrValidation, "name is required")
+	}
+	if segment.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	switch segment.Kind {
+	case domain.SegmentKindFilter:
+		if len(phoneNumbers) > 0 {
+			return nil, domain.NewError(domain.ErrValidation, "a segment has either a filter or phone numbers")
+		}
+		if err := validateSegmentFilter(segment.Filter); err != nil {
+			return nil, err
+		}
+	case domain.SegmentKindList:
+		filter := segment.Filter
+		if len(filter.Attributes) > 0 || len(filter.Tags) > 0 || filter.ActiveWithin != 0 || filter.InactiveFor != 0 {
+			return nil, domain.NewError(domain.ErrValidation, "a segment has either a filter or phone numbers")
+		}
+		if len(phoneNumbers) == 0 || len(phoneNumbers) > MaxSegmentListSize {
+			return nil, domain.NewError(domain.ErrValidation, "a list segment needs 1 to %d phone numbers", MaxSegmentListSize)
+		}
+		normalized := make([]string, 0, len(phoneNumbers))
+		for _, phoneNumber := range phoneNumbers {
+			if !utils.IsValidPhoneNumber(phoneNumber) {
+				return nil, domain.NewError(domain.ErrValidation, "invalid phone number %q", phoneNumber)
+			}
+			normalized = append(normalized, domain.PhoneDigits(phoneNumber))
+		}
+		phoneNumbers = normalized
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "kind must be one of: filter, list")
+	}
+
+	segment.TenantID = domain.TenantFromContext(ctx)
+	if err := s.segments.CreateSegment(ctx, &segment, phoneNumbers); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Created segment", "segment_id", segment.ID, "kind", segment.Kind, "requested_by", segment.Actor)
+	return &segment, nil
+}
+
+// validateSegmentFilter checks a filter's activity windows can match someone
+func validateSegmentFilter(filter domain.SegmentFilter) error {
+	if filter.ActiveWithin < 0 || filter.InactiveFor < 0 {
+		return domain.NewError(domain.ErrValidation, "activity windows must not be negative")
+	}
+	if filter.ActiveWithin > 0 && filter.InactiveFor >= filter.ActiveWithin {
+		return domain.NewError(domain.ErrValidation, "inactive_for must be shorter than active_within")
+	}
+	return nil
+}
+
+// ListSegments returns the caller tenant's segments
+func (s *campaignService) ListSegments(ctx context.Context) ([]domain.Segment, error) {
+	return s.segments.ListSegments(ctx, domain.TenantFromContext(ctx))
+}
+
+// PreviewSegment sizes a stored segment or an unsaved filter as a campaign starting now would
+func (s *campaignService) PreviewSegment(ctx context.Context, segmentID int64, filter domain.SegmentFilter, sampleSize int) (int64, []string, error) {
+	if sampleSize < 0 || sampleSize > maxSegmentSample {
+		return 0, nil, domain.NewError(domain.ErrValidation, "sample_size must be between 0 and %d", maxSegmentSample)
+	}
+
+	segment := &domain.Segment{TenantID: domain.TenantFromContext(ctx), Kind: domain.SegmentKindFilter, Filter: filter}
+	if segmentID != 0 {
+		stored, err := s.segments.GetSegment(ctx, segment.TenantID, segmentID)
+		if err != nil {
+			return 0, nil, err
+		}
+		segment = stored
+	} else if err := validateSegmentFilter(filter); err != nil {
+		return 0, nil, err
+	}
+	return s.segments.PreviewSegment(ctx, *segment, s.now(), sampleSize)
+}
+
+// CreateCampaign stores a draft campaign of one of the caller tenant's segments
+func (s *campaignService) CreateCampaign(ctx context.Context, campaign domain.Campaign) (*domain.Campaign, error) {
+	if campaign.Name == "" {
+		return nil, domain.NewError(domain.ErrValidation, "name is required")
+	}
+	if campaign.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	if err := validateCampaignTemplates(campaign); err != nil {
+		return nil, err
+	}
+	campaign.TenantID = domain.TenantFromContext(ctx)
+	if campaign.SegmentID != 0 {
+		if _, err := s.segments.GetSegment(ctx, campaign.TenantID, campaign.SegmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.campaigns.CreateCampaign(ctx, &campaign); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Created campaign", "campaign_id", campaign.ID, "segment_id", campaign.SegmentID, "template_id", campaign.TemplateID,
+		"variants", len(campaign.Variants), "requested_by", campaign.Actor)
+	return &campaign, nil
+}
+
+// validateCampaignTemplates checks a campaign has either a template or 2 or more variants
+func validateCampaignTemplates(campaign domain.Campaign) error {
+	if len(campaign.Variants) == 0 {
+		if campaign.TemplateID == "" {
+			return domain.NewError(domain.ErrValidation, "template_id or variants are required")
+		}
+		return nil
+	}
+	if campaign.TemplateID != "" {
+		return domain.NewError(domain.ErrValidation, "a campaign has either a template_id or variants")
+	}
+	if len(campaign.Variants) < 2 || len(campaign.Variants) > MaxCampaignVariants {
+		return domain.NewError(domain.ErrValidation, "a campaign needs 2 to %d variants", MaxCampaignVariants)
+	}
+
+	names := make(map[string]bool, len(campaign.Variants))
+	for _, variant := range campaign.Variants {
+		switch {
+		case variant.Name == "" || utf8.RuneCountInString(variant.Name) > maxCampaignKeyLength:
+			return domain.NewError(domain.ErrValidation, "variant names must have 1 to %d characters", maxCampaignKeyLength)
+		case names[variant.Name]:
+			return domain.NewError(domain.ErrValidation, "variant %q appears twice", variant.Name)
+		case variant.TemplateID == "" || utf8.RuneCountInString(variant.TemplateID) > maxCampaignKeyLength:
+			return domain.NewError(domain.ErrValidation, "variant %q needs a template_id of at most %d characters", variant.Name, maxCampaignKeyLength)
+		case variant.Weight <= 0:
+			return domain.NewError(domain.ErrValidation, "variant %q needs a positive weight", variant.Name)
+		case len(variant.Parameters) > maxImportParameters:
+			return domain.NewError(domain.ErrValidation, "variant %q has more than %d parameters", variant.Name, maxImportParameters)
+		}
+		names[variant.Name] = true
+	}
+	return nil
+}
+
+// ImportAudience reads the CSV row by row, storing valid rows in batches. Rows repeating a
+// phone number of the file are skipped, and contacts who opted out are left out by the
+// repository. Re-importing a file stores the same recipients and parameters again.
+func (s *campaignService) ImportAudience(ctx context.Context, campaignID int64, data io.Reader) (*domain.AudienceImport, error) {
+	campaign, err := s.campaigns.GetCampaign(ctx, domain.TenantFromContext(ctx), campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != domain.CampaignStatusDraft {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "campaign %d is already %s", campaignID, campaign.Status)
+	}
+
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, domain.NewError(domain.ErrValidation, "the CSV is empty")
+	}
+	if err != nil {
+		return nil, domain.WrapError(domain.ErrValidation, err, "invalid CSV header: %v", err)
+	}
+	phoneColumn, err := parseImportHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.AudienceImport{}
+	seen := make(map[string]bool)
+	batch := make([]domain.CampaignRecipient, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		imported, suppressed, err := s.campaigns.ImportCampaignRecipients(ctx, campaign, batch)
+		if err != nil {
+			return err
+		}
+		if imported+int64(len(suppressed)) < int64(len(batch)) {
+			return domain.NewError(domain.ErrConflict, "campaign %d was started during the import", campaignID)
+		}
+		summary.Imported += imported
+		summary.Suppressed += int64(len(suppressed))
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			summary.Rows++
+			rejectImportRow(summary, int64(parseErr.StartLine), "", "%v", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if summary.Rows == MaxAudienceImportRows {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			return nil, domain.NewError(domain.ErrValidation, "the CSV has more than %d rows; the first %d were imported", MaxAudienceImportRows, summary.Imported)
+		}
+		summary.Rows++
+		line, _ := reader.FieldPos(0)
+
+		if len(record) != len(header) {
+			rejectImportRow(summary, int64(line), "", "has %d fields, the header %d", len(record), len(header))
+			continue
+		}
+		phoneNumber := strings.TrimSpace(record[phoneColumn])
+		if !utils.IsValidPhoneNumber(phoneNumber) {
+			rejectImportRow(summary, int64(line), phoneNumber, "invalid phone number")
+			continue
+		}
+		recipient := domain.CampaignRecipient{PhoneNumber: domain.PhoneDigits(phoneNumber)}
+		if seen[recipient.PhoneNumber] {
+			summary.Duplicates++
+			continue
+		}
+
+		valid := true
+		for i, value := range record {
+			if i == phoneColumn || value == "" {
+				continue
+			}
+			if utf8.RuneCountInString(value) > maxImportParameterLength {
+				rejectImportRow(summary, int64(line), phoneNumber, "parameter %s is longer than %d characters", strings.TrimSpace(header[i]), maxImportParameterLength)
+				valid = false
+				break
+			}
+			if recipient.Parameters == nil {
+				recipient.Parameters = make(map[string]string, len(record)-1)
+			}
+			recipient.Parameters[strings.TrimSpace(header[i])] = value
+		}
+		if !valid {
+			continue
+		}
+
+		seen[recipient.PhoneNumber] = true
+		batch = append(batch, recipient)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Imported campaign audience", "campaign_id", campaignID, "rows", summary.Rows, "imported", summary.Imported,
+		"duplicates", summary.Duplicates, "suppressed", summary.Suppressed, "invalid", summary.Invalid)
+	return summary, nil
+}
+
+// parseImportHeader checks the column names of an import and returns the phone number column
+func parseImportHeader(header []string) (int, error) {
+	if len(header) > maxImportParameters+1 {
+		return 0, domain.NewError(domain.ErrValidation, "the CSV has more than %d parameter columns", maxImportParameters)
+	}
+	// Spreadsheets may start the file with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	phoneColumn := -1
+	names := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			return 0, domain.NewError(domain.ErrValidation, "column %d has no name", i+1)
+		case utf8.RuneCountInString(name) > maxCampaignKeyLength:
+			return 0, domain.NewError(domain.ErrValidation, "column name %q is longer than %d characters", name, maxCampaignKeyLength)
+		case names[name]:
+			return 0, domain.NewError(domain.ErrValidation, "column %q appears twice", name)
+		}
+		names[name] = true
+		if strings.EqualFold(name, "phone_number") {
+			phoneColumn = i
+		}
+	}
+	if phoneColumn < 0 {
+		return 0, domain.NewError(domain.ErrValidation, "the CSV header has no phone_number column")
+	}
+	return phoneColumn, nil
+}
+
+// rejectImportRow counts an invalid row, keeping the first maxImportErrors of them
+func rejectImportRow(summary *domain.AudienceImport, row int64, phoneNumber, format string, args ...interface{}) {
+	summary.Invalid++
+	if len(summary.Errors) < maxImportErrors {
+		summary.Errors = append(summary.Errors, domain.ImportRowError{Row: row, PhoneNumber: phoneNumber, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// StartCampaign materializes the audience of a draft campaign
+func (s *campaignService) StartCampaign(ctx context.Context, id int64, actor string) (*domain.Campaign, error) {
+	if actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	tenantID := domain.TenantFromContext(ctx)
+	campaign, err := s.campaigns.GetCampaign(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != domain.CampaignStatusDraft {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "campaign %d is already %s", id, campaign.Status)
+	}
+	var segment *domain.Segment
+	if campaign.SegmentID != 0 {
+		if segment, err = s.segments.GetSegment(ctx, tenantID, campaign.SegmentID); err != nil {
+			return nil, err
+		}
+	} else if campaign.AudienceSize == 0 {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "campaign %d has no segment and no imported recipients", id)
+	}
+
+	started, err := s.campaigns.StartCampaign(ctx, campaign, segment, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, domain.NewError(domain.ErrConflict, "campaign %d was started concurrently", id)
+	}
+	s.logger.Info("Started campaign", "campaign_id", id, "audience_size", campaign.AudienceSize, "requested_by", actor)
+	return campaign, nil
+}
+
+// GetCampaign returns a campaign of the caller tenant with its progress
+func (s *campaignService) GetCampaign(ctx context.Context, id int64) (*domain.Campaign, error) {
+	return s.campaigns.GetCampaign(ctx, domain.TenantFromContext(ctx), id)
+}
+
+// GetCampaignReport returns a report per variant in the order they were given, including
+// variants nothing was sent for yet
+func (s *campaignService) GetCampaignReport(ctx context.Context, id int64) ([]domain.VariantReport, error) {
+	campaign, err := s.campaigns.GetCampaign(ctx, domain.TenantFromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.campaigns.CampaignVariantStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := campaign.Variants
+	if len(variants) == 0 {
+		variants = []domain.CampaignVariant{{TemplateID: campaign.TemplateID, Parameters: campaign.Parameters, Weight: 1}}
+	}
+	reports := make([]domain.VariantReport, 0, len(variants))
+	for _, variant := range variants {
+		report := domain.VariantReport{Variant: variant, Stats: domain.StatsBucket{TemplateID: variant.TemplateID}}
+		if bucket := stats[variant.Name]; bucket != nil {
+			report.Stats.StatusCounts = bucket.StatusCounts
+			report.Stats.Total = bucket.Total
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Dispatch claims a batch of recipients and sends to each as its campaign's tenant. Sends
+// refused by a policy fail the recipient; other errors leave it claimed, to be sent again once
+// the claim times out.
+func (s *campaignService) Dispatch(ctx context.Context) (int, error) {
+	recipients, err := s.campaigns.ClaimCampaignRecipients(ctx, s.batchSize, s.now().Add(-campaignClaimTimeout))
+	if err != nil {
+		return 0, err
+	}
+
+	// Variants are loaded once per campaign of the batch
+	variants := make(map[int64][]domain.CampaignVariant)
+	for _, recipient := range recipients {
+		campaignVariants, loaded := variants[recipient.CampaignID]
+		if !loaded {
+			if campaignVariants, err = s.campaigns.ListCampaignVariants(ctx, recipient.CampaignID); err != nil {
+				s.logger.Error("Failed to load campaign variants", "error", err, "campaign_id", recipient.CampaignID)
+				continue
+			}
+			variants[recipient.CampaignID] = campaignVariants
+		}
+
+		// Recipient parameters override the variant's, which override the campaign's
+		templateID, variantName := recipient.TemplateID, ""
+		parameters := make(map[string]interface{}, len(recipient.CampaignParameters)+len(recipient.Parameters))
+		for key, value := range recipient.CampaignParameters {
+			parameters[key] = value
+		}
+		if variant := domain.PickVariant(campaignVariants, recipient.CampaignID, recipient.PhoneNumber); variant != nil {
+			templateID, variantName = variant.TemplateID, variant.Name
+			for key, value := range variant.Parameters {
+				parameters[key] = value
+			}
+		}
+		for key, value := range recipient.Parameters {
+			parameters[key] = value
+		}
+
+		sendCtx := domain.WithTenant(ctx, recipient.TenantID)
+		msg, sendErr := s.messages.SendTemplateMessage(sendCtx, "+"+recipient.PhoneNumber, templateID, parameters, "", "")
+		var messageID int64
+		errMsg := ""
+		var refused *domain.Error
+		switch {
+		case sendErr == nil:
+			messageID = msg.ID
+			campaignSendsTotal.WithLabelValues("sent").Inc()
+		case errors.As(sendErr, &refused):
+			errMsg = refused.Message
+			campaignSendsTotal.WithLabelValues("failed").Inc()
+		default:
+			s.logger.Error("Failed to send campaign message", "error", sendErr, "campaign_id", recipient.CampaignID)
+			continue
+		}
+
+		if err := s.campaigns.CompleteCampaignRecipient(ctx, recipient.CampaignID, recipient.PhoneNumber, variantName, messageID, errMsg); err != nil {
+			s.logger.Error("Failed to record campaign send", "error", err, "campaign_id", recipient.CampaignID, "message_id", messageID)
+		}
+	}
+
+	if completed, err := s.campaigns.CompleteCampaigns(ctx, s.now()); err != nil {
+		s.logger.Error("Failed to complete campaigns", "error", err)
+	} else if completed > 0 {
+		s.logger.Info("Completed campaigns", "count", completed)
+	}
+	return len(recipients), nil
+}
+
+// Run dispatches every interval, and right away again while full batches are claimed
+func (s *campaignService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := s.Dispatch(ctx)
+		if err != nil {
+			s.logger.Error("Failed to dispatch campaign messages", "error", err)
+		}
+		if err == nil && sent == s.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
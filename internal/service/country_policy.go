@@ -0,0 +1,292 @@
+// internal/service/country_policy.go
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// maxCountryPrefixLength bounds rule prefixes to a calling code plus a few national digits
+const maxCountryPrefixLength = 6
+
+// countryBlockedSendsTotal counts sends refused by a destination country rule
+var countryBlockedSendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_country_blocked_sends_total",
+	Help: "Sends refused because their destination country is blocked or not allowed.",
+}, []string{"country_code"})
+
+// CountryPolicy decides which destination countries may be sent to. Rules come from the
+// configured allow and block lists and from rules set at runtime, which are stored in the
+// database, override configured rules for the same prefix, and reach other replicas within
+// one refresh interval.
+type CountryPolicy interface {
+	SetRule(ctx context.Context, rule domain.CountryRule) (*domain.CountryRule, error)
+	// DeleteRule removes a runtime rule; configured rules can only be changed in the config
+	DeleteRule(ctx context.Context, prefix, actor string) error
+	// ListRules returns the rules in force ordered by prefix
+	ListRules() []domain.CountryRule
+	// Check returns an error if sends to the phone number are not allowed
+	Check(phoneNumber string) error
+	// Refresh reloads the runtime rules from the database
+	Refresh(ctx context.Context) error
+	// Run refreshes the runtime rules every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// countryPolicy implements CountryPolicy
+type countryPolicy struct {
+	repo       repository.CountryRuleRepository
+	configured map[string]domain.CountryRule
+	logger     utils.Logger
+
+	mu       sync.RWMutex
+	rules    map[string]domain.CountryRule
+	allowing bool
+}
+
+// NewCountryPolicy creates a country policy from the configured allowed and blocked prefixes.
+// A phone number follows the rule with the longest matching prefix; numbers no rule matches
+// are allowed unless some rule allows, in which case only allowed prefixes can be sent to.
+func NewCountryPolicy(repo repository.CountryRuleRepository, allowed, blocked []string, logger utils.Logger) CountryPolicy {
+	configured := make(map[string]domain.CountryRule, len(allowed)+len(blocked))
+	for _, prefix := range allowed {
+		configured[prefix] = domain.CountryRule{Prefix: prefix, Action: domain.CountryActionAllow, Actor: "config"}
+	}
+	for _, prefix := range blocked {
+		configured[prefix] = domain.CountryRule{Prefix: prefix, Action: domain.CountryActionBlock, Actor: "config"}
+	}
+
+	p := &countryPolicy{
+		repo:       repo,
+		configured: configured,
+		logger:     logger,
+	}
+	p.swap(nil)
+	return p
+}
+
+// validateCountryPrefix checks a rule prefix is a plausible dialing prefix
+func validateCountryPrefix(prefix string) error {
+	if prefix == "" || len(prefix) > maxCountryPrefixLength || domain.PhoneDigits(prefix) != prefix || prefix[0] == '0' {
+		return domain.NewError(domain.ErrValidation, "prefix must be 1 to %d digits without a leading zero", maxCountryPrefixLength)
+	}
+	return nil
+}
+
+// SetRule stores the rule and applies it locally right away
+func (p *countryPolicy) SetRule(ctx context.Context, rule domain.CountryRule) (*domain.CountryRule, error) {
+	rule.Prefix = strings.TrimPrefix(rule.Prefix, "+")
+	if err := validateCountryPrefix(rule.Prefix); err != nil {
+		return nil, err
+	}
+	if rule.Action != domain.CountryActionAllow && rule.Action != domain.CountryActionBlock {
+		return nil, domain.NewError(domain.ErrValidation, "action must be one of: allow, block")
+	}
+	if rule.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+
+	if err := p.repo.SetCountryRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+	if err := p.Refresh(ctx); err != nil {
+		p.logger.Error("Failed to refresh country rules", "error", err)
+	}
+
+	p.logger.Warn("Set country rule", "prefix", rule.Prefix, "action", rule.Action, "requested_by", rule.Actor, "reason", rule.Reason)
+	return &rule, nil
+}
+
+// DeleteRule removes a runtime rule and applies it locally right away
+func (p *countryPolicy) DeleteRule(ctx context.Context, prefix, actor string) error {
+	prefix = strings.TrimPrefix(prefix, "+")
+	if err := validateCountryPrefix(prefix); err != nil {
+		return err
+	}
+	if actor == "" {
+		return domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+
+	found, err := p.repo.DeleteCountryRule(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if _, ok := p.configured[prefix]; ok {
+			return domain.NewError(domain.ErrFailedPrecondition, "the rule for +%s is configured; change COUNTRY_ALLOWLIST or COUNTRY_BLOCKLIST to remove it", prefix)
+		}
+		return domain.NewError(domain.ErrNotFound, "no country rule for +%s", prefix)
+	}
+	if err := p.Refresh(ctx); err != nil {
+		p.logger.Error("Failed to refresh country rules", "error", err)
+	}
+
+	p.logger.Info("Deleted country rule", "prefix", prefix, "requested_by", actor)
+	return nil
+}
+
+// ListRules returns a copy of the rules in force
+func (p *countryPolicy) ListRules() []domain.CountryRule {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	rules := make([]domain.CountryRule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Prefix < rules[j].Prefix })
+	return rules
+}
+
+// Check finds the rule with the longest prefix of the phone number
+func (p *countryPolicy) Check(phoneNumber string) error {
+	digits := domain.PhoneDigits(phoneNumber)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for n := min(len(digits), maxCountryPrefixLength); n > 0; n-- {
+		rule, ok := p.rules[digits[:n]]
+		if !ok {
+			continue
+		}
+		if rule.Action == domain.CountryActionAllow {
+			return nil
+		}
+		return countryBlockedError(phoneNumber, rule.Reason)
+	}
+
+	if p.allowing {
+		return countryBlockedError(phoneNumber, "not in the allowed countries")
+	}
+	return nil
+}
+
+// countryBlockedError is returned for sends to a blocked destination
+func countryBlockedError(phoneNumber, reason string) error {
+	code, _ := domain.CountryCallingCode(phoneNumber)
+	countryBlockedSendsTotal.WithLabelValues(code).Inc()
+	if reason == "" {
+		return domain.NewError(domain.ErrFailedPrecondition, "sends to country code +%s are blocked", code)
+	}
+	return domain.NewError(domain.ErrFailedPrecondition, "sends to country code +%s are blocked: %s", code, reason)
+}
+
+// Refresh swaps in the stored runtime rules
+func (p *countryPolicy) Refresh(ctx context.Context) error {
+	stored, err := p.repo.ListCountryRules(ctx)
+	if err != nil {
+		return err
+	}
+	p.swap(stored)
+	return nil
+}
+
+// swap merges the runtime rules over the configured ones
+func (p *countryPolicy) swap(stored []domain.CountryRule) {
+	rules := make(map[string]domain.CountryRule, len(p.configured)+len(stored))
+	for prefix, rule := range p.configured {
+		rules[prefix] = rule
+	}
+	for _, rule := range stored {
+		rules[rule.Prefix] = rule
+	}
+
+	allowing := false
+	for _, rule := range rules {
+		if rule.Action == domain.CountryActionAllow {
+			allowing = true
+			break
+		}
+	}
+
+	p.mu.Lock()
+	p.rules, p.allowing = rules, allowing
+	p.mu.Unlock()
+}
+
+// Run refreshes the runtime rules immediately and then every interval
+func (p *countryPolicy) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Refresh(ctx); err != nil {
+			p.logger.Error("Failed to refresh country rules", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// countryRestrictedMessageService refuses sends to blocked destination countries
+type countryRestrictedMessageService struct {
+	MessageService
+	countries CountryPolicy
+	repo      repository.MessageRepository
+	logger    utils.Logger
+}
+
+// NewCountryRestrictedMessageService wraps a message service so sends to blocked countries are
+// refused, and messages to a country blocked after they were queued are failed unsent
+func NewCountryRestrictedMessageService(inner MessageService, countries CountryPolicy, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &countryRestrictedMessageService{
+		MessageService: inner,
+		countries:      countries,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage refuses blocked destinations before anything is stored
+func (s *countryRestrictedMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if err := s.countries.Check(phoneNumber); err != nil {
+		return nil, err
+	}
+	return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+}
+
+// RetryMessage refuses retries to a corrected phone number in a blocked country
+func (s *countryRestrictedMessageService) RetryMessage(ctx context.Context, id int64, phoneNumber string, parameters map[string]interface{}) (*domain.Message, error) {
+	if phoneNumber != "" {
+		if err := s.countries.Check(phoneNumber); err != nil {
+			return nil, err
+		}
+	}
+	return s.MessageService.RetryMessage(ctx, id, phoneNumber, parameters)
+}
+
+// ProcessQueueMessage fails queued messages whose destination has since been blocked
+func (s *countryRestrictedMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
+		return err
+	}
+
+	cause := s.countries.Check(queueMsg.PhoneNumber)
+	if cause == nil {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	if err := s.repo.UpdateMessageStatus(ctx, queueMsg.MessageID, "failed", "", cause.Error(), ""); err != nil {
+		s.logger.Error("Failed to update message status", "error", err, "message_id", queueMsg.MessageID)
+		return err
+	}
+	s.logger.Warn("Dropped message to blocked country", "message_id", queueMsg.MessageID, "error", cause)
+	return nil
+}
@@ -0,0 +1,54 @@
+// internal/service/delivery_latency.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+)
+
+// deliveryLatency tracks the time from queueing a message to each delivery stage;
+// alert on histogram_quantile over it to catch degrading delivery times
+var deliveryLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "whatsapp_delivery_latency_seconds",
+	Help:    "Time from a message being queued until it reached a delivery stage.",
+	Buckets: prometheus.ExponentialBuckets(0.25, 2, 16),
+}, []string{"stage"})
+
+// maxReportRange bounds the time range of reporting queries
+const maxReportRange = 366 * 24 * time.Hour
+
+// observeStageLatency records how long a message took to reach a stage
+func observeStageLatency(stage string, latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	deliveryLatency.WithLabelValues(stage).Observe(latency.Seconds())
+}
+
+// validateReportRange requires a bounded creation range so reports can't scan every partition
+func validateReportRange(filter domain.MessageFilter) error {
+	if filter.CreatedAfter.IsZero() || filter.CreatedBefore.IsZero() {
+		return domain.NewError(domain.ErrValidation, "created_after and created_before are required")
+	}
+	if !filter.CreatedBefore.After(filter.CreatedAfter) {
+		return domain.NewError(domain.ErrValidation, "created_before must be after created_after")
+	}
+	if filter.CreatedBefore.Sub(filter.CreatedAfter) > maxReportRange {
+		return domain.NewError(domain.ErrValidation, "time range must not exceed %d days", int(maxReportRange/(24*time.Hour)))
+	}
+	return nil
+}
+
+// GetDeliveryLatency reports p50/p95 time from queueing to the sent, delivered and read
+// stages for messages created in the filter's time range
+func (s *messageService) GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) ([]domain.StageLatency, error) {
+	if err := validateReportRange(filter); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveryLatency(ctx, filter)
+}
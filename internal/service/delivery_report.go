@@ -0,0 +1,50 @@
+// internal/service/delivery_report.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// enqueueFailedCode is the error code of messages whose write to the send topic failed
+const enqueueFailedCode = "enqueue_failed"
+
+// deliveryReportTimeout bounds the database update made for each delivery report
+const deliveryReportTimeout = 10 * time.Second
+
+// enqueueReportsTotal counts delivery reports of the send topic by result
+var enqueueReportsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_enqueue_reports_total",
+	Help: "Delivery reports of messages written to the send topic, by result.",
+}, []string{"result"})
+
+// NewEnqueueReporter returns the send producer's delivery hook. A confirmed write stamps the
+// message's enqueued_at; a failed one marks the message failed so it can be retried, which is
+// the only trace of the failure when the producer is async.
+func NewEnqueueReporter(repo repository.MessageRepository, logger utils.Logger) queue.DeliveryFunc {
+	return func(messageID int64, err error) {
+		// Reports arrive on the writer's goroutine, after the producing request is gone
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryReportTimeout)
+		defer cancel()
+
+		if err != nil {
+			enqueueReportsTotal.WithLabelValues("failed").Inc()
+			if updateErr := repo.UpdateMessageStatus(ctx, messageID, "failed", enqueueFailedCode, "Failed to queue message: "+err.Error(), ""); updateErr != nil {
+				logger.Error("Failed to mark unqueued message failed", "error", updateErr, "message_id", messageID)
+			}
+			return
+		}
+
+		enqueueReportsTotal.WithLabelValues("acknowledged").Inc()
+		if markErr := repo.MarkMessageEnqueued(ctx, messageID, time.Now()); markErr != nil {
+			logger.Error("Failed to mark message enqueued", "error", markErr, "message_id", messageID)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// internal/service/duplicate_suppression.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// duplicateSendsTotal counts sends collapsed into an identical earlier message
+var duplicateSendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_duplicate_sends_total",
+	Help: "Sends collapsed into an identical message sent to the same recipient within the duplicate window.",
+}, []string{"tenant_id"})
+
+// duplicateSuppressingMessageService collapses repeated identical sends into the first one
+type duplicateSuppressingMessageService struct {
+	MessageService
+	repo   repository.MessageRepository
+	window time.Duration
+	logger utils.Logger
+}
+
+// NewDuplicateSuppressingMessageService wraps a message service so a send of the same template
+// and parameters to the same recipient within window of an earlier one returns that message
+// instead of sending again, which protects customers from upstream retry storms. Messages that
+// failed to go out are not duplicates, so sending them again still works.
+func NewDuplicateSuppressingMessageService(inner MessageService, repo repository.MessageRepository, window time.Duration, logger utils.Logger) MessageService {
+	return &duplicateSuppressingMessageService{
+		MessageService: inner,
+		repo:           repo,
+		window:         window,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage returns the earlier message when this send duplicates one. A failed
+// lookup lets the send through: a rare double notification beats a lost one.
+func (s *duplicateSuppressingMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	tenantID := domain.TenantFromContext(ctx)
+	duplicate, err := s.repo.FindRecentDuplicate(ctx, &domain.Message{
+		PhoneNumber: phoneNumber,
+		TemplateID:  templateID,
+		Parameters:  parameters,
+		TenantID:    tenantID,
+	}, time.Now().Add(-s.window))
+	switch {
+	case err == nil:
+		duplicateSendsTotal.WithLabelValues(tenantID).Inc()
+		s.logger.Info("Collapsed duplicate send", "message_id", duplicate.ID, "template_id", templateID, "tenant_id", tenantID)
+		return duplicate, nil
+	case !errors.Is(err, domain.ErrNotFound):
+		s.logger.Error("Failed to look up duplicate sends", "error", err, "template_id", templateID)
+	}
+	return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+}
@@ -0,0 +1,131 @@
+// internal/service/handoff.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+)
+
+// Handoff reasons
+const (
+	// HandoffReasonUnhandled is given when nothing could answer the message automatically
+	HandoffReasonUnhandled = "unhandled"
+	// HandoffReasonFollowUp is given when the customer wrote again while an agent has the conversation
+	HandoffReasonFollowUp = "follow_up"
+)
+
+// HandoffRequest asks an agent to take over a conversation. It is published to the handoff
+// channel with the message that triggered it and the conversation's recent messages.
+type HandoffRequest struct {
+	ConversationID int64            `json:"conversation_id"`
+	TenantID       string           `json:"tenant_id"`
+	PhoneNumber    string           `json:"phone_number"`
+	ProfileName    string           `json:"profile_name,omitempty"`
+	Reason         string           `json:"reason"`
+	HandoffStatus  string           `json:"handoff_status"`
+	Message        HandoffMessage   `json:"message"`
+	Context        []HandoffMessage `json:"context,omitempty"`
+	RequestedAt    time.Time        `json:"requested_at"`
+}
+
+// HandoffMessage is an inbound message as shown to agents
+type HandoffMessage struct {
+	ExternalID string               `json:"external_id"`
+	Type       string               `json:"type"`
+	Text       string               `json:"text,omitempty"`
+	Order      *domain.InboundOrder `json:"order,omitempty"`
+	ReceivedAt time.Time            `json:"received_at"`
+}
+
+// newHandoffMessage converts a domain.InboundMessage
+func newHandoffMessage(msg domain.InboundMessage) HandoffMessage {
+	return HandoffMessage{
+		ExternalID: msg.ExternalID,
+		Type:       msg.Type,
+		Text:       msg.Text,
+		Order:      msg.Order,
+		ReceivedAt: msg.ReceivedAt,
+	}
+}
+
+// HandoffChannel delivers handoff requests to where agents pick them up
+type HandoffChannel interface {
+	Publish(ctx context.Context, req HandoffRequest) error
+}
+
+// kafkaHandoffChannel publishes handoff requests to a Kafka topic
+type kafkaHandoffChannel struct {
+	producer queue.Producer
+}
+
+// NewKafkaHandoffChannel creates a channel producing to producer's topic, keyed by
+// conversation so a conversation's requests stay in order
+func NewKafkaHandoffChannel(producer queue.Producer) HandoffChannel {
+	return &kafkaHandoffChannel{producer: producer}
+}
+
+// Publish produces the request as JSON
+func (c *kafkaHandoffChannel) Publish(ctx context.Context, req HandoffRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.producer.ProduceWithKey(ctx, []byte(strconv.FormatInt(req.ConversationID, 10)), data)
+}
+
+// handoffWebhookTimeout bounds each request to the handoff webhook
+const handoffWebhookTimeout = 10 * time.Second
+
+// webhookHandoffChannel posts handoff requests to an HTTP endpoint
+type webhookHandoffChannel struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewWebhookHandoffChannel creates a channel posting requests as JSON to url, e.g. a helpdesk
+// integration creating Zendesk or Freshdesk tickets. A non-empty token is sent as a bearer token.
+func NewWebhookHandoffChannel(url, token string) HandoffChannel {
+	return &webhookHandoffChannel{
+		url:        url,
+		token:      token,
+		httpClient: &http.Client{Timeout: handoffWebhookTimeout},
+	}
+}
+
+// Publish posts the request and fails on non-2xx responses
+func (c *webhookHandoffChannel) Publish(ctx context.Context, req HandoffRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("handoff webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// internal/service/inbound_handler.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+var (
+	// inboundHandlerTotal counts handler invocations by outcome
+	inboundHandlerTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_inbound_handler_total",
+		Help: "Inbound messages offered to each handler, by result (handled, passed, error).",
+	}, []string{"handler", "result"})
+
+	// inboundHandlerDuration tracks how long each handler takes
+	inboundHandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "whatsapp_inbound_handler_duration_seconds",
+		Help:    "Time each inbound handler took to look at a message.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler"})
+)
+
+// InboundHandler answers inbound messages automatically, e.g. a rules engine, an LLM or an
+// external bot service. It returns true once it dealt with the message; otherwise the next
+// handler gets it, and a message no handler takes is handed to an agent.
+type InboundHandler interface {
+	// Name identifies the handler in logs and metrics
+	Name() string
+	HandleInbound(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error)
+}
+
+// inboundHandlerFunc adapts a function to InboundHandler
+type inboundHandlerFunc struct {
+	name string
+	fn   func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error)
+}
+
+// NewInboundHandlerFunc creates a handler named name calling fn
+func NewInboundHandlerFunc(name string, fn func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error)) InboundHandler {
+	return &inboundHandlerFunc{name: name, fn: fn}
+}
+
+func (h *inboundHandlerFunc) Name() string { return h.name }
+
+func (h *inboundHandlerFunc) HandleInbound(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+	return h.fn(ctx, conversation, msg)
+}
+
+// InboundMiddleware wraps a handler, e.g. to bound or log it. The wrapped handler keeps its name.
+type InboundMiddleware func(next InboundHandler) InboundHandler
+
+// InboundPipeline is where handlers are registered. It offers each message to the handlers in
+// registration order, each wrapped in the pipeline's middleware, until one handles it.
+type InboundPipeline struct {
+	handlers   []InboundHandler
+	middleware []InboundMiddleware
+	logger     utils.Logger
+}
+
+// NewInboundPipeline creates an empty pipeline; middleware is applied outermost first
+func NewInboundPipeline(logger utils.Logger, middleware ...InboundMiddleware) *InboundPipeline {
+	return &InboundPipeline{middleware: middleware, logger: logger}
+}
+
+// Use adds middleware applied to handlers registered after it
+func (p *InboundPipeline) Use(middleware ...InboundMiddleware) {
+	p.middleware = append(p.middleware, middleware...)
+}
+
+// Register adds handlers to the end of the chain
+func (p *InboundPipeline) Register(handlers ...InboundHandler) {
+	for _, h := range handlers {
+		for i := len(p.middleware) - 1; i >= 0; i-- {
+			h = p.middleware[i](h)
+		}
+		p.handlers = append(p.handlers, h)
+	}
+}
+
+// Len returns the number of registered handlers
+func (p *InboundPipeline) Len() int {
+	return len(p.handlers)
+}
+
+// Name implements InboundHandler
+func (p *InboundPipeline) Name() string { return "pipeline" }
+
+// HandleInbound runs the chain. A failing handler is logged and skipped rather than failing the
+// message, so a broken bot sends customers to an agent instead of blocking the webhook.
+func (p *InboundPipeline) HandleInbound(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+	for _, h := range p.handlers {
+		start := time.Now()
+		handled, err := h.HandleInbound(ctx, conversation, msg)
+		inboundHandlerDuration.WithLabelValues(h.Name()).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err != nil:
+			inboundHandlerTotal.WithLabelValues(h.Name(), "error").Inc()
+			p.logger.Error("Inbound handler failed", "handler", h.Name(), "error", err, "external_id", msg.ExternalID)
+		case handled:
+			inboundHandlerTotal.WithLabelValues(h.Name(), "handled").Inc()
+			p.logger.Debug("Inbound message handled", "handler", h.Name(), "external_id", msg.ExternalID)
+			return true, nil
+		default:
+			inboundHandlerTotal.WithLabelValues(h.Name(), "passed").Inc()
+		}
+	}
+	return false, nil
+}
+
+// WithInboundTimeout bounds each handler call to timeout; 0 leaves calls unbounded
+func WithInboundTimeout(timeout time.Duration) InboundMiddleware {
+	return func(next InboundHandler) InboundHandler {
+		if timeout <= 0 {
+			return next
+		}
+		return NewInboundHandlerFunc(next.Name(), func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next.HandleInbound(ctx, conversation, msg)
+		})
+	}
+}
+
+// RecoverInbound turns a handler panic into an error
+func RecoverInbound() InboundMiddleware {
+	return func(next InboundHandler) InboundHandler {
+		return NewInboundHandlerFunc(next.Name(), func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (handled bool, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					handled, err = false, fmt.Errorf("inbound handler %s panicked: %v", next.Name(), r)
+				}
+			}()
+			return next.HandleInbound(ctx, conversation, msg)
+		})
+	}
+}
+
+// WithTypingIndicator shows the customer a typing indicator once a handler has been working on
+// their message for delay, e.g. while an LLM writes the reply. Failing to show it is only logged.
+func WithTypingIndicator(typing meta.TypingIndicator, delay time.Duration, logger utils.Logger) InboundMiddleware {
+	return func(next InboundHandler) InboundHandler {
+		if typing == nil {
+			return next
+		}
+		return NewInboundHandlerFunc(next.Name(), func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+			timer := time.AfterFunc(delay, func() {
+				if err := typing.SendTypingIndicator(ctx, msg.ExternalID); err != nil {
+					logger.Warn("Failed to send typing indicator", "handler", next.Name(), "error", err, "external_id", msg.ExternalID)
+				}
+			})
+			defer timer.Stop()
+			return next.HandleInbound(ctx, conversation, msg)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// internal/service/inbound_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+var (
+	// inboundMessagesTotal counts inbound messages by provider message type
+	inboundMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_inbound_messages_total",
+		Help: "Messages received from customers, by message type.",
+	}, []string{"type"})
+
+	// handoffsTotal counts handoff requests published to the agent channel
+	handoffsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_handoffs_total",
+		Help: "Handoff requests published to the agent channel, by reason.",
+	}, []string{"reason"})
+)
+
+// InboundService handles messages customers send in and hands conversations over to agents
+type InboundService interface {
+	// HandleInbound records an inbound message and hands its conversation to an agent. A
+	// failure is returned so the provider redelivers the webhook; handled messages are skipped.
+	HandleInbound(ctx context.Context, msg domain.InboundMessage) error
+	// GetConversation returns the caller tenant's conversation with a customer
+	GetConversation(ctx context.Context, phoneNumber string) (*domain.Conversation, error)
+	// UpdateHandoff records an agent taking (assigned) or finishing (resolved) a conversation,
+	// or hands it over by hand (pending)
+	UpdateHandoff(ctx context.Context, conversationID int64, status, agent string) (*domain.Conversation, error)
+	// SendTypingIndicator shows the customer who sent the caller tenant's inbound message
+	// messageID that a reply is being written
+	SendTypingIndicator(ctx context.Context, messageID string) error
+}
+
+// inboundService implements InboundService
+type inboundService struct {
+	repo        repository.ConversationRepository
+	handler     InboundHandler
+	typing      meta.TypingIndicator
+	channel     HandoffChannel
+	contextSize int
+	now         func() time.Time
+	logger      utils.Logger
+}
+
+// NewInboundService creates an inbound service handing every message to an agent
+func NewInboundService(repo repository.ConversationRepository, channel HandoffChannel, contextSize int, logger utils.Logger) InboundService {
+	return NewInboundServiceWithHandler(repo, nil, nil, channel, contextSize, logger)
+}
+
+// NewInboundServiceWithHandler creates an inbound service offering messages to handler (usually
+// an InboundPipeline) before handing them to an agent, and showing typing indicators through
+// typing (nil when the provider has none). Handoff requests carry the last contextSize messages
+// of the conversation; a nil channel only records inbound messages.
+func NewInboundServiceWithHandler(repo repository.ConversationRepository, handler InboundHandler, typing meta.TypingIndicator, channel HandoffChannel, contextSize int, logger utils.Logger) InboundService {
+	return &inboundService{
+		repo:        repo,
+		handler:     handler,
+		typing:      typing,
+		channel:     channel,
+		contextSize: contextSize,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+// HandleInbound records the message and publishes a handoff request for it
+func (s *inboundService) HandleInbound(ctx context.Context, msg domain.InboundMessage) error {
+	conversation, err := s.repo.RecordInbound(ctx, &msg)
+	if err != nil {
+		s.logger.Error("Failed to record inbound message", "error", err, "external_id", msg.ExternalID)
+		return err
+	}
+	if !msg.HandledAt.IsZero() {
+		s.logger.Debug("Skipping redelivered inbound message", "external_id", msg.ExternalID)
+		return nil
+	}
+	inboundMessagesTotal.WithLabelValues(msg.Type).Inc()
+
+	// Automation only answers conversations no agent has; what it doesn't answer goes to an
+	// agent, and messages of conversations an agent already has are forwarded as follow-ups
+	handled := false
+	if s.handler != nil && !conversation.WithAgent() {
+		if handled, err = s.handler.HandleInbound(ctx, *conversation, msg); err != nil {
+			s.logger.Error("Inbound handler failed", "error", err, "external_id", msg.ExternalID)
+			return err
+		}
+	}
+	if !handled && s.channel != nil {
+		reason := HandoffReasonUnhandled
+		if conversation.WithAgent() {
+			reason = HandoffReasonFollowUp
+		}
+		if err := s.handoff(ctx, conversation, msg, reason); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.MarkInboundHandled(ctx, msg.ID, s.now()); err != nil {
+		s.logger.Error("Failed to mark inbound message handled", "error", err, "inbound_id", msg.ID)
+		return err
+	}
+	return nil
+}
+
+// handoff publishes a handoff request and marks the conversation pending. The request is
+// published first, so a failure after it leads to a duplicate request rather than a lost one.
+func (s *inboundService) handoff(ctx context.Context, conversation *domain.Conversation, msg domain.InboundMessage, reason string) error {
+	recent, err := s.repo.RecentInbound(ctx, conversation.ID, s.contextSize+1)
+	if err != nil {
+		s.logger.Error("Failed to load conversation context", "error", err, "conversation_id", conversation.ID)
+		return err
+	}
+
+	status := conversation.HandoffStatus
+	if !conversation.WithAgent() {
+		status = domain.HandoffPending
+	}
+	req := HandoffRequest{
+		ConversationID: conversation.ID,
+		TenantID:       conversation.TenantID,
+		PhoneNumber:    conversation.PhoneNumber,
+		ProfileName:    conversation.ProfileName,
+		Reason:         reason,
+		HandoffStatus:  status,
+		Message:        newHandoffMessage(msg),
+		RequestedAt:    s.now(),
+	}
+	for _, previous := range recent {
+		if previous.ID != msg.ID && len(req.Context) < s.contextSize {
+			req.Context = append(req.Context, newHandoffMessage(previous))
+		}
+	}
+
+	if err := s.channel.Publish(ctx, req); err != nil {
+		s.logger.Error("Failed to publish handoff request", "error", err, "conversation_id", conversation.ID)
+		return err
+	}
+	handoffsTotal.WithLabelValues(reason).Inc()
+
+	if status != conversation.HandoffStatus {
+		if _, err := s.repo.UpdateHandoff(ctx, conversation.TenantID, conversation.ID, status, reason, ""); err != nil {
+			s.logger.Error("Failed to update handoff state", "error", err, "conversation_id", conversation.ID)
+			return err
+		}
+		s.logger.Info("Handed conversation to an agent", "conversation_id", conversation.ID, "reason", reason)
+	}
+	return nil
+}
+
+// GetConversation looks the conversation up by the customer's number in any common format
+func (s *inboundService) GetConversation(ctx context.Context, phoneNumber string) (*domain.Conversation, error) {
+	phoneNumber = domain.NormalizeCustomerPhone(phoneNumber)
+	if phoneNumber == "" {
+		return nil, domain.NewError(domain.ErrValidation, "phone number is required")
+	}
+	return s.repo.GetConversation(ctx, domain.TenantFromContext(ctx), phoneNumber)
+}
+
+// UpdateHandoff moves a conversation of the caller's tenant to a new handoff state
+func (s *inboundService) UpdateHandoff(ctx context.Context, conversationID int64, status, agent string) (*domain.Conversation, error) {
+	switch status {
+	case domain.HandoffPending:
+		// Nobody has taken it yet
+		agent = ""
+	case domain.HandoffResolved:
+	case domain.HandoffAssigned:
+		if agent == "" {
+			return nil, domain.NewError(domain.ErrValidation, "agent is required to assign a conversation")
+		}
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "handoff status must be pending, assigned or resolved")
+	}
+
+	conversation, err := s.repo.UpdateHandoff(ctx, domain.TenantFromContext(ctx), conversationID, status, "", agent)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Updated conversation handoff", "conversation_id", conversationID, "status", status, "agent", agent)
+	return conversation, nil
+}
+
+// SendTypingIndicator checks the message is the caller tenant's before showing the indicator
+func (s *inboundService) SendTypingIndicator(ctx context.Context, messageID string) error {
+	if messageID == "" {
+		return domain.NewError(domain.ErrValidation, "message ID is required")
+	}
+	if s.typing == nil {
+		return domain.NewError(domain.ErrFailedPrecondition, "the WhatsApp provider does not support typing indicators")
+	}
+	if _, err := s.repo.GetInbound(ctx, domain.TenantFromContext(ctx), messageID); err != nil {
+		return err
+	}
+	if err := s.typing.SendTypingIndicator(ctx, messageID); err != nil {
+		s.logger.Error("Failed to send typing indicator", "error", err, "external_id", messageID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// internal/service/leader.go
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+var (
+	leaderGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_worker_leader",
+		Help: "Whether this instance runs the singleton worker (1) or stands by (0), by worker.",
+	}, []string{"worker"})
+
+	leaderChangesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_worker_leader_changes_total",
+		Help: "Times this instance took or lost the lead of a singleton worker, by worker and change (acquired, released).",
+	}, []string{"worker", "change"})
+)
+
+// LeaderElector runs singleton workers on one instance at a time. Every instance runs the
+// elector for each worker; the one holding the worker's lock runs it, and another takes over
+// within the check interval once that instance stops or loses its database connection.
+type LeaderElector struct {
+	locks    repository.LeaderLocker
+	instance string
+	interval time.Duration
+	logger   utils.Logger
+
+	mu      sync.RWMutex
+	leading map[string]bool
+}
+
+// NewLeaderElector creates an elector for the instance named instance, which tries to take
+// locks and checks the ones it holds every interval
+func NewLeaderElector(locks repository.LeaderLocker, instance string, interval time.Duration, logger utils.Logger) *LeaderElector {
+	return &LeaderElector{
+		locks:    locks,
+		instance: instance,
+		interval: interval,
+		logger:   logger,
+		leading:  make(map[string]bool),
+	}
+}
+
+// IsLeader reports whether this instance runs worker
+func (e *LeaderElector) IsLeader(worker string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading[worker]
+}
+
+// Run runs fn while this instance holds worker's lock, until ctx is done or fn returns by
+// itself. fn gets a context cancelled when the lock is lost and must return soon after, so the
+// lock can pass on.
+func (e *LeaderElector) Run(ctx context.Context, worker string, fn func(ctx context.Context)) {
+	leaderGauge.WithLabelValues(worker).Set(0)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		lock, ok, err := e.locks.TryLock(ctx, worker)
+		if err != nil {
+			e.logger.Error("Failed to take worker lock", "error", err, "worker", worker, "instance", e.instance)
+		} else if ok && e.lead(ctx, worker, lock, ticker.C, fn) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs fn until the lock is lost or ctx is done, then releases the lock. It reports whether
+// fn returned by itself, having nothing to do.
+func (e *LeaderElector) lead(ctx context.Context, worker string, lock repository.HeldLock, ticks <-chan time.Time, fn func(ctx context.Context)) bool {
+	e.setLeading(worker, true)
+	e.logger.Info("Took lead of worker", "worker", worker, "instance", e.instance)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	finished := false
+	for leading := true; leading; {
+		select {
+		case <-ctx.Done():
+			leading = false
+		case <-done:
+			leading, finished = false, true
+		case <-ticks:
+			if err := lock.Check(ctx); err != nil {
+				e.logger.Error("Lost worker lock", "error", err, "worker", worker, "instance", e.instance)
+				leading = false
+			}
+		}
+	}
+	cancel()
+	<-done
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), e.interval)
+	defer cancelRelease()
+	if err := lock.Release(releaseCtx); err != nil {
+		e.logger.Warn("Failed to release worker lock", "error", err, "worker", worker, "instance", e.instance)
+	}
+	e.setLeading(worker, false)
+	e.logger.Info("Gave up lead of worker", "worker", worker, "instance", e.instance)
+	return finished
+}
+
+// Leading returns the workers this instance runs, sorted
+func (e *LeaderElector) Leading() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var workers []string
+	for worker, leading := range e.leading {
+		if leading {
+			workers = append(workers, worker)
+		}
+	}
+	sort.Strings(workers)
+	return workers
+}
+
+func (e *LeaderElector) setLeading(worker string, leading bool) {
+	e.mu.Lock()
+	e.leading[worker] = leading
+	e.mu.Unlock()
+
+	change := "released"
+	if leading {
+		change = "acquired"
+		leaderGauge.WithLabelValues(worker).Set(1)
+	} else {
+		leaderGauge.WithLabelValues(worker).Set(0)
+	}
+	leaderChangesTotal.WithLabelValues(worker, change).Inc()
+}
@@ -0,0 +1,98 @@
+// internal/service/message_search.go
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"messaging-microservice/internal/domain"
+)
+
+// parameterPrefix introduces a parameter condition in a search query
+const parameterPrefix = "parameters."
+
+// SearchMessages finds the caller tenant's messages matching query, newest first. The query
+// holds parameter conditions, written parameters.<name> = <value>, and free text matched
+// against parameter values and the rendered content; double quotes group words.
+func (s *messageService) SearchMessages(ctx context.Context, query string, filter domain.MessageFilter, limit, offset int) ([]*domain.Message, error) {
+	search, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	filter.TenantID = domain.TenantFromContext(ctx)
+	search.Filter = filter
+	return s.repo.SearchMessages(ctx, search, limit, offset)
+}
+
+// parseSearchQuery splits a search query into parameter conditions and free text
+func parseSearchQuery(query string) (domain.MessageSearch, error) {
+	search := domain.MessageSearch{Parameters: make(map[string]string)}
+
+	tokens, err := searchTokens(query)
+	if err != nil {
+		return search, err
+	}
+	var words []string
+	for i := 0; i < len(tokens); i++ {
+		name, ok := strings.CutPrefix(tokens[i], parameterPrefix)
+		if !ok {
+			if tokens[i] != "=" {
+				words = append(words, tokens[i])
+			}
+			continue
+		}
+		if name == "" || i+2 >= len(tokens) || tokens[i+1] != "=" {
+			return search, domain.NewError(domain.ErrValidation, "expected %s<name> = <value> in query", parameterPrefix)
+		}
+		search.Parameters[name] = tokens[i+2]
+		i += 2
+	}
+	search.Text = strings.Join(words, " ")
+
+	if len(search.Parameters) == 0 && search.Text == "" {
+		return search, domain.NewError(domain.ErrValidation, "query is required")
+	}
+	return search, nil
+}
+
+// searchTokens splits a query on spaces, keeping double-quoted runs together and making each
+// unquoted "=" a token of its own
+func searchTokens(query string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+		pending bool
+	)
+	flush := func() {
+		if pending {
+			tokens = append(tokens, current.String())
+		}
+		current.Reset()
+		pending = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			pending = true
+		case quoted:
+			current.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush()
+		case r == '=':
+			flush()
+			tokens = append(tokens, "=")
+		default:
+			current.WriteRune(r)
+			pending = true
+		}
+	}
+	if quoted {
+		return nil, domain.NewError(domain.ErrValidation, "unterminated quote in query")
+	}
+	flush()
+	return tokens, nil
+}
@@ -3,10 +3,12 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
 	"messaging-microservice/internal/domain"
 	"messaging-microservice/internal/queue"
 	"messaging-microservice/internal/repository"
@@ -14,23 +16,69 @@ import (
 	"messaging-microservice/pkg/utils"
 )
 
-// QueueMessage represents a message in the queue
+// expiredMessagesTotal counts queued messages expired unsent because their TTL elapsed
+var expiredMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_expired_messages_total",
+	Help: "Queued messages expired unsent because their TTL elapsed.",
+}, []string{"tenant_id"})
+
+// QueueMessage represents a message in the queue; encode and decode it with EncodeQueueMessage
+// and DecodeQueueMessage so the schema version is handled
 type QueueMessage struct {
+	// SchemaVersion and Type form the envelope; see QueueSchemaVersion
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Type          string `json:"type,omitempty"`
+
 	MessageID   int64                  `json:"message_id"`
 	PhoneNumber string                 `json:"phone_number"`
 	TemplateID  string                 `json:"template_id"`
 	Parameters  map[string]interface{} `json:"parameters"`
 	OrderID     string                 `json:"order_id"`
 	CustomerID  string                 `json:"customer_id"`
+	TenantID    string                 `json:"tenant_id,omitempty"`
+	// RecipientTimezone is set when the sender gave the recipient's timezone explicitly
+	RecipientTimezone string `json:"recipient_timezone,omitempty"`
+}
+
+// newQueueMessage builds the queue envelope of a stored message
+func newQueueMessage(msg *domain.Message) QueueMessage {
+	return QueueMessage{
+		MessageID:         msg.ID,
+		PhoneNumber:       msg.PhoneNumber,
+		TemplateID:        msg.TemplateID,
+		Parameters:        msg.Parameters,
+		OrderID:           msg.OrderID,
+		CustomerID:        msg.CustomerID,
+		TenantID:          msg.TenantID,
+		RecipientTimezone: msg.RecipientTimezone,
+	}
 }
 
 // MessageService defines the interface for message operations
 type MessageService interface {
 	SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error)
 	GetMessageByID(ctx context.Context, id int64) (*domain.Message, error)
-	ListMessages(ctx context.Context, orderID, customerID, phoneNumber string, limit, offset int) ([]*domain.Message, error)
-	UpdateMessageStatus(ctx context.Context, externalID, status, errorMessage string) error
+	GetMessageByExternalID(ctx context.Context, externalID string) (*domain.Message, error)
+	// GetMessages and GetMessagesByExternalIDs retrieve up to MaxBatchLookup messages at once,
+	// in the order asked for and leaving out those not found
+	GetMessages(ctx context.Context, ids []int64) ([]*domain.Message, error)
+	GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) ([]*domain.Message, error)
+	GetMessagesByOrderID(ctx context.Context, orderID string) ([]*domain.Message, error)
+	ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) ([]*domain.Message, int, error)
+	// SearchMessages finds the caller tenant's messages within filter matching a search query,
+	// newest first
+	SearchMessages(ctx context.Context, query string, filter domain.MessageFilter, limit, offset int) ([]*domain.Message, error)
+	ExportMessages(ctx context.Context, filter domain.MessageFilter, chunkSize int, fn func([]*domain.Message) error) error
+	GetMessageStats(ctx context.Context, filter domain.MessageFilter) (*domain.MessageStats, error)
+	GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) ([]domain.StageLatency, error)
+	UpdateMessageStatus(ctx context.Context, externalID, status, errorCode, errorMessage string) error
 	ProcessQueueMessage(ctx context.Context, data []byte) error
+	// RetryMessage sends a failed message again as a new attempt linked to it, with the phone
+	// number replaced and parameters overridden when given
+	RetryMessage(ctx context.Context, id int64, phoneNumber string, parameters map[string]interface{}) (*domain.Message, error)
+	// DeleteMessage hides a message from reads, listings and stats, or removes its row when
+	// hardDelete is set
+	DeleteMessage(ctx context.Context, id int64, hardDelete bool, actor, reason string) error
 }
 
 // messageService implements MessageService
@@ -40,6 +88,7 @@ type messageService struct {
 	producer  queue.Producer
 	logger    utils.Logger
 	isAsync   bool
+	outbox    MessageOutbox
 }
 
 // NewMessageService creates a new message service
@@ -53,18 +102,42 @@ func NewMessageService(repo repository.MessageRepository, whatsapp meta.Client,
 	}
 }
 
+// NewMessageServiceWithOutbox creates a message service that stores each message with its queue
+// payload and first status in one transaction, so a crash between the writes cannot leave a
+// message that is never queued
+func NewMessageServiceWithOutbox(repo repository.MessageRepository, whatsapp meta.Client, producer queue.Producer, outbox MessageOutbox, logger utils.Logger) MessageService {
+	s := NewMessageService(repo, whatsapp, producer, logger).(*messageService)
+	s.outbox = outbox
+	return s
+}
+
 // SendTemplateMessage sends a WhatsApp template message
 func (s *messageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
 	// Create message record
 	msg := &domain.Message{
-		PhoneNumber: phoneNumber,
-		TemplateID:  templateID,
-		Parameters:  parameters,
-		OrderID:     orderID,
-		CustomerID:  customerID,
-		Status:      "queued",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		PhoneNumber:       phoneNumber,
+		TemplateID:        templateID,
+		Parameters:        parameters,
+		OrderID:           orderID,
+		CustomerID:        customerID,
+		TenantID:          domain.TenantFromContext(ctx),
+		RecipientTimezone: domain.RecipientTimezoneFromContext(ctx),
+		ExpiresAt:         domain.ExpiresAtFromContext(ctx),
+		Attempt:           1,
+		Status:            "queued",
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	return s.submit(ctx, msg)
+}
+
+// submit stores a new message and queues it, or sends it right away when not async. A
+// synchronous send is only stored: NewSynchronousMessageService sends it.
+func (s *messageService) submit(ctx context.Context, msg *domain.Message) (*domain.Message, error) {
+	inline := inlineSendFromContext(ctx)
+	if s.isAsync && inline == nil && s.outbox.Work != nil {
+		return s.submitWithOutbox(ctx, msg)
 	}
 
 	// Save to database
@@ -74,31 +147,37 @@ func (s *messageService) SendTemplateMessage(ctx context.Context, phoneNumber, t
 	}
 	msg.ID = msgID
 
+	if inline != nil {
+		inline.messageID = msg.ID
+		return msg, nil
+	}
 	if s.isAsync {
 		// Queue for async processing
-		queueMsg := QueueMessage{
-			MessageID:   msg.ID,
-			PhoneNumber: msg.PhoneNumber,
-			TemplateID:  msg.TemplateID,
-			Parameters:  msg.Parameters,
-			OrderID:     msg.OrderID,
-			CustomerID:  msg.CustomerID,
-		}
+		queueMsg := newQueueMessage(msg)
 
-		// Convert to JSON
-		data, err := json.Marshal(queueMsg)
+		// Encode with the current schema version
+		data, err := EncodeQueueMessage(queueMsg)
 		if err != nil {
 			s.logger.Error("Failed to marshal queue message", "error", err)
 			return msg, nil // Return success but log error
 		}
 
 		// Send to queue
-		if err := s.producer.Produce(ctx, data); err != nil {
-			s.logger.Error("Failed to produce message to queue", "error", err)
-			// Update message status
-			if updateErr := s.repo.UpdateMessageStatus(ctx, msg.ID, "failed", "Failed to queue message: "+err.Error(), ""); updateErr != nil {
+		if err := s.producer.Produce(queue.WithMessageID(ctx, msg.ID), data); err != nil {
+			s.logger.Error("Failed to produce message to queue", "error", err, "message_id", msg.ID)
+			if errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, utils.ErrDeadlineTooClose) {
+				// The write may still reach the broker; its delivery report settles the status
+				return nil, domain.WrapError(context.DeadlineExceeded, err, "deadline exceeded queueing message %d; it is stored and may still be sent, check its status before retrying", msg.ID)
+			}
+			// Update message status, even when the caller's deadline has passed
+			updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+			defer cancel()
+			if updateErr := s.repo.UpdateMessageStatus(updateCtx, msg.ID, "failed", "", "Failed to queue message: "+err.Error(), ""); updateErr != nil {
 				s.logger.Error("Failed to update message status", "error", updateErr)
 			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, domain.WrapError(context.DeadlineExceeded, err, "deadline exceeded before queueing message %d; it is stored as failed and can be retried", msg.ID)
+			}
 			return nil, err
 		}
 	} else {
@@ -111,21 +190,98 @@ func (s *messageService) SendTemplateMessage(ctx context.Context, phoneNumber, t
 	return msg, nil
 }
 
+// retryableStatuses are the statuses of messages that were never delivered and can be retried
+var retryableStatuses = map[string]bool{
+	"failed":                   true,
+	domain.StatusQuotaExceeded: true,
+	domain.StatusExpired:       true,
+}
+
+// RetryMessage stores a copy of a failed message as its next attempt and queues it
+func (s *messageService) RetryMessage(ctx context.Context, id int64, phoneNumber string, parameters map[string]interface{}) (*domain.Message, error) {
+	original, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if !retryableStatuses[original.Status] {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "message %d is %s; only failed, expired and quota_exceeded messages can be retried", id, original.Status)
+	}
+	if original.ArchiveKey != "" {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "message %d is archived and cannot be retried", id)
+	}
+	if !original.DeletedAt.IsZero() {
+		return nil, domain.NewError(domain.ErrNotFound, "message not found")
+	}
+
+	merged := make(map[string]interface{}, len(original.Parameters)+len(parameters))
+	for key, value := range original.Parameters {
+		merged[key] = value
+	}
+	for key, value := range parameters {
+		merged[key] = value
+	}
+	if phoneNumber == "" {
+		phoneNumber = original.PhoneNumber
+	}
+
+	attempt := original.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+	now := time.Now()
+	msg := &domain.Message{
+		PhoneNumber:       phoneNumber,
+		TemplateID:        original.TemplateID,
+		Parameters:        merged,
+		OrderID:           original.OrderID,
+		CustomerID:        original.CustomerID,
+		TenantID:          original.TenantID,
+		RecipientTimezone: original.RecipientTimezone,
+		Attempt:           attempt + 1,
+		RetryOfID:         original.ID,
+		Status:            "queued",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	s.logger.Info("Retrying message", "message_id", original.ID, "attempt", msg.Attempt)
+	return s.submit(domain.WithTenant(ctx, original.TenantID), msg)
+}
+
 // ProcessQueueMessage processes a message from the queue
 func (s *messageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
-	var queueMsg QueueMessage
-	if err := json.Unmarshal(data, &queueMsg); err != nil {
-		s.logger.Error("Failed to unmarshal queue message", "error", err)
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
 		return err
 	}
 
-	// Get message from database
-	msg, err := s.GetMessageByID(ctx, queueMsg.MessageID)
+	// Get message from database; the send decision needs the latest status, not a replica's
+	msg, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), queueMsg.MessageID)
 	if err != nil {
 		s.logger.Error("Failed to get message from database", "error", err)
 		return err
 	}
 
+	// A message deleted while it waited in the queue is not sent
+	if !msg.DeletedAt.IsZero() {
+		s.logger.Info("Skipping deleted message", "message_id", msg.ID)
+		return nil
+	}
+
+	// The same payload can arrive again: the outbox relay republishes it when marking it
+	// published failed, and Kafka redelivers what was consumed but not committed. A message
+	// the provider already accepted, or that ended otherwise, is not sent twice.
+	if !awaitingSend(msg) {
+		s.logger.Info("Skipping message that is no longer waiting to be sent", "message_id", msg.ID, "status", msg.Status)
+		return nil
+	}
+
+	// Content that is stale by now is not worth sending
+	if !msg.ExpiresAt.IsZero() && time.Now().After(msg.ExpiresAt) {
+		return s.expireMessage(ctx, msg)
+	}
+
 	// Send message
 	if err := s.sendMessage(ctx, msg); err != nil {
 		s.logger.Error("Failed to send message", "error", err)
@@ -135,54 +291,315 @@ func (s *messageService) ProcessQueueMessage(ctx context.Context, data []byte) e
 	return nil
 }
 
+// awaitingSend reports whether a message still needs to be sent: it is queued, waits for a
+// retry, or was being sent when a consumer stopped before the provider accepted it
+func awaitingSend(msg *domain.Message) bool {
+	switch msg.Status {
+	case "queued", domain.StatusRetrying:
+		return true
+	case "processing":
+		return msg.ExternalID == ""
+	default:
+		return false
+	}
+}
+
+// expireMessage marks a message whose TTL elapsed in the queue as expired instead of sending it
+func (s *messageService) expireMessage(ctx context.Context, msg *domain.Message) error {
+	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, domain.StatusExpired, "", "expired at "+msg.ExpiresAt.Format(time.RFC3339)+" before it was sent", ""); err != nil {
+		s.logger.Error("Failed to update message status", "error", err, "message_id", msg.ID)
+		return err
+	}
+	expiredMessagesTotal.WithLabelValues(msg.TenantID).Inc()
+	s.logger.Info("Expired queued message", "message_id", msg.ID, "expires_at", msg.ExpiresAt)
+	return nil
+}
+
+// recordSentAttempts bounds the writes of a sent message's external ID, retried after
+// recordSentRetryDelay, doubling
+const (
+	recordSentAttempts   = 3
+	recordSentRetryDelay = 50 * time.Millisecond
+)
+
 // sendMessage sends a WhatsApp message
 func (s *messageService) sendMessage(ctx context.Context, msg *domain.Message) error {
+	// Queued messages arrive without a tenant; repository decorators key on the owner's
+	ctx = domain.WithTenant(ctx, msg.TenantID)
+
 	// Update status to processing
-	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, "processing", "", ""); err != nil {
+	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, "processing", "", "", ""); err != nil {
 		return err
 	}
 
 	// Send message using Meta's WhatsApp API
+	s.logger.Debug("Sending message", "message_id", msg.ID, "phone_number", msg.PhoneNumber, "template_id", msg.TemplateID)
 	resp, err := s.whatsapp.SendTemplateMessage(ctx, msg.PhoneNumber, msg.TemplateID, msg.Parameters)
 	if err != nil {
-		// Update status to failed
-		updateErr := s.repo.UpdateMessageStatus(ctx, msg.ID, "failed", err.Error(), "")
+		// Update status to failed, keeping the provider error code when there is one, even when
+		// the caller's deadline has passed
+		provider, errorCode := domain.ProviderMeta, ""
+		var apiErr providerCodedError
+		if errors.As(err, &apiErr) {
+			provider, errorCode = apiErr.Provider(), apiErr.ErrorCode()
+		}
+		updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+		defer cancel()
+		updateErr := s.repo.UpdateMessageStatus(updateCtx, msg.ID, "failed", errorCode, err.Error(), "")
 		if updateErr != nil {
 			s.logger.Error("Failed to update message status", "error", updateErr)
 		}
-		return err
+		if errors.Is(err, context.DeadlineExceeded) {
+			if errors.Is(err, utils.ErrDeadlineTooClose) {
+				return domain.WrapError(context.DeadlineExceeded, err, "deadline exceeded before sending message %d; it is marked failed and can be retried", msg.ID)
+			}
+			return domain.WrapError(context.DeadlineExceeded, err, "deadline exceeded sending message %d; it is marked failed but the provider may have accepted it", msg.ID)
+		}
+		return providerError(err, provider, errorCode)
 	}
 
-	// Extract the message ID from the Meta response
+	// Extract the message ID from the Meta response and store it before anything else: the
+	// provider may call back with a status at once, and callbacks find the message by it
 	var externalID string
-	if len(resp.Messages) > 0 && resp.Messages[0].ID != "" {
+	if len(resp.Messages) > 0 {
 		externalID = resp.Messages[0].ID
-	} else {
+	}
+	if externalID != "" {
+		if err := s.recordSent(ctx, msg.ID, externalID); err != nil {
+			return err
+		}
+		observeStageLatency(domain.StageSent, time.Since(msg.CreatedAt))
+	}
+
+	// Keep a snapshot of exactly what was sent
+	if len(resp.RequestPayload) > 0 {
+		if err := s.repo.SaveContentSnapshot(ctx, msg.ID, string(resp.RequestPayload)); err != nil {
+			s.logger.Error("Failed to save content snapshot", "error", err, "message_id", msg.ID)
+		}
+	}
+
+	if externalID == "" {
 		return errors.New("no message ID in response")
 	}
+	return nil
+}
+
+// recordSent marks a message the provider accepted sent with its external ID. The provider has
+// the message whatever happens to the caller, so the write outlives the caller's deadline and
+// is retried: a message without its external ID never matches its statuses.
+func (s *messageService) recordSent(ctx context.Context, id int64, externalID string) error {
+	updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+	defer cancel()
+
+	delay := recordSentRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := s.repo.UpdateMessageStatus(updateCtx, id, "sent", "", "", externalID)
+		if err == nil || attempt == recordSentAttempts {
+			return err
+		}
+		s.logger.Warn("Failed to record sent message, retrying", "error", err, "message_id", id, "external_id", externalID, "attempt", attempt)
+		select {
+		case <-updateCtx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// GetMessageByID retrieves a message by ID; soft deleted messages are not found
+func (s *messageService) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
+	return notDeleted(s.repo.GetMessageByID(ctx, id))
+}
+
+// GetMessageByExternalID retrieves a message by its provider external ID; soft deleted
+// messages are not found
+func (s *messageService) GetMessageByExternalID(ctx context.Context, externalID string) (*domain.Message, error) {
+	if externalID == "" {
+		return nil, domain.NewError(domain.ErrValidation, "external ID is required")
+	}
+	return notDeleted(s.repo.GetMessageByExternalID(ctx, externalID))
+}
+
+// notDeleted hides a soft deleted message from a single message read
+func notDeleted(msg *domain.Message, err error) (*domain.Message, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !msg.DeletedAt.IsZero() {
+		return nil, domain.NewError(domain.ErrNotFound, "message not found")
+	}
+	return msg, nil
+}
 
-	// Update status to sent
-	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, "sent", "", externalID); err != nil {
+// DeleteMessage soft deletes a message, or removes its row when hardDelete is set. Soft
+// deleting a message twice is not an error; hard deleting one that is already soft deleted
+// removes it.
+func (s *messageService) DeleteMessage(ctx context.Context, id int64, hardDelete bool, actor, reason string) error {
+	if actor == "" {
+		return domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	msg, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), id)
+	if err != nil {
 		return err
 	}
+	if !hardDelete && !msg.DeletedAt.IsZero() {
+		return nil
+	}
 
+	if err := s.repo.DeleteMessage(ctx, id, hardDelete); err != nil {
+		s.logger.Error("Failed to delete message", "error", err, "message_id", id, "hard_delete", hardDelete)
+		return err
+	}
+	s.logger.Info("Deleted message", "message_id", id, "hard_delete", hardDelete, "requested_by", actor, "reason", reason)
 	return nil
 }
 
-// GetMessageByID retrieves a message by ID
-func (s *messageService) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
-	return s.repo.GetMessageByID(ctx, id)
+// MaxBatchLookup bounds the messages a single batch lookup may ask for
+const MaxBatchLookup = 100
+
+// GetMessages retrieves many messages by ID in one query, in the order of ids. Messages not
+// found or soft deleted are left out.
+func (s *messageService) GetMessages(ctx context.Context, ids []int64) ([]*domain.Message, error) {
+	if err := checkBatchLookup(len(ids), "message ID"); err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.GetMessagesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*domain.Message, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID] = msg
+	}
+	ordered := make([]*domain.Message, 0, len(messages))
+	for _, id := range ids {
+		if msg, ok := byID[id]; ok && msg.DeletedAt.IsZero() {
+			ordered = append(ordered, msg)
+			delete(byID, id)
+		}
+	}
+	return ordered, nil
+}
+
+// GetMessagesByExternalIDs retrieves many messages by provider external ID in one query, in
+// the order of externalIDs. Messages not found or soft deleted are left out.
+func (s *messageService) GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) ([]*domain.Message, error) {
+	if err := checkBatchLookup(len(externalIDs), "external ID"); err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.GetMessagesByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byExternalID := make(map[string]*domain.Message, len(messages))
+	for _, msg := range messages {
+		byExternalID[msg.ExternalID] = msg
+	}
+	ordered := make([]*domain.Message, 0, len(messages))
+	for _, externalID := range externalIDs {
+		if msg, ok := byExternalID[externalID]; ok && msg.DeletedAt.IsZero() {
+			ordered = append(ordered, msg)
+			delete(byExternalID, externalID)
+		}
+	}
+	return ordered, nil
 }
 
-// ListMessages retrieves a list of messages
-func (s *messageService) ListMessages(ctx context.Context, orderID, customerID, phoneNumber string, limit, offset int) ([]*domain.Message, error) {
-	return s.repo.ListMessages(ctx, orderID, customerID, phoneNumber, limit, offset)
+// checkBatchLookup validates the number of keys a batch lookup asks for
+func checkBatchLookup(n int, key string) error {
+	if n == 0 {
+		return domain.NewError(domain.ErrValidation, "at least one %s is required", key)
+	}
+	if n > MaxBatchLookup {
+		return domain.NewError(domain.ErrValidation, "at most %d %ss can be looked up at once, got %d", MaxBatchLookup, key, n)
+	}
+	return nil
+}
+
+// GetMessagesByOrderID retrieves all messages sent for an order
+func (s *messageService) GetMessagesByOrderID(ctx context.Context, orderID string) ([]*domain.Message, error) {
+	if orderID == "" {
+		return nil, domain.NewError(domain.ErrValidation, "order ID is required")
+	}
+	return s.repo.GetMessagesByOrderID(ctx, orderID)
+}
+
+// ListMessages retrieves a page of messages in sort order along with the total number matching
+// the filter
+func (s *messageService) ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) ([]*domain.Message, int, error) {
+	messages, err := s.repo.ListMessages(ctx, filter, sort, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountMessages(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// ExportMessages pages through all messages matching the filter and passes each
+// chunk to fn, so exports never hold more than chunkSize messages in memory
+func (s *messageService) ExportMessages(ctx context.Context, filter domain.MessageFilter, chunkSize int, fn func([]*domain.Message) error) error {
+	if chunkSize <= 0 {
+		return domain.NewError(domain.ErrValidation, "chunk size must be positive")
+	}
+
+	var afterID int64
+	for {
+		messages, err := s.repo.ListMessagesAfterID(ctx, filter, afterID, chunkSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		if err := fn(messages); err != nil {
+			return err
+		}
+
+		afterID = messages[len(messages)-1].ID
+		if len(messages) < chunkSize {
+			return nil
+		}
+	}
+}
+
+// GetMessageStats counts the messages created in the filter's time range by day, template
+// and status
+func (s *messageService) GetMessageStats(ctx context.Context, filter domain.MessageFilter) (*domain.MessageStats, error) {
+	if err := validateReportRange(filter); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountMessagesByDay(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.MessageStats{}
+	for _, count := range counts {
+		n := len(stats.Buckets)
+		if n == 0 || !stats.Buckets[n-1].Day.Equal(count.Day) || stats.Buckets[n-1].TemplateID != count.TemplateID {
+			stats.Buckets = append(stats.Buckets, domain.StatsBucket{Day: count.Day, TemplateID: count.TemplateID})
+			n++
+		}
+		stats.Buckets[n-1].Add(count.Status, count.Count)
+		stats.Summary.Add(count.Status, count.Count)
+	}
+	return stats, nil
 }
 
 // UpdateMessageStatus updates the status of a message
-func (s *messageService) UpdateMessageStatus(ctx context.Context, externalID, status, errorMessage string) error {
+func (s *messageService) UpdateMessageStatus(ctx context.Context, externalID, status, errorCode, errorMessage string) error {
 	if externalID == "" {
-		return errors.New("external ID is required")
+		return domain.NewError(domain.ErrValidation, "external ID is required")
 	}
 
 	msg, err := s.repo.GetMessageByExternalID(ctx, externalID)
@@ -190,5 +607,25 @@ func (s *messageService) UpdateMessageStatus(ctx context.Context, externalID, st
 		return err
 	}
 
-	return s.repo.UpdateMessageStatus(ctx, msg.ID, status, errorMessage, externalID)
-}
\ No newline at end of file
+	return s.repo.UpdateMessageStatus(ctx, msg.ID, status, errorCode, errorMessage, externalID)
+}
+
+// providerCodedError is a provider rejection carrying the provider's error code
+type providerCodedError interface {
+	error
+	ErrorCode() string
+	Provider() string
+}
+
+// providerError classifies a failed provider call so callers can tell throttling and
+// outages apart from other failures
+func providerError(err error, provider, errorCode string) error {
+	category, _ := domain.ClassifyProviderError(provider, errorCode)
+	switch category {
+	case domain.ErrorCategoryRateLimited:
+		return domain.WrapError(domain.ErrProviderRateLimited, err, "provider rate limit reached")
+	case domain.ErrorCategoryProviderUnavailable:
+		return domain.WrapError(domain.ErrProviderUnavailable, err, "provider is unavailable")
+	}
+	return err
+}
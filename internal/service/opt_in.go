@@ -0,0 +1,176 @@
+// internal/service/opt_in.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// maxOptInClockSkew is how far ahead of our clock an opt-in may be dated
+const maxOptInClockSkew = 5 * time.Minute
+
+// optInRefusedSendsTotal counts marketing sends refused for a missing opt-in
+var optInRefusedSendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_opt_in_refused_sends_total",
+	Help: "Marketing sends refused because the contact has not opted in.",
+}, []string{"tenant_id"})
+
+// OptInService records contacts' opt-ins to marketing messages with their proof and, when
+// opt-ins are required, decides which marketing sends may go out
+type OptInService interface {
+	// Record stores an opt-in or opt-out of a contact of the caller tenant
+	Record(ctx context.Context, event domain.OptInEvent) (*domain.OptInEvent, error)
+	// List returns the events of a contact of the caller tenant, most recent first
+	List(ctx context.Context, phoneNumber string) ([]domain.OptInEvent, error)
+	// Check returns an error if the template is a marketing one and the contact isn't opted in
+	Check(ctx context.Context, tenantID, templateID, phoneNumber string) error
+}
+
+// optInService implements OptInService
+type optInService struct {
+	repo      repository.OptInRepository
+	marketing map[string]bool
+	now       func() time.Time
+	logger    utils.Logger
+}
+
+// NewOptInService creates an opt-in service; sends of marketingTemplates need an opt-in
+func NewOptInService(repo repository.OptInRepository, marketingTemplates []string, logger utils.Logger) OptInService {
+	marketing := make(map[string]bool, len(marketingTemplates))
+	for _, templateID := range marketingTemplates {
+		marketing[templateID] = true
+	}
+	return &optInService{
+		repo:      repo,
+		marketing: marketing,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// Record validates and stores the event; events without a time happened now
+func (s *optInService) Record(ctx context.Context, event domain.OptInEvent) (*domain.OptInEvent, error) {
+	if event.Action != domain.OptInActionOptIn && event.Action != domain.OptInActionOptOut {
+		return nil, domain.NewError(domain.ErrValidation, "action must be one of: opt_in, opt_out")
+	}
+	if event.Source == "" || event.Channel == "" {
+		return nil, domain.NewError(domain.ErrValidation, "source and channel are required")
+	}
+	if event.Action == domain.OptInActionOptIn && event.Evidence == "" {
+		return nil, domain.NewError(domain.ErrValidation, "evidence is required for opt-ins")
+	}
+	if event.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	now := s.now()
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now
+	} else if event.OccurredAt.Sub(now) > maxOptInClockSkew {
+		return nil, domain.NewError(domain.ErrValidation, "occurred_at must not be in the future")
+	}
+	event.TenantID = domain.TenantFromContext(ctx)
+	event.PhoneNumber = domain.PhoneDigits(event.PhoneNumber)
+
+	if err := s.repo.RecordOptIn(ctx, &event); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Recorded contact opt-in event", "tenant", event.TenantID, "action", event.Action, "source", event.Source, "channel", event.Channel, "requested_by", event.Actor)
+	return &event, nil
+}
+
+// List returns the events of a contact of the caller tenant
+func (s *optInService) List(ctx context.Context, phoneNumber string) ([]domain.OptInEvent, error) {
+	return s.repo.ListOptIns(ctx, domain.TenantFromContext(ctx), domain.PhoneDigits(phoneNumber))
+}
+
+// Check looks up the contact's latest event for marketing templates
+func (s *optInService) Check(ctx context.Context, tenantID, templateID, phoneNumber string) error {
+	if !s.marketing[templateID] {
+		return nil
+	}
+
+	latest, err := s.repo.LatestOptIn(ctx, tenantID, domain.PhoneDigits(phoneNumber))
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Action == domain.OptInActionOptIn {
+		return nil
+	}
+
+	optInRefusedSendsTotal.WithLabelValues(tenantID).Inc()
+	if latest == nil {
+		return domain.NewError(domain.ErrFailedPrecondition, "template %s is a marketing template and the recipient has not opted in", templateID)
+	}
+	return domain.NewError(domain.ErrFailedPrecondition, "template %s is a marketing template and the recipient opted out at %s", templateID, latest.OccurredAt.UTC().Format(time.RFC3339))
+}
+
+// optInRequiredMessageService refuses marketing sends to contacts without a valid opt-in
+type optInRequiredMessageService struct {
+	MessageService
+	optIns OptInService
+	repo   repository.MessageRepository
+	logger utils.Logger
+}
+
+// NewOptInRequiredMessageService wraps a message service so marketing sends to contacts who
+// haven't opted in are refused, and queued ones to contacts who opted out since are failed unsent
+func NewOptInRequiredMessageService(inner MessageService, optIns OptInService, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &optInRequiredMessageService{
+		MessageService: inner,
+		optIns:         optIns,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage refuses marketing sends without an opt-in before anything is stored
+func (s *optInRequiredMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if err := s.optIns.Check(ctx, domain.TenantFromContext(ctx), templateID, phoneNumber); err != nil {
+		return nil, err
+	}
+	return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+}
+
+// ProcessQueueMessage fails queued marketing messages whose recipient has since opted out
+func (s *optInRequiredMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
+		return err
+	}
+
+	// Envelopes queued before the tenant was included need a lookup
+	tenantID := queueMsg.TenantID
+	if tenantID == "" {
+		msg, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), queueMsg.MessageID)
+		if err != nil {
+			s.logger.Error("Failed to get message from database", "error", err)
+			return err
+		}
+		tenantID = msg.TenantID
+	}
+
+	cause := s.optIns.Check(ctx, tenantID, queueMsg.TemplateID, queueMsg.PhoneNumber)
+	if cause == nil {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+	if !errors.Is(cause, domain.ErrFailedPrecondition) {
+		s.logger.Error("Failed to check contact opt-in", "error", cause, "message_id", queueMsg.MessageID)
+		return cause
+	}
+
+	if err := s.repo.UpdateMessageStatus(ctx, queueMsg.MessageID, "failed", "", cause.Error(), ""); err != nil {
+		s.logger.Error("Failed to update message status", "error", err, "message_id", queueMsg.MessageID)
+		return err
+	}
+	s.logger.Warn("Dropped marketing message without opt-in", "message_id", queueMsg.MessageID, "error", cause)
+	return nil
+}
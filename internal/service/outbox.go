@@ -0,0 +1,151 @@
+// internal/service/outbox.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// outboxRelayBatch is how many pending payloads one relay run publishes at most
+const outboxRelayBatch = 500
+
+// outboxPublishedTotal counts outbox payloads that reached the queue, by path (inline, relay)
+var outboxPublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_outbox_published_total",
+	Help: "Outbox payloads published to the send topic, by path (inline, relay).",
+}, []string{"path"})
+
+// MessageOutbox holds what a message service needs to store messages with their queue payload
+// and first status in one transaction. History is optional.
+type MessageOutbox struct {
+	Work    repository.UnitOfWork
+	Outbox  repository.OutboxRepository
+	History repository.StatusHistoryRepository
+}
+
+// submitWithOutbox stores a message, its queue payload and its queued status together, then
+// produces the payload. A payload that fails to produce stays pending for the relay, so the
+// message is returned as queued all the same.
+func (s *messageService) submitWithOutbox(ctx context.Context, msg *domain.Message) (*domain.Message, error) {
+	var outboxMsg *domain.OutboxMessage
+	err := s.outbox.Work.Do(ctx, func(ctx context.Context) error {
+		msgID, err := s.repo.CreateMessage(ctx, msg)
+		if err != nil {
+			return err
+		}
+		msg.ID = msgID
+
+		data, err := EncodeQueueMessage(newQueueMessage(msg))
+		if err != nil {
+			return err
+		}
+		outboxMsg = &domain.OutboxMessage{MessageID: msg.ID, TenantID: msg.TenantID, Payload: data}
+		if _, err := s.outbox.Outbox.AddOutboxMessage(ctx, outboxMsg); err != nil {
+			return err
+		}
+
+		if s.outbox.History == nil {
+			return nil
+		}
+		return s.outbox.History.AddStatusHistory(ctx, &domain.StatusHistoryEntry{MessageID: msg.ID, Status: msg.Status})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.producer.Produce(queue.WithMessageID(ctx, msg.ID), outboxMsg.Payload); err != nil {
+		s.logger.Warn("Failed to produce message to queue; the outbox relay will publish it", "error", err, "message_id", msg.ID)
+		return msg, nil
+	}
+	outboxPublishedTotal.WithLabelValues("inline").Inc()
+
+	// Without the mark the relay publishes the payload again; the consumer skips messages no
+	// longer waiting to be sent, so the recipient gets one message
+	markCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+	defer cancel()
+	if err := s.outbox.Outbox.MarkOutboxPublished(markCtx, []int64{outboxMsg.ID}); err != nil {
+		s.logger.Error("Failed to mark outbox message published", "error", err, "message_id", msg.ID)
+	}
+	return msg, nil
+}
+
+// OutboxRelay publishes the queue payloads whose produce after commit did not go through
+type OutboxRelay interface {
+	// Relay publishes the oldest payloads pending for longer than the grace period
+	Relay(ctx context.Context) (int, error)
+	// Run relays now and then every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// outboxRelay implements OutboxRelay
+type outboxRelay struct {
+	outbox   repository.OutboxRepository
+	producer queue.Producer
+	grace    time.Duration
+	logger   utils.Logger
+}
+
+// NewOutboxRelay creates a relay publishing to producer. Payloads younger than grace are left
+// to the inline produce of the request that stored them.
+func NewOutboxRelay(outbox repository.OutboxRepository, producer queue.Producer, grace time.Duration, logger utils.Logger) OutboxRelay {
+	return &outboxRelay{
+		outbox:   outbox,
+		producer: producer,
+		grace:    grace,
+		logger:   logger,
+	}
+}
+
+// Relay publishes pending payloads in the order they were stored, stopping at the first that
+// fails so later ones do not overtake it
+func (r *outboxRelay) Relay(ctx context.Context) (int, error) {
+	pending, err := r.outbox.ListPendingOutbox(ctx, time.Now().Add(-r.grace), outboxRelayBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var published []int64
+	for _, msg := range pending {
+		produceCtx := queue.WithMessageID(domain.WithTenant(ctx, msg.TenantID), msg.MessageID)
+		if err = r.producer.Produce(produceCtx, msg.Payload); err != nil {
+			break
+		}
+		published = append(published, msg.ID)
+		outboxPublishedTotal.WithLabelValues("relay").Inc()
+	}
+
+	// Payloads published before a failure are marked all the same
+	if markErr := r.outbox.MarkOutboxPublished(ctx, published); markErr != nil {
+		return 0, errors.Join(err, markErr)
+	}
+	return len(published), err
+}
+
+// Run relays now and then every interval until ctx is done
+func (r *outboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if published, err := r.Relay(ctx); err != nil {
+			r.logger.Error("Failed to relay outbox messages", "error", err)
+		} else if published > 0 {
+			r.logger.Info("Relayed outbox messages", "count", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// internal/service/pause_service.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// releaseBatchSize bounds how many held messages are re-enqueued per database round trip
+const releaseBatchSize = 500
+
+// heldMessagesTotal counts queued messages held back by a send pause
+var heldMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_held_messages_total",
+	Help: "Queued messages held back because sending was paused.",
+}, []string{"scope"})
+
+// maintenancePause is the pause in force while MAINTENANCE_MODE is set
+var maintenancePause = domain.SendPause{
+	Scope:       domain.PauseScopeAll,
+	Reason:      "maintenance mode",
+	Actor:       "config",
+	Maintenance: true,
+}
+
+// PauseService pauses and resumes outbound sending. Pauses are stored in the database and
+// every replica refreshes its copy periodically, so a change takes up to one refresh
+// interval to reach the other replicas.
+type PauseService interface {
+	// Pause stops sends in the pause's scope
+	Pause(ctx context.Context, pause domain.SendPause) (*domain.SendPause, error)
+	// Resume lifts a pause and re-enqueues the messages it held, returning how many were released
+	Resume(ctx context.Context, scope, subject, actor string) (int64, error)
+	// ListPauses returns the pauses in force, including maintenance mode
+	ListPauses() []domain.SendPause
+	// PausedBy returns the pause covering a message of the given tenant and template, if any
+	PausedBy(tenantID, templateID string) (domain.SendPause, bool)
+	// Refresh reloads the pauses and releases the messages held by pauses that ended
+	Refresh(ctx context.Context) (int64, error)
+	// Run refreshes the pauses every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// pauseService implements PauseService
+type pauseService struct {
+	repo        repository.PauseRepository
+	messages    repository.MessageRepository
+	producer    queue.Producer
+	maintenance bool
+	logger      utils.Logger
+
+	mu     sync.RWMutex
+	pauses []domain.SendPause
+	loaded bool
+}
+
+// NewPauseService creates a new pause service; with maintenance set all sends stay paused
+// and the pause cannot be lifted through the API
+func NewPauseService(repo repository.PauseRepository, messages repository.MessageRepository, producer queue.Producer, maintenance bool, logger utils.Logger) PauseService {
+	return &pauseService{
+		repo:        repo,
+		messages:    messages,
+		producer:    producer,
+		maintenance: maintenance,
+		logger:      logger,
+	}
+}
+
+// validatePauseScope checks the scope and subject of a pause
+func validatePauseScope(scope, subject string) error {
+	switch scope {
+	case domain.PauseScopeAll:
+		if subject != "" {
+			return domain.NewError(domain.ErrValidation, "subject must be empty when pausing all sends")
+		}
+	case domain.PauseScopeTenant, domain.PauseScopeTemplate:
+		if subject == "" {
+			return domain.NewError(domain.ErrValidation, "subject is required when pausing a %s", scope)
+		}
+	default:
+		return domain.NewError(domain.ErrValidation, "scope must be one of: all, tenant, template")
+	}
+	return nil
+}
+
+// Pause stores the pause and applies it locally right away
+func (s *pauseService) Pause(ctx context.Context, pause domain.SendPause) (*domain.SendPause, error) {
+	if err := validatePauseScope(pause.Scope, pause.Subject); err != nil {
+		return nil, err
+	}
+	if pause.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+
+	if err := s.repo.CreatePause(ctx, &pause); err != nil {
+		return nil, err
+	}
+	if _, err := s.Refresh(ctx); err != nil {
+		s.logger.Error("Failed to refresh send pauses", "error", err)
+	}
+
+	s.logger.Info("Paused sending", "scope", pause.Scope, "subject", pause.Subject, "requested_by", pause.Actor, "reason", pause.Reason)
+	return &pause, nil
+}
+
+// Resume removes the pause and releases what it held
+func (s *pauseService) Resume(ctx context.Context, scope, subject, actor string) (int64, error) {
+	if err := validatePauseScope(scope, subject); err != nil {
+		return 0, err
+	}
+	if actor == "" {
+		return 0, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+	if s.maintenance && scope == domain.PauseScopeAll {
+		return 0, domain.NewError(domain.ErrFailedPrecondition, "sending is paused by maintenance mode; unset MAINTENANCE_MODE to resume")
+	}
+
+	found, err := s.repo.DeletePause(ctx, scope, subject)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, domain.NewError(domain.ErrNotFound, "no %s pause for %q", scope, subject)
+	}
+
+	released, err := s.Refresh(ctx)
+	if err != nil {
+		return released, err
+	}
+
+	s.logger.Info("Resumed sending", "scope", scope, "subject", subject, "requested_by", actor, "released_messages", released)
+	return released, nil
+}
+
+// ListPauses returns a copy of the pauses in force
+func (s *pauseService) ListPauses() []domain.SendPause {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pauses := make([]domain.SendPause, 0, len(s.pauses)+1)
+	if s.maintenance {
+		pauses = append(pauses, maintenancePause)
+	}
+	return append(pauses, s.pauses...)
+}
+
+// PausedBy checks the local copy of the pauses
+func (s *pauseService) PausedBy(tenantID, templateID string) (domain.SendPause, bool) {
+	if s.maintenance {
+		return maintenancePause, true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pause := range s.pauses {
+		if pause.Covers(tenantID, templateID) {
+			return pause, true
+		}
+	}
+	return domain.SendPause{}, false
+}
+
+// Refresh swaps in the stored pauses. Held messages are released for every pause that ended,
+// and all of them when nothing is paused or on the first load, which catches messages held
+// while this replica still saw a pause another replica had lifted. Released messages still
+// covered by a pause are simply held again by the consumer.
+func (s *pauseService) Refresh(ctx context.Context) (int64, error) {
+	pauses, err := s.repo.ListPauses(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	previous, first := s.pauses, !s.loaded
+	s.pauses, s.loaded = pauses, true
+	s.mu.Unlock()
+
+	if s.maintenance {
+		return 0, nil
+	}
+
+	var filters []domain.MessageFilter
+	if first || len(pauses) == 0 {
+		filters = append(filters, domain.MessageFilter{})
+	} else {
+		for _, pause := range previous {
+			if !containsPause(pauses, pause) {
+				filters = append(filters, pause.Filter())
+			}
+		}
+	}
+
+	var released int64
+	for _, filter := range filters {
+		n, err := s.release(ctx, filter)
+		released += n
+		if err != nil {
+			return released, err
+		}
+	}
+	return released, nil
+}
+
+// containsPause reports whether pauses has one with the same scope and subject as pause
+func containsPause(pauses []domain.SendPause, pause domain.SendPause) bool {
+	for _, p := range pauses {
+		if p.Scope == pause.Scope && p.Subject == pause.Subject {
+			return true
+		}
+	}
+	return false
+}
+
+// release re-enqueues the held messages matching filter in batches
+func (s *pauseService) release(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	var released int64
+	for {
+		messages, err := s.messages.ReleaseHeldMessages(ctx, filter, releaseBatchSize)
+		if err != nil {
+			return released, err
+		}
+
+		for i, msg := range messages {
+			if err := s.enqueue(ctx, msg); err != nil {
+				// Hold the rest again so a later refresh picks them up
+				for _, rest := range messages[i:] {
+					if holdErr := s.messages.HoldMessage(ctx, rest.ID); holdErr != nil {
+						s.logger.Error("Failed to hold message again", "error", holdErr, "message_id", rest.ID)
+					}
+				}
+				return released, err
+			}
+			released++
+		}
+
+		if len(messages) < releaseBatchSize {
+			return released, nil
+		}
+	}
+}
+
+// enqueue produces a released message to the send queue
+func (s *pauseService) enqueue(ctx context.Context, msg *domain.Message) error {
+	data, err := EncodeQueueMessage(newQueueMessage(msg))
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(queue.WithMessageID(ctx, msg.ID), data)
+}
+
+// Run refreshes the pauses immediately and then every interval
+func (s *pauseService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if released, err := s.Refresh(ctx); err != nil {
+			s.logger.Error("Failed to refresh send pauses", "error", err)
+		} else if released > 0 {
+			s.logger.Info("Released held messages", "count", released)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pausableMessageService holds queued messages covered by a send pause instead of sending them
+type pausableMessageService struct {
+	MessageService
+	pauses PauseService
+	repo   repository.MessageRepository
+	logger utils.Logger
+}
+
+// NewPausableMessageService wraps a message service so the queue consumer leaves paused
+// messages in the queued state until their pause is lifted
+func NewPausableMessageService(inner MessageService, pauses PauseService, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &pausableMessageService{
+		MessageService: inner,
+		pauses:         pauses,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// ProcessQueueMessage holds the message if a pause covers it and sends it otherwise
+func (s *pausableMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	if len(s.pauses.ListPauses()) == 0 {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
+		return err
+	}
+
+	// Envelopes queued before the tenant was included need a lookup
+	tenantID := queueMsg.TenantID
+	if tenantID == "" {
+		msg, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), queueMsg.MessageID)
+		if err != nil {
+			s.logger.Error("Failed to get message from database", "error", err)
+			return err
+		}
+		tenantID = msg.TenantID
+	}
+
+	pause, paused := s.pauses.PausedBy(tenantID, queueMsg.TemplateID)
+	if !paused {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	if err := s.repo.HoldMessage(ctx, queueMsg.MessageID); err != nil {
+		s.logger.Error("Failed to hold paused message", "error", err, "message_id", queueMsg.MessageID)
+		return err
+	}
+	heldMessagesTotal.WithLabelValues(pause.Scope).Inc()
+	s.logger.Info("Holding message while sending is paused", "message_id", queueMsg.MessageID, "scope", pause.Scope, "subject", pause.Subject)
+	return nil
+}
@@ -0,0 +1,179 @@
+// internal/service/privacy_service.go
+package service
+
+import (
+	"context"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// privacyExportChunkSize is the page size used when exporting a subject's messages
+const privacyExportChunkSize = 500
+
+// PrivacyService handles data subject requests (erasure and access)
+type PrivacyService interface {
+	EraseCustomerData(ctx context.Context, subject domain.DataSubject, hardDelete bool, actor, reason string) (*domain.AuditEntry, error)
+	ExportCustomerData(ctx context.Context, subject domain.DataSubject, actor, reason string, fn func([]*domain.Message) error) error
+}
+
+// PrivacyStores are the stores holding a data subject's data besides messages, erased with
+// them; nil stores are skipped
+type PrivacyStores struct {
+	Captures        repository.ProviderCaptureRepository
+	Conversations   repository.ConversationRepository
+	OptIns          repository.OptInRepository
+	Contacts        repository.SegmentRepository
+	WebhookFailures repository.WebhookFailureRepository
+}
+
+// privacyService implements PrivacyService
+type privacyService struct {
+	repo   repository.MessageRepository
+	audit  repository.AuditRepository
+	stores PrivacyStores
+	hasher utils.PhoneNumberHasher
+	logger utils.Logger
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService(repo repository.MessageRepository, audit repository.AuditRepository, hasher utils.PhoneNumberHasher, logger utils.Logger) PrivacyService {
+	return NewPrivacyServiceWithStores(repo, audit, PrivacyStores{}, hasher, logger)
+}
+
+// NewPrivacyServiceWithStores creates a privacy service also erasing the subject's data in stores
+func NewPrivacyServiceWithStores(repo repository.MessageRepository, audit repository.AuditRepository, stores PrivacyStores, hasher utils.PhoneNumberHasher, logger utils.Logger) PrivacyService {
+	return &privacyService{
+		repo:   repo,
+		audit:  audit,
+		stores: stores,
+		hasher: hasher,
+		logger: logger,
+	}
+}
+
+// EraseCustomerData anonymizes (or deletes) every message of the subject within the caller's
+// tenant, and deletes the subject's data in the other stores. The stores are erased first,
+// while the messages still tie a customer ID to its data; a failed erasure is safe to retry.
+func (s *privacyService) EraseCustomerData(ctx context.Context, subject domain.DataSubject, hardDelete bool, actor, reason string) (*domain.AuditEntry, error) {
+	entry, filter, err := s.newRequest(ctx, domain.AuditActionEraseCustomerData, subject, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var captures, conversations, optIns, contacts, webhookFailures int64
+	if s.stores.Captures != nil {
+		if captures, err = s.stores.Captures.EraseProviderCaptures(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if s.stores.Conversations != nil {
+		if conversations, err = s.stores.Conversations.EraseConversations(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if s.stores.OptIns != nil {
+		if optIns, err = s.stores.OptIns.EraseOptIns(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if s.stores.Contacts != nil {
+		if contacts, err = s.stores.Contacts.EraseContacts(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if s.stores.WebhookFailures != nil {
+		if webhookFailures, err = s.stores.WebhookFailures.EraseWebhookFailures(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	affected, err := s.repo.EraseMessages(ctx, filter, hardDelete)
+	if err != nil {
+		return nil, err
+	}
+	entry.AffectedRows = affected
+
+	if _, err := s.audit.RecordAuditEntry(ctx, entry); err != nil {
+		// The erasure already happened; surface the failure so the request is retried and audited
+		s.logger.Error("Failed to audit customer data erasure", "error", err, "affected_rows", affected)
+		return nil, err
+	}
+
+	s.logger.Info("Erased customer data", "audit_id", entry.ID, "subject_type", entry.SubjectType, "affected_rows", affected, "provider_captures", captures, "conversations", conversations, "opt_ins", optIns, "contacts", contacts, "webhook_failures", webhookFailures, "hard_delete", hardDelete)
+	return entry, nil
+}
+
+// ExportCustomerData passes every message of the subject within the caller's tenant to fn in chunks
+func (s *privacyService) ExportCustomerData(ctx context.Context, subject domain.DataSubject, actor, reason string, fn func([]*domain.Message) error) error {
+	entry, filter, err := s.newRequest(ctx, domain.AuditActionExportCustomerData, subject, actor, reason)
+	if err != nil {
+		return err
+	}
+
+	var afterID int64
+	var exportErr error
+	for {
+		messages, err := s.repo.ListMessagesAfterID(ctx, filter, afterID, privacyExportChunkSize)
+		if err != nil {
+			exportErr = err
+			break
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		if err := fn(messages); err != nil {
+			exportErr = err
+			break
+		}
+		entry.AffectedRows += int64(len(messages))
+
+		afterID = messages[len(messages)-1].ID
+		if len(messages) < privacyExportChunkSize {
+			break
+		}
+	}
+
+	// Record the access even when the export was cut short, since data may already have left
+	if _, err := s.audit.RecordAuditEntry(ctx, entry); err != nil {
+		s.logger.Error("Failed to audit customer data export", "error", err, "exported", entry.AffectedRows)
+		if exportErr == nil {
+			exportErr = err
+		}
+	}
+
+	return exportErr
+}
+
+// newRequest validates a data subject request and builds its audit entry and message filter
+func (s *privacyService) newRequest(ctx context.Context, action string, subject domain.DataSubject, actor, reason string) (*domain.AuditEntry, domain.MessageFilter, error) {
+	tenantID := domain.TenantFromContext(ctx)
+	entry := &domain.AuditEntry{
+		Action:   action,
+		TenantID: tenantID,
+		Actor:    actor,
+		Reason:   reason,
+	}
+	// A subject's data includes the messages soft deleted from normal reads
+	filter := domain.MessageFilter{TenantID: tenantID, IncludeDeleted: true}
+
+	switch {
+	case (subject.CustomerID == "") == (subject.PhoneNumber == ""):
+		return nil, filter, domain.NewError(domain.ErrValidation, "exactly one of customer_id or phone_number is required")
+	case actor == "":
+		return nil, filter, domain.NewError(domain.ErrValidation, "requested_by is required")
+	case subject.CustomerID != "":
+		entry.SubjectType = domain.SubjectCustomerID
+		entry.Subject = subject.CustomerID
+		filter.CustomerID = subject.CustomerID
+	default:
+		// Pseudonymize the phone number in the audit log when PHONE_HASH_KEY is set
+		entry.SubjectType = domain.SubjectPhoneNumber
+		entry.Subject = s.hasher.Hash(subject.PhoneNumber)
+		filter.PhoneNumber = subject.PhoneNumber
+	}
+
+	return entry, filter, nil
+}
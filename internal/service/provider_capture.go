@@ -0,0 +1,116 @@
+// internal/service/provider_capture.go
+package service
+
+import (
+	"context"
+	"sync"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// ProviderCaptureService serves the provider requests and responses captured while sending
+// messages in debug mode, to investigate how a message was rendered. The retention job
+// purges them, and erasing a customer's data deletes theirs.
+type ProviderCaptureService interface {
+	// Enabled reports whether sends are being captured
+	Enabled() bool
+	// ListProviderCaptures returns the caller tenant's captures of a message, oldest first
+	ListProviderCaptures(ctx context.Context, messageID int64) ([]domain.ProviderCapture, error)
+}
+
+// providerCaptureService implements ProviderCaptureService
+type providerCaptureService struct {
+	repo    repository.ProviderCaptureRepository
+	enabled bool
+	logger  utils.Logger
+}
+
+// NewProviderCaptureService creates a provider capture service; enabled only tells callers
+// whether new sends are captured
+func NewProviderCaptureService(repo repository.ProviderCaptureRepository, enabled bool, logger utils.Logger) ProviderCaptureService {
+	return &providerCaptureService{
+		repo:    repo,
+		enabled: enabled,
+		logger:  logger,
+	}
+}
+
+// Enabled reports whether sends are being captured
+func (s *providerCaptureService) Enabled() bool {
+	return s.enabled
+}
+
+// ListProviderCaptures returns the caller tenant's captures of a message
+func (s *providerCaptureService) ListProviderCaptures(ctx context.Context, messageID int64) ([]domain.ProviderCapture, error) {
+	return s.repo.ListProviderCaptures(ctx, domain.TenantFromContext(ctx), messageID)
+}
+
+// capturingMessageService records the provider requests of each queued send
+type capturingMessageService struct {
+	MessageService
+	repo   repository.ProviderCaptureRepository
+	logger utils.Logger
+}
+
+// NewCapturingMessageService wraps a message service so the provider requests and responses of
+// every queued send are stored against the message. Only requests through a transport from
+// utils.NewCapturingTransport are seen.
+func NewCapturingMessageService(inner MessageService, repo repository.ProviderCaptureRepository, logger utils.Logger) MessageService {
+	return &capturingMessageService{
+		MessageService: inner,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// ProcessQueueMessage sends the message, then stores the provider exchanges it took. A send
+// attempt that failed is captured as well, which is usually when captures are wanted.
+func (s *capturingMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	var mu sync.Mutex
+	var exchanges []utils.HTTPExchange
+	sendErr := s.MessageService.ProcessQueueMessage(utils.WithCapture(ctx, func(exchange utils.HTTPExchange) {
+		mu.Lock()
+		exchanges = append(exchanges, exchange)
+		mu.Unlock()
+	}), data)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(exchanges) == 0 {
+		return sendErr
+	}
+
+	tenantID := queueMsg.TenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	// Captures are kept even when the caller's deadline has passed
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+	defer cancel()
+	for _, exchange := range exchanges {
+		if err := s.repo.SaveProviderCapture(saveCtx, &domain.ProviderCapture{
+			MessageID:       queueMsg.MessageID,
+			TenantID:        tenantID,
+			Method:          exchange.Method,
+			URL:             exchange.URL,
+			RequestHeaders:  exchange.RequestHeaders,
+			RequestBody:     exchange.RequestBody,
+			StatusCode:      exchange.StatusCode,
+			ResponseHeaders: exchange.ResponseHeaders,
+			ResponseBody:    exchange.ResponseBody,
+			Error:           exchange.Error,
+			Duration:        exchange.Duration,
+			CreatedAt:       exchange.StartedAt,
+		}); err != nil {
+			s.logger.Error("Failed to save provider capture", "error", err, "message_id", queueMsg.MessageID)
+		}
+	}
+	return sendErr
+}
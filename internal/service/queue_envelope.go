@@ -0,0 +1,54 @@
+// internal/service/queue_envelope.go
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// QueueSchemaVersion is the version of the queue payload this build writes. Version 1 is the
+// unversioned payload written before envelopes existed.
+const QueueSchemaVersion = 2
+
+// minQueueSchemaVersion is the oldest payload version this build still reads; keep it at most
+// one below QueueSchemaVersion so messages queued by the previous release survive a deploy
+const minQueueSchemaVersion = 1
+
+// Queue payload types
+const (
+	QueueMessageTypeSendTemplate = "send_template"
+)
+
+// EncodeQueueMessage encodes a queue message with the current schema version. The envelope
+// fields sit next to the message fields, so consumers of the previous version, which ignore
+// unknown fields, still read it during a rolling deploy.
+func EncodeQueueMessage(msg QueueMessage) ([]byte, error) {
+	msg.SchemaVersion = QueueSchemaVersion
+	msg.Type = QueueMessageTypeSendTemplate
+	return json.Marshal(msg)
+}
+
+// DecodeQueueMessage decodes a queue message of any supported schema version. Payloads from a
+// newer release are refused rather than half understood.
+func DecodeQueueMessage(data []byte) (QueueMessage, error) {
+	var msg QueueMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return QueueMessage{}, err
+	}
+
+	// Version 1 payloads carry neither a version nor a type
+	if msg.SchemaVersion == 0 {
+		msg.SchemaVersion = 1
+	}
+	if msg.Type == "" {
+		msg.Type = QueueMessageTypeSendTemplate
+	}
+
+	if msg.SchemaVersion < minQueueSchemaVersion || msg.SchemaVersion > QueueSchemaVersion {
+		return QueueMessage{}, fmt.Errorf("unsupported queue schema version %d (supported: %d to %d)", msg.SchemaVersion, minQueueSchemaVersion, QueueSchemaVersion)
+	}
+	if msg.Type != QueueMessageTypeSendTemplate {
+		return QueueMessage{}, fmt.Errorf("unsupported queue message type %q", msg.Type)
+	}
+	return msg, nil
+}
@@ -0,0 +1,43 @@
+// internal/service/queue_schemas.go
+package service
+
+// QueueMessageAvroSchema is the Avro schema of QueueMessage, registered for the send topic when
+// the schema registry is enabled. New fields must have defaults so the schema stays backward
+// compatible.
+const QueueMessageAvroSchema = `{
+  "type": "record",
+  "name": "QueueMessage",
+  "namespace": "whatsapp.queue",
+  "fields": [
+    {"name": "schema_version", "type": "int", "default": 1},
+    {"name": "type", "type": "string", "default": "send_template"},
+    {"name": "message_id", "type": "long"},
+    {"name": "phone_number", "type": "string"},
+    {"name": "template_id", "type": "string"},
+    {"name": "parameters", "type": {"type": "map", "values": ["null", "string", "long", "double", "boolean"]}, "default": {}},
+    {"name": "order_id", "type": "string", "default": ""},
+    {"name": "customer_id", "type": "string", "default": ""},
+    {"name": "tenant_id", "type": "string", "default": ""},
+    {"name": "recipient_timezone", "type": "string", "default": ""}
+  ]
+}`
+
+// WebhookEventAvroSchema is the Avro schema of WebhookEvent, registered for the status topic
+// when the schema registry is enabled
+const WebhookEventAvroSchema = `{
+  "type": "record",
+  "name": "WebhookEvent",
+  "namespace": "whatsapp.events",
+  "fields": [
+    {"name": "message_id", "type": "long"},
+    {"name": "tenant_id", "type": "string", "default": ""},
+    {"name": "external_id", "type": "string"},
+    {"name": "status", "type": "string"},
+    {"name": "error_code", "type": "string", "default": ""},
+    {"name": "error_message", "type": "string", "default": ""},
+    {"name": "phone_number", "type": "string"},
+    {"name": "timestamp", "type": "string", "default": ""},
+    {"name": "sequence", "type": "long", "default": 0},
+    {"name": "dedupe_key", "type": "string", "default": ""}
+  ]
+}`
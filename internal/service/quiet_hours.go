@@ -0,0 +1,263 @@
+// internal/service/quiet_hours.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// quietHoursOff exempts a tenant from the default quiet hours
+const quietHoursOff = "off"
+
+// deferredMessagesTotal counts marketing messages deferred out of quiet hours
+var deferredMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_quiet_hours_deferred_total",
+	Help: "Marketing messages deferred because they arrived in the recipient's quiet hours.",
+}, []string{"tenant_id"})
+
+// QuietHoursConfig holds the quiet hours windows and the templates they apply to
+type QuietHoursConfig struct {
+	// Default is the window for tenants without their own; empty disables it
+	Default string
+	// Tenants overrides the window per tenant; "off" exempts the tenant
+	Tenants map[string]string
+	// MarketingTemplates are the templates of the marketing category, the only ones deferred
+	MarketingTemplates []string
+}
+
+// QuietHoursScheduler defers marketing messages that arrive in their recipient's quiet hours
+// and re-enqueues them once the window ends
+type QuietHoursScheduler interface {
+	// DeferUntil returns when a message may be sent if it falls in quiet hours at now
+	DeferUntil(tenantID, templateID, phoneNumber, timezone string, now time.Time) (time.Time, bool)
+	// Release re-enqueues the deferred messages that are due, returning how many were released
+	Release(ctx context.Context) (int64, error)
+	// Run releases due messages every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// quietHoursScheduler implements QuietHoursScheduler
+type quietHoursScheduler struct {
+	fallback  *domain.QuietHours
+	tenants   map[string]*domain.QuietHours
+	marketing map[string]bool
+	messages  repository.MessageRepository
+	producer  queue.Producer
+	now       func() time.Time
+	logger    utils.Logger
+}
+
+// NewQuietHoursScheduler creates a quiet hours scheduler. The recipient's timezone is the one
+// given with the message, or else inferred from the phone number's country code; messages whose
+// timezone cannot be inferred are never deferred.
+func NewQuietHoursScheduler(cfg QuietHoursConfig, messages repository.MessageRepository, producer queue.Producer, logger utils.Logger) (QuietHoursScheduler, error) {
+	s := &quietHoursScheduler{
+		tenants:   make(map[string]*domain.QuietHours, len(cfg.Tenants)),
+		marketing: make(map[string]bool, len(cfg.MarketingTemplates)),
+		messages:  messages,
+		producer:  producer,
+		now:       time.Now,
+		logger:    logger,
+	}
+
+	if cfg.Default != "" {
+		window, err := domain.ParseQuietHours(cfg.Default)
+		if err != nil {
+			return nil, err
+		}
+		s.fallback = &window
+	}
+	for tenantID, value := range cfg.Tenants {
+		if value == quietHoursOff {
+			s.tenants[tenantID] = nil
+			continue
+		}
+		window, err := domain.ParseQuietHours(value)
+		if err != nil {
+			return nil, err
+		}
+		s.tenants[tenantID] = &window
+	}
+	for _, templateID := range cfg.MarketingTemplates {
+		s.marketing[templateID] = true
+	}
+
+	return s, nil
+}
+
+// window returns the quiet hours of a tenant, or nil when it has none
+func (s *quietHoursScheduler) window(tenantID string) *domain.QuietHours {
+	if window, ok := s.tenants[tenantID]; ok {
+		return window
+	}
+	return s.fallback
+}
+
+// location resolves the recipient's timezone
+func (s *quietHoursScheduler) location(phoneNumber, timezone string) (*time.Location, bool) {
+	if timezone == "" {
+		inferred, ok := domain.TimezoneForPhone(phoneNumber)
+		if !ok {
+			return nil, false
+		}
+		timezone = inferred
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		s.logger.Warn("Unknown recipient timezone", "timezone", timezone, "error", err)
+		return nil, false
+	}
+	return loc, true
+}
+
+// DeferUntil applies the tenant's window in the recipient's local time
+func (s *quietHoursScheduler) DeferUntil(tenantID, templateID, phoneNumber, timezone string, now time.Time) (time.Time, bool) {
+	if !s.marketing[templateID] {
+		return time.Time{}, false
+	}
+	window := s.window(tenantID)
+	if window == nil {
+		return time.Time{}, false
+	}
+	loc, ok := s.location(phoneNumber, timezone)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	if !window.Contains(local) {
+		return time.Time{}, false
+	}
+	return window.NextAllowed(local), true
+}
+
+// Release re-enqueues due deferred messages in batches
+func (s *quietHoursScheduler) Release(ctx context.Context) (int64, error) {
+	var released int64
+	for {
+		now := s.now()
+		messages, err := s.messages.ReleaseDueMessages(ctx, now, releaseBatchSize)
+		if err != nil {
+			return released, err
+		}
+
+		for i, msg := range messages {
+			if err := s.enqueue(ctx, msg); err != nil {
+				// Defer the rest again so the next release picks them up
+				for _, rest := range messages[i:] {
+					if deferErr := s.messages.DeferMessage(ctx, rest.ID, now); deferErr != nil {
+						s.logger.Error("Failed to defer message again", "error", deferErr, "message_id", rest.ID)
+					}
+				}
+				return released, err
+			}
+			released++
+		}
+
+		if len(messages) < releaseBatchSize {
+			return released, nil
+		}
+	}
+}
+
+// enqueue produces a released message to the send queue
+func (s *quietHoursScheduler) enqueue(ctx context.Context, msg *domain.Message) error {
+	data, err := EncodeQueueMessage(newQueueMessage(msg))
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(queue.WithMessageID(ctx, msg.ID), data)
+}
+
+// Run releases due messages immediately and then every interval
+func (s *quietHoursScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if released, err := s.Release(ctx); err != nil {
+			s.logger.Error("Failed to release deferred messages", "error", err)
+		} else if released > 0 {
+			s.logger.Info("Released messages deferred by quiet hours", "count", released)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// quietHoursMessageService defers queued marketing messages out of their recipient's quiet hours
+type quietHoursMessageService struct {
+	MessageService
+	scheduler QuietHoursScheduler
+	repo      repository.MessageRepository
+	now       func() time.Time
+	logger    utils.Logger
+}
+
+// NewQuietHoursMessageService wraps a message service so the queue consumer defers marketing
+// messages that arrive in quiet hours instead of sending them
+func NewQuietHoursMessageService(inner MessageService, scheduler QuietHoursScheduler, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &quietHoursMessageService{
+		MessageService: inner,
+		scheduler:      scheduler,
+		repo:           repo,
+		now:            time.Now,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage rejects explicit recipient timezones that are not IANA names
+func (s *quietHoursMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if tz := domain.RecipientTimezoneFromContext(ctx); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, domain.NewError(domain.ErrValidation, "unknown recipient timezone %q", tz)
+		}
+	}
+	return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+}
+
+// ProcessQueueMessage defers the message if it falls in quiet hours and sends it otherwise
+func (s *quietHoursMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
+		return err
+	}
+
+	// Envelopes queued before the tenant was included need a lookup
+	tenantID := queueMsg.TenantID
+	if tenantID == "" {
+		msg, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), queueMsg.MessageID)
+		if err != nil {
+			s.logger.Error("Failed to get message from database", "error", err)
+			return err
+		}
+		tenantID = msg.TenantID
+	}
+
+	until, deferred := s.scheduler.DeferUntil(tenantID, queueMsg.TemplateID, queueMsg.PhoneNumber, queueMsg.RecipientTimezone, s.now())
+	if !deferred {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	if err := s.repo.DeferMessage(ctx, queueMsg.MessageID, until); err != nil {
+		s.logger.Error("Failed to defer message", "error", err, "message_id", queueMsg.MessageID)
+		return err
+	}
+	deferredMessagesTotal.WithLabelValues(tenantID).Inc()
+	s.logger.Info("Deferring marketing message out of quiet hours", "message_id", queueMsg.MessageID, "until", until)
+	return nil
+}
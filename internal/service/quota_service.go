@@ -0,0 +1,268 @@
+// internal/service/quota_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// quotaExceededTotal counts sends refused because a quota was used up
+var quotaExceededTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_quota_exceeded_total",
+	Help: "Sends refused because a monthly quota was used up.",
+}, []string{"scope"})
+
+// QuotaPolicy sets the monthly message quotas; a zero limit means unlimited
+type QuotaPolicy struct {
+	// TenantDefault applies to tenants without an entry in Tenants
+	TenantDefault int64
+	// CustomerDefault applies to customers without an entry in Customers
+	CustomerDefault int64
+	// Tenants overrides the limit per tenant ID
+	Tenants map[string]int64
+	// Customers overrides the limit per customer ID
+	Customers map[string]int64
+}
+
+// tenantLimit returns the monthly limit of a tenant
+func (p QuotaPolicy) tenantLimit(tenantID string) int64 {
+	if limit, ok := p.Tenants[tenantID]; ok {
+		return limit
+	}
+	return p.TenantDefault
+}
+
+// customerLimit returns the monthly limit of a customer
+func (p QuotaPolicy) customerLimit(customerID string) int64 {
+	if limit, ok := p.Customers[customerID]; ok {
+		return limit
+	}
+	return p.CustomerDefault
+}
+
+// Enabled reports whether any quota is configured
+func (p QuotaPolicy) Enabled() bool {
+	if p.TenantDefault > 0 || p.CustomerDefault > 0 {
+		return true
+	}
+	for _, limit := range p.Tenants {
+		if limit > 0 {
+			return true
+		}
+	}
+	for _, limit := range p.Customers {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotaService enforces monthly message quotas per tenant and customer
+type QuotaService interface {
+	// Reserve counts one message against the tenant's and customer's quotas, or returns
+	// domain.ErrQuotaExceeded without counting anything
+	Reserve(ctx context.Context, tenantID, customerID string) error
+	// Release gives back a message counted by Reserve that was never sent
+	Release(ctx context.Context, tenantID, customerID string) error
+	// Usage reports the quotas that apply to the tenant and, if given, the customer
+	Usage(ctx context.Context, tenantID, customerID string) ([]domain.QuotaUsage, error)
+}
+
+// quotaService implements QuotaService
+type quotaService struct {
+	repo   repository.QuotaRepository
+	policy QuotaPolicy
+	now    func() time.Time
+	logger utils.Logger
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(repo repository.QuotaRepository, policy QuotaPolicy, logger utils.Logger) QuotaService {
+	return &quotaService{
+		repo:   repo,
+		policy: policy,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// quotaSubject is one quota a send counts against
+type quotaSubject struct {
+	scope   string
+	subject string
+	limit   int64
+}
+
+// subjects returns the limited quotas that apply; customer IDs are only unique within a tenant
+func (s *quotaService) subjects(tenantID, customerID string) []quotaSubject {
+	var subjects []quotaSubject
+	if limit := s.policy.tenantLimit(tenantID); limit > 0 {
+		subjects = append(subjects, quotaSubject{domain.QuotaScopeTenant, tenantID, limit})
+	}
+	if customerID != "" {
+		if limit := s.policy.customerLimit(customerID); limit > 0 {
+			subjects = append(subjects, quotaSubject{domain.QuotaScopeCustomer, tenantID + "/" + customerID, limit})
+		}
+	}
+	return subjects
+}
+
+// Reserve consumes each quota in turn and rolls back the ones already taken if a later one is full
+func (s *quotaService) Reserve(ctx context.Context, tenantID, customerID string) error {
+	period, _ := domain.QuotaPeriod(s.now())
+
+	var taken []quotaSubject
+	for _, q := range s.subjects(tenantID, customerID) {
+		used, ok, err := s.repo.ConsumeQuota(ctx, q.scope, q.subject, period, q.limit)
+		if err == nil && ok {
+			taken = append(taken, q)
+			continue
+		}
+
+		for _, t := range taken {
+			if releaseErr := s.repo.ReleaseQuota(ctx, t.scope, t.subject, period); releaseErr != nil {
+				s.logger.Error("Failed to release quota", "error", releaseErr, "scope", t.scope, "subject", t.subject)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		quotaExceededTotal.WithLabelValues(q.scope).Inc()
+		return domain.NewError(domain.ErrQuotaExceeded, "%s quota of %d messages per month used up (%d used)", q.scope, q.limit, used)
+	}
+	return nil
+}
+
+// Release gives back one message on every quota that applies
+func (s *quotaService) Release(ctx context.Context, tenantID, customerID string) error {
+	period, _ := domain.QuotaPeriod(s.now())
+
+	for _, q := range s.subjects(tenantID, customerID) {
+		if err := s.repo.ReleaseQuota(ctx, q.scope, q.subject, period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Usage returns the current period's usage of every quota that applies
+func (s *quotaService) Usage(ctx context.Context, tenantID, customerID string) ([]domain.QuotaUsage, error) {
+	start, end := domain.QuotaPeriod(s.now())
+
+	subjects := s.subjects(tenantID, customerID)
+	usage := make([]domain.QuotaUsage, 0, len(subjects))
+	for _, q := range subjects {
+		used, err := s.repo.GetQuotaUsage(ctx, q.scope, q.subject, start)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, domain.QuotaUsage{
+			Scope:       q.scope,
+			Subject:     q.subject,
+			Limit:       q.limit,
+			Used:        used,
+			PeriodStart: start,
+			ResetsAt:    end,
+		})
+	}
+	return usage, nil
+}
+
+// quotaEnforcingMessageService checks quotas before handing sends to the wrapped service
+type quotaEnforcingMessageService struct {
+	MessageService
+	quotas         QuotaService
+	repo           repository.MessageRepository
+	recordExceeded bool
+	logger         utils.Logger
+}
+
+// NewQuotaEnforcingMessageService wraps a message service with quota enforcement. Sends over
+// quota are rejected with domain.ErrQuotaExceeded, or with recordExceeded stored with status
+// quota_exceeded and returned without being sent.
+func NewQuotaEnforcingMessageService(inner MessageService, quotas QuotaService, repo repository.MessageRepository, recordExceeded bool, logger utils.Logger) MessageService {
+	return &quotaEnforcingMessageService{
+		MessageService: inner,
+		quotas:         quotas,
+		repo:           repo,
+		recordExceeded: recordExceeded,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage reserves quota before sending and gives it back if the send is not accepted
+func (s *quotaEnforcingMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	tenantID := domain.TenantFromContext(ctx)
+
+	err := s.quotas.Reserve(ctx, tenantID, customerID)
+	if err != nil {
+		if !s.recordExceeded || !errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, err
+		}
+		return s.recordQuotaExceeded(ctx, phoneNumber, templateID, parameters, orderID, customerID, err)
+	}
+
+	msg, err := s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+	if err != nil {
+		if releaseErr := s.quotas.Release(ctx, tenantID, customerID); releaseErr != nil {
+			s.logger.Error("Failed to release quota", "error", releaseErr, "tenant_id", tenantID)
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// RetryMessage counts a retry against the quotas of the retried message's tenant and customer
+func (s *quotaEnforcingMessageService) RetryMessage(ctx context.Context, id int64, phoneNumber string, parameters map[string]interface{}) (*domain.Message, error) {
+	original, err := s.repo.GetMessageByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.quotas.Reserve(ctx, original.TenantID, original.CustomerID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.MessageService.RetryMessage(ctx, id, phoneNumber, parameters)
+	if err != nil {
+		if releaseErr := s.quotas.Release(ctx, original.TenantID, original.CustomerID); releaseErr != nil {
+			s.logger.Error("Failed to release quota", "error", releaseErr, "tenant_id", original.TenantID)
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// recordQuotaExceeded stores the refused send so callers can see and retry it later
+func (s *quotaEnforcingMessageService) recordQuotaExceeded(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string, cause error) (*domain.Message, error) {
+	now := time.Now()
+	msg := &domain.Message{
+		PhoneNumber:  phoneNumber,
+		TemplateID:   templateID,
+		Parameters:   parameters,
+		OrderID:      orderID,
+		CustomerID:   customerID,
+		TenantID:     domain.TenantFromContext(ctx),
+		Status:       domain.StatusQuotaExceeded,
+		ErrorMessage: cause.Error(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	id, err := s.repo.CreateMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.ID = id
+	return msg, nil
+}
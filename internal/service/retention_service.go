@@ -0,0 +1,220 @@
+// internal/service/retention_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// retentionPurgedRows counts rows removed by the retention job
+var retentionPurgedRows = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_retention_purged_rows_total",
+	Help: "Rows removed by the data retention job.",
+}, []string{"table"})
+
+// RetentionPolicy says how long data is kept
+type RetentionPolicy struct {
+	// MessageMaxAge is how long messages, and their status history, are kept after creation;
+	// zero keeps them forever
+	MessageMaxAge time.Duration
+	// WebhookEventMaxAge is how long inbound messages delivered by webhooks are kept; zero
+	// keeps them forever
+	WebhookEventMaxAge time.Duration
+	// OutboxMaxAge is how long queue payloads are kept once published; zero keeps them forever
+	OutboxMaxAge time.Duration
+	// ProviderCaptureMaxAge is how long provider captures are kept; zero keeps them forever
+	ProviderCaptureMaxAge time.Duration
+	// WebhookFailureMaxAge is how long failed webhook changes are kept, replayed or not; zero
+	// keeps them forever
+	WebhookFailureMaxAge time.Duration
+	// BatchSize bounds the rows removed per statement
+	BatchSize int
+	// PartitionMonthsAhead is how many months of message partitions are created in advance
+	PartitionMonthsAhead int
+}
+
+// RetentionService enforces the retention policy
+type RetentionService interface {
+	// PurgeExpired removes all data older than the policy allows and returns the rows removed
+	PurgeExpired(ctx context.Context) (int64, error)
+	// RotatePartitions creates the message partitions for the current and upcoming months
+	RotatePartitions(ctx context.Context) error
+	// Run rotates partitions and purges expired data every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// RetentionStores are the stores purged besides messages; nil stores are skipped
+type RetentionStores struct {
+	Captures        repository.ProviderCaptureRepository
+	WebhookFailures repository.WebhookFailureRepository
+	Inbound         repository.ConversationRepository
+	Outbox          repository.OutboxRepository
+	History         repository.StatusHistoryRepository
+}
+
+// purgeFunc deletes up to limit rows older than cutoff and returns how many it deleted
+type purgeFunc func(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+
+// expiringTable is a table purged of the rows older than maxAge
+type expiringTable struct {
+	name   string
+	maxAge time.Duration
+	purge  purgeFunc
+}
+
+// retentionService implements RetentionService
+type retentionService struct {
+	repo       repository.MessageRepository
+	partitions repository.PartitionRepository
+	stores     RetentionStores
+	policy     RetentionPolicy
+	now        func() time.Time
+	logger     utils.Logger
+}
+
+// NewRetentionService creates a new retention service; partitions may be nil when the
+// messages table is not partitioned
+func NewRetentionService(repo repository.MessageRepository, partitions repository.PartitionRepository, policy RetentionPolicy, logger utils.Logger) RetentionService {
+	return NewRetentionServiceWithStores(repo, partitions, RetentionStores{}, policy, logger)
+}
+
+// NewRetentionServiceWithStores creates a retention service also purging stores
+func NewRetentionServiceWithStores(repo repository.MessageRepository, partitions repository.PartitionRepository, stores RetentionStores, policy RetentionPolicy, logger utils.Logger) RetentionService {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 1000
+	}
+	return &retentionService{
+		repo:       repo,
+		partitions: partitions,
+		stores:     stores,
+		policy:     policy,
+		now:        time.Now,
+		logger:     logger,
+	}
+}
+
+// RotatePartitions creates partitions ahead of time so new messages never land in the
+// default partition
+func (s *retentionService) RotatePartitions(ctx context.Context) error {
+	if s.partitions == nil {
+		return nil
+	}
+	return s.partitions.EnsureMessagePartitions(ctx, s.now(), s.policy.PartitionMonthsAhead)
+}
+
+// PurgeExpired purges the messages, then every other store, past their max age
+func (s *retentionService) PurgeExpired(ctx context.Context) (int64, error) {
+	total, err := s.purgeMessages(ctx)
+	if err != nil {
+		return total, err
+	}
+
+	for _, table := range s.expiringTables() {
+		if table.maxAge <= 0 {
+			continue
+		}
+		purged, err := s.purgeBatches(ctx, table.name, table.maxAge, table.purge)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// expiringTables lists the tables of the configured stores. Status history is kept as long as
+// messages: an entry older than MessageMaxAge belongs to a message that has been purged.
+func (s *retentionService) expiringTables() []expiringTable {
+	var tables []expiringTable
+	if s.stores.History != nil {
+		tables = append(tables, expiringTable{"message_status_history", s.policy.MessageMaxAge, s.stores.History.PurgeStatusHistoryBefore})
+	}
+	if s.stores.Outbox != nil {
+		tables = append(tables, expiringTable{"message_outbox", s.policy.OutboxMaxAge, s.stores.Outbox.PurgePublishedOutboxBefore})
+	}
+	if s.stores.Inbound != nil {
+		tables = append(tables, expiringTable{"inbound_messages", s.policy.WebhookEventMaxAge, s.stores.Inbound.PurgeInboundBefore})
+	}
+	if s.stores.Captures != nil {
+		tables = append(tables, expiringTable{"provider_captures", s.policy.ProviderCaptureMaxAge, s.stores.Captures.PurgeProviderCapturesBefore})
+	}
+	if s.stores.WebhookFailures != nil {
+		tables = append(tables, expiringTable{"webhook_failures", s.policy.WebhookFailureMaxAge, s.stores.WebhookFailures.PurgeWebhookFailuresBefore})
+	}
+	return tables
+}
+
+// purgeMessages drops partitions that are wholly expired, then deletes the remaining
+// expired messages in batches until none are left
+func (s *retentionService) purgeMessages(ctx context.Context) (int64, error) {
+	if s.policy.MessageMaxAge <= 0 {
+		return 0, nil
+	}
+
+	var total int64
+	if s.partitions != nil {
+		dropped, err := s.partitions.DropMessagePartitionsBefore(ctx, s.now().Add(-s.policy.MessageMaxAge))
+		total += dropped
+		retentionPurgedRows.WithLabelValues("messages").Add(float64(dropped))
+		if err != nil {
+			return total, err
+		}
+	}
+
+	purged, err := s.purgeBatches(ctx, "messages", s.policy.MessageMaxAge, s.repo.PurgeMessagesBefore)
+	return total + purged, err
+}
+
+// purgeBatches deletes the rows of table older than maxAge with purge, a batch at a time
+// until a short batch
+func (s *retentionService) purgeBatches(ctx context.Context, table string, maxAge time.Duration, purge purgeFunc) (int64, error) {
+	cutoff := s.now().Add(-maxAge)
+	var total int64
+	for {
+		purged, err := purge(ctx, cutoff, s.policy.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		retentionPurgedRows.WithLabelValues(table).Add(float64(purged))
+
+		if purged < int64(s.policy.BatchSize) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Purged expired rows", "table", table, "rows", total, "cutoff", cutoff)
+	}
+	return total, nil
+}
+
+// Run rotates partitions and purges expired data every interval until ctx is done
+func (s *retentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RotatePartitions(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Message partition rotation failed", "error", err)
+		}
+		if _, err := s.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Retention purge failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// internal/service/send_retry.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/providerrouter"
+	"messaging-microservice/pkg/utils"
+)
+
+// IsTransientSendError reports whether a queued send failed for a reason that may clear up by
+// itself: the provider rate limited the send or was unavailable, the request never got a
+// response, or the circuit breaker held it back. Rejections of the message itself are final.
+func IsTransientSendError(err error) bool {
+	if errors.Is(err, domain.ErrProviderRateLimited) || errors.Is(err, domain.ErrProviderUnavailable) {
+		return true
+	}
+	if errors.Is(err, providerrouter.ErrCircuitOpen) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// NewSendRetryConfig returns the retry tier settings of the send topic. A message waiting in
+// a retry topic is marked retrying, so it is not retried by hand at the same time, and marked
+// failed for good once it is dead-lettered.
+func NewSendRetryConfig(delays []time.Duration, repo repository.MessageRepository, logger utils.Logger) queue.RetryConfig {
+	mark := func(ctx context.Context, data []byte, status, errorMessage string) {
+		queueMsg, err := DecodeQueueMessage(data)
+		if err != nil {
+			return
+		}
+		if err := repo.UpdateMessageStatus(ctx, queueMsg.MessageID, status, "", errorMessage, ""); err != nil {
+			logger.Error("Failed to update message status", "error", err, "message_id", queueMsg.MessageID)
+		}
+	}
+
+	return queue.RetryConfig{
+		Delays:      delays,
+		IsRetryable: IsTransientSendError,
+		OnRetry: func(ctx context.Context, data []byte, delay time.Duration, err error) {
+			mark(ctx, data, domain.StatusRetrying, fmt.Sprintf("retrying in %s after: %v", delay, err))
+		},
+		OnDeadLetter: func(ctx context.Context, data []byte, err error) {
+			mark(ctx, data, "failed", fmt.Sprintf("gave up after %d retries: %v", len(delays), err))
+		},
+	}
+}
@@ -0,0 +1,188 @@
+// internal/service/status_hooks.go
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// StatusHook is told about every stored status transition of an outbound message, whether
+// made by the send path, an admin control or a provider status callback. Hooks run
+// synchronously after the status is stored, so slow work such as a CRM sync should be handed
+// off to a goroutine or queue.
+type StatusHook interface {
+	// OnStatusChange is called with the message as stored after the transition; oldStatus is
+	// empty when the message was just created
+	OnStatusChange(ctx context.Context, msg *domain.Message, oldStatus, newStatus string)
+}
+
+// StatusHookFunc adapts a function to StatusHook
+type StatusHookFunc func(ctx context.Context, msg *domain.Message, oldStatus, newStatus string)
+
+// OnStatusChange calls f
+func (f StatusHookFunc) OnStatusChange(ctx context.Context, msg *domain.Message, oldStatus, newStatus string) {
+	f(ctx, msg, oldStatus, newStatus)
+}
+
+// StatusHooks is the set of hooks a deployment registers, itself a StatusHook calling each
+// in registration order
+type StatusHooks struct {
+	mu     sync.RWMutex
+	hooks  []StatusHook
+	logger utils.Logger
+}
+
+// NewStatusHooks creates an empty hook set
+func NewStatusHooks(logger utils.Logger) *StatusHooks {
+	return &StatusHooks{logger: logger}
+}
+
+// Register adds a hook
+func (h *StatusHooks) Register(hook StatusHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// Len returns the number of registered hooks
+func (h *StatusHooks) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.hooks)
+}
+
+// OnStatusChange calls every hook; a panicking hook is logged and the rest still run
+func (h *StatusHooks) OnStatusChange(ctx context.Context, msg *domain.Message, oldStatus, newStatus string) {
+	h.mu.RLock()
+	hooks := h.hooks
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		h.call(ctx, hook, msg, oldStatus, newStatus)
+	}
+}
+
+// call runs one hook, recovering from a panic in it
+func (h *StatusHooks) call(ctx context.Context, hook StatusHook, msg *domain.Message, oldStatus, newStatus string) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Error("Status hook panicked", "panic", r, "message_id", msg.ID, "status", newStatus)
+		}
+	}()
+	hook.OnStatusChange(ctx, msg, oldStatus, newStatus)
+}
+
+// statusTransitionsTotal counts status transitions seen by StatusTransitionMetrics
+var statusTransitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_status_transitions_total",
+	Help: "Message status transitions, by previous and new status; from is empty for new messages.",
+}, []string{"from", "to"})
+
+// StatusTransitionMetrics is a hook counting transitions in whatsapp_status_transitions_total
+var StatusTransitionMetrics StatusHook = StatusHookFunc(func(_ context.Context, _ *domain.Message, oldStatus, newStatus string) {
+	statusTransitionsTotal.WithLabelValues(oldStatus, newStatus).Inc()
+})
+
+// statusHookedRepository reports the status transitions it stores to a hook
+type statusHookedRepository struct {
+	repository.MessageRepository
+	hook   StatusHook
+	logger utils.Logger
+}
+
+// NewStatusHookedRepository wraps a message repository so every status update that changes a
+// message's status is reported to hook. Each transition costs a read of the message, so only
+// wrap the repository when hooks are registered. Transitions made in a unit of work are
+// reported once it commits.
+func NewStatusHookedRepository(inner repository.MessageRepository, hook StatusHook, logger utils.Logger) repository.MessageRepository {
+	return &statusHookedRepository{
+		MessageRepository: inner,
+		hook:              hook,
+		logger:            logger,
+	}
+}
+
+// CreateMessage reports a new message as a transition from no status
+func (r *statusHookedRepository) CreateMessage(ctx context.Context, message *domain.Message) (int64, error) {
+	id, err := r.MessageRepository.CreateMessage(ctx, message)
+	if err != nil {
+		return 0, err
+	}
+	created := *message
+	created.ID = id
+	repository.AfterCommit(ctx, func(ctx context.Context) { r.hook.OnStatusChange(ctx, &created, "", created.Status) })
+	return id, nil
+}
+
+// CreateMessages reports each new message as a transition from no status
+func (r *statusHookedRepository) CreateMessages(ctx context.Context, messages []*domain.Message) ([]int64, error) {
+	ids, err := r.MessageRepository.CreateMessages(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	for i, message := range messages {
+		created := *message
+		created.ID = ids[i]
+		repository.AfterCommit(ctx, func(ctx context.Context) { r.hook.OnStatusChange(ctx, &created, "", created.Status) })
+	}
+	return ids, nil
+}
+
+// UpdateMessageStatus reads the message's status before updating it. A message that cannot be
+// read is still updated; only its hooks are skipped.
+func (r *statusHookedRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error {
+	msg, readErr := r.MessageRepository.GetMessageByID(repository.WithPrimary(ctx), id)
+	if err := r.MessageRepository.UpdateMessageStatus(ctx, id, status, errorCode, errorMessage, externalID); err != nil {
+		return err
+	}
+	if readErr != nil {
+		r.logger.Error("Failed to read message for status hooks", "error", readErr, "message_id", id)
+		return nil
+	}
+	if msg.Status == status {
+		return nil
+	}
+
+	oldStatus := msg.Status
+	msg.Status = status
+	if errorCode != "" {
+		msg.ErrorCode = errorCode
+	}
+	if errorMessage != "" {
+		msg.ErrorMessage = errorMessage
+	}
+	if externalID != "" {
+		msg.ExternalID = externalID
+	}
+	repository.AfterCommit(ctx, func(ctx context.Context) { r.hook.OnStatusChange(ctx, msg, oldStatus, status) })
+	return nil
+}
+
+// UpdateMessageStatuses reports each message whose status the batch changed, once, from its
+// status before the batch to the one after it
+func (r *statusHookedRepository) UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (map[int64]domain.StatusUpdateResult, error) {
+	results, err := r.MessageRepository.UpdateMessageStatuses(ctx, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, result := range results {
+		if result.PreviousStatus == result.Status {
+			continue
+		}
+		msg, err := r.MessageRepository.GetMessageByID(repository.WithPrimary(ctx), id)
+		if err != nil {
+			r.logger.Error("Failed to read message for status hooks", "error", err, "message_id", id)
+			continue
+		}
+		repository.AfterCommit(ctx, func(ctx context.Context) { r.hook.OnStatusChange(ctx, msg, result.PreviousStatus, result.Status) })
+	}
+	return results, nil
+}
@@ -0,0 +1,125 @@
+// internal/service/status_quarantine.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+)
+
+// quarantineBatch is how many quarantined statuses one match run looks up at most
+const quarantineBatch = 500
+
+var quarantinedStatusesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_webhook_quarantined_statuses_total",
+	Help: "Statuses for unknown external IDs by provider and result (quarantined, matched, expired).",
+}, []string{"provider", "result"})
+
+// quarantineStatus keeps a status whose external ID no message has, to match it again. Without a
+// quarantine the status is dropped.
+func (s *webhookService) quarantineStatus(ctx context.Context, status *domain.QuarantinedStatus) error {
+	if s.quarantine == nil {
+		s.logger.Warn("Received status for unknown message", "external_id", status.ExternalID, "tenant_id", status.TenantID)
+		return nil
+	}
+	if err := s.quarantine.QuarantineStatus(ctx, status); err != nil {
+		return err
+	}
+	quarantinedStatusesTotal.WithLabelValues(status.Provider, "quarantined").Inc()
+	s.logger.Debug("Quarantined status for unknown message", "provider", status.Provider, "external_id", status.ExternalID, "tenant_id", status.TenantID)
+	return nil
+}
+
+// MatchQuarantined drops the statuses quarantined before the window, then applies the oldest
+// others whose message is now known, in the order they arrived
+func (s *webhookService) MatchQuarantined(ctx context.Context) (int, error) {
+	if s.quarantine == nil {
+		return 0, nil
+	}
+
+	expired, err := s.quarantine.ExpireQuarantinedStatuses(ctx, time.Now().Add(-s.window))
+	if err != nil {
+		return 0, err
+	}
+	for provider, count := range expired {
+		quarantinedStatusesTotal.WithLabelValues(provider, "expired").Add(float64(count))
+		s.logger.Warn("Dropped quarantined statuses no message claimed", "provider", provider, "count", count, "window", s.window)
+	}
+
+	statuses, err := s.quarantine.ListQuarantinedStatuses(ctx, quarantineBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var matched []int64
+	for _, status := range statuses {
+		tenantCtx := domain.WithTenant(ctx, status.TenantID)
+		var messageID int64
+		messageID, err = s.repo.GetMessageIDByExternalID(tenantCtx, status.TenantID, status.ExternalID)
+		if errors.Is(err, domain.ErrNotFound) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			break
+		}
+
+		err = s.applyStatuses(tenantCtx, []domain.StatusUpdate{{
+			MessageID:    messageID,
+			Status:       status.Status,
+			ErrorCode:    status.ErrorCode,
+			ErrorMessage: status.ErrorMessage,
+			ExternalID:   status.ExternalID,
+			At:           status.At,
+		}}, []WebhookEvent{{
+			MessageID:    messageID,
+			TenantID:     status.TenantID,
+			ExternalID:   status.ExternalID,
+			Status:       status.Status,
+			ErrorCode:    status.ErrorCode,
+			ErrorMessage: status.ErrorMessage,
+			PhoneNumber:  status.PhoneNumber,
+			Timestamp:    status.Timestamp,
+			DedupeKey:    status.DedupeKey,
+		}})
+		if err != nil {
+			break
+		}
+		matched = append(matched, status.ID)
+		quarantinedStatusesTotal.WithLabelValues(status.Provider, "matched").Inc()
+	}
+
+	// Statuses applied before a failure are removed all the same
+	if deleteErr := s.quarantine.DeleteQuarantinedStatuses(ctx, matched); deleteErr != nil {
+		return 0, errors.Join(err, deleteErr)
+	}
+	return len(matched), err
+}
+
+// RunQuarantine matches quarantined statuses now and then every interval until ctx is done
+func (s *webhookService) RunQuarantine(ctx context.Context, interval time.Duration) {
+	if s.quarantine == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if matched, err := s.MatchQuarantined(ctx); err != nil {
+			s.logger.Error("Failed to match quarantined statuses", "error", err)
+		} else if matched > 0 {
+			s.logger.Info("Matched quarantined statuses", "count", matched)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
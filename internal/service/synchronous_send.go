@@ -0,0 +1,81 @@
+// internal/service/synchronous_send.go
+package service
+
+import (
+	"context"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// inlineSend is how a synchronous send learns which message was stored for it
+type inlineSend struct {
+	messageID int64
+}
+
+type inlineSendContextKey struct{}
+
+// inlineSendFromContext returns the inline send a new message is stored for, or nil when the
+// message is to be queued
+func inlineSendFromContext(ctx context.Context) *inlineSend {
+	inline, _ := ctx.Value(inlineSendContextKey{}).(*inlineSend)
+	return inline
+}
+
+// synchronousMessageService sends messages asked for with domain.WithSynchronous inline
+type synchronousMessageService struct {
+	MessageService
+	repo   repository.MessageRepository
+	logger utils.Logger
+}
+
+// NewSynchronousMessageService wraps the outermost message service so synchronous sends skip
+// Kafka: the message is stored, then handed to ProcessQueueMessage as the consumer would, so
+// pauses, quiet hours and the other checks of queued messages still apply, all within the
+// call's deadline
+func NewSynchronousMessageService(inner MessageService, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &synchronousMessageService{
+		MessageService: inner,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage returns a synchronous send once the provider accepted it, with its
+// external ID. A message held by a pause or deferred by quiet hours is returned as it was left,
+// to be sent later; one failed by a check of queued messages is an error.
+func (s *synchronousMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if !domain.SynchronousFromContext(ctx) {
+		return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+	}
+
+	inline := &inlineSend{}
+	msg, err := s.MessageService.SendTemplateMessage(context.WithValue(ctx, inlineSendContextKey{}, inline), phoneNumber, templateID, parameters, orderID, customerID)
+	// Duplicates and messages recorded over quota come back as they are, and are not sent again
+	if err != nil || inline.messageID != msg.ID {
+		return msg, err
+	}
+
+	data, err := EncodeQueueMessage(newQueueMessage(msg))
+	if err != nil {
+		s.logger.Error("Failed to marshal queue message", "error", err, "message_id", msg.ID)
+		return nil, err
+	}
+	if err := s.MessageService.ProcessQueueMessage(ctx, data); err != nil {
+		return nil, err
+	}
+
+	// The provider has the message whatever happens to the caller, so the read outlives its deadline
+	readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliveryReportTimeout)
+	defer cancel()
+	sent, err := s.repo.GetMessageByID(repository.WithPrimary(readCtx), msg.ID)
+	if err != nil {
+		s.logger.Error("Failed to get message from database", "error", err, "message_id", msg.ID)
+		return nil, err
+	}
+	if sent.Status == "failed" {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "message %d was not sent: %s", msg.ID, sent.ErrorMessage)
+	}
+	return sent, nil
+}
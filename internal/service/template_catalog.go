@@ -0,0 +1,85 @@
+// internal/service/template_catalog.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// TemplateCatalog keeps the localized template bodies Twilio sends without a content template
+// in sync with the catalog file and the template_translations table. Translations stored in
+// the database override the file's.
+type TemplateCatalog interface {
+	// Refresh reloads the file and the stored translations into the catalog
+	Refresh(ctx context.Context) error
+	// Run refreshes the catalog every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// templateCatalog implements TemplateCatalog
+type templateCatalog struct {
+	repo    repository.TemplateTranslationRepository
+	file    string
+	catalog *twilio.Catalog
+	logger  utils.Logger
+}
+
+// NewTemplateCatalog creates a loader filling catalog from file, when set, and repo, when not
+// nil
+func NewTemplateCatalog(repo repository.TemplateTranslationRepository, file string, catalog *twilio.Catalog, logger utils.Logger) TemplateCatalog {
+	return &templateCatalog{
+		repo:    repo,
+		file:    file,
+		catalog: catalog,
+		logger:  logger,
+	}
+}
+
+// Refresh swaps in the current bodies; on any error the catalog keeps its previous ones
+func (c *templateCatalog) Refresh(ctx context.Context) error {
+	var entries []twilio.CatalogEntry
+	if c.file != "" {
+		fileEntries, err := twilio.LoadCatalogFile(c.file)
+		if err != nil {
+			return err
+		}
+		entries = fileEntries
+	}
+	if c.repo != nil {
+		translations, err := c.repo.ListTemplateTranslations(ctx)
+		if err != nil {
+			return err
+		}
+		// Later entries of a template and locale replace earlier ones
+		for _, translation := range translations {
+			entries = append(entries, twilio.CatalogEntry{
+				TemplateName: translation.TemplateID,
+				Locale:       translation.Locale,
+				Body:         translation.Body,
+			})
+		}
+	}
+	return c.catalog.Replace(entries)
+}
+
+// Run refreshes the catalog immediately and then every interval
+func (c *templateCatalog) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil {
+			c.logger.Error("Failed to refresh template catalog", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// internal/service/template_events.go
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/utils"
+)
+
+// metaTemplateActor is the actor of templates disabled because Meta paused or disabled them
+const metaTemplateActor = "meta"
+
+// templateEventsTotal counts template status and quality events received
+var templateEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_template_events_total",
+	Help: "Template status and quality webhook events received, by event.",
+}, []string{"event"})
+
+// TemplateEventService applies the template status and quality changes Meta reports
+type TemplateEventService interface {
+	// HandleTemplateEvent disables a template Meta paused or disabled, enables it again once Meta
+	// reinstates it, and notifies the template's owners
+	HandleTemplateEvent(ctx context.Context, event domain.TemplateEvent) error
+}
+
+// templateEventService implements TemplateEventService
+type templateEventService struct {
+	templates TemplateSwitch
+	notifier  alerts.Notifier
+	logger    utils.Logger
+}
+
+// NewTemplateEventService creates a new template event service. Alerts go to notifier; a nil
+// notifier only logs them.
+func NewTemplateEventService(templates TemplateSwitch, notifier alerts.Notifier, logger utils.Logger) TemplateEventService {
+	return &templateEventService{
+		templates: templates,
+		notifier:  notifier,
+		logger:    logger,
+	}
+}
+
+// HandleTemplateEvent applies a status or quality change
+func (s *templateEventService) HandleTemplateEvent(ctx context.Context, event domain.TemplateEvent) error {
+	if event.TemplateID == "" {
+		s.logger.Warn("Received template event without a template name", "event", event.Event)
+		return nil
+	}
+
+	if event.Field == domain.TemplateFieldQualityUpdate {
+		templateEventsTotal.WithLabelValues("QUALITY_" + event.Quality).Inc()
+		s.logger.Info("Template quality changed", "template_id", event.TemplateID, "previous_quality", event.PreviousQuality, "quality", event.Quality)
+		if event.Quality == domain.QualityRed || event.PreviousQuality == domain.QualityRed {
+			s.notify(ctx, alerts.Alert{
+				Key:      "template_quality:" + event.TemplateID,
+				Summary:  fmt.Sprintf("Template %s quality is %s (was %s)", event.TemplateID, event.Quality, event.PreviousQuality),
+				Resolved: event.Quality != domain.QualityRed,
+			})
+		}
+		return nil
+	}
+
+	templateEventsTotal.WithLabelValues(event.Event).Inc()
+	switch event.Event {
+	case "PAUSED", "DISABLED":
+		return s.disable(ctx, event)
+	case "REINSTATED", "APPROVED":
+		return s.reinstate(ctx, event)
+	default:
+		s.logger.Info("Template status changed", "template_id", event.TemplateID, "event", event.Event, "reason", event.Reason)
+		return nil
+	}
+}
+
+// disable switches the template off unless an operator already did, so their reason is kept
+func (s *templateEventService) disable(ctx context.Context, event domain.TemplateEvent) error {
+	reason := "Meta " + event.Event
+	if event.Reason != "" && event.Reason != "NONE" {
+		reason += ": " + event.Reason
+	}
+
+	if current, disabled := s.templates.Disabled(event.TemplateID); !disabled || current.Actor == metaTemplateActor {
+		_, err := s.templates.Disable(ctx, domain.DisabledTemplate{
+			TemplateID: event.TemplateID,
+			Reason:     reason,
+			Actor:      metaTemplateActor,
+		})
+		if err != nil {
+			s.logger.Error("Failed to disable template", "error", err, "template_id", event.TemplateID)
+			return err
+		}
+	}
+
+	s.notify(ctx, alerts.Alert{
+		Key:     "template_status:" + event.TemplateID,
+		Summary: fmt.Sprintf("Template %s (%s) was %s by Meta; sends of it are refused", event.TemplateID, event.Language, reason),
+	})
+	return nil
+}
+
+// reinstate switches the template back on if it was disabled because of Meta
+func (s *templateEventService) reinstate(ctx context.Context, event domain.TemplateEvent) error {
+	current, disabled := s.templates.Disabled(event.TemplateID)
+	if !disabled || current.Actor != metaTemplateActor {
+		return nil
+	}
+	if err := s.templates.Enable(ctx, event.TemplateID, metaTemplateActor); err != nil {
+		s.logger.Error("Failed to enable template", "error", err, "template_id", event.TemplateID)
+		return err
+	}
+
+	s.notify(ctx, alerts.Alert{
+		Key:      "template_status:" + event.TemplateID,
+		Summary:  fmt.Sprintf("Template %s (%s) was %s by Meta; sends of it are allowed again", event.TemplateID, event.Language, event.Event),
+		Resolved: true,
+	})
+	return nil
+}
+
+// notify sends an alert; failures are logged, as the template change itself was applied
+func (s *templateEventService) notify(ctx context.Context, alert alerts.Alert) {
+	s.logger.Warn("Template alert", "key", alert.Key, "summary", alert.Summary, "resolved", alert.Resolved)
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		s.logger.Error("Failed to send template alert", "error", err, "key", alert.Key)
+	}
+}
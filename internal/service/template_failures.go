@@ -0,0 +1,163 @@
+// internal/service/template_failures.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/utils"
+)
+
+// failureMonitorActor is the actor of templates disabled for failing too often
+const failureMonitorActor = "failure_monitor"
+
+// templateFailureBuckets is how many parts the window is counted in; outcomes drop out of the
+// window one part at a time
+const templateFailureBuckets = 10
+
+// templatesAutoDisabledTotal counts templates disabled for failing too often
+var templatesAutoDisabledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_templates_auto_disabled_total",
+	Help: "Templates disabled by the kill switch because too many of their sends failed.",
+}, []string{"template_id"})
+
+// TemplateFailurePolicy is when a template is disabled for failing: more than MaxFailureRate of
+// its sends in the last Window failed, once there were at least MinMessages
+type TemplateFailurePolicy struct {
+	MaxFailureRate float64
+	Window         time.Duration
+	MinMessages    int
+}
+
+// outcomeBucket counts the send outcomes of a part of the window
+type outcomeBucket struct {
+	start  time.Time
+	sent   int
+	failed int
+}
+
+// templateOutcomes are a template's send outcomes over the window
+type templateOutcomes [templateFailureBuckets]outcomeBucket
+
+// templateFailureMonitor implements StatusHook
+type templateFailureMonitor struct {
+	templates TemplateSwitch
+	policy    TemplateFailurePolicy
+	notifier  alerts.Notifier
+	logger    utils.Logger
+
+	mu       sync.Mutex
+	outcomes map[string]*templateOutcomes
+}
+
+// NewTemplateFailureMonitor creates a status hook following the send outcomes of each template.
+// A send counts when its message becomes sent, or failed without having been sent: failures
+// the provider reports later are mostly about the recipient, not the template. A template
+// failing more than the policy allows, typically through a broken parameter mapping, is
+// disabled with the kill switch and an alert goes to notifier, or is only logged when it is
+// nil. Each replica judges the sends it sees.
+func NewTemplateFailureMonitor(templates TemplateSwitch, policy TemplateFailurePolicy, notifier alerts.Notifier, logger utils.Logger) StatusHook {
+	return &templateFailureMonitor{
+		templates: templates,
+		policy:    policy,
+		notifier:  notifier,
+		logger:    logger,
+		outcomes:  make(map[string]*templateOutcomes),
+	}
+}
+
+// OnStatusChange counts a send outcome and disables the template when too many failed
+func (m *templateFailureMonitor) OnStatusChange(ctx context.Context, msg *domain.Message, oldStatus, newStatus string) {
+	var failed bool
+	switch {
+	case newStatus == "sent":
+	case newStatus == "failed" && oldStatus != "sent" && oldStatus != "delivered" && oldStatus != "read":
+		failed = true
+	default:
+		return
+	}
+	if msg.TemplateID == "" {
+		return
+	}
+	// Sends refused by the kill switch itself say nothing new
+	if _, disabled := m.templates.Disabled(msg.TemplateID); disabled {
+		return
+	}
+
+	if sent, failures, tripped := m.record(msg.TemplateID, failed); tripped {
+		m.trip(ctx, msg.TemplateID, sent, failures)
+	}
+}
+
+// record counts an outcome, returning the template's sends and failures in the window and
+// whether they trip the monitor. Outcomes that trip it are forgotten, so concurrent ones don't
+// trip it again.
+func (m *templateFailureMonitor) record(templateID string, failed bool) (sent, failures int, tripped bool) {
+	width := m.policy.Window / templateFailureBuckets
+	now := time.Now()
+	start := now.Truncate(width)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	outcomes, ok := m.outcomes[templateID]
+	if !ok {
+		outcomes = new(templateOutcomes)
+		m.outcomes[templateID] = outcomes
+	}
+	bucket := &outcomes[(start.UnixNano()/int64(width))%templateFailureBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = outcomeBucket{start: start}
+	}
+	bucket.sent++
+	if failed {
+		bucket.failed++
+	}
+
+	for _, bucket := range outcomes {
+		if now.Sub(bucket.start) < m.policy.Window {
+			sent, failures = sent+bucket.sent, failures+bucket.failed
+		}
+	}
+	tripped = failed && sent >= m.policy.MinMessages && float64(failures) > m.policy.MaxFailureRate*float64(sent)
+	if tripped {
+		delete(m.outcomes, templateID)
+	}
+	return sent, failures, tripped
+}
+
+// trip disables the template and raises the alert
+func (m *templateFailureMonitor) trip(ctx context.Context, templateID string, sent, failures int) {
+	rate := float64(failures) / float64(sent)
+	reason := fmt.Sprintf("%d of its last %d sends failed within %s", failures, sent, m.policy.Window)
+	_, err := m.templates.Disable(ctx, domain.DisabledTemplate{
+		TemplateID: templateID,
+		Reason:     reason,
+		Actor:      failureMonitorActor,
+	})
+	if err != nil {
+		m.logger.Error("Failed to disable failing template", "error", err, "template_id", templateID)
+		return
+	}
+	templatesAutoDisabledTotal.WithLabelValues(templateID).Inc()
+
+	alert := alerts.Alert{
+		Key:       "template_failure_rate:" + templateID,
+		Summary:   fmt.Sprintf("Template %s was disabled: %s; check its parameters, then enable it", templateID, reason),
+		Value:     rate,
+		Threshold: m.policy.MaxFailureRate,
+	}
+	m.logger.Warn("Template alert", "key", alert.Key, "summary", alert.Summary, "resolved", alert.Resolved)
+	if m.notifier == nil {
+		return
+	}
+	if err := m.notifier.Notify(ctx, alert); err != nil {
+		m.logger.Error("Failed to send template alert", "error", err, "key", alert.Key)
+	}
+}
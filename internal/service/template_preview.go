@@ -0,0 +1,144 @@
+// internal/service/template_preview.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+	"messaging-microservice/pkg/utils"
+)
+
+// TemplatePreviewService renders templates with the parameters of a send, so callers can check
+// their content before sending it
+type TemplatePreviewService interface {
+	// Preview renders the caller tenant's template in language, the default language when
+	// empty, as a send of parameters to phoneNumber would show it
+	Preview(ctx context.Context, templateID, language, phoneNumber string, parameters map[string]interface{}) (*domain.TemplatePreview, error)
+}
+
+// templatePreviewService implements TemplatePreviewService
+type templatePreviewService struct {
+	source   meta.TemplateSource
+	texts    meta.TextTemplateSource
+	accounts map[string]string
+	logger   utils.Logger
+}
+
+// NewTemplatePreviewService creates a preview service rendering the bodies texts sends as
+// text, and looking other templates up in source, in the WhatsApp Business Account accounts
+// maps each tenant to. Either may be nil for providers without them.
+func NewTemplatePreviewService(source meta.TemplateSource, texts meta.TextTemplateSource, accounts map[string]string, logger utils.Logger) TemplatePreviewService {
+	return &templatePreviewService{
+		source:   source,
+		texts:    texts,
+		accounts: accounts,
+		logger:   logger,
+	}
+}
+
+// Preview fetches the template's definition and fills in the parameters
+func (s *templatePreviewService) Preview(ctx context.Context, templateID, language, phoneNumber string, parameters map[string]interface{}) (*domain.TemplatePreview, error) {
+	if templateID == "" {
+		return nil, domain.NewError(domain.ErrValidation, "template_id is required")
+	}
+	if templateID == meta.CTAURLTemplate || meta.IsProductTemplate(templateID) {
+		return nil, domain.NewError(domain.ErrValidation, "interactive messages are not templates and cannot be previewed")
+	}
+	if err := meta.ValidateButtonURLParameters(parameters); err != nil {
+		return nil, domain.NewError(domain.ErrValidation, "%s", err)
+	}
+	if s.texts != nil {
+		if tmpl, locale, ok := s.texts.TextTemplate(templateID, parameters); ok {
+			return previewText(templateID, locale, tmpl, parameters)
+		}
+	}
+	if s.source == nil {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "the WhatsApp provider does not support template previews")
+	}
+	tenantID := domain.TenantFromContext(ctx)
+	wabaID, ok := s.accounts[tenantID]
+	if !ok {
+		return nil, domain.NewError(domain.ErrFailedPrecondition, "no WhatsApp Business Account is configured for tenant %s", tenantID)
+	}
+	if language == "" {
+		language = meta.DefaultTemplateLanguage
+	}
+
+	template, err := s.source.GetTemplate(ctx, wabaID, templateID, language)
+	if errors.Is(err, meta.ErrTemplateNotFound) {
+		return nil, domain.NewError(domain.ErrNotFound, "template %s in %s not found", templateID, language)
+	}
+	if err != nil {
+		s.logger.Error("Failed to look up template", "error", err, "template_id", templateID, "language", language)
+		return nil, domain.WrapError(domain.ErrProviderUnavailable, err, "failed to look up template")
+	}
+
+	payload, err := json.Marshal(meta.BuildTemplatePayload(phoneNumber, templateID, language, parameters))
+	if err != nil {
+		return nil, err
+	}
+	rendered := meta.RenderTemplate(template, parameters)
+	preview := &domain.TemplatePreview{
+		TemplateID:        templateID,
+		Language:          language,
+		Status:            template.Status,
+		Category:          template.Category,
+		Header:            rendered.Header,
+		Body:              rendered.Body,
+		Footer:            rendered.Footer,
+		Payload:           payload,
+		MissingParameters: rendered.Missing,
+		UnusedParameters:  rendered.Unused,
+	}
+	for _, button := range rendered.Buttons {
+		preview.Buttons = append(preview.Buttons, domain.TemplateButtonPreview{
+			Type:        button.Type,
+			Text:        button.Text,
+			URL:         button.URL,
+			PhoneNumber: button.PhoneNumber,
+		})
+	}
+	return preview, nil
+}
+
+// previewText renders a body the provider sends as text. Missing parameters the body needs are
+// rendered as their {{.name}} placeholder.
+func previewText(templateID, locale string, tmpl *render.Template, parameters map[string]interface{}) (*domain.TemplatePreview, error) {
+	preview := &domain.TemplatePreview{TemplateID: templateID, Language: locale}
+	data := make(map[string]interface{}, len(parameters))
+	for name, value := range parameters {
+		if name != meta.LocaleParameter {
+			data[name] = value
+		}
+	}
+	for _, name := range tmpl.RequiredParameters() {
+		if _, ok := data[name]; !ok {
+			placeholder := "{{." + name + "}}"
+			data[name] = placeholder
+			preview.MissingParameters = append(preview.MissingParameters, "body "+placeholder)
+		}
+	}
+
+	body, err := tmpl.Execute(data)
+	if err != nil {
+		return nil, domain.NewError(domain.ErrValidation, "template %s does not render: %v", templateID, err)
+	}
+	preview.Body = body
+
+	referenced := make(map[string]bool)
+	for _, name := range tmpl.Parameters() {
+		referenced[name] = true
+	}
+	for name := range data {
+		if !referenced[name] {
+			preview.UnusedParameters = append(preview.UnusedParameters, name)
+		}
+	}
+	sort.Strings(preview.UnusedParameters)
+	return preview, nil
+}
@@ -0,0 +1,212 @@
+// internal/service/template_switch.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// disabledTemplateSendsTotal counts sends refused because their template was disabled
+var disabledTemplateSendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_disabled_template_sends_total",
+	Help: "Sends refused because their template was disabled by the kill switch.",
+}, []string{"template_id"})
+
+// TemplateSwitch disables templates at runtime. The disabled set is stored in the database and
+// cached in memory; changes apply on this replica at once and on the others within one refresh
+// interval.
+type TemplateSwitch interface {
+	Disable(ctx context.Context, template domain.DisabledTemplate) (*domain.DisabledTemplate, error)
+	Enable(ctx context.Context, templateID, actor string) error
+	// ListDisabled returns the disabled templates, most recently disabled first
+	ListDisabled() []domain.DisabledTemplate
+	// Disabled returns the kill switch of a template, if it is disabled
+	Disabled(templateID string) (domain.DisabledTemplate, bool)
+	// Refresh reloads the disabled templates from the database
+	Refresh(ctx context.Context) error
+	// Run refreshes the disabled templates every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// templateSwitch implements TemplateSwitch
+type templateSwitch struct {
+	repo   repository.TemplateRepository
+	logger utils.Logger
+
+	mu       sync.RWMutex
+	disabled []domain.DisabledTemplate
+	byID     map[string]domain.DisabledTemplate
+}
+
+// NewTemplateSwitch creates a new template kill switch
+func NewTemplateSwitch(repo repository.TemplateRepository, logger utils.Logger) TemplateSwitch {
+	return &templateSwitch{
+		repo:   repo,
+		logger: logger,
+		byID:   make(map[string]domain.DisabledTemplate),
+	}
+}
+
+// Disable stores the kill switch and applies it locally right away
+func (s *templateSwitch) Disable(ctx context.Context, template domain.DisabledTemplate) (*domain.DisabledTemplate, error) {
+	if template.TemplateID == "" {
+		return nil, domain.NewError(domain.ErrValidation, "template_id is required")
+	}
+	if template.Actor == "" {
+		return nil, domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+
+	if err := s.repo.DisableTemplate(ctx, &template); err != nil {
+		return nil, err
+	}
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("Failed to refresh disabled templates", "error", err)
+	}
+
+	s.logger.Warn("Disabled template", "template_id", template.TemplateID, "requested_by", template.Actor, "reason", template.Reason)
+	return &template, nil
+}
+
+// Enable removes the kill switch and applies it locally right away
+func (s *templateSwitch) Enable(ctx context.Context, templateID, actor string) error {
+	if templateID == "" {
+		return domain.NewError(domain.ErrValidation, "template_id is required")
+	}
+	if actor == "" {
+		return domain.NewError(domain.ErrValidation, "requested_by is required")
+	}
+
+	found, err := s.repo.EnableTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.NewError(domain.ErrNotFound, "template %q is not disabled", templateID)
+	}
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("Failed to refresh disabled templates", "error", err)
+	}
+
+	s.logger.Info("Enabled template", "template_id", templateID, "requested_by", actor)
+	return nil
+}
+
+// ListDisabled returns a copy of the cached disabled templates
+func (s *templateSwitch) ListDisabled() []domain.DisabledTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]domain.DisabledTemplate(nil), s.disabled...)
+}
+
+// Disabled checks the cache
+func (s *templateSwitch) Disabled(templateID string) (domain.DisabledTemplate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	template, ok := s.byID[templateID]
+	return template, ok
+}
+
+// Refresh swaps in the stored disabled templates
+func (s *templateSwitch) Refresh(ctx context.Context) error {
+	disabled, err := s.repo.ListDisabledTemplates(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]domain.DisabledTemplate, len(disabled))
+	for _, template := range disabled {
+		byID[template.TemplateID] = template
+	}
+
+	s.mu.Lock()
+	s.disabled, s.byID = disabled, byID
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the cache immediately and then every interval
+func (s *templateSwitch) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Error("Failed to refresh disabled templates", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// templateDisabledError is returned for sends of a disabled template
+func templateDisabledError(template domain.DisabledTemplate) error {
+	if template.Reason == "" {
+		return domain.NewError(domain.ErrFailedPrecondition, "template %q is disabled", template.TemplateID)
+	}
+	return domain.NewError(domain.ErrFailedPrecondition, "template %q is disabled: %s", template.TemplateID, template.Reason)
+}
+
+// templateGuardedMessageService refuses sends of disabled templates
+type templateGuardedMessageService struct {
+	MessageService
+	templates TemplateSwitch
+	repo      repository.MessageRepository
+	logger    utils.Logger
+}
+
+// NewTemplateGuardedMessageService wraps a message service so sends of disabled templates are
+// refused, and messages of a template disabled after they were queued are failed unsent
+func NewTemplateGuardedMessageService(inner MessageService, templates TemplateSwitch, repo repository.MessageRepository, logger utils.Logger) MessageService {
+	return &templateGuardedMessageService{
+		MessageService: inner,
+		templates:      templates,
+		repo:           repo,
+		logger:         logger,
+	}
+}
+
+// SendTemplateMessage refuses disabled templates before anything is stored
+func (s *templateGuardedMessageService) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if template, disabled := s.templates.Disabled(templateID); disabled {
+		disabledTemplateSendsTotal.WithLabelValues(templateID).Inc()
+		return nil, templateDisabledError(template)
+	}
+	return s.MessageService.SendTemplateMessage(ctx, phoneNumber, templateID, parameters, orderID, customerID)
+}
+
+// ProcessQueueMessage fails queued messages whose template has since been disabled
+func (s *templateGuardedMessageService) ProcessQueueMessage(ctx context.Context, data []byte) error {
+	queueMsg, err := DecodeQueueMessage(data)
+	if err != nil {
+		s.logger.Error("Failed to decode queue message", "error", err)
+		return err
+	}
+
+	template, disabled := s.templates.Disabled(queueMsg.TemplateID)
+	if !disabled {
+		return s.MessageService.ProcessQueueMessage(ctx, data)
+	}
+
+	disabledTemplateSendsTotal.WithLabelValues(queueMsg.TemplateID).Inc()
+	cause := templateDisabledError(template)
+	if err := s.repo.UpdateMessageStatus(ctx, queueMsg.MessageID, "failed", "", cause.Error(), ""); err != nil {
+		s.logger.Error("Failed to update message status", "error", err, "message_id", queueMsg.MessageID)
+		return err
+	}
+	s.logger.Warn("Dropped message of disabled template", "message_id", queueMsg.MessageID, "template_id", queueMsg.TemplateID)
+	return nil
+}
@@ -0,0 +1,28 @@
+// internal/service/tenant_resolver.go
+package service
+
+// TenantResolver maps the WhatsApp phone number ID a webhook was delivered for
+// to the tenant that owns it
+type TenantResolver interface {
+	ResolveTenant(phoneNumberID string) (string, bool)
+}
+
+// staticTenantResolver implements TenantResolver from a fixed mapping
+type staticTenantResolver struct {
+	tenants map[string]string
+}
+
+// NewStaticTenantResolver creates a resolver from a phone number ID to tenant ID map
+func NewStaticTenantResolver(tenants map[string]string) TenantResolver {
+	copied := make(map[string]string, len(tenants))
+	for phoneNumberID, tenantID := range tenants {
+		copied[phoneNumberID] = tenantID
+	}
+	return &staticTenantResolver{tenants: copied}
+}
+
+// ResolveTenant returns the tenant for a phone number ID
+func (r *staticTenantResolver) ResolveTenant(phoneNumberID string) (string, bool) {
+	tenantID, ok := r.tenants[phoneNumberID]
+	return tenantID, ok
+}
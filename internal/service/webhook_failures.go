@@ -0,0 +1,203 @@
+// internal/service/webhook_failures.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/domain"
+)
+
+// Outcomes of the changes of a webhook batch
+const (
+	changeOutcomeProcessed = "processed"
+	changeOutcomeSkipped   = "skipped"
+	changeOutcomeFailed    = "failed"
+)
+
+// webhookReplayBatch is how many stored failures one replay run reprocesses at most
+const webhookReplayBatch = 100
+
+var (
+	webhookBatchChangesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_webhook_batch_changes_total",
+		Help: "Changes of webhook batches by provider and outcome (processed, skipped, failed).",
+	}, []string{"provider", "outcome"})
+
+	webhookBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "whatsapp_webhook_batch_size",
+		Help:    "Changes per webhook batch, by provider.",
+		Buckets: []float64{1, 2, 5, 10, 25, 50, 100},
+	}, []string{"provider"})
+
+	webhookPartialBatchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_webhook_partial_batches_total",
+		Help: "Webhook batches in which some changes failed and others went through, by provider.",
+	}, []string{"provider"})
+
+	webhookFailureReplaysTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_webhook_failure_replays_total",
+		Help: "Replays of stored webhook failures by result (replayed, failed, abandoned).",
+	}, []string{"result"})
+)
+
+// webhookChange is the outcome of one change of a Meta webhook batch; entry and change are
+// its position in the payload
+type webhookChange struct {
+	entry   int
+	change  int
+	entryID string
+	field   string
+	skipped bool
+	// err is the change's own failure, statusErr the failure of the statement storing the
+	// statuses of every change
+	err       error
+	statusErr error
+}
+
+func (c *webhookChange) failure() error {
+	return errors.Join(c.err, c.statusErr)
+}
+
+// rawWebhookPayload is a Meta webhook with its changes left undecoded, to store them as sent
+type rawWebhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string            `json:"id"`
+		Changes []json.RawMessage `json:"changes"`
+	} `json:"entry"`
+}
+
+// settleChanges records the outcomes of a batch's changes and stores the failed ones to be
+// replayed. It returns the failures nothing will replay: all of them when replaying or when
+// there is no store, none once they are stored.
+func (s *webhookService) settleChanges(ctx context.Context, body []byte, changes []*webhookChange, replaying bool) error {
+	var failed []*webhookChange
+	var errs []error
+	for _, change := range changes {
+		outcome := changeOutcomeProcessed
+		if err := change.failure(); err != nil {
+			outcome = changeOutcomeFailed
+			failed = append(failed, change)
+			errs = append(errs, err)
+		} else if change.skipped {
+			outcome = changeOutcomeSkipped
+		}
+		webhookBatchChangesTotal.WithLabelValues(domain.ProviderMeta, outcome).Inc()
+	}
+	if !replaying {
+		webhookBatchSize.WithLabelValues(domain.ProviderMeta).Observe(float64(len(changes)))
+		if len(failed) > 0 && len(failed) < len(changes) {
+			webhookPartialBatchesTotal.WithLabelValues(domain.ProviderMeta).Inc()
+		}
+	}
+	if len(failed) == 0 || replaying || s.failures == nil {
+		return errors.Join(errs...)
+	}
+
+	failures, err := webhookFailures(body, failed)
+	if err == nil {
+		err = s.failures.CreateWebhookFailures(ctx, failures)
+	}
+	if err != nil {
+		s.logger.Error("Failed to store failed webhook changes", "error", err, "count", len(failed))
+		return errors.Join(errs...)
+	}
+	for _, change := range failed {
+		s.logger.Warn("Stored failed webhook change for replay", "entry_id", change.entryID, "field", change.field, "error", change.failure())
+	}
+	return nil
+}
+
+// webhookFailures builds a failure per failed change, holding the payload with only that change
+func webhookFailures(body []byte, failed []*webhookChange) ([]domain.WebhookFailure, error) {
+	var raw rawWebhookPayload
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	failures := make([]domain.WebhookFailure, 0, len(failed))
+	for _, change := range failed {
+		payload, err := json.Marshal(map[string]interface{}{
+			"object": raw.Object,
+			"entry": []interface{}{map[string]interface{}{
+				"id":      change.entryID,
+				"changes": []json.RawMessage{raw.Entry[change.entry].Changes[change.change]},
+			}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, domain.WebhookFailure{
+			Provider: domain.ProviderMeta,
+			EntryID:  change.entryID,
+			Field:    change.field,
+			Payload:  payload,
+			Error:    change.failure().Error(),
+		})
+	}
+	return failures, nil
+}
+
+// ReplayFailures reprocesses the oldest stored failures. Failures that go through are deleted;
+// the others count an attempt and are abandoned after the last one.
+func (s *webhookService) ReplayFailures(ctx context.Context) (int, error) {
+	if s.failures == nil {
+		return 0, nil
+	}
+	failures, err := s.failures.ListWebhookFailures(ctx, s.maxReplays, webhookReplayBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, failure := range failures {
+		if err := s.processPayload(ctx, failure.Payload, true); err != nil {
+			result := "failed"
+			if failure.Attempts+1 >= s.maxReplays {
+				result = "abandoned"
+				s.logger.Error("Gave up replaying webhook change", "failure_id", failure.ID, "entry_id", failure.EntryID, "field", failure.Field, "error", err)
+			}
+			webhookFailureReplaysTotal.WithLabelValues(result).Inc()
+			if err := s.failures.RecordWebhookFailureAttempt(ctx, failure.ID, err.Error()); err != nil {
+				return replayed, err
+			}
+			continue
+		}
+
+		webhookFailureReplaysTotal.WithLabelValues("replayed").Inc()
+		if err := s.failures.DeleteWebhookFailure(ctx, failure.ID); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// Run replays stored failures now and then every interval until ctx is done
+func (s *webhookService) Run(ctx context.Context, interval time.Duration) {
+	if s.failures == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if replayed, err := s.ReplayFailures(ctx); err != nil {
+			s.logger.Error("Failed to replay webhook failures", "error", err)
+		} else if replayed > 0 {
+			s.logger.Info("Replayed failed webhook changes", "count", replayed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// internal/service/webhook_replay.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// Replay actions: replayed events are dropped, or only logged and counted
+const (
+	ReplayActionReject = "reject"
+	ReplayActionFlag   = "flag"
+)
+
+// Reasons an event is taken for a replay
+const (
+	replayReasonStale     = "stale"
+	replayReasonFuture    = "future"
+	replayReasonDuplicate = "duplicate"
+)
+
+// maxWebhookClockSkew is how far ahead of our clock a provider's event timestamp may be
+const maxWebhookClockSkew = 5 * time.Minute
+
+var webhookReplaysTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_webhook_replays_total",
+	Help: "Webhook status events taken for replays, by provider, reason (stale, future, duplicate) and action taken.",
+}, []string{"provider", "reason", "action"})
+
+// ReplayPolicy configures a ReplayGuard
+type ReplayPolicy struct {
+	// MaxAge is how old an event's timestamp may be; processed events are remembered as long
+	MaxAge time.Duration
+	// Action is ReplayActionReject or ReplayActionFlag
+	Action string
+}
+
+// ReplayGuard protects status webhooks against replayed or spoofed callbacks that carry a
+// valid signature: events dated outside the policy's window, and events already processed,
+// are dropped or flagged. It only sees events whose signature was verified, so a forged
+// callback can't have a genuine event taken for a replay. A nil guard accepts every event.
+type ReplayGuard struct {
+	nonces repository.WebhookNonceStore
+	policy ReplayPolicy
+	now    func() time.Time
+	logger utils.Logger
+}
+
+// NewReplayGuard creates a replay guard remembering processed events in nonces; nil nonces
+// only checks timestamps
+func NewReplayGuard(nonces repository.WebhookNonceStore, policy ReplayPolicy, logger utils.Logger) *ReplayGuard {
+	return &ReplayGuard{
+		nonces: nonces,
+		policy: policy,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// Allow reports whether the event with the given key and timestamp is processed. The zero
+// timestamp, for providers that don't date their callbacks, skips the age check.
+func (g *ReplayGuard) Allow(ctx context.Context, provider, key string, at time.Time) bool {
+	if g == nil {
+		return true
+	}
+
+	reason := ""
+	now := g.now()
+	switch {
+	case !at.IsZero() && now.Sub(at) > g.policy.MaxAge:
+		reason = replayReasonStale
+	case !at.IsZero() && at.Sub(now) > maxWebhookClockSkew:
+		reason = replayReasonFuture
+	case g.nonces != nil:
+		// An unavailable store lets the event through; statuses are applied idempotently
+		seen, err := g.nonces.Seen(ctx, key)
+		if err != nil {
+			g.logger.Warn("Webhook nonce lookup failed", "error", err, "provider", provider)
+		} else if seen {
+			reason = replayReasonDuplicate
+		}
+	}
+	if reason == "" {
+		return true
+	}
+
+	webhookReplaysTotal.WithLabelValues(provider, reason, g.policy.Action).Inc()
+	g.logger.Warn("Webhook event looks replayed", "provider", provider, "event", key, "reason", reason, "timestamp", at, "action", g.policy.Action)
+	return g.policy.Action != ReplayActionReject
+}
+
+// Processed remembers the keys of events that were applied. Keys are only recorded once the
+// events are stored, so a redelivery after a failure is not mistaken for a replay.
+func (g *ReplayGuard) Processed(ctx context.Context, keys []string) {
+	if g == nil || g.nonces == nil || len(keys) == 0 {
+		return
+	}
+	if err := g.nonces.Record(ctx, keys...); err != nil {
+		g.logger.Warn("Failed to record webhook nonces", "error", err, "count", len(keys))
+	}
+}
@@ -5,16 +5,33 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
+	"messaging-microservice/internal/domain"
 	"messaging-microservice/internal/queue"
 	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/twilio"
 	"messaging-microservice/pkg/utils"
 )
 
 // WebhookService defines the interface for webhook operations
 type WebhookService interface {
 	ProcessWebhook(ctx context.Context, body []byte, signature, url string) error
-	UpdateMessageStatus(ctx context.Context, externalID, status, errorMessage string) error
+	// ReplayFailures reprocesses the stored webhook changes that failed, returning how many
+	// went through
+	ReplayFailures(ctx context.Context) (int, error)
+	// Run replays failed webhook changes every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+	// MatchQuarantined applies the quarantined statuses whose message is now known, returning
+	// how many, and drops those quarantined for longer than the window
+	MatchQuarantined(ctx context.Context) (int, error)
+	// RunQuarantine matches quarantined statuses every interval until ctx is done
+	RunQuarantine(ctx context.Context, interval time.Duration)
+	// ProcessTwilioStatus applies a Twilio status callback whose signature was already checked
+	ProcessTwilioStatus(ctx context.Context, callback *twilio.StatusCallback) error
+	UpdateMessageStatus(ctx context.Context, externalID, status, errorCode, errorMessage string) error
 	GetVerifyToken() string
 }
 
@@ -22,15 +39,73 @@ type WebhookService interface {
 type webhookService struct {
 	repo       repository.MessageRepository
 	producer   queue.Producer
+	tenants    TenantResolver
+	hasher     utils.PhoneNumberHasher
+	inbound    InboundService
+	accounts   AccountQualityService
+	templates  TemplateEventService
+	signatures func(signature, url string, body []byte) bool
+	replay     *ReplayGuard
+	failures   repository.WebhookFailureRepository
+	maxReplays int
+	quarantine repository.StatusQuarantineRepository
+	window     time.Duration
+	lookupWait time.Duration
 	logger     utils.Logger
 	verifyToken string
 }
 
 // NewWebhookService creates a new webhook service
-func NewWebhookService(repo repository.MessageRepository, producer queue.Producer, logger utils.Logger, verifyToken string) WebhookService {
+func NewWebhookService(repo repository.MessageRepository, producer queue.Producer, tenants TenantResolver, hasher utils.PhoneNumberHasher, logger utils.Logger, verifyToken string) WebhookService {
+	return NewWebhookServiceWithInbound(repo, producer, tenants, hasher, nil, logger, verifyToken)
+}
+
+// NewWebhookServiceWithInbound creates a webhook service that passes the inbound messages of
+// Meta webhooks to inbound; a nil inbound ignores them
+func NewWebhookServiceWithInbound(repo repository.MessageRepository, producer queue.Producer, tenants TenantResolver, hasher utils.PhoneNumberHasher, inbound InboundService, logger utils.Logger, verifyToken string) WebhookService {
+	return NewWebhookServiceWithHandlers(repo, producer, tenants, hasher, WebhookHandlers{Inbound: inbound}, logger, verifyToken)
+}
+
+// WebhookHandlers receive the parts of Meta webhooks other than message statuses; nil handlers
+// ignore their part. Signatures checks the X-Hub-Signature-256 of Meta webhooks against the app
+// secret, typically the ValidateWebhookSignature of the Meta client; nil rejects them all.
+// Replay screens the statuses of both providers; nil accepts them all. Failures stores the
+// changes of Meta webhooks that failed, which are replayed up to MaxReplayAttempts times; nil
+// has the provider redeliver the whole payload instead.
+// Quarantine keeps the statuses of both providers for external IDs no message has, which are
+// matched again for QuarantineWindow; nil drops them. Before that, an unknown external ID is
+// looked up again for up to LookupWait per webhook, since its send may be committing.
+type WebhookHandlers struct {
+	Inbound           InboundService
+	Accounts          AccountQualityService
+	Templates         TemplateEventService
+	Signatures        func(signature, url string, body []byte) bool
+	Replay            *ReplayGuard
+	Failures          repository.WebhookFailureRepository
+	MaxReplayAttempts int
+	Quarantine        repository.StatusQuarantineRepository
+	QuarantineWindow  time.Duration
+	LookupWait        time.Duration
+}
+
+// NewWebhookServiceWithHandlers creates a webhook service passing inbound messages, account
+// events and template events to handlers
+func NewWebhookServiceWithHandlers(repo repository.MessageRepository, producer queue.Producer, tenants TenantResolver, hasher utils.PhoneNumberHasher, handlers WebhookHandlers, logger utils.Logger, verifyToken string) WebhookService {
 	return &webhookService{
 		repo:       repo,
 		producer:   producer,
+		tenants:    tenants,
+		hasher:     hasher,
+		inbound:    handlers.Inbound,
+		accounts:   handlers.Accounts,
+		templates:  handlers.Templates,
+		signatures: handlers.Signatures,
+		replay:     handlers.Replay,
+		failures:   handlers.Failures,
+		maxReplays: handlers.MaxReplayAttempts,
+		quarantine: handlers.Quarantine,
+		window:     handlers.QuarantineWindow,
+		lookupWait: handlers.LookupWait,
 		logger:     logger,
 		verifyToken: verifyToken,
 	}
@@ -42,7 +117,10 @@ type MetaWebhookPayload struct {
 	Entry  []struct {
 		ID      string `json:"id"`
 		Changes []struct {
+			Field string `json:"field"`
 			Value struct {
+				MetaAccountUpdate
+				MetaTemplateUpdate
 				MessagingProduct string `json:"messaging_product"`
 				Metadata         struct {
 					DisplayPhoneNumber string `json:"display_phone_number"`
@@ -59,33 +137,194 @@ type MetaWebhookPayload struct {
 						Message string `json:"message"`
 					} `json:"errors,omitempty"`
 				} `json:"statuses,omitempty"`
+				Contacts []struct {
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+					WaID string `json:"wa_id"`
+				} `json:"contacts,omitempty"`
+				Messages []MetaInboundMessage `json:"messages,omitempty"`
 			} `json:"value"`
 		} `json:"changes"`
 	} `json:"entry"`
 }
 
-// WebhookEvent represents a parsed webhook event
+// MetaInboundMessage is a message a customer sent, as delivered in a Meta webhook
+type MetaInboundMessage struct {
+	From      string `json:"from"`
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      *struct {
+		Body string `json:"body"`
+	} `json:"text,omitempty"`
+	Button *struct {
+		Text    string `json:"text"`
+		Payload string `json:"payload"`
+	} `json:"button,omitempty"`
+	Interactive *struct {
+		Type        string `json:"type"`
+		ButtonReply *struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"button_reply,omitempty"`
+		ListReply *struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"list_reply,omitempty"`
+	} `json:"interactive,omitempty"`
+	Order *struct {
+		CatalogID    string `json:"catalog_id"`
+		Text         string `json:"text"`
+		ProductItems []struct {
+			ProductRetailerID string      `json:"product_retailer_id"`
+			Quantity          json.Number `json:"quantity"`
+			ItemPrice         json.Number `json:"item_price"`
+			Currency          string      `json:"currency"`
+		} `json:"product_items"`
+	} `json:"order,omitempty"`
+}
+
+// MetaAccountUpdate holds the fields of account_update and phone_number_quality_update changes,
+// which Meta sends for the business account rather than one of its numbers
+type MetaAccountUpdate struct {
+	Event              string `json:"event,omitempty"`
+	DisplayPhoneNumber string `json:"display_phone_number,omitempty"`
+	PhoneNumber        string `json:"phone_number,omitempty"`
+	CurrentLimit       string `json:"current_limit,omitempty"`
+	ViolationInfo      *struct {
+		ViolationType string `json:"violation_type"`
+	} `json:"violation_info,omitempty"`
+	BanInfo *struct {
+		BanState string `json:"waba_ban_state"`
+		BanDate  string `json:"waba_ban_date"`
+	} `json:"ban_info,omitempty"`
+}
+
+// accountEvent converts the change of the given field to an account event
+func (u MetaAccountUpdate) accountEvent(tenantID, field string) domain.AccountEvent {
+	event := domain.AccountEvent{
+		TenantID:    tenantID,
+		PhoneNumber: u.PhoneNumber,
+		Field:       field,
+		Event:       u.Event,
+		ReceivedAt:  time.Now(),
+	}
+	if field == domain.AccountFieldQualityUpdate {
+		event.PhoneNumber = u.DisplayPhoneNumber
+		event.QualityRating = domain.QualityForEvent(u.Event)
+		event.MessagingLimitTier = u.CurrentLimit
+	}
+
+	var details []string
+	if u.ViolationInfo != nil {
+		details = append(details, "violation: "+u.ViolationInfo.ViolationType)
+	}
+	if u.BanInfo != nil {
+		details = append(details, "ban: "+strings.TrimSpace(u.BanInfo.BanState+" "+u.BanInfo.BanDate))
+	}
+	event.Detail = strings.Join(details, "; ")
+	return event
+}
+
+// MetaTemplateUpdate holds the fields of message_template_status_update and
+// message_template_quality_update changes; their event is MetaAccountUpdate.Event
+type MetaTemplateUpdate struct {
+	TemplateName         string `json:"message_template_name,omitempty"`
+	TemplateLanguage     string `json:"message_template_language,omitempty"`
+	Reason               string `json:"reason,omitempty"`
+	PreviousQualityScore string `json:"previous_quality_score,omitempty"`
+	NewQualityScore      string `json:"new_quality_score,omitempty"`
+}
+
+// templateEvent converts the change of the given field to a template event
+func (u MetaTemplateUpdate) templateEvent(field, event string) domain.TemplateEvent {
+	return domain.TemplateEvent{
+		TemplateID:      u.TemplateName,
+		Language:        u.TemplateLanguage,
+		Field:           field,
+		Event:           event,
+		Reason:          u.Reason,
+		PreviousQuality: u.PreviousQualityScore,
+		Quality:         u.NewQualityScore,
+	}
+}
+
+// text returns the message body, or the title of the button or list item picked
+func (m MetaInboundMessage) text() string {
+	switch {
+	case m.Text != nil:
+		return m.Text.Body
+	case m.Button != nil:
+		return m.Button.Text
+	case m.Interactive != nil && m.Interactive.ButtonReply != nil:
+		return m.Interactive.ButtonReply.Title
+	case m.Interactive != nil && m.Interactive.ListReply != nil:
+		return m.Interactive.ListReply.Title
+	case m.Order != nil:
+		return m.Order.Text
+	default:
+		return ""
+	}
+}
+
+// order returns the cart of an order message, or nil
+func (m MetaInboundMessage) order() *domain.InboundOrder {
+	if m.Order == nil {
+		return nil
+	}
+	order := &domain.InboundOrder{CatalogID: m.Order.CatalogID}
+	for _, item := range m.Order.ProductItems {
+		quantity, _ := item.Quantity.Int64()
+		price, _ := item.ItemPrice.Float64()
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductRetailerID: item.ProductRetailerID,
+			Quantity:          int(quantity),
+			ItemPrice:         price,
+			Currency:          item.Currency,
+		})
+	}
+	return order
+}
+
+// WebhookEvent represents a parsed webhook event published to the status-events topic.
+// Events are keyed by message ID so they stay ordered within a partition; consumers
+// should drop events whose Sequence is not greater than the last one applied for the
+// message, and may use DedupeKey to discard redelivered copies of the same event.
 type WebhookEvent struct {
+	MessageID    int64  `json:"message_id"`
+	TenantID     string `json:"tenant_id"`
 	ExternalID   string `json:"external_id"`
 	Status       string `json:"status"`
 	ErrorCode    string `json:"error_code,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
 	PhoneNumber  string `json:"phone_number"`
+	Timestamp    string `json:"timestamp,omitempty"`
+	Sequence     int64  `json:"sequence"`
+	DedupeKey    string `json:"dedupe_key"`
 }
 
 // ProcessWebhook processes an incoming webhook
 func (s *webhookService) ProcessWebhook(ctx context.Context, body []byte, signature, url string) error {
 	// Validate signature
-	// This would need to be implemented with your Meta client
 	if signature == "" {
-		return errors.New("missing webhook signature")
+		return domain.NewError(domain.ErrUnauthenticated, "missing webhook signature")
+	}
+	if s.signatures == nil || !s.signatures(signature, url, body) {
+		return domain.NewError(domain.ErrUnauthenticated, "invalid webhook signature")
 	}
 
+	return s.processPayload(ctx, body, false)
+}
+
+// processPayload applies a Meta webhook payload change by change. Changes that fail are stored
+// to be replayed, unless the payload is itself a replay; the failures that can't be stored are
+// returned so the provider redelivers the payload.
+func (s *webhookService) processPayload(ctx context.Context, body []byte, replaying bool) error {
 	// Parse webhook payload
 	var metaPayload MetaWebhookPayload
 	if err := json.Unmarshal(body, &metaPayload); err != nil {
-		s.logger.Error("Failed to unmarshal webhook payload", "error", err)
-		return err
+		return domain.WrapError(domain.ErrValidation, err, "malformed webhook payload")
 	}
 
 	// Check if it's a valid WhatsApp webhook
@@ -94,42 +333,299 @@ func (s *webhookService) ProcessWebhook(ctx context.Context, body []byte, signat
 		return nil // Not an error, just not relevant for us
 	}
 
-	// Process each status update
-	for _, entry := range metaPayload.Entry {
-		for _, change := range entry.Changes {
+	// Unknown external IDs are waited for up to lookupWait over the whole payload
+	waitUntil := time.Now().Add(s.lookupWait)
+
+	// Collect the status updates of every entry so they are applied with one statement,
+	// remembering the change each came from
+	var updates []domain.StatusUpdate
+	var events []WebhookEvent
+	var updateChanges []*webhookChange
+	var changes []*webhookChange
+	for e, entry := range metaPayload.Entry {
+		for c, change := range entry.Changes {
+			outcome := &webhookChange{entry: e, change: c, entryID: entry.ID, field: change.Field}
+			changes = append(changes, outcome)
+
+			if change.Field == domain.AccountFieldAccountUpdate || change.Field == domain.AccountFieldQualityUpdate {
+				outcome.err = s.recordAccountEvent(ctx, entry.ID, change.Field, change.Value.MetaAccountUpdate)
+				continue
+			}
+			if change.Field == domain.TemplateFieldStatusUpdate || change.Field == domain.TemplateFieldQualityUpdate {
+				if s.templates != nil {
+					outcome.err = s.templates.HandleTemplateEvent(ctx, change.Value.templateEvent(change.Field, change.Value.Event))
+				}
+				continue
+			}
+
+			// Resolve the tenant that owns the sending number; all lookups are scoped to it
+			phoneNumberID := change.Value.Metadata.PhoneNumberID
+			tenantID, ok := s.tenants.ResolveTenant(phoneNumberID)
+			if !ok {
+				s.logger.Warn("Received webhook for unknown phone number ID", "phone_number_id", phoneNumberID)
+				outcome.skipped = true
+				continue
+			}
+			ctx := domain.WithTenant(ctx, tenantID)
+
 			for _, status := range change.Value.Statuses {
+				dedupeKey := statusDedupeKey(status.ID, status.Status, status.Timestamp)
+				if !s.replay.Allow(ctx, domain.ProviderMeta, dedupeKey, parseStatusTimestamp(status.Timestamp)) {
+					continue
+				}
+
 				// Map status
 				mappedStatus := mapMetaStatus(status.Status)
-				
+
 				// Extract error info
-				var errorMessage string
+				var errorCode, errorMessage string
 				if len(status.Errors) > 0 {
+					errorCode = strconv.Itoa(status.Errors[0].Code)
 					errorMessage = status.Errors[0].Message
 				}
 
-				// Create webhook event
-				event := WebhookEvent{
+				// Find the message this status belongs to
+				messageID, err := s.lookupMessageID(ctx, tenantID, status.ID, waitUntil)
+				if err != nil {
+					// The send that got the ID may not be committed yet; other failures are
+					// the change's
+					if errors.Is(err, domain.ErrNotFound) {
+						err = s.quarantineStatus(ctx, &domain.QuarantinedStatus{
+							Provider:     domain.ProviderMeta,
+							TenantID:     tenantID,
+							ExternalID:   status.ID,
+							Status:       mappedStatus,
+							ErrorCode:    errorCode,
+							ErrorMessage: errorMessage,
+							PhoneNumber:  s.hasher.Hash(status.RecipientID),
+							Timestamp:    status.Timestamp,
+							At:           parseStatusTimestamp(status.Timestamp),
+							DedupeKey:    dedupeKey,
+						})
+					}
+					outcome.err = errors.Join(outcome.err, err)
+					continue
+				}
+
+				s.logger.Debug("Received status update", "message_id", messageID, "external_id", status.ID, "status", mappedStatus, "phone_number", status.RecipientID)
+				updates = append(updates, domain.StatusUpdate{
+					MessageID:    messageID,
+					Status:       mappedStatus,
+					ErrorCode:    errorCode,
+					ErrorMessage: errorMessage,
+					ExternalID:   status.ID,
+					At:           parseStatusTimestamp(status.Timestamp),
+				})
+				events = append(events, WebhookEvent{
+					MessageID:    messageID,
+					TenantID:     tenantID,
 					ExternalID:   status.ID,
 					Status:       mappedStatus,
+					ErrorCode:    errorCode,
 					ErrorMessage: errorMessage,
-					PhoneNumber:  status.RecipientID,
-				}
+					PhoneNumber:  s.hasher.Hash(status.RecipientID),
+					Timestamp:    status.Timestamp,
+					DedupeKey:    dedupeKey,
+				})
+				updateChanges = append(updateChanges, outcome)
+			}
 
-				// Handle webhook asynchronously
-				eventData, err := json.Marshal(event)
+			if s.inbound == nil {
+				continue
+			}
+			profileNames := make(map[string]string, len(change.Value.Contacts))
+			for _, contact := range change.Value.Contacts {
+				profileNames[contact.WaID] = contact.Profile.Name
+			}
+			for _, message := range change.Value.Messages {
+				err := s.inbound.HandleInbound(ctx, domain.InboundMessage{
+					TenantID:    tenantID,
+					ExternalID:  message.ID,
+					PhoneNumber: domain.NormalizeCustomerPhone(message.From),
+					ProfileName: profileNames[message.From],
+					Type:        message.Type,
+					Text:        message.text(),
+					Order:       message.order(),
+					ReceivedAt:  inboundTimestamp(message.Timestamp),
+				})
 				if err != nil {
-					s.logger.Error("Failed to marshal webhook event", "error", err)
-					continue
+					outcome.err = errors.Join(outcome.err, err)
 				}
+			}
+		}
+	}
 
-				if err := s.producer.Produce(ctx, eventData); err != nil {
-					s.logger.Error("Failed to produce webhook event to queue", "error", err)
-					continue
-				}
+	// Statuses are applied even when an inbound message failed; a failed change is replayed
+	// whole, and both are deduplicated. The statuses share one statement, so when it fails
+	// every change with a status did.
+	if err := s.applyStatuses(ctx, updates, events); err != nil {
+		for _, outcome := range updateChanges {
+			outcome.statusErr = err
+		}
+	}
+	return s.settleChanges(ctx, body, changes, replaying)
+}
 
-				// Also update message status directly for immediate feedback
-				s.UpdateMessageStatus(ctx, event.ExternalID, event.Status, event.ErrorMessage)
-			}
+// lookupRetryDelay is the first wait before an unknown external ID is looked up again; the
+// waits double up to the webhook's deadline
+const lookupRetryDelay = 25 * time.Millisecond
+
+// lookupMessageID finds the message a status is for. An external ID that isn't found is looked
+// up again until waitUntil: the provider can call back before the send that got the ID commits.
+func (s *webhookService) lookupMessageID(ctx context.Context, tenantID, externalID string, waitUntil time.Time) (int64, error) {
+	delay := lookupRetryDelay
+	for {
+		messageID, err := s.repo.GetMessageIDByExternalID(ctx, tenantID, externalID)
+		if !errors.Is(err, domain.ErrNotFound) || time.Now().Add(delay).After(waitUntil) {
+			return messageID, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// recordAccountEvent passes an account change to the account quality service. These changes
+// carry no phone number ID, so the tenant is resolved by the business account ID.
+func (s *webhookService) recordAccountEvent(ctx context.Context, accountID, field string, update MetaAccountUpdate) error {
+	if s.accounts == nil {
+		return nil
+	}
+	tenantID, ok := s.tenants.ResolveTenant(accountID)
+	if !ok {
+		s.logger.Warn("Received account event for unknown business account", "account_id", accountID, "event", update.Event)
+		return nil
+	}
+	return s.accounts.RecordEvent(domain.WithTenant(ctx, tenantID), update.accountEvent(tenantID, field))
+}
+
+// inboundTimestamp converts an inbound message's Unix timestamp, falling back to now
+func inboundTimestamp(timestamp string) time.Time {
+	if at := parseStatusTimestamp(timestamp); !at.IsZero() {
+		return at
+	}
+	return time.Now()
+}
+
+// ProcessTwilioStatus resolves the tenant from the sender the callback is for (the messaging
+// service, or the WhatsApp sender number) and feeds the status into the same pipeline as Meta's
+func (s *webhookService) ProcessTwilioStatus(ctx context.Context, callback *twilio.StatusCallback) error {
+	status, ok := callback.Status()
+	if !ok {
+		s.logger.Debug("Ignoring intermediate Twilio status", "message_sid", callback.MessageSID, "status", callback.MessageStatus)
+		return nil
+	}
+
+	sender := callback.MessagingServiceSID
+	if sender == "" {
+		sender = callback.From
+	}
+	tenantID, ok := s.tenants.ResolveTenant(sender)
+	if !ok {
+		s.logger.Warn("Received Twilio callback for unknown sender", "sender", sender)
+		return nil
+	}
+	ctx = domain.WithTenant(ctx, tenantID)
+
+	dedupeKey := statusDedupeKey(callback.MessageSID, callback.MessageStatus, "")
+	if !s.replay.Allow(ctx, domain.ProviderTwilio, dedupeKey, time.Time{}) {
+		return nil
+	}
+
+	messageID, err := s.lookupMessageID(ctx, tenantID, callback.MessageSID, time.Now().Add(s.lookupWait))
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.quarantineStatus(ctx, &domain.QuarantinedStatus{
+			Provider:     domain.ProviderTwilio,
+			TenantID:     tenantID,
+			ExternalID:   callback.MessageSID,
+			Status:       status,
+			ErrorCode:    callback.ErrorCode,
+			ErrorMessage: callback.ErrorMessage,
+			PhoneNumber:  s.hasher.Hash(strings.TrimPrefix(callback.To, "whatsapp:+")),
+			DedupeKey:    dedupeKey,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// Twilio callbacks carry no timestamp; the status is stamped when it is stored
+	updates := []domain.StatusUpdate{{
+		MessageID:    messageID,
+		Status:       status,
+		ErrorCode:    callback.ErrorCode,
+		ErrorMessage: callback.ErrorMessage,
+		ExternalID:   callback.MessageSID,
+	}}
+	events := []WebhookEvent{{
+		MessageID:    messageID,
+		TenantID:     tenantID,
+		ExternalID:   callback.MessageSID,
+		Status:       status,
+		ErrorCode:    callback.ErrorCode,
+		ErrorMessage: callback.ErrorMessage,
+		PhoneNumber:  s.hasher.Hash(strings.TrimPrefix(callback.To, "whatsapp:+")),
+		DedupeKey:    dedupeKey,
+	}}
+	return s.applyStatuses(ctx, updates, events)
+}
+
+// applyStatuses stores status updates and publishes their events
+func (s *webhookService) applyStatuses(ctx context.Context, updates []domain.StatusUpdate, events []WebhookEvent) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	// Update message statuses before publishing so events reflect stored state; a failure
+	// is returned so the provider redelivers the payload, which the dedupe keys make safe
+	results, err := s.repo.UpdateMessageStatuses(ctx, updates)
+	if err != nil {
+		s.logger.Error("Failed to update message statuses", "error", err, "count", len(updates))
+		return err
+	}
+	keys := make([]string, 0, len(events))
+	for _, event := range events {
+		keys = append(keys, event.DedupeKey)
+	}
+	s.replay.Processed(ctx, keys)
+
+	// Stage times recorded for the first time feed the delivery latency histogram
+	for _, result := range results {
+		if !result.DeliveredAt.IsZero() {
+			observeStageLatency(domain.StageDelivered, result.DeliveredAt.Sub(result.CreatedAt))
+		}
+		if !result.ReadAt.IsZero() {
+			observeStageLatency(domain.StageRead, result.ReadAt.Sub(result.CreatedAt))
+		}
+	}
+
+	// Each message's sequence advanced once per update; number its events in payload order
+	remaining := make(map[int64]int64, len(results))
+	for _, update := range updates {
+		remaining[update.MessageID]++
+	}
+	for _, event := range events {
+		result, ok := results[event.MessageID]
+		if !ok {
+			s.logger.Warn("Message disappeared before its status was stored", "message_id", event.MessageID)
+			continue
+		}
+		remaining[event.MessageID]--
+		event.Sequence = result.Sequence - remaining[event.MessageID]
+
+		// Publish event keyed by message ID to keep per-message ordering
+		eventData, err := json.Marshal(event)
+		if err != nil {
+			s.logger.Error("Failed to marshal webhook event", "error", err)
+			continue
+		}
+
+		if err := s.producer.ProduceWithKey(ctx, []byte(strconv.FormatInt(event.MessageID, 10)), eventData); err != nil {
+			s.logger.Error("Failed to produce webhook event to queue", "error", err)
+			continue
 		}
 	}
 
@@ -137,9 +633,9 @@ func (s *webhookService) ProcessWebhook(ctx context.Context, body []byte, signat
 }
 
 // UpdateMessageStatus updates the status of a message
-func (s *webhookService) UpdateMessageStatus(ctx context.Context, externalID, status, errorMessage string) error {
+func (s *webhookService) UpdateMessageStatus(ctx context.Context, externalID, status, errorCode, errorMessage string) error {
 	if externalID == "" {
-		return errors.New("external ID is required")
+		return domain.NewError(domain.ErrValidation, "external ID is required")
 	}
 
 	msg, err := s.repo.GetMessageByExternalID(ctx, externalID)
@@ -147,7 +643,7 @@ func (s *webhookService) UpdateMessageStatus(ctx context.Context, externalID, st
 		return err
 	}
 
-	return s.repo.UpdateMessageStatus(ctx, msg.ID, status, errorMessage, externalID)
+	return s.repo.UpdateMessageStatus(ctx, msg.ID, status, errorCode, errorMessage, externalID)
 }
 
 // GetVerifyToken returns the verification token for webhook setup
@@ -155,6 +651,21 @@ func (s *webhookService) GetVerifyToken() string {
 	return s.verifyToken
 }
 
+// statusDedupeKey derives a stable key for a provider status callback so that
+// redelivered webhooks for the same transition produce identical keys
+func statusDedupeKey(externalID, status, timestamp string) string {
+	return externalID + ":" + status + ":" + timestamp
+}
+
+// parseStatusTimestamp converts a webhook's Unix timestamp, returning the zero time if it is malformed
+func parseStatusTimestamp(timestamp string) time.Time {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
 // mapMetaStatus maps Meta status to internal status
 func mapMetaStatus(metaStatus string) string {
 	switch metaStatus {
@@ -0,0 +1,243 @@
+// pkg/alerts/alerts.go
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Alert is a threshold crossing or an event needing attention. Alerts are sent when they start
+// firing and again when they resolve, with the same Key. Event alerts leave Value and Threshold
+// zero.
+type Alert struct {
+	// Key identifies the condition, e.g. "consumer_lag:whatsapp-messages"
+	Key       string
+	Summary   string
+	Value     float64
+	Threshold float64
+	// Resolved is set when the value is back within the threshold
+	Resolved bool
+}
+
+// Notifier delivers alerts to an on-call channel
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// notifierTimeout bounds each notification request
+const notifierTimeout = 10 * time.Second
+
+// multiNotifier sends every alert to all of its notifiers
+type multiNotifier []Notifier
+
+// NewMultiNotifier combines notifiers; it returns nil when there are none
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return multiNotifier(notifiers)
+}
+
+// Notify sends to every notifier, returning their joined errors
+func (m multiNotifier) Notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// slackNotifier posts alerts to a Slack incoming webhook
+type slackNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(webhookURL string) Notifier {
+	return &slackNotifier{webhookURL: webhookURL, httpClient: &http.Client{Timeout: notifierTimeout}}
+}
+
+// Notify posts the alert as a message
+func (n *slackNotifier) Notify(ctx context.Context, alert Alert) error {
+	text := ":rotating_light: " + alert.Summary
+	if alert.Resolved {
+		text = ":white_check_mark: Resolved: " + alert.Summary
+	}
+	if alert.Value != 0 || alert.Threshold != 0 {
+		text += fmt.Sprintf(" (value %g, threshold %g)", alert.Value, alert.Threshold)
+	}
+	return postJSON(ctx, n.httpClient, n.webhookURL, map[string]string{"text": text})
+}
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyNotifier triggers and resolves PagerDuty incidents
+type pagerDutyNotifier struct {
+	routingKey string
+	source     string
+	url        string
+	httpClient *http.Client
+}
+
+// NewPagerDutyNotifier creates a notifier for a PagerDuty Events API v2 integration. Source
+// names the reporting service in incidents.
+func NewPagerDutyNotifier(routingKey, source string) Notifier {
+	return &pagerDutyNotifier{
+		routingKey: routingKey,
+		source:     source,
+		url:        pagerDutyEventsURL,
+		httpClient: &http.Client{Timeout: notifierTimeout},
+	}
+}
+
+// Notify triggers an incident, or resolves it, deduplicated by the alert key
+func (n *pagerDutyNotifier) Notify(ctx context.Context, alert Alert) error {
+	event := map[string]interface{}{
+		"routing_key":  n.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    alert.Key,
+		"payload": map[string]interface{}{
+			"summary":  alert.Summary,
+			"source":   n.source,
+			"severity": "error",
+			"custom_details": map[string]float64{
+				"value":     alert.Value,
+				"threshold": alert.Threshold,
+			},
+		},
+	}
+	if alert.Resolved {
+		event["event_action"] = "resolve"
+	}
+	return postJSON(ctx, n.httpClient, n.url, event)
+}
+
+// EmailConfig is the SMTP server alerts are mailed through and their recipients. Username and
+// Password are optional; with them the server must offer STARTTLS.
+type EmailConfig struct {
+	// Addr is the server's host:port, e.g. "smtp.example.com:587"
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// emailNotifier mails alerts through an SMTP server
+type emailNotifier struct {
+	config EmailConfig
+}
+
+// NewEmailNotifier creates a notifier mailing alerts to config.To
+func NewEmailNotifier(config EmailConfig) Notifier {
+	return &emailNotifier{config: config}
+}
+
+// Notify mails the alert, with the key in the subject so replies thread by condition
+func (n *emailNotifier) Notify(ctx context.Context, alert Alert) error {
+	subject := "[ALERT] " + alert.Summary
+	if alert.Resolved {
+		subject = "[RESOLVED] " + alert.Summary
+	}
+	body := fmt.Sprintf("%s\r\n\r\nAlert: %s\r\n", alert.Summary, alert.Key)
+	if alert.Value != 0 || alert.Threshold != 0 {
+		body += fmt.Sprintf("Value: %g\r\nThreshold: %g\r\n", alert.Value, alert.Threshold)
+	}
+	message := "From: " + n.config.From + "\r\n" +
+		"To: " + strings.Join(n.config.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" + body
+	return n.send(ctx, []byte(message))
+}
+
+// send delivers message like smtp.SendMail, within ctx and notifierTimeout
+func (n *emailNotifier) send(ctx context.Context, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, notifierTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.config.Addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	host, _, _ := net.SplitHostPort(n.config.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// postJSON posts body as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert notification returned status %d", resp.StatusCode)
+	}
+	return nil
+}
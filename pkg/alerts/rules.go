@@ -0,0 +1,248 @@
+// pkg/alerts/rules.go
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	dto "github.com/prometheus/client_model/go"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// alertFiring is 1 for the rules firing on this replica
+var alertFiring = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "whatsapp_alert_firing",
+	Help: "Whether an alerting rule is firing (1) or not (0), by alert key.",
+}, []string{"alert"})
+
+// Measure returns the value a rule watches. ok is false when there is nothing to judge yet,
+// e.g. no sends since the last evaluation; the rule then keeps its state.
+type Measure func(ctx context.Context) (value float64, ok bool, err error)
+
+// Rule is a measurement compared with a threshold on every evaluation
+type Rule struct {
+	// Key identifies the rule's alerts, e.g. "dlq_depth:whatsapp-messages.dlq"
+	Key     string
+	Measure Measure
+	// Threshold is the value the rule fires above, or below it with Below
+	Threshold float64
+	Below     bool
+	// Summary describes a measured value in the alert
+	Summary func(value float64) string
+}
+
+// firing reports whether value crosses the threshold
+func (r Rule) firing(value float64) bool {
+	if r.Below {
+		return value < r.Threshold
+	}
+	return value > r.Threshold
+}
+
+// Engine evaluates rules and notifies when they start firing and when they resolve
+type Engine interface {
+	// Evaluate measures every rule once
+	Evaluate(ctx context.Context)
+	// Run evaluates right away, then every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// engine implements Engine
+type engine struct {
+	rules    []Rule
+	notifier Notifier
+	logger   utils.Logger
+	firing   map[string]bool
+}
+
+// NewEngine creates an engine for rules. Alerts go to notifier, or are only logged when it is
+// nil.
+func NewEngine(rules []Rule, notifier Notifier, logger utils.Logger) Engine {
+	return &engine{
+		rules:    rules,
+		notifier: notifier,
+		logger:   logger,
+		firing:   make(map[string]bool),
+	}
+}
+
+// Evaluate measures the rules in order; a rule that fails to measure keeps its state
+func (e *engine) Evaluate(ctx context.Context) {
+	for _, rule := range e.rules {
+		value, ok, err := rule.Measure(ctx)
+		if err != nil {
+			e.logger.Error("Failed to evaluate alerting rule", "error", err, "alert", rule.Key)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		firing := rule.firing(value)
+		if firing == e.firing[rule.Key] {
+			continue
+		}
+		e.firing[rule.Key] = firing
+		alert := Alert{
+			Key:       rule.Key,
+			Summary:   rule.Summary(value),
+			Value:     value,
+			Threshold: rule.Threshold,
+			Resolved:  !firing,
+		}
+		e.notify(ctx, alert)
+	}
+}
+
+// notify logs and sends an alert starting or stopping to fire
+func (e *engine) notify(ctx context.Context, alert Alert) {
+	if alert.Resolved {
+		alertFiring.WithLabelValues(alert.Key).Set(0)
+		e.logger.Info("Alert resolved", "alert", alert.Key, "value", alert.Value, "threshold", alert.Threshold)
+	} else {
+		alertFiring.WithLabelValues(alert.Key).Set(1)
+		e.logger.Warn("Alert firing", "alert", alert.Key, "value", alert.Value, "threshold", alert.Threshold)
+	}
+	if e.notifier == nil {
+		return
+	}
+	if err := e.notifier.Notify(ctx, alert); err != nil {
+		e.logger.Error("Failed to send alert", "error", err, "alert", alert.Key)
+	}
+}
+
+// Run evaluates right away, so counters have a starting point, then every interval
+func (e *engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	e.Evaluate(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate(ctx)
+		}
+	}
+}
+
+// CounterIncrease measures how much the series of counter name matching labels grew since the
+// previous evaluation. The first evaluation only records where they stand.
+func CounterIncrease(gatherer prometheus.Gatherer, name string, labels map[string]string) Measure {
+	var mu sync.Mutex
+	last, started := 0.0, false
+	return func(context.Context) (float64, bool, error) {
+		value, err := gatherCounter(gatherer, name, labels)
+		if err != nil {
+			return 0, false, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		increase, ok := increaseSince(last, value), started
+		last, started = value, true
+		return increase, ok, nil
+	}
+}
+
+// CounterShare measures the share of the growth of counter name since the previous evaluation
+// that was in series matching labels, from 0 to 1: e.g. the share of sends that failed. It
+// is not judged until the counter grew by at least minTotal.
+func CounterShare(gatherer prometheus.Gatherer, name string, labels map[string]string, minTotal float64) Measure {
+	var mu sync.Mutex
+	lastPart, lastTotal, started := 0.0, 0.0, false
+	return func(context.Context) (float64, bool, error) {
+		part, err := gatherCounter(gatherer, name, labels)
+		if err != nil {
+			return 0, false, err
+		}
+		total, err := gatherCounter(gatherer, name, nil)
+		if err != nil {
+			return 0, false, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		partIncrease, totalIncrease := increaseSince(lastPart, part), increaseSince(lastTotal, total)
+		ok := started && totalIncrease > 0 && totalIncrease >= minTotal
+		// Quiet intervals carry over, so a trickle of sends is judged once there are enough
+		if ok || !started {
+			lastPart, lastTotal, started = part, total, true
+		}
+		if !ok {
+			return 0, false, nil
+		}
+		return partIncrease / totalIncrease, true, nil
+	}
+}
+
+// TimeUntil measures the seconds until the Unix time gauge name holds, e.g. a token's expiry.
+// A missing or zero gauge, meaning no such time, is not judged.
+func TimeUntil(gatherer prometheus.Gatherer, name string) Measure {
+	return func(context.Context) (float64, bool, error) {
+		families, err := gatherer.Gather()
+		if err != nil {
+			return 0, false, fmt.Errorf("gather %s: %w", name, err)
+		}
+		for _, family := range families {
+			if family.GetName() != name || len(family.GetMetric()) == 0 {
+				continue
+			}
+			at := family.GetMetric()[0].GetGauge().GetValue()
+			if at == 0 {
+				return 0, false, nil
+			}
+			return time.Until(time.Unix(int64(at), 0)).Seconds(), true, nil
+		}
+		return 0, false, nil
+	}
+}
+
+// gatherCounter sums the series of counter name matching labels; a counter not registered
+// yet, or without series, is 0
+func gatherCounter(gatherer prometheus.Gatherer, name string, labels map[string]string) (float64, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return 0, fmt.Errorf("gather %s: %w", name, err)
+	}
+	sum := 0.0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if matchLabels(metric.GetLabel(), labels) {
+				sum += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return sum, nil
+}
+
+// matchLabels reports whether a series has all of labels
+func matchLabels(pairs []*dto.LabelPair, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range pairs {
+		if value, ok := labels[pair.GetName()]; ok {
+			if value != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+// increaseSince is how much a counter grew from last, counting from zero after a reset
+func increaseSince(last, value float64) float64 {
+	if value < last {
+		return value
+	}
+	return value - last
+}
@@ -0,0 +1,227 @@
+// pkg/app/app.go
+
+// Package app is the composition root's container: constructors of shared components are
+// registered with Provide and built once on demand, and servers, consumers and background
+// workers register start and stop hooks that run in order when the app starts and in reverse
+// when it stops.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// Hook starts and stops a component. OnStart must not block: long-running work goes in a
+// goroutine, or in a worker registered with Go. Either function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// App holds the components of a process and runs their lifecycle
+type App struct {
+	logger utils.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	running bool
+	stopped bool
+
+	failOnce sync.Once
+	failed   chan struct{}
+	failure  error
+
+	providers  map[reflect.Type]*provider
+	supervised []*supervised
+}
+
+// New creates an empty app
+func New(logger utils.Logger) *App {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &App{
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		failed:    make(chan struct{}),
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// Context returns the context shared by the app's components, done once the app has stopped
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// Append registers a hook. Hooks start in the order they were appended and stop in reverse, so
+// a component appended after the ones it uses stops before them. Hooks must be appended before
+// Start.
+func (a *App) Append(hook Hook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running || a.stopped {
+		panic(fmt.Sprintf("app: hook %s appended after start", hook.Name))
+	}
+	a.hooks = append(a.hooks, hook)
+}
+
+// Closer registers closing c when the app stops, like a deferred Close
+func (a *App) Closer(name string, c io.Closer) {
+	a.Append(Hook{Name: name, OnStop: func(context.Context) error { return c.Close() }})
+}
+
+// Go registers a background worker. run is started with a context derived from the app's when
+// the app starts, and on stop its context is cancelled and it is waited for.
+func (a *App) Go(name string, run func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	a.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(a.ctx)
+			go func() {
+				defer close(done)
+				run(ctx)
+				if ctx.Err() == nil {
+					a.logger.Warn("Background worker returned before the app stopped", "component", name)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("did not return: %w", ctx.Err())
+			}
+		},
+	})
+}
+
+// Serve registers a server listening on addr: it starts listening when the app starts, so a
+// taken port fails the start, and serves in the background. A serve error fails the app; stop
+// shuts the server down.
+func (a *App) Serve(name, addr string, serve func(lis net.Listener) error, stop func(ctx context.Context) error) {
+	a.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			a.logger.Info("Listening", "component", name, "addr", lis.Addr().String())
+			go func() {
+				if err := serve(lis); err != nil {
+					a.Fail(fmt.Errorf("%s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		OnStop: stop,
+	})
+}
+
+// Fail stops a running app from within, e.g. when a server dies; Run returns err. Only the
+// first failure is kept.
+func (a *App) Fail(err error) {
+	a.failOnce.Do(func() {
+		a.failure = err
+		close(a.failed)
+	})
+}
+
+// Start runs the start hooks in order. When one fails, the hooks already started are stopped
+// and the failure is returned.
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	if a.running || a.stopped {
+		a.mu.Unlock()
+		return errors.New("app: already started")
+	}
+	a.running = true
+	hooks := a.hooks
+	a.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				err = fmt.Errorf("start %s: %w", hook.Name, err)
+				a.logger.Error("Failed to start component", "component", hook.Name, "error", err)
+				return errors.Join(err, a.Stop(ctx))
+			}
+		}
+		a.mu.Lock()
+		a.started = i + 1
+		a.mu.Unlock()
+	}
+	a.logger.Info("Started components", "count", len(hooks))
+	return nil
+}
+
+// Stop runs the stop hooks of the started components in reverse order, then cancels the
+// shared context. Every hook runs, whatever the others return; the failures are joined.
+func (a *App) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return nil
+	}
+	a.stopped = true
+	hooks := a.hooks[:a.started]
+	a.mu.Unlock()
+	defer a.cancel()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].OnStop == nil {
+			continue
+		}
+		if err := hooks[i].OnStop(ctx); err != nil {
+			a.logger.Error("Failed to stop component", "component", hooks[i].Name, "error", err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", hooks[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts the app, waits for one of signals or a failure, and stops it within timeout. It
+// returns the failure or start error, joined with the errors of stopping; nil after a signal
+// and a clean stop.
+func (a *App) Run(timeout time.Duration, signals ...os.Signal) error {
+	if err := a.Start(a.ctx); err != nil {
+		return err
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	defer signal.Stop(quit)
+
+	var err error
+	select {
+	case sig := <-quit:
+		a.logger.Info("Shutting down", "signal", sig.String())
+	case <-a.failed:
+		err = a.failure
+		a.logger.Error("Shutting down after a component failed", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return errors.Join(err, a.Stop(ctx))
+}
@@ -0,0 +1,65 @@
+// pkg/app/provide.go
+package app
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// provider builds one component type once
+type provider struct {
+	build    func(a *App) (interface{}, error)
+	building bool
+	built    bool
+	value    interface{}
+	err      error
+}
+
+// Provide registers the constructor of T. It runs on the first Resolve of T, which is how a
+// component gets what it needs without the composition root passing it along; components with
+// a lifecycle append their hooks from it. A later Provide of the same type replaces the
+// constructor, so tests can swap in fakes. Provide and Resolve are meant for the goroutine
+// composing the app.
+func Provide[T any](a *App, build func(a *App) (T, error)) {
+	a.providers[typeOf[T]()] = &provider{build: func(a *App) (interface{}, error) {
+		return build(a)
+	}}
+}
+
+// Supply registers a component already built
+func Supply[T any](a *App, value T) {
+	a.providers[typeOf[T]()] = &provider{built: true, value: value}
+}
+
+// Resolve returns the T built by its constructor, building it on the first call. It fails for
+// types nothing provides and for constructors that need themselves.
+func Resolve[T any](a *App) (T, error) {
+	var zero T
+	t := typeOf[T]()
+	p, ok := a.providers[t]
+	if !ok {
+		return zero, fmt.Errorf("app: no constructor provided for %v", t)
+	}
+	if !p.built {
+		if p.building {
+			return zero, fmt.Errorf("app: %v depends on itself", t)
+		}
+		p.building = true
+		value, err := p.build(a)
+		p.building = false
+		p.built, p.value, p.err = true, value, err
+		if err != nil {
+			p.err = fmt.Errorf("build %v: %w", t, err)
+		}
+	}
+	if p.err != nil {
+		return zero, p.err
+	}
+	value, _ := p.value.(T)
+	return value, nil
+}
+
+// typeOf returns the type T, interfaces included
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,154 @@
+// pkg/app/supervisor.go
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/utils"
+)
+
+var (
+	componentUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_component_up",
+		Help: "Whether a supervised component is running (1) or waiting to be restarted (0).",
+	}, []string{"component"})
+
+	componentRestartsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_component_restarts_total",
+		Help: "Restarts of supervised components that returned or panicked while the app was running.",
+	}, []string{"component"})
+)
+
+// RestartPolicy is how a supervised component is restarted. Restarts wait Backoff.Delay of the
+// failures in a row; once Backoff.Attempts failures are in a row the component is reported down,
+// and restarts go on. A run lasting StableAfter forgets the failures before it.
+type RestartPolicy struct {
+	Backoff     utils.Backoff
+	StableAfter time.Duration
+}
+
+// DefaultRestartPolicy restarts after 1s, doubling up to a minute, and reports a component
+// down after five failures in a row
+var DefaultRestartPolicy = RestartPolicy{
+	Backoff:     utils.Backoff{Attempts: 5, Initial: time.Second, Max: time.Minute, Jitter: 0.2},
+	StableAfter: time.Minute,
+}
+
+// supervised is the state of a supervised component
+type supervised struct {
+	name   string
+	policy RestartPolicy
+	logger utils.Logger
+
+	mu       sync.Mutex
+	failures int
+	lastErr  error
+}
+
+// Supervise registers a background component like Go, which is restarted whenever it returns
+// or panics before the app stops. Ready fails while the component is down.
+func (a *App) Supervise(name string, policy RestartPolicy, run func(ctx context.Context) error) {
+	c := &supervised{name: name, policy: policy, logger: a.logger}
+	a.mu.Lock()
+	a.supervised = append(a.supervised, c)
+	a.mu.Unlock()
+	a.Go(name, func(ctx context.Context) { c.run(ctx, run) })
+}
+
+// Ready returns an error naming the supervised components that are down, or nil; it serves as
+// a readiness check
+func (a *App) Ready(context.Context) error {
+	a.mu.Lock()
+	components := a.supervised
+	a.mu.Unlock()
+
+	var errs []error
+	for _, c := range components {
+		if err := c.down(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run runs fn until ctx is done, restarting it with backoff
+func (c *supervised) run(ctx context.Context, fn func(ctx context.Context) error) {
+	for {
+		stable := time.AfterFunc(c.policy.StableAfter, c.recovered)
+		componentUp.WithLabelValues(c.name).Set(1)
+		err := c.runOnce(ctx, fn)
+		componentUp.WithLabelValues(c.name).Set(0)
+		stable.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("returned")
+		}
+
+		failures, down := c.failed(err)
+		componentRestartsTotal.WithLabelValues(c.name).Inc()
+		delay := c.policy.Backoff.Delay(failures)
+		if down {
+			c.logger.Error("Component keeps failing; reporting not ready", "component", c.name, "failures", failures, "restart_in", delay, "error", err)
+		} else {
+			c.logger.Warn("Component stopped; restarting", "component", c.name, "failures", failures, "restart_in", delay, "error", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// runOnce runs fn, turning a panic into an error
+func (c *supervised) runOnce(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			c.logger.Error("Component panicked", "component", c.name, "panic", p, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
+
+// failed counts a failure, returning the failures in a row and whether the component is down
+func (c *supervised) failed(err error) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+	c.lastErr = err
+	return c.failures, c.failures >= c.policy.Backoff.Attempts
+}
+
+// recovered forgets the failures of a component that has been running for StableAfter
+func (c *supervised) recovered() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures >= c.policy.Backoff.Attempts {
+		c.logger.Info("Component recovered", "component", c.name, "failures", c.failures)
+	}
+	c.failures, c.lastErr = 0, nil
+}
+
+// down returns why the component is down, or nil
+func (c *supervised) down() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures == 0 || c.failures < c.policy.Backoff.Attempts {
+		return nil
+	}
+	return fmt.Errorf("%s failed %d times in a row: %w", c.name, c.failures, c.lastErr)
+}
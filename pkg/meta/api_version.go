@@ -0,0 +1,105 @@
+// pkg/meta/api_version.go
+package meta
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// graphURL is the Graph API host; requests go to graphURL/<version>
+const graphURL = "https://graph.facebook.com"
+
+// DefaultAPIVersion is the Graph API version used unless another is configured
+const DefaultAPIVersion = "v18.0"
+
+// apiVersionPattern matches Graph API versions such as v18.0
+var apiVersionPattern = regexp.MustCompile(`^v[1-9][0-9]*\.[0-9]+$`)
+
+// apiSupportPeriod is how long Meta guarantees a Graph API version keeps working after its
+// release
+const apiSupportPeriod = 2 * 365 * 24 * time.Hour
+
+// apiVersionReleases are the release dates of recent Graph API versions
+var apiVersionReleases = map[string]time.Time{
+	"v16.0": time.Date(2023, time.February, 2, 0, 0, 0, 0, time.UTC),
+	"v17.0": time.Date(2023, time.May, 23, 0, 0, 0, 0, time.UTC),
+	"v18.0": time.Date(2023, time.September, 12, 0, 0, 0, 0, time.UTC),
+	"v19.0": time.Date(2024, time.January, 23, 0, 0, 0, 0, time.UTC),
+	"v20.0": time.Date(2024, time.May, 21, 0, 0, 0, 0, time.UTC),
+	"v21.0": time.Date(2024, time.October, 2, 0, 0, 0, 0, time.UTC),
+	"v22.0": time.Date(2025, time.January, 21, 0, 0, 0, 0, time.UTC),
+	"v23.0": time.Date(2025, time.May, 29, 0, 0, 0, 0, time.UTC),
+}
+
+// ValidAPIVersion reports whether version is written like a Graph API version, e.g. v18.0
+func ValidAPIVersion(version string) bool {
+	return apiVersionPattern.MatchString(version)
+}
+
+// APIURL returns the Graph API base URL of a version
+func APIURL(version string) string {
+	return graphURL + "/" + version
+}
+
+// APIVersionEndOfLife returns the end of the support Meta guarantees for a version, two years
+// after its release. ok is false for versions this build does not know.
+func APIVersionEndOfLife(version string) (endOfLife time.Time, ok bool) {
+	released, ok := apiVersionReleases[version]
+	if !ok {
+		return time.Time{}, false
+	}
+	return released.Add(apiSupportPeriod), true
+}
+
+// CheckAPIVersion warns when version is past, or within warnBefore of, the end of its support
+func CheckAPIVersion(version string, now time.Time, warnBefore time.Duration, logger utils.Logger) {
+	endOfLife, ok := APIVersionEndOfLife(version)
+	if !ok {
+		logger.Info("Graph API version support period unknown; check Meta's changelog", "api_version", version)
+		return
+	}
+	switch {
+	case now.After(endOfLife):
+		logger.Warn("Graph API version is past its guaranteed support period and may stop working; set META_API_VERSION to a newer version",
+			"api_version", version, "supported_until", endOfLife.Format("2006-01-02"))
+	case now.Add(warnBefore).After(endOfLife):
+		logger.Warn("Graph API version is nearing the end of its guaranteed support period; plan an upgrade",
+			"api_version", version, "supported_until", endOfLife.Format("2006-01-02"))
+	}
+}
+
+// deprecationHeaders are response headers announcing that a request relies on something
+// Meta is retiring
+var deprecationHeaders = []string{"Deprecation", "Sunset", "X-Ad-Api-Version-Warning"}
+
+// deprecationLog warns about deprecation notices in Graph API responses, once per notice
+type deprecationLog struct {
+	version string
+	logger  utils.Logger
+	seen    sync.Map
+}
+
+// observe logs the deprecation notices of resp that were not logged before. Meta answers
+// calls to retired versions with the oldest version still served, named in
+// Facebook-Api-Version.
+func (d *deprecationLog) observe(resp *http.Response) {
+	if served := resp.Header.Get("Facebook-Api-Version"); served != "" && served != d.version {
+		if _, seen := d.seen.LoadOrStore("Facebook-Api-Version: "+served, true); !seen {
+			d.logger.Warn("Meta served a different Graph API version than requested; the requested one may be retired",
+				"api_version", d.version, "served_version", served)
+		}
+	}
+	for _, name := range deprecationHeaders {
+		value := resp.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if _, seen := d.seen.LoadOrStore(name+": "+value, true); !seen {
+			d.logger.Warn("Graph API response carries a deprecation notice", "api_version", d.version, "header", name, "value", value)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// pkg/meta/buttons.go
+package meta
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CTAURLTemplate is the template name messages are stored and queued under when they are an
+// interactive call-to-action URL message rather than a template. It can't clash with a real
+// template, whose names only have lowercase letters, digits and underscores.
+const CTAURLTemplate = "interactive:cta_url"
+
+// Parameters of CTAURLTemplate messages
+const (
+	CTAURLBody        = "body"
+	CTAURLDisplayText = "display_text"
+	CTAURLLink        = "url"
+	CTAURLHeader      = "header"
+	CTAURLFooter      = "footer"
+)
+
+// Limits Meta puts on CTA URL messages and URL buttons
+const (
+	maxCTABodyLength        = 1024
+	maxCTADisplayTextLength = 20
+	maxCTAHeaderLength      = 60
+	maxCTAFooterLength      = 60
+	maxTemplateButtons      = 10
+)
+
+// buttonURLPrefix marks template parameters holding the dynamic suffix of a URL button
+const buttonURLPrefix = "button_url."
+
+// ButtonURLParameter names the template parameter holding the dynamic URL suffix of the
+// template's button at index (counting all its buttons, call buttons included)
+func ButtonURLParameter(index int) string {
+	return buttonURLPrefix + strconv.Itoa(index)
+}
+
+// IsButtonURLParameter reports whether a template parameter is a button URL suffix
+func IsButtonURLParameter(name string) bool {
+	return strings.HasPrefix(name, buttonURLPrefix)
+}
+
+// ValidateButtonURLParameters checks the button URL parameters name valid button indexes
+func ValidateButtonURLParameters(parameters map[string]interface{}) error {
+	for name := range parameters {
+		if !IsButtonURLParameter(name) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(name, buttonURLPrefix))
+		if err != nil || index < 0 || index >= maxTemplateButtons {
+			return fmt.Errorf("button index in %q must be between 0 and %d", name, maxTemplateButtons-1)
+		}
+	}
+	return nil
+}
+
+// ValidateCTAURL checks the parameters of a CTAURLTemplate message
+func ValidateCTAURL(parameters map[string]interface{}) error {
+	text := func(name string) string {
+		value, _ := parameters[name].(string)
+		return value
+	}
+
+	if body := text(CTAURLBody); body == "" || len(body) > maxCTABodyLength {
+		return fmt.Errorf("body is required and must be at most %d characters", maxCTABodyLength)
+	}
+	if displayText := text(CTAURLDisplayText); displayText == "" || len(displayText) > maxCTADisplayTextLength {
+		return fmt.Errorf("display_text is required and must be at most %d characters", maxCTADisplayTextLength)
+	}
+	link, err := url.Parse(text(CTAURLLink))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	if len(text(CTAURLHeader)) > maxCTAHeaderLength {
+		return fmt.Errorf("header must be at most %d characters", maxCTAHeaderLength)
+	}
+	if len(text(CTAURLFooter)) > maxCTAFooterLength {
+		return fmt.Errorf("footer must be at most %d characters", maxCTAFooterLength)
+	}
+	return nil
+}
+
+// buildButtonComponents builds the button components filling in URL button suffixes, in
+// button order
+func buildButtonComponents(parameters map[string]interface{}) []map[string]interface{} {
+	var indexes []int
+	for name := range parameters {
+		if IsButtonURLParameter(name) {
+			if index, err := strconv.Atoi(strings.TrimPrefix(name, buttonURLPrefix)); err == nil {
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	sort.Ints(indexes)
+
+	components := make([]map[string]interface{}, 0, len(indexes))
+	for _, index := range indexes {
+		components = append(components, map[string]interface{}{
+			"type":     "button",
+			"sub_type": "url",
+			"index":    strconv.Itoa(index),
+			"parameters": []map[string]interface{}{{
+				"type": "text",
+				"text": fmt.Sprintf("%v", parameters[ButtonURLParameter(index)]),
+			}},
+		})
+	}
+	return components
+}
+
+// buildCTAURLPayload builds the request of an interactive CTA URL message
+func buildCTAURLPayload(to string, parameters map[string]interface{}) map[string]interface{} {
+	text := func(name string) string {
+		return fmt.Sprintf("%v", parameters[name])
+	}
+
+	interactive := map[string]interface{}{
+		"type": "cta_url",
+		"body": map[string]string{"text": text(CTAURLBody)},
+		"action": map[string]interface{}{
+			"name": "cta_url",
+			"parameters": map[string]string{
+				"display_text": text(CTAURLDisplayText),
+				"url":          text(CTAURLLink),
+			},
+		},
+	}
+	if _, ok := parameters[CTAURLHeader]; ok {
+		interactive["header"] = map[string]string{"type": "text", "text": text(CTAURLHeader)}
+	}
+	if _, ok := parameters[CTAURLFooter]; ok {
+		interactive["footer"] = map[string]string{"text": text(CTAURLFooter)}
+	}
+
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "interactive",
+		"interactive":       interactive,
+	}
+}
@@ -12,8 +12,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
-	"time"
 
 	"messaging-microservice/pkg/utils"
 )
@@ -32,6 +32,32 @@ type MessageResponse struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	} `json:"error,omitempty"`
+
+	// RequestPayload is the JSON body that was sent to the API
+	RequestPayload []byte `json:"-"`
+}
+
+// APIError is returned when the Meta API rejects a request
+type APIError struct {
+	StatusCode int
+	Code       int
+	Type       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("meta API error: %d - %s", e.Code, e.Message)
+}
+
+// ErrorCode returns the Meta error code as a string, as stored with failed messages
+func (e *APIError) ErrorCode() string {
+	return strconv.Itoa(e.Code)
+}
+
+// Provider names the provider the error code belongs to
+func (e *APIError) Provider() string {
+	return "meta"
 }
 
 // Client defines the interface for WhatsApp API clients
@@ -43,27 +69,78 @@ type Client interface {
 // metaClient implements Client using Meta WhatsApp API
 type metaClient struct {
 	phoneNumberID string
-	accessToken   string
+	tokens        TokenSource
 	appSecret     string
+	apiVersion    string
 	apiURL        string
-	httpClient    *http.Client
+	baseURL       string
+	httpClient    utils.HTTPClient
+	timeout       utils.CallTimeout
+	deprecations  *deprecationLog
 	logger        utils.Logger
 }
 
-// NewClient creates a new Meta WhatsApp client
-func NewClient(phoneNumberID, accessToken, appSecret string, logger utils.Logger) Client {
-	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
+// Option configures a Meta client
+type Option func(*metaClient)
+
+// WithCallTimeout bounds each API request by the caller's deadline within timeout, instead of
+// utils.DefaultCallTimeout
+func WithCallTimeout(timeout utils.CallTimeout) Option {
+	return func(c *metaClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithAPIVersion sends requests to the given Graph API version, such as v21.0, instead of
+// DefaultAPIVersion
+func WithAPIVersion(version string) Option {
+	return func(c *metaClient) {
+		c.apiVersion = version
+	}
+}
+
+// WithAPIURL sends requests to url, such as a metatest server, instead of the Graph API URL of
+// the version
+func WithAPIURL(url string) Option {
+	return func(c *metaClient) {
+		c.baseURL = url
+	}
+}
+
+// WithHTTPClient sets the retries, per-attempt timeout and transport of API requests, instead
+// of utils.DefaultHTTPRetry. Message sends are only retried when they could not connect.
+func WithHTTPClient(cfg utils.HTTPClientConfig) Option {
+	return func(c *metaClient) {
+		c.httpClient = utils.NewHTTPClientWithConfig(cfg, c.logger)
 	}
+}
+
+// NewClient creates a new Meta WhatsApp client
+func NewClient(phoneNumberID, accessToken, appSecret string, logger utils.Logger, opts ...Option) Client {
+	return NewClientWithTokenSource(phoneNumberID, staticToken(accessToken), appSecret, logger, opts...)
+}
 
-	return &metaClient{
+// NewClientWithTokenSource creates a Meta WhatsApp client that takes its access token from tokens
+func NewClientWithTokenSource(phoneNumberID string, tokens TokenSource, appSecret string, logger utils.Logger, opts ...Option) Client {
+	c := &metaClient{
 		phoneNumberID: phoneNumberID,
-		accessToken:   accessToken,
+		tokens:        tokens,
 		appSecret:     appSecret,
-		apiURL:        "https://graph.facebook.com/v18.0", // Using v18.0 as it's current as of writing
-		httpClient:    httpClient,
-		logger:        logger,
+		apiVersion:    DefaultAPIVersion,
+		// Requests are bounded by their context, see timeout
+		httpClient: utils.NewHTTPClientWithConfig(utils.HTTPClientConfig{Retry: utils.DefaultHTTPRetry}, logger),
+		timeout:    utils.DefaultCallTimeout,
+		logger:     logger,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.apiURL = APIURL(c.apiVersion)
+	if c.baseURL != "" {
+		c.apiURL = c.baseURL
+	}
+	c.deprecations = &deprecationLog{version: c.apiVersion, logger: logger}
+	return c
 }
 
 // SendTemplateMessage sends a WhatsApp template message through Meta's API
@@ -71,22 +148,14 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 	// Normalize phone number (remove WhatsApp prefix if present)
 	to = c.normalizePhoneNumber(to)
 
-	// Build template components based on parameters
-	components, err := c.buildTemplateComponents(parameters)
-	if err != nil {
-		return nil, err
-	}
-
-	// Prepare request payload
-	payload := map[string]interface{}{
-		"messaging_product": "whatsapp",
-		"to":                to,
-		"type":              "template",
-		"template": map[string]interface{}{
-			"name":       templateName,
-			"language":   map[string]string{"code": "en_US"},
-			"components": components,
-		},
+	// Prepare request payload; CTA URL and product messages are interactive messages, not templates
+	var payload map[string]interface{}
+	if templateName == CTAURLTemplate {
+		payload = buildCTAURLPayload(to, parameters)
+	} else if IsProductTemplate(templateName) {
+		payload = buildProductPayload(to, templateName, parameters)
+	} else {
+		payload = BuildTemplatePayload(to, templateName, DefaultTemplateLanguage, parameters)
 	}
 
 	// Convert payload to JSON
@@ -95,6 +164,13 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 		return nil, err
 	}
 
+	// Bound the request by the caller's deadline
+	ctx, cancel, err := c.timeout.Context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
 	// Create request
 	url := fmt.Sprintf("%s/%s/messages", c.apiURL, c.phoneNumberID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payloadBytes))
@@ -102,9 +178,14 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 		return nil, err
 	}
 
+	accessToken, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
 
 	// Send request
 	resp, err := c.httpClient.Do(req)
@@ -112,6 +193,7 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 		return nil, err
 	}
 	defer resp.Body.Close()
+	c.deprecations.observe(resp)
 
 	// Read response body
 	body, err := ioutil.ReadAll(resp.Body)
@@ -122,6 +204,21 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 	// Check for error status code
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		c.logger.Error("Meta API error", "status", resp.StatusCode, "body", string(body))
+
+		// Meta wraps errors in {"error": {...}}; fall back to the raw body otherwise
+		var errorResponse MessageResponse
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error != nil {
+			apiErr := &APIError{
+				StatusCode: resp.StatusCode,
+				Code:       errorResponse.Error.Code,
+				Type:       errorResponse.Error.Type,
+				Message:    errorResponse.Error.Message,
+			}
+			if apiErr.Code == metaInvalidTokenCode {
+				c.tokens.ReportInvalid(apiErr)
+			}
+			return nil, apiErr
+		}
 		return nil, fmt.Errorf("meta API error: %d - %s", resp.StatusCode, string(body))
 	}
 
@@ -131,9 +228,16 @@ func (c *metaClient) SendTemplateMessage(ctx context.Context, to, templateName s
 		return nil, err
 	}
 
+	messageResponse.RequestPayload = payloadBytes
+
 	// Check for error in response
 	if messageResponse.Error != nil {
-		return &messageResponse, fmt.Errorf("meta API error: %d - %s", messageResponse.Error.Code, messageResponse.Error.Message)
+		return &messageResponse, &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       messageResponse.Error.Code,
+			Type:       messageResponse.Error.Type,
+			Message:    messageResponse.Error.Message,
+		}
 	}
 
 	return &messageResponse, nil
@@ -157,8 +261,8 @@ func (c *metaClient) ValidateWebhookSignature(signature string, _ string, body [
 	h.Write(body)
 	expectedSignature := hex.EncodeToString(h.Sum(nil))
 
-	// Compare signatures
-	return receivedSignature == expectedSignature
+	// Compare signatures in constant time
+	return hmac.Equal([]byte(receivedSignature), []byte(expectedSignature))
 }
 
 // Helper methods
@@ -168,32 +272,6 @@ func (c *metaClient) normalizePhoneNumber(phoneNumber string) string {
 	return strings.TrimPrefix(phoneNumber, "whatsapp:")
 }
 
-// buildTemplateComponents builds the components array for a template message
-func (c *metaClient) buildTemplateComponents(parameters map[string]interface{}) ([]map[string]interface{}, error) {
-	if len(parameters) == 0 {
-		return nil, nil
-	}
-
-	// Convert parameters to component format
-	var params []map[string]interface{}
-	for _, value := range parameters {
-		params = append(params, map[string]interface{}{
-			"type": "text",
-			"text": fmt.Sprintf("%v", value),
-		})
-	}
-
-	// Create the body component with parameters
-	components := []map[string]interface{}{
-		{
-			"type":       "body",
-			"parameters": params,
-		},
-	}
-
-	return components, nil
-}
-
 // GetMessageExternalID extracts the external message ID from the response
 func (c *metaClient) GetMessageExternalID(response *MessageResponse) (string, error) {
 	if response == nil {
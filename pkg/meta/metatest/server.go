@@ -0,0 +1,255 @@
+// pkg/meta/metatest/server.go
+
+// Package metatest provides a fake Meta Graph API for tests. It answers message sends and
+// template lookups like the Cloud API, or with scripted errors, rate limits and delays, and
+// builds webhooks signed like Meta's, so provider behavior can be simulated deterministically.
+package metatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"messaging-microservice/pkg/meta"
+)
+
+// Request is a call the server received
+type Request struct {
+	Method string
+	// Version is the Graph API version of the path, such as v18.0, when it has one
+	Version string
+	// Path is the path after the version, such as /1234/messages
+	Path  string
+	Query string
+	// Token is the bearer token of the Authorization header
+	Token string
+	Body  []byte
+}
+
+// Payload decodes the JSON body of the request
+func (r Request) Payload() (map[string]interface{}, error) {
+	var payload map[string]interface{}
+	err := json.Unmarshal(r.Body, &payload)
+	return payload, err
+}
+
+// Error is a Graph API error, as returned in the error object of a response
+type Error struct {
+	Code      int    `json:"code"`
+	Subcode   int    `json:"error_subcode,omitempty"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	FBTraceID string `json:"fbtrace_id,omitempty"`
+}
+
+// Response is how the server answers a request. A response without Error accepts it: sends
+// get MessageID, or a generated wamid when it is empty.
+type Response struct {
+	Status    int
+	Error     *Error
+	MessageID string
+	// Header is added to the response, e.g. Retry-After or a deprecation notice
+	Header http.Header
+	// Delay holds the response back, to run into the caller's deadline
+	Delay time.Duration
+}
+
+// Accepted accepts a send with the external ID id
+func Accepted(id string) Response {
+	return Response{Status: http.StatusOK, MessageID: id}
+}
+
+// Fail rejects a request with a Graph API error
+func Fail(status, code int, message string) Response {
+	return Response{Status: status, Error: &Error{Code: code, Type: "OAuthException", Message: message}}
+}
+
+// RateLimited rejects a request as over the phone number's throughput, asking to retry after
+// retryAfter
+func RateLimited(retryAfter time.Duration) Response {
+	resp := Fail(http.StatusTooManyRequests, 130429, "(#130429) Rate limit hit")
+	resp.Header = http.Header{"Retry-After": {strconv.Itoa(int(retryAfter.Seconds()))}}
+	return resp
+}
+
+// InvalidToken rejects a request as made with an expired or revoked access token
+func InvalidToken() Response {
+	resp := Fail(http.StatusUnauthorized, 190, "Error validating access token: Session has expired.")
+	resp.Error.Subcode = 463
+	return resp
+}
+
+// Unavailable rejects a request as Meta does during an outage
+func Unavailable() Response {
+	return Fail(http.StatusServiceUnavailable, 2, "Service temporarily unavailable")
+}
+
+// Undeliverable rejects a send to a recipient that cannot receive it
+func Undeliverable() Response {
+	return Fail(http.StatusBadRequest, 131026, "Message undeliverable")
+}
+
+// Server is a fake Graph API on a local httptest server. Requests are answered with the
+// responses queued with Enqueue in order, then with Default. Paths may carry a version prefix,
+// so clients can be pointed at URL or at URL/<version>.
+type Server struct {
+	*httptest.Server
+	// AppSecret signs webhooks
+	AppSecret string
+
+	mu        sync.Mutex
+	script    []Response
+	fallback  Response
+	requests  []Request
+	templates []meta.TemplateDefinition
+	sent      int
+}
+
+// NewServer starts a fake Graph API whose webhooks are signed with appSecret; Close it when done
+func NewServer(appSecret string) *Server {
+	s := &Server{AppSecret: appSecret, fallback: Response{Status: http.StatusOK}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// APIURL returns the base URL of a Graph API version on the server
+func (s *Server) APIURL(version string) string {
+	return s.URL + "/" + version
+}
+
+// Enqueue queues responses for the next requests, in order
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// Default sets how requests are answered once the queued responses are used up, instead of
+// accepting them
+func (s *Server) Default(resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = resp
+}
+
+// AddTemplates makes templates available to template lookups
+func (s *Server) AddTemplates(templates ...meta.TemplateDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = append(s.templates, templates...)
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets the requests received and the queued responses, and accepts requests again
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests, s.script, s.sent = nil, nil, 0
+	s.fallback = Response{Status: http.StatusOK}
+}
+
+// serve records a request and answers it with the next response
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Token:  strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		Body:   body,
+	}
+	if segments := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2); len(segments) == 2 && meta.ValidAPIVersion(segments[0]) {
+		req.Version, req.Path = segments[0], "/"+segments[1]
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	resp := s.fallback
+	if len(s.script) > 0 {
+		resp, s.script = s.script[0], s.script[1:]
+	}
+	var result interface{}
+	if resp.Error == nil {
+		result = s.result(r, req, resp)
+	}
+	s.mu.Unlock()
+
+	if resp.Delay > 0 {
+		select {
+		case <-time.After(resp.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if resp.Error != nil {
+		result = map[string]interface{}{"error": resp.Error}
+	} else if result == nil {
+		status = http.StatusBadRequest
+		result = map[string]interface{}{"error": Error{
+			Code:    100,
+			Type:    "GraphMethodException",
+			Message: fmt.Sprintf("Unsupported %s request to %s", strings.ToLower(r.Method), req.Path),
+		}}
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// result is the body accepting a request, or nil for requests the server does not know. s.mu
+// is held.
+func (s *Server) result(r *http.Request, req Request, resp Response) interface{} {
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(req.Path, "/messages"):
+		var payload struct {
+			To     string `json:"to"`
+			Status string `json:"status"`
+		}
+		_ = json.Unmarshal(req.Body, &payload)
+		// Read receipts and typing indicators go to the same endpoint as sends
+		if payload.Status != "" {
+			return map[string]bool{"success": true}
+		}
+		id := resp.MessageID
+		if id == "" {
+			s.sent++
+			id = fmt.Sprintf("wamid.metatest-%d", s.sent)
+		}
+		return map[string]interface{}{
+			"messaging_product": "whatsapp",
+			"contacts":          []map[string]string{{"input": payload.To, "wa_id": strings.TrimPrefix(payload.To, "+")}},
+			"messages":          []map[string]string{{"id": id}},
+		}
+	case r.Method == http.MethodGet && strings.HasSuffix(req.Path, "/message_templates"):
+		// Like Meta, the name filter matches names containing it
+		name, language := r.URL.Query().Get("name"), r.URL.Query().Get("language")
+		data := []meta.TemplateDefinition{}
+		for _, tmpl := range s.templates {
+			if strings.Contains(tmpl.Name, name) && (language == "" || tmpl.Language == language) {
+				data = append(data, tmpl)
+			}
+		}
+		return map[string]interface{}{"data": data}
+	}
+	return nil
+}
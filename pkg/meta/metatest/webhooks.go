@@ -0,0 +1,93 @@
+// pkg/meta/metatest/webhooks.go
+package metatest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SignatureHeader is the header Meta signs webhooks in
+const SignatureHeader = "X-Hub-Signature-256"
+
+// Sign returns the X-Hub-Signature-256 value of body under appSecret
+func Sign(appSecret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Status is a message status reported in a webhook
+type Status struct {
+	ExternalID string
+	Recipient  string
+	// Status is sent, delivered, read or failed
+	Status string
+	At     time.Time
+	// ErrorCode and ErrorMessage describe why a failed message failed
+	ErrorCode    int
+	ErrorMessage string
+}
+
+// StatusWebhook builds the body of a webhook reporting statuses of messages sent from
+// phoneNumberID, in the shape Meta sends
+func StatusWebhook(phoneNumberID string, statuses ...Status) []byte {
+	values := make([]map[string]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		at := status.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		value := map[string]interface{}{
+			"id":           status.ExternalID,
+			"recipient_id": status.Recipient,
+			"status":       status.Status,
+			"timestamp":    strconv.FormatInt(at.Unix(), 10),
+		}
+		if status.ErrorCode != 0 {
+			value["errors"] = []map[string]interface{}{{
+				"code":    status.ErrorCode,
+				"title":   status.ErrorMessage,
+				"message": status.ErrorMessage,
+			}}
+		}
+		values = append(values, value)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"object": "whatsapp_business_account",
+		"entry": []map[string]interface{}{{
+			"id": "metatest-waba",
+			"changes": []map[string]interface{}{{
+				"field": "messages",
+				"value": map[string]interface{}{
+					"messaging_product": "whatsapp",
+					"metadata": map[string]string{
+						"display_phone_number": "15550000000",
+						"phone_number_id":      phoneNumberID,
+					},
+					"statuses": values,
+				},
+			}},
+		}},
+	})
+	return body
+}
+
+// WebhookRequest returns a POST of body to url signed with the server's app secret, to serve
+// to a webhook handler or send to a running service
+func (s *Server) WebhookRequest(ctx context.Context, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.AppSecret, body))
+	return req, nil
+}
@@ -0,0 +1,222 @@
+// pkg/meta/products.go
+package meta
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProductTemplate and ProductListTemplate are the template names single- and multi-product
+// messages are stored and queued under, like CTAURLTemplate
+const (
+	ProductTemplate     = "interactive:product"
+	ProductListTemplate = "interactive:product_list"
+)
+
+// Parameters of product messages. A product list's sections are flattened into
+// "section.<i>.title" and "section.<i>.product.<j>" parameters, see ProductListParameters.
+const (
+	ProductCatalogID  = "catalog_id"
+	ProductRetailerID = "product_retailer_id"
+	ProductHeader     = "header"
+	ProductBody       = "body"
+	ProductFooter     = "footer"
+)
+
+// Limits Meta puts on product messages
+const (
+	maxProductSections     = 10
+	maxProductListItems    = 30
+	maxProductSectionTitle = 24
+	maxProductBodyLength   = 1024
+	maxProductHeaderLength = 60
+	maxProductFooterLength = 60
+)
+
+// sectionPrefix starts the parameters of product list sections
+const sectionPrefix = "section."
+
+// ProductSection is a titled group of catalog items in a product list message
+type ProductSection struct {
+	Title              string
+	ProductRetailerIDs []string
+}
+
+// IsProductTemplate reports whether a template name is one of the product message ones
+func IsProductTemplate(templateName string) bool {
+	return templateName == ProductTemplate || templateName == ProductListTemplate
+}
+
+// ProductListParameters flattens a product list message into template parameters. Empty
+// optional texts are left out.
+func ProductListParameters(catalogID, header, body, footer string, sections []ProductSection) map[string]interface{} {
+	parameters := map[string]interface{}{
+		ProductCatalogID: catalogID,
+		ProductHeader:    header,
+		ProductBody:      body,
+	}
+	if footer != "" {
+		parameters[ProductFooter] = footer
+	}
+	for i, section := range sections {
+		parameters[sectionParameter(i, "title")] = section.Title
+		for j, retailerID := range section.ProductRetailerIDs {
+			parameters[sectionParameter(i, "product."+strconv.Itoa(j))] = retailerID
+		}
+	}
+	return parameters
+}
+
+// ProductSections reads a product list message's sections back from its parameters
+func ProductSections(parameters map[string]interface{}) []ProductSection {
+	titles := make(map[int]string)
+	products := make(map[int]map[int]string)
+	for name, value := range parameters {
+		rest, ok := strings.CutPrefix(name, sectionPrefix)
+		if !ok {
+			continue
+		}
+		index, field, _ := strings.Cut(rest, ".")
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+		if products[i] == nil {
+			products[i] = make(map[int]string)
+		}
+		if field == "title" {
+			titles[i] = fmt.Sprintf("%v", value)
+		} else if product, ok := strings.CutPrefix(field, "product."); ok {
+			if j, err := strconv.Atoi(product); err == nil {
+				products[i][j] = fmt.Sprintf("%v", value)
+			}
+		}
+	}
+
+	sections := make([]ProductSection, 0, len(products))
+	for _, i := range sortedIndexes(products) {
+		section := ProductSection{Title: titles[i]}
+		for _, j := range sortedIndexes(products[i]) {
+			section.ProductRetailerIDs = append(section.ProductRetailerIDs, products[i][j])
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// sortedIndexes returns the keys of an index map in order
+func sortedIndexes[V any](m map[int]V) []int {
+	indexes := make([]int, 0, len(m))
+	for i := range m {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// ValidateProductMessage checks the parameters of a ProductTemplate or ProductListTemplate message
+func ValidateProductMessage(templateName string, parameters map[string]interface{}) error {
+	text := func(name string) string {
+		value, _ := parameters[name].(string)
+		return value
+	}
+
+	if text(ProductCatalogID) == "" {
+		return fmt.Errorf("catalog_id is required")
+	}
+	if len(text(ProductBody)) > maxProductBodyLength {
+		return fmt.Errorf("body must be at most %d characters", maxProductBodyLength)
+	}
+	if len(text(ProductFooter)) > maxProductFooterLength {
+		return fmt.Errorf("footer must be at most %d characters", maxProductFooterLength)
+	}
+
+	if templateName == ProductTemplate {
+		if text(ProductRetailerID) == "" {
+			return fmt.Errorf("product_retailer_id is required")
+		}
+		return nil
+	}
+
+	if header := text(ProductHeader); header == "" || len(header) > maxProductHeaderLength {
+		return fmt.Errorf("header is required and must be at most %d characters", maxProductHeaderLength)
+	}
+	if text(ProductBody) == "" {
+		return fmt.Errorf("body is required")
+	}
+	sections := ProductSections(parameters)
+	if len(sections) == 0 || len(sections) > maxProductSections {
+		return fmt.Errorf("a product list needs between 1 and %d sections", maxProductSections)
+	}
+	items := 0
+	for _, section := range sections {
+		if len(sections) > 1 && (section.Title == "" || len(section.Title) > maxProductSectionTitle) {
+			return fmt.Errorf("sections need a title of at most %d characters", maxProductSectionTitle)
+		}
+		if len(section.ProductRetailerIDs) == 0 {
+			return fmt.Errorf("section %q has no products", section.Title)
+		}
+		for _, retailerID := range section.ProductRetailerIDs {
+			if retailerID == "" {
+				return fmt.Errorf("section %q has an empty product_retailer_id", section.Title)
+			}
+		}
+		items += len(section.ProductRetailerIDs)
+	}
+	if items > maxProductListItems {
+		return fmt.Errorf("a product list can have at most %d products", maxProductListItems)
+	}
+	return nil
+}
+
+// buildProductPayload builds the request of a single- or multi-product message
+func buildProductPayload(to, templateName string, parameters map[string]interface{}) map[string]interface{} {
+	text := func(name string) string {
+		return fmt.Sprintf("%v", parameters[name])
+	}
+
+	action := map[string]interface{}{"catalog_id": text(ProductCatalogID)}
+	interactive := map[string]interface{}{"action": action}
+	if templateName == ProductTemplate {
+		interactive["type"] = "product"
+		action["product_retailer_id"] = text(ProductRetailerID)
+	} else {
+		interactive["type"] = "product_list"
+		interactive["header"] = map[string]string{"type": "text", "text": text(ProductHeader)}
+
+		var sections []map[string]interface{}
+		for _, section := range ProductSections(parameters) {
+			items := make([]map[string]string, len(section.ProductRetailerIDs))
+			for i, retailerID := range section.ProductRetailerIDs {
+				items[i] = map[string]string{"product_retailer_id": retailerID}
+			}
+			entry := map[string]interface{}{"product_items": items}
+			if section.Title != "" {
+				entry["title"] = section.Title
+			}
+			sections = append(sections, entry)
+		}
+		action["sections"] = sections
+	}
+	if _, ok := parameters[ProductBody]; ok {
+		interactive["body"] = map[string]string{"text": text(ProductBody)}
+	}
+	if _, ok := parameters[ProductFooter]; ok {
+		interactive["footer"] = map[string]string{"text": text(ProductFooter)}
+	}
+
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "interactive",
+		"interactive":       interactive,
+	}
+}
+
+// sectionParameter names a parameter of the product list section at index i
+func sectionParameter(i int, field string) string {
+	return sectionPrefix + strconv.Itoa(i) + "." + field
+}
@@ -0,0 +1,275 @@
+// pkg/meta/templates.go
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"messaging-microservice/pkg/render"
+)
+
+// DefaultTemplateLanguage is the language templates are sent in
+const DefaultTemplateLanguage = "en_US"
+
+// LocaleParameter is the message parameter naming the recipient's locale ("es", "pt_BR"). It
+// picks the body of providers sending templates as localized plain text and is never a
+// template parameter.
+const LocaleParameter = "locale"
+
+// ErrTemplateNotFound is returned when an account has no template of the name and language
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateDefinition is a message template as created in WhatsApp Manager
+type TemplateDefinition struct {
+	Name       string              `json:"name"`
+	Language   string              `json:"language"`
+	Status     string              `json:"status"`
+	Category   string              `json:"category"`
+	Components []TemplateComponent `json:"components"`
+}
+
+// TemplateComponent is the header, body, footer or buttons of a template. Text holds {{n}}
+// placeholders for the parameters.
+type TemplateComponent struct {
+	// Type is HEADER, BODY, FOOTER or BUTTONS
+	Type string `json:"type"`
+	// Format is the header's TEXT, IMAGE, VIDEO, DOCUMENT or LOCATION
+	Format  string           `json:"format,omitempty"`
+	Text    string           `json:"text,omitempty"`
+	Buttons []TemplateButton `json:"buttons,omitempty"`
+}
+
+// TemplateButton is a button of a template; URL may end in a {{1}} placeholder for a suffix
+type TemplateButton struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// TemplateSource looks up the definitions of templates. Providers without template lookups
+// don't implement it.
+type TemplateSource interface {
+	// GetTemplate returns the template of the WhatsApp Business Account with the name and
+	// language, or ErrTemplateNotFound
+	GetTemplate(ctx context.Context, wabaID, name, language string) (*TemplateDefinition, error)
+}
+
+// TextTemplateSource is implemented by providers sending some templates as text they render
+// themselves, such as Twilio's localized catalog
+type TextTemplateSource interface {
+	// TextTemplate returns the body a send of the template with the parameters is rendered
+	// from, and the locale it is in; ok is false when the template is sent some other way
+	TextTemplate(templateName string, parameters map[string]interface{}) (tmpl *render.Template, locale string, ok bool)
+}
+
+// GetTemplate implements TemplateSource
+func (c *metaClient) GetTemplate(ctx context.Context, wabaID, name, language string) (*TemplateDefinition, error) {
+	ctx, cancel, err := c.timeout.Context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	query := url.Values{
+		"name":     {name},
+		"language": {language},
+		"fields":   {"name,language,status,category,components"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/message_templates?%s", c.apiURL, wabaID, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.deprecations.observe(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse MessageResponse
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error != nil {
+			apiErr := &APIError{
+				StatusCode: resp.StatusCode,
+				Code:       errorResponse.Error.Code,
+				Type:       errorResponse.Error.Type,
+				Message:    errorResponse.Error.Message,
+			}
+			if apiErr.Code == metaInvalidTokenCode {
+				c.tokens.ReportInvalid(apiErr)
+			}
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("meta API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var list struct {
+		Data []TemplateDefinition `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	// The name filter also matches templates whose name contains it
+	for i := range list.Data {
+		if list.Data[i].Name == name && list.Data[i].Language == language {
+			return &list.Data[i], nil
+		}
+	}
+	return nil, ErrTemplateNotFound
+}
+
+// BuildTemplatePayload builds the request sending a template to to in language. Parameters
+// other than button URL suffixes and the locale fill the body placeholders in the order of their names,
+// numerically for numbers: "1", "2", ..., "10".
+func BuildTemplatePayload(to, templateName, language string, parameters map[string]interface{}) map[string]interface{} {
+	template := map[string]interface{}{
+		"name":     templateName,
+		"language": map[string]string{"code": language},
+	}
+	if components := buildTemplateComponents(parameters); len(components) > 0 {
+		template["components"] = components
+	}
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "template",
+		"template":          template,
+	}
+}
+
+// buildTemplateComponents builds the components array for a template message
+func buildTemplateComponents(parameters map[string]interface{}) []map[string]interface{} {
+	// Convert parameters to component format; button URL suffixes go in button components
+	names := bodyParameterNames(parameters)
+	var components []map[string]interface{}
+	if len(names) > 0 {
+		params := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			params = append(params, map[string]interface{}{
+				"type": "text",
+				"text": fmt.Sprintf("%v", parameters[name]),
+			})
+		}
+		components = append(components, map[string]interface{}{
+			"type":       "body",
+			"parameters": params,
+		})
+	}
+	return append(components, buildButtonComponents(parameters)...)
+}
+
+// bodyParameterNames returns the names of the body parameters in the order they are sent:
+// numbers in numeric order, then other names alphabetically
+func bodyParameterNames(parameters map[string]interface{}) []string {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		if !IsButtonURLParameter(name) && name != LocaleParameter {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.Atoi(names[i])
+		b, errB := strconv.Atoi(names[j])
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// placeholder matches the {{n}} placeholders of template text
+var placeholder = regexp.MustCompile(`\{\{\s*(\d+)\s*\}\}`)
+
+// RenderedTemplate is a template's text with the parameters of a send filled in
+type RenderedTemplate struct {
+	Header  string
+	Body    string
+	Footer  string
+	Buttons []TemplateButton
+	// Missing lists the placeholders no parameter fills, as "body {{2}}" or "button 0 {{1}}"
+	Missing []string
+	// Unused lists the parameters no placeholder takes
+	Unused []string
+}
+
+// RenderTemplate fills the template's placeholders as a send of the parameters would. Body
+// placeholders take the body parameters by position, see BuildTemplatePayload; URL buttons
+// take their button_url parameter. Sends carry no header parameters, so header placeholders
+// stay unfilled.
+func RenderTemplate(template *TemplateDefinition, parameters map[string]interface{}) *RenderedTemplate {
+	rendered := &RenderedTemplate{}
+	names := bodyParameterNames(parameters)
+	used := make(map[string]bool, len(parameters))
+
+	fill := func(section, text string, value func(n int) (string, bool)) string {
+		return placeholder.ReplaceAllStringFunc(text, func(match string) string {
+			n, _ := strconv.Atoi(placeholder.FindStringSubmatch(match)[1])
+			if v, ok := value(n); ok {
+				return v
+			}
+			rendered.Missing = append(rendered.Missing, fmt.Sprintf("%s {{%d}}", section, n))
+			return match
+		})
+	}
+	bodyValue := func(n int) (string, bool) {
+		if n < 1 || n > len(names) {
+			return "", false
+		}
+		used[names[n-1]] = true
+		return fmt.Sprintf("%v", parameters[names[n-1]]), true
+	}
+	noValue := func(int) (string, bool) { return "", false }
+
+	for _, component := range template.Components {
+		switch strings.ToUpper(component.Type) {
+		case "HEADER":
+			rendered.Header = fill("header", component.Text, noValue)
+		case "BODY":
+			rendered.Body = fill("body", component.Text, bodyValue)
+		case "FOOTER":
+			rendered.Footer = component.Text
+		case "BUTTONS":
+			for i, button := range component.Buttons {
+				name := ButtonURLParameter(i)
+				button.URL = fill(fmt.Sprintf("button %d", i), button.URL, func(int) (string, bool) {
+					value, ok := parameters[name]
+					used[name] = ok
+					return fmt.Sprintf("%v", value), ok
+				})
+				rendered.Buttons = append(rendered.Buttons, button)
+			}
+		}
+	}
+
+	for name := range parameters {
+		if !used[name] && name != LocaleParameter {
+			rendered.Unused = append(rendered.Unused, name)
+		}
+	}
+	sort.Strings(rendered.Unused)
+	return rendered
+}
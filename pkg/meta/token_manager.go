@@ -0,0 +1,375 @@
+// pkg/meta/token_manager.go
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// ErrTokenInvalid is returned when Meta reports the access token as expired or revoked
+var ErrTokenInvalid = errors.New("meta access token is invalid")
+
+// metaInvalidTokenCode is the Graph API error code for expired or revoked tokens
+const metaInvalidTokenCode = 190
+
+// TokenSource supplies the access token used for API calls
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	// ReportInvalid tells the source the API rejected its token
+	ReportInvalid(err error)
+}
+
+// staticToken is a TokenSource for a fixed token
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) { return string(t), nil }
+func (t staticToken) ReportInvalid(error)                   {}
+
+// TokenHealth describes the last known state of the access token
+type TokenHealth struct {
+	Valid       bool
+	ExpiresAt   time.Time // zero when the token never expires
+	LastChecked time.Time
+	LastError   string
+}
+
+// TokenManager validates, refreshes and tracks the health of the Meta access token
+type TokenManager interface {
+	TokenSource
+	// Validate checks the current token against the debug_token endpoint
+	Validate(ctx context.Context) error
+	// Refresh exchanges the current token for a new long-lived one
+	Refresh(ctx context.Context) error
+	// Health returns the cached token state
+	Health() TokenHealth
+	// UpdateCredentials replaces the access token and app secret, e.g. after a secret rotation;
+	// empty values are left unchanged
+	UpdateCredentials(accessToken, appSecret string)
+	// Run revalidates the token every checkInterval and refreshes it before it expires, until ctx is done
+	Run(ctx context.Context)
+}
+
+// TokenManagerConfig configures a TokenManager
+type TokenManagerConfig struct {
+	AccessToken string
+	AppID       string
+	AppSecret   string
+	// RefreshBefore is how long before expiry the token is refreshed
+	RefreshBefore time.Duration
+	// CheckInterval is how often the token is revalidated
+	CheckInterval time.Duration
+	// HTTP sets the retries and transport of Graph API requests; zero Timeout and Retry
+	// default to 10s and utils.DefaultHTTPRetry
+	HTTP utils.HTTPClientConfig
+	// APIVersion is the Graph API version, DefaultAPIVersion when empty
+	APIVersion string
+	// APIURL overrides the Graph API base URL
+	APIURL string
+}
+
+// tokenManager implements TokenManager
+type tokenManager struct {
+	cfg          TokenManagerConfig
+	apiURL       string
+	httpClient   utils.HTTPClient
+	deprecations *deprecationLog
+	logger       utils.Logger
+
+	mu     sync.RWMutex
+	token  string
+	health TokenHealth
+}
+
+// NewTokenManager creates a token manager for the given token
+func NewTokenManager(cfg TokenManagerConfig, logger utils.Logger) TokenManager {
+	version := cfg.APIVersion
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = APIURL(version)
+	}
+
+	httpConfig := cfg.HTTP
+	if httpConfig.Timeout == 0 {
+		httpConfig.Timeout = 10 * time.Second
+	}
+	if httpConfig.Retry == (utils.Backoff{}) {
+		httpConfig.Retry = utils.DefaultHTTPRetry
+	}
+
+	return &tokenManager{
+		cfg:          cfg,
+		apiURL:       apiURL,
+		httpClient:   utils.NewHTTPClientWithConfig(httpConfig, logger),
+		deprecations: &deprecationLog{version: version, logger: logger},
+		logger:       logger,
+		token:        cfg.AccessToken,
+	}
+}
+
+// Token returns the current access token
+func (m *tokenManager) Token(context.Context) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.token, nil
+}
+
+// ReportInvalid marks the token unhealthy after the API rejected it
+func (m *tokenManager) ReportInvalid(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.Valid = false
+	m.health.LastError = err.Error()
+	m.logger.Error("Meta access token rejected by API", "error", err)
+}
+
+// UpdateCredentials replaces the access token and app secret
+func (m *tokenManager) UpdateCredentials(accessToken, appSecret string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if accessToken != "" {
+		m.token = accessToken
+	}
+	if appSecret != "" {
+		m.cfg.AppSecret = appSecret
+	}
+}
+
+// appSecret returns the current app secret
+func (m *tokenManager) appSecret() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg.AppSecret
+}
+
+// Health returns the cached token state
+func (m *tokenManager) Health() TokenHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.health
+}
+
+// Validate checks the current token against the debug_token endpoint
+func (m *tokenManager) Validate(ctx context.Context) error {
+	token, _ := m.Token(ctx)
+	appSecret := m.appSecret()
+
+	// An app access token can inspect any token; without an app ID the token inspects itself
+	inspector := token
+	if m.cfg.AppID != "" && appSecret != "" {
+		inspector = m.cfg.AppID + "|" + appSecret
+	}
+
+	query := url.Values{"input_token": {token}, "access_token": {inspector}}
+	var result struct {
+		Data struct {
+			IsValid   bool  `json:"is_valid"`
+			ExpiresAt int64 `json:"expires_at"`
+			Error     *struct {
+				Message string `json:"message"`
+			} `json:"error,omitempty"`
+		} `json:"data"`
+	}
+	if err := m.get(ctx, "/debug_token", query, &result); err != nil {
+		m.recordError(err)
+		return err
+	}
+
+	if !result.Data.IsValid {
+		err := ErrTokenInvalid
+		if result.Data.Error != nil {
+			err = fmt.Errorf("%w: %s", ErrTokenInvalid, result.Data.Error.Message)
+		}
+		m.recordError(err)
+		return err
+	}
+
+	var expiresAt time.Time
+	if result.Data.ExpiresAt > 0 {
+		expiresAt = time.Unix(result.Data.ExpiresAt, 0)
+	}
+
+	m.mu.Lock()
+	m.health = TokenHealth{Valid: true, ExpiresAt: expiresAt, LastChecked: time.Now()}
+	m.mu.Unlock()
+	return nil
+}
+
+// Refresh exchanges the current token for a new long-lived token through the OAuth endpoint
+func (m *tokenManager) Refresh(ctx context.Context) error {
+	appSecret := m.appSecret()
+	if m.cfg.AppID == "" || appSecret == "" {
+		return errors.New("META_APP_ID and META_APP_SECRET are required to refresh the access token")
+	}
+
+	token, _ := m.Token(ctx)
+	query := url.Values{
+		"grant_type":        {"fb_exchange_token"},
+		"client_id":         {m.cfg.AppID},
+		"client_secret":     {appSecret},
+		"fb_exchange_token": {token},
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := m.get(ctx, "/oauth/access_token", query, &result); err != nil {
+		m.recordError(err)
+		return err
+	}
+	if result.AccessToken == "" {
+		err := errors.New("token refresh returned no access token")
+		m.recordError(err)
+		return err
+	}
+
+	var expiresAt time.Time
+	if result.ExpiresIn > 0 {
+		expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+
+	m.mu.Lock()
+	m.token = result.AccessToken
+	m.health = TokenHealth{Valid: true, ExpiresAt: expiresAt, LastChecked: time.Now()}
+	m.mu.Unlock()
+
+	m.logger.Info("Refreshed Meta access token", "expires_at", expiresAt)
+	return nil
+}
+
+// Run revalidates the token periodically and refreshes it ahead of expiry
+func (m *tokenManager) Run(ctx context.Context) {
+	interval := m.cfg.CheckInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := m.Validate(ctx); err != nil {
+			m.logger.Error("Meta access token validation failed", "error", err)
+		}
+
+		health := m.Health()
+		if m.needsRefresh(health) {
+			if err := m.Refresh(ctx); err != nil {
+				m.logger.Error("Meta access token refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// needsRefresh reports whether an expiring token is inside the refresh window
+func (m *tokenManager) needsRefresh(health TokenHealth) bool {
+	if m.cfg.AppID == "" || health.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Until(health.ExpiresAt) <= m.cfg.RefreshBefore
+}
+
+// recordError keeps the last failure; only ErrTokenInvalid marks the token unhealthy,
+// since a network error says nothing about the token itself
+func (m *tokenManager) recordError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.LastChecked = time.Now()
+	m.health.LastError = err.Error()
+	if errors.Is(err, ErrTokenInvalid) {
+		m.health.Valid = false
+	}
+}
+
+// get performs a Graph API GET and decodes the JSON response
+func (m *tokenManager) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.apiURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	m.deprecations.observe(resp)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse MessageResponse
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error != nil {
+			if errorResponse.Error.Code == metaInvalidTokenCode {
+				return fmt.Errorf("%w: %s", ErrTokenInvalid, errorResponse.Error.Message)
+			}
+			return &APIError{
+				StatusCode: resp.StatusCode,
+				Code:       errorResponse.Error.Code,
+				Type:       errorResponse.Error.Type,
+				Message:    errorResponse.Error.Message,
+			}
+		}
+		return fmt.Errorf("meta API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// tokenCollector exports token health as Prometheus metrics
+type tokenCollector struct {
+	manager   TokenManager
+	valid     *prometheus.Desc
+	expiresAt *prometheus.Desc
+}
+
+// NewTokenHealthCollector exports the token's validity and expiry time
+func NewTokenHealthCollector(manager TokenManager) prometheus.Collector {
+	return &tokenCollector{
+		manager:   manager,
+		valid:     prometheus.NewDesc("whatsapp_meta_token_valid", "Whether the Meta access token was valid when last checked (1) or not (0).", nil, nil),
+		expiresAt: prometheus.NewDesc("whatsapp_meta_token_expiry_timestamp_seconds", "Unix time the Meta access token expires; 0 if it never expires.", nil, nil),
+	}
+}
+
+func (c *tokenCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.valid
+	ch <- c.expiresAt
+}
+
+func (c *tokenCollector) Collect(ch chan<- prometheus.Metric) {
+	health := c.manager.Health()
+
+	valid := 0.0
+	if health.Valid {
+		valid = 1
+	}
+	var expiresAt float64
+	if !health.ExpiresAt.IsZero() {
+		expiresAt = float64(health.ExpiresAt.Unix())
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.valid, prometheus.GaugeValue, valid)
+	ch <- prometheus.MustNewConstMetric(c.expiresAt, prometheus.GaugeValue, expiresAt)
+}
@@ -0,0 +1,80 @@
+// pkg/meta/typing.go
+package meta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TypingIndicator shows customers that a reply to their message is being written. Providers
+// without typing indicators don't implement it.
+type TypingIndicator interface {
+	// SendTypingIndicator marks the inbound message messageID read and shows the typing
+	// indicator until a reply is sent or 25 seconds pass
+	SendTypingIndicator(ctx context.Context, messageID string) error
+}
+
+// SendTypingIndicator implements TypingIndicator
+func (c *metaClient) SendTypingIndicator(ctx context.Context, messageID string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"status":            "read",
+		"message_id":        messageID,
+		"typing_indicator":  map[string]string{"type": "text"},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.timeout.Context(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	url := fmt.Sprintf("%s/%s/messages", c.apiURL, c.phoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	accessToken, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.deprecations.observe(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var errorResponse MessageResponse
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error != nil {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       errorResponse.Error.Code,
+			Type:       errorResponse.Error.Type,
+			Message:    errorResponse.Error.Message,
+		}
+		if apiErr.Code == metaInvalidTokenCode {
+			c.tokens.ReportInvalid(apiErr)
+		}
+		return apiErr
+	}
+	return fmt.Errorf("meta API error: %d - %s", resp.StatusCode, string(body))
+}
@@ -0,0 +1,187 @@
+// pkg/mockprovider/client.go
+package mockprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+// Config controls how the mock provider behaves
+type Config struct {
+	// PhoneNumberID is reported in webhook metadata so tenant resolution works
+	PhoneNumberID string
+	// AppSecret signs the synthetic webhooks like Meta does
+	AppSecret string
+	// WebhookURL receives the synthetic status webhooks; empty disables them
+	WebhookURL string
+	// DeliveredDelay and ReadDelay are measured from the send
+	DeliveredDelay time.Duration
+	ReadDelay      time.Duration
+	// FailureRate is the fraction (0..1) of sends rejected with a provider error
+	FailureRate float64
+}
+
+// mockClient implements meta.Client without talking to a real provider
+type mockClient struct {
+	cfg        Config
+	httpClient *http.Client
+	logger     utils.Logger
+	sent       int64
+}
+
+// NewClient creates a mock WhatsApp client for local development and tests
+func NewClient(cfg Config, logger utils.Logger) meta.Client {
+	return &mockClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		logger:     logger,
+	}
+}
+
+// SendTemplateMessage simulates a send and schedules delivery and read webhooks
+func (c *mockClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	to = strings.TrimPrefix(to, "whatsapp:")
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "template",
+		"template": map[string]interface{}{
+			"name":       templateName,
+			"language":   map[string]string{"code": "en_US"},
+			"parameters": parameters,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.FailureRate > 0 && mathrand.Float64() < c.cfg.FailureRate {
+		return nil, &meta.APIError{
+			StatusCode: http.StatusBadRequest,
+			Code:       131026,
+			Type:       "OAuthException",
+			Message:    "Message undeliverable (simulated by mock provider)",
+		}
+	}
+
+	externalID := c.newExternalID()
+	c.logger.Info("Mock provider accepted message", "external_id", externalID, "to", to, "template", templateName)
+
+	if c.cfg.WebhookURL != "" {
+		c.scheduleStatus(externalID, to, "delivered", c.cfg.DeliveredDelay)
+		c.scheduleStatus(externalID, to, "read", c.cfg.ReadDelay)
+	}
+
+	resp := &meta.MessageResponse{
+		MessagingProduct: "whatsapp",
+		RequestPayload:   payload,
+	}
+	resp.Contacts = append(resp.Contacts, struct {
+		WaID string `json:"wa_id"`
+	}{WaID: to})
+	resp.Messages = append(resp.Messages, struct {
+		ID string `json:"id"`
+	}{ID: externalID})
+
+	return resp, nil
+}
+
+// ValidateWebhookSignature validates signatures produced with the configured app secret
+func (c *mockClient) ValidateWebhookSignature(signature string, _ string, body []byte) bool {
+	if c.cfg.AppSecret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(c.sign(body)))
+}
+
+// SendTypingIndicator implements meta.TypingIndicator by logging the indicator
+func (c *mockClient) SendTypingIndicator(ctx context.Context, messageID string) error {
+	c.logger.Info("Mock provider showed typing indicator", "message_id", messageID)
+	return nil
+}
+
+// scheduleStatus posts a Meta-format status webhook after delay
+func (c *mockClient) scheduleStatus(externalID, recipient, status string, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		body, err := json.Marshal(c.statusWebhook(externalID, recipient, status))
+		if err != nil {
+			c.logger.Error("Failed to build mock webhook", "error", err)
+			return
+		}
+
+		req, err := http.NewRequest(http.MethodPost, c.cfg.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			c.logger.Error("Failed to create mock webhook request", "error", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature-256", c.sign(body))
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.logger.Error("Failed to deliver mock webhook", "error", err, "external_id", externalID, "status", status)
+			return
+		}
+		resp.Body.Close()
+
+		c.logger.Debug("Delivered mock webhook", "external_id", externalID, "status", status, "response", resp.StatusCode)
+	})
+}
+
+// statusWebhook builds a webhook payload in the same shape Meta sends
+func (c *mockClient) statusWebhook(externalID, recipient, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"object": "whatsapp_business_account",
+		"entry": []map[string]interface{}{{
+			"id": "mock-waba",
+			"changes": []map[string]interface{}{{
+				"field": "messages",
+				"value": map[string]interface{}{
+					"messaging_product": "whatsapp",
+					"metadata": map[string]string{
+						"display_phone_number": "15550000000",
+						"phone_number_id":      c.cfg.PhoneNumberID,
+					},
+					"statuses": []map[string]string{{
+						"id":           externalID,
+						"recipient_id": recipient,
+						"status":       status,
+						"timestamp":    strconv.FormatInt(time.Now().Unix(), 10),
+					}},
+				},
+			}},
+		}},
+	}
+}
+
+// sign computes the X-Hub-Signature-256 header value for a body
+func (c *mockClient) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(c.cfg.AppSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newExternalID generates a unique wamid-like identifier
+func (c *mockClient) newExternalID() string {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Sprintf("wamid.mock-%d", atomic.AddInt64(&c.sent, 1))
+	}
+	return fmt.Sprintf("wamid.mock-%d-%s", atomic.AddInt64(&c.sent, 1), hex.EncodeToString(random))
+}
@@ -0,0 +1,66 @@
+// pkg/objectstore/objectstore.go
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Store reads and writes whole objects by key
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// fileStore keeps objects as files under a directory, for local development and tests
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store that writes objects below dir
+func NewFileStore(dir string) Store {
+	return &fileStore{dir: dir}
+}
+
+// Put writes the object atomically
+func (s *fileStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get reads the object
+func (s *fileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// path maps a key to a file; cleaning it as an absolute path keeps it inside the directory
+func (s *fileStore) path(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("object key is required")
+	}
+	return filepath.Join(s.dir, filepath.Clean("/"+key)), nil
+}
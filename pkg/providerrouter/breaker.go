@@ -0,0 +1,187 @@
+// pkg/providerrouter/breaker.go
+package providerrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// breakerOpen is 1 while the circuit breaker rejects sends
+var breakerOpen = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "whatsapp_provider_circuit_open",
+	Help: "Whether the provider circuit breaker is open and rejecting sends.",
+})
+
+// ErrCircuitOpen is returned for sends rejected while the circuit breaker is open
+var ErrCircuitOpen = errors.New("provider circuit breaker is open")
+
+// GatedClient is a client that can tell whether a send would go out right now, so callers
+// such as the queue consumer can hold back work instead of failing it
+type GatedClient interface {
+	meta.Client
+	// Ready reports whether a send would be attempted now and, if not, roughly how long
+	// until it may be
+	Ready() (bool, time.Duration)
+}
+
+// BreakerConfig controls when the circuit breaker opens and closes
+type BreakerConfig struct {
+	// FailureThreshold is how many consecutive health errors open the circuit
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before one probe send is let through; a
+	// successful probe closes it, a failed one opens it for another Cooldown
+	Cooldown time.Duration
+	// IsHealthError reports whether a send error reflects on the provider's health; nil
+	// counts every error
+	IsHealthError func(error) bool
+}
+
+// breakerClient rejects sends while the provider keeps failing
+type breakerClient struct {
+	client meta.Client
+	cfg    BreakerConfig
+	now    func() time.Time
+	logger utils.Logger
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	probing  bool
+}
+
+// NewBreakerClient wraps client in a circuit breaker
+func NewBreakerClient(client meta.Client, cfg BreakerConfig, logger utils.Logger) GatedClient {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	breakerOpen.Set(0)
+
+	return &breakerClient{
+		client: client,
+		cfg:    cfg,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// SendTemplateMessage sends unless the circuit is open
+func (c *breakerClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	probe, ok := c.acquire()
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := c.client.SendTemplateMessage(ctx, to, templateName, parameters)
+	if errors.Is(err, context.Canceled) {
+		c.release(probe)
+		return resp, err
+	}
+	c.record(probe, err != nil && c.isHealthError(err))
+	return resp, err
+}
+
+// ValidateWebhookSignature delegates to the wrapped client
+func (c *breakerClient) ValidateWebhookSignature(signatureHeader, url string, body []byte) bool {
+	return c.client.ValidateWebhookSignature(signatureHeader, url, body)
+}
+
+// Ready reports whether the circuit lets a send through
+func (c *breakerClient) Ready() (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.open {
+		return true, 0
+	}
+	if remaining := c.openedAt.Add(c.cfg.Cooldown).Sub(c.now()); remaining > 0 {
+		return false, remaining
+	}
+	// Half open: ready unless the probe is already out
+	return !c.probing, c.cfg.Cooldown
+}
+
+// acquire decides whether a send may go out, and whether it is the half-open probe
+func (c *breakerClient) acquire() (probe, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.open {
+		return false, true
+	}
+	if c.probing || c.now().Before(c.openedAt.Add(c.cfg.Cooldown)) {
+		return false, false
+	}
+	c.probing = true
+	return true, true
+}
+
+// release gives up a probe that did not complete
+func (c *breakerClient) release(probe bool) {
+	if !probe {
+		return
+	}
+	c.mu.Lock()
+	c.probing = false
+	c.mu.Unlock()
+}
+
+// record updates the breaker with a send's outcome
+func (c *breakerClient) record(probe, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if probe {
+		c.probing = false
+	}
+	if !failed {
+		c.failures = 0
+		if c.open && probe {
+			c.open = false
+			breakerOpen.Set(0)
+			c.logger.Info("Provider circuit breaker closed")
+		}
+		return
+	}
+
+	c.failures++
+	if probe || (!c.open && c.failures >= c.cfg.FailureThreshold) {
+		if !c.open {
+			c.logger.Warn("Provider circuit breaker opened", "consecutive_failures", c.failures, "cooldown", c.cfg.Cooldown)
+		}
+		c.open = true
+		c.openedAt = c.now()
+		breakerOpen.Set(1)
+	}
+}
+
+func (c *breakerClient) isHealthError(err error) bool {
+	if c.cfg.IsHealthError == nil {
+		return true
+	}
+	return c.cfg.IsHealthError(err)
+}
+
+// IsDegradedError reports whether a send error points at the provider rather than the
+// message: rate limiting, server errors and errors that never got an API response
+func IsDegradedError(err error) bool {
+	var metaErr *meta.APIError
+	if errors.As(err, &metaErr) {
+		return metaErr.StatusCode == http.StatusTooManyRequests || metaErr.StatusCode >= 500
+	}
+	var twilioErr *twilio.APIError
+	if errors.As(err, &twilioErr) {
+		return twilioErr.StatusCode == http.StatusTooManyRequests || twilioErr.StatusCode >= 500
+	}
+	return true
+}
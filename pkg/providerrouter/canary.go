@@ -0,0 +1,163 @@
+// pkg/providerrouter/canary.go
+package providerrouter
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+// bucketCount is the resolution of the canary percentage (0.01%)
+const bucketCount = 10000
+
+// canaryActive is 1 while sends are routed to the canary provider
+var canaryActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "whatsapp_provider_canary_active",
+	Help: "Whether a share of sends is routed to the canary provider (0 after an automatic rollback).",
+}, []string{"provider"})
+
+// CanaryConfig controls how sends are split between the primary and canary providers
+type CanaryConfig struct {
+	// CanaryName labels the canary provider in metrics and logs
+	CanaryName string
+	// Percent of phone numbers (0..100) whose sends go to the canary
+	Percent float64
+	// MaxFailureRate (0..1) of the canary's recent sends above which the canary is rolled back
+	// for Cooldown; zero disables the automatic rollback
+	MaxFailureRate float64
+	// Window is how many recent canary sends the failure rate is computed over, and
+	// MinSamples how many are needed before it is trusted
+	Window     int
+	MinSamples int
+	Cooldown   time.Duration
+}
+
+// canaryClient routes a sticky share of phone numbers to a canary provider
+type canaryClient struct {
+	primary meta.Client
+	canary  meta.Client
+	cfg     CanaryConfig
+	now     func() time.Time
+	logger  utils.Logger
+
+	mu              sync.Mutex
+	results         []bool
+	next            int
+	samples         int
+	failures        int
+	rolledBackUntil time.Time
+}
+
+// NewCanaryClient creates a client that sends Percent of phone numbers through canary and the
+// rest through primary. A phone number always maps to the same provider for a given percentage,
+// and raising the percentage only moves numbers to the canary.
+func NewCanaryClient(primary, canary meta.Client, cfg CanaryConfig, logger utils.Logger) meta.Client {
+	if cfg.Window <= 0 {
+		cfg.Window = 100
+	}
+	if cfg.MinSamples <= 0 || cfg.MinSamples > cfg.Window {
+		cfg.MinSamples = cfg.Window
+	}
+	canaryActive.WithLabelValues(cfg.CanaryName).Set(1)
+
+	return &canaryClient{
+		primary: primary,
+		canary:  canary,
+		cfg:     cfg,
+		now:     time.Now,
+		logger:  logger,
+		results: make([]bool, cfg.Window),
+	}
+}
+
+// SendTemplateMessage sends through the provider the phone number is assigned to
+func (c *canaryClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	if !c.useCanary(to) {
+		return c.primary.SendTemplateMessage(ctx, to, templateName, parameters)
+	}
+
+	resp, err := c.canary.SendTemplateMessage(ctx, to, templateName, parameters)
+	if !errors.Is(err, context.Canceled) {
+		c.observe(err != nil)
+	}
+	return resp, err
+}
+
+// ValidateWebhookSignature accepts webhooks signed for either provider
+func (c *canaryClient) ValidateWebhookSignature(signatureHeader, url string, body []byte) bool {
+	return c.primary.ValidateWebhookSignature(signatureHeader, url, body) ||
+		c.canary.ValidateWebhookSignature(signatureHeader, url, body)
+}
+
+// useCanary reports whether the phone number is in the canary share and the canary is not rolled back
+func (c *canaryClient) useCanary(to string) bool {
+	if phoneBucket(to) >= int(c.cfg.Percent*bucketCount/100) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rolledBackUntil.IsZero() {
+		return true
+	}
+	if c.now().Before(c.rolledBackUntil) {
+		return false
+	}
+
+	c.rolledBackUntil = time.Time{}
+	canaryActive.WithLabelValues(c.cfg.CanaryName).Set(1)
+	c.logger.Info("Resuming canary provider after cooldown", "provider", c.cfg.CanaryName)
+	return true
+}
+
+// observe records a canary send and rolls the canary back once its failure rate is too high
+func (c *canaryClient) observe(failed bool) {
+	if c.cfg.MaxFailureRate <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.samples == len(c.results) {
+		if c.results[c.next] {
+			c.failures--
+		}
+	} else {
+		c.samples++
+	}
+	c.results[c.next] = failed
+	if failed {
+		c.failures++
+	}
+	c.next = (c.next + 1) % len(c.results)
+
+	if c.samples < c.cfg.MinSamples {
+		return
+	}
+	rate := float64(c.failures) / float64(c.samples)
+	if rate <= c.cfg.MaxFailureRate {
+		return
+	}
+
+	c.rolledBackUntil = c.now().Add(c.cfg.Cooldown)
+	c.samples, c.failures, c.next = 0, 0, 0
+	canaryActive.WithLabelValues(c.cfg.CanaryName).Set(0)
+	c.logger.Warn("Rolling back canary provider", "provider", c.cfg.CanaryName, "failure_rate", rate, "cooldown", c.cfg.Cooldown)
+}
+
+// phoneBucket maps a phone number to a stable bucket in [0, bucketCount)
+func phoneBucket(phoneNumber string) int {
+	h := fnv.New32a()
+	h.Write([]byte(phoneNumber))
+	return int(h.Sum32() % bucketCount)
+}
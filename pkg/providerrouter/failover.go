@@ -0,0 +1,284 @@
+// pkg/providerrouter/failover.go
+package providerrouter
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+var (
+	// failoverActive is 1 while new sends are failed over to the secondary provider
+	failoverActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "whatsapp_provider_failover_active",
+		Help: "Whether new sends are failed over from the primary provider.",
+	}, []string{"primary", "secondary"})
+
+	// providerSwitchovers counts failovers and failbacks
+	providerSwitchovers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_provider_switchovers_total",
+		Help: "Switchovers between providers, by the provider switched from and to.",
+	}, []string{"from", "to"})
+)
+
+// Switchover reasons
+const (
+	SwitchReasonErrorRate = "error_rate"
+	SwitchReasonLatency   = "latency"
+	SwitchReasonRecovered = "recovered"
+)
+
+// SwitchEvent describes a failover or failback
+type SwitchEvent struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+	// ErrorRate and LatencyP95 are the primary's health over the window when failing over
+	ErrorRate  float64       `json:"error_rate"`
+	LatencyP95 time.Duration `json:"latency_p95"`
+	At         time.Time     `json:"at"`
+}
+
+// FailoverConfig controls when sends fail over to the secondary provider and back
+type FailoverConfig struct {
+	// PrimaryName and SecondaryName label the providers in metrics, logs and events
+	PrimaryName   string
+	SecondaryName string
+	// MaxErrorRate (0..1) and MaxLatency (p95) of the primary's recent sends above which new
+	// sends fail over; a zero value disables that check
+	MaxErrorRate float64
+	MaxLatency   time.Duration
+	// Window is how many recent primary sends health is computed over, and MinSamples how
+	// many are needed before it is trusted
+	Window     int
+	MinSamples int
+	// While failed over, one send per ProbeInterval still goes to the primary; after
+	// RecoveryProbes healthy probes in a row sends fail back
+	ProbeInterval  time.Duration
+	RecoveryProbes int
+	// IsHealthError reports whether a send error reflects on the provider's health (as opposed
+	// to e.g. a bad recipient); nil counts every error
+	IsHealthError func(error) bool
+	// OnSwitch is called after every switchover; it must not block
+	OnSwitch func(SwitchEvent)
+}
+
+// sendSample is the outcome of one primary send
+type sendSample struct {
+	failed  bool
+	latency time.Duration
+}
+
+// failoverClient sends through the primary provider while it is healthy and through the
+// secondary otherwise
+type failoverClient struct {
+	primary   meta.Client
+	secondary meta.Client
+	cfg       FailoverConfig
+	now       func() time.Time
+	logger    utils.Logger
+
+	mu            sync.Mutex
+	samples       []sendSample
+	next          int
+	count         int
+	failedOver    bool
+	lastProbe     time.Time
+	healthyProbes int
+}
+
+// NewFailoverClient creates a client that fails new sends over to secondary while primary's
+// error rate or latency breaches the thresholds, and fails back once probes show it recovered
+func NewFailoverClient(primary, secondary meta.Client, cfg FailoverConfig, logger utils.Logger) meta.Client {
+	if cfg.Window <= 0 {
+		cfg.Window = 100
+	}
+	if cfg.MinSamples <= 0 || cfg.MinSamples > cfg.Window {
+		cfg.MinSamples = cfg.Window
+	}
+	if cfg.RecoveryProbes <= 0 {
+		cfg.RecoveryProbes = 1
+	}
+	failoverActive.WithLabelValues(cfg.PrimaryName, cfg.SecondaryName).Set(0)
+
+	return &failoverClient{
+		primary:   primary,
+		secondary: secondary,
+		cfg:       cfg,
+		now:       time.Now,
+		logger:    logger,
+		samples:   make([]sendSample, cfg.Window),
+	}
+}
+
+// SendTemplateMessage sends through the provider currently in use
+func (c *failoverClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	usePrimary, probe := c.route()
+	if !usePrimary {
+		return c.secondary.SendTemplateMessage(ctx, to, templateName, parameters)
+	}
+
+	start := c.now()
+	resp, err := c.primary.SendTemplateMessage(ctx, to, templateName, parameters)
+	if errors.Is(err, context.Canceled) {
+		return resp, err
+	}
+
+	sample := sendSample{failed: err != nil && c.isHealthError(err), latency: c.now().Sub(start)}
+	if probe {
+		c.observeProbe(sample)
+	} else {
+		c.observe(sample)
+	}
+	return resp, err
+}
+
+// ValidateWebhookSignature accepts webhooks signed for either provider
+func (c *failoverClient) ValidateWebhookSignature(signatureHeader, url string, body []byte) bool {
+	return c.primary.ValidateWebhookSignature(signatureHeader, url, body) ||
+		c.secondary.ValidateWebhookSignature(signatureHeader, url, body)
+}
+
+// route decides whether a send uses the primary, and whether it does so as a recovery probe
+func (c *failoverClient) route() (usePrimary, probe bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.failedOver {
+		return true, false
+	}
+	if now := c.now(); now.Sub(c.lastProbe) >= c.cfg.ProbeInterval {
+		c.lastProbe = now
+		return true, true
+	}
+	return false, false
+}
+
+// isHealthError applies the configured error classification
+func (c *failoverClient) isHealthError(err error) bool {
+	if c.cfg.IsHealthError == nil {
+		return true
+	}
+	return c.cfg.IsHealthError(err)
+}
+
+// breach returns the threshold the sample violates, if any, for probes
+func (c *failoverClient) breach(sample sendSample) string {
+	if sample.failed {
+		return SwitchReasonErrorRate
+	}
+	if c.cfg.MaxLatency > 0 && sample.latency > c.cfg.MaxLatency {
+		return SwitchReasonLatency
+	}
+	return ""
+}
+
+// observe records a regular primary send and fails over once the window breaches a threshold
+func (c *failoverClient) observe(sample sendSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failedOver {
+		return
+	}
+
+	c.samples[c.next] = sample
+	c.next = (c.next + 1) % len(c.samples)
+	if c.count < len(c.samples) {
+		c.count++
+	}
+	if c.count < c.cfg.MinSamples {
+		return
+	}
+
+	errorRate, p95 := c.health()
+	var reason string
+	switch {
+	case c.cfg.MaxErrorRate > 0 && errorRate > c.cfg.MaxErrorRate:
+		reason = SwitchReasonErrorRate
+	case c.cfg.MaxLatency > 0 && p95 > c.cfg.MaxLatency:
+		reason = SwitchReasonLatency
+	default:
+		return
+	}
+
+	c.failedOver = true
+	c.lastProbe = c.now()
+	c.healthyProbes = 0
+	c.switchover(SwitchEvent{
+		From:       c.cfg.PrimaryName,
+		To:         c.cfg.SecondaryName,
+		Reason:     reason,
+		ErrorRate:  errorRate,
+		LatencyP95: p95,
+		At:         c.now(),
+	})
+}
+
+// observeProbe records a probe send and fails back after enough healthy probes in a row
+func (c *failoverClient) observeProbe(sample sendSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.failedOver {
+		return
+	}
+	if reason := c.breach(sample); reason != "" {
+		c.healthyProbes = 0
+		c.logger.Debug("Primary provider probe failed", "provider", c.cfg.PrimaryName, "reason", reason)
+		return
+	}
+
+	c.healthyProbes++
+	if c.healthyProbes < c.cfg.RecoveryProbes {
+		return
+	}
+
+	c.failedOver = false
+	c.count, c.next = 0, 0
+	c.switchover(SwitchEvent{
+		From:   c.cfg.SecondaryName,
+		To:     c.cfg.PrimaryName,
+		Reason: SwitchReasonRecovered,
+		At:     c.now(),
+	})
+}
+
+// health returns the error rate and p95 latency of the sampled window; callers hold mu
+func (c *failoverClient) health() (float64, time.Duration) {
+	var failures int
+	latencies := make([]time.Duration, 0, c.count)
+	for _, sample := range c.samples[:c.count] {
+		if sample.failed {
+			failures++
+		}
+		latencies = append(latencies, sample.latency)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p95 := latencies[(len(latencies)*95-1)/100]
+	return float64(failures) / float64(c.count), p95
+}
+
+// switchover publishes a switch; callers hold mu
+func (c *failoverClient) switchover(event SwitchEvent) {
+	active := 0.0
+	if c.failedOver {
+		active = 1
+	}
+	failoverActive.WithLabelValues(c.cfg.PrimaryName, c.cfg.SecondaryName).Set(active)
+	providerSwitchovers.WithLabelValues(event.From, event.To).Inc()
+	c.logger.Warn("Switched WhatsApp provider", "from", event.From, "to", event.To, "reason", event.Reason, "error_rate", event.ErrorRate, "latency_p95", event.LatencyP95)
+
+	if c.cfg.OnSwitch != nil {
+		c.cfg.OnSwitch(event)
+	}
+}
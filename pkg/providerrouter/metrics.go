@@ -0,0 +1,41 @@
+// pkg/providerrouter/metrics.go
+package providerrouter
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"messaging-microservice/pkg/meta"
+)
+
+// providerSends counts sends per provider and outcome
+var providerSends = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "whatsapp_provider_sends_total",
+	Help: "Sends per provider, by result (success or failure).",
+}, []string{"provider", "result"})
+
+// instrumentedClient counts the sends of one provider
+type instrumentedClient struct {
+	meta.Client
+	name string
+}
+
+// NewInstrumentedClient counts the sends made through client in whatsapp_provider_sends_total.
+// Wrap each provider once, below any routing, so every send is counted exactly once.
+func NewInstrumentedClient(name string, client meta.Client) meta.Client {
+	return &instrumentedClient{Client: client, name: name}
+}
+
+// SendTemplateMessage sends and records the outcome
+func (c *instrumentedClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	resp, err := c.Client.SendTemplateMessage(ctx, to, templateName, parameters)
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	providerSends.WithLabelValues(c.name, result).Inc()
+	return resp, err
+}
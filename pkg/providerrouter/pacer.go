@@ -0,0 +1,116 @@
+// pkg/providerrouter/pacer.go
+package providerrouter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+// pacerKey is the rate limiter bucket provider sends are paced by
+const pacerKey = "provider_sends"
+
+// pacedClient spaces sends out to at most a given rate
+type pacedClient struct {
+	client  meta.Client
+	limiter utils.RateLimiter
+	limit   utils.RateLimit
+	now     func() time.Time
+	logger  utils.Logger
+
+	mu             sync.Mutex
+	exhaustedUntil time.Time
+	factor         float64
+}
+
+// PacedClient is a GatedClient whose rate can be scaled at runtime
+type PacedClient interface {
+	GatedClient
+	// SetRateFactor scales the configured rate; 1 restores it
+	SetRateFactor(factor float64)
+}
+
+// NewPacedClient wraps client so sends wait for a token from limiter's bucket. With a Redis
+// limiter the rate is shared by all replicas, which is what provider throughput limits need.
+func NewPacedClient(client meta.Client, limiter utils.RateLimiter, limit utils.RateLimit, logger utils.Logger) PacedClient {
+	return &pacedClient{
+		client:  client,
+		limiter: limiter,
+		limit:   limit,
+		now:     time.Now,
+		logger:  logger,
+		factor:  1,
+	}
+}
+
+// SetRateFactor scales the rate and burst by factor; factors outside (0, 1] restore the
+// configured rate
+func (c *pacedClient) SetRateFactor(factor float64) {
+	if factor <= 0 || factor > 1 {
+		factor = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.factor = factor
+}
+
+// currentLimit returns the configured limit scaled by the rate factor, keeping a burst of one
+func (c *pacedClient) currentLimit() utils.RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit := utils.RateLimit{RPS: c.limit.RPS * c.factor, Burst: int(float64(c.limit.Burst) * c.factor)}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return limit
+}
+
+// SendTemplateMessage waits for a token and sends
+func (c *pacedClient) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	for {
+		allowed, wait, err := c.limiter.Allow(ctx, pacerKey, c.currentLimit())
+		if err != nil {
+			// Pacing is best effort; a limiter outage must not stop sends
+			c.logger.Warn("Send pacer unavailable", "error", err)
+			break
+		}
+		if allowed {
+			break
+		}
+
+		c.mu.Lock()
+		c.exhaustedUntil = c.now().Add(wait)
+		c.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return c.client.SendTemplateMessage(ctx, to, templateName, parameters)
+}
+
+// ValidateWebhookSignature delegates to the wrapped client
+func (c *pacedClient) ValidateWebhookSignature(signatureHeader, url string, body []byte) bool {
+	return c.client.ValidateWebhookSignature(signatureHeader, url, body)
+}
+
+// Ready reports whether the bucket had tokens when last tried
+func (c *pacedClient) Ready() (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if remaining := c.exhaustedUntil.Sub(c.now()); remaining > 0 {
+		return false, remaining
+	}
+	return true, 0
+}
@@ -0,0 +1,333 @@
+// pkg/render/render.go
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Limits of safe mode
+const (
+	// MaxTemplateLength is the longest template text safe mode parses
+	MaxTemplateLength = 8192
+	// DefaultMaxOutput is the most bytes a template may render, WhatsApp's text limit
+	DefaultMaxOutput = 4096
+)
+
+// ErrOutputTooLong is returned when a template renders more than the engine's output limit
+var ErrOutputTooLong = errors.New("rendered text is too long")
+
+// Options configures an Engine
+type Options struct {
+	// Safe rejects templates that could run away or reach outside their parameters: nested
+	// template definitions, the call builtin, ranging over numbers and over-long texts
+	Safe bool
+	// MaxOutput bounds the rendered bytes; zero uses DefaultMaxOutput
+	MaxOutput int
+}
+
+// Engine parses message bodies written as Go text/template over the message parameters, with
+// helpers for defaults and formatting:
+//
+//	{{index . "name" | default "there"}}       a parameter that may be missing
+//	{{if eq .status "delayed"}}...{{end}}       conditionals
+//	{{currency .amount "EUR"}}                  €1,234.50
+//	{{date .eta "Mon, Jan 2"}}                  a date or RFC 3339 time, in a Go layout
+//	{{upper .code}} {{lower .code}} {{trim .code}}
+//
+// Referencing a parameter the message doesn't have with {{.name}} fails the render.
+type Engine struct {
+	opts Options
+}
+
+// NewEngine creates an engine
+func NewEngine(opts Options) *Engine {
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = DefaultMaxOutput
+	}
+	return &Engine{opts: opts}
+}
+
+// Template is a parsed message body; it is safe for concurrent use
+type Template struct {
+	tmpl      *template.Template
+	maxOutput int
+}
+
+// Parse parses a body, checking it against safe mode when the engine is in it
+func (e *Engine) Parse(name, text string) (*Template, error) {
+	if e.opts.Safe && len(text) > MaxTemplateLength {
+		return nil, fmt.Errorf("template %s is longer than %d bytes", name, MaxTemplateLength)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(helpers).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if e.opts.Safe {
+		if len(tmpl.Templates()) > 1 {
+			return nil, fmt.Errorf("template %s: define and block are not allowed", name)
+		}
+		if err := checkSafe(tmpl.Tree.Root); err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	return &Template{tmpl: tmpl, maxOutput: e.opts.MaxOutput}, nil
+}
+
+// Execute renders the template with the parameters, formatted with %v
+func (t *Template) Execute(parameters map[string]interface{}) (string, error) {
+	data := make(map[string]string, len(parameters))
+	for name, value := range parameters {
+		data[name] = fmt.Sprintf("%v", value)
+	}
+	out := &limitedBuilder{max: t.maxOutput}
+	if err := t.tmpl.Execute(out, data); err != nil {
+		if errors.Is(err, ErrOutputTooLong) {
+			return "", ErrOutputTooLong
+		}
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Parameters returns the names of the parameters the template refers to, sorted
+func (t *Template) Parameters() []string {
+	return t.parameters(false)
+}
+
+// RequiredParameters returns the names of the parameters the template refers to as .name,
+// which it fails to render without, sorted
+func (t *Template) RequiredParameters() []string {
+	return t.parameters(true)
+}
+
+func (t *Template) parameters(requiredOnly bool) []string {
+	names := make(map[string]bool)
+	collectParameters(t.tmpl.Tree.Root, names)
+	sorted := make([]string, 0, len(names))
+	for name, required := range names {
+		if required || !requiredOnly {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// limitedBuilder collects output up to max bytes
+type limitedBuilder struct {
+	strings.Builder
+	max int
+}
+
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, ErrOutputTooLong
+	}
+	return b.Builder.Write(p)
+}
+
+// checkSafe walks a template's nodes for the constructs safe mode rejects
+func checkSafe(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkSafe(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkSafe(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkSafe(cmd); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkSafe(arg); err != nil {
+				return err
+			}
+		}
+	case *parse.IdentifierNode:
+		if n.Ident == "call" {
+			return errors.New("call is not allowed")
+		}
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		// Ranging over parameters is bounded by them; ranging over a number is not
+		for _, cmd := range n.Pipe.Cmds {
+			for _, arg := range cmd.Args {
+				if _, ok := arg.(*parse.NumberNode); ok {
+					return errors.New("range over a number is not allowed")
+				}
+			}
+		}
+		return checkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return errors.New("template calls are not allowed")
+	}
+	return nil
+}
+
+// checkBranch checks the pipeline and both lists of an if, with or range
+func checkBranch(n *parse.BranchNode) error {
+	if err := checkSafe(n.Pipe); err != nil {
+		return err
+	}
+	if err := checkSafe(n.List); err != nil {
+		return err
+	}
+	return checkSafe(n.ElseList)
+}
+
+// collectParameters gathers the parameters a template refers to as .name, which are required,
+// or as index . "name"
+func collectParameters(node parse.Node, names map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectParameters(child, names)
+		}
+	case *parse.ActionNode:
+		collectParameters(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectParameters(cmd, names)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) >= 3 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "index" {
+				if _, ok := n.Args[1].(*parse.DotNode); ok {
+					if name, ok := n.Args[2].(*parse.StringNode); ok {
+						if _, seen := names[name.Text]; !seen {
+							names[name.Text] = false
+						}
+					}
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			collectParameters(arg, names)
+		}
+	case *parse.FieldNode:
+		names[n.Ident[0]] = true
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, names)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, names)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, names)
+	}
+}
+
+func collectBranch(n *parse.BranchNode, names map[string]bool) {
+	collectParameters(n.Pipe, names)
+	collectParameters(n.List, names)
+	collectParameters(n.ElseList, names)
+}
+
+// helpers are the functions templates may call besides text/template's builtins
+var helpers = template.FuncMap{
+	"default":  defaultValue,
+	"currency": formatCurrency,
+	"date":     formatDate,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+}
+
+// defaultValue returns value, or fallback when value is empty
+func defaultValue(fallback, value string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+// currencySymbols are the symbols written before amounts; other currencies follow the amount
+// with their code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"BRL": "R$",
+	"NGN": "₦",
+}
+
+// zeroDecimalCurrencies have no minor unit
+var zeroDecimalCurrencies = map[string]bool{"JPY": true, "KRW": true, "VND": true, "CLP": true}
+
+// formatCurrency writes a decimal amount with thousands separators and the currency's decimals:
+// "1234.5" in EUR is "€1,234.50", in CHF "1,234.50 CHF"
+func formatCurrency(amount, code string) (string, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return "", fmt.Errorf("currency: %q is not an amount", amount)
+	}
+	code = strings.ToUpper(code)
+	decimals := 2
+	if zeroDecimalCurrencies[code] {
+		decimals = 0
+	}
+
+	digits := value.FloatString(decimals)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, fraction, _ := strings.Cut(digits, ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	formatted := grouped.String()
+	if fraction != "" {
+		formatted += "." + fraction
+	}
+
+	if symbol, ok := currencySymbols[code]; ok {
+		return sign + symbol + formatted, nil
+	}
+	return sign + formatted + " " + code, nil
+}
+
+// dateLayouts are the layouts date parameters are read in
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// formatDate rewrites a date or time parameter in a Go layout
+func formatDate(value, layout string) (string, error) {
+	for _, in := range dateLayouts {
+		if t, err := time.Parse(in, strings.TrimSpace(value)); err == nil {
+			return t.Format(layout), nil
+		}
+	}
+	return "", fmt.Errorf("date: %q is not a date", value)
+}
@@ -0,0 +1,417 @@
+// pkg/schemaregistry/avro.go
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// AvroSchema converts between the JSON payloads the service produces and Avro binary. It covers
+// the types those payloads use: null, boolean, int, long, float, double, string, record, map,
+// array and unions; named type references are not supported.
+type AvroSchema struct {
+	text string
+	root *avroType
+}
+
+// avroType is one node of a parsed schema
+type avroType struct {
+	kind     string
+	fields   []avroField // record
+	values   *avroType   // map
+	items    *avroType   // array
+	branches []*avroType // union
+}
+
+// avroField is a record field; missing JSON fields take the default
+type avroField struct {
+	name       string
+	typ        *avroType
+	def        interface{}
+	hasDefault bool
+}
+
+// ParseAvroSchema parses an Avro schema in its JSON form
+func ParseAvroSchema(text string) (*AvroSchema, error) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return nil, fmt.Errorf("avro schema is not JSON: %w", err)
+	}
+	root, err := parseAvroType(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &AvroSchema{text: text, root: root}, nil
+}
+
+// String returns the schema text, as registered
+func (s *AvroSchema) String() string {
+	return s.text
+}
+
+func parseAvroType(raw interface{}) (*avroType, error) {
+	switch v := raw.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "string":
+			return &avroType{kind: v}, nil
+		}
+		return nil, fmt.Errorf("unsupported avro type %q", v)
+	case []interface{}:
+		union := &avroType{kind: "union"}
+		for _, branch := range v {
+			t, err := parseAvroType(branch)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, t)
+		}
+		return union, nil
+	case map[string]interface{}:
+		switch v["type"] {
+		case "record":
+			record := &avroType{kind: "record"}
+			fields, _ := v["fields"].([]interface{})
+			for _, f := range fields {
+				field, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("avro record field must be an object")
+				}
+				name, _ := field["name"].(string)
+				t, err := parseAvroType(field["type"])
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", name, err)
+				}
+				def, hasDefault := field["default"]
+				record.fields = append(record.fields, avroField{name: name, typ: t, def: def, hasDefault: hasDefault})
+			}
+			return record, nil
+		case "map":
+			values, err := parseAvroType(v["values"])
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{kind: "map", values: values}, nil
+		case "array":
+			items, err := parseAvroType(v["items"])
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{kind: "array", items: items}, nil
+		default:
+			return parseAvroType(v["type"])
+		}
+	}
+	return nil, fmt.Errorf("unsupported avro schema %v", raw)
+}
+
+// EncodeJSON converts a JSON document to Avro binary
+func (s *AvroSchema) EncodeJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeAvro(&buf, s.root, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeJSON converts Avro binary written with this schema back to a JSON document
+func (s *AvroSchema) DecodeJSON(data []byte) ([]byte, error) {
+	value, err := decodeAvro(bytes.NewReader(data), s.root)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func encodeAvro(buf *bytes.Buffer, t *avroType, value interface{}) error {
+	switch t.kind {
+	case "null":
+		if value != nil {
+			return fmt.Errorf("expected null, got %v", value)
+		}
+	case "boolean":
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected boolean, got %v", value)
+		}
+		if b {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case "int", "long":
+		n, err := jsonInt(value)
+		if err != nil {
+			return err
+		}
+		writeLong(buf, n)
+	case "float":
+		f, err := jsonFloat(value)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, float32(f))
+	case "double":
+		f, err := jsonFloat(value)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, f)
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %v", value)
+		}
+		writeLong(buf, int64(len(str)))
+		buf.WriteString(str)
+	case "record":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %v", value)
+		}
+		for _, field := range t.fields {
+			fieldValue, present := object[field.name]
+			if !present {
+				if !field.hasDefault {
+					return fmt.Errorf("field %s is missing and has no default", field.name)
+				}
+				fieldValue = field.def
+			}
+			if err := encodeAvro(buf, field.typ, normalizeDefault(fieldValue)); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+	case "map":
+		object, ok := value.(map[string]interface{})
+		if !ok && value != nil {
+			return fmt.Errorf("expected object, got %v", value)
+		}
+		// Sorted keys keep the encoding of equal payloads identical
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if len(keys) > 0 {
+			writeLong(buf, int64(len(keys)))
+			for _, key := range keys {
+				writeLong(buf, int64(len(key)))
+				buf.WriteString(key)
+				if err := encodeAvro(buf, t.values, object[key]); err != nil {
+					return fmt.Errorf("key %s: %w", key, err)
+				}
+			}
+		}
+		writeLong(buf, 0)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok && value != nil {
+			return fmt.Errorf("expected array, got %v", value)
+		}
+		if len(items) > 0 {
+			writeLong(buf, int64(len(items)))
+			for _, item := range items {
+				if err := encodeAvro(buf, t.items, item); err != nil {
+					return err
+				}
+			}
+		}
+		writeLong(buf, 0)
+	case "union":
+		index := unionBranch(t, value)
+		if index < 0 {
+			return fmt.Errorf("no union branch matches %v", value)
+		}
+		writeLong(buf, int64(index))
+		return encodeAvro(buf, t.branches[index], value)
+	}
+	return nil
+}
+
+// normalizeDefault turns schema defaults, decoded as float64, into JSON numbers
+func normalizeDefault(value interface{}) interface{} {
+	if f, ok := value.(float64); ok {
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return value
+}
+
+// unionBranch picks the union branch for a JSON value
+func unionBranch(t *avroType, value interface{}) int {
+	want := func(kinds ...string) int {
+		for _, kind := range kinds {
+			for i, branch := range t.branches {
+				if branch.kind == kind {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return want("null")
+	case bool:
+		return want("boolean")
+	case string:
+		return want("string")
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return want("long", "int", "double", "float")
+		}
+		return want("double", "float")
+	case map[string]interface{}:
+		return want("map", "record")
+	case []interface{}:
+		return want("array")
+	}
+	return -1
+}
+
+func jsonInt(value interface{}) (int64, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expected integer, got %v", value)
+	}
+	return n.Int64()
+}
+
+func jsonFloat(value interface{}) (float64, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expected number, got %v", value)
+	}
+	return n.Float64()
+}
+
+// writeLong writes a zig-zag varint
+func writeLong(buf *bytes.Buffer, n int64) {
+	var scratch [binary.MaxVarintLen64]byte
+	buf.Write(scratch[:binary.PutVarint(scratch[:], n)])
+}
+
+func readLong(r *bytes.Reader) (int64, error) {
+	return binary.ReadVarint(r)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	n, err := readLong(r)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > int64(r.Len()) {
+		return "", fmt.Errorf("invalid string length %d", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func decodeAvro(r *bytes.Reader, t *avroType) (interface{}, error) {
+	switch t.kind {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		return readLong(r)
+	case "float":
+		var f float32
+		err := binary.Read(r, binary.LittleEndian, &f)
+		return float64(f), err
+	case "double":
+		var f float64
+		if err := binary.Read(r, binary.LittleEndian, &f); err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.New("non-finite double cannot be represented in JSON")
+		}
+		return f, nil
+	case "string":
+		return readString(r)
+	case "record":
+		object := make(map[string]interface{}, len(t.fields))
+		for _, field := range t.fields {
+			value, err := decodeAvro(r, field.typ)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.name, err)
+			}
+			object[field.name] = value
+		}
+		return object, nil
+	case "map":
+		object := make(map[string]interface{})
+		err := readBlocks(r, func() error {
+			key, err := readString(r)
+			if err != nil {
+				return err
+			}
+			value, err := decodeAvro(r, t.values)
+			object[key] = value
+			return err
+		})
+		return object, err
+	case "array":
+		items := []interface{}{}
+		err := readBlocks(r, func() error {
+			item, err := decodeAvro(r, t.items)
+			items = append(items, item)
+			return err
+		})
+		return items, err
+	case "union":
+		index, err := readLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || int(index) >= len(t.branches) {
+			return nil, fmt.Errorf("invalid union branch %d", index)
+		}
+		return decodeAvro(r, t.branches[index])
+	}
+	return nil, fmt.Errorf("unsupported avro type %q", t.kind)
+}
+
+// readBlocks reads the blocks of a map or array, calling item once per entry
+func readBlocks(r *bytes.Reader, item func() error) error {
+	for {
+		count, err := readLong(r)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// A negative count is followed by the block's size in bytes
+			count = -count
+			if _, err := readLong(r); err != nil {
+				return err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
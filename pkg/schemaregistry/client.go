@@ -0,0 +1,144 @@
+// pkg/schemaregistry/client.go
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds the Schema Registry connection settings
+type Config struct {
+	// URL is the registry base URL, e.g. http://schema-registry:8081
+	URL string
+	// Username and Password enable basic auth when set
+	Username string
+	Password string
+	// Timeout bounds each registry request
+	Timeout time.Duration
+}
+
+// Client talks to a Confluent-compatible Schema Registry
+type Client interface {
+	// Register registers schema under subject, returning its global ID. Registering a schema
+	// the subject already has returns the existing ID.
+	Register(ctx context.Context, subject, schema string) (int, error)
+	// Schema returns the schema with the given ID
+	Schema(ctx context.Context, id int) (string, error)
+}
+
+// registryClient implements Client over the registry's REST API, caching schemas by ID since
+// they are immutable
+type registryClient struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu      sync.RWMutex
+	schemas map[int]string
+}
+
+// NewClient creates a Schema Registry client
+func NewClient(cfg Config) Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+
+	return &registryClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: timeout},
+		schemas:    make(map[int]string),
+	}
+}
+
+// Register posts the schema to /subjects/{subject}/versions
+func (c *registryClient) Register(ctx context.Context, subject, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	var resp struct {
+		ID int `json:"id"`
+	}
+	path := "/subjects/" + url.PathEscape(subject) + "/versions"
+	if err := c.do(ctx, http.MethodPost, path, body, &resp); err != nil {
+		return 0, fmt.Errorf("failed to register schema for %s: %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.schemas[resp.ID] = schema
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+// Schema fetches /schemas/ids/{id}, once per ID
+func (c *registryClient) Schema(ctx context.Context, id int) (string, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp struct {
+		Schema string `json:"schema"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return "", fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = resp.Schema
+	c.mu.Unlock()
+	return resp.Schema, nil
+}
+
+// do sends a registry request and decodes the JSON response into out
+func (c *registryClient) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var registryErr struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		if json.Unmarshal(data, &registryErr) == nil && registryErr.Message != "" {
+			return fmt.Errorf("schema registry error %d: %s", registryErr.ErrorCode, registryErr.Message)
+		}
+		return fmt.Errorf("schema registry returned status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,56 @@
+// pkg/secrets/aws.go
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// awsProvider reads a JSON key/value secret from AWS Secrets Manager
+type awsProvider struct {
+	client   *secretsmanager.Client
+	secretID string
+}
+
+// NewAWSProvider creates a provider for secretID using the default AWS credential chain
+func NewAWSProvider(ctx context.Context, region, secretID string) (Provider, error) {
+	opts := []func(*awsconfig.LoadOptions) error{}
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &awsProvider{
+		client:   secretsmanager.NewFromConfig(cfg),
+		secretID: secretID,
+	}, nil
+}
+
+// Fetch reads the secret's current version
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(p.secretID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value", p.secretID)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(*out.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", p.secretID, err)
+	}
+
+	return stringValues(data), nil
+}
@@ -0,0 +1,96 @@
+// pkg/secrets/secrets.go
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"messaging-microservice/pkg/utils"
+)
+
+// Provider fetches a set of named secrets from an external store
+type Provider interface {
+	// Fetch returns all secrets as key/value pairs
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Store caches secrets from a Provider and refreshes them periodically
+type Store struct {
+	provider Provider
+	interval time.Duration
+
+	mu        sync.RWMutex
+	values    map[string]string
+	listeners []func(key, value string)
+}
+
+// NewStore creates a secret store refreshed every interval
+func NewStore(provider Provider, interval time.Duration) *Store {
+	return &Store{
+		provider: provider,
+		interval: interval,
+		values:   make(map[string]string),
+	}
+}
+
+// Load fetches the secrets and notifies listeners of any value that changed
+func (s *Store) Load(ctx context.Context) error {
+	values, err := s.provider.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	var changed []string
+	for key, value := range values {
+		if s.values[key] != value {
+			changed = append(changed, key)
+		}
+	}
+	s.values = values
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, key := range changed {
+		for _, fn := range listeners {
+			fn(key, values[key])
+		}
+	}
+	return nil
+}
+
+// Get returns a secret and whether it exists
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// OnChange registers fn to be called when a secret changes on refresh
+func (s *Store) OnChange(fn func(key, value string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Run refreshes the secrets every interval until ctx is done
+func (s *Store) Run(ctx context.Context, logger utils.Logger) {
+	if s.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				logger.Error("Failed to refresh secrets", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// pkg/secrets/vault.go
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vaultProvider reads a KV version 2 secret from HashiCorp Vault
+type vaultProvider struct {
+	addr       string
+	token      string
+	path       string
+	httpClient *http.Client
+}
+
+// NewVaultProvider creates a provider for the KV v2 secret at path, e.g. "secret/data/whatsapp"
+func NewVaultProvider(addr, token, path string) Provider {
+	return &vaultProvider{
+		addr:       strings.TrimRight(addr, "/"),
+		token:      token,
+		path:       strings.Trim(path, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Fetch reads the secret's current version
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, err
+	}
+
+	return stringValues(secret.Data.Data), nil
+}
+
+// stringValues converts a decoded JSON object to strings, skipping nested values
+func stringValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case float64, bool:
+			values[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return values
+}
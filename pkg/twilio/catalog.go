@@ -0,0 +1,152 @@
+// pkg/twilio/catalog.go
+package twilio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+)
+
+// CatalogEntry is the body of a template in one locale, rendered by a render.Engine with the
+// message parameters: "Hola {{.name}}", or {{index . "1"}} for numbered parameters
+type CatalogEntry struct {
+	TemplateName string
+	Locale       string
+	Body         string
+}
+
+// Catalog holds the localized bodies sent for templates without a content template. It is
+// safe for concurrent use and can be replaced while sends go on.
+type Catalog struct {
+	defaultLocale string
+	engine        *render.Engine
+
+	mu     sync.RWMutex
+	bodies map[string]map[string]*render.Template
+}
+
+// NewCatalog creates an empty catalog parsing bodies with engine; bodies missing in a
+// recipient's locale are sent in defaultLocale
+func NewCatalog(defaultLocale string, engine *render.Engine) *Catalog {
+	return &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		engine:        engine,
+		bodies:        make(map[string]map[string]*render.Template),
+	}
+}
+
+// Replace swaps the catalog's bodies for entries. If any body fails to parse the catalog is
+// left unchanged.
+func (c *Catalog) Replace(entries []CatalogEntry) error {
+	bodies := make(map[string]map[string]*render.Template)
+	for _, entry := range entries {
+		locale := normalizeLocale(entry.Locale)
+		tmpl, err := c.engine.Parse(entry.TemplateName+"/"+locale, entry.Body)
+		if err != nil {
+			return fmt.Errorf("twilio catalog: body of %s in %s: %w", entry.TemplateName, entry.Locale, err)
+		}
+		if bodies[entry.TemplateName] == nil {
+			bodies[entry.TemplateName] = make(map[string]*render.Template)
+		}
+		bodies[entry.TemplateName][locale] = tmpl
+	}
+
+	c.mu.Lock()
+	c.bodies = bodies
+	c.mu.Unlock()
+	return nil
+}
+
+// Has reports whether the catalog has a body for the template in any locale
+func (c *Catalog) Has(templateName string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.bodies[templateName]) > 0
+}
+
+// Lookup returns the template's body in the parameters' locale, and the locale it is in. The
+// locale falls back to its language ("pt" for "pt_BR"), then to the default locale.
+func (c *Catalog) Lookup(templateName string, parameters map[string]interface{}) (*render.Template, string, bool) {
+	c.mu.RLock()
+	locales := c.bodies[templateName]
+	c.mu.RUnlock()
+
+	requested, _ := parameters[meta.LocaleParameter].(string)
+	requested = normalizeLocale(requested)
+	candidates := []string{requested}
+	if language, _, found := strings.Cut(requested, "_"); found {
+		candidates = append(candidates, language)
+	}
+	for _, locale := range append(candidates, c.defaultLocale) {
+		if tmpl, ok := locales[locale]; ok && locale != "" {
+			return tmpl, locale, true
+		}
+	}
+	return nil, "", false
+}
+
+// Render executes the template's body in the parameters' locale with the parameters
+func (c *Catalog) Render(templateName string, parameters map[string]interface{}) (string, error) {
+	tmpl, _, ok := c.Lookup(templateName, parameters)
+	if !ok {
+		locale, _ := parameters[meta.LocaleParameter].(string)
+		return "", fmt.Errorf("twilio catalog: no body for template %q in %q or %q", templateName, locale, c.defaultLocale)
+	}
+	body, err := tmpl.Execute(withoutLocale(parameters))
+	if err != nil {
+		return "", fmt.Errorf("twilio catalog: %w", err)
+	}
+	return body, nil
+}
+
+// withoutLocale returns the parameters other than the locale
+func withoutLocale(parameters map[string]interface{}) map[string]interface{} {
+	if _, ok := parameters[meta.LocaleParameter]; !ok {
+		return parameters
+	}
+	rest := make(map[string]interface{}, len(parameters))
+	for name, value := range parameters {
+		if name != meta.LocaleParameter {
+			rest[name] = value
+		}
+	}
+	return rest
+}
+
+// normalizeLocale spells locales like WhatsApp does: "pt-br" becomes "pt_BR"
+func normalizeLocale(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "-", "_"), "_")
+	if !found {
+		return strings.ToLower(language)
+	}
+	return strings.ToLower(language) + "_" + strings.ToUpper(region)
+}
+
+// LoadCatalogFile reads catalog entries from a JSON file mapping template names to bodies by
+// locale: {"order_shipped": {"en": "Order {{.order_id}} shipped", "es": "..."}}
+func LoadCatalogFile(path string) ([]CatalogEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file map[string]map[string]string
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("twilio catalog: %s: %w", path, err)
+	}
+
+	var entries []CatalogEntry
+	for templateName, locales := range file {
+		for locale, body := range locales {
+			entries = append(entries, CatalogEntry{TemplateName: templateName, Locale: locale, Body: body})
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,306 @@
+// pkg/twilio/client.go
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+	"messaging-microservice/pkg/utils"
+)
+
+// defaultAPIURL is the Twilio REST API base URL
+const defaultAPIURL = "https://api.twilio.com/2010-04-01"
+
+// Config holds the Twilio account and the content templates it sends
+type Config struct {
+	AccountSID string
+	AuthToken  string
+	// From is the WhatsApp sender ("whatsapp:+14155238886"); MessagingServiceSID, when set,
+	// sends through a messaging service instead
+	From                string
+	MessagingServiceSID string
+	// ContentSIDs maps template names to Content API SIDs (HX...). Template names that are
+	// themselves content SIDs are sent as is.
+	ContentSIDs map[string]string
+	// Catalog holds the localized bodies of templates without a content SID; those are sent
+	// as plain text in the recipient's locale, see meta.LocaleParameter
+	Catalog *Catalog
+	// StatusCallbackURL receives the message status callbacks; empty uses the sender's default
+	StatusCallbackURL string
+	// CallTimeout bounds each request by the caller's deadline; zero uses
+	// utils.DefaultCallTimeout
+	CallTimeout utils.CallTimeout
+	// HTTP sets the retries, per-attempt timeout and transport of requests; zero retries
+	// with utils.DefaultHTTPRetry. Message sends are only retried when they could not connect.
+	HTTP utils.HTTPClientConfig
+	// APIURL overrides the API base URL, for tests
+	APIURL string
+}
+
+// Message is the Twilio message resource returned by the API
+type Message struct {
+	SID                 string  `json:"sid"`
+	AccountSID          string  `json:"account_sid"`
+	MessagingServiceSID string  `json:"messaging_service_sid"`
+	To                  string  `json:"to"`
+	From                string  `json:"from"`
+	Status              string  `json:"status"`
+	ErrorCode           *int    `json:"error_code"`
+	ErrorMessage        *string `json:"error_message"`
+	NumSegments         string  `json:"num_segments"`
+	Price               *string `json:"price"`
+	PriceUnit           string  `json:"price_unit"`
+	DateCreated         string  `json:"date_created"`
+}
+
+// APIError is returned when Twilio rejects a request or reports a failed message
+type APIError struct {
+	StatusCode int
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	MoreInfo   string `json:"more_info"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("twilio API error: %d - %s", e.Code, e.Message)
+}
+
+// ErrorCode returns the Twilio error code as a string, as stored with failed messages
+func (e *APIError) ErrorCode() string {
+	return strconv.Itoa(e.Code)
+}
+
+// Provider names the provider the error code belongs to
+func (e *APIError) Provider() string {
+	return "twilio"
+}
+
+// client implements meta.Client using Twilio's Messages and Content APIs
+type client struct {
+	cfg        Config
+	httpClient utils.HTTPClient
+	logger     utils.Logger
+}
+
+// NewClient creates a Twilio WhatsApp client
+func NewClient(cfg Config, logger utils.Logger) meta.Client {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultAPIURL
+	}
+	if cfg.CallTimeout == (utils.CallTimeout{}) {
+		cfg.CallTimeout = utils.DefaultCallTimeout
+	}
+	if cfg.HTTP.Retry == (utils.Backoff{}) {
+		cfg.HTTP.Retry = utils.DefaultHTTPRetry
+	}
+
+	return &client{
+		cfg: cfg,
+		// Requests are bounded by their context, see Config.CallTimeout
+		httpClient: utils.NewHTTPClientWithConfig(cfg.HTTP, logger),
+		logger:     logger,
+	}
+}
+
+// SendTemplateMessage sends the content template mapped to templateName with the parameters
+// as its content variables. Button URLs of content templates are ordinary content variables.
+// Templates without a content template are sent as the catalog's body in the recipient's
+// locale.
+func (c *client) SendTemplateMessage(ctx context.Context, to, templateName string, parameters map[string]interface{}) (*meta.MessageResponse, error) {
+	if templateName == meta.CTAURLTemplate {
+		return nil, fmt.Errorf("twilio: CTA URL messages are not supported; use a content template with a URL button")
+	}
+	if meta.IsProductTemplate(templateName) {
+		return nil, fmt.Errorf("twilio: product messages are not supported; use a catalog content template")
+	}
+	form := url.Values{}
+	form.Set("To", "whatsapp:"+strings.TrimPrefix(to, "whatsapp:"))
+	if c.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", c.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", c.cfg.From)
+	}
+	contentSID, err := c.contentSID(templateName)
+	switch {
+	case err == nil:
+		variables := make(map[string]string, len(parameters))
+		for name, value := range parameters {
+			if name != meta.LocaleParameter {
+				variables[name] = fmt.Sprintf("%v", value)
+			}
+		}
+		variablesJSON, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("ContentSid", contentSID)
+		form.Set("ContentVariables", string(variablesJSON))
+	case c.cfg.Catalog.Has(templateName):
+		body, err := c.cfg.Catalog.Render(templateName, parameters)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("Body", body)
+	default:
+		return nil, err
+	}
+	if c.cfg.StatusCallbackURL != "" {
+		form.Set("StatusCallback", c.cfg.StatusCallbackURL)
+	}
+	payload := []byte(form.Encode())
+
+	ctx, cancel, err := c.cfg.CallTimeout.Context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", c.cfg.APIURL, c.cfg.AccountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.cfg.AccountSID, c.cfg.AuthToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		c.logger.Error("Twilio API error", "status", resp.StatusCode, "body", string(body))
+
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(body, apiErr); err == nil && apiErr.Code != 0 {
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("twilio API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var message Message
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, err
+	}
+
+	// Twilio accepts some sends it already knows will fail
+	if message.ErrorCode != nil {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Code: *message.ErrorCode}
+		if message.ErrorMessage != nil {
+			apiErr.Message = *message.ErrorMessage
+		}
+		return nil, apiErr
+	}
+
+	c.logger.Debug("Twilio accepted message", "sid", message.SID, "status", message.Status, "content_sid", contentSID, "template_id", templateName)
+	return toMessageResponse(&message, payload), nil
+}
+
+// TextTemplate implements meta.TextTemplateSource for templates sent from the catalog
+func (c *client) TextTemplate(templateName string, parameters map[string]interface{}) (*render.Template, string, bool) {
+	if _, err := c.contentSID(templateName); err == nil || !c.cfg.Catalog.Has(templateName) {
+		return nil, "", false
+	}
+	return c.cfg.Catalog.Lookup(templateName, parameters)
+}
+
+// contentSID resolves the content template of a template name
+func (c *client) contentSID(templateName string) (string, error) {
+	if sid, ok := c.cfg.ContentSIDs[templateName]; ok {
+		return sid, nil
+	}
+	if strings.HasPrefix(templateName, "HX") {
+		return templateName, nil
+	}
+	return "", fmt.Errorf("twilio: no content SID configured for template %q", templateName)
+}
+
+// toMessageResponse converts a Twilio message to the provider-neutral response
+func toMessageResponse(message *Message, payload []byte) *meta.MessageResponse {
+	resp := &meta.MessageResponse{
+		MessagingProduct: "whatsapp",
+		RequestPayload:   payload,
+	}
+	resp.Contacts = append(resp.Contacts, struct {
+		WaID string `json:"wa_id"`
+	}{WaID: strings.TrimPrefix(message.To, "whatsapp:+")})
+	resp.Messages = append(resp.Messages, struct {
+		ID string `json:"id"`
+	}{ID: message.SID})
+	return resp
+}
+
+// ValidateWebhookSignature checks an X-Twilio-Signature header: the base64 HMAC-SHA1, keyed
+// with the auth token, of the full callback URL followed by every form parameter's name and
+// value sorted by name. JSON callbacks sign the URL alone and carry the body's SHA-256 in
+// the bodySHA256 query parameter.
+func (c *client) ValidateWebhookSignature(signature, callbackURL string, body []byte) bool {
+	if c.cfg.AuthToken == "" || signature == "" {
+		return false
+	}
+
+	parsed, err := url.Parse(callbackURL)
+	if err != nil {
+		return false
+	}
+
+	data := callbackURL
+	if bodyHash := parsed.Query().Get("bodySHA256"); bodyHash != "" {
+		sum := sha256.Sum256(body)
+		if !hmac.Equal([]byte(hex.EncodeToString(sum[:])), []byte(bodyHash)) {
+			return false
+		}
+	} else {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return false
+		}
+		data += signedParams(form)
+	}
+
+	mac := hmac.New(sha1.New, []byte(c.cfg.AuthToken))
+	mac.Write([]byte(data))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// signedParams concatenates form parameters the way Twilio signs them
+func signedParams(form url.Values) string {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		values := append([]string(nil), form[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			b.WriteString(name)
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+// pkg/twilio/status.go
+package twilio
+
+import (
+	"errors"
+	"net/url"
+)
+
+// StatusCallback is a message status callback posted by Twilio
+type StatusCallback struct {
+	MessageSID string
+	AccountSID string
+	// MessagingServiceSID is set when the message was sent through a messaging service
+	MessagingServiceSID string
+	MessageStatus       string
+	To                  string
+	From                string
+	// ErrorCode and ErrorMessage are set for failed and undelivered messages
+	ErrorCode    string
+	ErrorMessage string
+	// ChannelStatusMessage carries the WhatsApp channel's own explanation, when given
+	ChannelStatusMessage string
+}
+
+// ParseStatusCallback reads a form-encoded status callback
+func ParseStatusCallback(form url.Values) (*StatusCallback, error) {
+	callback := &StatusCallback{
+		MessageSID:           form.Get("MessageSid"),
+		AccountSID:           form.Get("AccountSid"),
+		MessagingServiceSID:  form.Get("MessagingServiceSid"),
+		MessageStatus:        form.Get("MessageStatus"),
+		To:                   form.Get("To"),
+		From:                 form.Get("From"),
+		ErrorCode:            form.Get("ErrorCode"),
+		ErrorMessage:         form.Get("ErrorMessage"),
+		ChannelStatusMessage: form.Get("ChannelStatusMessage"),
+	}
+	if callback.MessageSID == "" {
+		// Older callbacks only carry SmsSid and SmsStatus
+		callback.MessageSID = form.Get("SmsSid")
+	}
+	if callback.MessageStatus == "" {
+		callback.MessageStatus = form.Get("SmsStatus")
+	}
+
+	if callback.MessageSID == "" || callback.MessageStatus == "" {
+		return nil, errors.New("twilio status callback is missing MessageSid or MessageStatus")
+	}
+	if callback.ErrorMessage == "" {
+		callback.ErrorMessage = callback.ChannelStatusMessage
+	}
+	return callback, nil
+}
+
+// Status maps the Twilio message status to the service's message status. Intermediate
+// states before Twilio hands the message to WhatsApp report ok=false.
+func (c *StatusCallback) Status() (status string, ok bool) {
+	switch c.MessageStatus {
+	case "sent":
+		return "sent", true
+	case "delivered":
+		return "delivered", true
+	case "read":
+		return "read", true
+	case "failed", "undelivered":
+		return "failed", true
+	default:
+		return "", false
+	}
+}
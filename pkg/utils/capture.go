@@ -0,0 +1,182 @@
+// pkg/utils/capture.go
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redacted replaces credentials in captured requests and responses
+const redacted = "[REDACTED]"
+
+// HTTPExchange is a captured HTTP request and its response, with credentials redacted and
+// bodies cut at the capturing transport's limit
+type HTTPExchange struct {
+	Method          string
+	URL             string
+	RequestHeaders  map[string]string
+	RequestBody     string
+	StatusCode      int
+	ResponseHeaders map[string]string
+	ResponseBody    string
+	// Error is set when no response was received
+	Error     string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+type captureContextKey struct{}
+
+// WithCapture returns a copy of ctx whose requests through a capturing transport are passed to
+// record. record may be called concurrently.
+func WithCapture(ctx context.Context, record func(HTTPExchange)) context.Context {
+	return context.WithValue(ctx, captureContextKey{}, record)
+}
+
+// credentialHeaders are the headers whose values are never captured
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Hub-Signature-256": true,
+	"X-Twilio-Signature":  true,
+}
+
+// credentialFields are the query, form and JSON fields whose values are never captured
+var credentialFields = []string{
+	"access_token", "input_token", "fb_exchange_token", "client_secret", "appsecret_proof", "auth_token", "password",
+}
+
+// credentialJSON matches the string values of credential fields in JSON, including JSON cut
+// short at the body limit
+var credentialJSON = regexp.MustCompile(`("(?:` + strings.Join(credentialFields, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// capturingTransport passes the requests of contexts from WithCapture to their recorder
+type capturingTransport struct {
+	next    http.RoundTripper
+	maxBody int
+}
+
+// NewCapturingTransport returns a transport that captures requests made with a context from
+// WithCapture, keeping up to maxBody bytes of each body. Other requests go to next untouched.
+func NewCapturingTransport(next http.RoundTripper, maxBody int) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &capturingTransport{next: next, maxBody: maxBody}
+}
+
+// RoundTrip sends req through the next transport and records the exchange
+func (t *capturingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	record, ok := req.Context().Value(captureContextKey{}).(func(HTTPExchange))
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	exchange := HTTPExchange{
+		Method:         req.Method,
+		URL:            redactURL(req.URL),
+		RequestHeaders: redactHeaders(req.Header),
+		StartedAt:      time.Now(),
+	}
+	// The body is read from a copy so the one sent is untouched; bodies that cannot be copied
+	// are not captured
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			head, _ := io.ReadAll(io.LimitReader(body, int64(t.maxBody)+1))
+			body.Close()
+			exchange.RequestBody = t.redactBody(head, req.Header.Get("Content-Type"))
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	exchange.Duration = time.Since(exchange.StartedAt)
+	if err != nil {
+		exchange.Error = err.Error()
+		record(exchange)
+		return nil, err
+	}
+
+	// The caller reads the captured head followed by the rest of the body
+	head, readErr := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBody)+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeaders = redactHeaders(resp.Header)
+	exchange.ResponseBody = t.redactBody(head, resp.Header.Get("Content-Type"))
+	if readErr != nil {
+		exchange.Error = "reading response body: " + readErr.Error()
+	}
+	record(exchange)
+	return resp, nil
+}
+
+// redactBody returns the first maxBody bytes of a body with credential fields redacted. body
+// holds one byte more when the body was longer.
+func (t *capturingTransport) redactBody(body []byte, contentType string) string {
+	truncated := len(body) > t.maxBody
+	if truncated {
+		body = body[:t.maxBody]
+	}
+	text := string(body)
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(text); err == nil {
+			redactValues(values)
+			text = values.Encode()
+		}
+	} else {
+		text = credentialJSON.ReplaceAllString(text, `$1"`+redacted+`"`)
+	}
+	if truncated {
+		text += fmt.Sprintf("...[truncated at %d bytes]", t.maxBody)
+	}
+	return text
+}
+
+// redactURL returns u as a string without user info or credential query parameters
+func redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	if clean.RawQuery != "" {
+		query := clean.Query()
+		redactValues(query)
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
+
+// redactValues replaces the values of credential fields
+func redactValues(values url.Values) {
+	for _, field := range credentialFields {
+		if _, ok := values[field]; ok {
+			values.Set(field, redacted)
+		}
+	}
+}
+
+// redactHeaders flattens headers, replacing the values of credential headers
+func redactHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		if credentialHeaders[http.CanonicalHeaderKey(name)] {
+			flat[name] = redacted
+			continue
+		}
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
@@ -0,0 +1,46 @@
+// pkg/utils/deadline.go
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDeadlineTooClose is returned, along with context.DeadlineExceeded, for calls not started
+// because the caller had less than their CallTimeout's Floor left
+var ErrDeadlineTooClose = errors.New("caller deadline too close")
+
+// CallTimeout bounds an outbound call by the deadline of the request it serves: the call gets
+// the time the caller has left, at most Ceiling, and is not started with less than Floor left.
+// Calls without a caller deadline get Ceiling.
+type CallTimeout struct {
+	Floor   time.Duration
+	Ceiling time.Duration
+}
+
+// DefaultCallTimeout keeps the 10s limit outbound calls had before following caller deadlines
+var DefaultCallTimeout = CallTimeout{Floor: 100 * time.Millisecond, Ceiling: 10 * time.Second}
+
+// Context returns ctx bounded for one call, or ErrDeadlineTooClose when the caller has less
+// than Floor left. A zero Ceiling leaves the caller's deadline as the only bound.
+func (t CallTimeout) Context(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	timeout := t.Ceiling
+	if deadline, ok := ctx.Deadline(); ok {
+		left := time.Until(deadline)
+		if left <= 0 || left < t.Floor {
+			return ctx, func() {}, fmt.Errorf("%w: %v left, %v needed: %w", ErrDeadlineTooClose, left.Round(time.Millisecond), t.Floor, context.DeadlineExceeded)
+		}
+		if left < timeout {
+			// The caller's deadline comes first
+			timeout = 0
+		}
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
@@ -12,11 +12,6 @@ func HasWhatsAppPrefix(phoneNumber string) bool {
 	return strings.HasPrefix(phoneNumber, "whatsapp:")
 }
 
-// GetPlaceholderIndex converts an index to a string for SQL placeholders
-func GetPlaceholderIndex(index int) string {
-	return strconv.Itoa(index)
-}
-
 // IsValidPhoneNumber checks if a phone number is valid
 // This is a simplified implementation and should be replaced with
 // proper phone number validation in a production environment
@@ -5,8 +5,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -17,25 +20,169 @@ type HTTPClient interface {
 	Post(ctx context.Context, url string, body interface{}, headers map[string]string) (*http.Response, error)
 }
 
+// HTTPClientConfig tunes an HTTPClient. Network errors and 429, 502, 503 and 504 responses
+// are retried when the request is idempotent: GET, HEAD, OPTIONS, PUT and DELETE requests, and
+// others carrying an Idempotency-Key or X-Idempotency-Key header, as net/http decides. Other
+// requests are only retried when they could not connect, since they were never sent.
+type HTTPClientConfig struct {
+	// Timeout bounds a whole request, retries included; zero leaves it to the context
+	Timeout time.Duration
+	// AttemptTimeout bounds each attempt; zero leaves it to Timeout and the context
+	AttemptTimeout time.Duration
+	// Retry is the number of attempts and the wait between them; Retry-After is honoured up
+	// to Retry.Max. A zero value makes one attempt.
+	Retry Backoff
+	// Transport sends the requests; nil uses http.DefaultTransport. See NewTransport.
+	Transport http.RoundTripper
+}
+
+// DefaultHTTPRetry retries idempotent requests twice, after about 200ms and 400ms
+var DefaultHTTPRetry = Backoff{Attempts: 3, Initial: 200 * time.Millisecond, Max: 2 * time.Second, Jitter: 0.2}
+
+// retryableStatuses are the responses worth trying again
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// maxDrainBytes is how much of a discarded response body is read so its connection can be
+// reused
+const maxDrainBytes = 64 << 10
+
 // httpClient implements HTTPClient
 type httpClient struct {
 	client *http.Client
+	cfg    HTTPClientConfig
 	logger Logger
 }
 
 // NewHTTPClient creates a new HTTP client
 func NewHTTPClient(timeout time.Duration, logger Logger) HTTPClient {
+	return NewHTTPClientWithConfig(HTTPClientConfig{Timeout: timeout}, logger)
+}
+
+// NewHTTPClientWithConfig creates an HTTP client with per-attempt timeouts and retries
+func NewHTTPClientWithConfig(cfg HTTPClientConfig, logger Logger) HTTPClient {
 	return &httpClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Transport: cfg.Transport,
+			Timeout:   cfg.Timeout,
 		},
+		cfg:    cfg,
 		logger: logger,
 	}
 }
 
-// Do executes an HTTP request
+// Do executes an HTTP request, retrying idempotent requests that failed transiently. The
+// body of every discarded response is drained and closed.
 func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
-	return c.client.Do(req)
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	idempotent := isIdempotent(req)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(req, attempt)
+		retry := replayable && attempt < c.cfg.Retry.Attempts && req.Context().Err() == nil
+		if err != nil {
+			retry = retry && (idempotent || neverSent(err))
+		} else {
+			retry = retry && idempotent && retryableStatuses[resp.StatusCode]
+		}
+		if !retry {
+			return resp, err
+		}
+
+		delay := c.cfg.Retry.Delay(attempt)
+		if err == nil {
+			if after := retryAfter(resp); after > 0 && (c.cfg.Retry.Max <= 0 || after <= c.cfg.Retry.Max) {
+				delay = after
+			}
+			drain(resp.Body)
+			c.logger.Debug("Retrying HTTP request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt, "status", resp.StatusCode, "retry_in", delay)
+		} else {
+			c.logger.Debug("Retrying HTTP request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt, "error", err, "retry_in", delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends one try of req, with a fresh body and the attempt timeout
+func (c *httpClient) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	try := req
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		try = req.Clone(req.Context())
+		try.Body = body
+	}
+	if c.cfg.AttemptTimeout <= 0 {
+		return c.client.Do(try)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.cfg.AttemptTimeout)
+	resp, err := c.client.Do(try.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt's context has to outlive Do until the caller is done with the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// isIdempotent reports whether req can be sent more than once, following net/http
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// neverSent reports whether err means the request could not reach the server, so even a
+// request that is not idempotent can be sent again
+func neverSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter returns the wait a response asks for in seconds, or zero
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// drain reads what is left of a body, up to maxDrainBytes, and closes it
+func drain(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// cancelOnClose releases an attempt's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
 }
 
 // Get executes an HTTP GET request
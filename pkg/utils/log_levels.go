@@ -0,0 +1,247 @@
+// pkg/utils/log_levels.go
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Fields debug logs can be enabled for
+const (
+	LogFieldComponent   = "component"
+	LogFieldPhoneNumber = "phone_number"
+)
+
+// logLevelNames are the levels that can be set
+var logLevelNames = map[string]zapcore.Level{
+	"debug": zapcore.DebugLevel,
+	"info":  zapcore.InfoLevel,
+	"warn":  zapcore.WarnLevel,
+	"error": zapcore.ErrorLevel,
+}
+
+// DebugTarget enables debug logs of the entries whose Field is Value: a component's, or
+// those about a phone number. Until is zero for a target that doesn't expire.
+type DebugTarget struct {
+	Field string
+	Value string
+	Until time.Time
+}
+
+// LogLevelState is the level of a logger, when a changed level reverts (zero when it doesn't)
+// and the debug targets in force
+type LogLevelState struct {
+	Level   string
+	Until   time.Time
+	Targets []DebugTarget
+}
+
+// logLevelState is what loggers read on every entry
+type logLevelState struct {
+	level   zapcore.Level
+	until   time.Time
+	targets []DebugTarget
+	// expires is the earliest expiry of the level and targets, zero when none expire
+	expires time.Time
+}
+
+// LogLevels holds the level of the loggers created with it and the debug targets, which can
+// be changed while the service runs
+type LogLevels struct {
+	base  zapcore.Level
+	mu    sync.Mutex
+	state atomic.Pointer[logLevelState]
+}
+
+// IsValidLogLevel reports whether level is one SetLogLevel and NewLogLevels accept
+func IsValidLogLevel(level string) bool {
+	_, ok := logLevelNames[level]
+	return ok
+}
+
+// NewLogLevels creates log levels starting at level; an unknown level means info
+func NewLogLevels(level string) *LogLevels {
+	base, ok := logLevelNames[level]
+	if !ok {
+		base = zapcore.InfoLevel
+	}
+	l := &LogLevels{base: base}
+	l.state.Store(&logLevelState{level: base})
+	return l
+}
+
+// SetLevel changes the level. With a duration the level reverts to the starting one after it.
+func (l *LogLevels) SetLevel(level string, d time.Duration) error {
+	parsed, ok := logLevelNames[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	l.update(func(s *logLevelState) {
+		s.level, s.until = parsed, time.Time{}
+		if d > 0 {
+			s.until = time.Now().Add(d)
+		}
+	})
+	return nil
+}
+
+// EnableDebug logs the entries whose field is value at debug level, for d or, without a
+// duration, until disabled. Phone numbers match whatever their formatting.
+func (l *LogLevels) EnableDebug(field, value string, d time.Duration) error {
+	if field != LogFieldComponent && field != LogFieldPhoneNumber {
+		return fmt.Errorf("debug logs can't be enabled by %q, only by %s or %s", field, LogFieldComponent, LogFieldPhoneNumber)
+	}
+	if value == "" {
+		return fmt.Errorf("%s is empty", field)
+	}
+	target := DebugTarget{Field: field, Value: value}
+	if d > 0 {
+		target.Until = time.Now().Add(d)
+	}
+	l.update(func(s *logLevelState) {
+		s.targets = append(removeTarget(s.targets, field, value), target)
+	})
+	return nil
+}
+
+// DisableDebug removes a debug target
+func (l *LogLevels) DisableDebug(field, value string) {
+	l.update(func(s *logLevelState) {
+		s.targets = removeTarget(s.targets, field, value)
+	})
+}
+
+// State returns the level and the debug targets in force
+func (l *LogLevels) State() LogLevelState {
+	s := l.current()
+	return LogLevelState{
+		Level:   s.level.String(),
+		Until:   s.until,
+		Targets: append([]DebugTarget(nil), s.targets...),
+	}
+}
+
+// current returns the state, dropping what has expired
+func (l *LogLevels) current() *logLevelState {
+	s := l.state.Load()
+	if s.expires.IsZero() || time.Now().Before(s.expires) {
+		return s
+	}
+	l.update(func(*logLevelState) {})
+	return l.state.Load()
+}
+
+// update applies change to a copy of the state, drops what has expired and stores it
+func (l *LogLevels) update(change func(s *logLevelState)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.state.Load()
+	s := &logLevelState{level: old.level, until: old.until, targets: append([]DebugTarget(nil), old.targets...)}
+	change(s)
+
+	now := time.Now()
+	if !s.until.IsZero() && !now.Before(s.until) {
+		s.level, s.until = l.base, time.Time{}
+	}
+	s.expires = s.until
+	targets := s.targets[:0]
+	for _, target := range s.targets {
+		if target.Until.IsZero() {
+			targets = append(targets, target)
+			continue
+		}
+		if now.Before(target.Until) {
+			targets = append(targets, target)
+			if s.expires.IsZero() || target.Until.Before(s.expires) {
+				s.expires = target.Until
+			}
+		}
+	}
+	s.targets = targets
+	l.state.Store(s)
+}
+
+// removeTarget returns targets without the one for field and value
+func removeTarget(targets []DebugTarget, field, value string) []DebugTarget {
+	kept := targets[:0]
+	for _, target := range targets {
+		if target.Field != field || !targetMatches(target, value) {
+			kept = append(kept, target)
+		}
+	}
+	return kept
+}
+
+// matches reports whether one of fields is a debug target
+func (s *logLevelState) matches(fields []zapcore.Field) bool {
+	for _, field := range fields {
+		if field.Type != zapcore.StringType {
+			continue
+		}
+		for _, target := range s.targets {
+			if field.Key == target.Field && targetMatches(target, field.String) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// targetMatches compares a target's value with a field's, phone numbers by their digits
+func targetMatches(target DebugTarget, value string) bool {
+	if target.Field == LogFieldPhoneNumber {
+		return normalizeDigits(target.Value) == normalizeDigits(value)
+	}
+	return target.Value == value
+}
+
+// leveledCore writes the entries at the level of its LogLevels or matching a debug target
+type leveledCore struct {
+	zapcore.Core
+	levels *LogLevels
+	// fields are those added with With, matched against the debug targets too
+	fields []zapcore.Field
+}
+
+// Enabled reports whether entries of level may be written. Below the level they may while
+// debug targets are in force, and Write decides from their fields.
+func (c *leveledCore) Enabled(level zapcore.Level) bool {
+	s := c.levels.current()
+	return level >= s.level || len(s.targets) > 0
+}
+
+// With adds fields to the entries of the returned core
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &leveledCore{
+		Core:   c.Core.With(fields),
+		levels: c.levels,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// Check passes entries at the level on to the wrapped core, and adds itself to those below
+// while there are debug targets, so Write can match their fields
+func (c *leveledCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	s := c.levels.current()
+	if entry.Level >= s.level {
+		return c.Core.Check(entry, checked)
+	}
+	if len(s.targets) > 0 {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes an entry at the level, or below it when it matches a debug target
+func (c *leveledCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	s := c.levels.current()
+	if entry.Level < s.level && !s.matches(c.fields) && !s.matches(fields) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
@@ -3,10 +3,13 @@ package utils
 
 import (
 	"os"
-	// "time"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger defines the interface for logging
@@ -23,8 +26,36 @@ type zapLogger struct {
 	logger *zap.SugaredLogger
 }
 
+// LogOptions configures a logger. The zero value writes JSON to stdout at the LOG_LEVEL level
+// without sampling.
+type LogOptions struct {
+	// Levels sets the level and debug targets; nil starts at LOG_LEVEL
+	Levels *LogLevels
+	// Encoding is "json" or "console"; empty means json
+	Encoding string
+	// File, when set, receives the logs instead of stdout. It is rotated once it reaches
+	// FileMaxSizeMB, keeping FileMaxBackups old files (0 keeps all) for FileMaxAgeDays (0
+	// keeps them however old), gzipped with FileCompress.
+	File           string
+	FileMaxSizeMB  int
+	FileMaxBackups int
+	FileMaxAgeDays int
+	FileCompress   bool
+	// SampleInitial info entries with the same message are written each second, then every
+	// SampleThereafter-th (0: none). Debug targets and entries at warn and above are never
+	// sampled. 0 disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+}
+
+// logEntriesDroppedTotal counts the info entries sampling dropped
+var logEntriesDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "whatsapp_log_entries_dropped_total",
+	Help: "Info log entries dropped by sampling.",
+})
+
 // NewLogger creates a new logger
-func NewLogger() Logger {
+func NewLogger(options LogOptions) Logger {
 	// Create zap logger config
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "ts",
@@ -39,34 +70,76 @@ func NewLogger() Logger {
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
+	encoder := zapcore.NewJSONEncoder(encoderConfig)
+	if options.Encoding == "console" {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
 
-	// Determine log level from environment
-	var level zapcore.Level
-	switch os.Getenv("LOG_LEVEL") {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	default:
-		level = zapcore.InfoLevel
+	// Write to stdout, or to a file rotated by size
+	output := zapcore.AddSync(os.Stdout)
+	if options.File != "" {
+		output = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   options.File,
+			MaxSize:    options.FileMaxSizeMB,
+			MaxBackups: options.FileMaxBackups,
+			MaxAge:     options.FileMaxAgeDays,
+			Compress:   options.FileCompress,
+		})
 	}
 
-	// Create core
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(os.Stdout),
-		level,
-	)
+	// Create core; levels filters what it writes and repeated info entries are sampled
+	var core zapcore.Core = zapcore.NewCore(encoder, output, zapcore.DebugLevel)
+	if options.SampleInitial > 0 {
+		core = &infoSampler{
+			Core: core,
+			sampled: zapcore.NewSamplerWithOptions(core, time.Second, options.SampleInitial, options.SampleThereafter,
+				zapcore.SamplerHook(func(_ zapcore.Entry, decision zapcore.SamplingDecision) {
+					if decision&zapcore.LogDropped != 0 {
+						logEntriesDroppedTotal.Inc()
+					}
+				})),
+		}
+	}
+	levels := options.Levels
+	if levels == nil {
+		levels = NewLogLevels(os.Getenv("LOG_LEVEL"))
+	}
+	core = &leveledCore{Core: core, levels: levels}
 
 	// Create logger
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 	return &zapLogger{logger: logger.Sugar()}
 }
 
+// infoSampler samples info entries, passing the others through
+type infoSampler struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+// With adds fields to the entries of the returned core
+func (s *infoSampler) With(fields []zapcore.Field) zapcore.Core {
+	return &infoSampler{Core: s.Core.With(fields), sampled: s.sampled.With(fields)}
+}
+
+// Check samples info entries
+func (s *infoSampler) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level == zapcore.InfoLevel {
+		return s.sampled.Check(entry, checked)
+	}
+	return s.Core.Check(entry, checked)
+}
+
+// WithComponent returns a logger adding the component to every entry, so the component's
+// debug logs can be enabled on their own. Loggers other than NewLogger's are returned as is.
+func WithComponent(logger Logger, component string) Logger {
+	if l, ok := logger.(*zapLogger); ok {
+		return &zapLogger{logger: l.logger.With(LogFieldComponent, component)}
+	}
+	return logger
+}
+
 // Debug logs a debug message
 func (l *zapLogger) Debug(msg string, keysAndValues ...interface{}) {
 	l.logger.Debugw(msg, keysAndValues...)
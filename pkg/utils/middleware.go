@@ -2,7 +2,11 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -59,50 +63,80 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RateLimiterMiddleware is a basic rate limiter middleware
-// Note: For production, consider using a more sophisticated rate limiter
-func RateLimiterMiddleware(logger Logger, rps int, burst int) gin.HandlerFunc {
-	// Simple in-memory store for IP-based rate limiting
-	// For production, consider using Redis or another distributed store
-	limiters := make(map[string]time.Time)
-	
+// RateLimitPolicy selects the limit applied to a request. API key limits take
+// precedence over route limits, which take precedence over Default.
+type RateLimitPolicy struct {
+	Default RateLimit
+	// Routes is keyed by the registered route path, e.g. "/webhook"
+	Routes map[string]RateLimit
+	// APIKeys is keyed by the value of the X-API-Key header
+	APIKeys map[string]RateLimit
+}
+
+// unmatchedRoute is the route limiter keys use for requests no route matches, so probing
+// made-up paths shares one bucket instead of getting a fresh one per path
+const unmatchedRoute = "unmatched"
+
+// RateLimiterMiddleware limits requests per route and per caller. Callers are identified by
+// their X-API-Key header when it is one of policy.APIKeys, or else by client IP.
+func RateLimiterMiddleware(limiter RateLimiter, policy RateLimitPolicy, logger Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		
-		// Check if IP is in the limiter map
-		lastReq, exists := limiters[ip]
-		now := time.Now()
-		
-		// If IP exists and request is too soon
-		if exists && now.Sub(lastReq) < time.Second/time.Duration(rps) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		limit, hasLimit := policy.Default, policy.Default.RPS > 0
+		if routeLimit, ok := policy.Routes[route]; ok {
+			limit, hasLimit = routeLimit, true
+		}
+
+		// Only configured keys get a bucket of their own, or rotating made-up keys would get
+		// past the per-IP limit
+		identity := "ip:" + c.ClientIP()
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			if keyLimit, ok := policy.APIKeys[apiKey]; ok {
+				identity = "key:" + HashAPIKey(apiKey)
+				limit, hasLimit = keyLimit, true
+			}
+		}
+
+		if !hasLimit {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), route+":"+identity, limit)
+		if err != nil {
+			// Fail open: rate limiting must not take the service down
+			logger.Error("Rate limiter failed", "error", err, "path", route)
+			c.Next()
+			return
+		}
+
+		if !allowed {
 			logger.Warn("Rate limit exceeded",
-				"ip", ip,
-				"path", c.Request.URL.Path,
+				"ip", c.ClientIP(),
+				"path", route,
 			)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "Rate limit exceeded. Please try again later.",
 			})
 			c.Abort()
 			return
 		}
-		
-		// Update last request time
-		limiters[ip] = now
-		
-		// Clean up old entries periodically (simple garbage collection)
-		// For production, implement a proper cleanup mechanism
-		if len(limiters) > 10000 {
-			for k, v := range limiters {
-				if now.Sub(v) > time.Minute {
-					delete(limiters, k)
-				}
-			}
-		}
-		
+
 		c.Next()
 	}
 }
 
+// HashAPIKey identifies an API key in limiter keys without exposing it in the store
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:16])
+}
+
 // AuthMiddleware is a placeholder for authentication middleware
 // Replace with your actual authentication logic
 func AuthMiddleware(logger Logger) gin.HandlerFunc {
@@ -0,0 +1,59 @@
+// pkg/utils/phone_hasher.go
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// PhoneNumberHasher pseudonymizes phone numbers before they leave the OLTP store
+type PhoneNumberHasher interface {
+	Hash(phoneNumber string) string
+}
+
+// hmacPhoneNumberHasher implements PhoneNumberHasher using keyed HMAC-SHA256
+type hmacPhoneNumberHasher struct {
+	key []byte
+}
+
+// NewHMACPhoneNumberHasher creates a hasher that replaces phone numbers with a keyed
+// HMAC of their digits, so the same number always maps to the same pseudonym
+func NewHMACPhoneNumberHasher(key string) PhoneNumberHasher {
+	return &hmacPhoneNumberHasher{key: []byte(key)}
+}
+
+// Hash returns the pseudonym of a phone number
+func (h *hmacPhoneNumberHasher) Hash(phoneNumber string) string {
+	if phoneNumber == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(normalizeDigits(phoneNumber)))
+	return "h:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// plainPhoneNumberHasher implements PhoneNumberHasher without pseudonymization
+type plainPhoneNumberHasher struct{}
+
+// NewPlainPhoneNumberHasher creates a hasher that returns phone numbers unchanged
+func NewPlainPhoneNumberHasher() PhoneNumberHasher {
+	return plainPhoneNumberHasher{}
+}
+
+// Hash returns the phone number unchanged
+func (plainPhoneNumberHasher) Hash(phoneNumber string) string {
+	return phoneNumber
+}
+
+// normalizeDigits strips the WhatsApp prefix and any non-digit characters
+func normalizeDigits(phoneNumber string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.TrimPrefix(phoneNumber, "whatsapp:"))
+}
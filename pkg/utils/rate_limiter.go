@@ -0,0 +1,249 @@
+// pkg/utils/rate_limiter.go
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimit is a token bucket refilled at RPS tokens per second holding at most Burst tokens
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+// ParseRateLimit parses a limit written as "rps:burst" (burst defaults to rps)
+func ParseRateLimit(value string) (RateLimit, error) {
+	rpsPart, burstPart, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+	rps, err := strconv.ParseFloat(rpsPart, 64)
+	if err != nil || rps <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: rps must be a positive number", value)
+	}
+
+	burst := int(math.Ceil(rps))
+	if hasBurst {
+		burst, err = strconv.Atoi(burstPart)
+		if err != nil || burst <= 0 {
+			return RateLimit{}, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", value)
+		}
+	}
+
+	return RateLimit{RPS: rps, Burst: burst}, nil
+}
+
+// ParseRateLimits parses a map of "rps:burst" values
+func ParseRateLimits(values map[string]string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit, len(values))
+	for key, value := range values {
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		limits[key] = limit
+	}
+	return limits, nil
+}
+
+// RateLimiter takes one token from the bucket identified by key
+type RateLimiter interface {
+	// Allow reports whether the request may proceed and, if not, how long to wait
+	Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+// bucket is an in-memory token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// memoryRateLimiter keeps token buckets in process memory
+type memoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryRateLimiter creates a rate limiter local to this process
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the in-memory bucket
+func (l *memoryRateLimiter) Allow(_ context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.RPS)
+	b.last = now
+
+	// Drop buckets that have been full for a while so the map doesn't grow unbounded
+	if len(l.buckets) > 10000 {
+		for k, other := range l.buckets {
+			if now.Sub(other.last) > time.Minute {
+				delete(l.buckets, k)
+			}
+		}
+	}
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / limit.RPS * float64(time.Second)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// tokenBucketScript atomically refills and takes from a bucket stored as a Redis hash.
+// It returns {allowed, retry_after_ms}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, retry}
+`)
+
+// redisRateLimiter shares token buckets between replicas through Redis
+type redisRateLimiter struct {
+	client   redis.UniversalClient
+	fallback RateLimiter
+	logger   Logger
+}
+
+// NewRedisRateLimiter creates a Redis-backed rate limiter. When Redis is unreachable,
+// requests are limited by fallback instead, so each replica enforces the limit on its own.
+func NewRedisRateLimiter(client redis.UniversalClient, fallback RateLimiter, logger Logger) RateLimiter {
+	return &redisRateLimiter{
+		client:   client,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Allow takes a token from the shared bucket, falling back to the local limiter on Redis errors
+func (l *redisRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	result, err := tokenBucketScript.Run(ctx, l.client, []string{"ratelimit:" + key},
+		limit.RPS, limit.Burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		l.logger.Warn("Redis rate limiter unavailable, using in-memory fallback", "error", err)
+		return l.fallback.Allow(ctx, key, limit)
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// WindowCounter counts events per key in fixed windows
+type WindowCounter interface {
+	// Incr adds n to the count of key, which is dropped at expireAt, and returns the new count
+	Incr(ctx context.Context, key string, n int64, expireAt time.Time) (int64, error)
+}
+
+// windowCount is an in-memory count and the time it is dropped
+type windowCount struct {
+	count    int64
+	expireAt time.Time
+}
+
+// memoryWindowCounter keeps counts in process memory
+type memoryWindowCounter struct {
+	mu     sync.Mutex
+	counts map[string]*windowCount
+	now    func() time.Time
+}
+
+// NewMemoryWindowCounter creates a window counter local to this process
+func NewMemoryWindowCounter() WindowCounter {
+	return &memoryWindowCounter{
+		counts: make(map[string]*windowCount),
+		now:    time.Now,
+	}
+}
+
+// Incr adds n to the in-memory count
+func (c *memoryWindowCounter) Incr(_ context.Context, key string, n int64, expireAt time.Time) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	w, exists := c.counts[key]
+	if !exists || !now.Before(w.expireAt) {
+		w = &windowCount{expireAt: expireAt}
+		c.counts[key] = w
+	}
+	w.count += n
+
+	// Drop expired counts so the map doesn't grow unbounded
+	if len(c.counts) > 10000 {
+		for k, other := range c.counts {
+			if !now.Before(other.expireAt) {
+				delete(c.counts, k)
+			}
+		}
+	}
+	return w.count, nil
+}
+
+// redisWindowCounter shares counts between replicas through Redis
+type redisWindowCounter struct {
+	client   redis.UniversalClient
+	fallback WindowCounter
+	logger   Logger
+}
+
+// NewRedisWindowCounter creates a Redis-backed window counter. When Redis is unreachable,
+// events are counted by fallback instead, so each replica counts on its own.
+func NewRedisWindowCounter(client redis.UniversalClient, fallback WindowCounter, logger Logger) WindowCounter {
+	return &redisWindowCounter{
+		client:   client,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Incr adds n to the shared count, falling back to the local counter on Redis errors
+func (c *redisWindowCounter) Incr(ctx context.Context, key string, n int64, expireAt time.Time) (int64, error) {
+	pipe := c.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, "counter:"+key, n)
+	pipe.ExpireAt(ctx, "counter:"+key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Warn("Redis window counter unavailable, using in-memory fallback", "error", err)
+		return c.fallback.Incr(ctx, key, n, expireAt)
+	}
+	return incr.Val(), nil
+}
@@ -0,0 +1,60 @@
+// pkg/utils/retry.go
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Backoff bounds how often and how long an operation is retried: the wait starts at Initial
+// and doubles after every failed attempt up to Max
+type Backoff struct {
+	// Attempts is the total number of tries; values below 1 mean a single try
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+	// Jitter spreads each wait randomly by up to this fraction either way (0 to 1), so
+	// clients failing together do not retry together
+	Jitter float64
+}
+
+// Delay returns the wait after the given failed attempt, counting from 1
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	if b.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Retry calls fn until it succeeds, the attempts run out or ctx is done, logging each failure
+// under name. It returns the last error of fn.
+func Retry(ctx context.Context, b Backoff, logger Logger, name string, fn func(context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= b.Attempts {
+			return fmt.Errorf("%s: gave up after %d attempts: %w", name, attempt, err)
+		}
+
+		delay := b.Delay(attempt)
+		logger.Warn("Waiting for dependency", "dependency", name, "attempt", attempt, "retry_in", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: %w", name, err)
+		case <-timer.C:
+		}
+	}
+}
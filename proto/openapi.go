@@ -0,0 +1,9 @@
+package proto
+
+import _ "embed"
+
+// OpenAPISpec is the OpenAPI (Swagger 2.0) document for the REST gateway,
+// generated by protoc-gen-openapiv2 from whatapp_gateway.yaml
+//
+//go:embed whatapp.swagger.json
+var OpenAPISpec []byte
@@ -19,9 +19,56 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	WhatsAppService_SendTemplateMessage_FullMethodName = "/whatsapp.WhatsAppService/SendTemplateMessage"
-	WhatsAppService_GetMessage_FullMethodName          = "/whatsapp.WhatsAppService/GetMessage"
-	WhatsAppService_ListMessages_FullMethodName        = "/whatsapp.WhatsAppService/ListMessages"
+	WhatsAppService_SendTemplateMessage_FullMethodName      = "/whatsapp.WhatsAppService/SendTemplateMessage"
+	WhatsAppService_SendCTAURLMessage_FullMethodName        = "/whatsapp.WhatsAppService/SendCTAURLMessage"
+	WhatsAppService_SendProductMessage_FullMethodName       = "/whatsapp.WhatsAppService/SendProductMessage"
+	WhatsAppService_SendProductListMessage_FullMethodName   = "/whatsapp.WhatsAppService/SendProductListMessage"
+	WhatsAppService_GetMessage_FullMethodName               = "/whatsapp.WhatsAppService/GetMessage"
+	WhatsAppService_ListMessages_FullMethodName             = "/whatsapp.WhatsAppService/ListMessages"
+	WhatsAppService_GetMessageByExternalID_FullMethodName   = "/whatsapp.WhatsAppService/GetMessageByExternalID"
+	WhatsAppService_GetMessages_FullMethodName              = "/whatsapp.WhatsAppService/GetMessages"
+	WhatsAppService_GetMessagesByExternalIDs_FullMethodName = "/whatsapp.WhatsAppService/GetMessagesByExternalIDs"
+	WhatsAppService_GetMessagesByOrderID_FullMethodName     = "/whatsapp.WhatsAppService/GetMessagesByOrderID"
+	WhatsAppService_ExportMessages_FullMethodName           = "/whatsapp.WhatsAppService/ExportMessages"
+	WhatsAppService_GetServiceInfo_FullMethodName           = "/whatsapp.WhatsAppService/GetServiceInfo"
+	WhatsAppService_EraseCustomerData_FullMethodName        = "/whatsapp.WhatsAppService/EraseCustomerData"
+	WhatsAppService_ExportCustomerData_FullMethodName       = "/whatsapp.WhatsAppService/ExportCustomerData"
+	WhatsAppService_GetMessageStats_FullMethodName          = "/whatsapp.WhatsAppService/GetMessageStats"
+	WhatsAppService_GetDeliveryLatency_FullMethodName       = "/whatsapp.WhatsAppService/GetDeliveryLatency"
+	WhatsAppService_GetQuota_FullMethodName                 = "/whatsapp.WhatsAppService/GetQuota"
+	WhatsAppService_PauseSending_FullMethodName             = "/whatsapp.WhatsAppService/PauseSending"
+	WhatsAppService_ResumeSending_FullMethodName            = "/whatsapp.WhatsAppService/ResumeSending"
+	WhatsAppService_ListSendPauses_FullMethodName           = "/whatsapp.WhatsAppService/ListSendPauses"
+	WhatsAppService_GetInstanceStatus_FullMethodName        = "/whatsapp.WhatsAppService/GetInstanceStatus"
+	WhatsAppService_GetLogLevel_FullMethodName              = "/whatsapp.WhatsAppService/GetLogLevel"
+	WhatsAppService_SetLogLevel_FullMethodName              = "/whatsapp.WhatsAppService/SetLogLevel"
+	WhatsAppService_DisableTemplate_FullMethodName          = "/whatsapp.WhatsAppService/DisableTemplate"
+	WhatsAppService_EnableTemplate_FullMethodName           = "/whatsapp.WhatsAppService/EnableTemplate"
+	WhatsAppService_ListDisabledTemplates_FullMethodName    = "/whatsapp.WhatsAppService/ListDisabledTemplates"
+	WhatsAppService_PreviewTemplateMessage_FullMethodName   = "/whatsapp.WhatsAppService/PreviewTemplateMessage"
+	WhatsAppService_SetCountryRule_FullMethodName           = "/whatsapp.WhatsAppService/SetCountryRule"
+	WhatsAppService_DeleteCountryRule_FullMethodName        = "/whatsapp.WhatsAppService/DeleteCountryRule"
+	WhatsAppService_ListCountryRules_FullMethodName         = "/whatsapp.WhatsAppService/ListCountryRules"
+	WhatsAppService_RetryMessage_FullMethodName             = "/whatsapp.WhatsAppService/RetryMessage"
+	WhatsAppService_GetConversation_FullMethodName          = "/whatsapp.WhatsAppService/GetConversation"
+	WhatsAppService_UpdateHandoff_FullMethodName            = "/whatsapp.WhatsAppService/UpdateHandoff"
+	WhatsAppService_SendTypingIndicator_FullMethodName      = "/whatsapp.WhatsAppService/SendTypingIndicator"
+	WhatsAppService_GetAccountQuality_FullMethodName        = "/whatsapp.WhatsAppService/GetAccountQuality"
+	WhatsAppService_ListAuditEntries_FullMethodName         = "/whatsapp.WhatsAppService/ListAuditEntries"
+	WhatsAppService_DeleteMessage_FullMethodName            = "/whatsapp.WhatsAppService/DeleteMessage"
+	WhatsAppService_SearchMessages_FullMethodName           = "/whatsapp.WhatsAppService/SearchMessages"
+	WhatsAppService_GetProviderCaptures_FullMethodName      = "/whatsapp.WhatsAppService/GetProviderCaptures"
+	WhatsAppService_RecordOptIn_FullMethodName              = "/whatsapp.WhatsAppService/RecordOptIn"
+	WhatsAppService_ListOptIns_FullMethodName               = "/whatsapp.WhatsAppService/ListOptIns"
+	WhatsAppService_UpsertContact_FullMethodName            = "/whatsapp.WhatsAppService/UpsertContact"
+	WhatsAppService_CreateSegment_FullMethodName            = "/whatsapp.WhatsAppService/CreateSegment"
+	WhatsAppService_ListSegments_FullMethodName             = "/whatsapp.WhatsAppService/ListSegments"
+	WhatsAppService_PreviewSegment_FullMethodName           = "/whatsapp.WhatsAppService/PreviewSegment"
+	WhatsAppService_CreateCampaign_FullMethodName           = "/whatsapp.WhatsAppService/CreateCampaign"
+	WhatsAppService_StartCampaign_FullMethodName            = "/whatsapp.WhatsAppService/StartCampaign"
+	WhatsAppService_GetCampaign_FullMethodName              = "/whatsapp.WhatsAppService/GetCampaign"
+	WhatsAppService_GetCampaignReport_FullMethodName        = "/whatsapp.WhatsAppService/GetCampaignReport"
+	WhatsAppService_ImportCampaignAudience_FullMethodName   = "/whatsapp.WhatsAppService/ImportCampaignAudience"
 )
 
 // WhatsAppServiceClient is the client API for WhatsAppService service.
@@ -30,10 +77,115 @@ const (
 type WhatsAppServiceClient interface {
 	// SendTemplateMessage sends a template-based WhatsApp message
 	SendTemplateMessage(ctx context.Context, in *SendTemplateMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error)
+	// SendCTAURLMessage sends an interactive message with a URL button, e.g. an order tracking link
+	SendCTAURLMessage(ctx context.Context, in *SendCTAURLMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error)
+	// SendProductMessage sends one product of the WhatsApp Business catalog
+	SendProductMessage(ctx context.Context, in *SendProductMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error)
+	// SendProductListMessage sends up to 30 catalog products in sections
+	SendProductListMessage(ctx context.Context, in *SendProductListMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error)
 	// GetMessage retrieves a message by ID
 	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*MessageResponse, error)
 	// ListMessages retrieves a list of messages with filtering options
 	ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error)
+	// GetMessageByExternalID retrieves a message by its provider external ID
+	GetMessageByExternalID(ctx context.Context, in *GetMessageByExternalIDRequest, opts ...grpc.CallOption) (*MessageResponse, error)
+	// GetMessages retrieves up to 100 messages by ID in one call, in the order asked for;
+	// IDs not found are left out
+	GetMessages(ctx context.Context, in *GetMessagesRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error)
+	// GetMessagesByExternalIDs retrieves up to 100 messages by provider external ID in one call,
+	// in the order asked for; external IDs not found are left out
+	GetMessagesByExternalIDs(ctx context.Context, in *GetMessagesByExternalIDsRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error)
+	// GetMessagesByOrderID retrieves all messages sent for an order, oldest first
+	GetMessagesByOrderID(ctx context.Context, in *GetMessagesByOrderIDRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error)
+	// ExportMessages streams all messages matching the filters, oldest first
+	ExportMessages(ctx context.Context, in *ExportMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageResponse], error)
+	// GetServiceInfo returns the API version, features and limits of this deployment
+	GetServiceInfo(ctx context.Context, in *GetServiceInfoRequest, opts ...grpc.CallOption) (*ServiceInfoResponse, error)
+	// EraseCustomerData anonymizes (or deletes) every message of a data subject; the request is audited
+	EraseCustomerData(ctx context.Context, in *EraseCustomerDataRequest, opts ...grpc.CallOption) (*EraseCustomerDataResponse, error)
+	// ExportCustomerData streams every message held about a data subject; the request is audited
+	ExportCustomerData(ctx context.Context, in *ExportCustomerDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageResponse], error)
+	// GetMessageStats counts messages created in a time range by day, template and status
+	GetMessageStats(ctx context.Context, in *GetMessageStatsRequest, opts ...grpc.CallOption) (*GetMessageStatsResponse, error)
+	// GetDeliveryLatency reports p50/p95 time from queueing to the sent, delivered and read stages
+	GetDeliveryLatency(ctx context.Context, in *GetDeliveryLatencyRequest, opts ...grpc.CallOption) (*GetDeliveryLatencyResponse, error)
+	// GetQuota reports the caller's monthly message quotas and how much of each remains
+	GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error)
+	// PauseSending stops outbound sends for everything, a tenant or a template; messages stay queued
+	PauseSending(ctx context.Context, in *PauseSendingRequest, opts ...grpc.CallOption) (*SendPause, error)
+	// ResumeSending lifts a pause and re-enqueues the messages it held
+	ResumeSending(ctx context.Context, in *ResumeSendingRequest, opts ...grpc.CallOption) (*ResumeSendingResponse, error)
+	// ListSendPauses returns the pauses in force
+	ListSendPauses(ctx context.Context, in *ListSendPausesRequest, opts ...grpc.CallOption) (*ListSendPausesResponse, error)
+	// GetInstanceStatus returns the replica's consumer partitions, in-flight work, worker
+	// utilization and the singleton workers it runs, to check how load spreads across replicas
+	GetInstanceStatus(ctx context.Context, in *GetInstanceStatusRequest, opts ...grpc.CallOption) (*InstanceStatus, error)
+	// GetLogLevel returns the log level and debug targets of the replica answering
+	GetLogLevel(ctx context.Context, in *GetLogLevelRequest, opts ...grpc.CallOption) (*LogLevel, error)
+	// SetLogLevel changes the log level of the replica answering, or enables or disables debug
+	// logs of one component or phone number on it, without a restart
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*LogLevel, error)
+	// DisableTemplate switches a template off; its sends fail with FAILED_PRECONDITION until enabled
+	DisableTemplate(ctx context.Context, in *DisableTemplateRequest, opts ...grpc.CallOption) (*DisabledTemplate, error)
+	// EnableTemplate switches a disabled template back on
+	EnableTemplate(ctx context.Context, in *EnableTemplateRequest, opts ...grpc.CallOption) (*EnableTemplateResponse, error)
+	// ListDisabledTemplates returns the templates switched off
+	ListDisabledTemplates(ctx context.Context, in *ListDisabledTemplatesRequest, opts ...grpc.CallOption) (*ListDisabledTemplatesResponse, error)
+	// PreviewTemplateMessage renders a template with the parameters of a send, and the request a
+	// send would make, without sending anything
+	PreviewTemplateMessage(ctx context.Context, in *PreviewTemplateMessageRequest, opts ...grpc.CallOption) (*TemplatePreview, error)
+	// SetCountryRule allows or blocks sends to a dialing prefix; blocked sends fail with FAILED_PRECONDITION
+	SetCountryRule(ctx context.Context, in *SetCountryRuleRequest, opts ...grpc.CallOption) (*CountryRule, error)
+	// DeleteCountryRule removes a rule set with SetCountryRule
+	DeleteCountryRule(ctx context.Context, in *DeleteCountryRuleRequest, opts ...grpc.CallOption) (*DeleteCountryRuleResponse, error)
+	// ListCountryRules returns the configured and runtime country rules in force
+	ListCountryRules(ctx context.Context, in *ListCountryRulesRequest, opts ...grpc.CallOption) (*ListCountryRulesResponse, error)
+	// RetryMessage sends a failed, expired or quota_exceeded message again as a new attempt
+	RetryMessage(ctx context.Context, in *RetryMessageRequest, opts ...grpc.CallOption) (*MessageResponse, error)
+	// GetConversation returns the conversation with a customer and its agent handoff state
+	GetConversation(ctx context.Context, in *GetConversationRequest, opts ...grpc.CallOption) (*Conversation, error)
+	// UpdateHandoff records an agent taking or finishing a conversation, or hands it over by hand
+	UpdateHandoff(ctx context.Context, in *UpdateHandoffRequest, opts ...grpc.CallOption) (*Conversation, error)
+	// SendTypingIndicator shows the customer a reply is being written to one of their messages
+	SendTypingIndicator(ctx context.Context, in *SendTypingIndicatorRequest, opts ...grpc.CallOption) (*SendTypingIndicatorResponse, error)
+	// GetAccountQuality returns the quality rating and messaging limit of the tenant's phone
+	// numbers and the account events Meta reported recently
+	GetAccountQuality(ctx context.Context, in *GetAccountQualityRequest, opts ...grpc.CallOption) (*GetAccountQualityResponse, error)
+	// ListAuditEntries returns the tenant's audit log of admin changes and data subject requests
+	ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error)
+	// DeleteMessage hides a message from reads, listings and stats, or with hard_delete removes
+	// its row; hard deletes are restricted to ADMIN_ACTORS. Both are audited.
+	DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error)
+	// SearchMessages finds messages by parameter values and by text in their parameters and
+	// rendered content, newest first
+	SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error)
+	// GetProviderCaptures returns the provider requests and responses of a message's send
+	// attempts, captured with credentials redacted while PROVIDER_CAPTURE is on
+	GetProviderCaptures(ctx context.Context, in *GetProviderCapturesRequest, opts ...grpc.CallOption) (*GetProviderCapturesResponse, error)
+	// RecordOptIn records a contact's opt-in to, or opt-out from, marketing messages with its proof
+	RecordOptIn(ctx context.Context, in *RecordOptInRequest, opts ...grpc.CallOption) (*OptInEvent, error)
+	// ListOptIns returns a contact's opt-in history and whether they are currently opted in
+	ListOptIns(ctx context.Context, in *ListOptInsRequest, opts ...grpc.CallOption) (*ListOptInsResponse, error)
+	// UpsertContact creates or replaces a contact's attributes and tags, which segments filter on
+	UpsertContact(ctx context.Context, in *UpsertContactRequest, opts ...grpc.CallOption) (*Contact, error)
+	// CreateSegment stores a campaign audience: a filter over contacts or a list of phone numbers
+	CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*Segment, error)
+	// ListSegments returns the tenant's segments, newest first
+	ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsResponse, error)
+	// PreviewSegment returns how many contacts a stored segment or an unsaved filter selects now
+	PreviewSegment(ctx context.Context, in *PreviewSegmentRequest, opts ...grpc.CallOption) (*PreviewSegmentResponse, error)
+	// CreateCampaign stores a draft campaign sending a template to a segment
+	CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error)
+	// StartCampaign materializes a draft campaign's audience from its segment and starts sending
+	StartCampaign(ctx context.Context, in *StartCampaignRequest, opts ...grpc.CallOption) (*Campaign, error)
+	// GetCampaign returns a campaign and its progress
+	GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*Campaign, error)
+	// GetCampaignReport compares the delivery and read rates of a campaign's template variants
+	GetCampaignReport(ctx context.Context, in *GetCampaignReportRequest, opts ...grpc.CallOption) (*CampaignReport, error)
+	// ImportCampaignAudience adds the phone numbers and per-row template parameters of a CSV
+	// streamed in chunks to a draft campaign's audience. Re-uploading a file leaves the audience
+	// as it was.
+	ImportCampaignAudience(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse], error)
 }
 
 type whatsAppServiceClient struct {
@@ -54,6 +206,36 @@ func (c *whatsAppServiceClient) SendTemplateMessage(ctx context.Context, in *Sen
 	return out, nil
 }
 
+func (c *whatsAppServiceClient) SendCTAURLMessage(ctx context.Context, in *SendCTAURLMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendTemplateMessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_SendCTAURLMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SendProductMessage(ctx context.Context, in *SendProductMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendTemplateMessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_SendProductMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SendProductListMessage(ctx context.Context, in *SendProductListMessageRequest, opts ...grpc.CallOption) (*SendTemplateMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendTemplateMessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_SendProductListMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *whatsAppServiceClient) GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*MessageResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MessageResponse)
@@ -61,123 +243,1651 @@ func (c *whatsAppServiceClient) GetMessage(ctx context.Context, in *GetMessageRe
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMessagesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetMessageByExternalID(ctx context.Context, in *GetMessageByExternalIDRequest, opts ...grpc.CallOption) (*MessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetMessageByExternalID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetMessages(ctx context.Context, in *GetMessagesRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMessagesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetMessagesByExternalIDs(ctx context.Context, in *GetMessagesByExternalIDsRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMessagesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetMessagesByExternalIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetMessagesByOrderID(ctx context.Context, in *GetMessagesByOrderIDRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMessagesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetMessagesByOrderID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ExportMessages(ctx context.Context, in *ExportMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WhatsAppService_ServiceDesc.Streams[0], WhatsAppService_ExportMessages_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportMessagesRequest, MessageResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ExportMessagesClient = grpc.ServerStreamingClient[MessageResponse]
+
+func (c *whatsAppServiceClient) GetServiceInfo(ctx context.Context, in *GetServiceInfoRequest, opts ...grpc.CallOption) (*ServiceInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServiceInfoResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetServiceInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) EraseCustomerData(ctx context.Context, in *EraseCustomerDataRequest, opts ...grpc.CallOption) (*EraseCustomerDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EraseCustomerDataResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_EraseCustomerData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ExportCustomerData(ctx context.Context, in *ExportCustomerDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WhatsAppService_ServiceDesc.Streams[1], WhatsAppService_ExportCustomerData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportCustomerDataRequest, MessageResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ExportCustomerDataClient = grpc.ServerStreamingClient[MessageResponse]
+
+func (c *whatsAppServiceClient) GetMessageStats(ctx context.Context, in *GetMessageStatsRequest, opts ...grpc.CallOption) (*GetMessageStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMessageStatsResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetMessageStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetDeliveryLatency(ctx context.Context, in *GetDeliveryLatencyRequest, opts ...grpc.CallOption) (*GetDeliveryLatencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeliveryLatencyResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetDeliveryLatency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) PauseSending(ctx context.Context, in *PauseSendingRequest, opts ...grpc.CallOption) (*SendPause, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendPause)
+	err := c.cc.Invoke(ctx, WhatsAppService_PauseSending_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ResumeSending(ctx context.Context, in *ResumeSendingRequest, opts ...grpc.CallOption) (*ResumeSendingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeSendingResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ResumeSending_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListSendPauses(ctx context.Context, in *ListSendPausesRequest, opts ...grpc.CallOption) (*ListSendPausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSendPausesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListSendPauses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetInstanceStatus(ctx context.Context, in *GetInstanceStatusRequest, opts ...grpc.CallOption) (*InstanceStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstanceStatus)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetInstanceStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetLogLevel(ctx context.Context, in *GetLogLevelRequest, opts ...grpc.CallOption) (*LogLevel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogLevel)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*LogLevel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogLevel)
+	err := c.cc.Invoke(ctx, WhatsAppService_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) DisableTemplate(ctx context.Context, in *DisableTemplateRequest, opts ...grpc.CallOption) (*DisabledTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisabledTemplate)
+	err := c.cc.Invoke(ctx, WhatsAppService_DisableTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) EnableTemplate(ctx context.Context, in *EnableTemplateRequest, opts ...grpc.CallOption) (*EnableTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnableTemplateResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_EnableTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListDisabledTemplates(ctx context.Context, in *ListDisabledTemplatesRequest, opts ...grpc.CallOption) (*ListDisabledTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDisabledTemplatesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListDisabledTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) PreviewTemplateMessage(ctx context.Context, in *PreviewTemplateMessageRequest, opts ...grpc.CallOption) (*TemplatePreview, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TemplatePreview)
+	err := c.cc.Invoke(ctx, WhatsAppService_PreviewTemplateMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SetCountryRule(ctx context.Context, in *SetCountryRuleRequest, opts ...grpc.CallOption) (*CountryRule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountryRule)
+	err := c.cc.Invoke(ctx, WhatsAppService_SetCountryRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) DeleteCountryRule(ctx context.Context, in *DeleteCountryRuleRequest, opts ...grpc.CallOption) (*DeleteCountryRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCountryRuleResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_DeleteCountryRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListCountryRules(ctx context.Context, in *ListCountryRulesRequest, opts ...grpc.CallOption) (*ListCountryRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCountryRulesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListCountryRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) RetryMessage(ctx context.Context, in *RetryMessageRequest, opts ...grpc.CallOption) (*MessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_RetryMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetConversation(ctx context.Context, in *GetConversationRequest, opts ...grpc.CallOption) (*Conversation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Conversation)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) UpdateHandoff(ctx context.Context, in *UpdateHandoffRequest, opts ...grpc.CallOption) (*Conversation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Conversation)
+	err := c.cc.Invoke(ctx, WhatsAppService_UpdateHandoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SendTypingIndicator(ctx context.Context, in *SendTypingIndicatorRequest, opts ...grpc.CallOption) (*SendTypingIndicatorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendTypingIndicatorResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_SendTypingIndicator_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetAccountQuality(ctx context.Context, in *GetAccountQualityRequest, opts ...grpc.CallOption) (*GetAccountQualityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountQualityResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetAccountQuality_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditEntriesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListAuditEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMessageResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_DeleteMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchMessagesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_SearchMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetProviderCaptures(ctx context.Context, in *GetProviderCapturesRequest, opts ...grpc.CallOption) (*GetProviderCapturesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProviderCapturesResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetProviderCaptures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) RecordOptIn(ctx context.Context, in *RecordOptInRequest, opts ...grpc.CallOption) (*OptInEvent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OptInEvent)
+	err := c.cc.Invoke(ctx, WhatsAppService_RecordOptIn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListOptIns(ctx context.Context, in *ListOptInsRequest, opts ...grpc.CallOption) (*ListOptInsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOptInsResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListOptIns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) UpsertContact(ctx context.Context, in *UpsertContactRequest, opts ...grpc.CallOption) (*Contact, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Contact)
+	err := c.cc.Invoke(ctx, WhatsAppService_UpsertContact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*Segment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Segment)
+	err := c.cc.Invoke(ctx, WhatsAppService_CreateSegment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSegmentsResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_ListSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) PreviewSegment(ctx context.Context, in *PreviewSegmentRequest, opts ...grpc.CallOption) (*PreviewSegmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreviewSegmentResponse)
+	err := c.cc.Invoke(ctx, WhatsAppService_PreviewSegment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Campaign)
+	err := c.cc.Invoke(ctx, WhatsAppService_CreateCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) StartCampaign(ctx context.Context, in *StartCampaignRequest, opts ...grpc.CallOption) (*Campaign, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Campaign)
+	err := c.cc.Invoke(ctx, WhatsAppService_StartCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*Campaign, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Campaign)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) GetCampaignReport(ctx context.Context, in *GetCampaignReportRequest, opts ...grpc.CallOption) (*CampaignReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CampaignReport)
+	err := c.cc.Invoke(ctx, WhatsAppService_GetCampaignReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whatsAppServiceClient) ImportCampaignAudience(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WhatsAppService_ServiceDesc.Streams[2], WhatsAppService_ImportCampaignAudience_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ImportCampaignAudienceClient = grpc.ClientStreamingClient[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]
+
+// WhatsAppServiceServer is the server API for WhatsAppService service.
+// All implementations must embed UnimplementedWhatsAppServiceServer
+// for forward compatibility.
+type WhatsAppServiceServer interface {
+	// SendTemplateMessage sends a template-based WhatsApp message
+	SendTemplateMessage(context.Context, *SendTemplateMessageRequest) (*SendTemplateMessageResponse, error)
+	// SendCTAURLMessage sends an interactive message with a URL button, e.g. an order tracking link
+	SendCTAURLMessage(context.Context, *SendCTAURLMessageRequest) (*SendTemplateMessageResponse, error)
+	// SendProductMessage sends one product of the WhatsApp Business catalog
+	SendProductMessage(context.Context, *SendProductMessageRequest) (*SendTemplateMessageResponse, error)
+	// SendProductListMessage sends up to 30 catalog products in sections
+	SendProductListMessage(context.Context, *SendProductListMessageRequest) (*SendTemplateMessageResponse, error)
+	// GetMessage retrieves a message by ID
+	GetMessage(context.Context, *GetMessageRequest) (*MessageResponse, error)
+	// ListMessages retrieves a list of messages with filtering options
+	ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error)
+	// GetMessageByExternalID retrieves a message by its provider external ID
+	GetMessageByExternalID(context.Context, *GetMessageByExternalIDRequest) (*MessageResponse, error)
+	// GetMessages retrieves up to 100 messages by ID in one call, in the order asked for;
+	// IDs not found are left out
+	GetMessages(context.Context, *GetMessagesRequest) (*GetMessagesResponse, error)
+	// GetMessagesByExternalIDs retrieves up to 100 messages by provider external ID in one call,
+	// in the order asked for; external IDs not found are left out
+	GetMessagesByExternalIDs(context.Context, *GetMessagesByExternalIDsRequest) (*GetMessagesResponse, error)
+	// GetMessagesByOrderID retrieves all messages sent for an order, oldest first
+	GetMessagesByOrderID(context.Context, *GetMessagesByOrderIDRequest) (*ListMessagesResponse, error)
+	// ExportMessages streams all messages matching the filters, oldest first
+	ExportMessages(*ExportMessagesRequest, grpc.ServerStreamingServer[MessageResponse]) error
+	// GetServiceInfo returns the API version, features and limits of this deployment
+	GetServiceInfo(context.Context, *GetServiceInfoRequest) (*ServiceInfoResponse, error)
+	// EraseCustomerData anonymizes (or deletes) every message of a data subject; the request is audited
+	EraseCustomerData(context.Context, *EraseCustomerDataRequest) (*EraseCustomerDataResponse, error)
+	// ExportCustomerData streams every message held about a data subject; the request is audited
+	ExportCustomerData(*ExportCustomerDataRequest, grpc.ServerStreamingServer[MessageResponse]) error
+	// GetMessageStats counts messages created in a time range by day, template and status
+	GetMessageStats(context.Context, *GetMessageStatsRequest) (*GetMessageStatsResponse, error)
+	// GetDeliveryLatency reports p50/p95 time from queueing to the sent, delivered and read stages
+	GetDeliveryLatency(context.Context, *GetDeliveryLatencyRequest) (*GetDeliveryLatencyResponse, error)
+	// GetQuota reports the caller's monthly message quotas and how much of each remains
+	GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error)
+	// PauseSending stops outbound sends for everything, a tenant or a template; messages stay queued
+	PauseSending(context.Context, *PauseSendingRequest) (*SendPause, error)
+	// ResumeSending lifts a pause and re-enqueues the messages it held
+	ResumeSending(context.Context, *ResumeSendingRequest) (*ResumeSendingResponse, error)
+	// ListSendPauses returns the pauses in force
+	ListSendPauses(context.Context, *ListSendPausesRequest) (*ListSendPausesResponse, error)
+	// GetInstanceStatus returns the replica's consumer partitions, in-flight work, worker
+	// utilization and the singleton workers it runs, to check how load spreads across replicas
+	GetInstanceStatus(context.Context, *GetInstanceStatusRequest) (*InstanceStatus, error)
+	// GetLogLevel returns the log level and debug targets of the replica answering
+	GetLogLevel(context.Context, *GetLogLevelRequest) (*LogLevel, error)
+	// SetLogLevel changes the log level of the replica answering, or enables or disables debug
+	// logs of one component or phone number on it, without a restart
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*LogLevel, error)
+	// DisableTemplate switches a template off; its sends fail with FAILED_PRECONDITION until enabled
+	DisableTemplate(context.Context, *DisableTemplateRequest) (*DisabledTemplate, error)
+	// EnableTemplate switches a disabled template back on
+	EnableTemplate(context.Context, *EnableTemplateRequest) (*EnableTemplateResponse, error)
+	// ListDisabledTemplates returns the templates switched off
+	ListDisabledTemplates(context.Context, *ListDisabledTemplatesRequest) (*ListDisabledTemplatesResponse, error)
+	// PreviewTemplateMessage renders a template with the parameters of a send, and the request a
+	// send would make, without sending anything
+	PreviewTemplateMessage(context.Context, *PreviewTemplateMessageRequest) (*TemplatePreview, error)
+	// SetCountryRule allows or blocks sends to a dialing prefix; blocked sends fail with FAILED_PRECONDITION
+	SetCountryRule(context.Context, *SetCountryRuleRequest) (*CountryRule, error)
+	// DeleteCountryRule removes a rule set with SetCountryRule
+	DeleteCountryRule(context.Context, *DeleteCountryRuleRequest) (*DeleteCountryRuleResponse, error)
+	// ListCountryRules returns the configured and runtime country rules in force
+	ListCountryRules(context.Context, *ListCountryRulesRequest) (*ListCountryRulesResponse, error)
+	// RetryMessage sends a failed, expired or quota_exceeded message again as a new attempt
+	RetryMessage(context.Context, *RetryMessageRequest) (*MessageResponse, error)
+	// GetConversation returns the conversation with a customer and its agent handoff state
+	GetConversation(context.Context, *GetConversationRequest) (*Conversation, error)
+	// UpdateHandoff records an agent taking or finishing a conversation, or hands it over by hand
+	UpdateHandoff(context.Context, *UpdateHandoffRequest) (*Conversation, error)
+	// SendTypingIndicator shows the customer a reply is being written to one of their messages
+	SendTypingIndicator(context.Context, *SendTypingIndicatorRequest) (*SendTypingIndicatorResponse, error)
+	// GetAccountQuality returns the quality rating and messaging limit of the tenant's phone
+	// numbers and the account events Meta reported recently
+	GetAccountQuality(context.Context, *GetAccountQualityRequest) (*GetAccountQualityResponse, error)
+	// ListAuditEntries returns the tenant's audit log of admin changes and data subject requests
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+	// DeleteMessage hides a message from reads, listings and stats, or with hard_delete removes
+	// its row; hard deletes are restricted to ADMIN_ACTORS. Both are audited.
+	DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error)
+	// SearchMessages finds messages by parameter values and by text in their parameters and
+	// rendered content, newest first
+	SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error)
+	// GetProviderCaptures returns the provider requests and responses of a message's send
+	// attempts, captured with credentials redacted while PROVIDER_CAPTURE is on
+	GetProviderCaptures(context.Context, *GetProviderCapturesRequest) (*GetProviderCapturesResponse, error)
+	// RecordOptIn records a contact's opt-in to, or opt-out from, marketing messages with its proof
+	RecordOptIn(context.Context, *RecordOptInRequest) (*OptInEvent, error)
+	// ListOptIns returns a contact's opt-in history and whether they are currently opted in
+	ListOptIns(context.Context, *ListOptInsRequest) (*ListOptInsResponse, error)
+	// UpsertContact creates or replaces a contact's attributes and tags, which segments filter on
+	UpsertContact(context.Context, *UpsertContactRequest) (*Contact, error)
+	// CreateSegment stores a campaign audience: a filter over contacts or a list of phone numbers
+	CreateSegment(context.Context, *CreateSegmentRequest) (*Segment, error)
+	// ListSegments returns the tenant's segments, newest first
+	ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsResponse, error)
+	// PreviewSegment returns how many contacts a stored segment or an unsaved filter selects now
+	PreviewSegment(context.Context, *PreviewSegmentRequest) (*PreviewSegmentResponse, error)
+	// CreateCampaign stores a draft campaign sending a template to a segment
+	CreateCampaign(context.Context, *CreateCampaignRequest) (*Campaign, error)
+	// StartCampaign materializes a draft campaign's audience from its segment and starts sending
+	StartCampaign(context.Context, *StartCampaignRequest) (*Campaign, error)
+	// GetCampaign returns a campaign and its progress
+	GetCampaign(context.Context, *GetCampaignRequest) (*Campaign, error)
+	// GetCampaignReport compares the delivery and read rates of a campaign's template variants
+	GetCampaignReport(context.Context, *GetCampaignReportRequest) (*CampaignReport, error)
+	// ImportCampaignAudience adds the phone numbers and per-row template parameters of a CSV
+	// streamed in chunks to a draft campaign's audience. Re-uploading a file leaves the audience
+	// as it was.
+	ImportCampaignAudience(grpc.ClientStreamingServer[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]) error
+	mustEmbedUnimplementedWhatsAppServiceServer()
+}
+
+// UnimplementedWhatsAppServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWhatsAppServiceServer struct{}
+
+func (UnimplementedWhatsAppServiceServer) SendTemplateMessage(context.Context, *SendTemplateMessageRequest) (*SendTemplateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTemplateMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SendCTAURLMessage(context.Context, *SendCTAURLMessageRequest) (*SendTemplateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendCTAURLMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SendProductMessage(context.Context, *SendProductMessageRequest) (*SendTemplateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendProductMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SendProductListMessage(context.Context, *SendProductListMessageRequest) (*SendTemplateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendProductListMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessage(context.Context, *GetMessageRequest) (*MessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMessages not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessageByExternalID(context.Context, *GetMessageByExternalIDRequest) (*MessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessageByExternalID not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessages(context.Context, *GetMessagesRequest) (*GetMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessages not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessagesByExternalIDs(context.Context, *GetMessagesByExternalIDsRequest) (*GetMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessagesByExternalIDs not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessagesByOrderID(context.Context, *GetMessagesByOrderIDRequest) (*ListMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessagesByOrderID not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ExportMessages(*ExportMessagesRequest, grpc.ServerStreamingServer[MessageResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportMessages not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetServiceInfo(context.Context, *GetServiceInfoRequest) (*ServiceInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceInfo not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) EraseCustomerData(context.Context, *EraseCustomerDataRequest) (*EraseCustomerDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseCustomerData not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ExportCustomerData(*ExportCustomerDataRequest, grpc.ServerStreamingServer[MessageResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportCustomerData not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetMessageStats(context.Context, *GetMessageStatsRequest) (*GetMessageStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessageStats not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetDeliveryLatency(context.Context, *GetDeliveryLatencyRequest) (*GetDeliveryLatencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeliveryLatency not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuota not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) PauseSending(context.Context, *PauseSendingRequest) (*SendPause, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseSending not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ResumeSending(context.Context, *ResumeSendingRequest) (*ResumeSendingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeSending not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListSendPauses(context.Context, *ListSendPausesRequest) (*ListSendPausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSendPauses not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetInstanceStatus(context.Context, *GetInstanceStatusRequest) (*InstanceStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstanceStatus not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetLogLevel(context.Context, *GetLogLevelRequest) (*LogLevel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogLevel not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*LogLevel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) DisableTemplate(context.Context, *DisableTemplateRequest) (*DisabledTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTemplate not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) EnableTemplate(context.Context, *EnableTemplateRequest) (*EnableTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableTemplate not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListDisabledTemplates(context.Context, *ListDisabledTemplatesRequest) (*ListDisabledTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDisabledTemplates not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) PreviewTemplateMessage(context.Context, *PreviewTemplateMessageRequest) (*TemplatePreview, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewTemplateMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SetCountryRule(context.Context, *SetCountryRuleRequest) (*CountryRule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCountryRule not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) DeleteCountryRule(context.Context, *DeleteCountryRuleRequest) (*DeleteCountryRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCountryRule not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListCountryRules(context.Context, *ListCountryRulesRequest) (*ListCountryRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCountryRules not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) RetryMessage(context.Context, *RetryMessageRequest) (*MessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetConversation(context.Context, *GetConversationRequest) (*Conversation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversation not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) UpdateHandoff(context.Context, *UpdateHandoffRequest) (*Conversation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateHandoff not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SendTypingIndicator(context.Context, *SendTypingIndicatorRequest) (*SendTypingIndicatorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTypingIndicator not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetAccountQuality(context.Context, *GetAccountQualityRequest) (*GetAccountQualityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountQuality not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEntries not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessage not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMessages not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetProviderCaptures(context.Context, *GetProviderCapturesRequest) (*GetProviderCapturesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProviderCaptures not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) RecordOptIn(context.Context, *RecordOptInRequest) (*OptInEvent, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordOptIn not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListOptIns(context.Context, *ListOptInsRequest) (*ListOptInsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOptIns not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) UpsertContact(context.Context, *UpsertContactRequest) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertContact not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) CreateSegment(context.Context, *CreateSegmentRequest) (*Segment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSegment not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSegments not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) PreviewSegment(context.Context, *PreviewSegmentRequest) (*PreviewSegmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewSegment not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) CreateCampaign(context.Context, *CreateCampaignRequest) (*Campaign, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCampaign not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) StartCampaign(context.Context, *StartCampaignRequest) (*Campaign, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartCampaign not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetCampaign(context.Context, *GetCampaignRequest) (*Campaign, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaign not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) GetCampaignReport(context.Context, *GetCampaignReportRequest) (*CampaignReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaignReport not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) ImportCampaignAudience(grpc.ClientStreamingServer[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportCampaignAudience not implemented")
+}
+func (UnimplementedWhatsAppServiceServer) mustEmbedUnimplementedWhatsAppServiceServer() {}
+func (UnimplementedWhatsAppServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeWhatsAppServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WhatsAppServiceServer will
+// result in compilation errors.
+type UnsafeWhatsAppServiceServer interface {
+	mustEmbedUnimplementedWhatsAppServiceServer()
+}
+
+func RegisterWhatsAppServiceServer(s grpc.ServiceRegistrar, srv WhatsAppServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWhatsAppServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WhatsAppService_ServiceDesc, srv)
+}
+
+func _WhatsAppService_SendTemplateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTemplateMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SendTemplateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SendTemplateMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SendTemplateMessage(ctx, req.(*SendTemplateMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SendCTAURLMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendCTAURLMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SendCTAURLMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SendCTAURLMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SendCTAURLMessage(ctx, req.(*SendCTAURLMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SendProductMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendProductMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SendProductMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SendProductMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SendProductMessage(ctx, req.(*SendProductMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SendProductListMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendProductListMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SendProductListMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SendProductListMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SendProductListMessage(ctx, req.(*SendProductListMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessage(ctx, req.(*GetMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ListMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListMessages(ctx, req.(*ListMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetMessageByExternalID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageByExternalIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessageByExternalID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessageByExternalID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessageByExternalID(ctx, req.(*GetMessageByExternalIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessages(ctx, req.(*GetMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetMessagesByExternalIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessagesByExternalIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessagesByExternalIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessagesByExternalIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessagesByExternalIDs(ctx, req.(*GetMessagesByExternalIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetMessagesByOrderID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessagesByOrderIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessagesByOrderID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessagesByOrderID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessagesByOrderID(ctx, req.(*GetMessagesByOrderIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ExportMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WhatsAppServiceServer).ExportMessages(m, &grpc.GenericServerStream[ExportMessagesRequest, MessageResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ExportMessagesServer = grpc.ServerStreamingServer[MessageResponse]
+
+func _WhatsAppService_GetServiceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetServiceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetServiceInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetServiceInfo(ctx, req.(*GetServiceInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_EraseCustomerData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseCustomerDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).EraseCustomerData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_EraseCustomerData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).EraseCustomerData(ctx, req.(*EraseCustomerDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ExportCustomerData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportCustomerDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WhatsAppServiceServer).ExportCustomerData(m, &grpc.GenericServerStream[ExportCustomerDataRequest, MessageResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ExportCustomerDataServer = grpc.ServerStreamingServer[MessageResponse]
+
+func _WhatsAppService_GetMessageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetMessageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetMessageStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetMessageStats(ctx, req.(*GetMessageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetDeliveryLatency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeliveryLatencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetDeliveryLatency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetDeliveryLatency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetDeliveryLatency(ctx, req.(*GetDeliveryLatencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetQuota(ctx, req.(*GetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_PauseSending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseSendingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).PauseSending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_PauseSending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).PauseSending(ctx, req.(*PauseSendingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ResumeSending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeSendingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ResumeSending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ResumeSending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ResumeSending(ctx, req.(*ResumeSendingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ListSendPauses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSendPausesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListSendPauses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListSendPauses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListSendPauses(ctx, req.(*ListSendPausesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetInstanceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInstanceStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetInstanceStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetInstanceStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetInstanceStatus(ctx, req.(*GetInstanceStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetLogLevel(ctx, req.(*GetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_DisableTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).DisableTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_DisableTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).DisableTemplate(ctx, req.(*DisableTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_EnableTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).EnableTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_EnableTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).EnableTemplate(ctx, req.(*EnableTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ListDisabledTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDisabledTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListDisabledTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListDisabledTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListDisabledTemplates(ctx, req.(*ListDisabledTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_PreviewTemplateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewTemplateMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).PreviewTemplateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_PreviewTemplateMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).PreviewTemplateMessage(ctx, req.(*PreviewTemplateMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SetCountryRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCountryRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SetCountryRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SetCountryRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SetCountryRule(ctx, req.(*SetCountryRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_DeleteCountryRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCountryRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).DeleteCountryRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_DeleteCountryRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).DeleteCountryRule(ctx, req.(*DeleteCountryRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ListCountryRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCountryRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListCountryRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListCountryRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListCountryRules(ctx, req.(*ListCountryRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_RetryMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).RetryMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_RetryMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).RetryMessage(ctx, req.(*RetryMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetConversation(ctx, req.(*GetConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_UpdateHandoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateHandoffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).UpdateHandoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_UpdateHandoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).UpdateHandoff(ctx, req.(*UpdateHandoffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SendTypingIndicator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTypingIndicatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SendTypingIndicator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SendTypingIndicator_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SendTypingIndicator(ctx, req.(*SendTypingIndicatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_GetAccountQuality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountQualityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetAccountQuality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetAccountQuality_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetAccountQuality(ctx, req.(*GetAccountQualityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_ListAuditEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListAuditEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListAuditEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListAuditEntries(ctx, req.(*ListAuditEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).DeleteMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_DeleteMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).DeleteMessage(ctx, req.(*DeleteMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhatsAppService_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).SearchMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_SearchMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).SearchMessages(ctx, req.(*SearchMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *whatsAppServiceClient) ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListMessagesResponse)
-	err := c.cc.Invoke(ctx, WhatsAppService_ListMessages_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _WhatsAppService_GetProviderCaptures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProviderCapturesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).GetProviderCaptures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_GetProviderCaptures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).GetProviderCaptures(ctx, req.(*GetProviderCapturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// WhatsAppServiceServer is the server API for WhatsAppService service.
-// All implementations must embed UnimplementedWhatsAppServiceServer
-// for forward compatibility.
-type WhatsAppServiceServer interface {
-	// SendTemplateMessage sends a template-based WhatsApp message
-	SendTemplateMessage(context.Context, *SendTemplateMessageRequest) (*SendTemplateMessageResponse, error)
-	// GetMessage retrieves a message by ID
-	GetMessage(context.Context, *GetMessageRequest) (*MessageResponse, error)
-	// ListMessages retrieves a list of messages with filtering options
-	ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error)
-	mustEmbedUnimplementedWhatsAppServiceServer()
+func _WhatsAppService_RecordOptIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordOptInRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).RecordOptIn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_RecordOptIn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).RecordOptIn(ctx, req.(*RecordOptInRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedWhatsAppServiceServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
-// pointer dereference when methods are called.
-type UnimplementedWhatsAppServiceServer struct{}
+func _WhatsAppService_ListOptIns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOptInsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListOptIns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListOptIns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListOptIns(ctx, req.(*ListOptInsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-func (UnimplementedWhatsAppServiceServer) SendTemplateMessage(context.Context, *SendTemplateMessageRequest) (*SendTemplateMessageResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SendTemplateMessage not implemented")
+func _WhatsAppService_UpsertContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).UpsertContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_UpsertContact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).UpsertContact(ctx, req.(*UpsertContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedWhatsAppServiceServer) GetMessage(context.Context, *GetMessageRequest) (*MessageResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetMessage not implemented")
+
+func _WhatsAppService_CreateSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).CreateSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_CreateSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).CreateSegment(ctx, req.(*CreateSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedWhatsAppServiceServer) ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListMessages not implemented")
+
+func _WhatsAppService_ListSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).ListSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_ListSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).ListSegments(ctx, req.(*ListSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedWhatsAppServiceServer) mustEmbedUnimplementedWhatsAppServiceServer() {}
-func (UnimplementedWhatsAppServiceServer) testEmbeddedByValue()                         {}
 
-// UnsafeWhatsAppServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to WhatsAppServiceServer will
-// result in compilation errors.
-type UnsafeWhatsAppServiceServer interface {
-	mustEmbedUnimplementedWhatsAppServiceServer()
+func _WhatsAppService_PreviewSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).PreviewSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_PreviewSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).PreviewSegment(ctx, req.(*PreviewSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterWhatsAppServiceServer(s grpc.ServiceRegistrar, srv WhatsAppServiceServer) {
-	// If the following call pancis, it indicates UnimplementedWhatsAppServiceServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
+func _WhatsAppService_CreateCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	s.RegisterService(&WhatsAppService_ServiceDesc, srv)
+	if interceptor == nil {
+		return srv.(WhatsAppServiceServer).CreateCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhatsAppService_CreateCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhatsAppServiceServer).CreateCampaign(ctx, req.(*CreateCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _WhatsAppService_SendTemplateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SendTemplateMessageRequest)
+func _WhatsAppService_StartCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartCampaignRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WhatsAppServiceServer).SendTemplateMessage(ctx, in)
+		return srv.(WhatsAppServiceServer).StartCampaign(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: WhatsAppService_SendTemplateMessage_FullMethodName,
+		FullMethod: WhatsAppService_StartCampaign_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WhatsAppServiceServer).SendTemplateMessage(ctx, req.(*SendTemplateMessageRequest))
+		return srv.(WhatsAppServiceServer).StartCampaign(ctx, req.(*StartCampaignRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WhatsAppService_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetMessageRequest)
+func _WhatsAppService_GetCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WhatsAppServiceServer).GetMessage(ctx, in)
+		return srv.(WhatsAppServiceServer).GetCampaign(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: WhatsAppService_GetMessage_FullMethodName,
+		FullMethod: WhatsAppService_GetCampaign_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WhatsAppServiceServer).GetMessage(ctx, req.(*GetMessageRequest))
+		return srv.(WhatsAppServiceServer).GetCampaign(ctx, req.(*GetCampaignRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WhatsAppService_ListMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListMessagesRequest)
+func _WhatsAppService_GetCampaignReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignReportRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WhatsAppServiceServer).ListMessages(ctx, in)
+		return srv.(WhatsAppServiceServer).GetCampaignReport(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: WhatsAppService_ListMessages_FullMethodName,
+		FullMethod: WhatsAppService_GetCampaignReport_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WhatsAppServiceServer).ListMessages(ctx, req.(*ListMessagesRequest))
+		return srv.(WhatsAppServiceServer).GetCampaignReport(ctx, req.(*GetCampaignReportRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WhatsAppService_ImportCampaignAudience_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WhatsAppServiceServer).ImportCampaignAudience(&grpc.GenericServerStream[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhatsAppService_ImportCampaignAudienceServer = grpc.ClientStreamingServer[ImportCampaignAudienceRequest, ImportCampaignAudienceResponse]
+
 // WhatsAppService_ServiceDesc is the grpc.ServiceDesc for WhatsAppService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -189,6 +1899,18 @@ var WhatsAppService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendTemplateMessage",
 			Handler:    _WhatsAppService_SendTemplateMessage_Handler,
 		},
+		{
+			MethodName: "SendCTAURLMessage",
+			Handler:    _WhatsAppService_SendCTAURLMessage_Handler,
+		},
+		{
+			MethodName: "SendProductMessage",
+			Handler:    _WhatsAppService_SendProductMessage_Handler,
+		},
+		{
+			MethodName: "SendProductListMessage",
+			Handler:    _WhatsAppService_SendProductListMessage_Handler,
+		},
 		{
 			MethodName: "GetMessage",
 			Handler:    _WhatsAppService_GetMessage_Handler,
@@ -197,7 +1919,187 @@ var WhatsAppService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListMessages",
 			Handler:    _WhatsAppService_ListMessages_Handler,
 		},
+		{
+			MethodName: "GetMessageByExternalID",
+			Handler:    _WhatsAppService_GetMessageByExternalID_Handler,
+		},
+		{
+			MethodName: "GetMessages",
+			Handler:    _WhatsAppService_GetMessages_Handler,
+		},
+		{
+			MethodName: "GetMessagesByExternalIDs",
+			Handler:    _WhatsAppService_GetMessagesByExternalIDs_Handler,
+		},
+		{
+			MethodName: "GetMessagesByOrderID",
+			Handler:    _WhatsAppService_GetMessagesByOrderID_Handler,
+		},
+		{
+			MethodName: "GetServiceInfo",
+			Handler:    _WhatsAppService_GetServiceInfo_Handler,
+		},
+		{
+			MethodName: "EraseCustomerData",
+			Handler:    _WhatsAppService_EraseCustomerData_Handler,
+		},
+		{
+			MethodName: "GetMessageStats",
+			Handler:    _WhatsAppService_GetMessageStats_Handler,
+		},
+		{
+			MethodName: "GetDeliveryLatency",
+			Handler:    _WhatsAppService_GetDeliveryLatency_Handler,
+		},
+		{
+			MethodName: "GetQuota",
+			Handler:    _WhatsAppService_GetQuota_Handler,
+		},
+		{
+			MethodName: "PauseSending",
+			Handler:    _WhatsAppService_PauseSending_Handler,
+		},
+		{
+			MethodName: "ResumeSending",
+			Handler:    _WhatsAppService_ResumeSending_Handler,
+		},
+		{
+			MethodName: "ListSendPauses",
+			Handler:    _WhatsAppService_ListSendPauses_Handler,
+		},
+		{
+			MethodName: "GetInstanceStatus",
+			Handler:    _WhatsAppService_GetInstanceStatus_Handler,
+		},
+		{
+			MethodName: "GetLogLevel",
+			Handler:    _WhatsAppService_GetLogLevel_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _WhatsAppService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "DisableTemplate",
+			Handler:    _WhatsAppService_DisableTemplate_Handler,
+		},
+		{
+			MethodName: "EnableTemplate",
+			Handler:    _WhatsAppService_EnableTemplate_Handler,
+		},
+		{
+			MethodName: "ListDisabledTemplates",
+			Handler:    _WhatsAppService_ListDisabledTemplates_Handler,
+		},
+		{
+			MethodName: "PreviewTemplateMessage",
+			Handler:    _WhatsAppService_PreviewTemplateMessage_Handler,
+		},
+		{
+			MethodName: "SetCountryRule",
+			Handler:    _WhatsAppService_SetCountryRule_Handler,
+		},
+		{
+			MethodName: "DeleteCountryRule",
+			Handler:    _WhatsAppService_DeleteCountryRule_Handler,
+		},
+		{
+			MethodName: "ListCountryRules",
+			Handler:    _WhatsAppService_ListCountryRules_Handler,
+		},
+		{
+			MethodName: "RetryMessage",
+			Handler:    _WhatsAppService_RetryMessage_Handler,
+		},
+		{
+			MethodName: "GetConversation",
+			Handler:    _WhatsAppService_GetConversation_Handler,
+		},
+		{
+			MethodName: "UpdateHandoff",
+			Handler:    _WhatsAppService_UpdateHandoff_Handler,
+		},
+		{
+			MethodName: "SendTypingIndicator",
+			Handler:    _WhatsAppService_SendTypingIndicator_Handler,
+		},
+		{
+			MethodName: "GetAccountQuality",
+			Handler:    _WhatsAppService_GetAccountQuality_Handler,
+		},
+		{
+			MethodName: "ListAuditEntries",
+			Handler:    _WhatsAppService_ListAuditEntries_Handler,
+		},
+		{
+			MethodName: "DeleteMessage",
+			Handler:    _WhatsAppService_DeleteMessage_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _WhatsAppService_SearchMessages_Handler,
+		},
+		{
+			MethodName: "GetProviderCaptures",
+			Handler:    _WhatsAppService_GetProviderCaptures_Handler,
+		},
+		{
+			MethodName: "RecordOptIn",
+			Handler:    _WhatsAppService_RecordOptIn_Handler,
+		},
+		{
+			MethodName: "ListOptIns",
+			Handler:    _WhatsAppService_ListOptIns_Handler,
+		},
+		{
+			MethodName: "UpsertContact",
+			Handler:    _WhatsAppService_UpsertContact_Handler,
+		},
+		{
+			MethodName: "CreateSegment",
+			Handler:    _WhatsAppService_CreateSegment_Handler,
+		},
+		{
+			MethodName: "ListSegments",
+			Handler:    _WhatsAppService_ListSegments_Handler,
+		},
+		{
+			MethodName: "PreviewSegment",
+			Handler:    _WhatsAppService_PreviewSegment_Handler,
+		},
+		{
+			MethodName: "CreateCampaign",
+			Handler:    _WhatsAppService_CreateCampaign_Handler,
+		},
+		{
+			MethodName: "StartCampaign",
+			Handler:    _WhatsAppService_StartCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaign",
+			Handler:    _WhatsAppService_GetCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaignReport",
+			Handler:    _WhatsAppService_GetCampaignReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportMessages",
+			Handler:       _WhatsAppService_ExportMessages_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportCustomerData",
+			Handler:       _WhatsAppService_ExportCustomerData_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportCampaignAudience",
+			Handler:       _WhatsAppService_ImportCampaignAudience_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/whatapp.proto",
 }
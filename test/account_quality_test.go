@@ -0,0 +1,163 @@
+// test/account_quality_test.go
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// MockAccountQualityRepository is a mock implementation of repository.AccountQualityRepository
+type MockAccountQualityRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountQualityRepository) RecordEvent(ctx context.Context, event *domain.AccountEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAccountQualityRepository) ListQuality(ctx context.Context, tenantID string) ([]domain.AccountQuality, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]domain.AccountQuality), args.Error(1)
+}
+
+func (m *MockAccountQualityRepository) ListEvents(ctx context.Context, tenantID string, limit int) ([]domain.AccountEvent, error) {
+	args := m.Called(ctx, tenantID, limit)
+	return args.Get(0).([]domain.AccountEvent), args.Error(1)
+}
+
+// MockRateScaler records the send rate factors applied
+type MockRateScaler struct {
+	mock.Mock
+}
+
+func (m *MockRateScaler) SetRateFactor(factor float64) {
+	m.Called(factor)
+}
+
+// newQualityLogger returns a logger accepting the account quality service's logs
+func newQualityLogger() *MockLogger {
+	logger := newInboundLogger()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	return logger
+}
+
+// Test a red rating slows sends down until the number recovers
+func TestAccountQualitySlowsSendsWhileRed(t *testing.T) {
+	repo := new(MockAccountQualityRepository)
+	repo.On("RecordEvent", mock.Anything, mock.Anything).Return(nil)
+	pacer := new(MockRateScaler)
+	pacer.On("SetRateFactor", 0.5).Return().Once()
+	pacer.On("SetRateFactor", 1.0).Return().Once()
+
+	svc := service.NewAccountQualityService(repo, pacer, 0.5, newQualityLogger())
+	ctx := context.Background()
+	flagged := domain.AccountEvent{TenantID: "tenant-a", PhoneNumber: "15550001", Field: domain.AccountFieldQualityUpdate, Event: "FLAGGED", QualityRating: domain.QualityRed}
+
+	assert.NoError(t, svc.RecordEvent(ctx, flagged))
+	assert.Equal(t, 0.5, svc.RateFactor())
+
+	// A repeated rating and a tier change leave the rate alone
+	assert.NoError(t, svc.RecordEvent(ctx, flagged))
+	assert.NoError(t, svc.RecordEvent(ctx, domain.AccountEvent{TenantID: "tenant-a", PhoneNumber: "15550001", Event: "DOWNGRADE", MessagingLimitTier: "TIER_1K"}))
+	assert.Equal(t, 0.5, svc.RateFactor())
+
+	unflagged := flagged
+	unflagged.Event, unflagged.QualityRating = "UNFLAGGED", domain.QualityGreen
+	assert.NoError(t, svc.RecordEvent(ctx, unflagged))
+	assert.Equal(t, 1.0, svc.RateFactor())
+
+	pacer.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "RecordEvent", 4)
+}
+
+// Test refreshing picks up numbers other replicas saw flagged
+func TestAccountQualityRefresh(t *testing.T) {
+	repo := new(MockAccountQualityRepository)
+	repo.On("ListQuality", mock.Anything, "").Return([]domain.AccountQuality{
+		{TenantID: "tenant-a", PhoneNumber: "15550001", QualityRating: domain.QualityGreen},
+		{TenantID: "tenant-b", PhoneNumber: "15550002", QualityRating: domain.QualityRed},
+	}, nil)
+	pacer := new(MockRateScaler)
+	pacer.On("SetRateFactor", 0.25).Return().Once()
+
+	svc := service.NewAccountQualityService(repo, pacer, 0.25, newQualityLogger())
+	assert.NoError(t, svc.Refresh(context.Background()))
+	assert.Equal(t, 0.25, svc.RateFactor())
+	pacer.AssertExpectations(t)
+}
+
+// Test the caller's tenant scopes the quality and events returned
+func TestGetAccountQuality(t *testing.T) {
+	repo := new(MockAccountQualityRepository)
+	repo.On("ListQuality", mock.Anything, "tenant-a").Return([]domain.AccountQuality{{TenantID: "tenant-a", PhoneNumber: "15550001"}}, nil)
+	repo.On("ListEvents", mock.Anything, "tenant-a", 20).Return([]domain.AccountEvent{{ID: 7}}, nil)
+
+	svc := service.NewAccountQualityService(repo, nil, 0.5, newQualityLogger())
+	ctx := domain.WithTenant(context.Background(), "tenant-a")
+
+	qualities, events, err := svc.GetAccountQuality(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, qualities, 1)
+	assert.Len(t, events, 1)
+
+	_, _, err = svc.GetAccountQuality(ctx, 500)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+const testAccountWebhook = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "WABA-1",
+    "changes": [{
+      "field": "phone_number_quality_update",
+      "value": {"display_phone_number": "15550001", "event": "FLAGGED", "current_limit": "TIER_10K"}
+    }, {
+      "field": "account_update",
+      "value": {"phone_number": "15550001", "event": "ACCOUNT_VIOLATION", "violation_info": {"violation_type": "SCAM"}}
+    }]
+  }, {
+    "id": "WABA-UNKNOWN",
+    "changes": [{
+      "field": "phone_number_quality_update",
+      "value": {"display_phone_number": "15559999", "event": "FLAGGED", "current_limit": "TIER_1K"}
+    }]
+  }]
+}`
+
+// Test account webhooks are resolved by business account and recorded
+func TestProcessWebhookAccountEvents(t *testing.T) {
+	repo := new(MockAccountQualityRepository)
+	var recorded []domain.AccountEvent
+	repo.On("RecordEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = append(recorded, *args.Get(1).(*domain.AccountEvent))
+	}).Return(nil)
+	pacer := new(MockRateScaler)
+	pacer.On("SetRateFactor", 0.5).Return()
+
+	accounts := service.NewAccountQualityService(repo, pacer, 0.5, newQualityLogger())
+	tenants := service.NewStaticTenantResolver(map[string]string{"PNID-1": "tenant-a", "WABA-1": "tenant-a"})
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), tenants, utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Accounts: accounts, Signatures: testWebhookSignatures}, newQualityLogger(), "verify-token")
+
+	err := svc.ProcessWebhook(context.Background(), []byte(testAccountWebhook), signWebhook([]byte(testAccountWebhook)), "https://example.com/webhook")
+	assert.NoError(t, err)
+
+	if assert.Len(t, recorded, 2, "the unknown business account is skipped") {
+		assert.Equal(t, "tenant-a", recorded[0].TenantID)
+		assert.Equal(t, "15550001", recorded[0].PhoneNumber)
+		assert.Equal(t, domain.QualityRed, recorded[0].QualityRating)
+		assert.Equal(t, "TIER_10K", recorded[0].MessagingLimitTier)
+
+		assert.Equal(t, domain.AccountFieldAccountUpdate, recorded[1].Field)
+		assert.Equal(t, "ACCOUNT_VIOLATION", recorded[1].Event)
+		assert.Empty(t, recorded[1].QualityRating)
+		assert.Equal(t, "violation: SCAM", recorded[1].Detail)
+	}
+	pacer.AssertExpectations(t)
+}
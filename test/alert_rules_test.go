@@ -0,0 +1,189 @@
+// test/alert_rules_test.go
+package test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/meta/metatest"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test rules fire once when their measure crosses the threshold, resolve when back within it,
+// and keep their state over intervals with too little to judge
+func TestAlertRules(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	sends := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "sends_total"}, []string{"provider", "result"})
+	signatureFailures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "webhook_errors_total"}, []string{"code"})
+	expiry := prometheus.NewGauge(prometheus.GaugeOpts{Name: "token_expiry_timestamp_seconds"})
+	registry.MustRegister(sends, signatureFailures, expiry)
+	expiry.Set(float64(time.Now().Add(30 * 24 * time.Hour).Unix()))
+
+	notifier := new(MockNotifier)
+	var sent []alerts.Alert
+	notifier.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(alerts.Alert))
+	}).Return(nil)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	summary := func(value float64) string { return "summary" }
+	engine := alerts.NewEngine([]alerts.Rule{
+		{Key: "send_failure_rate", Measure: alerts.CounterShare(registry, "sends_total", map[string]string{"result": "failure"}, 10), Threshold: 0.5, Summary: summary},
+		{Key: "webhook_signature_failures", Measure: alerts.CounterIncrease(registry, "webhook_errors_total", map[string]string{"code": "invalid_signature"}), Threshold: 3, Summary: summary},
+		{Key: "meta_token_expiry", Measure: alerts.TimeUntil(registry, "token_expiry_timestamp_seconds"), Threshold: (7 * 24 * time.Hour).Seconds(), Below: true, Summary: summary},
+	}, notifier, logger)
+
+	// The first evaluation only records where the counters stand
+	sends.WithLabelValues("meta", "failure").Add(100)
+	signatureFailures.WithLabelValues("invalid_signature").Add(100)
+	engine.Evaluate(context.Background())
+	assert.Empty(t, sent)
+
+	sends.WithLabelValues("meta", "success").Add(4)
+	sends.WithLabelValues("twilio", "failure").Add(8)
+	signatureFailures.WithLabelValues("invalid_signature").Add(5)
+	signatureFailures.WithLabelValues("malformed_payload").Add(50)
+	expiry.Set(float64(time.Now().Add(24 * time.Hour).Unix()))
+	engine.Evaluate(context.Background())
+	require.Len(t, sent, 3)
+	assert.Equal(t, "send_failure_rate", sent[0].Key)
+	assert.InDelta(t, 8.0/12, sent[0].Value, 0.001)
+	assert.False(t, sent[0].Resolved)
+	assert.Equal(t, "webhook_signature_failures", sent[1].Key)
+	assert.Equal(t, 5.0, sent[1].Value)
+	assert.Equal(t, "meta_token_expiry", sent[2].Key)
+
+	// Too few sends to judge: the rate keeps firing; no signature failures resolves
+	sends.WithLabelValues("meta", "success").Add(5)
+	engine.Evaluate(context.Background())
+	require.Len(t, sent, 4)
+	assert.Equal(t, "webhook_signature_failures", sent[3].Key)
+	assert.True(t, sent[3].Resolved)
+
+	// The quiet interval's sends count toward the next
+	sends.WithLabelValues("meta", "success").Add(5)
+	expiry.Set(float64(time.Now().Add(60 * 24 * time.Hour).Unix()))
+	engine.Evaluate(context.Background())
+	require.Len(t, sent, 6)
+	assert.Equal(t, "send_failure_rate", sent[4].Key)
+	assert.True(t, sent[4].Resolved)
+	assert.Equal(t, "meta_token_expiry", sent[5].Key)
+	assert.True(t, sent[5].Resolved)
+}
+
+// Test the signature failure rule fires on the webhooks the Meta handler rejects for a wrong
+// signature, as counted by the metric it emits
+func TestSignatureFailureAlert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newQualityLogger()
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, logger, "verify-token")
+	router := gin.New()
+	router.POST("/webhook", handler.NewWebhookHandler(svc, logger).HandleWebhook)
+	deliver := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testStatusWebhook))
+		req.Header.Set(metatest.SignatureHeader, signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	notifier := new(MockNotifier)
+	var sent []alerts.Alert
+	notifier.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(alerts.Alert))
+	}).Return(nil)
+	engine := alerts.NewEngine([]alerts.Rule{{
+		Key:       "webhook_signature_failures",
+		Measure:   alerts.CounterIncrease(prometheus.DefaultGatherer, "whatsapp_webhook_errors_total", map[string]string{"code": handler.WebhookErrorInvalidSignature}),
+		Threshold: 2,
+		Summary:   func(float64) string { return "summary" },
+	}}, notifier, logger)
+	engine.Evaluate(context.Background())
+
+	for _, signature := range []string{"sha256=forged", metatest.Sign("other-secret", []byte(testStatusWebhook)), ""} {
+		require.Equal(t, http.StatusUnauthorized, deliver(signature))
+	}
+	engine.Evaluate(context.Background())
+	require.Len(t, sent, 1)
+	assert.Equal(t, 3.0, sent[0].Value)
+	assert.False(t, sent[0].Resolved)
+}
+
+// Test the email notifier mails the alert through the SMTP server to every recipient
+func TestEmailNotifier(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		received <- serveSMTP(conn)
+	}()
+
+	err = alerts.NewEmailNotifier(alerts.EmailConfig{
+		Addr: lis.Addr().String(),
+		From: "alerts@example.com",
+		To:   []string{"oncall@example.com", "ops@example.com"},
+	}).Notify(context.Background(), alerts.Alert{Key: "dlq_depth:whatsapp-messages.dlq", Summary: "250 messages wait in whatsapp-messages.dlq", Value: 250, Threshold: 100})
+	require.NoError(t, err)
+
+	lines := <-received
+	assert.Contains(t, lines, "RCPT TO:<oncall@example.com>")
+	assert.Contains(t, lines, "RCPT TO:<ops@example.com>")
+	assert.Contains(t, lines, "Subject: [ALERT] 250 messages wait in whatsapp-messages.dlq")
+	assert.Contains(t, lines, "Threshold: 100")
+}
+
+// serveSMTP answers one SMTP session without extensions, returning the lines the client sent
+func serveSMTP(conn net.Conn) []string {
+	var lines []string
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ready")
+	data := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return lines
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+		switch {
+		case data && line == ".":
+			data = false
+			reply("250 queued")
+		case data:
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250 localhost")
+		case line == "DATA":
+			data = true
+			reply("354 go ahead")
+		case line == "QUIT":
+			reply("221 bye")
+			return lines
+		default:
+			reply("250 ok")
+		}
+	}
+}
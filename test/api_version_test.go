@@ -0,0 +1,75 @@
+// test/api_version_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/config"
+	"messaging-microservice/pkg/meta"
+)
+
+// Test startup warns only when the version is near or past the end of its support
+func TestCheckAPIVersion(t *testing.T) {
+	endOfLife, ok := meta.APIVersionEndOfLife("v18.0")
+	assert.True(t, ok)
+	warnBefore := 90 * 24 * time.Hour
+
+	quiet := new(MockLogger)
+	meta.CheckAPIVersion("v18.0", endOfLife.Add(-2*warnBefore), warnBefore, quiet)
+	quiet.AssertNotCalled(t, "Warn", mock.Anything, mock.Anything)
+
+	nearing := new(MockLogger)
+	nearing.On("Warn", "Graph API version is nearing the end of its guaranteed support period; plan an upgrade", mock.Anything).Once()
+	meta.CheckAPIVersion("v18.0", endOfLife.Add(-warnBefore/2), warnBefore, nearing)
+	nearing.AssertExpectations(t)
+
+	past := new(MockLogger)
+	past.On("Warn", mock.Anything, mock.Anything).Once()
+	meta.CheckAPIVersion("v18.0", endOfLife.Add(time.Hour), warnBefore, past)
+	past.AssertExpectations(t)
+
+	unknown := new(MockLogger)
+	unknown.On("Info", mock.Anything, mock.Anything).Once()
+	meta.CheckAPIVersion("v99.0", time.Now(), warnBefore, unknown)
+	unknown.AssertExpectations(t)
+}
+
+// Test deprecation headers in Graph API responses are logged once per notice
+func TestTokenManagerLogsDeprecationHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Facebook-Api-Version", "v19.0")
+		w.Header().Set("Sunset", "Tue, 10 Sep 2025 00:00:00 GMT")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"is_valid": true}})
+	}))
+	defer server.Close()
+
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	logger.On("Warn", "Meta served a different Graph API version than requested; the requested one may be retired", mock.Anything).Once()
+	logger.On("Warn", "Graph API response carries a deprecation notice", mock.Anything).Once()
+
+	manager := meta.NewTokenManager(meta.TokenManagerConfig{AccessToken: "current", APIVersion: "v18.0", APIURL: server.URL}, logger)
+	assert.NoError(t, manager.Validate(context.Background()))
+	assert.NoError(t, manager.Validate(context.Background()))
+
+	logger.AssertExpectations(t)
+}
+
+// Test the API version defaults to the built-in one and must look like a version
+func TestConfigMetaAPIVersion(t *testing.T) {
+	cfg, err := config.Load("--database-url=postgres://db/messages", "--meta-phone-number-id=123", "--meta-access-token=token")
+	assert.NoError(t, err)
+	assert.Equal(t, meta.DefaultAPIVersion, cfg.MetaAPIVersion)
+	assert.Equal(t, "https://graph.facebook.com/v21.0", meta.APIURL("v21.0"))
+
+	_, err = config.Load("--meta-phone-number-id=123", "--meta-access-token=token", "--meta-api-version=18")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "META_API_VERSION must be a Graph API version")
+}
@@ -0,0 +1,229 @@
+// test/app_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/pkg/app"
+	"messaging-microservice/pkg/utils"
+)
+
+// newTestApp returns an app logging to a permissive mock
+func newTestApp() *app.App {
+	logger := new(MockLogger)
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe()
+	}
+	return app.New(logger)
+}
+
+// recordingHook appends its starts and stops to events
+func recordingHook(name string, events *[]string, startErr error) app.Hook {
+	return app.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+// Test hooks start in order and stop in reverse, and workers are stopped through their context
+func TestAppLifecycleOrder(t *testing.T) {
+	application := newTestApp()
+	var events []string
+	application.Append(recordingHook("database", &events, nil))
+	var worker sync.WaitGroup
+	worker.Add(1)
+	application.Go("worker", func(ctx context.Context) {
+		defer worker.Done()
+		<-ctx.Done()
+	})
+	application.Append(recordingHook("server", &events, nil))
+
+	assert.NoError(t, application.Start(context.Background()))
+	assert.NoError(t, application.Context().Err())
+	assert.NoError(t, application.Stop(context.Background()))
+	worker.Wait()
+
+	assert.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, events)
+	assert.Error(t, application.Context().Err(), "the shared context ends with the app")
+	assert.NoError(t, application.Stop(context.Background()), "stopping twice is a no-op")
+}
+
+// Test a failed start stops the components already started, and only those
+func TestAppStartFailureRollsBack(t *testing.T) {
+	application := newTestApp()
+	var events []string
+	application.Append(recordingHook("database", &events, nil))
+	application.Append(recordingHook("consumer", &events, errors.New("no brokers")))
+	application.Append(recordingHook("server", &events, nil))
+
+	err := application.Start(context.Background())
+	assert.ErrorContains(t, err, "start consumer: no brokers")
+	assert.Equal(t, []string{"start database", "start consumer", "stop database"}, events)
+}
+
+// Test workers that ignore their context make the stop fail once its deadline passes
+func TestAppStopDeadline(t *testing.T) {
+	application := newTestApp()
+	release := make(chan struct{})
+	defer close(release)
+	application.Go("stuck", func(context.Context) { <-release })
+
+	assert.NoError(t, application.Start(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := application.Stop(ctx)
+	assert.ErrorContains(t, err, "stop stuck")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+// Test a server that fails ends Run with its error, and a taken port fails the start
+func TestAppServeFailure(t *testing.T) {
+	application := newTestApp()
+	application.Serve("server", "127.0.0.1:0", func(lis net.Listener) error {
+		lis.Close()
+		return errors.New("serve failed")
+	}, nil)
+	err := application.Run(time.Second)
+	assert.ErrorContains(t, err, "serve failed")
+
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer taken.Close()
+	application = newTestApp()
+	application.Serve("server", taken.Addr().String(), func(net.Listener) error { return nil }, nil)
+	assert.ErrorContains(t, application.Start(context.Background()), "start server")
+}
+
+// testStore and testCache are components built by constructors
+type testStore struct{ name string }
+type testCache struct{ store *testStore }
+
+// Test constructors run once, on first use, can be replaced, and may not depend on themselves
+func TestAppProvideResolve(t *testing.T) {
+	application := newTestApp()
+	builds := 0
+	app.Provide(application, func(a *app.App) (*testStore, error) {
+		builds++
+		return &testStore{name: "postgres"}, nil
+	})
+	app.Provide(application, func(a *app.App) (*testCache, error) {
+		store, err := app.Resolve[*testStore](a)
+		return &testCache{store: store}, err
+	})
+
+	cache, err := app.Resolve[*testCache](application)
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", cache.store.name)
+	store, err := app.Resolve[*testStore](application)
+	assert.NoError(t, err)
+	assert.Same(t, cache.store, store)
+	assert.Equal(t, 1, builds)
+
+	fake := newTestApp()
+	app.Supply(fake, &testStore{name: "fake"})
+	app.Provide(fake, func(a *app.App) (*testCache, error) {
+		store, err := app.Resolve[*testStore](a)
+		return &testCache{store: store}, err
+	})
+	cache, err = app.Resolve[*testCache](fake)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", cache.store.name)
+
+	_, err = app.Resolve[context.Context](fake)
+	assert.ErrorContains(t, err, "no constructor provided")
+
+	cyclic := newTestApp()
+	app.Provide(cyclic, func(a *app.App) (*testStore, error) {
+		_, err := app.Resolve[*testCache](a)
+		return nil, err
+	})
+	app.Provide(cyclic, func(a *app.App) (*testCache, error) {
+		_, err := app.Resolve[*testStore](a)
+		return nil, err
+	})
+	_, err = app.Resolve[*testStore](cyclic)
+	assert.ErrorContains(t, err, "depends on itself")
+}
+
+// Test supervised components restart after returning or panicking, report down after the
+// failures allowed, and recover once a run lasts
+func TestAppSupervise(t *testing.T) {
+	application := newTestApp()
+	policy := app.RestartPolicy{
+		Backoff:     utils.Backoff{Attempts: 3, Initial: time.Millisecond, Max: time.Millisecond},
+		StableAfter: 50 * time.Millisecond,
+	}
+	runs := make(chan int, 10)
+	var count int
+	application.Supervise("consumer", policy, func(ctx context.Context) error {
+		count++
+		runs <- count
+		switch {
+		case count == 2:
+			panic("broker gone")
+		case count < 3:
+			return errors.New("read failed")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	assert.NoError(t, application.Start(context.Background()))
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-runs:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatalf("run %d did not start", want)
+		}
+	}
+	assert.Eventually(t, func() bool { return application.Ready(context.Background()) == nil }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, application.Stop(context.Background()))
+	assert.Empty(t, runs, "no restart after the app stops")
+}
+
+// Test a component failing the allowed times in a row fails readiness until it lasts a run
+func TestAppSuperviseDown(t *testing.T) {
+	application := newTestApp()
+	policy := app.RestartPolicy{
+		Backoff:     utils.Backoff{Attempts: 2, Initial: time.Millisecond, Max: time.Millisecond},
+		StableAfter: 50 * time.Millisecond,
+	}
+	var mu sync.Mutex
+	failing := true
+	application.Supervise("consumer", policy, func(ctx context.Context) error {
+		mu.Lock()
+		fail := failing
+		mu.Unlock()
+		if fail {
+			return errors.New("no brokers")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	assert.NoError(t, application.Start(context.Background()))
+	defer application.Stop(context.Background())
+	assert.Eventually(t, func() bool { return application.Ready(context.Background()) != nil }, time.Second, time.Millisecond)
+	assert.ErrorContains(t, application.Ready(context.Background()), "no brokers")
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	assert.Eventually(t, func() bool { return application.Ready(context.Background()) == nil }, time.Second, 5*time.Millisecond)
+}
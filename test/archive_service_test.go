@@ -0,0 +1,133 @@
+// test/archive_service_test.go
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/objectstore"
+)
+
+// Test archived batches can be read back through the archive-aware repository
+func TestArchiveRoundTrip(t *testing.T) {
+	store := objectstore.NewFileStore(t.TempDir())
+	mockRepo := new(MockMessageRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	created := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	messages := []*domain.Message{
+		{ID: 1, PhoneNumber: "+14155550100", TemplateID: "order_confirmation", Parameters: map[string]interface{}{"order": "A1"}, Status: "read", CreatedAt: created},
+		{ID: 2, PhoneNumber: "+14155550101", TemplateID: "order_confirmation", Parameters: map[string]interface{}{"order": "A2"}, Status: "delivered", CreatedAt: created},
+	}
+	key := "messages/2024/03/05/1-2.jsonl.gz"
+	mockRepo.On("ListMessagesToArchive", mock.Anything, mock.Anything, 10).Return(messages, nil).Once()
+	mockRepo.On("MarkMessagesArchived", mock.Anything, []int64{1, 2}, key).Return(nil)
+
+	archiver := service.NewArchiveService(mockRepo, store, service.ArchivePolicy{MaxHotAge: 90 * 24 * time.Hour, BatchSize: 10}, mockLogger)
+	archived, err := archiver.ArchiveExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), archived)
+
+	// The stub row carries a newer status than the archived copy
+	stub := &domain.Message{ID: 2, PhoneNumber: "+14155550101", Status: "read", ArchiveKey: key, CreatedAt: created}
+	mockRepo.On("GetMessageByID", mock.Anything, int64(2)).Return(stub, nil)
+
+	repo := repository.NewArchivedMessageRepository(mockRepo, store, mockLogger)
+	msg, err := repo.GetMessageByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "+14155550101", msg.PhoneNumber)
+	assert.Equal(t, "A2", msg.Parameters["order"])
+	assert.Equal(t, "read", msg.Status)
+}
+
+// Test erasing an archived message removes it from the archive, and reads of its stub no
+// longer restore it
+func TestArchivedMessageErasure(t *testing.T) {
+	dir := t.TempDir()
+	store := objectstore.NewFileStore(dir)
+	mockRepo := new(MockMessageRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	created := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	key := "messages/2024/03/05/1-2.jsonl.gz"
+	mockRepo.On("ListMessagesToArchive", mock.Anything, mock.Anything, 10).Return([]*domain.Message{
+		{ID: 1, PhoneNumber: "+14155550100", TemplateID: "order_confirmation", Parameters: map[string]interface{}{"order": "A1"}, Status: "read", CreatedAt: created},
+		{ID: 2, PhoneNumber: "+14155550101", TemplateID: "order_confirmation", Parameters: map[string]interface{}{"order": "A2"}, Status: "read", CreatedAt: created},
+	}, nil).Once()
+	mockRepo.On("MarkMessagesArchived", mock.Anything, []int64{1, 2}, key).Return(nil)
+	archiver := service.NewArchiveService(mockRepo, store, service.ArchivePolicy{MaxHotAge: 90 * 24 * time.Hour, BatchSize: 10}, mockLogger)
+	_, err := archiver.ArchiveExpired(context.Background())
+	require.NoError(t, err)
+
+	// The stub keeps the phone number, so the erasure finds it
+	filter := domain.MessageFilter{TenantID: "default", PhoneNumber: "+14155550101", IncludeDeleted: true}
+	mockRepo.On("ListMessagesAfterID", mock.Anything, filter, int64(0), mock.Anything).Return([]*domain.Message{
+		{ID: 2, PhoneNumber: "+14155550101", Status: "read", ArchiveKey: key, CreatedAt: created},
+	}, nil)
+	mockRepo.On("EraseMessages", mock.Anything, filter, false).Return(1, nil)
+
+	repo := repository.NewArchivedMessageRepository(mockRepo, store, mockLogger)
+	erased, err := repo.EraseMessages(context.Background(), filter, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), erased)
+
+	mockRepo.On("GetMessageByID", mock.Anything, int64(2)).Return(&domain.Message{ID: 2, PhoneNumber: "erased", Parameters: map[string]interface{}{}, Status: "read", ArchiveKey: key, ErasedAt: time.Now(), CreatedAt: created}, nil)
+	msg, err := repo.GetMessageByID(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "erased", msg.PhoneNumber)
+	assert.Empty(t, msg.Parameters)
+
+	// The other message of the archive is untouched
+	mockRepo.On("GetMessageByID", mock.Anything, int64(1)).Return(&domain.Message{ID: 1, PhoneNumber: "+14155550100", Status: "read", ArchiveKey: key, CreatedAt: created}, nil)
+	msg, err = repo.GetMessageByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "A1", msg.Parameters["order"])
+
+	data, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	archive, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NotContains(t, string(archive), "+14155550101")
+	assert.NotContains(t, string(archive), "A2")
+	assert.Contains(t, string(archive), "+14155550100")
+}
+
+// Test an erasure without a filter is refused before any archive is touched
+func TestArchivedMessageErasureRequiresFilter(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	repo := repository.NewArchivedMessageRepository(mockRepo, objectstore.NewFileStore(t.TempDir()), new(MockLogger))
+
+	_, err := repo.EraseMessages(context.Background(), domain.MessageFilter{}, true)
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "ListMessagesAfterID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "EraseMessages", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test nothing is marked archived when the upload fails
+func TestArchiveUploadFailure(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	messages := []*domain.Message{{ID: 1, CreatedAt: time.Now().AddDate(0, 0, -100)}}
+	mockRepo.On("ListMessagesToArchive", mock.Anything, mock.Anything, 1000).Return(messages, nil)
+
+	// A file store rooted at a regular file cannot create directories
+	store := objectstore.NewFileStore("/dev/null")
+	archiver := service.NewArchiveService(mockRepo, store, service.ArchivePolicy{MaxHotAge: 24 * time.Hour}, new(MockLogger))
+
+	_, err := archiver.ArchiveExpired(context.Background())
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "MarkMessagesArchived", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,128 @@
+// test/audit_log_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// Test template kill switch changes are audited with the state before and after
+func TestAuditedTemplateSwitch(t *testing.T) {
+	templates := new(MockTemplateRepository)
+	templates.On("DisableTemplate", mock.Anything, mock.Anything).Return(nil)
+	templates.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{
+		{TemplateID: "promo_spring", Reason: "wrong price", Actor: "alice"},
+	}, nil)
+	templates.On("EnableTemplate", mock.Anything, "promo_spring").Return(true, nil)
+
+	audit := new(MockAuditRepository)
+	var entries []domain.AuditEntry
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, *args.Get(1).(*domain.AuditEntry))
+	}).Return(1, nil)
+
+	logger := newQualityLogger()
+	templateSwitch := service.NewAuditedTemplateSwitch(service.NewTemplateSwitch(templates, logger), service.NewAuditLog(audit, nil, logger), nil, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	_, err := templateSwitch.Disable(ctx, domain.DisabledTemplate{TemplateID: "promo_spring", Reason: "wrong price", Actor: "alice"})
+	assert.NoError(t, err)
+	assert.NoError(t, templateSwitch.Enable(ctx, "promo_spring", "bob"))
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, domain.AuditActionDisableTemplate, entries[0].Action)
+		assert.Empty(t, entries[0].TenantID, "a kill switch reaches every tenant")
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.Nil(t, entries[0].Before)
+		var after domain.DisabledTemplate
+		assert.NoError(t, json.Unmarshal(entries[0].After, &after))
+		assert.Equal(t, "wrong price", after.Reason)
+
+		assert.Equal(t, domain.AuditActionEnableTemplate, entries[1].Action)
+		assert.Equal(t, "bob", entries[1].Actor)
+		assert.NotNil(t, entries[1].Before)
+		assert.Nil(t, entries[1].After)
+	}
+}
+
+// Test entries name the authenticated caller whatever requested_by claims, and are filed under
+// the tenant a change reaches
+func TestAuditActorIsAuthenticatedCaller(t *testing.T) {
+	pauseRepo := new(MockPauseRepository)
+	pauseRepo.On("CreatePause", mock.Anything, mock.Anything).Return(nil)
+	pauseRepo.On("ListPauses", mock.Anything).Return([]domain.SendPause{}, nil)
+	messages := new(MockMessageRepository)
+	messages.On("ReleaseHeldMessages", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.Message{}, nil)
+	rules := new(MockCountryRuleRepository)
+	rules.On("SetCountryRule", mock.Anything, mock.Anything).Return(nil)
+	rules.On("ListCountryRules", mock.Anything).Return([]domain.CountryRule{}, nil)
+
+	audit := new(MockAuditRepository)
+	var entries []domain.AuditEntry
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, *args.Get(1).(*domain.AuditEntry))
+	}).Return(1, nil)
+	logger := newQualityLogger()
+	auditLog := service.NewAuditLog(audit, nil, logger)
+	pauses := service.NewAuditedPauseService(service.NewPauseService(pauseRepo, messages, new(MockProducer), false, logger), auditLog, []string{"root"}, logger)
+	countries := service.NewAuditedCountryPolicy(service.NewCountryPolicy(rules, nil, nil, logger), auditLog, []string{"root"}, logger)
+
+	asAcme := domain.WithCaller(domain.WithTenantScope(context.Background(), "acme"), "acme-billing")
+	pause, err := pauses.Pause(asAcme, domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "acme", Actor: "root"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme-billing", pause.Actor)
+	asRoot := domain.WithCaller(domain.WithTenantScope(context.Background(), "ops"), "root")
+	_, err = countries.SetRule(asRoot, domain.CountryRule{Prefix: "234", Action: domain.CountryActionBlock, Actor: "alice"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "acme-billing", entries[0].Actor)
+		assert.Equal(t, "acme", entries[0].TenantID)
+		assert.Equal(t, "root", entries[1].Actor)
+		assert.Empty(t, entries[1].TenantID)
+	}
+}
+
+// Test entries are published keyed by subject, and a failed publish leaves the stored entry
+func TestAuditLogPublishes(t *testing.T) {
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Return(5, nil)
+	producer := new(MockProducer)
+	producer.On("ProduceWithKey", mock.Anything, []byte("template:promo_spring"), mock.MatchedBy(func(data []byte) bool {
+		var event service.AuditEvent
+		return json.Unmarshal(data, &event) == nil && event.ID == 5 && event.Action == domain.AuditActionDisableTemplate
+	})).Return(errors.New("broker down"))
+
+	auditLog := service.NewAuditLog(audit, producer, newQualityLogger())
+	id, err := auditLog.RecordAuditEntry(context.Background(), &domain.AuditEntry{
+		Action: domain.AuditActionDisableTemplate, SubjectType: domain.SubjectTemplate, Subject: "promo_spring", Actor: "alice",
+	})
+
+	assert.NoError(t, err, "the database holds the record of truth")
+	assert.Equal(t, int64(5), id)
+	producer.AssertExpectations(t)
+}
+
+// Test queries are scoped to the caller's tenant and bounded
+func TestListAuditEntries(t *testing.T) {
+	audit := new(MockAuditRepository)
+	audit.On("ListAuditEntries", mock.Anything, domain.AuditFilter{TenantID: "acme", Action: "pause_sending", Limit: 100}).
+		Return([]domain.AuditEntry{{ID: 1}}, nil)
+
+	auditLog := service.NewAuditLog(audit, nil, newQualityLogger())
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	entries, err := auditLog.ListAuditEntries(ctx, domain.AuditFilter{TenantID: "other", Action: "pause_sending"})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = auditLog.ListAuditEntries(ctx, domain.AuditFilter{Limit: 5000})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -0,0 +1,122 @@
+// test/backpressure_test.go
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/providerrouter"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test the breaker opens after consecutive provider errors and closes after a good probe
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	provider := new(MockWhatsAppClient)
+	call := provider.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &meta.APIError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"})
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	breaker := providerrouter.NewBreakerClient(provider, providerrouter.BreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         20 * time.Millisecond,
+		IsHealthError:    providerrouter.IsDegradedError,
+	}, logger)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := breaker.SendTemplateMessage(ctx, "+15550001", "order_confirmation", nil)
+		assert.Error(t, err)
+	}
+	ready, wait := breaker.Ready()
+	assert.False(t, ready)
+	assert.Greater(t, wait, time.Duration(0))
+
+	_, err := breaker.SendTemplateMessage(ctx, "+15550001", "order_confirmation", nil)
+	assert.ErrorIs(t, err, providerrouter.ErrCircuitOpen)
+	provider.AssertNumberOfCalls(t, "SendTemplateMessage", 2)
+
+	time.Sleep(30 * time.Millisecond)
+	ready, _ = breaker.Ready()
+	assert.True(t, ready, "half open after the cooldown")
+
+	call.Unset()
+	provider.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	_, err = breaker.SendTemplateMessage(ctx, "+15550001", "order_confirmation", nil)
+	assert.NoError(t, err)
+	ready, _ = breaker.Ready()
+	assert.True(t, ready)
+}
+
+// Test errors about the message itself do not open the breaker
+func TestBreakerIgnoresMessageErrors(t *testing.T) {
+	provider := new(MockWhatsAppClient)
+	provider.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &meta.APIError{StatusCode: http.StatusBadRequest, Code: 131026, Message: "undeliverable"})
+
+	breaker := providerrouter.NewBreakerClient(provider, providerrouter.BreakerConfig{
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+		IsHealthError:    providerrouter.IsDegradedError,
+	}, new(MockLogger))
+
+	_, err := breaker.SendTemplateMessage(context.Background(), "+15550001", "order_confirmation", nil)
+	assert.Error(t, err)
+	ready, _ := breaker.Ready()
+	assert.True(t, ready)
+}
+
+// Test the pacer reports itself exhausted once the bucket runs dry
+func TestPacerGate(t *testing.T) {
+	provider := new(MockWhatsAppClient)
+	provider.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+
+	paced := providerrouter.NewPacedClient(provider, utils.NewMemoryRateLimiter(), utils.RateLimit{RPS: 10, Burst: 1}, new(MockLogger))
+	ctx := context.Background()
+
+	_, err := paced.SendTemplateMessage(ctx, "+15550001", "order_confirmation", nil)
+	assert.NoError(t, err)
+	ready, _ := paced.Ready()
+	assert.True(t, ready)
+
+	// The second send waits for the next token, leaving the pacer exhausted meanwhile
+	done := make(chan struct{})
+	go func() {
+		_, _ = paced.SendTemplateMessage(ctx, "+15550001", "order_confirmation", nil)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	ready, wait := paced.Ready()
+	assert.False(t, ready)
+	assert.Greater(t, wait, time.Duration(0))
+	<-done
+}
+
+// fixedGate is a gate with a preset answer
+type fixedGate struct {
+	ready bool
+	wait  time.Duration
+}
+
+func (g fixedGate) Ready() (bool, time.Duration) {
+	return g.ready, g.wait
+}
+
+// Test combined gates wait for the slowest closed gate
+func TestAllGates(t *testing.T) {
+	assert.Nil(t, queue.AllGates())
+
+	ready, _ := queue.AllGates(fixedGate{ready: true}, fixedGate{ready: true}).Ready()
+	assert.True(t, ready)
+
+	ready, wait := queue.AllGates(fixedGate{ready: true}, fixedGate{wait: time.Second}, fixedGate{wait: 3 * time.Second}).Ready()
+	assert.False(t, ready)
+	assert.Equal(t, 3*time.Second, wait)
+}
@@ -0,0 +1,112 @@
+// test/caller_limits_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+func callThrough(interceptor grpc.UnaryServerInterceptor, ctx context.Context, method string) error {
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	return err
+}
+
+// Test authenticated API keys get their own rate limit and bucket, apart from their tenant's,
+// and keys the request did not authenticate with share the tenant's
+func TestCallerLimitInterceptorRate(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	interceptor := handler.CallerLimitInterceptor(utils.NewMemoryRateLimiter(), utils.NewMemoryWindowCounter(), handler.CallerLimitPolicy{
+		Rate:  utils.RateLimit{RPS: 1, Burst: 1},
+		Rates: map[string]utils.RateLimit{"billing-key": {RPS: 1, Burst: 2}},
+	}, logger)
+
+	tenant := domain.WithTenant(context.Background(), "acme")
+	keyed := metadata.NewIncomingContext(domain.WithCaller(tenant, "acme-billing"), metadata.Pairs("x-api-key", "billing-key"))
+	unauthenticated := metadata.NewIncomingContext(tenant, metadata.Pairs("x-api-key", "billing-key"))
+	method := pb.WhatsAppService_GetMessage_FullMethodName
+
+	assert.NoError(t, callThrough(interceptor, tenant, method))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, tenant, method)))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, unauthenticated, method)))
+
+	assert.NoError(t, callThrough(interceptor, keyed, method))
+	assert.NoError(t, callThrough(interceptor, keyed, method))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, keyed, method)))
+}
+
+// Test only sends count against a tenant's daily message limit
+func TestCallerLimitInterceptorDailyMessages(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	interceptor := handler.CallerLimitInterceptor(utils.NewMemoryRateLimiter(), utils.NewMemoryWindowCounter(), handler.CallerLimitPolicy{
+		DailyMessages:      1,
+		DailyMessageLimits: map[string]int64{"acme": 2},
+	}, logger)
+
+	acme := domain.WithTenant(context.Background(), "acme")
+	globex := domain.WithTenant(context.Background(), "globex")
+	send := pb.WhatsAppService_SendTemplateMessage_FullMethodName
+
+	assert.NoError(t, callThrough(interceptor, acme, send))
+	assert.NoError(t, callThrough(interceptor, acme, pb.WhatsAppService_SendProductMessage_FullMethodName))
+	assert.NoError(t, callThrough(interceptor, acme, pb.WhatsAppService_GetMessage_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, acme, send)))
+
+	assert.NoError(t, callThrough(interceptor, globex, send))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, globex, send)))
+}
+
+// Test retries count as sends, and a campaign counts its audience once it has started
+func TestCallerLimitInterceptorCountsEverySend(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	interceptor := handler.CallerLimitInterceptor(utils.NewMemoryRateLimiter(), utils.NewMemoryWindowCounter(), handler.CallerLimitPolicy{DailyMessages: 5}, logger)
+	acme := domain.WithTenant(context.Background(), "acme")
+
+	startCampaign := func() error {
+		_, err := interceptor(acme, nil, &grpc.UnaryServerInfo{FullMethod: pb.WhatsAppService_StartCampaign_FullMethodName}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &pb.Campaign{AudienceSize: 3}, nil
+		})
+		return err
+	}
+
+	assert.NoError(t, callThrough(interceptor, acme, pb.WhatsAppService_RetryMessage_FullMethodName))
+	assert.NoError(t, startCampaign())
+	assert.NoError(t, callThrough(interceptor, acme, pb.WhatsAppService_SendTemplateMessage_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(callThrough(interceptor, acme, pb.WhatsAppService_RetryMessage_FullMethodName)))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(startCampaign()))
+}
+
+// Test the Redis window counter falls back to counting in memory when Redis is unreachable
+func TestRedisWindowCounterFallback(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	counter := utils.NewRedisWindowCounter(client, utils.NewMemoryWindowCounter(), logger)
+	expireAt := time.Now().Add(time.Hour)
+	for want := int64(1); want <= 2; want++ {
+		count, err := counter.Incr(context.Background(), "k", 1, expireAt)
+		assert.NoError(t, err)
+		assert.Equal(t, want, count)
+	}
+	logger.AssertCalled(t, "Warn", "Redis window counter unavailable, using in-memory fallback", mock.Anything)
+}
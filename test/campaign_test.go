@@ -0,0 +1,394 @@
+// test/campaign_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+)
+
+// MockSegmentRepository mocks repository.SegmentRepository
+type MockSegmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockSegmentRepository) UpsertContact(ctx context.Context, contact *domain.Contact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) CreateSegment(ctx context.Context, segment *domain.Segment, phoneNumbers []string) error {
+	args := m.Called(ctx, segment, phoneNumbers)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) GetSegment(ctx context.Context, tenantID string, id int64) (*domain.Segment, error) {
+	args := m.Called(ctx, tenantID, id)
+	segment, _ := args.Get(0).(*domain.Segment)
+	return segment, args.Error(1)
+}
+
+func (m *MockSegmentRepository) ListSegments(ctx context.Context, tenantID string) ([]domain.Segment, error) {
+	args := m.Called(ctx, tenantID)
+	segments, _ := args.Get(0).([]domain.Segment)
+	return segments, args.Error(1)
+}
+
+func (m *MockSegmentRepository) PreviewSegment(ctx context.Context, segment domain.Segment, now time.Time, sampleSize int) (int64, []string, error) {
+	args := m.Called(ctx, segment, now, sampleSize)
+	sample, _ := args.Get(1).([]string)
+	return args.Get(0).(int64), sample, args.Error(2)
+}
+
+func (m *MockSegmentRepository) EraseContacts(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockCampaignRepository mocks repository.CampaignRepository
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepository) CreateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) GetCampaign(ctx context.Context, tenantID string, id int64) (*domain.Campaign, error) {
+	args := m.Called(ctx, tenantID, id)
+	campaign, _ := args.Get(0).(*domain.Campaign)
+	return campaign, args.Error(1)
+}
+
+func (m *MockCampaignRepository) ListCampaignVariants(ctx context.Context, campaignID int64) ([]domain.CampaignVariant, error) {
+	args := m.Called(ctx, campaignID)
+	variants, _ := args.Get(0).([]domain.CampaignVariant)
+	return variants, args.Error(1)
+}
+
+func (m *MockCampaignRepository) StartCampaign(ctx context.Context, campaign *domain.Campaign, segment *domain.Segment, now time.Time) (bool, error) {
+	args := m.Called(ctx, campaign, segment, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ImportCampaignRecipients(ctx context.Context, campaign *domain.Campaign, recipients []domain.CampaignRecipient) (int64, []string, error) {
+	args := m.Called(ctx, campaign, recipients)
+	suppressed, _ := args.Get(1).([]string)
+	return args.Get(0).(int64), suppressed, args.Error(2)
+}
+
+func (m *MockCampaignRepository) ClaimCampaignRecipients(ctx context.Context, limit int, staleBefore time.Time) ([]domain.CampaignRecipient, error) {
+	args := m.Called(ctx, limit, staleBefore)
+	recipients, _ := args.Get(0).([]domain.CampaignRecipient)
+	return recipients, args.Error(1)
+}
+
+func (m *MockCampaignRepository) CompleteCampaignRecipient(ctx context.Context, campaignID int64, phoneNumber, variant string, messageID int64, errMsg string) error {
+	args := m.Called(ctx, campaignID, phoneNumber, variant, messageID, errMsg)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) CampaignVariantStats(ctx context.Context, campaignID int64) (map[string]*domain.StatsBucket, error) {
+	args := m.Called(ctx, campaignID)
+	stats, _ := args.Get(0).(map[string]*domain.StatsBucket)
+	return stats, args.Error(1)
+}
+
+func (m *MockCampaignRepository) CompleteCampaigns(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// stubSender answers template sends with send, passing their parameters to record when set;
+// other MessageService methods aren't used
+type stubSender struct {
+	service.MessageService
+	send   func(ctx context.Context, phoneNumber, templateID string) (*domain.Message, error)
+	record func(parameters map[string]interface{})
+}
+
+func (s *stubSender) SendTemplateMessage(ctx context.Context, phoneNumber, templateID string, parameters map[string]interface{}, orderID, customerID string) (*domain.Message, error) {
+	if s.record != nil {
+		s.record(parameters)
+	}
+	return s.send(ctx, phoneNumber, templateID)
+}
+
+// Test list segments are stored with normalized phone numbers and can't carry a filter too
+func TestCreateListSegment(t *testing.T) {
+	segments := new(MockSegmentRepository)
+	segments.On("CreateSegment", mock.Anything, mock.MatchedBy(func(segment *domain.Segment) bool {
+		return segment.TenantID == "acme" && segment.Kind == domain.SegmentKindList
+	}), []string{"15551234567", "447700900123"}).Return(nil).Once()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	campaigns := service.NewCampaignService(segments, new(MockCampaignRepository), nil, 10, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	_, err := campaigns.CreateSegment(ctx, domain.Segment{Name: "VIPs", Kind: domain.SegmentKindList, Actor: "ops"},
+		[]string{"+1 555 123 4567", "+447700900123"})
+	assert.NoError(t, err)
+
+	_, err = campaigns.CreateSegment(ctx, domain.Segment{
+		Name:   "VIPs",
+		Kind:   domain.SegmentKindList,
+		Filter: domain.SegmentFilter{Tags: []string{"vip"}},
+		Actor:  "ops",
+	}, []string{"+15551234567"})
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	_, err = campaigns.CreateSegment(ctx, domain.Segment{
+		Name:   "Lapsed",
+		Kind:   domain.SegmentKindFilter,
+		Filter: domain.SegmentFilter{ActiveWithin: time.Hour, InactiveFor: 2 * time.Hour},
+		Actor:  "ops",
+	}, nil)
+	assert.True(t, errors.Is(err, domain.ErrValidation), "a window nobody can be in is refused")
+	segments.AssertExpectations(t)
+}
+
+// Test only draft campaigns start, materializing their segment's audience
+func TestStartCampaign(t *testing.T) {
+	segment := &domain.Segment{ID: 3, TenantID: "acme", Kind: domain.SegmentKindFilter, Filter: domain.SegmentFilter{Tags: []string{"vip"}}}
+	segments := new(MockSegmentRepository)
+	segments.On("GetSegment", mock.Anything, "acme", int64(3)).Return(segment, nil)
+	repo := new(MockCampaignRepository)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(1)).Return(&domain.Campaign{ID: 1, TenantID: "acme", SegmentID: 3, Status: domain.CampaignStatusDraft}, nil)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(2)).Return(&domain.Campaign{ID: 2, TenantID: "acme", SegmentID: 3, Status: domain.CampaignStatusRunning}, nil)
+	repo.On("StartCampaign", mock.Anything, mock.MatchedBy(func(campaign *domain.Campaign) bool { return campaign.ID == 1 }), segment, mock.Anything).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Campaign).AudienceSize = 42 }).
+		Return(true, nil).Once()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	campaigns := service.NewCampaignService(segments, repo, nil, 10, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	campaign, err := campaigns.StartCampaign(ctx, 1, "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), campaign.AudienceSize)
+
+	_, err = campaigns.StartCampaign(ctx, 2, "ops")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+	repo.AssertExpectations(t)
+}
+
+// Test a dispatch sends as each recipient's tenant, fails recipients refused by a policy and
+// leaves transient failures claimed for a retry
+func TestDispatchCampaignRecipients(t *testing.T) {
+	repo := new(MockCampaignRepository)
+	repo.On("ClaimCampaignRecipients", mock.Anything, 10, mock.Anything).Return([]domain.CampaignRecipient{
+		{CampaignID: 1, TenantID: "acme", PhoneNumber: "15551234567", TemplateID: "spring_sale"},
+		{CampaignID: 1, TenantID: "acme", PhoneNumber: "15557654321", TemplateID: "spring_sale"},
+		{CampaignID: 1, TenantID: "acme", PhoneNumber: "15550000000", TemplateID: "spring_sale"},
+	}, nil).Once()
+	repo.On("ListCampaignVariants", mock.Anything, int64(1)).Return(nil, nil).Once()
+	repo.On("CompleteCampaignRecipient", mock.Anything, int64(1), "15551234567", "", int64(7), "").Return(nil).Once()
+	repo.On("CompleteCampaignRecipient", mock.Anything, int64(1), "15557654321", "", int64(0), "contact opted out").Return(nil).Once()
+	repo.On("CompleteCampaigns", mock.Anything, mock.Anything).Return(int64(0), nil)
+	logger := new(MockLogger)
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	sender := &stubSender{send: func(ctx context.Context, phoneNumber, templateID string) (*domain.Message, error) {
+		assert.Equal(t, "acme", domain.TenantFromContext(ctx))
+		switch phoneNumber {
+		case "+15551234567":
+			return &domain.Message{ID: 7}, nil
+		case "+15557654321":
+			return nil, domain.NewError(domain.ErrFailedPrecondition, "contact opted out")
+		}
+		return nil, errors.New("connection reset")
+	}}
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, sender, 10, logger)
+
+	sent, err := campaigns.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sent)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CompleteCampaignRecipient", mock.Anything, int64(1), "15550000000", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test an import stores valid rows with their parameters, skips duplicates and reports bad rows
+func TestImportCampaignAudience(t *testing.T) {
+	campaign := &domain.Campaign{ID: 5, TenantID: "acme", Status: domain.CampaignStatusDraft}
+	repo := new(MockCampaignRepository)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(5)).Return(campaign, nil)
+	repo.On("ImportCampaignRecipients", mock.Anything, campaign, []domain.CampaignRecipient{
+		{PhoneNumber: "15551234567", Parameters: map[string]string{"name": "Ada", "code": "SPRING10"}},
+		{PhoneNumber: "447700900123", Parameters: map[string]string{"name": "Grace"}},
+	}).Return(int64(1), []string{"447700900123"}, nil).Once()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, nil, 10, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	csv := "\ufeffphone_number,name,code\n" +
+		"+1 555 123 4567,Ada,SPRING10\n" +
+		"+447700900123,Grace,\n" +
+		"15551234567,Ada again,SPRING10\n" +
+		"12345,Bob,\n" +
+		"+15557654321,Eve\n"
+	summary, err := campaigns.ImportAudience(ctx, 5, strings.NewReader(csv))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), summary.Rows)
+	assert.Equal(t, int64(1), summary.Imported)
+	assert.Equal(t, int64(1), summary.Suppressed)
+	assert.Equal(t, int64(1), summary.Duplicates)
+	assert.Equal(t, int64(2), summary.Invalid)
+	if assert.Len(t, summary.Errors, 2) {
+		assert.Equal(t, domain.ImportRowError{Row: 5, PhoneNumber: "12345", Message: "invalid phone number"}, summary.Errors[0])
+		assert.Equal(t, int64(6), summary.Errors[1].Row)
+	}
+	repo.AssertExpectations(t)
+
+	_, err = campaigns.ImportAudience(ctx, 5, strings.NewReader("name,code\nAda,SPRING10\n"))
+	assert.True(t, errors.Is(err, domain.ErrValidation), "a header without phone_number is refused")
+}
+
+// Test the HTTP upload authenticates its caller and imports into the key's tenant, whatever
+// X-Tenant-ID says
+func TestImportCampaignAudienceOverHTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	campaign := &domain.Campaign{ID: 5, TenantID: "acme", Status: domain.CampaignStatusDraft}
+	repo := new(MockCampaignRepository)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(5)).Return(campaign, nil)
+	repo.On("ImportCampaignRecipients", mock.Anything, campaign, mock.Anything).Return(int64(1), []string(nil), nil).Once()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, nil, 10, logger)
+	router := gin.New()
+	router.POST("/campaigns/:campaign_id/audience", handler.TenantMiddleware(tenantCredentials), handler.NewAudienceImportHandler(campaigns, logger).HandleImport)
+
+	upload := func(headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "/campaigns/5/audience", strings.NewReader("phone_number\n+15551234567\n"))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, upload(map[string]string{"X-Tenant-ID": "acme"}))
+	assert.Equal(t, http.StatusForbidden, upload(map[string]string{"X-API-Key": "globex-key", "X-Tenant-ID": "acme"}))
+	assert.Equal(t, http.StatusOK, upload(map[string]string{"X-API-Key": "acme-key"}))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "GetCampaign", mock.Anything, "globex", mock.Anything)
+}
+
+// Test campaigns without a segment only start once recipients were imported
+func TestStartCampaignWithoutAudience(t *testing.T) {
+	repo := new(MockCampaignRepository)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(5)).Return(&domain.Campaign{ID: 5, TenantID: "acme", Status: domain.CampaignStatusDraft}, nil)
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, nil, 10, new(MockLogger))
+
+	_, err := campaigns.StartCampaign(domain.WithTenant(context.Background(), "acme"), 5, "ops")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+	repo.AssertNotCalled(t, "StartCampaign", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test variants split an audience close to their weights, the same way every time
+func TestPickVariant(t *testing.T) {
+	variants := []domain.CampaignVariant{{Name: "A", Weight: 3}, {Name: "B", Weight: 1}}
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		phoneNumber := fmt.Sprintf("1555%07d", i)
+		variant := domain.PickVariant(variants, 9, phoneNumber)
+		counts[variant.Name]++
+		assert.Equal(t, variant, domain.PickVariant(variants, 9, phoneNumber))
+	}
+
+	assert.InDelta(t, 7500, counts["A"], 250)
+	assert.InDelta(t, 2500, counts["B"], 250)
+	assert.Nil(t, domain.PickVariant(nil, 9, "15551234567"))
+}
+
+// Test a variant's template is sent with its parameters between the campaign's and the row's
+func TestDispatchCampaignVariant(t *testing.T) {
+	variants := []domain.CampaignVariant{{Name: "B", TemplateID: "spring_sale_b", Parameters: map[string]string{"code": "B10", "cta": "Shop"}, Weight: 1}}
+	repo := new(MockCampaignRepository)
+	repo.On("ClaimCampaignRecipients", mock.Anything, 10, mock.Anything).Return([]domain.CampaignRecipient{{
+		CampaignID:         1,
+		TenantID:           "acme",
+		PhoneNumber:        "15551234567",
+		CampaignParameters: map[string]string{"code": "SPRING", "name": "customer", "cta": "Buy"},
+		Parameters:         map[string]string{"name": "Ada"},
+	}}, nil).Once()
+	repo.On("ListCampaignVariants", mock.Anything, int64(1)).Return(variants, nil).Once()
+	repo.On("CompleteCampaignRecipient", mock.Anything, int64(1), "15551234567", "B", int64(7), "").Return(nil).Once()
+	repo.On("CompleteCampaigns", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	var sentParameters map[string]interface{}
+	sender := &stubSender{send: func(ctx context.Context, phoneNumber, templateID string) (*domain.Message, error) {
+		assert.Equal(t, "spring_sale_b", templateID)
+		return &domain.Message{ID: 7}, nil
+	}}
+	sender.record = func(parameters map[string]interface{}) { sentParameters = parameters }
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, sender, 10, new(MockLogger))
+
+	_, err := campaigns.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"code": "B10", "cta": "Shop", "name": "Ada"}, sentParameters)
+	repo.AssertExpectations(t)
+}
+
+// Test the report lists every variant in order with its delivery and read counts
+func TestGetCampaignReport(t *testing.T) {
+	repo := new(MockCampaignRepository)
+	repo.On("GetCampaign", mock.Anything, "acme", int64(1)).Return(&domain.Campaign{ID: 1, TenantID: "acme", Variants: []domain.CampaignVariant{
+		{Name: "A", TemplateID: "spring_sale_a", Weight: 1},
+		{Name: "B", TemplateID: "spring_sale_b", Weight: 1},
+	}}, nil)
+	stats := &domain.StatsBucket{}
+	stats.Add("read", 3)
+	stats.Add("delivered", 1)
+	stats.Add("failed", 4)
+	repo.On("CampaignVariantStats", mock.Anything, int64(1)).Return(map[string]*domain.StatsBucket{"A": stats}, nil)
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), repo, nil, 10, new(MockLogger))
+
+	reports, err := campaigns.GetCampaignReport(domain.WithTenant(context.Background(), "acme"), 1)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "A", reports[0].Variant.Name)
+		assert.Equal(t, "spring_sale_a", reports[0].Stats.TemplateID)
+		assert.Equal(t, 0.5, reports[0].Stats.DeliveredRate())
+		assert.Equal(t, 0.375, reports[0].Stats.ReadRate())
+		assert.Equal(t, "B", reports[1].Variant.Name)
+		assert.Equal(t, int64(0), reports[1].Stats.Total)
+	}
+}
+
+// Test variant campaigns need 2 or more distinct, weighted variants and no campaign template
+func TestCreateCampaignVariantsValidation(t *testing.T) {
+	campaigns := service.NewCampaignService(new(MockSegmentRepository), new(MockCampaignRepository), nil, 10, new(MockLogger))
+	ctx := domain.WithTenant(context.Background(), "acme")
+	a := domain.CampaignVariant{Name: "A", TemplateID: "spring_sale_a", Weight: 1}
+
+	for name, variants := range map[string][]domain.CampaignVariant{
+		"one variant":    {a},
+		"duplicate name": {a, a},
+		"zero weight":    {a, {Name: "B", TemplateID: "spring_sale_b"}},
+		"no template":    {a, {Name: "B", Weight: 1}},
+	} {
+		_, err := campaigns.CreateCampaign(ctx, domain.Campaign{Name: "Spring", Variants: variants, Actor: "ops"})
+		assert.True(t, errors.Is(err, domain.ErrValidation), name)
+	}
+
+	_, err := campaigns.CreateCampaign(ctx, domain.Campaign{Name: "Spring", TemplateID: "spring_sale", Actor: "ops",
+		Variants: []domain.CampaignVariant{a, {Name: "B", TemplateID: "spring_sale_b", Weight: 1}}})
+	assert.True(t, errors.Is(err, domain.ErrValidation), "template_id and variants are exclusive")
+}
@@ -0,0 +1,71 @@
+// test/canary_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/providerrouter"
+)
+
+// Test a phone number always goes to the same provider and the split follows the percentage
+func TestCanaryStickySplit(t *testing.T) {
+	primary := new(MockWhatsAppClient)
+	primary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	canary := new(MockWhatsAppClient)
+	canary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+
+	client := providerrouter.NewCanaryClient(primary, canary, providerrouter.CanaryConfig{
+		CanaryName: "mock",
+		Percent:    20,
+	}, new(MockLogger))
+
+	for i := 0; i < 1000; i++ {
+		_, err := client.SendTemplateMessage(context.Background(), fmt.Sprintf("+1555%07d", i), "order_confirmation", nil)
+		assert.NoError(t, err)
+	}
+	canarySends := len(canary.Calls)
+	assert.InDelta(t, 200, canarySends, 60)
+
+	// The same numbers again land on the same providers
+	for i := 0; i < 1000; i++ {
+		_, _ = client.SendTemplateMessage(context.Background(), fmt.Sprintf("+1555%07d", i), "order_confirmation", nil)
+	}
+	assert.Equal(t, 2*canarySends, len(canary.Calls))
+}
+
+// Test the canary is rolled back once its failure rate crosses the threshold, and resumes after the cooldown
+func TestCanaryAutomaticRollback(t *testing.T) {
+	primary := new(MockWhatsAppClient)
+	primary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	canary := new(MockWhatsAppClient)
+	canary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("provider down"))
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	client := providerrouter.NewCanaryClient(primary, canary, providerrouter.CanaryConfig{
+		CanaryName:     "mock",
+		Percent:        100,
+		MaxFailureRate: 0.5,
+		Window:         10,
+		MinSamples:     5,
+		Cooldown:       50 * time.Millisecond,
+	}, mockLogger)
+
+	for i := 0; i < 8; i++ {
+		_, _ = client.SendTemplateMessage(context.Background(), "+15550000001", "order_confirmation", nil)
+	}
+	canary.AssertNumberOfCalls(t, "SendTemplateMessage", 5)
+	primary.AssertNumberOfCalls(t, "SendTemplateMessage", 3)
+
+	time.Sleep(60 * time.Millisecond)
+	_, _ = client.SendTemplateMessage(context.Background(), "+15550000001", "order_confirmation", nil)
+	canary.AssertNumberOfCalls(t, "SendTemplateMessage", 6)
+}
@@ -0,0 +1,103 @@
+// test/config_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/config"
+)
+
+// writeConfigFile writes a YAML config file into a temporary directory
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Test flags override environment variables, which override the config file
+func TestConfigSourcePrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+database_url: postgres://app:pw@db:5432/messages
+meta_phone_number_id: "111"
+meta_access_token: from-file
+http_port: 7000
+grpc_port: 7001
+kafka_brokers: [k1:9092, k2:9092]
+`)
+	t.Setenv("HTTP_PORT", "8000")
+
+	cfg, err := config.Load("--config", path, "--http-port=9000")
+	assert.NoError(t, err)
+	assert.Equal(t, "9000", cfg.HTTPPort)
+	assert.Equal(t, "7001", cfg.GRPCPort)
+	assert.Equal(t, "from-file", cfg.MetaAccessToken)
+	assert.Equal(t, []string{"k1:9092", "k2:9092"}, cfg.KafkaBrokers)
+	assert.False(t, cfg.ValidateOnly)
+}
+
+// Test malformed and unknown settings are rejected
+func TestConfigRejectsInvalidSettings(t *testing.T) {
+	path := writeConfigFile(t, "database_url: postgres://db/messages\nno_such_setting: 1\n")
+
+	_, err := config.Load("--config", path, "--read-timeout=soon", "--meta-acess-token=x")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "READ_TIMEOUT")
+	assert.Contains(t, err.Error(), "unknown flag --meta-acess-token")
+	assert.Contains(t, err.Error(), "unknown setting NO_SUCH_SETTING")
+}
+
+// Test validation reports every problem at once
+func TestConfigValidateReportsAllErrors(t *testing.T) {
+	_, err := config.Load("--database-max-open-conns=0", "--whatsapp-provider=carrier-pigeon", "--database-driver=mysql")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DATABASE_URL is required")
+	assert.Contains(t, err.Error(), "DATABASE_MAX_OPEN_CONNS must be positive")
+	assert.Contains(t, err.Error(), "WHATSAPP_PROVIDER must be one of")
+	assert.Contains(t, err.Error(), "DATABASE_DRIVER must be one of")
+}
+
+// Test --validate-config output masks secrets
+func TestConfigRedacted(t *testing.T) {
+	cfg, err := config.Load(
+		"--validate-config",
+		"--database-url=postgres://app:hunter2@db:5432/messages",
+		"--meta-phone-number-id=111",
+		"--meta-access-token=EAAG-secret",
+	)
+	assert.NoError(t, err)
+	assert.True(t, cfg.ValidateOnly)
+
+	out := cfg.Redacted()
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "EAAG-secret")
+	assert.Contains(t, out, `MetaAccessToken: "[REDACTED]"`)
+	assert.Contains(t, out, `DatabaseURL: "postgres://app:REDACTED@db:5432/messages"`)
+	assert.Contains(t, out, `MetaPhoneNumberID: "111"`)
+}
+
+// Test topic layouts and retry delays are checked
+func TestConfigValidatesKafkaTopics(t *testing.T) {
+	_, err := config.Load("--kafka-topic-layouts=whatsapp-messages=twelve", "--kafka-retry-delays=10m,1m")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "KAFKA_TOPIC_LAYOUTS: whatsapp-messages")
+	assert.Contains(t, err.Error(), "KAFKA_RETRY_DELAYS must be increasing")
+}
+
+// Test gRPC server limits default to values that suit load balancers and are checked
+func TestConfigGRPCServerLimits(t *testing.T) {
+	cfg, err := config.Load("--database-url=postgres://db/messages", "--meta-phone-number-id=111", "--meta-access-token=x")
+	assert.NoError(t, err)
+	assert.Equal(t, 4<<20, cfg.GRPCMaxRecvMsgSize)
+	assert.Equal(t, 2*time.Minute, cfg.GRPCKeepaliveTime)
+	assert.True(t, cfg.GRPCKeepalivePermitWithoutStream)
+
+	_, err = config.Load("--grpc-max-recv-msg-size=0", "--grpc-max-concurrent-streams=-1", "--grpc-keepalive-time=0s")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GRPC_MAX_RECV_MSG_SIZE must be positive")
+	assert.Contains(t, err.Error(), "GRPC_MAX_CONCURRENT_STREAMS must not be negative")
+	assert.Contains(t, err.Error(), "GRPC_KEEPALIVE_TIME must be positive")
+}
@@ -0,0 +1,88 @@
+// test/consumer_monitor_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/pkg/alerts"
+)
+
+// fakeConsumer reports preset stats
+type fakeConsumer struct {
+	stats queue.ConsumerStats
+}
+
+func (c *fakeConsumer) Consume(ctx context.Context, handler queue.MessageHandler) error {
+	return nil
+}
+
+func (c *fakeConsumer) Stats() queue.ConsumerStats {
+	return c.stats
+}
+
+func (c *fakeConsumer) Close() error {
+	return nil
+}
+
+// MockNotifier records alerts
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, alert alerts.Alert) error {
+	args := m.Called(ctx, alert)
+	return args.Error(0)
+}
+
+// Test alerts fire once when a threshold is crossed and resolve when back within it
+func TestConsumerMonitorAlertsOnLagAndFailures(t *testing.T) {
+	consumer := &fakeConsumer{stats: queue.ConsumerStats{Topic: "whatsapp-messages"}}
+	notifier := new(MockNotifier)
+	var sent []alerts.Alert
+	notifier.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(alerts.Alert))
+	}).Return(nil)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	monitor := queue.NewConsumerMonitor(consumer, queue.MonitorConfig{MaxLag: 1000, MaxFailureRate: 0.5, MinMessages: 10}, notifier, logger)
+
+	consumer.stats = queue.ConsumerStats{Topic: "whatsapp-messages", Lag: 5000, Handled: 20, Failed: 15}
+	monitor.Check(context.Background())
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "consumer_lag:whatsapp-messages", sent[0].Key)
+	assert.False(t, sent[0].Resolved)
+	assert.Equal(t, "consumer_failure_rate:whatsapp-messages", sent[1].Key)
+
+	// Still over: no repeat. Too few messages to judge the failure rate either way.
+	consumer.stats = queue.ConsumerStats{Topic: "whatsapp-messages", Lag: 4000, Handled: 22, Failed: 15}
+	monitor.Check(context.Background())
+	assert.Len(t, sent, 2)
+
+	consumer.stats = queue.ConsumerStats{Topic: "whatsapp-messages", Lag: 10, Handled: 122, Failed: 16}
+	monitor.Check(context.Background())
+	assert.Len(t, sent, 4)
+	assert.True(t, sent[2].Resolved)
+	assert.True(t, sent[3].Resolved)
+}
+
+// Test the Slack notifier posts the alert text to the webhook
+func TestSlackNotifier(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	err := alerts.NewSlackNotifier(server.URL).Notify(context.Background(), alerts.Alert{Key: "k", Summary: "Consumer lag is high", Value: 5000, Threshold: 1000})
+	assert.NoError(t, err)
+	assert.Contains(t, body["text"], "Consumer lag is high")
+}
@@ -0,0 +1,129 @@
+// test/country_policy_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// MockCountryRuleRepository mocks repository.CountryRuleRepository
+type MockCountryRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockCountryRuleRepository) SetCountryRule(ctx context.Context, rule *domain.CountryRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockCountryRuleRepository) DeleteCountryRule(ctx context.Context, prefix string) (bool, error) {
+	args := m.Called(ctx, prefix)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCountryRuleRepository) ListCountryRules(ctx context.Context) ([]domain.CountryRule, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.CountryRule), args.Error(1)
+}
+
+// Test country calling codes are read from E.164 numbers
+func TestCountryCallingCode(t *testing.T) {
+	tests := map[string]string{
+		"+15551234567":           "1",
+		"whatsapp:+447700900123": "44",
+		"+2348012345678":         "234",
+		"+79161234567":           "7",
+		"+35312345678":           "353",
+	}
+	for phoneNumber, want := range tests {
+		code, ok := domain.CountryCallingCode(phoneNumber)
+		assert.True(t, ok, phoneNumber)
+		assert.Equal(t, want, code, phoneNumber)
+	}
+
+	_, ok := domain.CountryCallingCode("0123")
+	assert.False(t, ok)
+}
+
+// Test the longest matching prefix decides, and runtime rules override configured ones
+func TestCountryPolicyRules(t *testing.T) {
+	mockRepo := new(MockCountryRuleRepository)
+	mockRepo.On("ListCountryRules", mock.Anything).Return([]domain.CountryRule{
+		{Prefix: "1876", Action: domain.CountryActionBlock, Reason: "fraud", Actor: "ops"},
+	}, nil)
+
+	countries := service.NewCountryPolicy(mockRepo, nil, []string{"234"}, new(MockLogger))
+	assert.NoError(t, countries.Refresh(context.Background()))
+
+	assert.NoError(t, countries.Check("+15551234567"))
+	assert.True(t, errors.Is(countries.Check("+18765551234"), domain.ErrFailedPrecondition))
+	assert.True(t, errors.Is(countries.Check("+2348012345678"), domain.ErrFailedPrecondition))
+	assert.Len(t, countries.ListRules(), 2)
+}
+
+// Test that once a prefix is allowed, numbers matching no rule are blocked
+func TestCountryPolicyAllowlist(t *testing.T) {
+	countries := service.NewCountryPolicy(new(MockCountryRuleRepository), []string{"44", "353"}, []string{"4470"}, new(MockLogger))
+
+	assert.NoError(t, countries.Check("+447700900123"))
+	assert.NoError(t, countries.Check("+35312345678"))
+	assert.Error(t, countries.Check("+15551234567"))
+	assert.Error(t, countries.Check("+447012345678"))
+}
+
+// Test sends to blocked countries are refused before anything is stored
+func TestCountryRestrictedSendIsRefused(t *testing.T) {
+	countries := service.NewCountryPolicy(new(MockCountryRuleRepository), nil, []string{"234"}, new(MockLogger))
+	mockRepo := new(MockMessageRepository)
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	svc := service.NewCountryRestrictedMessageService(inner, countries, mockRepo, new(MockLogger))
+
+	_, err := svc.SendTemplateMessage(context.Background(), "+2348012345678", "order_confirmation", nil, "order-1", "customer-1")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+	assert.Contains(t, err.Error(), "+234")
+	mockRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+}
+
+// Test configured rules cannot be removed through the API
+func TestDeleteConfiguredCountryRule(t *testing.T) {
+	mockRepo := new(MockCountryRuleRepository)
+	mockRepo.On("DeleteCountryRule", mock.Anything, "234").Return(false, nil)
+	countries := service.NewCountryPolicy(mockRepo, nil, []string{"234"}, new(MockLogger))
+
+	err := countries.DeleteRule(context.Background(), "+234", "ops")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+}
+
+// Test only admins change country rules through the API
+func TestCountryRulesNeedAdmin(t *testing.T) {
+	mockRepo := new(MockCountryRuleRepository)
+	mockRepo.On("SetCountryRule", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("DeleteCountryRule", mock.Anything, "234").Return(true, nil)
+	mockRepo.On("ListCountryRules", mock.Anything).Return([]domain.CountryRule{}, nil)
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Return(1, nil)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	countries := service.NewAuditedCountryPolicy(service.NewCountryPolicy(mockRepo, nil, nil, logger), service.NewAuditLog(audit, nil, logger), []string{"root"}, logger)
+	asAcme := domain.WithCaller(domain.WithTenantScope(context.Background(), "acme"), "acme-billing")
+	asRoot := domain.WithCaller(domain.WithTenantScope(context.Background(), "ops"), "root")
+	rule := domain.CountryRule{Prefix: "234", Action: domain.CountryActionBlock, Actor: "root"}
+
+	_, err := countries.SetRule(asAcme, rule)
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	assert.ErrorIs(t, countries.DeleteRule(asAcme, "234", "root"), domain.ErrPermissionDenied)
+	mockRepo.AssertNotCalled(t, "SetCountryRule", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "DeleteCountryRule", mock.Anything, mock.Anything)
+
+	_, err = countries.SetRule(asRoot, rule)
+	assert.NoError(t, err)
+	assert.NoError(t, countries.DeleteRule(asRoot, "234", "root"))
+}
@@ -0,0 +1,58 @@
+// test/cta_url_test.go
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/twilio"
+)
+
+// Test CTA URL messages are checked against Meta's limits
+func TestValidateCTAURL(t *testing.T) {
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			meta.CTAURLBody:        "Your order ORD-1 has shipped",
+			meta.CTAURLDisplayText: "Track order",
+			meta.CTAURLLink:        "https://shop.example.com/track/ORD-1",
+		}
+	}
+	assert.NoError(t, meta.ValidateCTAURL(valid()))
+
+	tests := map[string]func(map[string]interface{}){
+		"missing body":      func(p map[string]interface{}) { delete(p, meta.CTAURLBody) },
+		"long display text": func(p map[string]interface{}) { p[meta.CTAURLDisplayText] = strings.Repeat("x", 21) },
+		"relative url":      func(p map[string]interface{}) { p[meta.CTAURLLink] = "/track/ORD-1" },
+		"non-http url":      func(p map[string]interface{}) { p[meta.CTAURLLink] = "javascript:alert(1)" },
+		"long footer":       func(p map[string]interface{}) { p[meta.CTAURLFooter] = strings.Repeat("x", 61) },
+	}
+	for name, change := range tests {
+		parameters := valid()
+		change(parameters)
+		assert.Error(t, meta.ValidateCTAURL(parameters), name)
+	}
+}
+
+// Test button URL parameters must name a button index
+func TestValidateButtonURLParameters(t *testing.T) {
+	assert.Equal(t, "button_url.1", meta.ButtonURLParameter(1))
+	assert.True(t, meta.IsButtonURLParameter("button_url.1"))
+	assert.False(t, meta.IsButtonURLParameter("order_id"))
+
+	assert.NoError(t, meta.ValidateButtonURLParameters(map[string]interface{}{"order_id": "ORD-1", "button_url.0": "ORD-1"}))
+	assert.Error(t, meta.ValidateButtonURLParameters(map[string]interface{}{"button_url.10": "ORD-1"}))
+	assert.Error(t, meta.ValidateButtonURLParameters(map[string]interface{}{"button_url.first": "ORD-1"}))
+}
+
+// Test Twilio refuses CTA URL messages instead of looking up a content template
+func TestTwilioRejectsCTAURL(t *testing.T) {
+	client := twilio.NewClient(twilio.Config{AccountSID: "AC123", AuthToken: "token", APIURL: "http://127.0.0.1:0"}, new(MockLogger))
+
+	_, err := client.SendTemplateMessage(context.Background(), "+15551234567", meta.CTAURLTemplate, map[string]interface{}{
+		meta.CTAURLBody: "hi", meta.CTAURLDisplayText: "Track", meta.CTAURLLink: "https://example.com",
+	})
+	assert.ErrorContains(t, err, "not supported")
+}
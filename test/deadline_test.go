@@ -0,0 +1,107 @@
+// test/deadline_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test calls get the caller's remaining time within the floor and ceiling
+func TestCallTimeoutBounds(t *testing.T) {
+	timeout := utils.CallTimeout{Floor: 50 * time.Millisecond, Ceiling: time.Second}
+
+	// Without a caller deadline the ceiling applies
+	ctx, cancel, err := timeout.Context(context.Background())
+	assert.NoError(t, err)
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 50*time.Millisecond)
+	cancel()
+
+	// A caller deadline sooner than the ceiling is kept
+	caller, cancelCaller := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancelCaller()
+	callerDeadline, _ := caller.Deadline()
+	ctx, cancel, err = timeout.Context(caller)
+	assert.NoError(t, err)
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, callerDeadline, deadline)
+	cancel()
+
+	// Less than the floor left fails without starting the call
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	_, cancel, err = timeout.Context(short)
+	cancel()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, utils.ErrDeadlineTooClose)
+}
+
+// Test a provider request is cut off at the caller's deadline rather than a fixed timeout
+func TestTwilioClientFollowsCallerDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The request context only sees the client go away once the body is read
+		io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := twilio.NewClient(twilio.Config{AccountSID: "AC123", AuthToken: "token", APIURL: server.URL}, new(MockLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.SendTemplateMessage(ctx, "+15551234567", "HX0123456789abcdef", nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// Test a queue write cut off by the deadline leaves the message queued and says so
+func TestSendTemplateMessageQueueDeadline(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("CreateMessage", mock.Anything, mock.Anything).Return(42, nil)
+	producer := new(MockProducer)
+	producer.On("Produce", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+	svc := service.NewMessageService(repo, new(MockWhatsAppClient), producer, newQualityLogger())
+
+	_, err := svc.SendTemplateMessage(context.Background(), "+15551234567", "order_update", nil, "", "")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, domain.ErrorMessage(err, ""), "message 42")
+	assert.Contains(t, domain.ErrorMessage(err, ""), "may still be sent")
+	repo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test a queue write never started for lack of time marks the message failed
+func TestSendTemplateMessageDeadlineTooClose(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("CreateMessage", mock.Anything, mock.Anything).Return(42, nil)
+	repo.On("UpdateMessageStatus", mock.Anything, int64(42), "failed", "", mock.Anything, "").Return(nil)
+	producer := new(MockProducer)
+	producer.On("Produce", mock.Anything, mock.Anything).Return(errors.Join(utils.ErrDeadlineTooClose, context.DeadlineExceeded))
+
+	svc := service.NewMessageService(repo, new(MockWhatsAppClient), producer, newQualityLogger())
+
+	_, err := svc.SendTemplateMessage(context.Background(), "+15551234567", "order_update", nil, "", "")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, domain.ErrorMessage(err, ""), "can be retried")
+	repo.AssertExpectations(t)
+}
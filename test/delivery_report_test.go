@@ -0,0 +1,24 @@
+// test/delivery_report_test.go
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/service"
+)
+
+// Test acknowledged writes stamp enqueued_at and failed ones mark the message failed
+func TestEnqueueReporter(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("MarkMessageEnqueued", mock.Anything, int64(1), mock.Anything).Return(nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(2), "failed", "enqueue_failed", mock.Anything, "").Return(nil)
+
+	report := service.NewEnqueueReporter(mockRepo, new(MockLogger))
+	report(1, nil)
+	report(2, errors.New("leader not available"))
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "MarkMessageEnqueued", mock.Anything, int64(2), mock.Anything)
+}
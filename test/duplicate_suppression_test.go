@@ -0,0 +1,62 @@
+// test/duplicate_suppression_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// Test a repeated send within the window returns the earlier message without sending again
+func TestDuplicateSendCollapsed(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	earlier := &domain.Message{ID: 42, PhoneNumber: "1234567890", TemplateID: "order_shipped", Status: "sent"}
+	mockRepo.On("FindRecentDuplicate", mock.Anything, mock.MatchedBy(func(msg *domain.Message) bool {
+		return msg.TenantID == "acme" && msg.PhoneNumber == "1234567890" && msg.TemplateID == "order_shipped" && msg.Parameters["order"] == "A-1"
+	}), mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) >= 10*time.Minute && time.Since(since) < 11*time.Minute
+	})).Return(earlier, nil)
+
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), mockProducer, new(MockLogger))
+	svc := service.NewDuplicateSuppressingMessageService(inner, mockRepo, 10*time.Minute, newQualityLogger())
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	msg, err := svc.SendTemplateMessage(ctx, "1234567890", "order_shipped", map[string]interface{}{"order": "A-1"}, "A-1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), msg.ID)
+	mockRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+}
+
+// Test sends go through when there is no duplicate or the lookup fails
+func TestDuplicateSendLookupMissOrFailure(t *testing.T) {
+	for name, lookupErr := range map[string]error{
+		"no duplicate":  domain.NewError(domain.ErrNotFound, "message not found"),
+		"lookup failed": errors.New("connection refused"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockProducer := new(MockProducer)
+			mockRepo.On("FindRecentDuplicate", mock.Anything, mock.Anything, mock.Anything).Return(nil, lookupErr)
+			mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(7, nil)
+			mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+
+			inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), mockProducer, new(MockLogger))
+			svc := service.NewDuplicateSuppressingMessageService(inner, mockRepo, 10*time.Minute, newQualityLogger())
+
+			msg, err := svc.SendTemplateMessage(context.Background(), "1234567890", "order_shipped", nil, "", "")
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), msg.ID)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// test/error_detail_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/internal/domain"
+)
+
+// Test provider error codes map to stable categories
+func TestClassifyProviderError(t *testing.T) {
+	tests := []struct {
+		provider  string
+		code      string
+		category  domain.ErrorCategory
+		retryable bool
+	}{
+		{domain.ProviderMeta, "130429", domain.ErrorCategoryRateLimited, true},
+		{domain.ProviderMeta, "132001", domain.ErrorCategoryTemplate, false},
+		{domain.ProviderMeta, "131047", domain.ErrorCategoryRecipient, false},
+		{domain.ProviderMeta, "190", domain.ErrorCategoryAuthentication, false},
+		{domain.ProviderTwilio, "63016", domain.ErrorCategoryRecipient, false},
+		{domain.ProviderTwilio, "20429", domain.ErrorCategoryRateLimited, true},
+		{domain.ProviderMeta, "999999", domain.ErrorCategoryUnknown, false},
+	}
+
+	for _, tt := range tests {
+		category, retryable := domain.ClassifyProviderError(tt.provider, tt.code)
+		assert.Equal(t, tt.category, category, "%s code %s", tt.provider, tt.code)
+		assert.Equal(t, tt.retryable, retryable, "%s code %s", tt.provider, tt.code)
+	}
+}
+
+// Test failures without a provider code are classified as internal
+func TestNewErrorDetailInternal(t *testing.T) {
+	assert.Nil(t, domain.NewErrorDetail(domain.ProviderMeta, "", ""))
+
+	detail := domain.NewErrorDetail(domain.ProviderMeta, "", "Failed to queue message: broker down")
+	assert.Equal(t, domain.ErrorCategoryInternal, detail.Category)
+	assert.False(t, detail.Retryable)
+}
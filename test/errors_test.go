@@ -0,0 +1,40 @@
+// test/errors_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+)
+
+// Test domain errors map to gRPC codes and HTTP statuses, including when wrapped
+func TestErrorMapping(t *testing.T) {
+	cases := []struct {
+		err        error
+		code       codes.Code
+		httpStatus int
+		message    string
+	}{
+		{domain.NewError(domain.ErrNotFound, "message not found"), codes.NotFound, http.StatusNotFound, "message not found"},
+		{fmt.Errorf("lookup: %w", domain.NewError(domain.ErrValidation, "order ID is required")), codes.InvalidArgument, http.StatusBadRequest, "order ID is required"},
+		{domain.WrapError(domain.ErrProviderRateLimited, errors.New("meta 130429"), "provider rate limit reached"), codes.ResourceExhausted, http.StatusTooManyRequests, "provider rate limit reached"},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, http.StatusGatewayTimeout, "context deadline exceeded"},
+		{status.Error(codes.InvalidArgument, "bad timestamp"), codes.InvalidArgument, http.StatusBadRequest, "bad timestamp"},
+		{errors.New("pq: connection refused"), codes.Internal, http.StatusInternalServerError, "failed to get message"},
+	}
+
+	for _, tc := range cases {
+		s := status.Convert(handler.GRPCError(tc.err, "failed to get message"))
+		assert.Equal(t, tc.code, s.Code(), tc.err.Error())
+		assert.Equal(t, tc.message, s.Message())
+		assert.Equal(t, tc.httpStatus, handler.HTTPStatus(tc.err))
+	}
+}
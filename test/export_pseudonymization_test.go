@@ -0,0 +1,80 @@
+// test/export_pseudonymization_test.go
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// exportStream collects the messages of a gRPC export
+type exportStream struct {
+	grpc.ServerStream
+	sent []*pb.MessageResponse
+}
+
+func (s *exportStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *exportStream) Send(msg *pb.MessageResponse) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// newExportedMessageService serves one message whose number is repeated in a parameter and
+// in its content snapshot
+func newExportedMessageService() service.MessageService {
+	repo := new(MockMessageRepository)
+	repo.On("ListMessagesAfterID", mock.Anything, mock.Anything, int64(0), mock.Anything).Return([]*domain.Message{{
+		ID:              7,
+		PhoneNumber:     "+14155550123",
+		TemplateID:      "callback_scheduled",
+		Parameters:      map[string]interface{}{"callback_number": "+1 (415) 555-0123", "agent": "Dana"},
+		Status:          "delivered",
+		ContentSnapshot: `{"messaging_product":"whatsapp","to":"14155550123","template":{"name":"callback_scheduled"}}`,
+	}}, nil)
+	return service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+}
+
+// Test neither export carries the cleartext number once pseudonymization is on, in any field
+func TestExportPseudonymizesEveryField(t *testing.T) {
+	hasher := utils.NewHMACPhoneNumberHasher("secret")
+
+	h := handler.NewGrpcMessageHandler(newExportedMessageService(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{}, hasher, new(MockLogger))
+	stream := &exportStream{}
+	require.NoError(t, h.ExportMessages(&pb.ExportMessagesRequest{}, stream))
+	require.Len(t, stream.sent, 1)
+	record, err := protojson.Marshal(stream.sent[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(record), "4155550123")
+	assert.Equal(t, hasher.Hash("+14155550123"), stream.sent[0].PhoneNumber)
+	assert.Equal(t, hasher.Hash("+1 (415) 555-0123"), stream.sent[0].Parameters["callback_number"])
+	assert.Equal(t, "Dana", stream.sent[0].Parameters["agent"])
+	assert.Empty(t, stream.sent[0].ContentSnapshot)
+
+	gin.SetMode(gin.TestMode)
+	for _, format := range []string{"jsonl", "csv"} {
+		router := gin.New()
+		router.GET("/export/messages", handler.NewExportHandler(newExportedMessageService(), hasher, new(MockLogger)).HandleExport)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export/messages?format="+format, nil))
+		assert.Equal(t, http.StatusOK, w.Code, format)
+		assert.NotContains(t, w.Body.String(), "4155550123", format)
+		assert.Contains(t, w.Body.String(), hasher.Hash("+14155550123"), format)
+	}
+}
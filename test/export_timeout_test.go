@@ -0,0 +1,84 @@
+// test/export_timeout_test.go
+package test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// exportWriteTimeout is the server write timeout the slow export outlives
+const exportWriteTimeout = 200 * time.Millisecond
+
+// newSlowExportService exports three messages one per page, each page taking longer than
+// half the write timeout
+func newSlowExportService() service.MessageService {
+	repo := new(MockMessageRepository)
+	for id := int64(1); id <= 3; id++ {
+		repo.On("ListMessagesAfterID", mock.Anything, mock.Anything, id-1, 1).
+			After(exportWriteTimeout/2).
+			Return([]*domain.Message{{ID: id, PhoneNumber: "+14155550100", TemplateID: "order_confirmation", Status: "sent"}}, nil)
+	}
+	repo.On("ListMessagesAfterID", mock.Anything, mock.Anything, int64(3), 1).Return([]*domain.Message{}, nil)
+	return service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{})
+}
+
+// Test both export endpoints stream to the end when the export outlives the write timeout
+func TestExportOutlivesWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hasher := utils.NewPlainPhoneNumberHasher()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	pb.RegisterWhatsAppServiceServer(grpcServer, handler.NewGrpcMessageHandler(newSlowExportService(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{}, hasher, discardLogger{}))
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gateway, err := handler.NewGatewayHandler(ctx, lis.Addr().String())
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Any("/v1/*path", gin.WrapH(gateway))
+	router.GET("/export/messages", handler.NewExportHandler(newSlowExportService(), hasher, discardLogger{}).HandleExport)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = exportWriteTimeout
+	server.Start()
+	defer server.Close()
+
+	for _, path := range []string{"/v1/messages:export?chunk_size=1", "/export/messages?format=jsonl&chunk_size=1"} {
+		started := time.Now()
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err, path)
+
+		var lines int
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines++
+		}
+		resp.Body.Close()
+
+		assert.NoError(t, scanner.Err(), path)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Equal(t, 3, lines, path)
+		assert.Greater(t, time.Since(started), exportWriteTimeout, path)
+	}
+}
@@ -0,0 +1,63 @@
+// test/external_id_cache_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+)
+
+// Test the LRU evicts the least recently used entry and scopes keys by tenant
+func TestLRUExternalIDCache(t *testing.T) {
+	ctx := context.Background()
+	cache := repository.NewLRUExternalIDCache(2, time.Hour)
+
+	cache.Set(ctx, "tenant-a", "wamid.1", 1)
+	cache.Set(ctx, "tenant-a", "wamid.2", 2)
+	_, _ = cache.Get(ctx, "tenant-a", "wamid.1")
+	cache.Set(ctx, "tenant-a", "wamid.3", 3)
+
+	id, ok := cache.Get(ctx, "tenant-a", "wamid.1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), id)
+	_, ok = cache.Get(ctx, "tenant-a", "wamid.2")
+	assert.False(t, ok)
+	_, ok = cache.Get(ctx, "tenant-b", "wamid.3")
+	assert.False(t, ok)
+}
+
+// Test expired entries are misses
+func TestLRUExternalIDCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := repository.NewLRUExternalIDCache(10, time.Nanosecond)
+	cache.Set(ctx, "tenant-a", "wamid.1", 1)
+	time.Sleep(time.Millisecond)
+
+	_, ok := cache.Get(ctx, "tenant-a", "wamid.1")
+	assert.False(t, ok)
+}
+
+// Test the external ID recorded at send time answers later webhook lookups without the database
+func TestCachedMessageRepositoryFilledOnSend(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(7), "sent", "", "", "wamid.7").Return(nil)
+
+	repo := repository.NewCachedMessageRepository(mockRepo, repository.NewLRUExternalIDCache(10, time.Hour))
+	ctx := domain.WithTenant(context.Background(), "tenant-a")
+	assert.NoError(t, repo.UpdateMessageStatus(ctx, 7, "sent", "", "", "wamid.7"))
+
+	id, err := repo.GetMessageIDByExternalID(context.Background(), "tenant-a", "wamid.7")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+	mockRepo.AssertNotCalled(t, "GetMessageIDByExternalID", mock.Anything, mock.Anything, mock.Anything)
+
+	// Other tenants still go to the database
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-b", "wamid.7").Return(0, domain.NewError(domain.ErrNotFound, "message not found"))
+	_, err = repo.GetMessageIDByExternalID(context.Background(), "tenant-b", "wamid.7")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,96 @@
+// test/failover_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/providerrouter"
+)
+
+// Test sends fail over once the primary's error rate breaches the threshold and fail back after healthy probes
+func TestFailoverAndFailback(t *testing.T) {
+	primary := new(MockWhatsAppClient)
+	primary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("provider down")).Times(4)
+	primary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	secondary := new(MockWhatsAppClient)
+	secondary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	var events []providerrouter.SwitchEvent
+	client := providerrouter.NewFailoverClient(primary, secondary, providerrouter.FailoverConfig{
+		PrimaryName:    "meta",
+		SecondaryName:  "mock",
+		MaxErrorRate:   0.5,
+		Window:         10,
+		MinSamples:     4,
+		ProbeInterval:  20 * time.Millisecond,
+		RecoveryProbes: 2,
+		OnSwitch:       func(event providerrouter.SwitchEvent) { events = append(events, event) },
+	}, mockLogger)
+
+	for i := 0; i < 4; i++ {
+		_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+		assert.Error(t, err)
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "meta", events[0].From)
+		assert.Equal(t, "mock", events[0].To)
+		assert.Equal(t, providerrouter.SwitchReasonErrorRate, events[0].Reason)
+	}
+
+	// New sends go to the secondary until a probe is due
+	_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+	assert.NoError(t, err)
+	secondary.AssertNumberOfCalls(t, "SendTemplateMessage", 1)
+	primary.AssertNumberOfCalls(t, "SendTemplateMessage", 4)
+
+	// Two healthy probes in a row fail back
+	for i := 0; i < 2; i++ {
+		time.Sleep(25 * time.Millisecond)
+		_, err = client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+		assert.NoError(t, err)
+	}
+	primary.AssertNumberOfCalls(t, "SendTemplateMessage", 6)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "meta", events[1].To)
+		assert.Equal(t, providerrouter.SwitchReasonRecovered, events[1].Reason)
+	}
+
+	_, err = client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+	assert.NoError(t, err)
+	primary.AssertNumberOfCalls(t, "SendTemplateMessage", 7)
+}
+
+// Test slow primary sends trigger a failover even when they succeed
+func TestFailoverOnLatency(t *testing.T) {
+	primary := new(MockWhatsAppClient)
+	primary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&meta.MessageResponse{}, nil).After(15 * time.Millisecond)
+	secondary := new(MockWhatsAppClient)
+	secondary.On("SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&meta.MessageResponse{}, nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	client := providerrouter.NewFailoverClient(primary, secondary, providerrouter.FailoverConfig{
+		PrimaryName:   "meta",
+		SecondaryName: "mock",
+		MaxLatency:    5 * time.Millisecond,
+		Window:        3,
+		MinSamples:    3,
+		ProbeInterval: time.Hour,
+	}, mockLogger)
+
+	for i := 0; i < 4; i++ {
+		_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+		assert.NoError(t, err)
+	}
+	primary.AssertNumberOfCalls(t, "SendTemplateMessage", 3)
+	secondary.AssertNumberOfCalls(t, "SendTemplateMessage", 1)
+}
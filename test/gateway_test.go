@@ -0,0 +1,201 @@
+// test/gateway_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// newGatewayServer serves the REST gateway in front of a gRPC server with the tenant and
+// validation interceptors, over a handler reading messages from repo
+func newGatewayServer(t *testing.T, repo *MockMessageRepository) *httptest.Server {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(handler.TenantInterceptor(tenantCredentials), handler.ValidationInterceptor()),
+		grpc.ChainStreamInterceptor(handler.TenantStreamInterceptor(tenantCredentials), handler.ValidationStreamInterceptor()),
+	)
+	svc := service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{})
+	pb.RegisterWhatsAppServiceServer(grpcServer, handler.NewGrpcMessageHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{Provider: "meta"}, utils.NewPlainPhoneNumberHasher(), discardLogger{}))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	gateway, err := handler.NewGatewayHandler(ctx, lis.Addr().String())
+	require.NoError(t, err)
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// gatewayCall makes a request to the gateway as acme's caller, or with headers when given
+func gatewayCall(t *testing.T, server *httptest.Server, method, path, body string, headers map[string]string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if headers == nil {
+		headers = map[string]string{"X-Api-Key": "acme-key"}
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	decoded := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &decoded)
+	}
+	return resp.StatusCode, decoded
+}
+
+// Test gateway routes reach their RPC with path, query and body fields mapped
+func TestGatewayRoutes(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockMessageRepository)
+	repo.On("GetMessageByID", mock.Anything, int64(1)).Return(&domain.Message{ID: 1, PhoneNumber: "+14155550100", TemplateID: "order_confirmation", OrderID: "ORD-1", Status: "delivered", CreatedAt: created, UpdatedAt: created}, nil)
+	repo.On("GetMessagesByOrderID", mock.Anything, "ORD-1").Return([]*domain.Message{{ID: 1, OrderID: "ORD-1", Status: "delivered"}, {ID: 2, OrderID: "ORD-1", Status: "sent"}}, nil)
+	repo.On("ListMessages", mock.Anything, mock.MatchedBy(func(filter domain.MessageFilter) bool {
+		return filter.OrderID == "ORD-1"
+	}), mock.Anything, 5, 10).Return([]*domain.Message{{ID: 3, OrderID: "ORD-1"}}, nil)
+	repo.On("CountMessages", mock.Anything, mock.Anything).Return(11, nil)
+	server := newGatewayServer(t, repo)
+
+	code, body := gatewayCall(t, server, http.MethodGet, "/v1/messages/1", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "1", body["id"])
+	assert.Equal(t, "+14155550100", body["phoneNumber"])
+	assert.Equal(t, "order_confirmation", body["templateId"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", body["createdAtTs"])
+
+	code, body = gatewayCall(t, server, http.MethodGet, "/v1/orders/ORD-1/messages", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, body["messages"], 2)
+
+	code, body = gatewayCall(t, server, http.MethodGet, "/v1/messages?order_id=ORD-1&limit=5&offset=10", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, body["messages"], 1)
+	assert.Equal(t, float64(11), body["totalCount"])
+
+	code, body = gatewayCall(t, server, http.MethodGet, "/v1/service-info", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, handler.APIVersion, body["apiVersion"])
+	assert.Equal(t, "meta", body["provider"])
+}
+
+// Test gateway errors carry the gRPC code and message and the HTTP status the Gin handlers use
+// for the same error
+func TestGatewayErrorMapping(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("GetMessageByID", mock.Anything, int64(404)).Return(nil, domain.NewError(domain.ErrNotFound, "message 404 not found"))
+	repo.On("GetMessageByID", mock.Anything, int64(500)).Return(nil, errors.New("connection reset by peer"))
+	server := newGatewayServer(t, repo)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		code    codes.Code
+		message string
+	}{
+		{"not found", http.MethodGet, "/v1/messages/404", "", nil, codes.NotFound, "message 404 not found"},
+		{"internal error is not leaked", http.MethodGet, "/v1/messages/500", "", nil, codes.Internal, "failed to get message"},
+		{"malformed path parameter", http.MethodGet, "/v1/messages/abc", "", nil, codes.InvalidArgument, ""},
+		{"rule on a path parameter", http.MethodGet, "/v1/messages/-1", "", nil, codes.InvalidArgument, "message_id must be positive"},
+		{"rule on a query parameter", http.MethodGet, "/v1/messages?limit=5000", "", nil, codes.InvalidArgument, "limit must be between 0 and 1000"},
+		{"rule on a body field", http.MethodPost, "/v1/messages", `{"phoneNumber": "call me", "templateId": "order_update"}`, nil, codes.InvalidArgument, "phone_number must be a phone number"},
+		{"malformed body", http.MethodPost, "/v1/messages", `{"phoneNumber":`, nil, codes.InvalidArgument, ""},
+		{"no API key", http.MethodGet, "/v1/messages/1", "", map[string]string{}, codes.Unauthenticated, "missing or unknown x-api-key"},
+		{"other tenant", http.MethodGet, "/v1/messages/1", "", map[string]string{"X-Api-Key": "acme-key", "X-Tenant-Id": "globex"}, codes.PermissionDenied, `API key is not valid for tenant "globex"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := gatewayCall(t, server, tt.method, tt.path, tt.body, tt.headers)
+
+			assert.Equal(t, handler.HTTPStatus(statusError(tt.code)), code)
+			assert.Equal(t, float64(tt.code), body["code"])
+			if tt.message != "" {
+				assert.Equal(t, tt.message, body["message"])
+			}
+		})
+	}
+
+	code, _ := gatewayCall(t, server, http.MethodGet, "/v1/no-such-route", "", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// statusError is an error of the given gRPC code
+func statusError(code codes.Code) error {
+	return status.Error(code, code.String())
+}
+
+// gatewayRoutePattern matches the routes declared in whatapp_gateway.yaml
+var gatewayRoutePattern = regexp.MustCompile(`(?m)^\s+(get|post|put|patch|delete): (\S+)`)
+
+// Test the OpenAPI document is served and describes every gateway route
+func TestOpenAPISpecCoversGatewayRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/openapi.json", handler.HandleOpenAPISpec)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var spec struct {
+		Swagger string                            `json:"swagger"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "2.0", spec.Swagger)
+
+	config, err := os.ReadFile("../proto/whatapp_gateway.yaml")
+	require.NoError(t, err)
+	routes := gatewayRoutePattern.FindAllStringSubmatch(string(config), -1)
+	require.NotEmpty(t, routes)
+	for _, route := range routes {
+		path := regexp.MustCompile(`\{(\w+)\}`).ReplaceAllStringFunc(route[2], func(param string) string {
+			return "{" + lowerCamel(strings.Trim(param, "{}")) + "}"
+		})
+		assert.Contains(t, spec.Paths[path], route[1], "%s %s", route[1], route[2])
+	}
+}
+
+// lowerCamel converts a proto field name to its JSON name
+func lowerCamel(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
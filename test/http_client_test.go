@@ -0,0 +1,127 @@
+// test/http_client_test.go
+package test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/utils"
+)
+
+// newRetryingClient returns an HTTP client retrying quickly, for tests
+func newRetryingClient(attemptTimeout time.Duration) utils.HTTPClient {
+	logger := new(MockLogger)
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	return utils.NewHTTPClientWithConfig(utils.HTTPClientConfig{
+		AttemptTimeout: attemptTimeout,
+		Retry:          utils.Backoff{Attempts: 3, Initial: time.Millisecond, Max: 10 * time.Millisecond, Jitter: 0.5},
+	}, logger)
+}
+
+// Test idempotent requests are retried through transient failures, replaying their body
+func TestHTTPClientRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, server.URL, strings.NewReader("payload"))
+	resp, err := newRetryingClient(0).Do(req)
+
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+}
+
+// Test a POST that reached the server is not sent again
+func TestHTTPClientDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader("{}"))
+	resp, err := newRetryingClient(0).Do(req)
+
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// Test a POST that could not connect is retried, since it was never sent
+func TestHTTPClientRetriesUnsentPost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	logger := new(MockLogger)
+	logger.On("Debug", "Retrying HTTP request", mock.Anything).Times(2)
+	client := utils.NewHTTPClientWithConfig(utils.HTTPClientConfig{
+		Retry: utils.Backoff{Attempts: 3, Initial: time.Millisecond, Max: time.Millisecond},
+	}, logger)
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://"+addr, strings.NewReader("{}"))
+	_, err = client.Do(req)
+
+	assert.Error(t, err)
+	logger.AssertExpectations(t)
+}
+
+// Test a hung attempt is cut off and tried again
+func TestHTTPClientAttemptTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	resp, err := newRetryingClient(100 * time.Millisecond).Do(req)
+
+	if assert.NoError(t, err) {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, readErr, "the body outlives Do")
+		assert.Equal(t, "ok", string(body))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// Test jitter spreads backoff delays around the exponential value
+func TestBackoffJitter(t *testing.T) {
+	b := utils.Backoff{Attempts: 5, Initial: 100 * time.Millisecond, Max: time.Second, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		delay := b.Delay(2)
+		assert.GreaterOrEqual(t, delay, 160*time.Millisecond)
+		assert.LessOrEqual(t, delay, 240*time.Millisecond)
+	}
+}
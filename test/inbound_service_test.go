@@ -0,0 +1,424 @@
+// test/inbound_service_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// Mock conversation repository
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) RecordInbound(ctx context.Context, msg *domain.InboundMessage) (*domain.Conversation, error) {
+	args := m.Called(ctx, msg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) MarkInboundHandled(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) RecentInbound(ctx context.Context, conversationID int64, limit int) ([]domain.InboundMessage, error) {
+	args := m.Called(ctx, conversationID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.InboundMessage), args.Error(1)
+}
+
+func (m *MockConversationRepository) GetInbound(ctx context.Context, tenantID, externalID string) (*domain.InboundMessage, error) {
+	args := m.Called(ctx, tenantID, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InboundMessage), args.Error(1)
+}
+
+func (m *MockConversationRepository) GetConversation(ctx context.Context, tenantID, phoneNumber string) (*domain.Conversation, error) {
+	args := m.Called(ctx, tenantID, phoneNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) GetConversationByID(ctx context.Context, tenantID string, id int64) (*domain.Conversation, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) UpdateHandoff(ctx context.Context, tenantID string, id int64, status, reason, agent string) (*domain.Conversation, error) {
+	args := m.Called(ctx, tenantID, id, status, reason, agent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) EraseConversations(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockConversationRepository) PurgeInboundBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock handoff channel
+type MockHandoffChannel struct {
+	mock.Mock
+}
+
+func (m *MockHandoffChannel) Publish(ctx context.Context, req service.HandoffRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+// Mock inbound service
+type MockInboundService struct {
+	mock.Mock
+}
+
+func (m *MockInboundService) HandleInbound(ctx context.Context, msg domain.InboundMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockInboundService) GetConversation(ctx context.Context, phoneNumber string) (*domain.Conversation, error) {
+	args := m.Called(ctx, phoneNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockInboundService) SendTypingIndicator(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockInboundService) UpdateHandoff(ctx context.Context, conversationID int64, status, agent string) (*domain.Conversation, error) {
+	args := m.Called(ctx, conversationID, status, agent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func newInboundLogger() *MockLogger {
+	logger := new(MockLogger)
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	return logger
+}
+
+// recordInbound makes the repository store msg under the given ID
+func recordInbound(repo *MockConversationRepository, id int64, conversation *domain.Conversation) {
+	repo.On("RecordInbound", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		msg := args.Get(1).(*domain.InboundMessage)
+		msg.ID = id
+		msg.ConversationID = conversation.ID
+	}).Return(conversation, nil)
+}
+
+// Test a new message of a conversation automation owns is handed to an agent with context
+func TestHandleInboundHandsOff(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	conversation := &domain.Conversation{ID: 9, TenantID: "tenant-a", PhoneNumber: "15551234567", ProfileName: "Ada", HandoffStatus: domain.HandoffNone}
+	recordInbound(repo, 3, conversation)
+	repo.On("RecentInbound", mock.Anything, int64(9), 3).Return([]domain.InboundMessage{
+		{ID: 1, ExternalID: "wamid.1", Type: "text", Text: "hi"},
+		{ID: 2, ExternalID: "wamid.2", Type: "text", Text: "where is my order?"},
+		{ID: 3, ExternalID: "wamid.3", Type: "text", Text: "hello??"},
+	}, nil)
+	repo.On("UpdateHandoff", mock.Anything, "tenant-a", int64(9), domain.HandoffPending, service.HandoffReasonUnhandled, "").Return(conversation, nil)
+	repo.On("MarkInboundHandled", mock.Anything, int64(3), mock.Anything).Return(nil)
+
+	var published service.HandoffRequest
+	channel.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published = args.Get(1).(service.HandoffRequest)
+	}).Return(nil)
+
+	svc := service.NewInboundService(repo, channel, 2, newInboundLogger())
+	err := svc.HandleInbound(context.Background(), domain.InboundMessage{
+		TenantID: "tenant-a", ExternalID: "wamid.3", PhoneNumber: "15551234567", Type: "text", Text: "hello??",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), published.ConversationID)
+	assert.Equal(t, service.HandoffReasonUnhandled, published.Reason)
+	assert.Equal(t, domain.HandoffPending, published.HandoffStatus)
+	assert.Equal(t, "hello??", published.Message.Text)
+	assert.Len(t, published.Context, 2)
+	assert.Equal(t, "wamid.1", published.Context[0].ExternalID)
+	repo.AssertExpectations(t)
+}
+
+// Test messages of a conversation an agent has are forwarded without changing its state
+func TestHandleInboundFollowUp(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	conversation := &domain.Conversation{ID: 9, TenantID: "tenant-a", HandoffStatus: domain.HandoffAssigned, HandoffAgent: "alice"}
+	recordInbound(repo, 4, conversation)
+	repo.On("RecentInbound", mock.Anything, int64(9), mock.Anything).Return([]domain.InboundMessage{}, nil)
+	repo.On("MarkInboundHandled", mock.Anything, int64(4), mock.Anything).Return(nil)
+	channel.On("Publish", mock.Anything, mock.MatchedBy(func(req service.HandoffRequest) bool {
+		return req.Reason == service.HandoffReasonFollowUp && req.HandoffStatus == domain.HandoffAssigned
+	})).Return(nil)
+
+	svc := service.NewInboundService(repo, channel, 5, newInboundLogger())
+	assert.NoError(t, svc.HandleInbound(context.Background(), domain.InboundMessage{TenantID: "tenant-a", ExternalID: "wamid.4"}))
+
+	channel.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateHandoff", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test redelivered messages are not handed off twice
+func TestHandleInboundSkipsHandled(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	repo.On("RecordInbound", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.InboundMessage).HandledAt = time.Now()
+	}).Return(&domain.Conversation{ID: 9, HandoffStatus: domain.HandoffPending}, nil)
+
+	svc := service.NewInboundService(repo, channel, 5, newInboundLogger())
+	assert.NoError(t, svc.HandleInbound(context.Background(), domain.InboundMessage{ExternalID: "wamid.3"}))
+
+	channel.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "MarkInboundHandled", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test a failed publish leaves the message unhandled so the redelivery retries it
+func TestHandleInboundPublishFailure(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	recordInbound(repo, 3, &domain.Conversation{ID: 9, HandoffStatus: domain.HandoffNone})
+	repo.On("RecentInbound", mock.Anything, int64(9), mock.Anything).Return([]domain.InboundMessage{}, nil)
+	channel.On("Publish", mock.Anything, mock.Anything).Return(errors.New("helpdesk down"))
+
+	svc := service.NewInboundService(repo, channel, 5, newInboundLogger())
+	assert.Error(t, svc.HandleInbound(context.Background(), domain.InboundMessage{ExternalID: "wamid.3"}))
+
+	repo.AssertNotCalled(t, "MarkInboundHandled", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateHandoff", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test handoff updates are validated and scoped to the caller's tenant
+func TestUpdateHandoff(t *testing.T) {
+	repo := new(MockConversationRepository)
+	repo.On("UpdateHandoff", mock.Anything, "tenant-a", int64(9), domain.HandoffAssigned, "", "alice").
+		Return(&domain.Conversation{ID: 9, HandoffStatus: domain.HandoffAssigned, HandoffAgent: "alice"}, nil)
+	svc := service.NewInboundService(repo, nil, 5, newInboundLogger())
+	ctx := domain.WithTenant(context.Background(), "tenant-a")
+
+	conversation, err := svc.UpdateHandoff(ctx, 9, domain.HandoffAssigned, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", conversation.HandoffAgent)
+
+	_, err = svc.UpdateHandoff(ctx, 9, domain.HandoffAssigned, "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	_, err = svc.UpdateHandoff(ctx, 9, domain.HandoffNone, "alice")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+const testInboundWebhook = `{
+	"object": "whatsapp_business_account",
+	"entry": [{
+		"id": "WABA-1",
+		"changes": [{
+			"value": {
+				"messaging_product": "whatsapp",
+				"metadata": {"display_phone_number": "15550000000", "phone_number_id": "PNID-1"},
+				"contacts": [{"profile": {"name": "Ada"}, "wa_id": "15551234567"}],
+				"messages": [
+					{"from": "15551234567", "id": "wamid.IN1", "timestamp": "1700000000", "type": "text", "text": {"body": "where is my order?"}},
+					{"from": "15551234567", "id": "wamid.IN2", "timestamp": "1700000005", "type": "interactive",
+					 "interactive": {"type": "button_reply", "button_reply": {"id": "talk", "title": "Talk to a person"}}}
+				]
+			}
+		}]
+	}]
+}`
+
+// Test inbound messages in a Meta webhook reach the inbound service
+func TestProcessWebhookInboundMessages(t *testing.T) {
+	inbound := new(MockInboundService)
+	inbound.On("HandleInbound", mock.Anything, domain.InboundMessage{
+		TenantID: "tenant-a", ExternalID: "wamid.IN1", PhoneNumber: "15551234567", ProfileName: "Ada",
+		Type: "text", Text: "where is my order?", ReceivedAt: time.Unix(1700000000, 0),
+	}).Return(nil)
+	inbound.On("HandleInbound", mock.Anything, domain.InboundMessage{
+		TenantID: "tenant-a", ExternalID: "wamid.IN2", PhoneNumber: "15551234567", ProfileName: "Ada",
+		Type: "interactive", Text: "Talk to a person", ReceivedAt: time.Unix(1700000005, 0),
+	}).Return(errors.New("db down"))
+
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Inbound: inbound, Signatures: testWebhookSignatures}, new(MockLogger), "verify-token")
+	err := svc.ProcessWebhook(context.Background(), []byte(testInboundWebhook), signWebhook([]byte(testInboundWebhook)), "https://example.com/webhook")
+
+	assert.ErrorContains(t, err, "db down", "a failed message is reported so the webhook is redelivered")
+	inbound.AssertExpectations(t)
+}
+
+// Test customer numbers are normalized to WhatsApp IDs
+func TestNormalizeCustomerPhone(t *testing.T) {
+	assert.Equal(t, "15551234567", domain.NormalizeCustomerPhone("+15551234567"))
+	assert.Equal(t, "15551234567", domain.NormalizeCustomerPhone("whatsapp:+15551234567"))
+	assert.Equal(t, "15551234567", domain.NormalizeCustomerPhone(" 15551234567 "))
+}
+
+// Test the pipeline offers messages in order until a handler takes one, skipping failing handlers
+func TestInboundPipeline(t *testing.T) {
+	var calls []string
+	handler := func(name string, handled bool, err error) service.InboundHandler {
+		return service.NewInboundHandlerFunc(name, func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+			calls = append(calls, name)
+			return handled, err
+		})
+	}
+	pipeline := service.NewInboundPipeline(newInboundLogger(), service.RecoverInbound())
+	pipeline.Register(
+		handler("rules", false, nil),
+		handler("broken", false, errors.New("bot down")),
+		service.NewInboundHandlerFunc("panics", func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+			calls = append(calls, "panics")
+			panic("nil map")
+		}),
+		handler("llm", true, nil),
+		handler("never", true, nil),
+	)
+
+	handled, err := pipeline.HandleInbound(context.Background(), domain.Conversation{}, domain.InboundMessage{})
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	assert.Equal(t, []string{"rules", "broken", "panics", "llm"}, calls)
+}
+
+// Test middleware wraps handlers outermost first and only applies to later registrations
+func TestInboundPipelineMiddleware(t *testing.T) {
+	var calls []string
+	trace := func(label string) service.InboundMiddleware {
+		return func(next service.InboundHandler) service.InboundHandler {
+			return service.NewInboundHandlerFunc(next.Name(), func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+				calls = append(calls, label+":"+next.Name())
+				return next.HandleInbound(ctx, conversation, msg)
+			})
+		}
+	}
+	pass := func(name string) service.InboundHandler {
+		return service.NewInboundHandlerFunc(name, func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+			_, bounded := ctx.Deadline()
+			calls = append(calls, name+":"+map[bool]string{true: "bounded", false: "unbounded"}[bounded])
+			return false, nil
+		})
+	}
+
+	pipeline := service.NewInboundPipeline(newInboundLogger(), trace("outer"), trace("inner"))
+	pipeline.Register(pass("a"))
+	pipeline.Use(service.WithInboundTimeout(time.Second))
+	pipeline.Register(pass("b"))
+
+	handled, err := pipeline.HandleInbound(context.Background(), domain.Conversation{}, domain.InboundMessage{})
+	assert.NoError(t, err)
+	assert.False(t, handled)
+	assert.Equal(t, 2, pipeline.Len())
+	assert.Equal(t, []string{"outer:a", "inner:a", "a:unbounded", "outer:b", "inner:b", "b:bounded"}, calls)
+}
+
+// Test messages a handler answers are not handed off, and agents' conversations skip handlers
+func TestHandleInboundWithHandler(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	var offered []int64
+	bot := service.NewInboundHandlerFunc("faq", func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+		offered = append(offered, msg.ID)
+		return true, nil
+	})
+	svc := service.NewInboundServiceWithHandler(repo, bot, nil, channel, 5, newInboundLogger())
+
+	recordInbound(repo, 5, &domain.Conversation{ID: 9, HandoffStatus: domain.HandoffResolved})
+	repo.On("MarkInboundHandled", mock.Anything, int64(5), mock.Anything).Return(nil)
+	assert.NoError(t, svc.HandleInbound(context.Background(), domain.InboundMessage{ExternalID: "wamid.5"}))
+	channel.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+
+	repo = new(MockConversationRepository)
+	svc = service.NewInboundServiceWithHandler(repo, bot, nil, channel, 5, newInboundLogger())
+	recordInbound(repo, 6, &domain.Conversation{ID: 9, HandoffStatus: domain.HandoffAssigned, HandoffAgent: "alice"})
+	repo.On("RecentInbound", mock.Anything, int64(9), mock.Anything).Return([]domain.InboundMessage{}, nil)
+	repo.On("MarkInboundHandled", mock.Anything, int64(6), mock.Anything).Return(nil)
+	channel.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	assert.NoError(t, svc.HandleInbound(context.Background(), domain.InboundMessage{ExternalID: "wamid.6"}))
+
+	assert.Equal(t, []int64{5}, offered)
+	channel.AssertNumberOfCalls(t, "Publish", 1)
+}
+
+// Mock typing indicator
+type MockTypingIndicator struct {
+	mock.Mock
+}
+
+func (m *MockTypingIndicator) SendTypingIndicator(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+// Test typing indicators are only shown for the caller tenant's messages
+func TestSendTypingIndicator(t *testing.T) {
+	repo := new(MockConversationRepository)
+	typing := new(MockTypingIndicator)
+	repo.On("GetInbound", mock.Anything, "tenant-a", "wamid.1").Return(&domain.InboundMessage{ID: 1, ExternalID: "wamid.1"}, nil)
+	repo.On("GetInbound", mock.Anything, "tenant-a", "wamid.other").Return(nil, domain.NewError(domain.ErrNotFound, "inbound message not found"))
+	typing.On("SendTypingIndicator", mock.Anything, "wamid.1").Return(nil)
+	ctx := domain.WithTenant(context.Background(), "tenant-a")
+
+	svc := service.NewInboundServiceWithHandler(repo, nil, typing, nil, 5, newInboundLogger())
+	assert.NoError(t, svc.SendTypingIndicator(ctx, "wamid.1"))
+	assert.ErrorIs(t, svc.SendTypingIndicator(ctx, "wamid.other"), domain.ErrNotFound)
+	assert.ErrorIs(t, svc.SendTypingIndicator(ctx, ""), domain.ErrValidation)
+	typing.AssertNumberOfCalls(t, "SendTypingIndicator", 1)
+
+	svc = service.NewInboundService(repo, nil, 5, newInboundLogger())
+	assert.ErrorIs(t, svc.SendTypingIndicator(ctx, "wamid.1"), domain.ErrFailedPrecondition)
+}
+
+// Test the typing indicator is only shown while a handler is slow
+func TestWithTypingIndicator(t *testing.T) {
+	typing := new(MockTypingIndicator)
+	typing.On("SendTypingIndicator", mock.Anything, "wamid.slow").Return(nil).Once()
+	handler := service.NewInboundHandlerFunc("llm", func(ctx context.Context, conversation domain.Conversation, msg domain.InboundMessage) (bool, error) {
+		if msg.ExternalID == "wamid.slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return true, nil
+	})
+	wrapped := service.WithTypingIndicator(typing, 10*time.Millisecond, newInboundLogger())(handler)
+
+	_, err := wrapped.HandleInbound(context.Background(), domain.Conversation{}, domain.InboundMessage{ExternalID: "wamid.fast"})
+	assert.NoError(t, err)
+	_, err = wrapped.HandleInbound(context.Background(), domain.Conversation{}, domain.InboundMessage{ExternalID: "wamid.slow"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "llm", wrapped.Name())
+	typing.AssertExpectations(t)
+	assert.Same(t, handler, service.WithTypingIndicator(nil, time.Second, newInboundLogger())(handler))
+}
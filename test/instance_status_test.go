@@ -0,0 +1,61 @@
+// test/instance_status_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/queue"
+	pb "messaging-microservice/proto"
+)
+
+// Test GetInstanceStatus reports the replica's consumers, partitions and send slots
+func TestGetInstanceStatus(t *testing.T) {
+	readAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	consumer := &fakeConsumer{stats: queue.ConsumerStats{
+		Topic:       "whatsapp-messages",
+		Lag:         12,
+		Handled:     100,
+		Failed:      3,
+		InFlight:    1,
+		Utilization: 0.75,
+		Partitions: []queue.PartitionStats{
+			{Partition: 0, Offset: 41, LastReadAt: readAt},
+			{Partition: 3, Offset: 7, LastReadAt: readAt},
+		},
+	}}
+	sends := handler.NewSendLimiter(4, 10, time.Second, time.Second)
+	assert.True(t, sends.Acquire(context.Background()))
+	defer sends.Release()
+
+	h := handler.NewGrpcMessageHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{
+		MaxInFlightSends: 4,
+		Instance: handler.InstanceInfo{
+			ID:        "whatsapp-1",
+			StartedAt: readAt.Add(-time.Hour),
+			Consumers: []queue.Consumer{consumer},
+			Sends:     sends,
+		},
+	}, nil, new(MockLogger))
+
+	resp, err := h.GetInstanceStatus(context.Background(), &pb.GetInstanceStatusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "whatsapp-1", resp.InstanceId)
+	assert.Equal(t, int32(1), resp.SendsInFlight)
+	assert.Equal(t, int32(4), resp.MaxInFlightSends)
+	assert.Empty(t, resp.LeadingWorkers, "without leader election no worker is reported")
+	assert.Len(t, resp.Consumers, 1)
+
+	status := resp.Consumers[0]
+	assert.Equal(t, "whatsapp-messages", status.Topic)
+	assert.Equal(t, int64(1), status.InFlight)
+	assert.Equal(t, 0.75, status.Utilization)
+	assert.Len(t, status.Partitions, 2)
+	assert.Equal(t, int32(3), status.Partitions[1].Partition)
+	assert.Equal(t, int64(7), status.Partitions[1].Offset)
+	assert.True(t, readAt.Equal(status.Partitions[1].LastReadAt.AsTime()))
+}
@@ -0,0 +1,139 @@
+// test/leader_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+)
+
+// memoryLocker is a LeaderLocker shared by electors in one process
+type memoryLocker struct {
+	mu   sync.Mutex
+	held map[string]*memoryLock
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{held: make(map[string]*memoryLock)}
+}
+
+func (l *memoryLocker) TryLock(ctx context.Context, name string) (repository.HeldLock, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.held[name]; ok {
+		return nil, false, nil
+	}
+	lock := &memoryLock{locker: l, name: name}
+	l.held[name] = lock
+	return lock, true, nil
+}
+
+// drop loses the lock named name, as when its holder's connection drops
+func (l *memoryLocker) drop(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held[name].lost = true
+	delete(l.held, name)
+}
+
+type memoryLock struct {
+	locker *memoryLocker
+	name   string
+	lost   bool
+}
+
+func (l *memoryLock) Check(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	if l.lost {
+		return errors.New("connection closed")
+	}
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	if l.locker.held[l.name] == l {
+		delete(l.locker.held, l.name)
+	}
+	return nil
+}
+
+// Test one instance runs a singleton worker and another takes over once it loses its lock
+func TestLeaderElectorFailover(t *testing.T) {
+	locker := newMemoryLocker()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	var mu sync.Mutex
+	running := map[string]int{}
+	starts := 0
+	worker := func(instance string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			mu.Lock()
+			running[instance]++
+			starts++
+			mu.Unlock()
+			<-ctx.Done()
+			mu.Lock()
+			running[instance]--
+			mu.Unlock()
+		}
+	}
+	runningCount := func() (int, int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return running["a"], running["b"], starts
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := service.NewLeaderElector(locker, "a", 10*time.Millisecond, logger)
+	b := service.NewLeaderElector(locker, "b", 10*time.Millisecond, logger)
+	go a.Run(ctx, "campaign_dispatch", worker("a"))
+	assert.Eventually(t, func() bool { return a.IsLeader("campaign_dispatch") }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"campaign_dispatch"}, a.Leading())
+	go b.Run(ctx, "campaign_dispatch", worker("b"))
+
+	time.Sleep(50 * time.Millisecond)
+	runA, runB, _ := runningCount()
+	assert.Equal(t, 1, runA)
+	assert.Equal(t, 0, runB, "only the lock holder runs the worker")
+
+	locker.drop("campaign_dispatch")
+	assert.Eventually(t, func() bool {
+		runA, runB, starts := runningCount()
+		return starts == 2 && runA+runB == 1 && a.IsLeader("campaign_dispatch") != b.IsLeader("campaign_dispatch")
+	}, time.Second, 5*time.Millisecond, "the worker restarts on one instance")
+}
+
+// Test a worker with nothing to do stops its elector instead of being restarted
+func TestLeaderElectorWorkerFinishes(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	elector := service.NewLeaderElector(newMemoryLocker(), "a", 10*time.Millisecond, logger)
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		elector.Run(context.Background(), "webhook_replay", func(ctx context.Context) { runs++ })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("elector kept running")
+	}
+	assert.Equal(t, 1, runs)
+	assert.False(t, elector.IsLeader("webhook_replay"))
+}
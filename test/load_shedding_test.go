@@ -0,0 +1,46 @@
+// test/load_shedding_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/internal/handler"
+)
+
+// Test SendLimiter sheds requests beyond the in-flight and queue limits
+func TestSendLimiterSheds(t *testing.T) {
+	limiter := handler.NewSendLimiter(1, 0, 10*time.Millisecond, time.Second)
+	ctx := context.Background()
+
+	// First request takes the only slot
+	assert.True(t, limiter.Acquire(ctx))
+	assert.Equal(t, 1, limiter.InFlight())
+
+	// No queue capacity, so the next request is shed
+	assert.False(t, limiter.Acquire(ctx))
+
+	// Releasing the slot lets new requests through again
+	limiter.Release()
+	assert.True(t, limiter.Acquire(ctx))
+	limiter.Release()
+}
+
+// Test SendLimiter lets queued requests through once a slot frees up
+func TestSendLimiterQueues(t *testing.T) {
+	limiter := handler.NewSendLimiter(1, 1, time.Second, time.Second)
+	ctx := context.Background()
+
+	assert.True(t, limiter.Acquire(ctx))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		limiter.Release()
+	}()
+
+	// Waits in the queue until the first request releases its slot
+	assert.True(t, limiter.Acquire(ctx))
+	limiter.Release()
+}
@@ -0,0 +1,159 @@
+// test/log_levels_test.go
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// captureLogger returns a logger with options writing to a file, and a function returning the
+// entries written since it was last called
+func captureLogger(t *testing.T, options utils.LogOptions) (utils.Logger, func() []string) {
+	options.File = filepath.Join(t.TempDir(), "service.log")
+	logger := utils.NewLogger(options)
+
+	read := 0
+	return logger, func() []string {
+		data, err := os.ReadFile(options.File)
+		if err != nil {
+			return nil
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		entries := lines[read:]
+		read = len(lines)
+		return entries
+	}
+}
+
+// Test debug logs are written for the targets enabled only, until they expire
+func TestLogLevelsDebugTargets(t *testing.T) {
+	levels := utils.NewLogLevels("info")
+	logger, written := captureLogger(t, utils.LogOptions{Levels: levels})
+	consumer := utils.WithComponent(logger, "message_consumer")
+
+	logger.Debug("hidden", "phone_number", "+15551234567")
+	consumer.Debug("hidden")
+	logger.Info("shown")
+	assert.Len(t, written(), 1)
+
+	assert.NoError(t, levels.EnableDebug(utils.LogFieldPhoneNumber, "whatsapp:+1 555 123 4567", 0))
+	assert.NoError(t, levels.EnableDebug(utils.LogFieldComponent, "message_consumer", 50*time.Millisecond))
+	logger.Debug("about the number", "phone_number", "+15551234567")
+	logger.Debug("about another number", "phone_number", "+15557654321")
+	consumer.Debug("from the consumer")
+	assert.Len(t, written(), 2)
+
+	time.Sleep(60 * time.Millisecond)
+	consumer.Debug("after expiry")
+	levels.DisableDebug(utils.LogFieldPhoneNumber, "+15551234567")
+	logger.Debug("after disabling", "phone_number", "+15551234567")
+	assert.Empty(t, written())
+	assert.Empty(t, levels.State().Targets)
+
+	assert.Error(t, levels.EnableDebug("tenant_id", "acme", 0))
+	assert.Error(t, levels.SetLevel("verbose", 0))
+	assert.NoError(t, levels.SetLevel("error", 50*time.Millisecond))
+	logger.Warn("below error")
+	assert.Empty(t, written())
+	time.Sleep(60 * time.Millisecond)
+	logger.Warn("reverted to info")
+	assert.Len(t, written(), 1)
+	assert.Equal(t, "info", levels.State().Level)
+}
+
+// Test SetLogLevel changes the replica's level and debug targets, and audits the change
+func TestSetLogLevel(t *testing.T) {
+	audit := new(MockAuditRepository)
+	var entries []domain.AuditEntry
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, *args.Get(1).(*domain.AuditEntry))
+	}).Return(1, nil)
+	logger := newQualityLogger()
+	levels := utils.NewLogLevels("info")
+	h := handler.NewGrpcMessageHandler(nil, nil, nil, nil, nil, nil, nil, nil, service.NewAuditLog(audit, nil, logger), nil, nil, nil, nil, handler.ServiceInfo{
+		Instance: handler.InstanceInfo{ID: "whatsapp-1", Logs: levels},
+	}, utils.NewHMACPhoneNumberHasher("key"), logger)
+	ctx := context.Background()
+
+	resp, err := h.SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: "debug", Duration: durationpb.New(time.Hour), RequestedBy: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "whatsapp-1", resp.InstanceId)
+	assert.Equal(t, "debug", resp.Level)
+	assert.NotNil(t, resp.RevertsAt)
+
+	resp, err = h.SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: "debug", PhoneNumber: "+15551234567", RequestedBy: "alice"})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.DebugTargets, 1) {
+		assert.Equal(t, "+15551234567", resp.DebugTargets[0].PhoneNumber)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), resp.DebugTargets[0].ExpiresAt.AsTime(), time.Minute, "targets expire by default")
+	}
+	_, err = h.SetLogLevel(ctx, &pb.SetLogLevelRequest{PhoneNumber: "+15551234567", RequestedBy: "alice"})
+	assert.NoError(t, err)
+	resp, err = h.GetLogLevel(ctx, &pb.GetLogLevelRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.DebugTargets)
+
+	for _, req := range []*pb.SetLogLevelRequest{
+		{Level: "verbose", RequestedBy: "alice"},
+		{Level: "info", Component: "webhook", RequestedBy: "alice"},
+		{Level: "debug", Component: "webhook", PhoneNumber: "+15551234567", RequestedBy: "alice"},
+	} {
+		_, err = h.SetLogLevel(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, domain.AuditActionSetLogLevel, entries[0].Action)
+		assert.Equal(t, "whatsapp-1", entries[0].Subject)
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.True(t, strings.HasPrefix(entries[1].Subject, "whatsapp-1/phone_number:h:"), "phone numbers are pseudonymized in the audit log")
+	}
+}
+
+// Test repeated info entries are sampled, unlike warnings, and console encoding is plain text
+func TestLoggerSampling(t *testing.T) {
+	logger, written := captureLogger(t, utils.LogOptions{
+		Levels:           utils.NewLogLevels("info"),
+		Encoding:         "console",
+		SampleInitial:    3,
+		SampleThereafter: 5,
+	})
+
+	for i := 0; i < 13; i++ {
+		logger.Info("Received message from Kafka", "offset", i)
+		logger.Warn("Slow handler", "offset", i)
+	}
+	logger.Info("Started components")
+
+	entries := written()
+	var infos, warnings int
+	for _, entry := range entries {
+		assert.False(t, strings.HasPrefix(entry, "{"), "console entries aren't JSON")
+		switch {
+		case strings.Contains(entry, "Received message from Kafka"):
+			infos++
+		case strings.Contains(entry, "Slow handler"):
+			assert.Contains(t, entry, "WARN")
+			warnings++
+		}
+	}
+	assert.Equal(t, 5, infos, "the first 3, then the 8th and 13th")
+	assert.Equal(t, 13, warnings)
+	assert.Contains(t, entries[len(entries)-1], "Started components", "other messages are counted apart")
+}
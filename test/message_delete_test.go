@@ -0,0 +1,117 @@
+// test/message_delete_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// Test soft deletes are audited, and a soft deleted message is no longer found
+func TestDeleteMessageSoft(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("GetMessageByID", mock.Anything, int64(7)).Return(&domain.Message{ID: 7, Status: "sent"}, nil).Once()
+	repo.On("DeleteMessage", mock.Anything, int64(7), false).Return(nil)
+	repo.On("GetMessageByID", mock.Anything, int64(7)).Return(&domain.Message{ID: 7, Status: "sent", DeletedAt: time.Now()}, nil)
+
+	audit := new(MockAuditRepository)
+	var entries []domain.AuditEntry
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, *args.Get(1).(*domain.AuditEntry))
+	}).Return(1, nil)
+
+	logger := newQualityLogger()
+	svc := service.NewAuditedMessageService(service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), logger), service.NewAuditLog(audit, nil, logger), nil, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	assert.NoError(t, svc.DeleteMessage(ctx, 7, false, "alice", "test send"))
+
+	_, err := svc.GetMessageByID(ctx, 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, domain.AuditActionDeleteMessage, entries[0].Action)
+		assert.Equal(t, domain.SubjectMessage, entries[0].SubjectType)
+		assert.Equal(t, "7", entries[0].Subject)
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.Equal(t, "acme", entries[0].TenantID)
+		assert.Nil(t, entries[0].Before, "the deleted content stays out of the audit log")
+	}
+	repo.AssertExpectations(t)
+}
+
+// Test only callers authenticated as admins can hard delete messages, whatever requested_by says
+func TestDeleteMessageHardRequiresAdmin(t *testing.T) {
+	repo := new(MockMessageRepository)
+	repo.On("GetMessageByID", mock.Anything, int64(7)).Return(&domain.Message{ID: 7, Status: "sent"}, nil)
+	repo.On("DeleteMessage", mock.Anything, int64(7), true).Return(nil)
+
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditActionHardDeleteMessage && entry.Actor == "root"
+	})).Return(1, nil)
+
+	logger := newQualityLogger()
+	svc := service.NewAuditedMessageService(service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), logger), service.NewAuditLog(audit, nil, logger), []string{"root"}, logger)
+
+	err := svc.DeleteMessage(domain.WithCaller(context.Background(), "alice"), 7, true, "alice", "")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	err = svc.DeleteMessage(domain.WithCaller(context.Background(), "alice"), 7, true, "root", "")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	err = svc.DeleteMessage(context.Background(), 7, true, "root", "")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied, "unauthenticated requests are never admins")
+	repo.AssertNotCalled(t, "DeleteMessage", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.NoError(t, svc.DeleteMessage(domain.WithCaller(context.Background(), "root"), 7, true, "alice", "mistaken entry"))
+	repo.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+// Test an admin's hard delete, authenticated through gRPC metadata, can't reach another
+// tenant's message
+func TestDeleteMessageHardScopedToTenant(t *testing.T) {
+	table, repo := newMessageTable("acme", "globex")
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Subject == "1" && entry.Actor == "acme-billing" && entry.TenantID == "acme"
+	})).Return(1, nil).Once()
+
+	svc := service.NewAuditedMessageService(service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{}), service.NewAuditLog(audit, nil, discardLogger{}), []string{"acme-billing"}, discardLogger{})
+	h := handler.NewGrpcMessageHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{}, utils.NewPlainPhoneNumberHasher(), discardLogger{})
+	hardDelete := func(id int64) error {
+		_, err := callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+			return h.DeleteMessage(ctx, &pb.DeleteMessageRequest{MessageId: id, HardDelete: true, RequestedBy: "someone-else"})
+		})
+		return err
+	}
+
+	assert.Equal(t, codes.NotFound, status.Code(hardDelete(2)))
+	assert.True(t, table.has(2))
+
+	assert.NoError(t, hardDelete(1))
+	assert.False(t, table.has(1))
+	audit.AssertExpectations(t)
+}
+
+// Test a message deleted while queued is not sent
+func TestProcessQueueMessageDeleted(t *testing.T) {
+	repo := new(MockMessageRepository)
+	whatsapp := new(MockWhatsAppClient)
+	repo.On("GetMessageByID", mock.Anything, int64(3)).Return(&domain.Message{ID: 3, Status: "queued", DeletedAt: time.Now()}, nil)
+
+	svc := service.NewMessageService(repo, whatsapp, new(MockProducer), newQualityLogger())
+
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), []byte(`{"message_id":3}`)))
+	repo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	whatsapp.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,60 @@
+// test/message_search_test.go
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// Test search queries are split into parameter conditions and free text, scoped to the tenant
+func TestSearchMessagesParsesQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		parameters map[string]string
+		text       string
+	}{
+		{"parameter", "parameters.tracking_id = TRK-1", map[string]string{"tracking_id": "TRK-1"}, ""},
+		{"parameter without spaces", "parameters.tracking_id=TRK-1", map[string]string{"tracking_id": "TRK-1"}, ""},
+		{"quoted value", `parameters.name = "Sam Smith" parameters.1=ORD-1`, map[string]string{"name": "Sam Smith", "1": "ORD-1"}, ""},
+		{"free text", "parcel delayed", map[string]string{}, "parcel delayed"},
+		{"both", `parameters.order_id = ORD-1 "out for delivery"`, map[string]string{"order_id": "ORD-1"}, "out for delivery"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMessageRepository)
+			repo.On("SearchMessages", mock.Anything, domain.MessageSearch{
+				Filter:     domain.MessageFilter{TenantID: "acme"},
+				Parameters: tt.parameters,
+				Text:       tt.text,
+			}, 20, 0).Return([]*domain.Message{{ID: 1}}, nil)
+
+			svc := service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+
+			messages, err := svc.SearchMessages(domain.WithTenant(context.Background(), "acme"), tt.query, domain.MessageFilter{}, 20, 0)
+
+			assert.NoError(t, err)
+			assert.Len(t, messages, 1)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// Test malformed queries are rejected before reaching the database
+func TestSearchMessagesRejectsInvalidQuery(t *testing.T) {
+	for _, query := range []string{"", "parameters.tracking_id", "parameters.tracking_id =", "parameters. = x", `"unterminated`} {
+		repo := new(MockMessageRepository)
+		svc := service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+
+		_, err := svc.SearchMessages(context.Background(), query, domain.MessageFilter{}, 20, 0)
+
+		assert.ErrorIs(t, err, domain.ErrValidation, query)
+		repo.AssertNotCalled(t, "SearchMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
@@ -3,13 +3,16 @@ package test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
-	// "time"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
 	"messaging-microservice/internal/service"
 	"messaging-microservice/pkg/meta"
 	// "messaging-microservice/pkg/utils"
@@ -25,6 +28,14 @@ func (m *MockMessageRepository) CreateMessage(ctx context.Context, message *doma
 	return int64(args.Int(0)), args.Error(1)
 }
 
+func (m *MockMessageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) ([]int64, error) {
+	args := m.Called(ctx, messages)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
 func (m *MockMessageRepository) GetMessageByID(ctx context.Context, id int64) (*domain.Message, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -41,16 +52,163 @@ func (m *MockMessageRepository) GetMessageByExternalID(ctx context.Context, exte
 	return args.Get(0).(*domain.Message), args.Error(1)
 }
 
-func (m *MockMessageRepository) ListMessages(ctx context.Context, orderID, customerID, phoneNumber string, limit, offset int) ([]*domain.Message, error) {
-	args := m.Called(ctx, orderID, customerID, phoneNumber, limit, offset)
+func (m *MockMessageRepository) GetTenantMessageByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Message, error) {
+	args := m.Called(ctx, tenantID, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error) {
+	args := m.Called(ctx, tenantID, externalID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetMessagesByIDs(ctx context.Context, ids []int64) ([]*domain.Message, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetMessagesByExternalIDs(ctx context.Context, externalIDs []string) ([]*domain.Message, error) {
+	args := m.Called(ctx, externalIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (map[int64]domain.StatusUpdateResult, error) {
+	args := m.Called(ctx, updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]domain.StatusUpdateResult), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetMessagesByOrderID(ctx context.Context, orderID string) ([]*domain.Message, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindRecentDuplicate(ctx context.Context, message *domain.Message, since time.Time) (*domain.Message, error) {
+	args := m.Called(ctx, message, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListMessages(ctx context.Context, filter domain.MessageFilter, sort domain.MessageSort, limit, offset int) ([]*domain.Message, error) {
+	args := m.Called(ctx, filter, sort, limit, offset)
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListMessagesAfterID(ctx context.Context, filter domain.MessageFilter, afterID int64, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, filter, afterID, limit)
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) SearchMessages(ctx context.Context, search domain.MessageSearch, limit, offset int) ([]*domain.Message, error) {
+	args := m.Called(ctx, search, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountMessages(ctx context.Context, filter domain.MessageFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountMessagesByDay(ctx context.Context, filter domain.MessageFilter) ([]domain.StatusCount, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StatusCount), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetDeliveryLatency(ctx context.Context, filter domain.MessageFilter) ([]domain.StageLatency, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StageLatency), args.Error(1)
+}
+
+func (m *MockMessageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorCode, errorMessage, externalID string) error {
+	args := m.Called(ctx, id, status, errorCode, errorMessage, externalID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) SaveContentSnapshot(ctx context.Context, id int64, snapshot string) error {
+	args := m.Called(ctx, id, snapshot)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) NextStatusSequence(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockMessageRepository) PurgeMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockMessageRepository) EraseMessages(ctx context.Context, filter domain.MessageFilter, hardDelete bool) (int64, error) {
+	args := m.Called(ctx, filter, hardDelete)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockMessageRepository) DeleteMessage(ctx context.Context, id int64, hardDelete bool) error {
+	args := m.Called(ctx, id, hardDelete)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ListMessagesToArchive(ctx context.Context, before time.Time, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkMessagesArchived(ctx context.Context, ids []int64, archiveKey string) error {
+	args := m.Called(ctx, ids, archiveKey)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) HoldMessage(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ReleaseHeldMessages(ctx context.Context, filter domain.MessageFilter, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, filter, limit)
 	return args.Get(0).([]*domain.Message), args.Error(1)
 }
 
-func (m *MockMessageRepository) UpdateMessageStatus(ctx context.Context, id int64, status, errorMessage, externalID string) error {
-	args := m.Called(ctx, id, status, errorMessage, externalID)
+func (m *MockMessageRepository) DeferMessage(ctx context.Context, id int64, until time.Time) error {
+	args := m.Called(ctx, id, until)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) MarkMessageEnqueued(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) ReleaseDueMessages(ctx context.Context, now time.Time, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
 type MockWhatsAppClient struct {
 	mock.Mock
 }
@@ -77,6 +235,11 @@ func (m *MockProducer) Produce(ctx context.Context, value []byte) error {
 	return args.Error(0)
 }
 
+func (m *MockProducer) ProduceWithKey(ctx context.Context, key, value []byte) error {
+	args := m.Called(ctx, key, value)
+	return args.Error(0)
+}
+
 func (m *MockProducer) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -107,6 +270,13 @@ func (m *MockLogger) Fatal(msg string, keysAndValues ...interface{}) {
 	m.Called(msg, keysAndValues)
 }
 
+// newDebugLogger returns a logger that allows debug logs only
+func newDebugLogger() *MockLogger {
+	logger := new(MockLogger)
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	return logger
+}
+
 // Test SendTemplateMessage
 func TestSendTemplateMessage(t *testing.T) {
 	// Create mocks
@@ -193,4 +363,312 @@ func TestSendTemplateMessageRepositoryError(t *testing.T) {
 	// Verify mock expectations
 	mockRepo.AssertExpectations(t)
 	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
-}
\ No newline at end of file
+}
+// Test ListMessages returns the total count from the repository
+func TestListMessagesTotalCount(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+
+	// Create test data
+	filter := domain.MessageFilter{
+		OrderID: "ORD-12345",
+		Status:  "failed",
+	}
+	messages := []*domain.Message{
+		{ID: 1, OrderID: "ORD-12345", Status: "failed"},
+		{ID: 2, OrderID: "ORD-12345", Status: "failed"},
+	}
+
+	// Set up mock expectations
+	mockRepo.On("ListMessages", mock.Anything, filter, domain.MessageSort{}, 2, 0).Return(messages, nil)
+	mockRepo.On("CountMessages", mock.Anything, filter).Return(7, nil)
+
+	// Create service
+	svc := service.NewMessageService(mockRepo, mockWhatsApp, mockProducer, mockLogger)
+
+	// Test
+	result, total, err := svc.ListMessages(context.Background(), filter, domain.MessageSort{}, 2, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 7, total)
+
+	// Verify mock expectations
+	mockRepo.AssertExpectations(t)
+}
+
+// Test ExportMessages pages through the repository with a keyset cursor
+func TestExportMessagesChunks(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+
+	filter := domain.MessageFilter{TemplateID: "order_confirmation"}
+
+	// Set up mock expectations: two full chunks followed by a partial one
+	mockRepo.On("ListMessagesAfterID", mock.Anything, filter, int64(0), 2).Return([]*domain.Message{{ID: 1}, {ID: 2}}, nil)
+	mockRepo.On("ListMessagesAfterID", mock.Anything, filter, int64(2), 2).Return([]*domain.Message{{ID: 5}, {ID: 8}}, nil)
+	mockRepo.On("ListMessagesAfterID", mock.Anything, filter, int64(8), 2).Return([]*domain.Message{{ID: 9}}, nil)
+
+	// Create service
+	svc := service.NewMessageService(mockRepo, mockWhatsApp, mockProducer, mockLogger)
+
+	// Test
+	var exported []int64
+	err := svc.ExportMessages(context.Background(), filter, 2, func(messages []*domain.Message) error {
+		for _, msg := range messages {
+			exported = append(exported, msg.ID)
+		}
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 5, 8, 9}, exported)
+
+	// Verify mock expectations
+	mockRepo.AssertExpectations(t)
+}
+
+// Test a message whose TTL elapsed while queued is expired instead of sent
+func TestProcessQueueMessageExpired(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockLogger := new(MockLogger)
+
+	stale := &domain.Message{ID: 3, PhoneNumber: "+1234567890", TemplateID: "delivery_eta", Status: "queued", ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("GetMessageByID", mock.Anything, int64(3)).Return(stale, nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(3), domain.StatusExpired, "", mock.Anything, "").Return(nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	svc := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), mockLogger)
+
+	err := svc.ProcessQueueMessage(context.Background(), []byte(`{"message_id":3}`))
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockWhatsApp.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test the expiry given with a send is stored with the message
+func TestSendTemplateMessageWithExpiry(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+
+	expiresAt := time.Now().Add(time.Hour)
+	mockRepo.On("CreateMessage", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool {
+		return m.ExpiresAt.Equal(expiresAt)
+	})).Return(1, nil)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), mockProducer, mockLogger)
+
+	ctx := domain.WithExpiresAt(context.Background(), expiresAt)
+	msg, err := svc.SendTemplateMessage(ctx, "+1234567890", "delivery_eta", nil, "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expiresAt, msg.ExpiresAt)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test a retry is stored as the next attempt of the failed message with the corrections applied
+func TestRetryMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+
+	failed := &domain.Message{
+		ID: 9, PhoneNumber: "+1234567890", TemplateID: "order_confirmation", TenantID: "acme", CustomerID: "CUST-1",
+		Parameters: map[string]interface{}{"order_id": "ORD-1", "name": "Sam"}, Status: "failed", Attempt: 1,
+	}
+	mockRepo.On("GetMessageByID", mock.Anything, int64(9)).Return(failed, nil)
+	mockRepo.On("CreateMessage", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool {
+		return m.RetryOfID == 9 && m.Attempt == 2 && m.PhoneNumber == "+1234567891" && m.TenantID == "acme" &&
+			m.Parameters["order_id"] == "ORD-2" && m.Parameters["name"] == "Sam" && m.Status == "queued"
+	})).Return(10, nil)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), mockProducer, mockLogger)
+
+	msg, err := svc.RetryMessage(context.Background(), 9, "+1234567891", map[string]interface{}{"order_id": "ORD-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), msg.ID)
+	assert.Equal(t, int64(9), msg.RetryOfID)
+	mockRepo.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+// Test only undelivered messages can be retried
+func TestRetryMessageRejectsDeliveredMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(9)).Return(&domain.Message{ID: 9, Status: "delivered"}, nil)
+
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+
+	_, err := svc.RetryMessage(context.Background(), 9, "", nil)
+
+	assert.ErrorIs(t, err, domain.ErrFailedPrecondition)
+	mockRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+}
+
+// Test listings can only be sorted by whitelisted columns, before any query is sent
+func TestListMessagesRejectsUnknownSort(t *testing.T) {
+	repo := repository.NewMessageRepository(&sqlx.DB{}, new(MockLogger))
+
+	for _, sort := range []domain.MessageSort{
+		{By: "phone_number"},
+		{By: "created_at; DROP TABLE messages"},
+		{By: "status", Order: "sideways"},
+	} {
+		_, err := repo.ListMessages(context.Background(), domain.MessageFilter{}, sort, 10, 0)
+		assert.ErrorIs(t, err, domain.ErrValidation, sort.By)
+	}
+}
+
+// Test a batch lookup returns the messages found in the order asked for, without deleted ones
+func TestGetMessagesKeepsRequestOrder(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("GetMessagesByIDs", mock.Anything, []int64{3, 1, 2, 4, 3}).Return([]*domain.Message{
+		{ID: 1}, {ID: 2, DeletedAt: time.Now()}, {ID: 3},
+	}, nil)
+	mockRepo.On("GetMessagesByExternalIDs", mock.Anything, []string{"wamid.B", "wamid.A"}).Return([]*domain.Message{
+		{ID: 1, ExternalID: "wamid.A"}, {ID: 2, ExternalID: "wamid.B"},
+	}, nil)
+
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+
+	messages, err := svc.GetMessages(context.Background(), []int64{3, 1, 2, 4, 3})
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, int64(3), messages[0].ID)
+		assert.Equal(t, int64(1), messages[1].ID)
+	}
+
+	messages, err = svc.GetMessagesByExternalIDs(context.Background(), []string{"wamid.B", "wamid.A"})
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "wamid.B", messages[0].ExternalID)
+		assert.Equal(t, "wamid.A", messages[1].ExternalID)
+	}
+}
+
+// Test batch lookups are bounded
+func TestGetMessagesRejectsOversizedBatch(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+
+	_, err := svc.GetMessages(context.Background(), make([]int64, service.MaxBatchLookup+1))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	_, err = svc.GetMessagesByExternalIDs(context.Background(), nil)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	mockRepo.AssertNotCalled(t, "GetMessagesByIDs", mock.Anything, mock.Anything)
+}
+
+// Test the external ID is stored before anything else once the provider accepts a message, and
+// its write is retried
+func TestProcessQueueMessageRecordsExternalIDFirst(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockLogger := newDebugLogger()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	var calls []string
+	record := func(args mock.Arguments) { calls = append(calls, args.String(2)) }
+	mockRepo.On("GetMessageByID", mock.Anything, int64(5)).Return(&domain.Message{ID: 5, PhoneNumber: "+1234567890", TemplateID: "welcome", Status: "queued"}, nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(5), "processing", "", "", "").Return(nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(5), "sent", "", "", "wamid.5").Run(record).Return(errors.New("connection reset")).Once()
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(5), "sent", "", "", "wamid.5").Run(record).Return(nil).Once()
+	mockRepo.On("SaveContentSnapshot", mock.Anything, int64(5), `{"to":"+1234567890"}`).Run(func(args mock.Arguments) {
+		calls = append(calls, "snapshot")
+	}).Return(nil)
+
+	var resp meta.MessageResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"messages": [{"id": "wamid.5"}]}`), &resp))
+	resp.RequestPayload = []byte(`{"to":"+1234567890"}`)
+	mockWhatsApp.On("SendTemplateMessage", mock.Anything, "+1234567890", "welcome", mock.Anything).Return(&resp, nil)
+
+	svc := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), mockLogger)
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), []byte(`{"message_id":5}`)))
+	assert.Equal(t, []string{"sent", "sent", "snapshot"}, calls)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test a payload delivered twice, as after a failed outbox mark or a redelivery, sends the
+// message once
+func TestProcessQueueMessageDeliveredTwice(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockLogger := newInboundLogger()
+
+	stored := &domain.Message{ID: 5, PhoneNumber: "+1234567890", TemplateID: "welcome", Status: "queued"}
+	mockRepo.On("GetMessageByID", mock.Anything, int64(5)).Return(stored, nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(5), mock.Anything, "", "", mock.Anything).Run(func(args mock.Arguments) {
+		stored.Status, stored.ExternalID = args.String(2), args.String(5)
+	}).Return(nil)
+	mockRepo.On("SaveContentSnapshot", mock.Anything, int64(5), mock.Anything).Return(nil)
+
+	var resp meta.MessageResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"messages": [{"id": "wamid.5"}]}`), &resp))
+	mockWhatsApp.On("SendTemplateMessage", mock.Anything, "+1234567890", "welcome", mock.Anything).Return(&resp, nil)
+
+	svc := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), mockLogger)
+	data := []byte(`{"message_id":5}`)
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+
+	mockWhatsApp.AssertNumberOfCalls(t, "SendTemplateMessage", 1)
+	assert.Equal(t, "sent", stored.Status)
+}
+
+// Test which statuses a queued payload is still sent in
+func TestProcessQueueMessageSkipsFinishedMessages(t *testing.T) {
+	tests := []struct {
+		status     string
+		externalID string
+		sent       bool
+	}{
+		{"queued", "", true},
+		{domain.StatusRetrying, "", true},
+		{"processing", "", true},
+		{"processing", "wamid.5", false},
+		{"sent", "wamid.5", false},
+		{"delivered", "wamid.5", false},
+		{"failed", "", false},
+		{domain.StatusExpired, "", false},
+		{domain.StatusQuotaExceeded, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.externalID, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockWhatsApp := new(MockWhatsAppClient)
+			mockRepo.On("GetMessageByID", mock.Anything, int64(5)).Return(&domain.Message{ID: 5, PhoneNumber: "+1234567890", TemplateID: "welcome", Status: tt.status, ExternalID: tt.externalID}, nil)
+			mockRepo.On("UpdateMessageStatus", mock.Anything, int64(5), mock.Anything, "", "", mock.Anything).Return(nil)
+			mockRepo.On("SaveContentSnapshot", mock.Anything, int64(5), mock.Anything).Return(nil)
+			var resp meta.MessageResponse
+			assert.NoError(t, json.Unmarshal([]byte(`{"messages": [{"id": "wamid.5"}]}`), &resp))
+			mockWhatsApp.On("SendTemplateMessage", mock.Anything, "+1234567890", "welcome", mock.Anything).Return(&resp, nil)
+
+			svc := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), newInboundLogger())
+			assert.NoError(t, svc.ProcessQueueMessage(context.Background(), []byte(`{"message_id":5}`)))
+
+			if tt.sent {
+				mockWhatsApp.AssertNumberOfCalls(t, "SendTemplateMessage", 1)
+			} else {
+				mockWhatsApp.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockRepo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// test/message_stats_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// Test counts are grouped into day/template buckets with a summary and rates
+func TestGetMessageStats(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	day1 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	filter := domain.MessageFilter{CreatedAfter: day1, CreatedBefore: day1.AddDate(0, 0, 7)}
+
+	mockRepo.On("CountMessagesByDay", mock.Anything, filter).Return([]domain.StatusCount{
+		{Day: day1, TemplateID: "order_confirmation", Status: "delivered", Count: 6},
+		{Day: day1, TemplateID: "order_confirmation", Status: "failed", Count: 1},
+		{Day: day1, TemplateID: "order_confirmation", Status: "read", Count: 3},
+		{Day: day2, TemplateID: "order_confirmation", Status: "read", Count: 10},
+	}, nil)
+
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	stats, err := svc.GetMessageStats(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, stats.Buckets, 2)
+	assert.Equal(t, int64(10), stats.Buckets[0].Total)
+	assert.InDelta(t, 0.9, stats.Buckets[0].DeliveredRate(), 1e-9)
+	assert.InDelta(t, 0.3, stats.Buckets[0].ReadRate(), 1e-9)
+	assert.InDelta(t, 0.1, stats.Buckets[0].FailureRate(), 1e-9)
+	assert.Equal(t, int64(20), stats.Summary.Total)
+	assert.Equal(t, int64(13), stats.Summary.StatusCounts["read"])
+}
+
+// Test the time range must be bounded on both ends and not too long
+func TestGetMessageStatsValidation(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	now := time.Now()
+
+	for _, filter := range []domain.MessageFilter{
+		{CreatedAfter: now},
+		{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)},
+		{CreatedAfter: now.AddDate(-2, 0, 0), CreatedBefore: now},
+	} {
+		_, err := svc.GetMessageStats(context.Background(), filter)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	}
+	mockRepo.AssertNotCalled(t, "CountMessagesByDay", mock.Anything, mock.Anything)
+}
+
+// Test delivery latency is reported per stage for a bounded range
+func TestGetDeliveryLatency(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	now := time.Now()
+	filter := domain.MessageFilter{CreatedAfter: now.Add(-24 * time.Hour), CreatedBefore: now}
+
+	expected := []domain.StageLatency{
+		{Stage: domain.StageSent, Count: 100, P50: time.Second, P95: 3 * time.Second},
+		{Stage: domain.StageDelivered, Count: 95, P50: 4 * time.Second, P95: 40 * time.Second},
+		{Stage: domain.StageRead, Count: 60, P50: 10 * time.Minute, P95: 6 * time.Hour},
+	}
+	mockRepo.On("GetDeliveryLatency", mock.Anything, filter).Return(expected, nil)
+
+	latencies, err := svc.GetDeliveryLatency(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, latencies)
+
+	_, err = svc.GetDeliveryLatency(context.Background(), domain.MessageFilter{CreatedBefore: now})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
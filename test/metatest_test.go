@@ -0,0 +1,133 @@
+// test/metatest_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/meta/metatest"
+)
+
+// newMetatestClient returns a Meta client talking to server
+func newMetatestClient(server *metatest.Server) meta.Client {
+	logger := new(MockLogger)
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	return meta.NewClient("PNID-1", "token-1", server.AppSecret, logger, meta.WithAPIURL(server.APIURL(meta.DefaultAPIVersion)))
+}
+
+// Test sends are accepted like the Cloud API does and recorded as sent
+func TestMetatestServerAcceptsSends(t *testing.T) {
+	server := metatest.NewServer("secret")
+	defer server.Close()
+	client := newMetatestClient(server)
+
+	server.Enqueue(metatest.Accepted("wamid.FIXED"))
+	resp, err := client.SendTemplateMessage(context.Background(), "whatsapp:+15551234567", "order_confirmation", map[string]interface{}{"1": "ORD-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "wamid.FIXED", resp.Messages[0].ID)
+	assert.Equal(t, "15551234567", resp.Contacts[0].WaID)
+
+	resp, err = client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "wamid.metatest-1", resp.Messages[0].ID, "unscripted sends get generated IDs")
+
+	requests := server.Requests()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, meta.DefaultAPIVersion, requests[0].Version)
+	assert.Equal(t, "/PNID-1/messages", requests[0].Path)
+	assert.Equal(t, "token-1", requests[0].Token)
+	payload, err := requests[0].Payload()
+	assert.NoError(t, err)
+	assert.Equal(t, "+15551234567", payload["to"])
+	assert.Equal(t, "template", payload["type"])
+}
+
+// Test scripted errors reach callers as the API errors Meta returns
+func TestMetatestServerErrors(t *testing.T) {
+	server := metatest.NewServer("secret")
+	defer server.Close()
+	client := newMetatestClient(server)
+
+	server.Enqueue(metatest.RateLimited(30*time.Second), metatest.InvalidToken(), metatest.Undeliverable())
+	for _, want := range []struct{ status, code int }{
+		{http.StatusTooManyRequests, 130429},
+		{http.StatusUnauthorized, 190},
+		{http.StatusBadRequest, 131026},
+	} {
+		_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+		var apiErr *meta.APIError
+		if assert.True(t, errors.As(err, &apiErr)) {
+			assert.Equal(t, want.status, apiErr.StatusCode)
+			assert.Equal(t, want.code, apiErr.Code)
+		}
+	}
+	assert.Len(t, server.Requests(), 3, "sends are not retried")
+
+	server.Default(metatest.Unavailable())
+	_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+	assert.Error(t, err)
+
+	server.Reset()
+	_, err = client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", nil)
+	assert.NoError(t, err)
+	assert.Len(t, server.Requests(), 1)
+}
+
+// Test delayed responses run into the caller's deadline
+func TestMetatestServerDelay(t *testing.T) {
+	server := metatest.NewServer("secret")
+	defer server.Close()
+	client := newMetatestClient(server)
+
+	server.Enqueue(metatest.Response{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err := client.SendTemplateMessage(ctx, "+15551234567", "order_confirmation", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Len(t, server.Requests(), 1, "the request reached the server")
+}
+
+// Test template lookups find the templates added to the server
+func TestMetatestServerTemplates(t *testing.T) {
+	server := metatest.NewServer("secret")
+	defer server.Close()
+	server.AddTemplates(
+		meta.TemplateDefinition{Name: "order_shipped", Language: "en_US", Status: "APPROVED", Category: "UTILITY"},
+		meta.TemplateDefinition{Name: "order_shipped_v2", Language: "en_US", Status: "APPROVED", Category: "UTILITY"},
+	)
+	source := newMetatestClient(server).(meta.TemplateSource)
+
+	tmpl, err := source.GetTemplate(context.Background(), "WABA-1", "order_shipped", "en_US")
+	assert.NoError(t, err)
+	assert.Equal(t, "order_shipped", tmpl.Name)
+
+	_, err = source.GetTemplate(context.Background(), "WABA-1", "order_shipped", "es")
+	assert.True(t, errors.Is(err, meta.ErrTemplateNotFound))
+	assert.Equal(t, "/WABA-1/message_templates", server.Requests()[0].Path)
+}
+
+// Test webhooks built by the server carry signatures the client accepts
+func TestMetatestWebhookSignature(t *testing.T) {
+	server := metatest.NewServer("secret")
+	defer server.Close()
+	client := newMetatestClient(server)
+
+	body := metatest.StatusWebhook("PNID-1", metatest.Status{ExternalID: "wamid.ABC", Recipient: "15551234567", Status: "failed", ErrorCode: 131026, ErrorMessage: "Message undeliverable"})
+	req, err := server.WebhookRequest(context.Background(), "http://localhost/webhook/meta", body)
+	assert.NoError(t, err)
+	signature := req.Header.Get(metatest.SignatureHeader)
+	assert.True(t, client.ValidateWebhookSignature(signature, req.URL.String(), body))
+	assert.False(t, client.ValidateWebhookSignature(signature, req.URL.String(), append(body, ' ')))
+	assert.False(t, client.ValidateWebhookSignature(metatest.Sign("other", body), req.URL.String(), body))
+}
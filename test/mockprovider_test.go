@@ -0,0 +1,83 @@
+// test/mockprovider_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/mockprovider"
+)
+
+// Test the mock provider returns an external ID and posts signed status webhooks
+func TestMockProviderEmitsWebhooks(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+	type received struct {
+		status    string
+		valid     bool
+		phoneNbID string
+	}
+	webhooks := make(chan received, 2)
+
+	var whatsappClient meta.Client
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload struct {
+			Entry []struct {
+				Changes []struct {
+					Value struct {
+						Metadata struct {
+							PhoneNumberID string `json:"phone_number_id"`
+						} `json:"metadata"`
+						Statuses []struct {
+							Status string `json:"status"`
+						} `json:"statuses"`
+					} `json:"value"`
+				} `json:"changes"`
+			} `json:"entry"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		value := payload.Entry[0].Changes[0].Value
+		webhooks <- received{
+			status:    value.Statuses[0].Status,
+			valid:     whatsappClient.ValidateWebhookSignature(r.Header.Get("X-Hub-Signature-256"), "", body),
+			phoneNbID: value.Metadata.PhoneNumberID,
+		}
+	}))
+	defer server.Close()
+
+	whatsappClient = mockprovider.NewClient(mockprovider.Config{
+		PhoneNumberID:  "mock-phone",
+		AppSecret:      "secret",
+		WebhookURL:     server.URL,
+		DeliveredDelay: 10 * time.Millisecond,
+		ReadDelay:      30 * time.Millisecond,
+	}, mockLogger)
+
+	resp, err := whatsappClient.SendTemplateMessage(context.Background(), "+1234567890", "order_confirmation", nil)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Messages, 1)
+	assert.NotEmpty(t, resp.Messages[0].ID)
+	assert.NotEmpty(t, resp.RequestPayload)
+
+	for _, want := range []string{"delivered", "read"} {
+		select {
+		case got := <-webhooks:
+			assert.Equal(t, want, got.status)
+			assert.True(t, got.valid)
+			assert.Equal(t, "mock-phone", got.phoneNbID)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s webhook", want)
+		}
+	}
+}
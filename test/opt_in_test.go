@@ -0,0 +1,121 @@
+// test/opt_in_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// MockOptInRepository mocks repository.OptInRepository
+type MockOptInRepository struct {
+	mock.Mock
+}
+
+func (m *MockOptInRepository) RecordOptIn(ctx context.Context, event *domain.OptInEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOptInRepository) ListOptIns(ctx context.Context, tenantID, phoneNumber string) ([]domain.OptInEvent, error) {
+	args := m.Called(ctx, tenantID, phoneNumber)
+	events, _ := args.Get(0).([]domain.OptInEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockOptInRepository) LatestOptIn(ctx context.Context, tenantID, phoneNumber string) (*domain.OptInEvent, error) {
+	args := m.Called(ctx, tenantID, phoneNumber)
+	event, _ := args.Get(0).(*domain.OptInEvent)
+	return event, args.Error(1)
+}
+
+func (m *MockOptInRepository) EraseOptIns(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Test opt-ins are stored for the caller tenant with a normalized phone number, and need proof
+func TestRecordOptIn(t *testing.T) {
+	repo := new(MockOptInRepository)
+	repo.On("RecordOptIn", mock.Anything, mock.MatchedBy(func(event *domain.OptInEvent) bool {
+		return event.TenantID == "acme" && event.PhoneNumber == "15551234567" && !event.OccurredAt.IsZero()
+	})).Return(nil).Once()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	optIns := service.NewOptInService(repo, []string{"spring_sale"}, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	event := domain.OptInEvent{
+		PhoneNumber: "+1 555 123 4567",
+		Action:      domain.OptInActionOptIn,
+		Source:      "checkout",
+		Channel:     "web",
+		Evidence:    "Send me offers on WhatsApp",
+		Actor:       "storefront",
+	}
+	_, err := optIns.Record(ctx, event)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+
+	event.Evidence = ""
+	_, err = optIns.Record(ctx, event)
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	event.Evidence, event.OccurredAt = "Send me offers", time.Now().Add(time.Hour)
+	_, err = optIns.Record(ctx, event)
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+}
+
+// Test marketing sends need the contact's latest event to be an opt-in
+func TestOptInRequiredSendIsRefused(t *testing.T) {
+	repo := new(MockOptInRepository)
+	repo.On("LatestOptIn", mock.Anything, "acme", "15551234567").Return(nil, nil).Once()
+	repo.On("LatestOptIn", mock.Anything, "acme", "15551234567").Return(&domain.OptInEvent{Action: domain.OptInActionOptOut, OccurredAt: time.Now()}, nil).Once()
+	optIns := service.NewOptInService(repo, []string{"spring_sale"}, new(MockLogger))
+
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(7, nil).Once()
+	mockProducer := new(MockProducer)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockProducer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Maybe()
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), mockProducer, logger)
+	svc := service.NewOptInRequiredMessageService(inner, optIns, mockRepo, logger)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	_, err := svc.SendTemplateMessage(ctx, "+15551234567", "spring_sale", nil, "", "customer-1")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+	_, err = svc.SendTemplateMessage(ctx, "+15551234567", "spring_sale", nil, "", "customer-1")
+	assert.ErrorContains(t, err, "opted out")
+
+	// Other templates don't need an opt-in
+	_, err = svc.SendTemplateMessage(ctx, "+15551234567", "order_confirmation", nil, "order-1", "customer-1")
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// Test a queued marketing message to a contact who opted out since is failed unsent
+func TestOptInRequiredDropsQueuedMessage(t *testing.T) {
+	repo := new(MockOptInRepository)
+	repo.On("LatestOptIn", mock.Anything, "acme", "15551234567").Return(&domain.OptInEvent{Action: domain.OptInActionOptOut, OccurredAt: time.Now()}, nil)
+	optIns := service.NewOptInService(repo, []string{"spring_sale"}, new(MockLogger))
+
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(7), "failed", "", mock.Anything, "").Return(nil).Once()
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	client := new(MockWhatsAppClient)
+	svc := service.NewOptInRequiredMessageService(service.NewMessageService(mockRepo, client, new(MockProducer), logger), optIns, mockRepo, logger)
+
+	data, _ := service.EncodeQueueMessage(service.QueueMessage{MessageID: 7, TenantID: "acme", PhoneNumber: "+15551234567", TemplateID: "spring_sale"})
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+	mockRepo.AssertExpectations(t)
+	client.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
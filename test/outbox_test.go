@@ -0,0 +1,159 @@
+// test/outbox_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+)
+
+// stubUnitOfWork runs the work without a database; err fails the commit
+type stubUnitOfWork struct {
+	err   error
+	calls int
+}
+
+func (u *stubUnitOfWork) Begin(ctx context.Context) (context.Context, *sqlx.Tx, error) {
+	return ctx, nil, nil
+}
+
+func (u *stubUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.calls++
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return u.err
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) AddOutboxMessage(ctx context.Context, msg *domain.OutboxMessage) (int64, error) {
+	args := m.Called(ctx, msg)
+	msg.ID = int64(args.Int(0))
+	return msg.ID, args.Error(1)
+}
+
+func (m *MockOutboxRepository) ListPendingOutbox(ctx context.Context, cutoff time.Time, limit int) ([]domain.OutboxMessage, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).([]domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) PurgePublishedOutboxBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockStatusHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatusHistoryRepository) AddStatusHistory(ctx context.Context, entry *domain.StatusHistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockStatusHistoryRepository) ListStatusHistory(ctx context.Context, messageID int64) ([]domain.StatusHistoryEntry, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).([]domain.StatusHistoryEntry), args.Error(1)
+}
+
+func (m *MockStatusHistoryRepository) PurgeStatusHistoryBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Test a message, its payload and its first status are stored together, then produced
+func TestSendTemplateMessageWithOutbox(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockOutbox := new(MockOutboxRepository)
+	mockHistory := new(MockStatusHistoryRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	work := &stubUnitOfWork{}
+
+	mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(7, nil)
+	var payload []byte
+	mockOutbox.On("AddOutboxMessage", mock.Anything, mock.MatchedBy(func(msg *domain.OutboxMessage) bool {
+		payload = msg.Payload
+		return msg.MessageID == 7
+	})).Return(3, nil)
+	mockHistory.On("AddStatusHistory", mock.Anything, &domain.StatusHistoryEntry{MessageID: 7, Status: "queued"}).Return(nil)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil).Once()
+	mockOutbox.On("MarkOutboxPublished", mock.Anything, []int64{3}).Return(nil).Once()
+
+	svc := service.NewMessageServiceWithOutbox(mockRepo, new(MockWhatsAppClient), mockProducer, service.MessageOutbox{
+		Work: work, Outbox: mockOutbox, History: mockHistory,
+	}, mockLogger)
+	msg, err := svc.SendTemplateMessage(context.Background(), "+1234567890", "order_confirmation", nil, "ORD-1", "CUST-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), msg.ID)
+	assert.Equal(t, 1, work.calls)
+
+	queueMsg, err := service.DecodeQueueMessage(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), queueMsg.MessageID)
+	mockProducer.AssertCalled(t, "Produce", mock.Anything, payload)
+
+	// A payload that fails to produce is left pending for the relay
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(errors.New("broker down")).Once()
+	msg, err = svc.SendTemplateMessage(context.Background(), "+1234567890", "order_confirmation", nil, "ORD-1", "CUST-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", msg.Status)
+	mockOutbox.AssertNumberOfCalls(t, "MarkOutboxPublished", 1)
+
+	// Nothing is produced when the transaction fails
+	work.err = errors.New("commit failed")
+	_, err = svc.SendTemplateMessage(context.Background(), "+1234567890", "order_confirmation", nil, "ORD-1", "CUST-1")
+	assert.Error(t, err)
+	mockProducer.AssertNumberOfCalls(t, "Produce", 2)
+}
+
+// Test the relay publishes pending payloads in order and stops at the first failure
+func TestOutboxRelay(t *testing.T) {
+	mockProducer := new(MockProducer)
+	mockOutbox := new(MockOutboxRepository)
+	mockLogger := new(MockLogger)
+
+	mockOutbox.On("ListPendingOutbox", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{
+		{ID: 1, MessageID: 10, TenantID: "acme", Payload: []byte("a")},
+		{ID: 2, MessageID: 11, TenantID: "acme", Payload: []byte("b")},
+		{ID: 3, MessageID: 12, TenantID: "acme", Payload: []byte("c")},
+	}, nil)
+	mockProducer.On("Produce", mock.Anything, []byte("a")).Return(nil)
+	mockProducer.On("Produce", mock.Anything, []byte("b")).Return(errors.New("broker down"))
+	mockOutbox.On("MarkOutboxPublished", mock.Anything, []int64{1}).Return(nil)
+
+	relay := service.NewOutboxRelay(mockOutbox, mockProducer, 30*time.Second, mockLogger)
+	published, err := relay.Relay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, published)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, []byte("c"))
+	mockOutbox.AssertExpectations(t)
+}
+
+// Test side effects registered outside a unit of work run right away
+func TestAfterCommitWithoutTransaction(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, repository.InTx(ctx))
+
+	ran := false
+	repository.AfterCommit(ctx, func(context.Context) { ran = true })
+	assert.True(t, ran)
+}
@@ -0,0 +1,145 @@
+// test/pause_service_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// MockPauseRepository mocks repository.PauseRepository
+type MockPauseRepository struct {
+	mock.Mock
+}
+
+func (m *MockPauseRepository) CreatePause(ctx context.Context, pause *domain.SendPause) error {
+	args := m.Called(ctx, pause)
+	return args.Error(0)
+}
+
+func (m *MockPauseRepository) DeletePause(ctx context.Context, scope, subject string) (bool, error) {
+	args := m.Called(ctx, scope, subject)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPauseRepository) ListPauses(ctx context.Context) ([]domain.SendPause, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.SendPause), args.Error(1)
+}
+
+// Test the consumer holds a message of a paused template instead of sending it
+func TestPausedTemplateIsHeld(t *testing.T) {
+	templatePause := domain.SendPause{Scope: domain.PauseScopeTemplate, Subject: "order_confirmation", Actor: "ops"}
+	mockPauses := new(MockPauseRepository)
+	mockPauses.On("ListPauses", mock.Anything).Return([]domain.SendPause{templatePause}, nil)
+
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("ReleaseHeldMessages", mock.Anything, domain.MessageFilter{}, mock.Anything).Return([]*domain.Message{}, nil)
+	mockRepo.On("HoldMessage", mock.Anything, int64(7)).Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	pauses := service.NewPauseService(mockPauses, mockRepo, new(MockProducer), false, mockLogger)
+	_, err := pauses.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), mockLogger)
+	svc := service.NewPausableMessageService(inner, pauses, mockRepo, mockLogger)
+
+	data, _ := json.Marshal(service.QueueMessage{MessageID: 7, TemplateID: "order_confirmation", TenantID: "acme"})
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+
+	mockRepo.AssertCalled(t, "HoldMessage", mock.Anything, int64(7))
+	mockRepo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test resuming a pause re-enqueues the messages it held
+func TestResumeReleasesHeldMessages(t *testing.T) {
+	tenantPause := domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "acme", Actor: "ops"}
+	templatePause := domain.SendPause{Scope: domain.PauseScopeTemplate, Subject: "promo", Actor: "ops"}
+	mockPauses := new(MockPauseRepository)
+	mockPauses.On("ListPauses", mock.Anything).Return([]domain.SendPause{tenantPause, templatePause}, nil).Once()
+	mockPauses.On("DeletePause", mock.Anything, domain.PauseScopeTenant, "acme").Return(true, nil)
+	mockPauses.On("ListPauses", mock.Anything).Return([]domain.SendPause{templatePause}, nil)
+
+	held := []*domain.Message{{ID: 1, TenantID: "acme"}, {ID: 2, TenantID: "acme"}}
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("ReleaseHeldMessages", mock.Anything, domain.MessageFilter{}, mock.Anything).Return([]*domain.Message{}, nil).Once()
+	mockRepo.On("ReleaseHeldMessages", mock.Anything, domain.MessageFilter{TenantID: "acme"}, mock.Anything).Return(held, nil)
+
+	mockProducer := new(MockProducer)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	pauses := service.NewPauseService(mockPauses, mockRepo, mockProducer, false, mockLogger)
+	_, err := pauses.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	released, err := pauses.Resume(context.Background(), domain.PauseScopeTenant, "acme", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), released)
+	mockProducer.AssertNumberOfCalls(t, "Produce", 2)
+
+	_, paused := pauses.PausedBy("acme", "order_confirmation")
+	assert.False(t, paused)
+	_, paused = pauses.PausedBy("acme", "promo")
+	assert.True(t, paused)
+}
+
+// Test maintenance mode pauses everything and cannot be resumed through the API
+func TestMaintenanceModeCannotBeResumed(t *testing.T) {
+	pauses := service.NewPauseService(new(MockPauseRepository), new(MockMessageRepository), new(MockProducer), true, new(MockLogger))
+
+	pause, paused := pauses.PausedBy("acme", "order_confirmation")
+	assert.True(t, paused)
+	assert.True(t, pause.Maintenance)
+
+	_, err := pauses.Resume(context.Background(), domain.PauseScopeAll, "", "ops")
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+}
+
+// Test only admins pause beyond their tenant, and other callers' tenant pauses apply to their
+// own tenant whatever subject they name
+func TestPauseScopesNeedAdmin(t *testing.T) {
+	mockPauses := new(MockPauseRepository)
+	mockPauses.On("CreatePause", mock.Anything, mock.Anything).Return(nil)
+	mockPauses.On("ListPauses", mock.Anything).Return([]domain.SendPause{}, nil)
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("ReleaseHeldMessages", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.Message{}, nil)
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Return(1, nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	inner := service.NewPauseService(mockPauses, mockRepo, new(MockProducer), false, mockLogger)
+	pauses := service.NewAuditedPauseService(inner, service.NewAuditLog(audit, nil, mockLogger), []string{"root"}, mockLogger)
+	asAcme := domain.WithCaller(domain.WithTenantScope(context.Background(), "acme"), "acme-billing")
+	asRoot := domain.WithCaller(domain.WithTenantScope(context.Background(), "ops"), "root")
+
+	for _, scope := range []string{domain.PauseScopeAll, domain.PauseScopeTemplate} {
+		_, err := pauses.Pause(asAcme, domain.SendPause{Scope: scope, Subject: "promo", Actor: "root"})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied, scope)
+	}
+	_, err := pauses.Resume(asAcme, domain.PauseScopeAll, "", "root")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	_, err = pauses.Pause(domain.WithTenantScope(context.Background(), "acme"), domain.SendPause{Scope: domain.PauseScopeAll, Actor: "root"})
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied, "unauthenticated callers are not admins")
+
+	pause, err := pauses.Pause(asAcme, domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "globex", Actor: "ops"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", pause.Subject)
+	mockPauses.AssertCalled(t, "CreatePause", mock.Anything, &domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "acme", Actor: "acme-billing"})
+
+	_, err = pauses.Pause(asRoot, domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "globex", Actor: "root"})
+	assert.NoError(t, err)
+	mockPauses.AssertCalled(t, "CreatePause", mock.Anything, &domain.SendPause{Scope: domain.PauseScopeTenant, Subject: "globex", Actor: "root"})
+	_, err = pauses.Pause(asRoot, domain.SendPause{Scope: domain.PauseScopeAll, Actor: "root"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,20 @@
+// test/phone_hasher_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test HMAC hashing is stable across formatting and depends on the key
+func TestHMACPhoneNumberHasher(t *testing.T) {
+	hasher := utils.NewHMACPhoneNumberHasher("secret")
+
+	hashed := hasher.Hash("+1 (234) 567-890")
+	assert.NotContains(t, hashed, "1234567890")
+	assert.Equal(t, hashed, hasher.Hash("whatsapp:+1234567890"))
+	assert.NotEqual(t, hashed, utils.NewHMACPhoneNumberHasher("other").Hash("+1234567890"))
+	assert.Equal(t, "", hasher.Hash(""))
+}
@@ -0,0 +1,136 @@
+// test/privacy_service_test.go
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// MockAuditRepository is a mock implementation of repository.AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) (int64, error) {
+	args := m.Called(ctx, entry)
+	entry.ID = int64(args.Int(0))
+	return entry.ID, args.Error(1)
+}
+
+func (m *MockAuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+// Test erasure is scoped to the caller's tenant and audited with a pseudonymized phone number
+func TestEraseCustomerDataByPhoneNumber(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockAudit := new(MockAuditRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	hasher := utils.NewHMACPhoneNumberHasher("secret")
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	filter := domain.MessageFilter{TenantID: "acme", PhoneNumber: "+1234567890", IncludeDeleted: true}
+	mockRepo.On("EraseMessages", ctx, filter, false).Return(3, nil)
+	mockAudit.On("RecordAuditEntry", ctx, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditActionEraseCustomerData &&
+			entry.TenantID == "acme" &&
+			entry.SubjectType == domain.SubjectPhoneNumber &&
+			entry.Subject == hasher.Hash("+1234567890") &&
+			entry.Actor == "dpo@example.com" &&
+			entry.AffectedRows == 3
+	})).Return(42, nil)
+
+	privacyService := service.NewPrivacyService(mockRepo, mockAudit, hasher, mockLogger)
+	entry, err := privacyService.EraseCustomerData(ctx, domain.DataSubject{PhoneNumber: "+1234567890"}, false, "dpo@example.com", "ticket-7")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), entry.ID)
+	assert.Equal(t, int64(3), entry.AffectedRows)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+// Test erasure deletes the subject's provider captures, conversations, opt-ins and contacts
+// before their messages are anonymized
+func TestEraseCustomerDataErasesStores(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockAudit := new(MockAuditRepository)
+	captures := new(MockProviderCaptureRepository)
+	conversations := new(MockConversationRepository)
+	optIns := new(MockOptInRepository)
+	contacts := new(MockSegmentRepository)
+	webhookFailures := new(MockWebhookFailureRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	filter := domain.MessageFilter{TenantID: "acme", CustomerID: "CUST-1", IncludeDeleted: true}
+	var order []string
+	captures.On("EraseProviderCaptures", ctx, filter).Return(int64(4), nil).Run(func(mock.Arguments) { order = append(order, "captures") })
+	conversations.On("EraseConversations", ctx, filter).Return(int64(1), nil).Run(func(mock.Arguments) { order = append(order, "conversations") })
+	optIns.On("EraseOptIns", ctx, filter).Return(int64(2), nil).Run(func(mock.Arguments) { order = append(order, "opt_ins") })
+	contacts.On("EraseContacts", ctx, filter).Return(int64(1), nil).Run(func(mock.Arguments) { order = append(order, "contacts") })
+	webhookFailures.On("EraseWebhookFailures", ctx, filter).Return(int64(1), nil).Run(func(mock.Arguments) { order = append(order, "webhook_failures") })
+	mockRepo.On("EraseMessages", ctx, filter, false).Return(2, nil).Run(func(mock.Arguments) { order = append(order, "messages") })
+	mockAudit.On("RecordAuditEntry", ctx, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.AffectedRows == 2
+	})).Return(1, nil)
+
+	privacyService := service.NewPrivacyServiceWithStores(mockRepo, mockAudit, service.PrivacyStores{
+		Captures: captures, Conversations: conversations, OptIns: optIns, Contacts: contacts, WebhookFailures: webhookFailures,
+	}, utils.NewPlainPhoneNumberHasher(), mockLogger)
+	_, err := privacyService.EraseCustomerData(ctx, domain.DataSubject{CustomerID: "CUST-1"}, false, "dpo@example.com", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"captures", "conversations", "opt_ins", "contacts", "webhook_failures", "messages"}, order)
+	mockAudit.AssertExpectations(t)
+}
+
+// Test requests must name exactly one subject identifier and an actor
+func TestPrivacyRequestValidation(t *testing.T) {
+	privacyService := service.NewPrivacyService(new(MockMessageRepository), new(MockAuditRepository), utils.NewPlainPhoneNumberHasher(), new(MockLogger))
+
+	_, err := privacyService.EraseCustomerData(context.Background(), domain.DataSubject{CustomerID: "c", PhoneNumber: "p"}, false, "dpo", "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	_, err = privacyService.EraseCustomerData(context.Background(), domain.DataSubject{}, false, "dpo", "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	err = privacyService.ExportCustomerData(context.Background(), domain.DataSubject{CustomerID: "c"}, "", "", nil)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+// Test export pages through the subject's messages and audits the number exported
+func TestExportCustomerData(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockAudit := new(MockAuditRepository)
+
+	ctx := context.Background()
+	filter := domain.MessageFilter{TenantID: domain.DefaultTenantID, CustomerID: "CUST-1", IncludeDeleted: true}
+	mockRepo.On("ListMessagesAfterID", ctx, filter, int64(0), 500).Return([]*domain.Message{{ID: 1}, {ID: 4}}, nil)
+	mockAudit.On("RecordAuditEntry", ctx, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditActionExportCustomerData && entry.Subject == "CUST-1" && entry.AffectedRows == 2
+	})).Return(1, nil)
+
+	privacyService := service.NewPrivacyService(mockRepo, mockAudit, utils.NewPlainPhoneNumberHasher(), new(MockLogger))
+
+	var exported []int64
+	err := privacyService.ExportCustomerData(ctx, domain.DataSubject{CustomerID: "CUST-1"}, "dpo", "", func(messages []*domain.Message) error {
+		for _, msg := range messages {
+			exported = append(exported, msg.ID)
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 4}, exported)
+	mockAudit.AssertExpectations(t)
+}
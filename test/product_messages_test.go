@@ -0,0 +1,121 @@
+// test/product_messages_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test product list sections survive being flattened into template parameters
+func TestProductListParameters(t *testing.T) {
+	sections := []meta.ProductSection{
+		{Title: "Shoes", ProductRetailerIDs: []string{"SKU-1", "SKU-2"}},
+		{Title: "Socks", ProductRetailerIDs: []string{"SKU-3"}},
+	}
+	parameters := meta.ProductListParameters("CAT-1", "Our picks", "Take a look", "", sections)
+
+	assert.Equal(t, "CAT-1", parameters[meta.ProductCatalogID])
+	assert.Equal(t, "SKU-2", parameters["section.0.product.1"])
+	assert.NotContains(t, parameters, meta.ProductFooter)
+	for _, value := range parameters {
+		assert.IsType(t, "", value, "parameters must stay scalar for the queue schema")
+	}
+	assert.Equal(t, sections, meta.ProductSections(parameters))
+	assert.NoError(t, meta.ValidateProductMessage(meta.ProductListTemplate, parameters))
+}
+
+// Test product messages are checked against Meta's limits
+func TestValidateProductMessage(t *testing.T) {
+	assert.NoError(t, meta.ValidateProductMessage(meta.ProductTemplate, map[string]interface{}{
+		meta.ProductCatalogID: "CAT-1", meta.ProductRetailerID: "SKU-1",
+	}))
+	assert.Error(t, meta.ValidateProductMessage(meta.ProductTemplate, map[string]interface{}{meta.ProductRetailerID: "SKU-1"}))
+	assert.Error(t, meta.ValidateProductMessage(meta.ProductTemplate, map[string]interface{}{meta.ProductCatalogID: "CAT-1"}))
+
+	list := func(sections ...meta.ProductSection) map[string]interface{} {
+		return meta.ProductListParameters("CAT-1", "Our picks", "Take a look", "", sections)
+	}
+	assert.Error(t, meta.ValidateProductMessage(meta.ProductListTemplate, list()), "no sections")
+	assert.Error(t, meta.ValidateProductMessage(meta.ProductListTemplate, list(
+		meta.ProductSection{ProductRetailerIDs: []string{"SKU-1"}},
+		meta.ProductSection{Title: "Socks", ProductRetailerIDs: []string{"SKU-2"}},
+	)), "untitled section among several")
+	assert.NoError(t, meta.ValidateProductMessage(meta.ProductListTemplate, list(
+		meta.ProductSection{ProductRetailerIDs: []string{"SKU-1"}},
+	)), "a single section needs no title")
+
+	many := make([]string, 31)
+	for i := range many {
+		many[i] = "SKU"
+	}
+	assert.Error(t, meta.ValidateProductMessage(meta.ProductListTemplate, list(meta.ProductSection{Title: "All", ProductRetailerIDs: many})))
+}
+
+const testOrderWebhook = `{
+	"object": "whatsapp_business_account",
+	"entry": [{
+		"id": "WABA-1",
+		"changes": [{
+			"value": {
+				"messaging_product": "whatsapp",
+				"metadata": {"display_phone_number": "15550000000", "phone_number_id": "PNID-1"},
+				"contacts": [{"profile": {"name": "Ada"}, "wa_id": "15551234567"}],
+				"messages": [{
+					"from": "15551234567", "id": "wamid.ORDER1", "timestamp": "1700000000", "type": "order",
+					"order": {
+						"catalog_id": "CAT-1",
+						"text": "Gift wrap please",
+						"product_items": [
+							{"product_retailer_id": "SKU-1", "quantity": 2, "item_price": 19.5, "currency": "USD"},
+							{"product_retailer_id": "SKU-3", "quantity": "1", "item_price": "4", "currency": "USD"}
+						]
+					}
+				}]
+			}
+		}]
+	}]
+}`
+
+// Test carts customers send from the catalog reach the inbound service
+func TestProcessWebhookOrderMessage(t *testing.T) {
+	inbound := new(MockInboundService)
+	inbound.On("HandleInbound", mock.Anything, domain.InboundMessage{
+		TenantID: "tenant-a", ExternalID: "wamid.ORDER1", PhoneNumber: "15551234567", ProfileName: "Ada",
+		Type: "order", Text: "Gift wrap please",
+		Order: &domain.InboundOrder{CatalogID: "CAT-1", Items: []domain.OrderItem{
+			{ProductRetailerID: "SKU-1", Quantity: 2, ItemPrice: 19.5, Currency: "USD"},
+			{ProductRetailerID: "SKU-3", Quantity: 1, ItemPrice: 4, Currency: "USD"},
+		}},
+		ReceivedAt: time.Unix(1700000000, 0),
+	}).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Inbound: inbound, Signatures: testWebhookSignatures}, new(MockLogger), "verify-token")
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), []byte(testOrderWebhook), signWebhook([]byte(testOrderWebhook)), "https://example.com/webhook"))
+	inbound.AssertExpectations(t)
+}
+
+// Test agents see the cart of an order message
+func TestHandoffCarriesOrder(t *testing.T) {
+	repo := new(MockConversationRepository)
+	channel := new(MockHandoffChannel)
+	recordInbound(repo, 7, &domain.Conversation{ID: 9, HandoffStatus: domain.HandoffNone})
+	repo.On("RecentInbound", mock.Anything, int64(9), mock.Anything).Return([]domain.InboundMessage{}, nil)
+	repo.On("UpdateHandoff", mock.Anything, mock.Anything, int64(9), domain.HandoffPending, service.HandoffReasonUnhandled, "").Return(&domain.Conversation{}, nil)
+	repo.On("MarkInboundHandled", mock.Anything, int64(7), mock.Anything).Return(nil)
+	order := &domain.InboundOrder{CatalogID: "CAT-1", Items: []domain.OrderItem{{ProductRetailerID: "SKU-1", Quantity: 2}}}
+	channel.On("Publish", mock.Anything, mock.MatchedBy(func(req service.HandoffRequest) bool {
+		return req.Message.Order == order
+	})).Return(nil)
+
+	svc := service.NewInboundService(repo, channel, 5, newInboundLogger())
+	assert.NoError(t, svc.HandleInbound(context.Background(), domain.InboundMessage{ExternalID: "wamid.ORDER1", Type: "order", Order: order}))
+	channel.AssertExpectations(t)
+}
@@ -0,0 +1,163 @@
+// test/provider_capture_test.go
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// MockProviderCaptureRepository is a mock implementation of ProviderCaptureRepository
+type MockProviderCaptureRepository struct {
+	mock.Mock
+}
+
+func (m *MockProviderCaptureRepository) SaveProviderCapture(ctx context.Context, capture *domain.ProviderCapture) error {
+	args := m.Called(ctx, capture)
+	return args.Error(0)
+}
+
+func (m *MockProviderCaptureRepository) ListProviderCaptures(ctx context.Context, tenantID string, messageID int64) ([]domain.ProviderCapture, error) {
+	args := m.Called(ctx, tenantID, messageID)
+	captures, _ := args.Get(0).([]domain.ProviderCapture)
+	return captures, args.Error(1)
+}
+
+func (m *MockProviderCaptureRepository) PurgeProviderCapturesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProviderCaptureRepository) EraseProviderCaptures(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Test captured exchanges leave out credentials while the caller still reads the whole body
+func TestCapturingTransportRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			assert.Contains(t, string(body), "secret-token", "the request sent is untouched")
+		}
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"access_token":"new-token","messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer server.Close()
+
+	var exchanges []utils.HTTPExchange
+	client := &http.Client{Transport: utils.NewCapturingTransport(nil, 1024)}
+	ctx := utils.WithCapture(context.Background(), func(exchange utils.HTTPExchange) {
+		exchanges = append(exchanges, exchange)
+	})
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/v18.0/123/messages?access_token=secret-token&fields=id",
+		strings.NewReader(`{"to":"15551234567","access_token":"secret-token"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "new-token")
+
+	if assert.Len(t, exchanges, 1) {
+		exchange := exchanges[0]
+		assert.Equal(t, http.MethodPost, exchange.Method)
+		assert.Contains(t, exchange.URL, "fields=id")
+		assert.NotContains(t, exchange.URL, "secret-token")
+		assert.Equal(t, "[REDACTED]", exchange.RequestHeaders["Authorization"])
+		assert.Equal(t, `{"to":"15551234567","access_token":"[REDACTED]"}`, exchange.RequestBody)
+		assert.Equal(t, http.StatusOK, exchange.StatusCode)
+		assert.Equal(t, "[REDACTED]", exchange.ResponseHeaders["Set-Cookie"])
+		assert.Equal(t, `{"access_token":"[REDACTED]","messages":[{"id":"wamid.1"}]}`, exchange.ResponseBody)
+	}
+
+	// Requests without a recorder are not captured
+	resp, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, exchanges, 1)
+}
+
+// Test bodies over the limit are cut in the capture but not for the caller
+func TestCapturingTransportTruncatesBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	var captured utils.HTTPExchange
+	ctx := utils.WithCapture(context.Background(), func(exchange utils.HTTPExchange) { captured = exchange })
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := (&http.Client{Transport: utils.NewCapturingTransport(nil, 10)}).Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Len(t, body, 100)
+	assert.Equal(t, strings.Repeat("x", 10)+"...[truncated at 10 bytes]", captured.ResponseBody)
+}
+
+// Test a failed queued send stores the provider exchange against the message
+func TestCapturingMessageServiceStoresFailedSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 63016, "message": "Template variables are missing", "status": 400}`))
+	}))
+	defer server.Close()
+
+	logger := new(MockLogger)
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	provider := twilio.NewClient(twilio.Config{
+		AccountSID:  "AC123",
+		AuthToken:   "token",
+		From:        "whatsapp:+14155238886",
+		ContentSIDs: map[string]string{"order_update": "HX0123456789abcdef"},
+		HTTP:        utils.HTTPClientConfig{Transport: utils.NewCapturingTransport(nil, 1024)},
+		APIURL:      server.URL,
+	}, logger)
+
+	repo := new(MockMessageRepository)
+	repo.On("GetMessageByID", mock.Anything, int64(7)).Return(&domain.Message{ID: 7, PhoneNumber: "+15551234567", TemplateID: "order_update", Status: "queued", TenantID: "acme"}, nil)
+	repo.On("UpdateMessageStatus", mock.Anything, int64(7), mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	captures := new(MockProviderCaptureRepository)
+	captures.On("SaveProviderCapture", mock.Anything, mock.MatchedBy(func(capture *domain.ProviderCapture) bool {
+		return capture.MessageID == 7 && capture.TenantID == "acme" &&
+			capture.StatusCode == http.StatusBadRequest &&
+			capture.RequestHeaders["Authorization"] == "[REDACTED]" &&
+			strings.Contains(capture.RequestBody, "ContentSid=HX0123456789abcdef") &&
+			strings.Contains(capture.ResponseBody, "Template variables are missing")
+	})).Return(nil).Once()
+
+	svc := service.NewCapturingMessageService(service.NewMessageService(repo, provider, new(MockProducer), logger), captures, logger)
+	data, _ := service.EncodeQueueMessage(service.QueueMessage{MessageID: 7, TenantID: "acme"})
+
+	assert.Error(t, svc.ProcessQueueMessage(context.Background(), data))
+	captures.AssertExpectations(t)
+}
+
+// Test captures are listed for the caller's tenant only
+func TestProviderCaptureServiceScopesToTenant(t *testing.T) {
+	captures := new(MockProviderCaptureRepository)
+	captures.On("ListProviderCaptures", mock.Anything, "acme", int64(7)).Return([]domain.ProviderCapture{{ID: 1, MessageID: 7}}, nil)
+
+	svc := service.NewProviderCaptureService(captures, true, new(MockLogger))
+	list, err := svc.ListProviderCaptures(domain.WithTenant(context.Background(), "acme"), 7)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.True(t, svc.Enabled())
+}
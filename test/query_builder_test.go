@@ -0,0 +1,301 @@
+// test/query_builder_test.go
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+)
+
+// statementLog is a database/sql driver recording the statements run on it. Queries return
+// no rows, and COUNT(*) zero.
+type statementLog struct {
+	mu         sync.Mutex
+	statements []recordedStatement
+}
+
+// recordedStatement is a statement with its whitespace collapsed, and its arguments
+type recordedStatement struct {
+	sql  string
+	args []interface{}
+}
+
+func newStatementLog() (*statementLog, *sqlx.DB) {
+	log := &statementLog{}
+	return log, sqlx.NewDb(sql.OpenDB(log), "postgres")
+}
+
+// last returns the last statement, from the clause starting with from on when given
+func (l *statementLog) last(t *testing.T, from string) recordedStatement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	require.NotEmpty(t, l.statements)
+	statement := l.statements[len(l.statements)-1]
+	if from != "" {
+		i := strings.Index(statement.sql, from)
+		require.GreaterOrEqual(t, i, 0, statement.sql)
+		statement.sql = statement.sql[i:]
+	}
+	return statement
+}
+
+func (l *statementLog) record(query string, args []driver.NamedValue) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	statement := recordedStatement{sql: strings.Join(strings.Fields(query), " ")}
+	for _, a := range args {
+		statement.args = append(statement.args, a.Value)
+	}
+	l.statements = append(l.statements, statement)
+}
+
+func (l *statementLog) Connect(context.Context) (driver.Conn, error) { return l, nil }
+func (l *statementLog) Driver() driver.Driver                        { return l }
+func (l *statementLog) Open(string) (driver.Conn, error)             { return l, nil }
+func (l *statementLog) Close() error                                 { return nil }
+func (l *statementLog) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+func (l *statementLog) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (l *statementLog) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	l.record(query, args)
+	if strings.Contains(query, "SELECT COUNT(*)") {
+		return &tableRows{columns: []string{"count"}, values: [][]driver.Value{{int64(0)}}}, nil
+	}
+	return &tableRows{columns: []string{"id"}}, nil
+}
+
+func (l *statementLog) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	l.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+// Test every filter field becomes one condition, numbered in order, with the paging arguments after
+func TestQueryBuilderFullFilter(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewMessageRepository(db, discardLogger{})
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 1, 0)
+
+	_, err := repo.ListMessages(context.Background(), domain.MessageFilter{
+		TenantID: "acme", OrderID: "ORD-1", CustomerID: "C-1", PhoneNumber: "+14155550100",
+		Status: "sent", TemplateID: "order_confirmation", CreatedAfter: after, CreatedBefore: before,
+	}, domain.MessageSort{}, 20, 40)
+	require.NoError(t, err)
+
+	statement := log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages WHERE deleted_at IS NULL AND tenant_id = $1 AND order_id = $2 AND customer_id = $3"+
+		" AND phone_number = $4 AND status = $5 AND template_id = $6 AND created_at >= $7 AND created_at < $8"+
+		" ORDER BY created_at DESC, id DESC LIMIT $9 OFFSET $10", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "ORD-1", "C-1", "+14155550100", "sent", "order_confirmation", after, before, int64(20), int64(40)}, statement.args)
+}
+
+// Test unset filter fields add no condition and leave no gaps in the placeholder numbers
+func TestQueryBuilderSkipsUnsetFields(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewMessageRepository(db, discardLogger{})
+	before := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.CountMessages(context.Background(), domain.MessageFilter{Status: "failed", CreatedBefore: before})
+	require.NoError(t, err)
+	statement := log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages WHERE deleted_at IS NULL AND status = $1 AND created_at < $2", statement.sql)
+	assert.Equal(t, []interface{}{"failed", before}, statement.args)
+
+	_, err = repo.ListMessages(context.Background(), domain.MessageFilter{IncludeDeleted: true}, domain.MessageSort{By: "status", Order: "asc"}, 10, 0)
+	require.NoError(t, err)
+	statement = log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages ORDER BY status ASC, id ASC LIMIT $1 OFFSET $2", statement.sql)
+	assert.Equal(t, []interface{}{int64(10), int64(0)}, statement.args)
+}
+
+// Test conditions added after the filter join the same WHERE clause and continue its numbering
+func TestQueryBuilderConditionsAfterFilter(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewMessageRepository(db, discardLogger{})
+
+	_, err := repo.ListMessagesAfterID(context.Background(), domain.MessageFilter{IncludeDeleted: true}, 7, 100)
+	require.NoError(t, err)
+	statement := log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages WHERE id > $1 ORDER BY id ASC LIMIT $2", statement.sql)
+	assert.Equal(t, []interface{}{int64(7), int64(100)}, statement.args)
+
+	_, err = repo.SearchMessages(context.Background(), domain.MessageSearch{
+		Filter:     domain.MessageFilter{TemplateID: "order_confirmation"},
+		Parameters: map[string]string{"order": "ORD-1"},
+		Text:       "refund",
+	}, 5, 0)
+	require.NoError(t, err)
+	statement = log.last(t, "FROM messages")
+	assert.True(t, strings.HasPrefix(statement.sql, "FROM messages WHERE deleted_at IS NULL AND template_id = $1 AND parameters @> $2::jsonb AND "), statement.sql)
+	assert.True(t, strings.HasSuffix(statement.sql, " @@ plainto_tsquery('simple', $3) ORDER BY created_at DESC, id DESC LIMIT $4 OFFSET $5"), statement.sql)
+	assert.Equal(t, []interface{}{"order_confirmation", `{"order":"ORD-1"}`, "refund", int64(5), int64(0)}, statement.args)
+}
+
+// Test a tenant scope replaces the tenant the filter asked for, and scopes lookups by ID
+func TestQueryBuilderTenantScope(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewMessageRepository(db, discardLogger{})
+	ctx := domain.WithTenantScope(context.Background(), "acme")
+
+	_, err := repo.CountMessages(ctx, domain.MessageFilter{TenantID: "globex", OrderID: "ORD-1"})
+	require.NoError(t, err)
+	statement := log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages WHERE deleted_at IS NULL AND tenant_id = $1 AND order_id = $2", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "ORD-1"}, statement.args)
+
+	_, err = repo.GetMessageByID(ctx, 9)
+	assert.Error(t, err)
+	statement = log.last(t, "FROM messages")
+	assert.Equal(t, "FROM messages WHERE id = $1 AND tenant_id = $2", statement.sql)
+	assert.Equal(t, []interface{}{int64(9), "acme"}, statement.args)
+}
+
+// Test values bound before the conditions keep their placeholders, and an erasure without any
+// condition is refused before reaching the database
+func TestQueryBuilderErase(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewMessageRepository(db, discardLogger{})
+
+	_, err := repo.EraseMessages(context.Background(), domain.MessageFilter{CustomerID: "C-1"}, false)
+	require.NoError(t, err)
+	statement := log.last(t, "")
+	assert.True(t, strings.HasPrefix(statement.sql, "UPDATE messages SET phone_number = 'erased'"), statement.sql)
+	assert.True(t, strings.HasSuffix(statement.sql, "erased_at = $1, updated_at = $1 WHERE customer_id = $2"), statement.sql)
+	require.Len(t, statement.args, 2)
+	assert.IsType(t, time.Time{}, statement.args[0])
+	assert.Equal(t, "C-1", statement.args[1])
+
+	_, err = repo.EraseMessages(context.Background(), domain.MessageFilter{PhoneNumber: "+14155550100"}, true)
+	require.NoError(t, err)
+	statement = log.last(t, "")
+	assert.Equal(t, "DELETE FROM messages WHERE phone_number = $1", statement.sql)
+	assert.Equal(t, []interface{}{"+14155550100"}, statement.args)
+
+	executed := len(log.statements)
+	_, err = repo.EraseMessages(context.Background(), domain.MessageFilter{}, true)
+	assert.EqualError(t, err, "refusing to erase messages without a filter")
+	assert.Len(t, log.statements, executed)
+}
+
+// Test provider captures are erased through the subject's messages, never without a filter
+func TestQueryBuilderEraseProviderCaptures(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewProviderCaptureRepository(db, discardLogger{})
+
+	_, err := repo.EraseProviderCaptures(context.Background(), domain.MessageFilter{TenantID: "acme", CustomerID: "C-1", IncludeDeleted: true})
+	require.NoError(t, err)
+	statement := log.last(t, "")
+	assert.Equal(t, "DELETE FROM provider_captures WHERE message_id IN (SELECT id FROM messages WHERE tenant_id = $1 AND customer_id = $2)", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "C-1"}, statement.args)
+
+	_, err = repo.EraseProviderCaptures(context.Background(), domain.MessageFilter{IncludeDeleted: true})
+	assert.EqualError(t, err, "refusing to erase provider captures without a filter")
+}
+
+// Test conversations are erased by their WhatsApp ID, or through a customer's messages
+func TestQueryBuilderEraseConversations(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewConversationRepository(db, discardLogger{})
+
+	_, err := repo.EraseConversations(context.Background(), domain.MessageFilter{TenantID: "acme", PhoneNumber: "whatsapp:+14155550100"})
+	require.NoError(t, err)
+	statement := log.last(t, "")
+	assert.Equal(t, "DELETE FROM conversations WHERE tenant_id = $1 AND phone_number = $2", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "14155550100"}, statement.args)
+
+	_, err = repo.EraseConversations(context.Background(), domain.MessageFilter{TenantID: "acme", CustomerID: "C-1"})
+	require.NoError(t, err)
+	statement = log.last(t, "")
+	assert.Equal(t, "DELETE FROM conversations WHERE tenant_id = $1 AND phone_number IN (SELECT regexp_replace(trim(phone_number), '^(whatsapp:)?\\+?', '') FROM messages WHERE tenant_id = $2 AND customer_id = $3)", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "acme", "C-1"}, statement.args)
+
+	_, err = repo.EraseConversations(context.Background(), domain.MessageFilter{TenantID: "acme"})
+	assert.EqualError(t, err, "refusing to erase conversations without a customer")
+}
+
+// Test opt-ins and contacts are erased by phone digits, or through a customer's messages, and a
+// latest opt-out is kept as a suppression
+func TestQueryBuilderEraseOptInsAndContacts(t *testing.T) {
+	log, db := newStatementLog()
+	optIns := repository.NewOptInRepository(db, discardLogger{})
+	contacts := repository.NewSegmentRepository(db, discardLogger{})
+
+	_, err := optIns.EraseOptIns(context.Background(), domain.MessageFilter{TenantID: "acme", PhoneNumber: "+1 (415) 555-0100"})
+	require.NoError(t, err)
+	statement := log.last(t, "FROM contact_opt_ins WHERE")
+	assert.True(t, strings.HasPrefix(statement.sql, "FROM contact_opt_ins WHERE tenant_id = $1 AND phone_number = $2 ), suppressions AS ( UPDATE contact_opt_ins SET evidence = NULL WHERE id IN (SELECT id FROM events WHERE n = 1 AND action = $3) )"), statement.sql)
+	assert.True(t, strings.HasSuffix(statement.sql, "DELETE FROM contact_opt_ins WHERE id IN (SELECT id FROM events WHERE n > 1 OR action <> $3)"), statement.sql)
+	assert.Equal(t, []interface{}{"acme", "14155550100", "opt_out"}, statement.args)
+
+	_, err = contacts.EraseContacts(context.Background(), domain.MessageFilter{TenantID: "acme", CustomerID: "C-1"})
+	require.NoError(t, err)
+	statements := log.statements[len(log.statements)-2:]
+	assert.Equal(t, "DELETE FROM segment_members WHERE segment_id IN (SELECT id FROM segments WHERE tenant_id = $1)"+
+		" AND phone_number IN (SELECT regexp_replace(phone_number, '[^0-9]', '', 'g') FROM messages WHERE tenant_id = $2 AND customer_id = $3)", statements[0].sql)
+	assert.Equal(t, "DELETE FROM contacts WHERE tenant_id = $1"+
+		" AND phone_number IN (SELECT regexp_replace(phone_number, '[^0-9]', '', 'g') FROM messages WHERE tenant_id = $2 AND customer_id = $3)", statements[1].sql)
+	assert.Equal(t, []interface{}{"acme", "acme", "C-1"}, statements[1].args)
+
+	_, err = optIns.EraseOptIns(context.Background(), domain.MessageFilter{TenantID: "acme"})
+	assert.EqualError(t, err, "refusing to erase opt-ins without a customer")
+	_, err = contacts.EraseContacts(context.Background(), domain.MessageFilter{CustomerID: "C-1"})
+	assert.EqualError(t, err, "refusing to erase contacts without a tenant")
+}
+
+// Test webhook failures are erased by the phone numbers in their payload, in any tenant
+func TestQueryBuilderEraseWebhookFailures(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewWebhookFailureRepository(db, discardLogger{})
+
+	_, err := repo.EraseWebhookFailures(context.Background(), domain.MessageFilter{TenantID: "acme", PhoneNumber: "+1 (415) 555-0100"})
+	require.NoError(t, err)
+	statement := log.last(t, "")
+	assert.True(t, strings.HasPrefix(statement.sql, "DELETE FROM webhook_failures WHERE id IN (SELECT id FROM ("), statement.sql)
+	assert.Contains(t, statement.sql, "value.statuses[*].recipient_id")
+	assert.True(t, strings.HasSuffix(statement.sql, ") AS failure_phones WHERE phone_number = $1)"), statement.sql)
+	assert.Equal(t, []interface{}{"14155550100"}, statement.args)
+
+	_, err = repo.EraseWebhookFailures(context.Background(), domain.MessageFilter{CustomerID: "C-1"})
+	assert.EqualError(t, err, "refusing to erase webhook failures without a customer")
+}
+
+// Test the audit log listing builds its filter with the same builder
+func TestQueryBuilderAuditFilter(t *testing.T) {
+	log, db := newStatementLog()
+	repo := repository.NewAuditRepository(db, discardLogger{})
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.ListAuditEntries(context.Background(), domain.AuditFilter{Actor: "ops", SubjectType: "message", Since: since, Limit: 50})
+	require.NoError(t, err)
+	statement := log.last(t, "FROM audit_log")
+	assert.Equal(t, "FROM audit_log WHERE actor = $1 AND subject_type = $2 AND created_at >= $3 ORDER BY created_at DESC, id DESC LIMIT $4", statement.sql)
+	assert.Equal(t, []interface{}{"ops", "message", since, int64(50)}, statement.args)
+
+	_, err = repo.ListAuditEntries(context.Background(), domain.AuditFilter{Limit: 50})
+	require.NoError(t, err)
+	statement = log.last(t, "FROM audit_log")
+	assert.Equal(t, "FROM audit_log ORDER BY created_at DESC, id DESC LIMIT $1", statement.sql)
+
+	// A tenant's entries include those of changes reaching every tenant
+	_, err = repo.ListAuditEntries(context.Background(), domain.AuditFilter{TenantID: "acme", Action: "set_country_rule", Limit: 50})
+	require.NoError(t, err)
+	statement = log.last(t, "FROM audit_log")
+	assert.Equal(t, "FROM audit_log WHERE tenant_id IN ($1, '') AND action = $2 ORDER BY created_at DESC, id DESC LIMIT $3", statement.sql)
+	assert.Equal(t, []interface{}{"acme", "set_country_rule", int64(50)}, statement.args)
+}
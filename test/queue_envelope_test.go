@@ -0,0 +1,49 @@
+// test/queue_envelope_test.go
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/internal/service"
+)
+
+// Test payloads written before envelopes existed still decode
+func TestDecodeUnversionedQueueMessage(t *testing.T) {
+	msg, err := service.DecodeQueueMessage([]byte(`{"message_id":7,"phone_number":"+1234567890","template_id":"order_confirmation","parameters":{"order_id":"ORD-1"}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), msg.MessageID)
+	assert.Equal(t, 1, msg.SchemaVersion)
+	assert.Equal(t, service.QueueMessageTypeSendTemplate, msg.Type)
+}
+
+// Test the current version round-trips and stays readable by a consumer that predates envelopes
+func TestEncodeQueueMessageIsBackwardCompatible(t *testing.T) {
+	data, err := service.EncodeQueueMessage(service.QueueMessage{MessageID: 7, TemplateID: "order_confirmation", TenantID: "acme"})
+	assert.NoError(t, err)
+
+	msg, err := service.DecodeQueueMessage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, service.QueueSchemaVersion, msg.SchemaVersion)
+	assert.Equal(t, "acme", msg.TenantID)
+
+	// The version 1 consumer decoded straight into these fields
+	var legacy struct {
+		MessageID  int64  `json:"message_id"`
+		TemplateID string `json:"template_id"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &legacy))
+	assert.Equal(t, int64(7), legacy.MessageID)
+	assert.Equal(t, "order_confirmation", legacy.TemplateID)
+}
+
+// Test payloads from a newer release or of an unknown type are refused
+func TestDecodeQueueMessageRejectsUnknownSchema(t *testing.T) {
+	_, err := service.DecodeQueueMessage([]byte(`{"schema_version":99,"type":"send_template","message_id":7}`))
+	assert.Error(t, err)
+
+	_, err = service.DecodeQueueMessage([]byte(`{"schema_version":2,"type":"send_text","message_id":7}`))
+	assert.Error(t, err)
+}
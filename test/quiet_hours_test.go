@@ -0,0 +1,131 @@
+// test/quiet_hours_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// Test a window spanning midnight ends the next morning
+func TestQuietHoursNextAllowed(t *testing.T) {
+	window, err := domain.ParseQuietHours("21:00-09:00")
+	assert.NoError(t, err)
+
+	loc, _ := time.LoadLocation("Europe/London")
+	evening := time.Date(2024, 6, 3, 22, 30, 0, 0, loc)
+	assert.True(t, window.Contains(evening))
+	assert.Equal(t, time.Date(2024, 6, 4, 9, 0, 0, 0, loc), window.NextAllowed(evening))
+
+	early := time.Date(2024, 6, 4, 7, 15, 0, 0, loc)
+	assert.Equal(t, time.Date(2024, 6, 4, 9, 0, 0, 0, loc), window.NextAllowed(early))
+
+	noon := time.Date(2024, 6, 4, 12, 0, 0, 0, loc)
+	assert.False(t, window.Contains(noon))
+	assert.Equal(t, noon, window.NextAllowed(noon))
+
+	_, err = domain.ParseQuietHours("21:00")
+	assert.Error(t, err)
+}
+
+// Test the recipient timezone is inferred from the country code unless given explicitly
+func TestQuietHoursUseRecipientTimezone(t *testing.T) {
+	scheduler, err := service.NewQuietHoursScheduler(service.QuietHoursConfig{
+		Default:            "21:00-09:00",
+		Tenants:            map[string]string{"exempt": "off"},
+		MarketingTemplates: []string{"promo"},
+	}, new(MockMessageRepository), new(MockProducer), new(MockLogger))
+	assert.NoError(t, err)
+
+	// 22:00 UTC is 23:00 in London in summer, 06:00 the next day in Singapore and 18:00 in New York
+	now := time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC)
+
+	until, deferred := scheduler.DeferUntil("acme", "promo", "+447700900123", "", now)
+	assert.True(t, deferred)
+	assert.Equal(t, time.Date(2024, 6, 4, 8, 0, 0, 0, time.UTC), until.UTC())
+
+	until, deferred = scheduler.DeferUntil("acme", "promo", "+447700900123", "Asia/Singapore", now)
+	assert.True(t, deferred)
+	assert.Equal(t, time.Date(2024, 6, 4, 1, 0, 0, 0, time.UTC), until.UTC())
+
+	_, deferred = scheduler.DeferUntil("acme", "order_confirmation", "+447700900123", "", now)
+	assert.False(t, deferred, "only marketing templates are deferred")
+
+	_, deferred = scheduler.DeferUntil("exempt", "promo", "+447700900123", "", now)
+	assert.False(t, deferred, "tenants with quiet hours off are not deferred")
+
+	_, deferred = scheduler.DeferUntil("acme", "promo", "+12125550123", "", now)
+	assert.False(t, deferred, "18:00 in New York is outside quiet hours")
+}
+
+// Test the consumer defers a marketing message arriving in quiet hours instead of sending it
+func TestQuietHoursDefersMarketingMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("DeferMessage", mock.Anything, int64(7), mock.Anything).Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	scheduler, err := service.NewQuietHoursScheduler(service.QuietHoursConfig{
+		Default:            "11:00-14:00",
+		MarketingTemplates: []string{"promo"},
+	}, mockRepo, new(MockProducer), mockLogger)
+	assert.NoError(t, err)
+
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), mockLogger)
+	svc := service.NewQuietHoursMessageService(inner, scheduler, mockRepo, mockLogger)
+
+	// Pick the recipient's timezone so it is around noon there whenever the test runs
+	offset := 12 - time.Now().UTC().Hour()
+	timezone := fmt.Sprintf("Etc/GMT%+d", -offset)
+	if offset == 0 {
+		timezone = "UTC"
+	}
+
+	data, _ := json.Marshal(service.QueueMessage{MessageID: 7, PhoneNumber: "+447700900123", TemplateID: "promo", TenantID: "acme", RecipientTimezone: timezone})
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+
+	mockRepo.AssertCalled(t, "DeferMessage", mock.Anything, int64(7), mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test due deferred messages are re-enqueued with their timezone
+func TestQuietHoursReleaseEnqueuesDueMessages(t *testing.T) {
+	due := []*domain.Message{{ID: 1, TemplateID: "promo", TenantID: "acme", RecipientTimezone: "Asia/Tokyo"}, {ID: 2, TemplateID: "promo", TenantID: "acme"}}
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("ReleaseDueMessages", mock.Anything, mock.Anything, mock.Anything).Return(due, nil)
+
+	var produced []service.QueueMessage
+	mockProducer := new(MockProducer)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var queueMsg service.QueueMessage
+		_ = json.Unmarshal(args.Get(1).([]byte), &queueMsg)
+		produced = append(produced, queueMsg)
+	}).Return(nil)
+
+	scheduler, err := service.NewQuietHoursScheduler(service.QuietHoursConfig{MarketingTemplates: []string{"promo"}}, mockRepo, mockProducer, new(MockLogger))
+	assert.NoError(t, err)
+
+	released, err := scheduler.Release(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), released)
+	assert.Len(t, produced, 2)
+	assert.Equal(t, "Asia/Tokyo", produced[0].RecipientTimezone)
+}
+
+// Test an explicit recipient timezone must be a known IANA name
+func TestQuietHoursRejectUnknownTimezone(t *testing.T) {
+	scheduler, err := service.NewQuietHoursScheduler(service.QuietHoursConfig{}, new(MockMessageRepository), new(MockProducer), new(MockLogger))
+	assert.NoError(t, err)
+	svc := service.NewQuietHoursMessageService(nil, scheduler, new(MockMessageRepository), new(MockLogger))
+
+	ctx := domain.WithRecipientTimezone(context.Background(), "Mars/Olympus_Mons")
+	_, err = svc.SendTemplateMessage(ctx, "+447700900123", "promo", nil, "", "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -0,0 +1,89 @@
+// test/quota_service_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// MockQuotaRepository mocks repository.QuotaRepository
+type MockQuotaRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuotaRepository) ConsumeQuota(ctx context.Context, scope, subject string, period time.Time, limit int64) (int64, bool, error) {
+	args := m.Called(ctx, scope, subject, period, limit)
+	return int64(args.Int(0)), args.Bool(1), args.Error(2)
+}
+
+func (m *MockQuotaRepository) ReleaseQuota(ctx context.Context, scope, subject string, period time.Time) error {
+	args := m.Called(ctx, scope, subject, period)
+	return args.Error(0)
+}
+
+func (m *MockQuotaRepository) GetQuotaUsage(ctx context.Context, scope, subject string, period time.Time) (int64, error) {
+	args := m.Called(ctx, scope, subject, period)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+// Test a full customer quota rolls back the tenant quota already taken
+func TestQuotaReserveRollsBack(t *testing.T) {
+	mockRepo := new(MockQuotaRepository)
+	mockRepo.On("ConsumeQuota", mock.Anything, domain.QuotaScopeTenant, "acme", mock.Anything, int64(1000)).Return(10, true, nil)
+	mockRepo.On("ConsumeQuota", mock.Anything, domain.QuotaScopeCustomer, "acme/cust-1", mock.Anything, int64(5)).Return(5, false, nil)
+	mockRepo.On("ReleaseQuota", mock.Anything, domain.QuotaScopeTenant, "acme", mock.Anything).Return(nil)
+
+	quotas := service.NewQuotaService(mockRepo, service.QuotaPolicy{
+		TenantDefault: 1000,
+		Customers:     map[string]int64{"cust-1": 5},
+	}, new(MockLogger))
+
+	err := quotas.Reserve(context.Background(), "acme", "cust-1")
+	assert.True(t, errors.Is(err, domain.ErrQuotaExceeded))
+	mockRepo.AssertCalled(t, "ReleaseQuota", mock.Anything, domain.QuotaScopeTenant, "acme", mock.Anything)
+}
+
+// Test usage reports only the limited quotas for the current month
+func TestQuotaUsage(t *testing.T) {
+	mockRepo := new(MockQuotaRepository)
+	mockRepo.On("GetQuotaUsage", mock.Anything, domain.QuotaScopeTenant, "acme", mock.Anything).Return(1200, nil)
+
+	quotas := service.NewQuotaService(mockRepo, service.QuotaPolicy{TenantDefault: 1000}, new(MockLogger))
+	usage, err := quotas.Usage(context.Background(), "acme", "cust-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, usage, 1)
+	assert.Equal(t, int64(0), usage[0].Remaining())
+	assert.Equal(t, 1, usage[0].PeriodStart.Day())
+	assert.Equal(t, usage[0].PeriodStart.AddDate(0, 1, 0), usage[0].ResetsAt)
+}
+
+// Test sends over quota are stored as quota_exceeded instead of sent in record mode
+func TestQuotaEnforcingRecordsExceeded(t *testing.T) {
+	mockQuotaRepo := new(MockQuotaRepository)
+	mockQuotaRepo.On("ConsumeQuota", mock.Anything, domain.QuotaScopeTenant, "acme", mock.Anything, int64(1)).Return(1, false, nil)
+
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("CreateMessage", mock.Anything, mock.MatchedBy(func(msg *domain.Message) bool {
+		return msg.Status == domain.StatusQuotaExceeded && msg.TenantID == "acme"
+	})).Return(42, nil)
+
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	quotas := service.NewQuotaService(mockQuotaRepo, service.QuotaPolicy{TenantDefault: 1}, new(MockLogger))
+	svc := service.NewQuotaEnforcingMessageService(inner, quotas, mockRepo, true, new(MockLogger))
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	msg, err := svc.SendTemplateMessage(ctx, "1234567890", "order_confirmation", nil, "order-1", "cust-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), msg.ID)
+	assert.Equal(t, domain.StatusQuotaExceeded, msg.Status)
+	mockRepo.AssertNumberOfCalls(t, "CreateMessage", 1)
+}
@@ -0,0 +1,169 @@
+// test/rate_limiter_test.go
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test the in-memory limiter allows a burst and then rejects with a retry delay
+func TestMemoryRateLimiterBurst(t *testing.T) {
+	limiter := utils.NewMemoryRateLimiter()
+	limit := utils.RateLimit{RPS: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(context.Background(), "k", limit)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := limiter.Allow(context.Background(), "k", limit)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// Other keys have their own bucket
+	allowed, _, _ = limiter.Allow(context.Background(), "other", limit)
+	assert.True(t, allowed)
+}
+
+// Test the Redis limiter falls back to the in-memory limiter when Redis is unreachable
+func TestRedisRateLimiterFallback(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	limiter := utils.NewRedisRateLimiter(client, utils.NewMemoryRateLimiter(), mockLogger)
+	limit := utils.RateLimit{RPS: 1, Burst: 1}
+
+	allowed, _, err := limiter.Allow(context.Background(), "k", limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, err = limiter.Allow(context.Background(), "k", limit)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	mockLogger.AssertCalled(t, "Warn", "Redis rate limiter unavailable, using in-memory fallback", mock.Anything)
+}
+
+// Test route and API key limits override the default
+func TestRateLimiterMiddlewarePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	router := gin.New()
+	router.Use(utils.RateLimiterMiddleware(utils.NewMemoryRateLimiter(), utils.RateLimitPolicy{
+		Default: utils.RateLimit{RPS: 1, Burst: 1},
+		Routes:  map[string]utils.RateLimit{"/webhook": {RPS: 1, Burst: 3}},
+		APIKeys: map[string]utils.RateLimit{"partner": {RPS: 1, Burst: 2}},
+	}, mockLogger))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/webhook", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("/health", "").Code)
+	limited := do("/health", "")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do("/webhook", "").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do("/webhook", "").Code)
+
+	assert.Equal(t, http.StatusOK, do("/health", "partner").Code)
+	assert.Equal(t, http.StatusOK, do("/health", "partner").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("/health", "partner").Code)
+}
+
+// recordingRateLimiter records the keys it is asked about and allows everything
+type recordingRateLimiter struct {
+	keys []string
+}
+
+func (l *recordingRateLimiter) Allow(ctx context.Context, key string, limit utils.RateLimit) (bool, time.Duration, error) {
+	l.keys = append(l.keys, key)
+	return true, 0, nil
+}
+
+// Test unknown API keys share their IP's bucket, and known ones are not stored in cleartext
+func TestRateLimiterMiddlewareUnknownAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	do := func(router *gin.Engine, apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = "203.0.113.7:41000"
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	policy := utils.RateLimitPolicy{
+		Default: utils.RateLimit{RPS: 1, Burst: 2},
+		APIKeys: map[string]utils.RateLimit{"partner-secret": {RPS: 1, Burst: 5}},
+	}
+
+	router := gin.New()
+	router.Use(utils.RateLimiterMiddleware(utils.NewMemoryRateLimiter(), policy, mockLogger))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	assert.Equal(t, http.StatusOK, do(router, "random-1"))
+	assert.Equal(t, http.StatusOK, do(router, "random-2"))
+	assert.Equal(t, http.StatusTooManyRequests, do(router, "random-3"))
+	assert.Equal(t, http.StatusTooManyRequests, do(router, ""))
+	assert.Equal(t, http.StatusOK, do(router, "partner-secret"))
+
+	recorder := &recordingRateLimiter{}
+	router = gin.New()
+	router.Use(utils.RateLimiterMiddleware(recorder, policy, mockLogger))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	do(router, "partner-secret")
+	do(router, "random-1")
+	if assert.Len(t, recorder.keys, 2) {
+		assert.NotContains(t, recorder.keys[0], "partner-secret")
+		assert.Contains(t, recorder.keys[0], ":key:")
+		assert.Equal(t, "/health:ip:203.0.113.7", recorder.keys[1])
+	}
+}
+
+// Test requests to paths no route matches share one bucket per caller
+func TestRateLimiterMiddlewareUnmatchedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	router := gin.New()
+	router.Use(utils.RateLimiterMiddleware(utils.NewMemoryRateLimiter(), utils.RateLimitPolicy{
+		Default: utils.RateLimit{RPS: 1, Burst: 2},
+	}, mockLogger))
+
+	for i, want := range []int{http.StatusNotFound, http.StatusNotFound, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/probe-%d", i), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code)
+	}
+}
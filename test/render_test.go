@@ -0,0 +1,108 @@
+// test/render_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+)
+
+// Test bodies support conditionals, defaults and the formatting helpers
+func TestRenderHelpers(t *testing.T) {
+	engine := render.NewEngine(render.Options{Safe: true})
+	tmpl, err := engine.Parse("order_delayed", `Hi {{index . "name" | default "there"}}, {{if eq .status "delayed"}}order {{upper .order_id}} is late{{else}}order {{upper .order_id}} is on time{{end}}: {{currency .total "EUR"}}, arriving {{date .eta "Mon, Jan 2"}}.`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eta", "name", "order_id", "status", "total"}, tmpl.Parameters())
+	assert.Equal(t, []string{"eta", "order_id", "status", "total"}, tmpl.RequiredParameters())
+
+	body, err := tmpl.Execute(map[string]interface{}{"status": "delayed", "order_id": "ord-1", "total": "1234.5", "eta": "2026-03-02"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi there, order ORD-1 is late: €1,234.50, arriving Mon, Mar 2.", body)
+
+	_, err = tmpl.Execute(map[string]interface{}{"status": "delayed"})
+	assert.Error(t, err, "missing required parameters fail the render")
+	_, err = tmpl.Execute(map[string]interface{}{"status": "delayed", "order_id": "ord-1", "total": "lots", "eta": "2026-03-02"})
+	assert.ErrorContains(t, err, "not an amount")
+}
+
+// Test amounts are formatted with the currency's symbol or code and decimals
+func TestRenderCurrency(t *testing.T) {
+	engine := render.NewEngine(render.Options{})
+	tests := map[string]string{
+		`{{currency "1234567.891" "usd"}}`: "$1,234,567.89",
+		`{{currency "-5" "GBP"}}`:          "-£5.00",
+		`{{currency "1500.4" "JPY"}}`:      "¥1,500",
+		`{{currency "99.9" "CHF"}}`:        "99.90 CHF",
+	}
+	for text, want := range tests {
+		tmpl, err := engine.Parse("currency", text)
+		assert.NoError(t, err)
+		got, err := tmpl.Execute(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, text)
+	}
+}
+
+// Test safe mode rejects bodies that could run away and caps the output
+func TestRenderSafeMode(t *testing.T) {
+	safe := render.NewEngine(render.Options{Safe: true, MaxOutput: 16})
+	for _, text := range []string{
+		`{{range 1000000000}}x{{end}}`,
+		`{{define "loop"}}{{template "loop"}}{{end}}`,
+		`{{call .fn}}`,
+		strings.Repeat("x", render.MaxTemplateLength+1),
+	} {
+		_, err := safe.Parse("unsafe", text)
+		assert.Error(t, err, text)
+	}
+
+	tmpl, err := safe.Parse("long", `{{.name}}{{.name}}`)
+	assert.NoError(t, err)
+	_, err = tmpl.Execute(map[string]interface{}{"name": "0123456789"})
+	assert.True(t, errors.Is(err, render.ErrOutputTooLong))
+
+	_, err = render.NewEngine(render.Options{}).Parse("trusted", `{{range 3}}x{{end}}`)
+	assert.NoError(t, err, "outside safe mode any template parses")
+}
+
+// stubTextTemplateSource serves one body rendered as text
+type stubTextTemplateSource struct {
+	tmpl *render.Template
+}
+
+func (s *stubTextTemplateSource) TextTemplate(templateName string, parameters map[string]interface{}) (*render.Template, string, bool) {
+	if templateName != "order_shipped" {
+		return nil, "", false
+	}
+	return s.tmpl, "es", true
+}
+
+// Test previews of bodies sent as text render them with the engine and list the gaps
+func TestPreviewTextTemplate(t *testing.T) {
+	tmpl, err := render.NewEngine(render.Options{Safe: true}).Parse("order_shipped/es", `Hola {{index . "name" | default "cliente"}}, tu pedido {{.order_id}} llega el {{date .eta "02/01"}}`)
+	assert.NoError(t, err)
+	previews := service.NewTemplatePreviewService(nil, &stubTextTemplateSource{tmpl: tmpl}, nil, new(MockLogger))
+
+	preview, err := previews.Preview(context.Background(), "order_shipped", "", "", map[string]interface{}{
+		"eta": "2026-03-02", "coupon": "SAVE10", meta.LocaleParameter: "es",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "es", preview.Language)
+	assert.Equal(t, "Hola cliente, tu pedido {{.order_id}} llega el 02/03", preview.Body)
+	assert.Equal(t, []string{"body {{.order_id}}"}, preview.MissingParameters)
+	assert.Equal(t, []string{"coupon"}, preview.UnusedParameters)
+
+	_, err = previews.Preview(context.Background(), "order_shipped", "", "", map[string]interface{}{"order_id": "ORD-1", "eta": "soon"})
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	_, err = previews.Preview(context.Background(), "order_confirmation", "", "", nil)
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition), "templates sent otherwise need a template source")
+}
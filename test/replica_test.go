@@ -0,0 +1,31 @@
+// test/replica_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/internal/repository"
+)
+
+// Test reads stay on the primary until the replica's lag has been checked
+func TestReadRouterDefaultsToPrimary(t *testing.T) {
+	primary, replica := &sqlx.DB{}, &sqlx.DB{}
+	router := repository.NewReadRouter(primary, replica, 5*time.Second, new(MockLogger))
+
+	assert.Same(t, primary, router.Reader(context.Background()))
+	assert.Same(t, primary, router.Reader(repository.WithPrimary(context.Background())))
+}
+
+// Test a router without a replica always reads from the primary
+func TestReadRouterWithoutReplica(t *testing.T) {
+	primary := &sqlx.DB{}
+	router := repository.NewReadRouter(primary, nil, 5*time.Second, new(MockLogger))
+	assert.Same(t, primary, router.Reader(context.Background()))
+
+	var unset *repository.ReadRouter
+	assert.Nil(t, unset.Reader(context.Background()))
+}
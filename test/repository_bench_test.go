@@ -0,0 +1,212 @@
+// test/repository_bench_test.go
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/pkg/utils"
+)
+
+// The repository benchmarks run against the Postgres in BENCH_DATABASE_URL, migrated like a
+// deployment's, and are skipped without it. Rows are written under a tenant of their own and
+// deleted afterwards, so a shared database can be used.
+const benchDatabaseEnv = "BENCH_DATABASE_URL"
+
+// benchSeedRows is how many messages ListMessages benchmarks page through
+const benchSeedRows = 20000
+
+// discardLogger drops everything, so logging does not weigh on the timings
+type discardLogger struct{}
+
+func (discardLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (discardLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (discardLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (discardLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (discardLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+var _ utils.Logger = discardLogger{}
+
+// benchRepo is a message repository under benchmark
+type benchRepo struct {
+	driver string
+	repo   repository.MessageRepository
+}
+
+// benchRepositories connects to the benchmark database and returns the sqlx repository and
+// the pgx one in front of it, with the tenant their rows are written under
+func benchRepositories(b *testing.B) ([]benchRepo, string) {
+	b.Helper()
+	dsn := os.Getenv(benchDatabaseEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", benchDatabaseEnv)
+	}
+	ctx := context.Background()
+
+	db, err := repository.Connect(ctx, func() string { return dsn })
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	pool, err := repository.ConnectPgx(ctx, func() string { return dsn }, repository.PgxPoolConfig{
+		MaxConns:           10,
+		MaxConnLifetime:    time.Hour,
+		MaxConnIdleTime:    time.Minute,
+		StatementCacheSize: 128,
+	})
+	if err != nil {
+		db.Close()
+		b.Fatalf("connect pgx: %v", err)
+	}
+
+	tenant := "bench-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	b.Cleanup(func() {
+		if _, err := db.ExecContext(ctx, `DELETE FROM messages WHERE tenant_id = $1`, tenant); err != nil {
+			b.Errorf("clean up tenant %s: %v", tenant, err)
+		}
+		pool.Close()
+		db.Close()
+	})
+
+	repo := repository.NewMessageRepository(db, discardLogger{})
+	return []benchRepo{
+		{driver: "pq", repo: repo},
+		{driver: "pgx", repo: repository.NewPgxMessageRepository(repo, pool, discardLogger{})},
+	}, tenant
+}
+
+// benchMessage returns the i-th message of a benchmark, spread over statuses, templates and
+// customers so filters select a share of them
+func benchMessage(tenant string, i int) *domain.Message {
+	now := time.Now()
+	return &domain.Message{
+		PhoneNumber: fmt.Sprintf("+1555%07d", i%10000000),
+		TemplateID:  []string{"order_confirmation", "order_shipped", "order_delivered"}[i%3],
+		Parameters:  map[string]interface{}{"order_id": "ORD-" + strconv.Itoa(i), "name": "Bench"},
+		OrderID:     "ORD-" + strconv.Itoa(i),
+		CustomerID:  "cust-" + strconv.Itoa(i%500),
+		Status:      []string{"queued", "sent", "delivered", "read", "failed"}[i%5],
+		TenantID:    tenant,
+		Attempt:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// benchSeed inserts count messages and returns their IDs
+func benchSeed(b *testing.B, repo repository.MessageRepository, tenant string, count int) []int64 {
+	b.Helper()
+	ctx := domain.WithTenant(context.Background(), tenant)
+	ids := make([]int64, 0, count)
+	for start := 0; start < count; start += 1000 {
+		batch := make([]*domain.Message, 0, 1000)
+		for i := start; i < start+1000 && i < count; i++ {
+			batch = append(batch, benchMessage(tenant, i))
+		}
+		batchIDs, err := repo.CreateMessages(ctx, batch)
+		if err != nil {
+			b.Fatalf("seed: %v", err)
+		}
+		ids = append(ids, batchIDs...)
+	}
+	return ids
+}
+
+// BenchmarkCreateMessage measures single-row inserts, the path of every send
+func BenchmarkCreateMessage(b *testing.B) {
+	repos, tenant := benchRepositories(b)
+	ctx := domain.WithTenant(context.Background(), tenant)
+	for _, r := range repos {
+		b.Run(r.driver, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.CreateMessage(ctx, benchMessage(tenant, i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCreateMessages measures bulk inserts by batch size; ns/op is per batch, and the
+// rows/s metric compares batch sizes
+func BenchmarkCreateMessages(b *testing.B) {
+	repos, tenant := benchRepositories(b)
+	ctx := domain.WithTenant(context.Background(), tenant)
+	for _, r := range repos {
+		for _, size := range []int{10, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/batch=%d", r.driver, size), func(b *testing.B) {
+				batch := make([]*domain.Message, size)
+				for i := range batch {
+					batch[i] = benchMessage(tenant, i)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := r.repo.CreateMessages(ctx, batch); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "rows/s")
+			})
+		}
+	}
+}
+
+// BenchmarkListMessages measures a page of a tenant's messages under the filters the API
+// offers, over benchSeedRows messages
+func BenchmarkListMessages(b *testing.B) {
+	repos, tenant := benchRepositories(b)
+	benchSeed(b, repos[0].repo, tenant, benchSeedRows)
+	ctx := domain.WithTenant(context.Background(), tenant)
+
+	filters := []struct {
+		name   string
+		filter domain.MessageFilter
+		sort   domain.MessageSort
+	}{
+		{"tenant", domain.MessageFilter{TenantID: tenant}, domain.MessageSort{}},
+		{"status", domain.MessageFilter{TenantID: tenant, Status: "delivered"}, domain.MessageSort{}},
+		{"customer", domain.MessageFilter{TenantID: tenant, CustomerID: "cust-42"}, domain.MessageSort{}},
+		{"template_status", domain.MessageFilter{TenantID: tenant, TemplateID: "order_shipped", Status: "failed"}, domain.MessageSort{}},
+		{"created_range", domain.MessageFilter{TenantID: tenant, CreatedAfter: time.Now().Add(-time.Hour), CreatedBefore: time.Now().Add(time.Hour)}, domain.MessageSort{By: "updated_at", Order: "asc"}},
+	}
+	for _, r := range repos {
+		for _, f := range filters {
+			b.Run(r.driver+"/"+f.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := r.repo.ListMessages(ctx, f.filter, f.sort, 50, 0); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkUpdateMessageStatus measures status updates of existing messages, the path of every
+// status callback
+func BenchmarkUpdateMessageStatus(b *testing.B) {
+	repos, tenant := benchRepositories(b)
+	ids := benchSeed(b, repos[0].repo, tenant, 10000)
+	ctx := domain.WithTenant(context.Background(), tenant)
+	statuses := []string{domain.StageSent, domain.StageDelivered, domain.StageRead}
+
+	for _, r := range repos {
+		b.Run(r.driver, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				id := ids[i%len(ids)]
+				if err := r.repo.UpdateMessageStatus(ctx, id, statuses[i%len(statuses)], "", "", "wamid.bench"+strconv.FormatInt(id, 10)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
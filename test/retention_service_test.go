@@ -0,0 +1,186 @@
+// test/retention_service_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+)
+
+// MockPartitionRepository mocks repository.PartitionRepository
+type MockPartitionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPartitionRepository) EnsureMessagePartitions(ctx context.Context, from time.Time, monthsAhead int) error {
+	args := m.Called(ctx, from, monthsAhead)
+	return args.Error(0)
+}
+
+func (m *MockPartitionRepository) DropMessagePartitionsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+// Test the purge deletes in batches until a short batch and uses the policy's cutoff
+func TestRetentionPurgeBatches(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	start := time.Now()
+	cutoffMatches := mock.MatchedBy(func(cutoff time.Time) bool {
+		return cutoff.Before(start.Add(-30*24*time.Hour+time.Second)) && cutoff.After(start.Add(-30*24*time.Hour-time.Minute))
+	})
+	mockRepo.On("PurgeMessagesBefore", mock.Anything, cutoffMatches, 100).Return(100, nil).Twice()
+	mockRepo.On("PurgeMessagesBefore", mock.Anything, cutoffMatches, 100).Return(7, nil).Once()
+
+	retention := service.NewRetentionService(mockRepo, nil, service.RetentionPolicy{MessageMaxAge: 30 * 24 * time.Hour, BatchSize: 100}, mockLogger)
+	purged, err := retention.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(207), purged)
+	mockRepo.AssertNumberOfCalls(t, "PurgeMessagesBefore", 3)
+}
+
+// Test a zero max age keeps everything
+func TestRetentionDisabled(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	retention := service.NewRetentionService(mockRepo, nil, service.RetentionPolicy{}, new(MockLogger))
+
+	purged, err := retention.PurgeExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+	mockRepo.AssertNotCalled(t, "PurgeMessagesBefore", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test whole partitions are dropped before the remaining rows are deleted
+func TestRetentionDropsPartitions(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockPartitions := new(MockPartitionRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockPartitions.On("DropMessagePartitionsBefore", mock.Anything, mock.Anything).Return(5000, nil)
+	mockRepo.On("PurgeMessagesBefore", mock.Anything, mock.Anything, 1000).Return(12, nil)
+
+	retention := service.NewRetentionService(mockRepo, mockPartitions, service.RetentionPolicy{MessageMaxAge: 90 * 24 * time.Hour}, mockLogger)
+	purged, err := retention.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5012), purged)
+}
+
+// Test provider captures are purged by their own max age, even while messages are kept forever
+func TestRetentionPurgesProviderCaptures(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	captures := new(MockProviderCaptureRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	start := time.Now()
+	captures.On("PurgeProviderCapturesBefore", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return !cutoff.After(start.Add(-72*time.Hour+time.Second)) && cutoff.After(start.Add(-72*time.Hour-time.Minute))
+	}), 10).Return(int64(10), nil).Once()
+	captures.On("PurgeProviderCapturesBefore", mock.Anything, mock.Anything, 10).Return(int64(3), nil).Once()
+
+	retention := service.NewRetentionServiceWithStores(mockRepo, nil, service.RetentionStores{Captures: captures}, service.RetentionPolicy{ProviderCaptureMaxAge: 72 * time.Hour, BatchSize: 10}, mockLogger)
+	purged, err := retention.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), purged)
+	captures.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "PurgeMessagesBefore", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test webhook failures are purged by their own max age alongside provider captures
+func TestRetentionPurgesWebhookFailures(t *testing.T) {
+	captures := new(MockProviderCaptureRepository)
+	failures := new(MockWebhookFailureRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	start := time.Now()
+	captures.On("PurgeProviderCapturesBefore", mock.Anything, mock.Anything, 10).Return(int64(2), nil).Once()
+	failures.On("PurgeWebhookFailuresBefore", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return !cutoff.After(start.Add(-30*24*time.Hour+time.Second)) && cutoff.After(start.Add(-30*24*time.Hour-time.Minute))
+	}), 10).Return(int64(4), nil).Once()
+
+	retention := service.NewRetentionServiceWithStores(new(MockMessageRepository), nil, service.RetentionStores{Captures: captures, WebhookFailures: failures}, service.RetentionPolicy{
+		ProviderCaptureMaxAge: 72 * time.Hour,
+		WebhookFailureMaxAge:  30 * 24 * time.Hour,
+		BatchSize:             10,
+	}, mockLogger)
+	purged, err := retention.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), purged)
+	captures.AssertExpectations(t)
+	failures.AssertExpectations(t)
+}
+
+// Test status history goes with messages, inbound messages by the webhook event max age and
+// outbox payloads by their own, and stores without a max age are left alone
+func TestRetentionPurgesWebhookEventsAndOutbox(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	history := new(MockStatusHistoryRepository)
+	outbox := new(MockOutboxRepository)
+	inbound := new(MockConversationRepository)
+	captures := new(MockProviderCaptureRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	start := time.Now()
+	olderThan := func(maxAge time.Duration) interface{} {
+		return mock.MatchedBy(func(cutoff time.Time) bool {
+			return !cutoff.After(start.Add(-maxAge+time.Second)) && cutoff.After(start.Add(-maxAge-time.Minute))
+		})
+	}
+	mockRepo.On("PurgeMessagesBefore", mock.Anything, olderThan(90*24*time.Hour), 10).Return(1, nil).Once()
+	history.On("PurgeStatusHistoryBefore", mock.Anything, olderThan(90*24*time.Hour), 10).Return(int64(3), nil).Once()
+	outbox.On("PurgePublishedOutboxBefore", mock.Anything, olderThan(24*time.Hour), 10).Return(int64(10), nil).Once()
+	outbox.On("PurgePublishedOutboxBefore", mock.Anything, olderThan(24*time.Hour), 10).Return(int64(0), nil).Once()
+	inbound.On("PurgeInboundBefore", mock.Anything, olderThan(14*24*time.Hour), 10).Return(int64(2), nil).Once()
+
+	retention := service.NewRetentionServiceWithStores(mockRepo, nil, service.RetentionStores{
+		Captures: captures, Inbound: inbound, Outbox: outbox, History: history,
+	}, service.RetentionPolicy{
+		MessageMaxAge:      90 * 24 * time.Hour,
+		WebhookEventMaxAge: 14 * 24 * time.Hour,
+		OutboxMaxAge:       24 * time.Hour,
+		BatchSize:          10,
+	}, mockLogger)
+	purged, err := retention.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), purged)
+	mockRepo.AssertExpectations(t)
+	history.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+	inbound.AssertExpectations(t)
+	captures.AssertNotCalled(t, "PurgeProviderCapturesBefore", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test rotation creates the configured months ahead and is skipped without partitions
+func TestRetentionRotatePartitions(t *testing.T) {
+	mockPartitions := new(MockPartitionRepository)
+	mockPartitions.On("EnsureMessagePartitions", mock.Anything, mock.Anything, 3).Return(nil)
+
+	retention := service.NewRetentionService(new(MockMessageRepository), mockPartitions, service.RetentionPolicy{PartitionMonthsAhead: 3}, new(MockLogger))
+	assert.NoError(t, retention.RotatePartitions(context.Background()))
+	mockPartitions.AssertExpectations(t)
+
+	unpartitioned := service.NewRetentionService(new(MockMessageRepository), nil, service.RetentionPolicy{PartitionMonthsAhead: 3}, new(MockLogger))
+	assert.NoError(t, unpartitioned.RotatePartitions(context.Background()))
+}
+
+// Test partition names follow the migration's messages_pYYYY_MM scheme in UTC
+func TestMessagePartitionName(t *testing.T) {
+	ts := time.Date(2024, 2, 29, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600))
+	assert.Equal(t, "messages_p2024_03", repository.MessagePartitionName(ts))
+}
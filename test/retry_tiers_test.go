@@ -0,0 +1,124 @@
+// test/retry_tiers_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/providerrouter"
+)
+
+var retryDelays = []time.Duration{time.Minute, 10 * time.Minute, time.Hour}
+
+// Test retry tier and dead-letter topic names
+func TestRetryTopicNames(t *testing.T) {
+	assert.Equal(t, "whatsapp-messages.retry.1m", queue.RetryTopic("whatsapp-messages", time.Minute))
+	assert.Equal(t, "whatsapp-messages.retry.10m", queue.RetryTopic("whatsapp-messages", 10*time.Minute))
+	assert.Equal(t, "whatsapp-messages.retry.1h", queue.RetryTopic("whatsapp-messages", time.Hour))
+	assert.Equal(t, "whatsapp-messages.retry.90s", queue.RetryTopic("whatsapp-messages", 90*time.Second))
+	assert.Equal(t, "whatsapp-messages.dlq", queue.DeadLetterTopic("whatsapp-messages"))
+}
+
+// Test which send failures are worth retrying later
+func TestIsTransientSendError(t *testing.T) {
+	assert.True(t, service.IsTransientSendError(domain.WrapError(domain.ErrProviderRateLimited, errors.New("130429"), "provider rate limit reached")))
+	assert.True(t, service.IsTransientSendError(domain.WrapError(domain.ErrProviderUnavailable, errors.New("131000"), "provider is unavailable")))
+	assert.True(t, service.IsTransientSendError(providerrouter.ErrCircuitOpen))
+	assert.True(t, service.IsTransientSendError(&url.Error{Op: "Post", URL: "https://graph.facebook.com", Err: errors.New("connection reset")}))
+
+	assert.False(t, service.IsTransientSendError(errors.New("no message ID in response")))
+	assert.False(t, service.IsTransientSendError(domain.NewError(domain.ErrNotFound, "message not found")))
+}
+
+// Test a transient failure on the main topic moves to the first retry tier
+func TestRetryHandlerSchedulesRetry(t *testing.T) {
+	data, err := service.EncodeQueueMessage(service.QueueMessage{MessageID: 7})
+	assert.NoError(t, err)
+
+	next := new(MockProducer)
+	next.On("Produce", mock.Anything, data).Return(nil)
+	repo := new(MockMessageRepository)
+	repo.On("UpdateMessageStatus", mock.Anything, int64(7), domain.StatusRetrying, "", mock.MatchedBy(func(msg string) bool {
+		return strings.HasPrefix(msg, "retrying in 1m0s after:")
+	}), "").Return(nil)
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	sendErr := domain.WrapError(domain.ErrProviderUnavailable, errors.New("131000"), "provider is unavailable")
+	handler := queue.RetryHandler(func(ctx context.Context, data []byte) error {
+		return sendErr
+	}, 0, next, service.NewSendRetryConfig(retryDelays, repo, logger), logger)
+
+	err = handler(context.Background(), data)
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+	next.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+// Test a transient failure on the last tier is dead-lettered and marked failed
+func TestRetryHandlerDeadLetters(t *testing.T) {
+	data, err := service.EncodeQueueMessage(service.QueueMessage{MessageID: 7})
+	assert.NoError(t, err)
+
+	dlq := new(MockProducer)
+	dlq.On("Produce", mock.Anything, data).Return(nil)
+	repo := new(MockMessageRepository)
+	repo.On("UpdateMessageStatus", mock.Anything, int64(7), "failed", "", mock.Anything, "").Return(nil)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	handler := queue.RetryHandler(func(ctx context.Context, data []byte) error {
+		return providerrouter.ErrCircuitOpen
+	}, len(retryDelays), dlq, service.NewSendRetryConfig(retryDelays, repo, logger), logger)
+
+	assert.ErrorIs(t, handler(context.Background(), data), providerrouter.ErrCircuitOpen)
+	dlq.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+// Test permanent failures and successes are not moved along the retry chain
+func TestRetryHandlerIgnoresPermanentFailures(t *testing.T) {
+	next := new(MockProducer)
+	repo := new(MockMessageRepository)
+	cfg := service.NewSendRetryConfig(retryDelays, repo, new(MockLogger))
+
+	failing := queue.RetryHandler(func(ctx context.Context, data []byte) error {
+		return errors.New("no message ID in response")
+	}, 0, next, cfg, new(MockLogger))
+	assert.Error(t, failing(context.Background(), []byte(`{"message_id":7}`)))
+
+	succeeding := queue.RetryHandler(func(ctx context.Context, data []byte) error {
+		return nil
+	}, 0, next, cfg, new(MockLogger))
+	assert.NoError(t, succeeding(context.Background(), []byte(`{"message_id":7}`)))
+
+	next.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test the producer failing leaves both errors to the consumer
+func TestRetryHandlerProduceFailure(t *testing.T) {
+	next := new(MockProducer)
+	next.On("Produce", mock.Anything, mock.Anything).Return(errors.New("broker down"))
+	repo := new(MockMessageRepository)
+	logger := new(MockLogger)
+	logger.On("Error", mock.Anything, mock.Anything).Return()
+
+	handler := queue.RetryHandler(func(ctx context.Context, data []byte) error {
+		return providerrouter.ErrCircuitOpen
+	}, 1, next, service.NewSendRetryConfig(retryDelays, repo, logger), logger)
+
+	err := handler(context.Background(), []byte(`{"message_id":7}`))
+	assert.ErrorIs(t, err, providerrouter.ErrCircuitOpen)
+	assert.ErrorContains(t, err, "broker down")
+	repo.AssertNotCalled(t, "UpdateMessageStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
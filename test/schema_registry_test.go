@@ -0,0 +1,134 @@
+// test/schema_registry_test.go
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/schemaregistry"
+)
+
+// fakeRegistry serves the subset of the Schema Registry API the client uses
+func fakeRegistry(t *testing.T) (*httptest.Server, *int) {
+	var mu sync.Mutex
+	schemas := map[int]string{}
+	fetches := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/versions"):
+			var body struct {
+				Schema string `json:"schema"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			id := len(schemas) + 1
+			schemas[id] = body.Schema
+			fmt.Fprintf(w, `{"id":%d}`, id)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/schemas/ids/"):
+			var id int
+			fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/schemas/ids/"), "%d", &id)
+			schema, ok := schemas[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error_code":40403,"message":"Schema not found"}`)
+				return
+			}
+			fetches++
+			_ = json.NewEncoder(w).Encode(map[string]string{"schema": schema})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+// Test a queue message survives the Avro round trip with the wire format header
+func TestSchemaRegistryRoundTrip(t *testing.T) {
+	server, fetches := fakeRegistry(t)
+	client := schemaregistry.NewClient(schemaregistry.Config{URL: server.URL})
+	ctx := context.Background()
+
+	serializer, err := schemaregistry.NewSerializer(ctx, client, schemaregistry.SubjectForTopic("whatsapp-messages"), service.QueueMessageAvroSchema)
+	assert.NoError(t, err)
+
+	payload, _ := service.EncodeQueueMessage(service.QueueMessage{
+		MessageID:   42,
+		PhoneNumber: "+447700900123",
+		TemplateID:  "order_confirmation",
+		Parameters:  map[string]interface{}{"name": "Ada", "total": 12.5, "items": 3},
+		TenantID:    "acme",
+	})
+	framed, err := serializer.Serialize(ctx, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), framed[0])
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(framed[1:5]))
+	assert.True(t, schemaregistry.IsFramed(framed))
+
+	// A fresh client has to look the writer schema up by ID
+	deserializer := schemaregistry.NewDeserializer(schemaregistry.NewClient(schemaregistry.Config{URL: server.URL}))
+	decoded, err := deserializer.Deserialize(ctx, framed)
+	assert.NoError(t, err)
+	_, err = deserializer.Deserialize(ctx, framed)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *fetches, "schemas are cached by ID")
+
+	queueMsg, err := service.DecodeQueueMessage(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), queueMsg.MessageID)
+	assert.Equal(t, "acme", queueMsg.TenantID)
+	assert.Equal(t, "Ada", queueMsg.Parameters["name"])
+	assert.Equal(t, 12.5, queueMsg.Parameters["total"])
+	assert.EqualValues(t, 3, queueMsg.Parameters["items"])
+}
+
+// Test payloads that do not match the schema are refused
+func TestSchemaRegistryRejectsMismatchedPayload(t *testing.T) {
+	server, _ := fakeRegistry(t)
+	client := schemaregistry.NewClient(schemaregistry.Config{URL: server.URL})
+
+	serializer, err := schemaregistry.NewSerializer(context.Background(), client, "whatsapp-status-events-value", service.WebhookEventAvroSchema)
+	assert.NoError(t, err)
+
+	_, err = serializer.Serialize(context.Background(), []byte(`{"message_id":"not-a-number","external_id":"wamid.1","status":"sent","phone_number":"+1"}`))
+	assert.Error(t, err)
+	_, err = serializer.Serialize(context.Background(), []byte(`{"message_id":1,"status":"sent","phone_number":"+1"}`))
+	assert.Error(t, err, "external_id has no default")
+}
+
+// Test the consumer handler accepts both framed and plain JSON payloads
+func TestRegistryHandlerPassesThroughJSON(t *testing.T) {
+	server, _ := fakeRegistry(t)
+	client := schemaregistry.NewClient(schemaregistry.Config{URL: server.URL})
+	serializer, err := schemaregistry.NewSerializer(context.Background(), client, "whatsapp-messages-value", service.QueueMessageAvroSchema)
+	assert.NoError(t, err)
+
+	var received []service.QueueMessage
+	handler := queue.RegistryHandler(func(ctx context.Context, data []byte) error {
+		queueMsg, err := service.DecodeQueueMessage(data)
+		received = append(received, queueMsg)
+		return err
+	}, schemaregistry.NewDeserializer(client))
+
+	plain, _ := service.EncodeQueueMessage(service.QueueMessage{MessageID: 1, PhoneNumber: "+1", TemplateID: "t", Parameters: map[string]interface{}{"code": "1234"}})
+	framed, err := serializer.Serialize(context.Background(), plain)
+	assert.NoError(t, err)
+
+	assert.NoError(t, handler(context.Background(), plain))
+	assert.NoError(t, handler(context.Background(), framed))
+	assert.Len(t, received, 2)
+	assert.Equal(t, received[0], received[1])
+}
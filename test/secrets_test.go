@@ -0,0 +1,60 @@
+// test/secrets_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/pkg/secrets"
+)
+
+// Test the Vault provider reads KV v2 data and the store reports changed values on reload
+func TestVaultSecretStore(t *testing.T) {
+	token := "first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/whatsapp", r.URL.Path)
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{
+					"META_ACCESS_TOKEN": token,
+					"DATABASE_PASSWORD": "db-pass",
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	store := secrets.NewStore(secrets.NewVaultProvider(server.URL, "vault-token", "/secret/data/whatsapp"), 0)
+
+	var changes []string
+	store.OnChange(func(key, value string) {
+		changes = append(changes, key+"="+value)
+	})
+
+	assert.NoError(t, store.Load(context.Background()))
+	value, ok := store.Get("META_ACCESS_TOKEN")
+	assert.True(t, ok)
+	assert.Equal(t, "first", value)
+	assert.Len(t, changes, 2)
+
+	token = "second"
+	changes = nil
+	assert.NoError(t, store.Load(context.Background()))
+	assert.Equal(t, []string{"META_ACCESS_TOKEN=second"}, changes)
+}
+
+// Test Vault errors are surfaced
+func TestVaultSecretStoreError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	store := secrets.NewStore(secrets.NewVaultProvider(server.URL, "bad", "secret/data/whatsapp"), 0)
+	assert.Error(t, store.Load(context.Background()))
+}
@@ -0,0 +1,113 @@
+// test/service_info_test.go
+package test
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// newServiceInfoHandler serves a deployment sending through meta with the given service
+func newServiceInfoHandler(svc service.MessageService) *handler.GrpcMessageHandler {
+	info := handler.ServiceInfo{Provider: "meta", MaxInFlightSends: 8, MaxQueuedSends: 32, CatalogID: "catalog-1"}
+	return handler.NewGrpcMessageHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, info, utils.NewPlainPhoneNumberHasher(), discardLogger{})
+}
+
+// Test GetServiceInfo reports the API version, features, provider and limits
+func TestGetServiceInfo(t *testing.T) {
+	h := newServiceInfoHandler(nil)
+
+	resp, err := h.GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, handler.APIVersion, resp.ApiVersion)
+	assert.Regexp(t, regexp.MustCompile(`^\d+\.\d+\.\d+$`), resp.ApiVersion)
+	assert.Equal(t, handler.Features, resp.Features)
+	assert.Equal(t, "meta", resp.Provider)
+	assert.Equal(t, int32(8), resp.Limits.MaxInFlightSends)
+	assert.Equal(t, int32(32), resp.Limits.MaxQueuedSends)
+	assert.Equal(t, int32(10), resp.Limits.DefaultPageSize)
+}
+
+// Test a client changing the reported features does not change what later calls report
+func TestGetServiceInfoFeaturesAreCopied(t *testing.T) {
+	h := newServiceInfoHandler(nil)
+
+	first, err := h.GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{})
+	require.NoError(t, err)
+	first.Features[0] = "tampered"
+
+	second, err := h.GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{})
+	require.NoError(t, err)
+	assert.NotEqual(t, "tampered", handler.Features[0])
+	assert.Equal(t, handler.Features, second.Features)
+}
+
+// Test ListMessages pages by the default page size GetServiceInfo advertises
+func TestGetServiceInfoDefaultPageSizeApplies(t *testing.T) {
+	repo := new(MockMessageRepository)
+	h := newServiceInfoHandler(service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{}))
+
+	info, err := h.GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{})
+	require.NoError(t, err)
+	repo.On("ListMessages", mock.Anything, mock.Anything, mock.Anything, int(info.Limits.DefaultPageSize), 0).Return([]*domain.Message{}, nil).Once()
+	repo.On("CountMessages", mock.Anything, mock.Anything).Return(0, nil).Once()
+
+	_, err = h.ListMessages(context.Background(), &pb.ListMessagesRequest{})
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// Test the handshake over gRPC: authenticated callers get the service info, others are
+// refused before it is served
+func TestGetServiceInfoOverGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(handler.TenantInterceptor(tenantCredentials), handler.ValidationInterceptor()))
+	pb.RegisterWhatsAppServiceServer(server, newServiceInfoHandler(nil))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewWhatsAppServiceClient(conn)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-api-key", "acme-key"))
+	resp, err := client.GetServiceInfo(ctx, &pb.GetServiceInfoRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, handler.APIVersion, resp.ApiVersion)
+	assert.Contains(t, resp.Features, "request_validation")
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+		code codes.Code
+	}{
+		{"no key", metadata.MD{}, codes.Unauthenticated},
+		{"unknown key", metadata.Pairs("x-api-key", "stolen-key"), codes.Unauthenticated},
+		{"other tenant", metadata.Pairs("x-api-key", "acme-key", "x-tenant-id", "globex"), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetServiceInfo(metadata.NewOutgoingContext(context.Background(), tt.md), &pb.GetServiceInfoRequest{})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
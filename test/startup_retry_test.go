@@ -0,0 +1,102 @@
+// test/startup_retry_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/queue"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test the backoff doubles up to its maximum
+func TestBackoffDelay(t *testing.T) {
+	b := utils.Backoff{Attempts: 10, Initial: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, time.Second, b.Delay(1))
+	assert.Equal(t, 2*time.Second, b.Delay(2))
+	assert.Equal(t, 4*time.Second, b.Delay(3))
+	assert.Equal(t, 5*time.Second, b.Delay(4))
+	assert.Equal(t, 5*time.Second, b.Delay(9))
+}
+
+// Test a dependency that comes up after a few attempts
+func TestRetryEventuallySucceeds(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	calls := 0
+	err := utils.Retry(context.Background(), utils.Backoff{Attempts: 5, Initial: time.Millisecond, Max: time.Millisecond}, logger, "database", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	logger.AssertNumberOfCalls(t, "Warn", 2)
+}
+
+// Test retries are bounded and return the last error
+func TestRetryGivesUp(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	refused := errors.New("connection refused")
+
+	calls := 0
+	err := utils.Retry(context.Background(), utils.Backoff{Attempts: 3, Initial: time.Millisecond, Max: time.Millisecond}, logger, "kafka", func(ctx context.Context) error {
+		calls++
+		return refused
+	})
+
+	assert.ErrorIs(t, err, refused)
+	assert.ErrorContains(t, err, "kafka: gave up after 3 attempts")
+	assert.Equal(t, 3, calls)
+}
+
+// Test a canceled context stops the wait
+func TestRetryCanceled(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := utils.Retry(ctx, utils.Backoff{Attempts: 3, Initial: time.Hour, Max: time.Hour}, logger, "kafka", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	assert.Error(t, err)
+}
+
+// Test the retry chain lists every tier and the DLQ
+func TestRetryChainTopics(t *testing.T) {
+	assert.Nil(t, queue.RetryChainTopics("whatsapp-messages", nil))
+	assert.Equal(t, []string{
+		"whatsapp-messages.retry.1m",
+		"whatsapp-messages.retry.10m",
+		"whatsapp-messages.dlq",
+	}, queue.RetryChainTopics("whatsapp-messages", []time.Duration{time.Minute, 10 * time.Minute}))
+}
+
+// Test topic layouts parse as partitions with an optional replication factor
+func TestParseTopicLayout(t *testing.T) {
+	partitions, replicationFactor, err := queue.ParseTopicLayout("12:3")
+	assert.NoError(t, err)
+	assert.Equal(t, 12, partitions)
+	assert.Equal(t, 3, replicationFactor)
+
+	partitions, replicationFactor, err = queue.ParseTopicLayout("6")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, partitions)
+	assert.Equal(t, 0, replicationFactor)
+
+	for _, invalid := range []string{"", "0", "six", "6:0", "6:x"} {
+		_, _, err := queue.ParseTopicLayout(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
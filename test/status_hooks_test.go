@@ -0,0 +1,81 @@
+// test/status_hooks_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+)
+
+// statusTransition is one call of a recording hook
+type statusTransition struct {
+	id       int64
+	from, to string
+}
+
+// recordingHooks returns a hook set recording its transitions after a panicking hook
+func recordingHooks(transitions *[]statusTransition) *service.StatusHooks {
+	hooks := service.NewStatusHooks(newQualityLogger())
+	hooks.Register(service.StatusHookFunc(func(context.Context, *domain.Message, string, string) {
+		panic("broken hook")
+	}))
+	hooks.Register(service.StatusHookFunc(func(_ context.Context, msg *domain.Message, oldStatus, newStatus string) {
+		*transitions = append(*transitions, statusTransition{msg.ID, oldStatus, newStatus})
+	}))
+	return hooks
+}
+
+// Test a send reports creation and each status change, and a panicking hook doesn't stop it
+func TestStatusHooksOnSend(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockClient := new(MockWhatsAppClient)
+	mockProducer := new(MockProducer)
+	queued := &domain.Message{ID: 1, PhoneNumber: "1234567890", TemplateID: "welcome", Status: "queued"}
+	mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(1)).Return(queued, nil).Twice()
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(1), "processing", "", "", "").Return(nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(1)).Return(&domain.Message{ID: 1, Status: "processing"}, nil).Once()
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(1), "sent", "", "", "wamid.1").Return(nil)
+
+	var resp meta.MessageResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"messages": [{"id": "wamid.1"}]}`), &resp))
+	mockClient.On("SendTemplateMessage", mock.Anything, "1234567890", "welcome", mock.Anything).Return(&resp, nil)
+
+	var transitions []statusTransition
+	repo := service.NewStatusHookedRepository(mockRepo, recordingHooks(&transitions), newQualityLogger())
+	svc := service.NewMessageService(repo, mockClient, mockProducer, newDebugLogger())
+
+	_, err := svc.SendTemplateMessage(context.Background(), "1234567890", "welcome", nil, "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), []byte(`{"message_id":1}`)))
+
+	assert.Equal(t, []statusTransition{{1, "", "queued"}, {1, "queued", "processing"}, {1, "processing", "sent"}}, transitions)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test a status callback batch reports each changed message once and skips repeats
+func TestStatusHooksOnStatusBatch(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	updates := []domain.StatusUpdate{{MessageID: 1, Status: "delivered"}, {MessageID: 1, Status: "read"}, {MessageID: 2, Status: "read"}}
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, updates).Return(map[int64]domain.StatusUpdateResult{
+		1: {Sequence: 3, PreviousStatus: "sent", Status: "read"},
+		2: {Sequence: 5, PreviousStatus: "read", Status: "read"},
+	}, nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(1)).Return(&domain.Message{ID: 1, Status: "read"}, nil)
+
+	var transitions []statusTransition
+	repo := service.NewStatusHookedRepository(mockRepo, recordingHooks(&transitions), newQualityLogger())
+
+	_, err := repo.UpdateMessageStatuses(context.Background(), updates)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []statusTransition{{1, "sent", "read"}}, transitions)
+	mockRepo.AssertNotCalled(t, "GetMessageByID", mock.Anything, int64(2))
+}
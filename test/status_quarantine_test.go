@@ -0,0 +1,127 @@
+// test/status_quarantine_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// MockStatusQuarantineRepository mocks repository.StatusQuarantineRepository
+type MockStatusQuarantineRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatusQuarantineRepository) QuarantineStatus(ctx context.Context, status *domain.QuarantinedStatus) error {
+	args := m.Called(ctx, status)
+	return args.Error(0)
+}
+
+func (m *MockStatusQuarantineRepository) ListQuarantinedStatuses(ctx context.Context, limit int) ([]domain.QuarantinedStatus, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]domain.QuarantinedStatus), args.Error(1)
+}
+
+func (m *MockStatusQuarantineRepository) DeleteQuarantinedStatuses(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockStatusQuarantineRepository) ExpireQuarantinedStatuses(ctx context.Context, cutoff time.Time) (map[string]int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+// Test a status for an external ID no message has yet is quarantined instead of dropped
+func TestProcessWebhookQuarantinesUnknownExternalID(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	quarantine := new(MockStatusQuarantineRepository)
+
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(0, domain.NewError(domain.ErrNotFound, "message not found"))
+	var quarantined *domain.QuarantinedStatus
+	quarantine.On("QuarantineStatus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		quarantined = args.Get(1).(*domain.QuarantinedStatus)
+	}).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Signatures:       testWebhookSignatures,
+		Quarantine:       quarantine,
+		QuarantineWindow: 15 * time.Minute,
+	}, mockLogger, "verify-token")
+
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), []byte(testStatusWebhook), signWebhook([]byte(testStatusWebhook)), "/webhook"))
+	assert.Equal(t, domain.ProviderMeta, quarantined.Provider)
+	assert.Equal(t, "tenant-a", quarantined.TenantID)
+	assert.Equal(t, "delivered", quarantined.Status)
+	assert.Equal(t, "wamid.ABC:delivered:1700000000", quarantined.DedupeKey)
+	assert.Equal(t, time.Unix(1700000000, 0), quarantined.At)
+	mockRepo.AssertNotCalled(t, "UpdateMessageStatuses", mock.Anything, mock.Anything)
+}
+
+// Test quarantined statuses are applied once their message is known and expire after the window
+func TestMatchQuarantinedStatuses(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	quarantine := new(MockStatusQuarantineRepository)
+
+	quarantine.On("ExpireQuarantinedStatuses", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff) > 14*time.Minute && time.Since(cutoff) < 16*time.Minute
+	})).Return(map[string]int64{domain.ProviderTwilio: 2}, nil)
+	quarantine.On("ListQuarantinedStatuses", mock.Anything, mock.Anything).Return([]domain.QuarantinedStatus{
+		{ID: 1, Provider: domain.ProviderMeta, TenantID: "tenant-a", ExternalID: "wamid.A", Status: "delivered", DedupeKey: "wamid.A:delivered:1"},
+		{ID: 2, Provider: domain.ProviderMeta, TenantID: "tenant-a", ExternalID: "wamid.B", Status: "delivered", DedupeKey: "wamid.B:delivered:1"},
+	}, nil)
+	quarantine.On("DeleteQuarantinedStatuses", mock.Anything, []int64{1}).Return(nil)
+
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.A").Return(42, nil)
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.B").Return(0, domain.NewError(domain.ErrNotFound, "message not found"))
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, []domain.StatusUpdate{
+		{MessageID: 42, Status: "delivered", ExternalID: "wamid.A"},
+	}).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 2}}, nil)
+	mockProducer.On("ProduceWithKey", mock.Anything, []byte("42"), mock.Anything).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Quarantine:       quarantine,
+		QuarantineWindow: 15 * time.Minute,
+	}, mockLogger, "verify-token")
+
+	matched, err := svc.MatchQuarantined(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, matched)
+	quarantine.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+// Test a status whose external ID is stored while the webhook waits is applied, not quarantined
+func TestProcessWebhookWaitsForExternalID(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	quarantine := new(MockStatusQuarantineRepository)
+
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(0, domain.NewError(domain.ErrNotFound, "message not found")).Twice()
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(42, nil).Once()
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, mock.Anything).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 2}}, nil)
+	mockProducer.On("ProduceWithKey", mock.Anything, []byte("42"), mock.Anything).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Signatures:       testWebhookSignatures,
+		Quarantine:       quarantine,
+		QuarantineWindow: 15 * time.Minute,
+		LookupWait:       time.Second,
+	}, newDebugLogger(), "verify-token")
+
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), []byte(testStatusWebhook), signWebhook([]byte(testStatusWebhook)), "/webhook"))
+	mockRepo.AssertExpectations(t)
+	quarantine.AssertNotCalled(t, "QuarantineStatus", mock.Anything, mock.Anything)
+}
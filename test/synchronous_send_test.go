@@ -0,0 +1,86 @@
+// test/synchronous_send_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+)
+
+// Test a synchronous send skips the queue and returns the message sent, with its external ID
+func TestSynchronousSend(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockProducer := new(MockProducer)
+	queued := &domain.Message{ID: 9, PhoneNumber: "+1234567890", TemplateID: "otp", Status: "queued"}
+	mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(9, nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(9)).Return(queued, nil).Once()
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(9), "processing", "", "", "").Return(nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(9), "sent", "", "", "wamid.9").Return(nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(9)).Return(&domain.Message{ID: 9, PhoneNumber: "+1234567890", TemplateID: "otp", Status: "sent", ExternalID: "wamid.9"}, nil).Once()
+
+	var resp meta.MessageResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"messages": [{"id": "wamid.9"}]}`), &resp))
+	mockWhatsApp.On("SendTemplateMessage", mock.Anything, "+1234567890", "otp", mock.Anything).Return(&resp, nil)
+
+	inner := service.NewMessageService(mockRepo, mockWhatsApp, mockProducer, newDebugLogger())
+	svc := service.NewSynchronousMessageService(inner, mockRepo, new(MockLogger))
+
+	ctx := domain.WithSynchronous(context.Background())
+	msg, err := svc.SendTemplateMessage(ctx, "+1234567890", "otp", map[string]interface{}{"code": "123456"}, "", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "sent", msg.Status)
+	assert.Equal(t, "wamid.9", msg.ExternalID)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test a synchronous send collapsed into a message still in the queue doesn't send it twice
+func TestSynchronousSendDuplicateNotSentAgain(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	earlier := &domain.Message{ID: 42, PhoneNumber: "+1234567890", TemplateID: "otp", Status: "queued"}
+	mockRepo.On("FindRecentDuplicate", mock.Anything, mock.Anything, mock.Anything).Return(earlier, nil)
+
+	inner := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), new(MockLogger))
+	deduplicated := service.NewDuplicateSuppressingMessageService(inner, mockRepo, 10*time.Minute, newQualityLogger())
+	svc := service.NewSynchronousMessageService(deduplicated, mockRepo, new(MockLogger))
+
+	msg, err := svc.SendTemplateMessage(domain.WithSynchronous(context.Background()), "+1234567890", "otp", nil, "", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), msg.ID)
+	assert.Equal(t, "queued", msg.Status)
+	mockWhatsApp.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test a synchronous send the provider rejects fails the call and the message
+func TestSynchronousSendRejected(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockWhatsApp := new(MockWhatsAppClient)
+	mockRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(3, nil)
+	mockRepo.On("GetMessageByID", mock.Anything, int64(3)).Return(&domain.Message{ID: 3, PhoneNumber: "+1234567890", TemplateID: "otp", Status: "queued"}, nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(3), "processing", "", "", "").Return(nil)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(3), "failed", mock.Anything, mock.Anything, "").Return(nil)
+	mockWhatsApp.On("SendTemplateMessage", mock.Anything, "+1234567890", "otp", mock.Anything).Return(nil, domain.NewError(domain.ErrValidation, "template parameters do not match"))
+
+	logger := newDebugLogger()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	inner := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), logger)
+	svc := service.NewSynchronousMessageService(inner, mockRepo, new(MockLogger))
+
+	msg, err := svc.SendTemplateMessage(domain.WithSynchronous(context.Background()), "+1234567890", "otp", nil, "", "")
+
+	assert.Error(t, err)
+	assert.Nil(t, msg)
+	mockRepo.AssertExpectations(t)
+}
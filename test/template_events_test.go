@@ -0,0 +1,105 @@
+// test/template_events_test.go
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/alerts"
+	"messaging-microservice/pkg/utils"
+)
+
+// templateWebhook wraps template changes in a Meta webhook payload
+func templateWebhook(field, value string) []byte {
+	return []byte(`{"object": "whatsapp_business_account", "entry": [{"id": "WABA-1", "changes": [{"field": "` + field + `", "value": ` + value + `}]}]}`)
+}
+
+// newTemplateEventWebhook returns a webhook service passing template events to a service
+// disabling templates through repo and alerting notifier
+func newTemplateEventWebhook(repo *MockTemplateRepository, notifier alerts.Notifier) service.WebhookService {
+	logger := newQualityLogger()
+	templates := service.NewTemplateEventService(service.NewTemplateSwitch(repo, logger), notifier, logger)
+	return service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Templates: templates, Signatures: testWebhookSignatures}, logger, "verify-token")
+}
+
+// Test a template Meta pauses is disabled until Meta reinstates it, alerting its owners both times
+func TestTemplatePausedByMeta(t *testing.T) {
+	repo := new(MockTemplateRepository)
+	repo.On("DisableTemplate", mock.Anything, mock.MatchedBy(func(template *domain.DisabledTemplate) bool {
+		return template.TemplateID == "promo_spring" && template.Actor == "meta" && template.Reason == "Meta PAUSED"
+	})).Return(nil).Once()
+	repo.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{
+		{TemplateID: "promo_spring", Reason: "Meta PAUSED", Actor: "meta"},
+	}, nil).Once()
+	repo.On("EnableTemplate", mock.Anything, "promo_spring").Return(true, nil).Once()
+	repo.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{}, nil).Once()
+
+	notifier := new(MockNotifier)
+	var sent []alerts.Alert
+	notifier.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(alerts.Alert))
+	}).Return(nil)
+
+	svc := newTemplateEventWebhook(repo, notifier)
+	ctx := context.Background()
+
+	paused := templateWebhook(domain.TemplateFieldStatusUpdate, `{"event": "PAUSED", "message_template_id": 123, "message_template_name": "promo_spring", "message_template_language": "en_US", "reason": null}`)
+	assert.NoError(t, svc.ProcessWebhook(ctx, paused, signWebhook(paused), "https://example.com/webhook"))
+
+	reinstated := templateWebhook(domain.TemplateFieldStatusUpdate, `{"event": "REINSTATED", "message_template_id": 123, "message_template_name": "promo_spring", "message_template_language": "en_US"}`)
+	assert.NoError(t, svc.ProcessWebhook(ctx, reinstated, signWebhook(reinstated), "https://example.com/webhook"))
+
+	repo.AssertExpectations(t)
+	if assert.Len(t, sent, 2) {
+		assert.Equal(t, "template_status:promo_spring", sent[0].Key)
+		assert.False(t, sent[0].Resolved)
+		assert.Contains(t, sent[0].Summary, "refused")
+		assert.Equal(t, "template_status:promo_spring", sent[1].Key)
+		assert.True(t, sent[1].Resolved)
+	}
+}
+
+// Test Meta's events leave a template disabled by an operator alone
+func TestTemplateEventsKeepOperatorKillSwitch(t *testing.T) {
+	repo := new(MockTemplateRepository)
+	repo.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{
+		{TemplateID: "promo_spring", Reason: "wrong price", Actor: "ops"},
+	}, nil)
+	templateSwitch := service.NewTemplateSwitch(repo, new(MockLogger))
+	assert.NoError(t, templateSwitch.Refresh(context.Background()))
+
+	notifier := new(MockNotifier)
+	notifier.On("Notify", mock.Anything, mock.Anything).Return(nil)
+	svc := service.NewTemplateEventService(templateSwitch, notifier, newQualityLogger())
+
+	assert.NoError(t, svc.HandleTemplateEvent(context.Background(), domain.TemplateEvent{
+		TemplateID: "promo_spring", Field: domain.TemplateFieldStatusUpdate, Event: "DISABLED",
+	}))
+	assert.NoError(t, svc.HandleTemplateEvent(context.Background(), domain.TemplateEvent{
+		TemplateID: "promo_spring", Field: domain.TemplateFieldStatusUpdate, Event: "REINSTATED",
+	}))
+
+	repo.AssertNotCalled(t, "DisableTemplate", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "EnableTemplate", mock.Anything, mock.Anything)
+	notifier.AssertNumberOfCalls(t, "Notify", 1)
+}
+
+// Test a template's quality dropping to red alerts its owners without disabling it
+func TestTemplateQualityAlert(t *testing.T) {
+	repo := new(MockTemplateRepository)
+	notifier := new(MockNotifier)
+	notifier.On("Notify", mock.Anything, mock.MatchedBy(func(alert alerts.Alert) bool {
+		return alert.Key == "template_quality:promo_spring" && !alert.Resolved
+	})).Return(nil).Once()
+
+	svc := newTemplateEventWebhook(repo, notifier)
+	quality := templateWebhook(domain.TemplateFieldQualityUpdate, `{"previous_quality_score": "GREEN", "new_quality_score": "RED", "event": "", "message_template_id": 123, "message_template_name": "promo_spring", "message_template_language": "en_US"}`)
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), quality, signWebhook(quality), "https://example.com/webhook"))
+
+	notifier.AssertExpectations(t)
+	repo.AssertNotCalled(t, "DisableTemplate", mock.Anything, mock.Anything)
+}
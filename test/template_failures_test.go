@@ -0,0 +1,71 @@
+// test/template_failures_test.go
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/alerts"
+)
+
+// Test a template failing more than the policy allows is disabled once, with an alert, while
+// other templates and failures after a send are not counted against it
+func TestTemplateFailureMonitor(t *testing.T) {
+	repo := new(MockTemplateRepository)
+	repo.On("DisableTemplate", mock.Anything, mock.MatchedBy(func(template *domain.DisabledTemplate) bool {
+		return template.TemplateID == "order_confirmation" && template.Actor == "failure_monitor" && template.Reason == "6 of its last 10 sends failed within 5m0s"
+	})).Return(nil).Once()
+	repo.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{
+		{TemplateID: "order_confirmation", Reason: "6 of its last 10 sends failed within 5m0s", Actor: "failure_monitor"},
+	}, nil).Once()
+
+	notifier := new(MockNotifier)
+	var sent []alerts.Alert
+	notifier.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(alerts.Alert))
+	}).Return(nil)
+
+	logger := newQualityLogger()
+	templates := service.NewTemplateSwitch(repo, logger)
+	monitor := service.NewTemplateFailureMonitor(templates, service.TemplateFailurePolicy{
+		MaxFailureRate: 0.5,
+		Window:         5 * time.Minute,
+		MinMessages:    10,
+	}, notifier, logger)
+
+	ctx := context.Background()
+	orderConfirmation := &domain.Message{TemplateID: "order_confirmation"}
+	shipment := &domain.Message{TemplateID: "shipment_dispatched"}
+	for i := 0; i < 4; i++ {
+		monitor.OnStatusChange(ctx, orderConfirmation, "queued", "sent")
+		// Delivery failures are about the recipient
+		monitor.OnStatusChange(ctx, orderConfirmation, "sent", "failed")
+		monitor.OnStatusChange(ctx, shipment, "queued", "failed")
+	}
+	for i := 0; i < 5; i++ {
+		monitor.OnStatusChange(ctx, orderConfirmation, "queued", "failed")
+	}
+	// 5 of 9: too few sends to judge
+	assert.Empty(t, sent)
+
+	monitor.OnStatusChange(ctx, orderConfirmation, "retrying", "failed")
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "template_failure_rate:order_confirmation", sent[0].Key)
+		assert.InDelta(t, 0.6, sent[0].Value, 0.001)
+		assert.Contains(t, sent[0].Summary, "was disabled")
+	}
+	_, disabled := templates.Disabled("order_confirmation")
+	assert.True(t, disabled)
+
+	// Sends refused by the kill switch don't trip it again
+	for i := 0; i < 20; i++ {
+		monitor.OnStatusChange(ctx, orderConfirmation, "queued", "failed")
+	}
+	assert.Len(t, sent, 1)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,145 @@
+// test/template_preview_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+)
+
+// stubTemplateSource serves one template definition
+type stubTemplateSource struct {
+	template *meta.TemplateDefinition
+	wabaID   string
+}
+
+func (s *stubTemplateSource) GetTemplate(ctx context.Context, wabaID, name, language string) (*meta.TemplateDefinition, error) {
+	s.wabaID = wabaID
+	if s.template == nil || s.template.Name != name || s.template.Language != language {
+		return nil, meta.ErrTemplateNotFound
+	}
+	return s.template, nil
+}
+
+func shippingTemplate() *meta.TemplateDefinition {
+	return &meta.TemplateDefinition{
+		Name:     "order_shipped",
+		Language: "en_US",
+		Status:   "APPROVED",
+		Category: "UTILITY",
+		Components: []meta.TemplateComponent{
+			{Type: "HEADER", Format: "TEXT", Text: "Order {{1}}"},
+			{Type: "BODY", Text: "Hi {{1}}, order {{2}} ships with {{3}}."},
+			{Type: "FOOTER", Text: "Reply STOP to opt out"},
+			{Type: "BUTTONS", Buttons: []meta.TemplateButton{
+				{Type: "URL", Text: "Track", URL: "https://shop.example.com/track/{{1}}"},
+				{Type: "QUICK_REPLY", Text: "Thanks"},
+			}},
+		},
+	}
+}
+
+// Test body parameters are sent in numeric order of their names
+func TestBuildTemplatePayloadOrdersBodyParameters(t *testing.T) {
+	payload := meta.BuildTemplatePayload("+15551234567", "order_shipped", "de", map[string]interface{}{
+		"10": "ten", "2": "two", "1": "one", meta.ButtonURLParameter(0): "ORD-1",
+	})
+
+	raw, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	var sent struct {
+		Template struct {
+			Language   struct{ Code string } `json:"language"`
+			Components []struct {
+				Type       string `json:"type"`
+				Parameters []struct {
+					Text string `json:"text"`
+				} `json:"parameters"`
+			} `json:"components"`
+		} `json:"template"`
+	}
+	assert.NoError(t, json.Unmarshal(raw, &sent))
+	assert.Equal(t, "de", sent.Template.Language.Code)
+	assert.Len(t, sent.Template.Components, 2)
+	assert.Equal(t, "body", sent.Template.Components[0].Type)
+	var texts []string
+	for _, p := range sent.Template.Components[0].Parameters {
+		texts = append(texts, p.Text)
+	}
+	assert.Equal(t, []string{"one", "two", "ten"}, texts)
+	assert.Equal(t, "button", sent.Template.Components[1].Type)
+}
+
+// Test rendering fills body and button placeholders and reports the gaps
+func TestRenderTemplate(t *testing.T) {
+	rendered := meta.RenderTemplate(shippingTemplate(), map[string]interface{}{
+		"1":                        "Ada",
+		"2":                        "ORD-1",
+		meta.ButtonURLParameter(0): "ORD-1",
+		meta.ButtonURLParameter(1): "ignored",
+	})
+
+	assert.Equal(t, "Hi Ada, order ORD-1 ships with {{3}}.", rendered.Body)
+	assert.Equal(t, "Order {{1}}", rendered.Header)
+	assert.Equal(t, "Reply STOP to opt out", rendered.Footer)
+	assert.Equal(t, "https://shop.example.com/track/ORD-1", rendered.Buttons[0].URL)
+	assert.Equal(t, []string{"header {{1}}", "body {{3}}"}, rendered.Missing)
+	assert.Equal(t, []string{meta.ButtonURLParameter(1)}, rendered.Unused)
+}
+
+// Test a preview renders the tenant's template and the payload a send would make
+func TestPreviewTemplate(t *testing.T) {
+	source := &stubTemplateSource{template: shippingTemplate()}
+	previews := service.NewTemplatePreviewService(source, nil, map[string]string{"default": "waba-1", "acme": "waba-2"}, new(MockLogger))
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	preview, err := previews.Preview(ctx, "order_shipped", "", "+15551234567", map[string]interface{}{
+		"1": "Ada", "2": "ORD-1", "3": "DHL", meta.ButtonURLParameter(0): "ORD-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "waba-2", source.wabaID)
+	assert.Equal(t, meta.DefaultTemplateLanguage, preview.Language)
+	assert.Equal(t, "APPROVED", preview.Status)
+	assert.Equal(t, "Hi Ada, order ORD-1 ships with DHL.", preview.Body)
+	assert.Equal(t, []string{"header {{1}}"}, preview.MissingParameters)
+	assert.Empty(t, preview.UnusedParameters)
+	assert.Len(t, preview.Buttons, 2)
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(preview.Payload, &payload))
+	assert.Equal(t, "+15551234567", payload["to"])
+	assert.Equal(t, "template", payload["type"])
+}
+
+// Test previews fail for unknown templates, tenants without an account and providers
+// without template lookups
+func TestPreviewTemplateErrors(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	accounts := map[string]string{"default": "waba-1"}
+	ctx := context.Background()
+
+	previews := service.NewTemplatePreviewService(&stubTemplateSource{template: shippingTemplate()}, nil, accounts, logger)
+	_, err := previews.Preview(ctx, "order_shipped", "fr", "", nil)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+
+	_, err = previews.Preview(domain.WithTenant(ctx, "acme"), "order_shipped", "", "", nil)
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+
+	_, err = previews.Preview(ctx, meta.CTAURLTemplate, "", "", nil)
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	_, err = previews.Preview(ctx, "order_shipped", "", "", map[string]interface{}{"button_url.first": "x"})
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	_, err = service.NewTemplatePreviewService(nil, nil, accounts, logger).Preview(ctx, "order_shipped", "", "", nil)
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+}
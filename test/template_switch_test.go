@@ -0,0 +1,107 @@
+// test/template_switch_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+)
+
+// MockTemplateRepository mocks repository.TemplateRepository
+type MockTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRepository) DisableTemplate(ctx context.Context, template *domain.DisabledTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTemplateRepository) EnableTemplate(ctx context.Context, templateID string) (bool, error) {
+	args := m.Called(ctx, templateID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTemplateRepository) ListDisabledTemplates(ctx context.Context) ([]domain.DisabledTemplate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.DisabledTemplate), args.Error(1)
+}
+
+// newDisabledTemplateSwitch returns a kill switch with the given template disabled
+func newDisabledTemplateSwitch(t *testing.T, templateID string) service.TemplateSwitch {
+	mockTemplates := new(MockTemplateRepository)
+	mockTemplates.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{
+		{TemplateID: templateID, Reason: "wrong price", Actor: "ops"},
+	}, nil)
+
+	templates := service.NewTemplateSwitch(mockTemplates, new(MockLogger))
+	assert.NoError(t, templates.Refresh(context.Background()))
+	return templates
+}
+
+// Test sends of a disabled template fail with a precondition error and store nothing
+func TestDisabledTemplateRefusesSend(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	inner := service.NewMessageService(mockRepo, new(MockWhatsAppClient), new(MockProducer), new(MockLogger))
+	svc := service.NewTemplateGuardedMessageService(inner, newDisabledTemplateSwitch(t, "promo"), mockRepo, new(MockLogger))
+
+	_, err := svc.SendTemplateMessage(context.Background(), "1234567890", "promo", nil, "", "")
+
+	assert.True(t, errors.Is(err, domain.ErrFailedPrecondition))
+	assert.Contains(t, err.Error(), "wrong price")
+	mockRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+}
+
+// Test messages queued before their template was disabled are failed unsent
+func TestDisabledTemplateFailsQueuedMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("UpdateMessageStatus", mock.Anything, int64(9), "failed", "", mock.Anything, "").Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	mockWhatsApp := new(MockWhatsAppClient)
+	inner := service.NewMessageService(mockRepo, mockWhatsApp, new(MockProducer), mockLogger)
+	svc := service.NewTemplateGuardedMessageService(inner, newDisabledTemplateSwitch(t, "promo"), mockRepo, mockLogger)
+
+	data, _ := json.Marshal(service.QueueMessage{MessageID: 9, TemplateID: "promo"})
+	assert.NoError(t, svc.ProcessQueueMessage(context.Background(), data))
+
+	mockRepo.AssertExpectations(t)
+	mockWhatsApp.AssertNotCalled(t, "SendTemplateMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test only admins flip the kill switch through the API, while internal changes, such as
+// Meta's template events, still go through
+func TestTemplateSwitchNeedsAdmin(t *testing.T) {
+	mockTemplates := new(MockTemplateRepository)
+	mockTemplates.On("DisableTemplate", mock.Anything, mock.Anything).Return(nil)
+	mockTemplates.On("EnableTemplate", mock.Anything, "promo").Return(true, nil)
+	mockTemplates.On("ListDisabledTemplates", mock.Anything).Return([]domain.DisabledTemplate{}, nil)
+	audit := new(MockAuditRepository)
+	audit.On("RecordAuditEntry", mock.Anything, mock.Anything).Return(1, nil)
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+	logger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	templates := service.NewAuditedTemplateSwitch(service.NewTemplateSwitch(mockTemplates, logger), service.NewAuditLog(audit, nil, logger), []string{"root"}, logger)
+	asAcme := domain.WithCaller(domain.WithTenantScope(context.Background(), "acme"), "acme-billing")
+	asRoot := domain.WithCaller(domain.WithTenantScope(context.Background(), "ops"), "root")
+
+	_, err := templates.Disable(asAcme, domain.DisabledTemplate{TemplateID: "promo", Reason: "wrong price", Actor: "root"})
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	assert.ErrorIs(t, templates.Enable(asAcme, "promo", "root"), domain.ErrPermissionDenied)
+	mockTemplates.AssertNotCalled(t, "DisableTemplate", mock.Anything, mock.Anything)
+	mockTemplates.AssertNotCalled(t, "EnableTemplate", mock.Anything, mock.Anything)
+
+	_, err = templates.Disable(asRoot, domain.DisabledTemplate{TemplateID: "promo", Reason: "wrong price", Actor: "root"})
+	assert.NoError(t, err)
+	assert.NoError(t, templates.Enable(asRoot, "promo", "root"))
+	_, err = templates.Disable(context.Background(), domain.DisabledTemplate{TemplateID: "promo", Reason: "PAUSED", Actor: "meta"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,405 @@
+// test/tenant_scope_test.go
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+	pb "messaging-microservice/proto"
+)
+
+// messageTable is a database/sql driver over an in-memory messages table. It applies the
+// equality conditions of the message repository's statements, which is all tenant scoping
+// needs, so the repository's SQL is exercised without a Postgres.
+type messageTable struct {
+	mu   sync.Mutex
+	rows []map[string]driver.Value
+}
+
+var (
+	equalsPattern = regexp.MustCompile(`(\w+) = \$(\d+)`)
+	anyPattern    = regexp.MustCompile(`(\w+) = ANY\(\$(\d+)\)`)
+	afterPattern  = regexp.MustCompile(`(\w+) > \$(\d+)`)
+	nullPattern   = regexp.MustCompile(`(\w+) IS NULL`)
+)
+
+// newMessageTable returns a repository over a table holding one message per tenant given,
+// with IDs from 1, external IDs wamid.<id> and the same order ID
+func newMessageTable(tenants ...string) (*messageTable, repository.MessageRepository) {
+	table := &messageTable{}
+	now := time.Now()
+	for i, tenant := range tenants {
+		table.rows = append(table.rows, map[string]driver.Value{
+			"id":           int64(i + 1),
+			"phone_number": "+1415555010" + strconv.Itoa(i),
+			"template_id":  "order_confirmation",
+			"parameters":   "{}",
+			"order_id":     "ORD-1",
+			"status":       "sent",
+			"external_id":  "wamid." + strconv.Itoa(i+1),
+			"tenant_id":    tenant,
+			"attempt":      int64(1),
+			"created_at":   now,
+			"updated_at":   now,
+		})
+	}
+	db := sqlx.NewDb(sql.OpenDB(table), "postgres")
+	return table, repository.NewMessageRepository(db, discardLogger{})
+}
+
+// has reports whether the message is still stored and not soft deleted
+func (t *messageTable) has(id int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, row := range t.rows {
+		if row["id"] == id {
+			return row["deleted_at"] == nil
+		}
+	}
+	return false
+}
+
+func (t *messageTable) Connect(context.Context) (driver.Conn, error) { return t, nil }
+func (t *messageTable) Driver() driver.Driver                        { return t }
+func (t *messageTable) Open(string) (driver.Conn, error)             { return t, nil }
+func (t *messageTable) Close() error                                 { return nil }
+func (t *messageTable) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+func (t *messageTable) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+// QueryContext returns the listed columns, or COUNT(*), of the rows matching the statement
+func (t *messageTable) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	selected, _, ok := strings.Cut(strings.TrimPrefix(query, "SELECT "), " FROM ")
+	if !ok {
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	}
+	matching := t.matching(query, args)
+
+	if selected == "COUNT(*)" {
+		return &tableRows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(matching))}}}, nil
+	}
+	rows := &tableRows{}
+	for _, column := range strings.Split(selected, ",") {
+		rows.columns = append(rows.columns, strings.TrimSpace(column))
+	}
+	for _, row := range matching {
+		values := make([]driver.Value, len(rows.columns))
+		for i, column := range rows.columns {
+			values[i] = row[column]
+		}
+		rows.values = append(rows.values, values)
+	}
+	return rows, nil
+}
+
+// ExecContext applies an UPDATE's assignments to the matching rows, or removes them for a DELETE
+func (t *messageTable) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	matching := t.matching(query, args)
+
+	switch {
+	case strings.HasPrefix(query, "DELETE FROM messages"):
+		kept := t.rows[:0]
+		for _, row := range t.rows {
+			if !containsRow(matching, row) {
+				kept = append(kept, row)
+			}
+		}
+		t.rows = kept
+	case strings.HasPrefix(query, "UPDATE messages SET "):
+		set, _, _ := strings.Cut(strings.TrimPrefix(query, "UPDATE messages SET "), " WHERE ")
+		for _, row := range matching {
+			for _, assignment := range equalsPattern.FindAllStringSubmatch(set, -1) {
+				row[assignment[1]] = arg(args, assignment[2])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported statement: %s", query)
+	}
+	return driver.RowsAffected(len(matching)), nil
+}
+
+// matching returns the rows satisfying every condition of the statement's WHERE clause
+func (t *messageTable) matching(query string, args []driver.NamedValue) []map[string]driver.Value {
+	_, where, _ := strings.Cut(query, " WHERE ")
+	where, _, _ = strings.Cut(where, " ORDER BY ")
+
+	var matching []map[string]driver.Value
+	for _, row := range t.rows {
+		ok := true
+		for _, condition := range anyPattern.FindAllStringSubmatch(where, -1) {
+			ok = ok && strings.Contains(","+strings.Trim(fmt.Sprint(arg(args, condition[2])), "{}")+",", ","+strings.Trim(fmt.Sprint(row[condition[1]]), `"`)+",")
+		}
+		for _, condition := range equalsPattern.FindAllStringSubmatch(anyPattern.ReplaceAllString(where, ""), -1) {
+			ok = ok && fmt.Sprint(row[condition[1]]) == fmt.Sprint(arg(args, condition[2]))
+		}
+		for _, condition := range afterPattern.FindAllStringSubmatch(where, -1) {
+			ok = ok && row[condition[1]].(int64) > arg(args, condition[2]).(int64)
+		}
+		for _, condition := range nullPattern.FindAllStringSubmatch(where, -1) {
+			ok = ok && row[condition[1]] == nil
+		}
+		if ok {
+			matching = append(matching, row)
+		}
+	}
+	return matching
+}
+
+// arg returns the value bound to placeholder n, with quoted array items unquoted
+func arg(args []driver.NamedValue, n string) driver.Value {
+	i, _ := strconv.Atoi(n)
+	if value, ok := args[i-1].Value.(string); ok {
+		return strings.ReplaceAll(value, `"`, "")
+	}
+	return args[i-1].Value
+}
+
+func containsRow(rows []map[string]driver.Value, row map[string]driver.Value) bool {
+	for _, candidate := range rows {
+		if candidate["id"] == row["id"] {
+			return true
+		}
+	}
+	return false
+}
+
+// tableRows are the results of a messageTable query
+type tableRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *tableRows) Columns() []string { return r.columns }
+func (r *tableRows) Close() error      { return nil }
+func (r *tableRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// tenantCredentials authenticate one caller of each tenant
+var tenantCredentials = map[string]handler.GRPCCredential{
+	"acme-key":   {Caller: "acme-billing", Tenant: "acme"},
+	"globex-key": {Caller: "globex-support", Tenant: "globex"},
+}
+
+// newTenantScopedHandler serves message 1 of acme and message 2 of globex
+func newTenantScopedHandler() (*messageTable, *handler.GrpcMessageHandler) {
+	table, repo := newMessageTable("acme", "globex")
+	svc := service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{})
+	return table, handler.NewGrpcMessageHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, handler.ServiceInfo{}, utils.NewPlainPhoneNumberHasher(), discardLogger{})
+}
+
+// callAs makes a unary call through TenantInterceptor with the given metadata
+func callAs(md metadata.MD, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	return handler.TenantInterceptor(tenantCredentials)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return call(ctx)
+	})
+}
+
+// asAcme is the metadata of acme's caller
+var asAcme = metadata.Pairs("x-api-key", "acme-key")
+
+// Test callers are authenticated by API key and can't pick another tenant
+func TestTenantInterceptorAuthenticatesCaller(t *testing.T) {
+	_, h := newTenantScopedHandler()
+	getFirst := func(ctx context.Context) (interface{}, error) {
+		return h.GetMessage(ctx, &pb.GetMessageRequest{MessageId: 1})
+	}
+
+	_, err := callAs(metadata.MD{}, getFirst)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = callAs(metadata.Pairs("x-api-key", "forged-key", "x-tenant-id", "acme"), getFirst)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = callAs(metadata.Pairs("x-api-key", "globex-key", "x-tenant-id", "acme"), getFirst)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := callAs(metadata.Pairs("x-api-key", "acme-key", "x-tenant-id", "acme"), getFirst)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.(*pb.MessageResponse).Id)
+
+	// A key's tenant applies without x-tenant-id too
+	_, err = callAs(metadata.Pairs("x-api-key", "globex-key"), getFirst)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// Test without credentials, as in development, x-tenant-id still scopes the request
+func TestTenantInterceptorWithoutCredentials(t *testing.T) {
+	_, h := newTenantScopedHandler()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "globex"))
+	resp, err := handler.TenantInterceptor(nil)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return h.ListMessages(ctx, &pb.ListMessagesRequest{})
+	})
+	require.NoError(t, err)
+	list := resp.(*pb.ListMessagesResponse)
+	require.Len(t, list.Messages, 1)
+	assert.Equal(t, int64(2), list.Messages[0].Id)
+}
+
+// Test no single message RPC reaches another tenant's message
+func TestCrossTenantMessageLookups(t *testing.T) {
+	table, h := newTenantScopedHandler()
+
+	_, err := callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessage(ctx, &pb.GetMessageRequest{MessageId: 2})
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err), "GetMessage")
+
+	_, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessageByExternalID(ctx, &pb.GetMessageByExternalIDRequest{ExternalId: "wamid.2"})
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err), "GetMessageByExternalID")
+
+	resp, err := callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessageByExternalID(ctx, &pb.GetMessageByExternalIDRequest{ExternalId: "wamid.1"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.(*pb.MessageResponse).Id)
+
+	_, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.DeleteMessage(ctx, &pb.DeleteMessageRequest{MessageId: 2, RequestedBy: "acme-billing"})
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err), "DeleteMessage")
+	assert.True(t, table.has(2))
+
+	_, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.DeleteMessage(ctx, &pb.DeleteMessageRequest{MessageId: 1, RequestedBy: "acme-billing"})
+	})
+	require.NoError(t, err)
+	assert.False(t, table.has(1))
+}
+
+// Test batch, order, listing and export RPCs leave other tenants' messages out
+func TestCrossTenantMessageListings(t *testing.T) {
+	_, h := newTenantScopedHandler()
+
+	resp, err := callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessages(ctx, &pb.GetMessagesRequest{MessageIds: []int64{1, 2}})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, messageIDs(resp.(*pb.GetMessagesResponse).Messages), "GetMessages")
+
+	resp, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessagesByExternalIDs(ctx, &pb.GetMessagesByExternalIDsRequest{ExternalIds: []string{"wamid.1", "wamid.2"}})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, messageIDs(resp.(*pb.GetMessagesResponse).Messages), "GetMessagesByExternalIDs")
+
+	resp, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.GetMessagesByOrderID(ctx, &pb.GetMessagesByOrderIDRequest{OrderId: "ORD-1"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, messageIDs(resp.(*pb.ListMessagesResponse).Messages), "GetMessagesByOrderID")
+
+	resp, err = callAs(asAcme, func(ctx context.Context) (interface{}, error) {
+		return h.ListMessages(ctx, &pb.ListMessagesRequest{})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, messageIDs(resp.(*pb.ListMessagesResponse).Messages), "ListMessages")
+	assert.Equal(t, int32(1), resp.(*pb.ListMessagesResponse).TotalCount)
+
+	stream := &metadataStream{ctx: metadata.NewIncomingContext(context.Background(), asAcme)}
+	err = handler.TenantStreamInterceptor(tenantCredentials)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test"}, func(srv interface{}, scoped grpc.ServerStream) error {
+		return h.ExportMessages(&pb.ExportMessagesRequest{}, &collectingStream{ServerStream: scoped, sent: &stream.sent})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, messageIDs(stream.sent), "ExportMessages")
+}
+
+// messageIDs lists the IDs of messages in order
+func messageIDs(messages []*pb.MessageResponse) []int64 {
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.Id)
+	}
+	return ids
+}
+
+// metadataStream is a server stream with an incoming context, collecting what is exported
+type metadataStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.MessageResponse
+}
+
+func (s *metadataStream) Context() context.Context {
+	return s.ctx
+}
+
+// collectingStream is an export stream over the stream an interceptor handed on
+type collectingStream struct {
+	grpc.ServerStream
+	sent *[]*pb.MessageResponse
+}
+
+func (s *collectingStream) Send(msg *pb.MessageResponse) error {
+	*s.sent = append(*s.sent, msg)
+	return nil
+}
+
+// Test the HTTP export authenticates its caller and only exports the key's tenant
+func TestTenantMiddlewareScopesExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, repo := newMessageTable("acme", "globex")
+	svc := service.NewMessageService(repo, new(MockWhatsAppClient), new(MockProducer), discardLogger{})
+	router := gin.New()
+	router.GET("/export/messages", handler.TenantMiddleware(tenantCredentials), handler.NewExportHandler(svc, utils.NewPlainPhoneNumberHasher(), discardLogger{}).HandleExport)
+
+	export := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/export/messages?format=jsonl", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, export(nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, export(map[string]string{"X-API-Key": "forged-key", "X-Tenant-ID": "acme"}).Code)
+	assert.Equal(t, http.StatusForbidden, export(map[string]string{"X-API-Key": "globex-key", "X-Tenant-ID": "acme"}).Code)
+
+	w := export(map[string]string{"X-API-Key": "acme-key"})
+	require.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"id":1,`)
+}
@@ -0,0 +1,79 @@
+// test/token_manager_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/meta"
+)
+
+// newGraphAPIServer fakes the debug_token and oauth endpoints of the Graph API
+func newGraphAPIServer(t *testing.T, validTokens map[string]int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/debug_token":
+			expiresAt, ok := validTokens[r.URL.Query().Get("input_token")]
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"is_valid": ok, "expires_at": expiresAt},
+			})
+		case "/oauth/access_token":
+			assert.Equal(t, "fb_exchange_token", r.URL.Query().Get("grant_type"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "refreshed", "token_type": "bearer", "expires_in": 3600,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// Test validation caches expiry and refresh swaps in the new token
+func TestTokenManagerValidateAndRefresh(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+	server := newGraphAPIServer(t, map[string]int64{"current": expiresAt})
+	defer server.Close()
+
+	manager := meta.NewTokenManager(meta.TokenManagerConfig{
+		AccessToken: "current",
+		AppID:       "app",
+		AppSecret:   "secret",
+		APIURL:      server.URL,
+	}, mockLogger)
+
+	assert.NoError(t, manager.Validate(context.Background()))
+	health := manager.Health()
+	assert.True(t, health.Valid)
+	assert.Equal(t, expiresAt, health.ExpiresAt.Unix())
+
+	assert.NoError(t, manager.Refresh(context.Background()))
+	token, _ := manager.Token(context.Background())
+	assert.Equal(t, "refreshed", token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), manager.Health().ExpiresAt, time.Minute)
+}
+
+// Test an invalid token is reported as ErrTokenInvalid and marks the token unhealthy
+func TestTokenManagerInvalidToken(t *testing.T) {
+	mockLogger := new(MockLogger)
+
+	server := newGraphAPIServer(t, map[string]int64{})
+	defer server.Close()
+
+	manager := meta.NewTokenManager(meta.TokenManagerConfig{AccessToken: "revoked", APIURL: server.URL}, mockLogger)
+
+	err := manager.Validate(context.Background())
+	assert.ErrorIs(t, err, meta.ErrTokenInvalid)
+	assert.False(t, manager.Health().Valid)
+	assert.NotEmpty(t, manager.Health().LastError)
+
+	assert.Error(t, manager.Refresh(context.Background()), "refresh needs an app ID")
+}
@@ -0,0 +1,68 @@
+// test/transport_test.go
+package test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"messaging-microservice/config"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test provider requests go through the configured proxy
+func TestTransportProxy(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	transport, err := utils.NewTransport(proxy.URL, "")
+	assert.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: transport}).Get("http://graph.facebook.invalid/v18.0/me")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+	assert.Equal(t, "graph.facebook.invalid", proxiedHost)
+}
+
+// Test servers signed by a CA from the bundle are trusted
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	// Unknown to the system roots
+	plain, err := utils.NewTransport("", "")
+	assert.NoError(t, err)
+	_, err = (&http.Client{Transport: plain}).Get(server.URL)
+	assert.Error(t, err)
+
+	trusting, err := utils.NewTransport("", bundle)
+	assert.NoError(t, err)
+	resp, err := (&http.Client{Transport: trusting}).Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+}
+
+// Test a proxy URL or CA bundle that cannot be used is a configuration error
+func TestConfigValidatesProviderEgress(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	assert.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+
+	_, err := config.Load("--provider-https-proxy=proxy.corp:3128", "--provider-ca-bundle="+empty)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "PROVIDER_HTTPS_PROXY must be an http, https or socks5 URL")
+	assert.Contains(t, err.Error(), "PROVIDER_CA_BUNDLE")
+}
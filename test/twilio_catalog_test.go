@@ -0,0 +1,124 @@
+// test/twilio_catalog_test.go
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/render"
+	"messaging-microservice/pkg/twilio"
+)
+
+// MockTemplateTranslationRepository mocks repository.TemplateTranslationRepository
+type MockTemplateTranslationRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemplateTranslationRepository) ListTemplateTranslations(ctx context.Context) ([]domain.TemplateTranslation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.TemplateTranslation), args.Error(1)
+}
+
+// Test catalog bodies are picked by locale, falling back to the language and default locale
+func TestCatalogRendersRecipientLocale(t *testing.T) {
+	catalog := twilio.NewCatalog("en", render.NewEngine(render.Options{Safe: true}))
+	assert.NoError(t, catalog.Replace([]twilio.CatalogEntry{
+		{TemplateName: "order_shipped", Locale: "en", Body: "Order {{.order_id}} has shipped"},
+		{TemplateName: "order_shipped", Locale: "es", Body: "Tu pedido {{.order_id}} va en camino"},
+		{TemplateName: "order_shipped", Locale: "pt-br", Body: "Seu pedido {{index . \"1\"}} foi enviado"},
+	}))
+	assert.True(t, catalog.Has("order_shipped"))
+	assert.False(t, catalog.Has("order_confirmation"))
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"es", "Tu pedido ORD-1 va en camino"},
+		{"es_MX", "Tu pedido ORD-1 va en camino"},
+		{"pt_BR", "Seu pedido ORD-1 foi enviado"},
+		{"fr", "Order ORD-1 has shipped"},
+		{"", "Order ORD-1 has shipped"},
+	}
+	for _, tt := range tests {
+		body, err := catalog.Render("order_shipped", map[string]interface{}{"order_id": "ORD-1", "1": "ORD-1", meta.LocaleParameter: tt.locale})
+		assert.NoError(t, err, tt.locale)
+		assert.Equal(t, tt.want, body, tt.locale)
+	}
+
+	_, err := catalog.Render("order_shipped", map[string]interface{}{meta.LocaleParameter: "es"})
+	assert.Error(t, err, "missing parameters fail the render")
+
+	assert.Error(t, catalog.Replace([]twilio.CatalogEntry{{TemplateName: "broken", Locale: "en", Body: "{{.order_id"}}))
+	assert.True(t, catalog.Has("order_shipped"), "a bad replacement keeps the previous bodies")
+}
+
+// Test templates without a content SID are sent as the catalog body, and the locale is never
+// a content variable
+func TestTwilioSendsCatalogBody(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "queued", "to": "whatsapp:+15551234567"}`))
+	}))
+	defer server.Close()
+
+	catalog := twilio.NewCatalog("en", render.NewEngine(render.Options{Safe: true}))
+	assert.NoError(t, catalog.Replace([]twilio.CatalogEntry{
+		{TemplateName: "order_shipped", Locale: "es", Body: "Tu pedido {{.order_id}} va en camino"},
+	}))
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	client := twilio.NewClient(twilio.Config{
+		AccountSID:  "AC123",
+		AuthToken:   "token",
+		From:        "whatsapp:+14155238886",
+		ContentSIDs: map[string]string{"order_confirmation": "HX0123456789abcdef"},
+		Catalog:     catalog,
+		APIURL:      server.URL,
+	}, mockLogger)
+
+	_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_shipped", map[string]interface{}{"order_id": "ORD-1", meta.LocaleParameter: "es"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tu pedido ORD-1 va en camino", form.Get("Body"))
+	assert.Empty(t, form.Get("ContentSid"))
+
+	_, err = client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", map[string]interface{}{"1": "Ada", meta.LocaleParameter: "es"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"1":"Ada"}`, form.Get("ContentVariables"))
+	assert.Empty(t, form.Get("Body"))
+}
+
+// Test stored translations are loaded over the catalog file's
+func TestTemplateCatalogRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"order_shipped": {"en": "Order {{.order_id}} has shipped", "es": "Pedido {{.order_id}} enviado"}}`), 0o600))
+
+	repo := new(MockTemplateTranslationRepository)
+	repo.On("ListTemplateTranslations", mock.Anything).Return([]domain.TemplateTranslation{
+		{TemplateID: "order_shipped", Locale: "es", Body: "Tu pedido {{.order_id}} va en camino"},
+	}, nil)
+
+	catalog := twilio.NewCatalog("en", render.NewEngine(render.Options{Safe: true}))
+	assert.NoError(t, service.NewTemplateCatalog(repo, path, catalog, new(MockLogger)).Refresh(context.Background()))
+
+	body, err := catalog.Render("order_shipped", map[string]interface{}{"order_id": "ORD-1", meta.LocaleParameter: "es"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tu pedido ORD-1 va en camino", body)
+	body, err = catalog.Render("order_shipped", map[string]interface{}{"order_id": "ORD-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Order ORD-1 has shipped", body)
+}
@@ -0,0 +1,114 @@
+// test/twilio_client_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/pkg/twilio"
+)
+
+// Test templates are sent as a content SID with the parameters as content variables
+func TestTwilioSendsContentTemplate(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Accounts/AC123/Messages.json", r.URL.Path)
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "token", pass)
+		r.ParseForm()
+		form = r.PostForm
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "queued", "to": "whatsapp:+15551234567", "error_code": null, "error_message": null}`))
+	}))
+	defer server.Close()
+
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	client := twilio.NewClient(twilio.Config{
+		AccountSID:  "AC123",
+		AuthToken:   "token",
+		From:        "whatsapp:+14155238886",
+		ContentSIDs: map[string]string{"order_confirmation": "HX0123456789abcdef"},
+		APIURL:      server.URL,
+	}, mockLogger)
+
+	resp, err := client.SendTemplateMessage(context.Background(), "+15551234567", "order_confirmation", map[string]interface{}{"1": "Ada", "2": 42})
+	assert.NoError(t, err)
+	assert.Equal(t, "SM123", resp.Messages[0].ID)
+
+	assert.Equal(t, "whatsapp:+15551234567", form.Get("To"))
+	assert.Equal(t, "whatsapp:+14155238886", form.Get("From"))
+	assert.Equal(t, "HX0123456789abcdef", form.Get("ContentSid"))
+	var variables map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(form.Get("ContentVariables")), &variables))
+	assert.Equal(t, map[string]string{"1": "Ada", "2": "42"}, variables)
+
+	_, err = client.SendTemplateMessage(context.Background(), "+15551234567", "unknown_template", nil)
+	assert.Error(t, err)
+}
+
+// Test Twilio error responses surface the Twilio error code
+func TestTwilioAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 63016, "message": "Failed to send freeform message", "more_info": "https://www.twilio.com/docs/errors/63016", "status": 400}`))
+	}))
+	defer server.Close()
+
+	mockLogger := new(MockLogger)
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	client := twilio.NewClient(twilio.Config{AccountSID: "AC123", AuthToken: "token", APIURL: server.URL}, mockLogger)
+
+	_, err := client.SendTemplateMessage(context.Background(), "+15551234567", "HX0123456789abcdef", nil)
+	var apiErr *twilio.APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, "63016", apiErr.ErrorCode())
+	}
+}
+
+// Test the X-Twilio-Signature check against Twilio's documented example
+func TestTwilioWebhookSignature(t *testing.T) {
+	client := twilio.NewClient(twilio.Config{AuthToken: "12345"}, new(MockLogger))
+	body := []byte(url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}.Encode())
+
+	callbackURL := "https://mycompany.com/myapp.php?foo=1&bar=2"
+	assert.True(t, client.ValidateWebhookSignature("0/KCTR6DLpKmkAf8muzZqo1nDgQ=", callbackURL, body))
+	assert.False(t, client.ValidateWebhookSignature("0/KCTR6DLpKmkAf8muzZqo1nDgQ=", "https://mycompany.com/other", body))
+}
+
+// Test status callbacks map to message statuses
+func TestTwilioStatusCallback(t *testing.T) {
+	callback, err := twilio.ParseStatusCallback(url.Values{
+		"MessageSid":    {"SM123"},
+		"MessageStatus": {"undelivered"},
+		"ErrorCode":     {"63016"},
+	})
+	assert.NoError(t, err)
+	status, ok := callback.Status()
+	assert.True(t, ok)
+	assert.Equal(t, "failed", status)
+	assert.Equal(t, "63016", callback.ErrorCode)
+
+	callback, err = twilio.ParseStatusCallback(url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"sending"}})
+	assert.NoError(t, err)
+	_, ok = callback.Status()
+	assert.False(t, ok)
+
+	_, err = twilio.ParseStatusCallback(url.Values{"MessageStatus": {"sent"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+// test/twilio_webhook_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// Test a Twilio status callback is stored and published like a Meta status
+func TestProcessTwilioStatus(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "SM123").Return(42, nil)
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, []domain.StatusUpdate{
+		{MessageID: 42, Status: "failed", ErrorCode: "63016", ErrorMessage: "outside the window", ExternalID: "SM123"},
+	}).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 2}}, nil)
+
+	var published service.WebhookEvent
+	mockProducer.On("ProduceWithKey", mock.Anything, []byte("42"), mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal(args.Get(2).([]byte), &published))
+	}).Return(nil)
+
+	resolver := service.NewStaticTenantResolver(map[string]string{"whatsapp:+14155238886": "tenant-a"})
+	svc := service.NewWebhookService(mockRepo, mockProducer, resolver, utils.NewPlainPhoneNumberHasher(), mockLogger, "verify-token")
+
+	err := svc.ProcessTwilioStatus(context.Background(), &twilio.StatusCallback{
+		MessageSID:    "SM123",
+		MessageStatus: "undelivered",
+		From:          "whatsapp:+14155238886",
+		To:            "whatsapp:+15551234567",
+		ErrorCode:     "63016",
+		ErrorMessage:  "outside the window",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-a", published.TenantID)
+	assert.Equal(t, "failed", published.Status)
+	assert.Equal(t, int64(2), published.Sequence)
+	assert.Equal(t, "15551234567", published.PhoneNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test the Twilio callback endpoint rejects unsigned callbacks and accepts signed ones
+func TestTwilioCallbackSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(MockMessageRepository)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	svc := service.NewWebhookService(mockRepo, new(MockProducer), service.NewStaticTenantResolver(nil), utils.NewPlainPhoneNumberHasher(), mockLogger, "verify-token")
+
+	const callbackURL = "https://example.com/webhook/twilio"
+	client := twilio.NewClient(twilio.Config{AuthToken: "token"}, mockLogger)
+	router := gin.New()
+	router.POST("/webhook/twilio", handler.NewTwilioWebhookHandler(svc, client.ValidateWebhookSignature, callbackURL, mockLogger).HandleStatusCallback)
+
+	body := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"sending"}}.Encode()
+	post := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/twilio", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("bogus"))
+	// Signature of callbackURL + "MessageSidSM123MessageStatussending" with auth token "token"
+	assert.Equal(t, http.StatusNoContent, post("lnSHUgdPXaWFglm545FE9iR8pIE="))
+}
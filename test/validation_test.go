@@ -0,0 +1,106 @@
+// test/validation_test.go
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"messaging-microservice/internal/handler"
+	pb "messaging-microservice/proto"
+)
+
+// validate runs req through the validation interceptor, returning the error it was rejected
+// with or nil when it reached the handler
+func validate(req interface{}) error {
+	_, err := handler.ValidationInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+// Test requests breaking a rule are rejected with InvalidArgument naming the field
+func TestValidationInterceptorRejects(t *testing.T) {
+	parameters := make(map[string]string, 51)
+	for i := 0; i < 51; i++ {
+		parameters[strings.Repeat("p", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		req     interface{}
+		message string
+	}{
+		{"missing phone", &pb.SendTemplateMessageRequest{TemplateId: "order_update"}, "phone_number is required"},
+		{"bad phone", &pb.SendTemplateMessageRequest{PhoneNumber: "call me", TemplateId: "order_update"}, "phone_number must be a phone number"},
+		{"long template", &pb.SendTemplateMessageRequest{PhoneNumber: "+15551234567", TemplateId: strings.Repeat("t", 51)}, "template_id must be at most 50 characters"},
+		{"too many parameters", &pb.SendTemplateMessageRequest{PhoneNumber: "+15551234567", TemplateId: "order_update", Parameters: parameters}, "parameters must have at most 50 entries"},
+		{"long parameter", &pb.SendTemplateMessageRequest{PhoneNumber: "+15551234567", TemplateId: "order_update", Parameters: map[string]string{"1": strings.Repeat("v", 1025)}}, "parameters must be at most 1024 characters"},
+		{"negative message ID", &pb.GetMessageRequest{MessageId: -1}, "message_id must be positive"},
+		{"limit too large", &pb.ListMessagesRequest{Limit: 1001}, "limit must be between 0 and 1000"},
+		{"negative offset", &pb.ListMessagesRequest{Offset: -1}, "offset must not be negative"},
+		{"too many IDs", &pb.GetMessagesRequest{MessageIds: make([]int64, 101)}, "message_ids must have at most 100 entries"},
+		{"zero ID in batch", &pb.GetMessagesRequest{MessageIds: []int64{1, 0}}, "message_ids must be positive"},
+		{"missing requester", &pb.DeleteMessageRequest{MessageId: 1}, "requested_by is required"},
+		{"long parameter name", &pb.SendTemplateMessageRequest{PhoneNumber: "+15551234567", TemplateId: "order_update", Parameters: map[string]string{strings.Repeat("k", 51): "v"}}, "parameters keys must be at most 50 characters"},
+		{"bad optional phone", &pb.ListMessagesRequest{PhoneNumber: "nope"}, "phone_number must be a phone number"},
+		{"bad phone in list", &pb.CreateSegmentRequest{Name: "vip", RequestedBy: "ops", PhoneNumbers: []string{"+15551234567", "12"}}, "phone_numbers must be a phone number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.req)
+
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Equal(t, tt.message, status.Convert(err).Message())
+		})
+	}
+}
+
+// Test valid requests, and requests without rules, reach the handler
+func TestValidationInterceptorAccepts(t *testing.T) {
+	for _, req := range []interface{}{
+		&pb.SendTemplateMessageRequest{PhoneNumber: "whatsapp:+1 555 123 4567", TemplateId: "order_update", Parameters: map[string]string{"1": "ORD-1"}},
+		&pb.ListMessagesRequest{},
+		&pb.ListMessagesRequest{Limit: 1000, Offset: 20},
+		&pb.GetMessagesRequest{MessageIds: []int64{1, 2, 3}},
+		&pb.GetServiceInfoRequest{},
+		&pb.CreateSegmentRequest{Name: "vip", RequestedBy: "ops", PhoneNumbers: []string{"+15551234567"}},
+	} {
+		assert.NoError(t, validate(req))
+	}
+}
+
+// Test every validation rule of the API can be enforced by the interceptors
+func TestValidationRulesNameFields(t *testing.T) {
+	assert.NoError(t, handler.CheckRequestRules())
+}
+
+// recvStream is a server stream receiving a single request
+type recvStream struct {
+	grpc.ServerStream
+	req *pb.ExportMessagesRequest
+}
+
+func (s *recvStream) Context() context.Context { return context.Background() }
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	m.(*pb.ExportMessagesRequest).PhoneNumber = s.req.PhoneNumber
+	return nil
+}
+
+// Test streaming requests are validated as they are received
+func TestValidationStreamInterceptor(t *testing.T) {
+	handlerCalled := false
+	err := handler.ValidationStreamInterceptor()(nil, &recvStream{req: &pb.ExportMessagesRequest{PhoneNumber: "nope"}}, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		handlerCalled = true
+		return stream.RecvMsg(new(pb.ExportMessagesRequest))
+	})
+
+	assert.True(t, handlerCalled)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,102 @@
+// test/webhook_errors_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/handler"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// stubWebhookService fails every webhook with err
+type stubWebhookService struct {
+	err error
+}
+
+func (s *stubWebhookService) ProcessWebhook(ctx context.Context, body []byte, signature, url string) error {
+	return s.err
+}
+
+func (s *stubWebhookService) ReplayFailures(ctx context.Context) (int, error) {
+	return 0, s.err
+}
+
+func (s *stubWebhookService) Run(ctx context.Context, interval time.Duration) {}
+
+func (s *stubWebhookService) MatchQuarantined(ctx context.Context) (int, error) {
+	return 0, s.err
+}
+
+func (s *stubWebhookService) RunQuarantine(ctx context.Context, interval time.Duration) {}
+
+func (s *stubWebhookService) ProcessTwilioStatus(ctx context.Context, callback *twilio.StatusCallback) error {
+	return s.err
+}
+
+func (s *stubWebhookService) UpdateMessageStatus(ctx context.Context, externalID, status, errorCode, errorMessage string) error {
+	return s.err
+}
+
+func (s *stubWebhookService) GetVerifyToken() string {
+	return "verify-token"
+}
+
+// Test webhook failures are classified: malformed payloads are acknowledged, transient failures
+// ask for a retry
+func TestWebhookErrorResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	tests := []struct {
+		name      string
+		err       error
+		status    int
+		code      string
+		retryable bool
+	}{
+		{"malformed", domain.WrapError(domain.ErrValidation, errors.New("unexpected EOF"), "malformed webhook payload"), http.StatusOK, handler.WebhookErrorMalformed, false},
+		{"signature", domain.NewError(domain.ErrUnauthenticated, "missing webhook signature"), http.StatusUnauthorized, handler.WebhookErrorInvalidSignature, false},
+		{"database", errors.New("connection refused"), http.StatusInternalServerError, handler.WebhookErrorProcessing, true},
+		{"partly transient", errors.Join(domain.NewError(domain.ErrValidation, "bad event"), errors.New("connection refused")), http.StatusInternalServerError, handler.WebhookErrorProcessing, true},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		router.POST("/webhook", handler.NewWebhookHandler(&stubWebhookService{err: tt.err}, mockLogger).HandleWebhook)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var body struct {
+			Error handler.WebhookError `json:"error"`
+		}
+		assert.Equal(t, tt.status, w.Code, tt.name)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), tt.name)
+		assert.Equal(t, tt.code, body.Error.Code, tt.name)
+		assert.Equal(t, tt.retryable, body.Error.Retryable, tt.name)
+	}
+}
+
+// Test a payload that isn't JSON is reported as a validation error
+func TestProcessWebhookMalformedPayload(t *testing.T) {
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), service.NewStaticTenantResolver(nil), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, new(MockLogger), "verify-token")
+
+	body := []byte(`{"object": `)
+	err := svc.ProcessWebhook(context.Background(), body, signWebhook(body), "/webhook")
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+}
@@ -0,0 +1,154 @@
+// test/webhook_failures_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/utils"
+)
+
+// MockWebhookFailureRepository mocks repository.WebhookFailureRepository
+type MockWebhookFailureRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookFailureRepository) CreateWebhookFailures(ctx context.Context, failures []domain.WebhookFailure) error {
+	args := m.Called(ctx, failures)
+	return args.Error(0)
+}
+
+func (m *MockWebhookFailureRepository) ListWebhookFailures(ctx context.Context, maxAttempts, limit int) ([]domain.WebhookFailure, error) {
+	args := m.Called(ctx, maxAttempts, limit)
+	return args.Get(0).([]domain.WebhookFailure), args.Error(1)
+}
+
+func (m *MockWebhookFailureRepository) RecordWebhookFailureAttempt(ctx context.Context, id int64, errorMessage string) error {
+	args := m.Called(ctx, id, errorMessage)
+	return args.Error(0)
+}
+
+func (m *MockWebhookFailureRepository) DeleteWebhookFailure(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookFailureRepository) PurgeWebhookFailuresBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookFailureRepository) EraseWebhookFailures(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// stubTemplateEvents fails the events of one template
+type stubTemplateEvents struct {
+	failing string
+}
+
+func (s *stubTemplateEvents) HandleTemplateEvent(ctx context.Context, event domain.TemplateEvent) error {
+	if event.TemplateID == s.failing {
+		return errors.New("notifier unavailable")
+	}
+	return nil
+}
+
+const testBatchWebhook = `{
+	"object": "whatsapp_business_account",
+	"entry": [{
+		"id": "WABA-1",
+		"changes": [
+			{"field": "message_template_status_update", "value": {"event": "PAUSED", "message_template_name": "order_shipped"}},
+			{"field": "message_template_status_update", "value": {"event": "PAUSED", "message_template_name": "order_confirmation"}}
+		]
+	}]
+}`
+
+// Test a failed change of a batch is stored alone for replay and the batch is acknowledged
+func TestProcessWebhookStoresFailedChanges(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	failures := new(MockWebhookFailureRepository)
+
+	var stored []domain.WebhookFailure
+	failures.On("CreateWebhookFailures", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]domain.WebhookFailure)
+	}).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Templates:         &stubTemplateEvents{failing: "order_confirmation"},
+		Signatures:        testWebhookSignatures,
+		Failures:          failures,
+		MaxReplayAttempts: 3,
+	}, mockLogger, "verify-token")
+
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), []byte(testBatchWebhook), signWebhook([]byte(testBatchWebhook)), "/webhook"))
+	assert.Len(t, stored, 1)
+	assert.Equal(t, "WABA-1", stored[0].EntryID)
+	assert.Equal(t, domain.TemplateFieldStatusUpdate, stored[0].Field)
+	assert.Equal(t, "notifier unavailable", stored[0].Error)
+
+	var payload service.MetaWebhookPayload
+	assert.NoError(t, json.Unmarshal(stored[0].Payload, &payload))
+	assert.Len(t, payload.Entry, 1)
+	assert.Len(t, payload.Entry[0].Changes, 1)
+	assert.Equal(t, "order_confirmation", payload.Entry[0].Changes[0].Value.TemplateName)
+}
+
+// Test failures that can't be stored fail the webhook so the provider redelivers it
+func TestProcessWebhookFailureStoreUnavailable(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Error", mock.Anything, mock.Anything).Maybe()
+	failures := new(MockWebhookFailureRepository)
+	failures.On("CreateWebhookFailures", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Templates:  &stubTemplateEvents{failing: "order_confirmation"},
+		Signatures: testWebhookSignatures,
+		Failures:   failures,
+	}, mockLogger, "verify-token")
+
+	err := svc.ProcessWebhook(context.Background(), []byte(testBatchWebhook), signWebhook([]byte(testBatchWebhook)), "/webhook")
+	assert.ErrorContains(t, err, "notifier unavailable")
+}
+
+// Test replays delete the failures that go through and count an attempt for the others
+func TestReplayWebhookFailures(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Error", mock.Anything, mock.Anything).Maybe()
+	failures := new(MockWebhookFailureRepository)
+
+	change := func(template string) []byte {
+		return []byte(`{"object": "whatsapp_business_account", "entry": [{"id": "WABA-1", "changes": [
+			{"field": "message_template_status_update", "value": {"event": "PAUSED", "message_template_name": "` + template + `"}}
+		]}]}`)
+	}
+	failures.On("ListWebhookFailures", mock.Anything, 3, mock.Anything).Return([]domain.WebhookFailure{
+		{ID: 1, Payload: change("order_shipped")},
+		{ID: 2, Payload: change("order_confirmation"), Attempts: 2},
+	}, nil)
+	failures.On("DeleteWebhookFailure", mock.Anything, int64(1)).Return(nil)
+	failures.On("RecordWebhookFailureAttempt", mock.Anything, int64(2), "notifier unavailable").Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(new(MockMessageRepository), new(MockProducer), newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{
+		Templates:         &stubTemplateEvents{failing: "order_confirmation"},
+		Failures:          failures,
+		MaxReplayAttempts: 3,
+	}, mockLogger, "verify-token")
+
+	replayed, err := svc.ReplayFailures(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	failures.AssertExpectations(t)
+	failures.AssertNotCalled(t, "CreateWebhookFailures", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,145 @@
+// test/webhook_fuzz_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/meta/metatest"
+	"messaging-microservice/pkg/utils"
+)
+
+// fuzzMessageRepository knows every external ID and records the status updates applied
+type fuzzMessageRepository struct {
+	*MockMessageRepository
+	ids     map[string]int64
+	updates []domain.StatusUpdate
+}
+
+func (r *fuzzMessageRepository) GetMessageIDByExternalID(ctx context.Context, tenantID, externalID string) (int64, error) {
+	if _, ok := r.ids[externalID]; !ok {
+		r.ids[externalID] = int64(len(r.ids) + 1)
+	}
+	return r.ids[externalID], nil
+}
+
+func (r *fuzzMessageRepository) UpdateMessageStatuses(ctx context.Context, updates []domain.StatusUpdate) (map[int64]domain.StatusUpdateResult, error) {
+	r.updates = append(r.updates, updates...)
+	results := make(map[int64]domain.StatusUpdateResult, len(updates))
+	for _, update := range updates {
+		results[update.MessageID] = domain.StatusUpdateResult{Sequence: int64(len(r.updates))}
+	}
+	return results, nil
+}
+
+// addWebhookSeeds adds well-formed, truncated and oddly typed Meta webhooks to the corpus
+func addWebhookSeeds(f *testing.F) {
+	valid := metatest.StatusWebhook("PNID-1",
+		metatest.Status{ExternalID: "wamid.A", Recipient: "15551234567", Status: "delivered"},
+		metatest.Status{ExternalID: "wamid.B", Recipient: "15551234567", Status: "failed", ErrorCode: 131026, ErrorMessage: "Message undeliverable"},
+	)
+	for _, seed := range [][]byte{
+		[]byte(testStatusWebhook),
+		valid,
+		valid[:len(valid)/2],
+		metatest.StatusWebhook("PNID-2", metatest.Status{ExternalID: "wamid.C", Status: "read"}),
+		[]byte(`{"object": "page", "entry": []}`),
+		[]byte(`{"object": "whatsapp_business_account", "entry": [{"changes": [{"field": "account_update", "value": {"event": "DISABLED_UPDATE"}}]}]}`),
+		[]byte(`{"object": "whatsapp_business_account", "entry": [{"changes": [{"value": {"metadata": {"phone_number_id": "PNID-1"}, "statuses": [{"id": "", "status": "", "timestamp": "-99999999999999999999", "errors": [{"code": -1}]}]}}]}]}`),
+		[]byte(`{"object": "whatsapp_business_account", "entry": [{"changes": [{"value": {"statuses": "not-a-list"}}]}]}`),
+		[]byte(`{"object": 1}`),
+		[]byte(`null`),
+		[]byte(``),
+	} {
+		f.Add(seed)
+	}
+}
+
+// Fuzz Meta webhook parsing: no body signed with another secret is processed, no body panics
+// the service, undecodable bodies are rejected as validation errors, and every status of the
+// tenant's changes is applied and no other
+func FuzzProcessMetaWebhook(f *testing.F) {
+	addWebhookSeeds(f)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		repo := &fuzzMessageRepository{MockMessageRepository: new(MockMessageRepository), ids: map[string]int64{}}
+		producer := new(MockProducer)
+		producer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		logger := new(MockLogger)
+		for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+			logger.On(level, mock.Anything, mock.Anything).Maybe()
+		}
+		svc := service.NewWebhookServiceWithHandlers(repo, producer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, logger, "verify-token")
+
+		if err := svc.ProcessWebhook(context.Background(), body, metatest.Sign("other-secret", body), "/webhook"); !errors.Is(err, domain.ErrUnauthenticated) || len(repo.updates) > 0 {
+			t.Fatalf("body %q signed with another secret: got %v, %d statuses applied", body, err, len(repo.updates))
+		}
+		err := svc.ProcessWebhook(context.Background(), body, signWebhook(body), "/webhook")
+
+		var payload service.MetaWebhookPayload
+		if parseErr := json.Unmarshal(body, &payload); parseErr != nil {
+			if !errors.Is(err, domain.ErrValidation) {
+				t.Fatalf("undecodable body %q: got %v, want a validation error", body, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("decodable body %q: %v", body, err)
+		}
+
+		var want []string
+		if payload.Object == "whatsapp_business_account" {
+			for _, entry := range payload.Entry {
+				for _, change := range entry.Changes {
+					switch change.Field {
+					case domain.AccountFieldAccountUpdate, domain.AccountFieldQualityUpdate, domain.TemplateFieldStatusUpdate, domain.TemplateFieldQualityUpdate:
+						continue
+					}
+					if change.Value.Metadata.PhoneNumberID != "PNID-1" {
+						continue
+					}
+					for _, status := range change.Value.Statuses {
+						want = append(want, status.ID)
+					}
+				}
+			}
+		}
+		if len(repo.updates) != len(want) {
+			t.Fatalf("body %q: applied %d statuses, want %d", body, len(repo.updates), len(want))
+		}
+		for i, update := range repo.updates {
+			if update.ExternalID != want[i] || update.MessageID != repo.ids[want[i]] {
+				t.Fatalf("body %q: update %d is for %q (message %d), want %q", body, i, update.ExternalID, update.MessageID, want[i])
+			}
+		}
+	})
+}
+
+// Fuzz webhook signature validation: the signature of the body under the app secret is the
+// only header accepted, and none is without a secret
+func FuzzValidateWebhookSignature(f *testing.F) {
+	body := []byte(testStatusWebhook)
+	f.Add("secret", body, metatest.Sign("secret", body))
+	f.Add("secret", body, "sha256=")
+	f.Add("secret", body, "sha256=="+metatest.Sign("secret", body)[7:])
+	f.Add("secret", body, "SHA256="+metatest.Sign("secret", body)[7:])
+	f.Add("", body, metatest.Sign("", body))
+	f.Add("secret", []byte{}, "sha1=abc")
+	f.Fuzz(func(t *testing.T, secret string, body []byte, header string) {
+		client := meta.NewClient("PNID-1", "token", secret, discardLogger{})
+		valid := metatest.Sign(secret, body)
+
+		if secret != "" && !client.ValidateWebhookSignature(valid, "/webhook", body) {
+			t.Fatalf("secret %q, body %q: valid signature rejected", secret, body)
+		}
+		if client.ValidateWebhookSignature(header, "/webhook", body) && (secret == "" || header != valid) {
+			t.Fatalf("secret %q, body %q: header %q accepted", secret, body, header)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// test/webhook_replay_test.go
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/repository"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta/metatest"
+	"messaging-microservice/pkg/twilio"
+	"messaging-microservice/pkg/utils"
+)
+
+// statusWebhookAt is a Meta status webhook for wamid.ABC dated at
+func statusWebhookAt(at time.Time) []byte {
+	return []byte(fmt.Sprintf(`{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"value": {
+			"metadata": {"phone_number_id": "PNID-1"},
+			"statuses": [{"id": "wamid.ABC", "status": "delivered", "timestamp": "%s"}]
+		}}]}]
+	}`, strconv.FormatInt(at.Unix(), 10)))
+}
+
+func newReplayWebhookService(repo *MockMessageRepository, producer *MockProducer, logger *MockLogger, action string) service.WebhookService {
+	guard := service.NewReplayGuard(repository.NewMemoryWebhookNonceStore(100, time.Hour), service.ReplayPolicy{
+		MaxAge: time.Hour,
+		Action: action,
+	}, logger)
+	resolver := service.NewStaticTenantResolver(map[string]string{"PNID-1": "tenant-a", "whatsapp:+14155238886": "tenant-a"})
+	return service.NewWebhookServiceWithHandlers(repo, producer, resolver, utils.NewPlainPhoneNumberHasher(),
+		service.WebhookHandlers{Replay: guard, Signatures: testWebhookSignatures}, logger, "verify-token")
+}
+
+// Test statuses dated outside the window are dropped before they are looked up
+func TestReplayGuardRejectsStaleAndFutureStatuses(t *testing.T) {
+	repo := new(MockMessageRepository)
+	producer := new(MockProducer)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	svc := newReplayWebhookService(repo, producer, logger, service.ReplayActionReject)
+
+	stale, future := statusWebhookAt(time.Now().Add(-2*time.Hour)), statusWebhookAt(time.Now().Add(time.Hour))
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), stale, signWebhook(stale), "/webhook"))
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), future, signWebhook(future), "/webhook"))
+
+	repo.AssertNotCalled(t, "GetMessageIDByExternalID", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateMessageStatuses", mock.Anything, mock.Anything)
+}
+
+// Test a status redelivered after it was processed is dropped
+func TestReplayGuardRejectsProcessedStatus(t *testing.T) {
+	repo := new(MockMessageRepository)
+	producer := new(MockProducer)
+	logger := newDebugLogger()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	repo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(42, nil)
+	repo.On("UpdateMessageStatuses", mock.Anything, mock.Anything).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 1}}, nil).Once()
+	producer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	svc := newReplayWebhookService(repo, producer, logger, service.ReplayActionReject)
+
+	payload := statusWebhookAt(time.Now().Add(-time.Minute))
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), payload, signWebhook(payload), "/webhook"))
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), payload, signWebhook(payload), "/webhook"))
+
+	repo.AssertNumberOfCalls(t, "UpdateMessageStatuses", 1)
+	producer.AssertNumberOfCalls(t, "ProduceWithKey", 1)
+}
+
+// Test a forged callback is rejected before the replay check, so the genuine event still goes
+// through after it
+func TestReplayGuardChecksSignatureFirst(t *testing.T) {
+	repo := new(MockMessageRepository)
+	producer := new(MockProducer)
+	logger := newDebugLogger()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	repo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(42, nil)
+	repo.On("UpdateMessageStatuses", mock.Anything, mock.Anything).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 1}}, nil).Once()
+	producer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	svc := newReplayWebhookService(repo, producer, logger, service.ReplayActionReject)
+
+	payload := statusWebhookAt(time.Now().Add(-time.Minute))
+	err := svc.ProcessWebhook(context.Background(), payload, metatest.Sign("other-secret", payload), "/webhook")
+	assert.True(t, errors.Is(err, domain.ErrUnauthenticated))
+	repo.AssertNotCalled(t, "GetMessageIDByExternalID", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), payload, signWebhook(payload), "/webhook"))
+	repo.AssertExpectations(t)
+	producer.AssertExpectations(t)
+}
+
+// Test flagged replays are logged but still processed
+func TestReplayGuardFlagsStaleStatus(t *testing.T) {
+	repo := new(MockMessageRepository)
+	producer := new(MockProducer)
+	logger := newDebugLogger()
+	logger.On("Warn", "Webhook event looks replayed", mock.Anything).Once()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+
+	repo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(42, nil)
+	repo.On("UpdateMessageStatuses", mock.Anything, mock.Anything).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 1}}, nil).Once()
+	producer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	svc := newReplayWebhookService(repo, producer, logger, service.ReplayActionFlag)
+
+	stale := statusWebhookAt(time.Now().Add(-2 * time.Hour))
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), stale, signWebhook(stale), "/webhook"))
+
+	repo.AssertExpectations(t)
+	logger.AssertExpectations(t)
+}
+
+// Test an undated Twilio callback is only checked against processed events
+func TestReplayGuardRejectsProcessedTwilioCallback(t *testing.T) {
+	repo := new(MockMessageRepository)
+	producer := new(MockProducer)
+	logger := new(MockLogger)
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	repo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "SM123").Return(42, nil)
+	repo.On("UpdateMessageStatuses", mock.Anything, mock.Anything).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 1}}, nil).Once()
+	producer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	svc := newReplayWebhookService(repo, producer, logger, service.ReplayActionReject)
+
+	callback := &twilio.StatusCallback{MessageSID: "SM123", MessageStatus: "delivered", From: "whatsapp:+14155238886", To: "whatsapp:+15551234567"}
+	assert.NoError(t, svc.ProcessTwilioStatus(context.Background(), callback))
+	assert.NoError(t, svc.ProcessTwilioStatus(context.Background(), callback))
+
+	repo.AssertNumberOfCalls(t, "UpdateMessageStatuses", 1)
+}
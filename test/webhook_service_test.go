@@ -0,0 +1,186 @@
+// test/webhook_service_test.go
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"messaging-microservice/internal/domain"
+	"messaging-microservice/internal/service"
+	"messaging-microservice/pkg/meta"
+	"messaging-microservice/pkg/meta/metatest"
+	"messaging-microservice/pkg/utils"
+)
+
+const testStatusWebhook = `{
+	"object": "whatsapp_business_account",
+	"entry": [{
+		"id": "WABA-1",
+		"changes": [{
+			"value": {
+				"messaging_product": "whatsapp",
+				"metadata": {"display_phone_number": "15550000000", "phone_number_id": "PNID-1"},
+				"statuses": [{
+					"id": "wamid.ABC",
+					"recipient_id": "1234567890",
+					"status": "delivered",
+					"timestamp": "1700000000"
+				}]
+			}
+		}]
+	}]
+}`
+
+// testAppSecret is the app secret test webhooks are signed with
+const testAppSecret = "app-secret"
+
+// testWebhookSignatures checks webhook signatures as the Meta client does
+var testWebhookSignatures = meta.NewClient("PNID-1", "token", testAppSecret, discardLogger{}).ValidateWebhookSignature
+
+// signWebhook returns the X-Hub-Signature-256 of body under testAppSecret
+func signWebhook(body []byte) string {
+	return metatest.Sign(testAppSecret, body)
+}
+
+func newTestTenantResolver() service.TenantResolver {
+	return service.NewStaticTenantResolver(map[string]string{"PNID-1": "tenant-a"})
+}
+
+// Test ProcessWebhook publishes a sequenced, keyed status event
+func TestProcessWebhookPublishesSequencedEvent(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := newDebugLogger()
+
+	// Set up mock expectations
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.ABC").Return(42, nil)
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, []domain.StatusUpdate{
+		{MessageID: 42, Status: "delivered", ExternalID: "wamid.ABC", At: time.Unix(1700000000, 0)},
+	}).Return(map[int64]domain.StatusUpdateResult{42: {Sequence: 3}}, nil)
+
+	var published service.WebhookEvent
+	mockProducer.On("ProduceWithKey", mock.Anything, []byte("42"), mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal(args.Get(2).([]byte), &published))
+	}).Return(nil)
+
+	mockLogger.On("Error", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	// Create service
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, mockLogger, "verify-token")
+
+	// Test
+	err := svc.ProcessWebhook(context.Background(), []byte(testStatusWebhook), signWebhook([]byte(testStatusWebhook)), "/webhook")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), published.MessageID)
+	assert.Equal(t, "tenant-a", published.TenantID)
+	assert.Equal(t, int64(3), published.Sequence)
+	assert.Equal(t, "delivered", published.Status)
+	assert.Equal(t, "wamid.ABC:delivered:1700000000", published.DedupeKey)
+
+	// Verify mock expectations
+	mockRepo.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+// Test webhooks without a valid signature are rejected before they are parsed
+func TestProcessWebhookRejectsBadSignature(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	body := []byte(testStatusWebhook)
+	tampered := []byte(strings.Replace(testStatusWebhook, "delivered", "read", 1))
+
+	tests := []struct {
+		name       string
+		signatures func(signature, url string, body []byte) bool
+		signature  string
+	}{
+		{"missing", testWebhookSignatures, ""},
+		{"forged", testWebhookSignatures, "sha256=fuzz"},
+		{"other secret", testWebhookSignatures, metatest.Sign("other-secret", body)},
+		{"other body", testWebhookSignatures, signWebhook(tampered)},
+		{"no validator", nil, signWebhook(body)},
+	}
+	for _, tt := range tests {
+		svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: tt.signatures}, new(MockLogger), "verify-token")
+		err := svc.ProcessWebhook(context.Background(), body, tt.signature, "/webhook")
+		assert.True(t, errors.Is(err, domain.ErrUnauthenticated), tt.name)
+	}
+	mockRepo.AssertNotCalled(t, "GetMessageIDByExternalID", mock.Anything, mock.Anything, mock.Anything)
+	mockProducer.AssertNotCalled(t, "ProduceWithKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ProcessWebhook ignores statuses for phone number IDs no tenant owns
+func TestProcessWebhookUnknownPhoneNumberID(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	// Create service with a resolver that doesn't know PNID-1
+	resolver := service.NewStaticTenantResolver(map[string]string{"PNID-2": "tenant-b"})
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, resolver, utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, mockLogger, "verify-token")
+
+	// Test
+	err := svc.ProcessWebhook(context.Background(), []byte(testStatusWebhook), signWebhook([]byte(testStatusWebhook)), "/webhook")
+
+	// Assert nothing was looked up or published
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "GetMessageIDByExternalID", mock.Anything, mock.Anything, mock.Anything)
+	mockProducer.AssertNotCalled(t, "ProduceWithKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test statuses batched in one payload are stored with one call and numbered in payload order
+func TestProcessWebhookBatchesStatuses(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockProducer := new(MockProducer)
+	mockLogger := newDebugLogger()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	payload := `{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"value": {
+			"metadata": {"phone_number_id": "PNID-1"},
+			"statuses": [
+				{"id": "wamid.A", "status": "sent", "timestamp": "1"},
+				{"id": "wamid.B", "status": "sent", "timestamp": "1"},
+				{"id": "wamid.A", "status": "delivered", "timestamp": "2"},
+				{"id": "wamid.A", "status": "read", "timestamp": "3"}
+			]
+		}}]}]
+	}`
+
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.A").Return(1, nil)
+	mockRepo.On("GetMessageIDByExternalID", mock.Anything, "tenant-a", "wamid.B").Return(2, nil)
+	mockRepo.On("UpdateMessageStatuses", mock.Anything, mock.MatchedBy(func(updates []domain.StatusUpdate) bool {
+		return len(updates) == 4
+	})).Return(map[int64]domain.StatusUpdateResult{1: {Sequence: 7}, 2: {Sequence: 1}}, nil).Once()
+
+	var published []service.WebhookEvent
+	mockProducer.On("ProduceWithKey", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var event service.WebhookEvent
+		assert.NoError(t, json.Unmarshal(args.Get(2).([]byte), &event))
+		published = append(published, event)
+	}).Return(nil)
+
+	svc := service.NewWebhookServiceWithHandlers(mockRepo, mockProducer, newTestTenantResolver(), utils.NewPlainPhoneNumberHasher(), service.WebhookHandlers{Signatures: testWebhookSignatures}, mockLogger, "verify-token")
+	assert.NoError(t, svc.ProcessWebhook(context.Background(), []byte(payload), signWebhook([]byte(payload)), "/webhook"))
+
+	assert.Len(t, published, 4)
+	sequences := map[string]int64{}
+	for _, event := range published {
+		sequences[event.ExternalID+":"+event.Status] = event.Sequence
+	}
+	assert.Equal(t, map[string]int64{"wamid.A:sent": 5, "wamid.B:sent": 1, "wamid.A:delivered": 6, "wamid.A:read": 7}, sequences)
+	mockRepo.AssertNumberOfCalls(t, "UpdateMessageStatuses", 1)
+}